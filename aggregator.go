@@ -0,0 +1,133 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// ColumnAggregator is an interface implemented by types which compute custom
+// statistics of the values written to a column, and contribute them to the
+// key/value metadata of column chunks and files. Aggregators let programs
+// compute statistics such as the number of distinct tenants or the length of
+// the longest string of a column while the values are written, instead of
+// scanning the data a second time.
+//
+// Writers construct an aggregator for each column configured with the
+// ColumnAggregators option, the methods are called by the goroutine writing
+// rows and do not need to be safe for concurrent use.
+type ColumnAggregator interface {
+	// Observe is called with the values of each data page written to the
+	// column, including null values. The values must not be retained after
+	// the method returned.
+	Observe(values []Value)
+
+	// RowGroupMetadata is called after the column chunk of each row group is
+	// written, and returns the key/value pairs added to the metadata of the
+	// column chunk. Aggregators computing statistics per row group reset
+	// them when the method is called.
+	RowGroupMetadata() map[string]string
+
+	// FileMetadata is called when the writer is closed, and returns the
+	// key/value pairs added to the metadata of the file. The keys must not
+	// collide with the key/value metadata set on the writer.
+	FileMetadata() map[string]string
+}
+
+// The AggregatorColumn interface is a declarative representation of column
+// aggregators used when configuring aggregators on a parquet writer.
+type AggregatorColumn interface {
+	// Returns the path of the column that the aggregator applies to.
+	Path() []string
+
+	// Constructs a new aggregator, called by writers when they are created
+	// and when they are reset to write a new file.
+	NewAggregator() ColumnAggregator
+}
+
+// Aggregate constructs an aggregator column object for the column at the given
+// path, which calls newAggregator to construct the aggregators of writers.
+func Aggregate(newAggregator func() ColumnAggregator, path ...string) AggregatorColumn {
+	return &aggregatorColumn{path: path, newAggregator: newAggregator}
+}
+
+type aggregatorColumn struct {
+	path          []string
+	newAggregator func() ColumnAggregator
+}
+
+func (a *aggregatorColumn) Path() []string                  { return a.path }
+func (a *aggregatorColumn) NewAggregator() ColumnAggregator { return a.newAggregator() }
+
+func searchAggregatorColumn(aggregators []AggregatorColumn, path columnPath) AggregatorColumn {
+	for _, a := range aggregators {
+		if path.equal(a.Path()) {
+			return a
+		}
+	}
+	return nil
+}
+
+// validateAggregators returns an error if some of the aggregators are
+// configured for paths that do not name leaf columns of the schema.
+func validateAggregators(schema *Schema, aggregators []AggregatorColumn) error {
+	for _, a := range aggregators {
+		if _, ok := schema.Lookup(a.Path()...); !ok {
+			return fmt.Errorf("aggregator configured for column %q which is not a leaf column of the schema", columnPath(a.Path()))
+		}
+	}
+	return nil
+}
+
+// LookupColumnChunkMetadata returns the value of the key/value metadata of the
+// column chunk associated with the given key, for example the metadata written
+// by column aggregators.
+//
+// The ok boolean will be true if the key was found, false otherwise, including
+// when the column chunk was not read from a file.
+func LookupColumnChunkMetadata(chunk ColumnChunk, key string) (value string, ok bool) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return "", false
+	}
+	for _, kv := range c.chunk.MetaData.KeyValueMetadata {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return "", false
+}
+
+// observePage passes the values of the page to the aggregator of the column.
+func (c *writerColumn) observePage(page Page) error {
+	if c.aggregation.values == nil {
+		c.aggregation.values = make([]Value, defaultValueBufferSize)
+	}
+	values := c.aggregation.values
+	reader := page.Values()
+	for {
+		n, err := reader.ReadValues(values)
+		if n > 0 {
+			c.aggregation.aggregator.Observe(values[:n])
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return err
+		}
+	}
+}
+
+func makeKeyValueMetadata(metadata map[string]string) []format.KeyValue {
+	if len(metadata) == 0 {
+		return nil
+	}
+	keyValueMetadata := make([]format.KeyValue, 0, len(metadata))
+	for k, v := range metadata {
+		keyValueMetadata = append(keyValueMetadata, format.KeyValue{Key: k, Value: v})
+	}
+	sortKeyValueMetadata(keyValueMetadata)
+	return keyValueMetadata
+}
@@ -0,0 +1,122 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+// maxLengthAggregator computes the length of the longest string of a column,
+// per row group and for the whole file.
+type maxLengthAggregator struct {
+	rowGroupMaxLength int
+	fileMaxLength     int
+	numNulls          int
+}
+
+func (a *maxLengthAggregator) Observe(values []parquet.Value) {
+	for _, v := range values {
+		if v.IsNull() {
+			a.numNulls++
+			continue
+		}
+		if n := len(v.ByteArray()); n > a.rowGroupMaxLength {
+			a.rowGroupMaxLength = n
+		}
+	}
+}
+
+func (a *maxLengthAggregator) RowGroupMetadata() map[string]string {
+	if a.rowGroupMaxLength > a.fileMaxLength {
+		a.fileMaxLength = a.rowGroupMaxLength
+	}
+	metadata := map[string]string{"max_length": strconv.Itoa(a.rowGroupMaxLength)}
+	a.rowGroupMaxLength = 0
+	return metadata
+}
+
+func (a *maxLengthAggregator) FileMetadata() map[string]string {
+	return map[string]string{
+		"name.max_length": strconv.Itoa(a.fileMaxLength),
+		"name.null_count": strconv.Itoa(a.numNulls),
+	}
+}
+
+func TestColumnAggregators(t *testing.T) {
+	type Row struct {
+		ID   int64   `parquet:"id"`
+		Name *string `parquet:"name,optional"`
+	}
+
+	aggregators := parquet.ColumnAggregators(
+		parquet.Aggregate(func() parquet.ColumnAggregator { return new(maxLengthAggregator) }, "name"),
+	)
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, aggregators, parquet.PageBufferSize(256))
+
+	// Each row group has longer names than the previous one, and one row out
+	// of four has a null name.
+	const numRowGroups = 3
+	const rowsPerGroup = 100
+	for i := 0; i < numRowGroups; i++ {
+		for j := 0; j < rowsPerGroup; j++ {
+			row := Row{ID: int64(i*rowsPerGroup + j)}
+			if j%4 != 0 {
+				name := fmt.Sprintf("%0*d", 10*(i+1)-j%3, j)
+				row.Name = &name
+			}
+			if err := w.Write(&row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, rowGroup := range f.RowGroups() {
+		chunk, _ := parquet.LookupColumnChunk(rowGroup, "name")
+		value, ok := parquet.LookupColumnChunkMetadata(chunk, "max_length")
+		if !ok {
+			t.Fatalf("row group %d: missing column chunk metadata", i)
+		}
+		if want := strconv.Itoa(10 * (i + 1)); value != want {
+			t.Errorf("row group %d: wrong max length: want=%s got=%s", i, want, value)
+		}
+
+		chunk, _ = parquet.LookupColumnChunk(rowGroup, "id")
+		if _, ok := parquet.LookupColumnChunkMetadata(chunk, "max_length"); ok {
+			t.Errorf("row group %d: metadata found on a column without aggregator", i)
+		}
+	}
+
+	for key, want := range map[string]string{
+		"name.max_length": "30",
+		"name.null_count": strconv.Itoa(numRowGroups * rowsPerGroup / 4),
+	} {
+		if value, ok := f.Lookup(key); !ok || value != want {
+			t.Errorf("wrong file metadata for %q: want=%q got=%q (%t)", key, want, value, ok)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic when configuring an aggregator for a missing column")
+		}
+	}()
+	parquet.NewWriter(new(bytes.Buffer), parquet.SchemaOf(Row{}),
+		parquet.ColumnAggregators(parquet.Aggregate(func() parquet.ColumnAggregator { return new(maxLengthAggregator) }, "missing")),
+	)
+}
@@ -0,0 +1,102 @@
+package parquet
+
+// Allocator is an interface implemented by types which manage the memory that
+// column buffers hold their values in, as an alternative to slices allocated on
+// the Go heap.
+//
+// Column buffers never store Go pointers in memory returned by an Allocator, so
+// the memory may be allocated outside of the Go heap (for example with mmap),
+// which keeps large write buffers out of reach of the garbage collector and
+// reduces the duration of its pauses.
+//
+// The memory is released when column buffers grow and need a larger area, and
+// when they are reset. Values read from a column buffer (e.g. byte arrays) may
+// reference its memory, programs must not retain them after resetting the
+// buffer.
+//
+// Allocator implementations must be safe to use concurrently from multiple
+// goroutines.
+type Allocator interface {
+	// Allocate returns a byte slice with a length of at least size bytes.
+	Allocate(size int) []byte
+
+	// Release is called with slices previously returned by Allocate when the
+	// column buffers do not use them anymore.
+	Release(buf []byte)
+}
+
+// ColumnBufferAllocator creates a configuration option which installs an
+// allocator for the memory holding the values of row group column buffers.
+//
+// The allocator is used by columns of the BOOLEAN, INT32, INT64, FLOAT, DOUBLE,
+// BYTE_ARRAY, and FIXED_LEN_BYTE_ARRAY types. The definition and repetition
+// levels of optional and repeated columns, as well as dictionaries and the
+// indexes of dictionary-encoded columns, remain allocated on the Go heap.
+//
+// Since the memory is released when the buffers are reset, programs should
+// call the Reset method of buffers that they do not use anymore.
+//
+// Defaults to nil, which allocates values on the Go heap.
+func ColumnBufferAllocator(allocator Allocator) RowGroupOption {
+	return rowGroupOption(func(config *RowGroupConfig) { config.Allocator = allocator })
+}
+
+// setColumnBufferAllocator installs the allocator on the leaf column buffer, if
+// its type supports it. The column buffer must be empty.
+func setColumnBufferAllocator(column ColumnBuffer, allocator Allocator) {
+	if c, ok := column.(interface{ setAllocator(Allocator) }); ok {
+		c.setAllocator(allocator)
+	}
+}
+
+// allocatorOf returns the allocator installed on the leaf column buffer, or nil
+// if its values are allocated on the Go heap.
+func allocatorOf(column ColumnBuffer) Allocator {
+	if c, ok := column.(interface{ bufferAllocator() Allocator }); ok {
+		return c.bufferAllocator()
+	}
+	return nil
+}
+
+// columnMemory manages the memory that a column buffer allocates with an
+// Allocator. The zero-value has no allocator, column buffers then grow their
+// values with the Go slices they were constructed with.
+type columnMemory struct {
+	allocator Allocator
+	// Minimum size of allocations, which avoids growing from small areas
+	// after the buffer was reset.
+	minSize int
+	memory  []byte
+}
+
+func (m *columnMemory) init(allocator Allocator, minSize int) {
+	*m = columnMemory{allocator: allocator, minSize: minSize}
+}
+
+func (m *columnMemory) bufferAllocator() Allocator { return m.allocator }
+
+// grow returns a new area of at least size bytes holding a copy of data, which
+// are the bytes in use in the current area. The current area is released.
+func (m *columnMemory) grow(data []byte, size int) []byte {
+	size = max(size, 2*len(m.memory))
+	size = max(size, m.minSize)
+	memory := m.allocator.Allocate(size)
+	memory = memory[:cap(memory)]
+	copy(memory, data)
+	m.release()
+	m.memory = memory
+	return memory
+}
+
+// release releases the current area to the allocator, returning true if the
+// memory of the column buffer was allocated with an allocator.
+func (m *columnMemory) release() bool {
+	if m.allocator == nil {
+		return false
+	}
+	if m.memory != nil {
+		m.allocator.Release(m.memory)
+		m.memory = nil
+	}
+	return true
+}
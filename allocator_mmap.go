@@ -0,0 +1,38 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package parquet
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// NewMmapAllocator returns an Allocator which creates anonymous memory mappings
+// to hold the values of column buffers, outside of the Go heap. The memory is
+// returned to the operating system when column buffers release it.
+//
+// On platforms which do not support memory mappings, the allocator allocates
+// memory on the Go heap.
+//
+// The allocator panics if a memory mapping cannot be created, similarly to the
+// Go runtime when it fails to allocate memory.
+func NewMmapAllocator() Allocator { return mmapAllocator{} }
+
+type mmapAllocator struct{}
+
+func (mmapAllocator) Allocate(size int) []byte {
+	pageSize := os.Getpagesize()
+	size = ((size + pageSize - 1) / pageSize) * pageSize
+	b, err := syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		panic(fmt.Errorf("parquet: cannot allocate %d bytes of memory: %w", size, err))
+	}
+	return b
+}
+
+func (mmapAllocator) Release(b []byte) {
+	// The slice must be the one returned by syscall.Mmap, which looks up the
+	// mapping by the address of its last byte.
+	_ = syscall.Munmap(b[:cap(b)])
+}
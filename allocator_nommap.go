@@ -0,0 +1,17 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package parquet
+
+// NewMmapAllocator returns an Allocator which creates anonymous memory mappings
+// to hold the values of column buffers, outside of the Go heap. The memory is
+// returned to the operating system when column buffers release it.
+//
+// On platforms which do not support memory mappings, the allocator allocates
+// memory on the Go heap.
+func NewMmapAllocator() Allocator { return heapAllocator{} }
+
+type heapAllocator struct{}
+
+func (heapAllocator) Allocate(size int) []byte { return make([]byte, size) }
+
+func (heapAllocator) Release([]byte) {}
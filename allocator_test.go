@@ -0,0 +1,142 @@
+package parquet_test
+
+import (
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+// countingAllocator allocates memory on the Go heap and tracks the number of
+// bytes not yet released.
+type countingAllocator struct {
+	mutex sync.Mutex
+	inUse map[*byte]int
+}
+
+func (a *countingAllocator) Allocate(size int) []byte {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	b := make([]byte, size)
+	if a.inUse == nil {
+		a.inUse = make(map[*byte]int)
+	}
+	a.inUse[&b[0]] = size
+	return b
+}
+
+func (a *countingAllocator) Release(b []byte) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if _, ok := a.inUse[&b[0]]; !ok {
+		panic("released memory which was not allocated or already released")
+	}
+	delete(a.inUse, &b[0])
+}
+
+func (a *countingAllocator) bytesInUse() (n int) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, size := range a.inUse {
+		n += size
+	}
+	return n
+}
+
+func TestColumnBufferAllocator(t *testing.T) {
+	type Row struct {
+		Flag   bool     `parquet:"flag"`
+		Int32  int32    `parquet:"int32"`
+		Int64  int64    `parquet:"int64"`
+		Uint32 uint32   `parquet:"uint32"`
+		Uint64 uint64   `parquet:"uint64"`
+		Float  float32  `parquet:"float"`
+		Double float64  `parquet:"double"`
+		Name   string   `parquet:"name"`
+		Tags   []string `parquet:"tags"`
+		Key    [16]byte `parquet:"key"`
+		Hash   [5]byte  `parquet:"hash"`
+		Note   *string  `parquet:"note,optional"`
+	}
+
+	makeRows := func(n int) []Row {
+		rows := make([]Row, n)
+		for i := range rows {
+			note := string(rune('a' + i%26))
+			rows[i] = Row{
+				Flag:   i%3 == 0,
+				Int32:  int32(n - i),
+				Int64:  int64(i) * 1e9,
+				Uint32: uint32(i),
+				Uint64: uint64(i) << 40,
+				Float:  float32(i) / 2,
+				Double: float64(i) / 3,
+				Name:   string(make([]byte, i%50)),
+				Tags:   []string{note, note + note},
+				Key:    [16]byte{byte(i)},
+				Hash:   [5]byte{4: byte(i)},
+			}
+			if i%2 == 0 {
+				rows[i].Note = &note
+			}
+		}
+		return rows
+	}
+
+	allocators := []struct {
+		scenario  string
+		allocator func() parquet.Allocator
+	}{
+		{scenario: "counting", allocator: func() parquet.Allocator { return new(countingAllocator) }},
+		{scenario: "mmap", allocator: parquet.NewMmapAllocator},
+	}
+
+	for _, test := range allocators {
+		t.Run(test.scenario, func(t *testing.T) {
+			allocator := test.allocator()
+			buffer := parquet.NewBuffer(
+				parquet.SchemaOf(Row{}),
+				parquet.ColumnBufferCapacity(10),
+				parquet.ColumnBufferAllocator(allocator),
+				parquet.SortingColumns(parquet.Ascending("int32")),
+			)
+
+			// Write twice to verify that the buffer can be reused after the
+			// memory was released by Reset.
+			for _, n := range []int{1000, 100} {
+				rows := makeRows(n)
+				for i := range rows {
+					if err := buffer.Write(&rows[i]); err != nil {
+						t.Fatal(err)
+					}
+				}
+				sort.Sort(buffer)
+
+				reader := parquet.NewRowGroupReader(buffer)
+				for i := len(rows) - 1; i >= 0; i-- {
+					var row Row
+					if err := reader.Read(&row); err != nil {
+						t.Fatalf("reading row %d: %v", i, err)
+					}
+					if !reflect.DeepEqual(row, rows[i]) {
+						t.Fatalf("wrong row after sorting:\nwant = %+v\ngot  = %+v", rows[i], row)
+					}
+				}
+				if err := reader.Read(new(Row)); err != io.EOF {
+					t.Fatalf("expected io.EOF after reading all rows, got %v", err)
+				}
+
+				if a, ok := allocator.(*countingAllocator); ok && a.bytesInUse() == 0 {
+					t.Error("no memory was allocated with the allocator")
+				}
+				buffer.Reset()
+				if a, ok := allocator.(*countingAllocator); ok && a.bytesInUse() != 0 {
+					t.Errorf("%d bytes not released after resetting the buffer", a.bytesInUse())
+				}
+			}
+		})
+	}
+}
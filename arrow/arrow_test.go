@@ -0,0 +1,377 @@
+package arrow_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/segmentio/parquet-go"
+	parquetarrow "github.com/segmentio/parquet-go/arrow"
+)
+
+type contact struct {
+	Name  string  `parquet:"name"`
+	Phone *string `parquet:"phone"`
+}
+
+type event struct {
+	ID       int64            `parquet:"id"`
+	Name     string           `parquet:"name,dict"`
+	Level    uint16           `parquet:"level"`
+	Score    *float64         `parquet:"score"`
+	Time     time.Time        `parquet:"time"`
+	Payload  []byte           `parquet:"payload"`
+	Tags     []string         `parquet:"tags"`
+	Labels   []int32          `parquet:"labels,list"`
+	Attrs    map[string]int32 `parquet:"attrs"`
+	Contact  *contact         `parquet:"contact"`
+	Contacts []contact        `parquet:"contacts,list"`
+}
+
+func newEvent(i int) event {
+	e := event{
+		ID:      int64(i),
+		Name:    fmt.Sprintf("event-%d", i%3),
+		Level:   uint16(i * 1000),
+		Time:    time.Unix(int64(i), int64(i)).UTC(),
+		Payload: []byte{byte(i)},
+	}
+	if i%2 == 0 {
+		score := float64(i) / 2
+		e.Score = &score
+		phone := fmt.Sprintf("555-%04d", i)
+		e.Contact = &contact{Name: "A", Phone: &phone}
+	}
+	for j := 0; j < i%4; j++ {
+		e.Tags = append(e.Tags, fmt.Sprint(j))
+		e.Contacts = append(e.Contacts, contact{Name: fmt.Sprint(i, j)})
+	}
+	for j := 0; j < i%3; j++ {
+		e.Labels = append(e.Labels, int32(i*j))
+	}
+	if i%5 != 0 {
+		e.Attrs = map[string]int32{"a": int32(i)}
+		if i%2 == 0 {
+			e.Attrs["b"] = -int32(i)
+		}
+	}
+	return e
+}
+
+func writeEvents(t *testing.T, schema *parquet.Schema, events []event, rowGroupSize int) *parquet.File {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	w := parquet.NewWriter(buf, schema, parquet.PageBufferSize(128))
+
+	for i, e := range events {
+		if err := w.Write(e); err != nil {
+			t.Fatal(err)
+		}
+		if (i+1)%rowGroupSize == 0 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func readEvents(t *testing.T, f *parquet.File) []event {
+	t.Helper()
+	r := parquet.NewReader(f)
+	events := make([]event, 0, f.NumRows())
+	for i := int64(0); i < f.NumRows(); i++ {
+		var e event
+		if err := r.Read(&e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := parquetarrow.SchemaOf(parquet.SchemaOf(event{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: arrow.BinaryTypes.String}},
+		{Name: "level", Type: arrow.PrimitiveTypes.Uint16},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}},
+		{Name: "payload", Type: arrow.BinaryTypes.Binary},
+		{Name: "tags", Type: arrow.ListOfField(arrow.Field{Name: "element", Type: arrow.BinaryTypes.String})},
+		{Name: "labels", Type: arrow.ListOfField(arrow.Field{Name: "element", Type: arrow.PrimitiveTypes.Int32})},
+		{Name: "attrs", Type: func() arrow.DataType {
+			m := arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Int32)
+			m.SetItemNullable(false)
+			return m
+		}()},
+		{Name: "contact", Type: arrow.StructOf(
+			arrow.Field{Name: "name", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "phone", Type: arrow.BinaryTypes.String, Nullable: true},
+		), Nullable: true},
+		{Name: "contacts", Type: arrow.ListOfField(arrow.Field{Name: "element", Type: arrow.StructOf(
+			arrow.Field{Name: "name", Type: arrow.BinaryTypes.String},
+			arrow.Field{Name: "phone", Type: arrow.BinaryTypes.String, Nullable: true},
+		)})},
+	}, nil)
+
+	if !schema.Equal(want) {
+		t.Errorf("schema mismatch:\nwant: %s\ngot:  %s", want, schema)
+	}
+}
+
+func TestParquetSchemaOf(t *testing.T) {
+	schema, err := parquetarrow.ParquetSchemaOf("event", arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Uint64},
+		{Name: "name", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int16, ValueType: arrow.BinaryTypes.String}, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Microsecond}},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.Binary)},
+		{Name: "attrs", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.PrimitiveTypes.Float32)},
+		{Name: "point", Type: arrow.StructOf(
+			arrow.Field{Name: "x", Type: arrow.PrimitiveTypes.Int8},
+			arrow.Field{Name: "y", Type: &arrow.FixedSizeBinaryType{ByteWidth: 4}, Nullable: true},
+		)},
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `message event {
+	required group attrs (MAP) {
+		repeated group key_value {
+			required binary key (STRING);
+			optional float value;
+		}
+	}
+	required int32 day (DATE);
+	required int64 id (INT(64,false));
+	optional binary name (STRING);
+	required group point {
+		required int32 x (INT(8,true));
+		optional fixed_len_byte_array(4) y;
+	}
+	required group tags (LIST) {
+		repeated group list {
+			optional binary element;
+		}
+	}
+	required int64 time (TIMESTAMP(isAdjustedToUTC=true,unit=MICROS));
+}`
+
+	if got := schema.String(); got != want {
+		t.Errorf("schema mismatch:\nwant:\n%s\ngot:\n%s", want, got)
+	}
+
+	if leaf, _ := schema.Lookup("name"); leaf.Node.Encoding() != &parquet.RLEDictionary {
+		t.Errorf("dictionary type was not mapped to a dictionary encoded column: %v", leaf.Node.Encoding())
+	}
+
+	_, err = parquetarrow.ParquetSchemaOf("event", arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Second}},
+	}, nil))
+	if err == nil {
+		t.Error("expected an error converting timestamps in seconds")
+	}
+}
+
+func TestReadRowGroup(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	events := make([]event, 10)
+	for i := range events {
+		events[i] = newEvent(i)
+	}
+	f := writeEvents(t, parquet.SchemaOf(event{}), events, len(events))
+
+	record, err := parquetarrow.ReadRowGroup(f.RowGroups()[0], mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer record.Release()
+
+	if n := record.NumRows(); n != int64(len(events)) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(events), n)
+	}
+
+	column := func(name string) arrow.Array {
+		indexes := record.Schema().FieldIndices(name)
+		if len(indexes) != 1 {
+			t.Fatalf("missing column %q", name)
+		}
+		return record.Column(indexes[0])
+	}
+
+	names := column("name").(*array.Dictionary)
+	if n := names.Dictionary().Len(); n != 3 {
+		t.Errorf("wrong number of dictionary values: want=3 got=%d", n)
+	}
+	scores := column("score").(*array.Float64)
+	tags := column("tags").(*array.List)
+	labels := column("labels").(*array.List)
+	labelValues := labels.ListValues().(*array.Int32)
+	contacts := column("contacts").(*array.List)
+	contactNames := contacts.ListValues().(*array.Struct).Field(0).(*array.String)
+	attrs := column("attrs").(*array.Map)
+	contactField := column("contact").(*array.Struct)
+
+	for i, e := range events {
+		dict := names.Dictionary().(*array.String)
+		if got := dict.Value(names.GetValueIndex(i)); got != e.Name {
+			t.Errorf("row %d: wrong name: want=%q got=%q", i, e.Name, got)
+		}
+		if scores.IsNull(i) != (e.Score == nil) || (e.Score != nil && scores.Value(i) != *e.Score) {
+			t.Errorf("row %d: wrong score", i)
+		}
+		if contactField.IsNull(i) != (e.Contact == nil) {
+			t.Errorf("row %d: wrong contact validity", i)
+		}
+		if start, end := tags.ValueOffsets(i); int(end-start) != len(e.Tags) {
+			t.Errorf("row %d: wrong number of tags: want=%d got=%d", i, len(e.Tags), end-start)
+		}
+		start, end := labels.ValueOffsets(i)
+		if int(end-start) != len(e.Labels) {
+			t.Errorf("row %d: wrong number of labels: want=%d got=%d", i, len(e.Labels), end-start)
+		} else {
+			for j, label := range e.Labels {
+				k := int(start) + j
+				if labelValues.Value(k) != label {
+					t.Errorf("row %d: wrong label %d", i, j)
+				}
+			}
+		}
+		start, end = contacts.ValueOffsets(i)
+		if int(end-start) != len(e.Contacts) {
+			t.Errorf("row %d: wrong number of contacts: want=%d got=%d", i, len(e.Contacts), end-start)
+			continue
+		}
+		for j, c := range e.Contacts {
+			if got := contactNames.Value(int(start) + j); got != c.Name {
+				t.Errorf("row %d: wrong contact name %d: want=%q got=%q", i, j, c.Name, got)
+			}
+		}
+		if start, end := attrs.ValueOffsets(i); int(end-start) != len(e.Attrs) {
+			t.Errorf("row %d: wrong number of attributes: want=%d got=%d", i, len(e.Attrs), end-start)
+		}
+	}
+}
+
+func TestReadFileWriteTable(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	events := make([]event, 100)
+	for i := range events {
+		events[i] = newEvent(i)
+	}
+	schema := parquet.SchemaOf(event{})
+	f := writeEvents(t, schema, events, 30)
+
+	table, err := parquetarrow.ReadFile(f, mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer table.Release()
+
+	if n := table.NumRows(); n != int64(len(events)) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(events), n)
+	}
+	if n := len(table.Column(0).Data().Chunks()); n != len(f.RowGroups()) {
+		t.Errorf("wrong number of chunks: want=%d got=%d", len(f.RowGroups()), n)
+	}
+
+	buf := new(bytes.Buffer)
+	w := parquet.NewWriter(buf, schema)
+	n, err := parquetarrow.WriteTable(w, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(events)) {
+		t.Errorf("wrong number of rows written: want=%d got=%d", len(events), n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Events are compared after being read from parquet files, which does not
+	// preserve the difference between nil and empty slices or maps.
+	want := readEvents(t, f)
+	f, err = parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, got := range readEvents(t, f) {
+		if !reflect.DeepEqual(want[i], got) {
+			t.Errorf("row %d mismatch:\nwant: %+v\ngot:  %+v", i, want[i], got)
+		}
+	}
+}
+
+func TestWriteRecordSchemaMismatch(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	b := array.NewInt64Builder(mem)
+	defer b.Release()
+	b.AppendValues([]int64{1, 2, 3}, nil)
+	column := b.NewArray()
+	defer column.Release()
+
+	record := array.NewRecord(arrow.NewSchema([]arrow.Field{{Name: "name", Type: arrow.PrimitiveTypes.Int64}}, nil), []arrow.Array{column}, 3)
+	defer record.Release()
+
+	w := parquet.NewWriter(new(bytes.Buffer), parquet.SchemaOf(struct {
+		Name string `parquet:"name"`
+	}{}))
+	if _, err := parquetarrow.WriteRecord(w, record); err == nil {
+		t.Error("expected an error writing a record which does not match the schema")
+	}
+}
+
+func TestReadRowGroupBuffer(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	buffer := parquet.NewBuffer(parquet.SchemaOf(event{}))
+	for i := 0; i < 10; i++ {
+		if err := buffer.Write(newEvent(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	record, err := parquetarrow.ReadRowGroup(buffer, mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer record.Release()
+
+	schema, err := parquetarrow.SchemaOf(buffer.Schema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !record.Schema().Equal(schema) {
+		t.Errorf("schema mismatch:\nwant: %s\ngot:  %s", schema, record.Schema())
+	}
+	if n := record.NumRows(); n != 10 {
+		t.Errorf("wrong number of rows: want=10 got=%d", n)
+	}
+}
@@ -0,0 +1,551 @@
+package arrow
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/bitutil"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/segmentio/parquet-go"
+)
+
+// ReadRowGroup converts a parquet row group to an arrow record of the schema
+// returned by SchemaOf.
+//
+// The row group is read column by column: the values of each leaf column are
+// loaded from its pages, and the nesting of structs, lists, and maps is
+// reconstructed from their repetition and definition levels without assembling
+// parquet rows.
+//
+// Columns which are dictionary encoded in the schema, or whose pages are
+// dictionary encoded, are converted to arrow dictionary arrays. The schema of
+// the record may therefore differ from the one returned by SchemaOf for files
+// which do not report the dictionary encoding of their columns.
+//
+// The returned record is allocated with mem and must be released by the
+// caller.
+func ReadRowGroup(rowGroup parquet.RowGroup, mem memory.Allocator) (arrow.Record, error) {
+	columns, dictionary, err := readColumnChunks(rowGroup)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newConverter(rowGroup.Schema(), dictionary)
+	if err != nil {
+		return nil, err
+	}
+	return c.newRecord(columns, rowGroup.NumRows(), mem)
+}
+
+// ReadFile converts the row groups of a parquet file to an arrow table, each
+// row group being a chunk of the table columns. Columns are converted to arrow
+// dictionary arrays if the pages of the first row group are dictionary
+// encoded, see ReadRowGroup for details.
+//
+// The returned table is allocated with mem and must be released by the caller.
+func ReadFile(file *parquet.File, mem memory.Allocator) (arrow.Table, error) {
+	var c *converter
+	rowGroups := file.RowGroups()
+	records := make([]arrow.Record, 0, len(rowGroups))
+	defer func() {
+		for _, record := range records {
+			record.Release()
+		}
+	}()
+
+	for i, rowGroup := range rowGroups {
+		columns, dictionary, err := readColumnChunks(rowGroup)
+		if err != nil {
+			return nil, fmt.Errorf("reading row group %d: %w", i, err)
+		}
+		if c == nil {
+			if c, err = newConverter(file.Schema(), dictionary); err != nil {
+				return nil, err
+			}
+		}
+		record, err := c.newRecord(columns, rowGroup.NumRows(), mem)
+		if err != nil {
+			return nil, fmt.Errorf("reading row group %d: %w", i, err)
+		}
+		records = append(records, record)
+	}
+
+	if c == nil {
+		var err error
+		if c, err = newConverter(file.Schema(), nil); err != nil {
+			return nil, err
+		}
+	}
+	return array.NewTableFromRecords(c.schema, records), nil
+}
+
+const (
+	leafNode = iota
+	structNode
+	listNode
+	mapNode
+)
+
+// node is the mapping of a parquet node to an arrow field.
+type node struct {
+	kind int
+	// The definition level of the node when the arrow value is not null.
+	definitionLevel int
+	// For lists and maps, the repetition level of their repeated group and
+	// the definition level when they have at least one element.
+	repetitionLevel        int
+	elementDefinitionLevel int
+	// Indexes of the leaf columns of the node, the levels of the first column
+	// are used to determine the validity and length of arrow values.
+	columns  []int
+	children []*node
+	// Type of the arrow values, and of the values of dictionary arrays for
+	// dictionary encoded leaf columns.
+	dataType   arrow.DataType
+	valueType  arrow.DataType
+	dictionary bool
+}
+
+type converter struct {
+	schema *arrow.Schema
+	nodes  []*node
+}
+
+// newConverter constructs a converter for the parquet schema. Leaf columns are
+// converted to dictionary arrays if they are dictionary encoded in the schema
+// or if the value at their index in dictionary is true.
+func newConverter(schema *parquet.Schema, dictionary []bool) (*converter, error) {
+	c := new(converter)
+	fields := schema.Fields()
+	arrowFields := make([]arrow.Field, len(fields))
+	nc := &nodeConverter{dictionary: dictionary}
+
+	for i, f := range fields {
+		n, field, err := nc.convert(f.Name(), f, 0, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s → %w", f.Name(), err)
+		}
+		c.nodes = append(c.nodes, n)
+		arrowFields[i] = field
+	}
+
+	c.schema = arrow.NewSchema(arrowFields, nil)
+	return c, nil
+}
+
+type nodeConverter struct {
+	// Index of the next leaf column in the schema.
+	columnIndex int
+	dictionary  []bool
+}
+
+// convert returns the mapping of a parquet node with the given parent levels.
+func (c *nodeConverter) convert(name string, p parquet.Node, definitionLevel, repetitionLevel int) (*node, arrow.Field, error) {
+	field := arrow.Field{Name: name}
+	startColumnIndex := c.columnIndex
+
+	switch {
+	case p.Optional():
+		definitionLevel++
+		field.Nullable = true
+	case p.Repeated():
+		elem, elemField, err := c.convert("element", parquet.Required(p), definitionLevel+1, repetitionLevel+1)
+		if err != nil {
+			return nil, field, err
+		}
+		field.Type = arrow.ListOfField(elemField)
+		n := &node{
+			kind:                   listNode,
+			definitionLevel:        definitionLevel,
+			repetitionLevel:        repetitionLevel + 1,
+			elementDefinitionLevel: definitionLevel + 1,
+			columns:                elem.columns,
+			children:               []*node{elem},
+			dataType:               field.Type,
+		}
+		return n, field, nil
+	}
+
+	n := &node{definitionLevel: definitionLevel, repetitionLevel: repetitionLevel + 1, elementDefinitionLevel: definitionLevel + 1}
+
+	switch {
+	case p.Leaf():
+		t, err := arrowTypeOf(p.Type())
+		if err != nil {
+			return nil, field, err
+		}
+		n.kind, n.valueType, n.dataType = leafNode, t, t
+		dictionary := isDictionary(p) || (c.columnIndex < len(c.dictionary) && c.dictionary[c.columnIndex])
+		if dictionary && t.ID() != arrow.BOOL {
+			n.dictionary = true
+			n.dataType = &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int32, ValueType: t}
+		}
+		c.columnIndex++
+
+	case isList(p):
+		list := childByName(p, "list")
+		if list == nil || list.Leaf() || !list.Repeated() || childByName(list, "element") == nil {
+			return nil, field, fmt.Errorf("group of logical type LIST is not composed of a repeated .list.element")
+		}
+		elem, elemField, err := c.convert("element", childByName(list, "element"), n.elementDefinitionLevel, n.repetitionLevel)
+		if err != nil {
+			return nil, field, err
+		}
+		n.kind, n.children = listNode, []*node{elem}
+		n.dataType = arrow.ListOfField(elemField)
+
+	case isMap(p):
+		keyValue := childByName(p, "key_value")
+		if keyValue == nil || keyValue.Leaf() || !keyValue.Repeated() {
+			return nil, field, fmt.Errorf("group of logical type MAP is not composed of a repeated .key_value group")
+		}
+		keyNode, valueNode := childByName(keyValue, "key"), childByName(keyValue, "value")
+		if keyNode == nil || valueNode == nil || !keyNode.Required() {
+			return nil, field, fmt.Errorf("group of logical type MAP must have a required key and a value")
+		}
+		key, _, err := c.convert("key", keyNode, n.elementDefinitionLevel, n.repetitionLevel)
+		if err != nil {
+			return nil, field, fmt.Errorf("key → %w", err)
+		}
+		value, valueField, err := c.convert("value", valueNode, n.elementDefinitionLevel, n.repetitionLevel)
+		if err != nil {
+			return nil, field, fmt.Errorf("value → %w", err)
+		}
+		mapType := arrow.MapOf(key.dataType, value.dataType)
+		mapType.SetItemNullable(valueField.Nullable)
+		entries := &node{
+			kind:            structNode,
+			definitionLevel: n.elementDefinitionLevel,
+			children:        []*node{key, value},
+			dataType:        mapType.ValueType(),
+		}
+		n.kind, n.children, n.dataType = mapNode, []*node{entries}, mapType
+
+	default:
+		fields := p.Fields()
+		arrowFields := make([]arrow.Field, len(fields))
+		for i, f := range fields {
+			child, childField, err := c.convert(f.Name(), f, definitionLevel, repetitionLevel)
+			if err != nil {
+				return nil, field, fmt.Errorf("%s → %w", f.Name(), err)
+			}
+			n.children = append(n.children, child)
+			arrowFields[i] = childField
+		}
+		if len(fields) == 0 {
+			return nil, field, fmt.Errorf("empty groups cannot be converted to arrow structs")
+		}
+		n.kind, n.dataType = structNode, arrow.StructOf(arrowFields...)
+	}
+
+	for i := startColumnIndex; i < c.columnIndex; i++ {
+		n.columns = append(n.columns, i)
+	}
+	if n.kind == mapNode {
+		n.children[0].columns = n.columns
+	}
+
+	field.Type = n.dataType
+	return n, field, nil
+}
+
+func childByName(node parquet.Node, name string) parquet.Node {
+	for _, f := range node.Fields() {
+		if f.Name() == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func isList(node parquet.Node) bool {
+	lt := node.Type().LogicalType()
+	return lt != nil && lt.List != nil
+}
+
+func isMap(node parquet.Node) bool {
+	lt := node.Type().LogicalType()
+	return lt != nil && lt.Map != nil
+}
+
+// span is a range of values of a leaf column making an arrow value.
+type span struct{ begin, end int }
+
+// readColumnChunks reads the values of the column chunks of a row group, and
+// reports which of the column chunks have dictionary encoded pages.
+func readColumnChunks(rowGroup parquet.RowGroup) ([][]parquet.Value, []bool, error) {
+	columns := rowGroup.ColumnChunks()
+	values := make([][]parquet.Value, len(columns))
+	dictionary := make([]bool, len(columns))
+
+	for i, column := range columns {
+		v, dict, err := readColumnChunk(column)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading column %d: %w", i, err)
+		}
+		values[i], dictionary[i] = v, dict
+	}
+
+	return values, dictionary, nil
+}
+
+// newRecord converts the values of the leaf columns of a row group to an arrow
+// record.
+func (c *converter) newRecord(values [][]parquet.Value, numRows int64, mem memory.Allocator) (arrow.Record, error) {
+	slots := make([][]span, len(values))
+
+	for i, v := range values {
+		// Each row starts at a value with a repetition level of zero.
+		for j := range v {
+			if v[j].RepetitionLevel() == 0 {
+				if n := len(slots[i]); n > 0 {
+					slots[i][n-1].end = j
+				}
+				slots[i] = append(slots[i], span{begin: j, end: len(v)})
+			}
+		}
+	}
+
+	for i := range slots {
+		if int64(len(slots[i])) != numRows {
+			return nil, fmt.Errorf("column %d has %d rows but the row group has %d", i, len(slots[i]), numRows)
+		}
+	}
+
+	b := &recordBuilder{mem: mem, values: values}
+	arrays := make([]arrow.Array, 0, len(c.nodes))
+	defer func() {
+		for _, a := range arrays {
+			a.Release()
+		}
+	}()
+
+	for _, n := range c.nodes {
+		a, err := b.build(n, slots, int(numRows))
+		if err != nil {
+			return nil, err
+		}
+		arrays = append(arrays, a)
+	}
+
+	return array.NewRecord(c.schema, arrays, numRows), nil
+}
+
+func readColumnChunk(column parquet.ColumnChunk) (values []parquet.Value, dictionary bool, err error) {
+	pages := column.Pages()
+	defer pages.Close()
+
+	values = make([]parquet.Value, 0, column.NumValues())
+	for {
+		page, err := pages.ReadPage()
+		if err != nil {
+			if err == io.EOF {
+				return values, dictionary, nil
+			}
+			return values, dictionary, err
+		}
+		if page.Dictionary() != nil {
+			dictionary = true
+		}
+
+		reader := page.Values()
+		for {
+			if len(values) == cap(values) {
+				values = append(values, parquet.Value{})[:len(values)]
+			}
+			buffer := values[len(values):cap(values)]
+			n, err := reader.ReadValues(buffer)
+			// Page buffers may be reused once the next page is read, byte
+			// arrays are cloned so the values remain valid.
+			for i, v := range buffer[:n] {
+				switch v.Kind() {
+				case parquet.ByteArray, parquet.FixedLenByteArray, parquet.Int96:
+					buffer[i] = v.Clone()
+				}
+			}
+			values = values[:len(values)+n]
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return values, dictionary, err
+			}
+		}
+	}
+}
+
+type recordBuilder struct {
+	mem    memory.Allocator
+	values [][]parquet.Value
+}
+
+// build returns the arrow array of the node for the given slots, which are
+// indexed by leaf column and have one span per value of the array.
+func (b *recordBuilder) build(n *node, slots [][]span, length int) (arrow.Array, error) {
+	if n.kind == leafNode {
+		return b.buildLeaf(n, slots[n.columns[0]])
+	}
+
+	first := n.columns[0]
+	values := b.values[first]
+	validity := make([]byte, bitutil.BytesForBits(int64(length)))
+	nulls := 0
+	for i, s := range slots[first] {
+		if values[s.begin].DefinitionLevel() >= n.definitionLevel {
+			bitutil.SetBit(validity, i)
+		} else {
+			nulls++
+		}
+	}
+
+	var buffers []*memory.Buffer
+	if nulls > 0 {
+		buffers = append(buffers, memory.NewBufferBytes(validity))
+	} else {
+		buffers = append(buffers, nil)
+	}
+
+	if n.kind == structNode {
+		children := make([]arrow.ArrayData, 0, len(n.children))
+		defer func() {
+			for _, child := range children {
+				child.Release()
+			}
+		}()
+		for _, child := range n.children {
+			a, err := b.build(child, slots, length)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, a.Data())
+			a.Data().Retain()
+			a.Release()
+		}
+		data := array.NewData(n.dataType, length, buffers, children, nulls, 0)
+		defer data.Release()
+		return array.MakeFromData(data), nil
+	}
+
+	// Lists and maps split the spans of their leaf columns into one span per
+	// element; a new element starts at each value with the repetition level
+	// of the repeated group.
+	offsets := make([]int32, length+1)
+	elements := make([][]span, len(slots))
+	for _, column := range n.columns {
+		values := b.values[column]
+		elems := make([]span, 0, len(slots[column]))
+		for _, s := range slots[column] {
+			if values[s.begin].DefinitionLevel() < n.elementDefinitionLevel {
+				continue
+			}
+			elems = append(elems, span{begin: s.begin, end: s.end})
+			for j := s.begin + 1; j < s.end; j++ {
+				if values[j].RepetitionLevel() == n.repetitionLevel {
+					elems[len(elems)-1].end = j
+					elems = append(elems, span{begin: j, end: s.end})
+				}
+			}
+		}
+		elements[column] = elems
+	}
+
+	numElements := 0
+	for i, s := range slots[first] {
+		if values[s.begin].DefinitionLevel() >= n.elementDefinitionLevel {
+			numElements++
+			for j := s.begin + 1; j < s.end; j++ {
+				if values[j].RepetitionLevel() == n.repetitionLevel {
+					numElements++
+				}
+			}
+		}
+		offsets[i+1] = int32(numElements)
+	}
+
+	for _, column := range n.columns {
+		if len(elements[column]) != numElements {
+			return nil, fmt.Errorf("column %d has %d repeated values but column %d has %d", column, len(elements[column]), first, numElements)
+		}
+	}
+
+	child, err := b.build(n.children[0], elements, numElements)
+	if err != nil {
+		return nil, err
+	}
+	defer child.Release()
+
+	buffers = append(buffers, memory.NewBufferBytes(arrow.Int32Traits.CastToBytes(offsets)))
+	data := array.NewData(n.dataType, length, buffers, []arrow.ArrayData{child.Data()}, nulls, 0)
+	defer data.Release()
+	return array.MakeFromData(data), nil
+}
+
+func (b *recordBuilder) buildLeaf(n *node, slots []span) (arrow.Array, error) {
+	builder := array.NewBuilder(b.mem, n.valueType)
+	defer builder.Release()
+	builder.Reserve(len(slots))
+
+	values := b.values[n.columns[0]]
+	for _, s := range slots {
+		v := values[s.begin]
+		if v.DefinitionLevel() < n.definitionLevel {
+			builder.AppendNull()
+			continue
+		}
+
+		switch builder := builder.(type) {
+		case *array.BooleanBuilder:
+			builder.Append(v.Boolean())
+		case *array.Int8Builder:
+			builder.Append(int8(v.Int32()))
+		case *array.Int16Builder:
+			builder.Append(int16(v.Int32()))
+		case *array.Int32Builder:
+			builder.Append(v.Int32())
+		case *array.Int64Builder:
+			builder.Append(v.Int64())
+		case *array.Uint8Builder:
+			builder.Append(uint8(v.Uint32()))
+		case *array.Uint16Builder:
+			builder.Append(uint16(v.Uint32()))
+		case *array.Uint32Builder:
+			builder.Append(v.Uint32())
+		case *array.Uint64Builder:
+			builder.Append(v.Uint64())
+		case *array.Float32Builder:
+			builder.Append(v.Float())
+		case *array.Float64Builder:
+			builder.Append(v.Double())
+		case *array.Date32Builder:
+			builder.Append(arrow.Date32(v.Int32()))
+		case *array.Time32Builder:
+			builder.Append(arrow.Time32(v.Int32()))
+		case *array.Time64Builder:
+			builder.Append(arrow.Time64(v.Int64()))
+		case *array.TimestampBuilder:
+			builder.Append(arrow.Timestamp(v.Int64()))
+		case *array.StringBuilder:
+			builder.BinaryBuilder.Append(v.ByteArray())
+		case *array.BinaryBuilder:
+			builder.Append(v.ByteArray())
+		case *array.FixedSizeBinaryBuilder:
+			builder.Append(v.ByteArray())
+		default:
+			return nil, fmt.Errorf("unsupported arrow type %s", n.valueType)
+		}
+	}
+
+	a := builder.NewArray()
+	if !n.dictionary {
+		return a, nil
+	}
+	defer a.Release()
+
+	dict := array.NewDictionaryBuilder(b.mem, n.dataType.(*arrow.DictionaryType))
+	defer dict.Release()
+	if err := dict.AppendArray(a); err != nil {
+		return nil, err
+	}
+	return dict.NewArray(), nil
+}
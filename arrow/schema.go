@@ -0,0 +1,269 @@
+// Package arrow converts parquet row groups to arrow records, and arrow records
+// back to parquet rows.
+//
+// The package is a separate module so programs which do not use arrow do not
+// have to depend on it.
+package arrow
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// SchemaOf returns the arrow schema of records converted from row groups of the
+// parquet schema.
+//
+// The columns of the parquet schema are mapped to arrow types as follows:
+//
+//   - BOOLEAN, FLOAT, and DOUBLE columns are arrow booleans, float32 and float64
+//   - INT32 and INT64 columns are arrow integers of the bit width and sign of
+//     their INT logical type, or int32 and int64 if they have none
+//   - DATE, TIME, and TIMESTAMP columns are arrow values of the same types
+//     and units, timestamps adjusted to UTC have the "UTC" time zone
+//   - BYTE_ARRAY columns are arrow binary values, or strings if they have the
+//     STRING, ENUM, or JSON logical types
+//   - FIXED_LEN_BYTE_ARRAY and INT96 columns are arrow fixed size binary
+//     values of their length
+//   - columns of leaf nodes with a dictionary encoding are arrow dictionaries
+//     with int32 indexes
+//
+// Groups are mapped to arrow structs, groups of the LIST logical type to arrow
+// lists, groups of the MAP logical type to arrow maps, and repeated fields to
+// arrow lists of non-nullable elements. Optional fields are nullable.
+//
+// The function returns an error if the schema contains columns which cannot be
+// converted, such as LIST groups which do not follow the standard layout.
+func SchemaOf(schema *parquet.Schema) (*arrow.Schema, error) {
+	c, err := newConverter(schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.schema, nil
+}
+
+// ParquetSchemaOf returns a parquet schema of the given name for the arrow
+// schema, mapping arrow types to parquet columns the reverse way of SchemaOf.
+//
+// Arrow timestamps are mapped to TIMESTAMP columns adjusted to UTC, dictionary
+// types are mapped to dictionary encoded columns, and nullable fields are
+// optional. Lists and maps use the standard layouts of the LIST and MAP
+// logical types.
+//
+// The function returns an error if the schema contains types which have no
+// parquet equivalent, for example timestamps in seconds.
+func ParquetSchemaOf(name string, schema *arrow.Schema) (*parquet.Schema, error) {
+	root, err := parquetGroupOf(schema.Fields())
+	if err != nil {
+		return nil, err
+	}
+	return parquet.NewSchema(name, root), nil
+}
+
+func parquetGroupOf(fields []arrow.Field) (parquet.Group, error) {
+	group := make(parquet.Group, len(fields))
+	for _, f := range fields {
+		node, err := parquetNodeOf(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s → %w", f.Name, err)
+		}
+		if f.Nullable {
+			node = parquet.Optional(node)
+		}
+		if _, exists := group[f.Name]; exists {
+			return nil, fmt.Errorf("duplicate field %q", f.Name)
+		}
+		group[f.Name] = node
+	}
+	return group, nil
+}
+
+func parquetNodeOf(t arrow.DataType) (parquet.Node, error) {
+	switch t := t.(type) {
+	case *arrow.BooleanType:
+		return parquet.Leaf(parquet.BooleanType), nil
+	case *arrow.Int8Type:
+		return parquet.Int(8), nil
+	case *arrow.Int16Type:
+		return parquet.Int(16), nil
+	case *arrow.Int32Type:
+		return parquet.Int(32), nil
+	case *arrow.Int64Type:
+		return parquet.Int(64), nil
+	case *arrow.Uint8Type:
+		return parquet.Uint(8), nil
+	case *arrow.Uint16Type:
+		return parquet.Uint(16), nil
+	case *arrow.Uint32Type:
+		return parquet.Uint(32), nil
+	case *arrow.Uint64Type:
+		return parquet.Uint(64), nil
+	case *arrow.Float32Type:
+		return parquet.Leaf(parquet.FloatType), nil
+	case *arrow.Float64Type:
+		return parquet.Leaf(parquet.DoubleType), nil
+	case *arrow.StringType:
+		return parquet.String(), nil
+	case *arrow.BinaryType:
+		return parquet.Leaf(parquet.ByteArrayType), nil
+	case *arrow.FixedSizeBinaryType:
+		return parquet.Leaf(parquet.FixedLenByteArrayType(t.ByteWidth)), nil
+	case *arrow.Date32Type:
+		return parquet.Date(), nil
+	case *arrow.Time32Type:
+		if t.Unit == arrow.Millisecond {
+			return parquet.Time(parquet.Millisecond), nil
+		}
+	case *arrow.Time64Type:
+		if unit := parquetTimeUnitOf(t.Unit); unit != nil {
+			return parquet.Time(unit), nil
+		}
+	case *arrow.TimestampType:
+		if unit := parquetTimeUnitOf(t.Unit); unit != nil {
+			return parquet.Timestamp(unit), nil
+		}
+	case *arrow.DictionaryType:
+		node, err := parquetNodeOf(t.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		return parquet.Encoded(node, &parquet.RLEDictionary), nil
+	case *arrow.StructType:
+		return parquetGroupOf(t.Fields())
+	case *arrow.MapType:
+		key, err := parquetNodeOf(t.KeyType())
+		if err != nil {
+			return nil, fmt.Errorf("key → %w", err)
+		}
+		value, err := parquetNodeOf(t.ItemType())
+		if err != nil {
+			return nil, fmt.Errorf("value → %w", err)
+		}
+		if t.ItemField().Nullable {
+			value = parquet.Optional(value)
+		}
+		return parquet.Map(key, value), nil
+	case *arrow.ListType:
+		elem, err := parquetNodeOf(t.Elem())
+		if err != nil {
+			return nil, fmt.Errorf("element → %w", err)
+		}
+		if t.ElemField().Nullable {
+			elem = parquet.Optional(elem)
+		}
+		return parquet.List(elem), nil
+	}
+	return nil, fmt.Errorf("arrow type %s cannot be converted to a parquet column", t)
+}
+
+func parquetTimeUnitOf(unit arrow.TimeUnit) parquet.TimeUnit {
+	switch unit {
+	case arrow.Millisecond:
+		return parquet.Millisecond
+	case arrow.Microsecond:
+		return parquet.Microsecond
+	case arrow.Nanosecond:
+		return parquet.Nanosecond
+	default:
+		return nil
+	}
+}
+
+func arrowTimeUnitOf(unit *format.TimeUnit) arrow.TimeUnit {
+	switch {
+	case unit.Millis != nil:
+		return arrow.Millisecond
+	case unit.Micros != nil:
+		return arrow.Microsecond
+	default:
+		return arrow.Nanosecond
+	}
+}
+
+// arrowTypeOf returns the arrow type of values of a parquet leaf column.
+func arrowTypeOf(typ parquet.Type) (arrow.DataType, error) {
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.UTF8 != nil, lt.Enum != nil, lt.Json != nil:
+			if typ.Kind() == parquet.ByteArray {
+				return arrow.BinaryTypes.String, nil
+			}
+		case lt.Date != nil:
+			return arrow.FixedWidthTypes.Date32, nil
+		case lt.Time != nil:
+			unit := arrowTimeUnitOf(&lt.Time.Unit)
+			if unit == arrow.Millisecond {
+				return &arrow.Time32Type{Unit: unit}, nil
+			}
+			return &arrow.Time64Type{Unit: unit}, nil
+		case lt.Timestamp != nil:
+			t := &arrow.TimestampType{Unit: arrowTimeUnitOf(&lt.Timestamp.Unit)}
+			if lt.Timestamp.IsAdjustedToUTC {
+				t.TimeZone = "UTC"
+			}
+			return t, nil
+		case lt.Integer != nil:
+			return arrowIntegerTypeOf(int(lt.Integer.BitWidth), lt.Integer.IsSigned)
+		}
+	}
+
+	switch typ.Kind() {
+	case parquet.Boolean:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case parquet.Int32:
+		return arrow.PrimitiveTypes.Int32, nil
+	case parquet.Int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case parquet.Int96:
+		return &arrow.FixedSizeBinaryType{ByteWidth: 12}, nil
+	case parquet.Float:
+		return arrow.PrimitiveTypes.Float32, nil
+	case parquet.Double:
+		return arrow.PrimitiveTypes.Float64, nil
+	case parquet.ByteArray:
+		return arrow.BinaryTypes.Binary, nil
+	case parquet.FixedLenByteArray:
+		return &arrow.FixedSizeBinaryType{ByteWidth: typ.Length()}, nil
+	default:
+		return nil, fmt.Errorf("parquet column of type %s cannot be converted to an arrow type", typ)
+	}
+}
+
+func arrowIntegerTypeOf(bitWidth int, signed bool) (arrow.DataType, error) {
+	switch bitWidth {
+	case 8:
+		if signed {
+			return arrow.PrimitiveTypes.Int8, nil
+		}
+		return arrow.PrimitiveTypes.Uint8, nil
+	case 16:
+		if signed {
+			return arrow.PrimitiveTypes.Int16, nil
+		}
+		return arrow.PrimitiveTypes.Uint16, nil
+	case 32:
+		if signed {
+			return arrow.PrimitiveTypes.Int32, nil
+		}
+		return arrow.PrimitiveTypes.Uint32, nil
+	case 64:
+		if signed {
+			return arrow.PrimitiveTypes.Int64, nil
+		}
+		return arrow.PrimitiveTypes.Uint64, nil
+	default:
+		return nil, fmt.Errorf("invalid integer bit width: %d", bitWidth)
+	}
+}
+
+func isDictionary(node parquet.Node) bool {
+	if enc := node.Encoding(); enc != nil {
+		switch enc.Encoding() {
+		case format.RLEDictionary, format.PlainDictionary:
+			return true
+		}
+	}
+	return false
+}
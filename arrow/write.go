@@ -0,0 +1,162 @@
+package arrow
+
+import (
+	"fmt"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/segmentio/parquet-go"
+)
+
+// writeBatchSize is the number of rows buffered by WriteRecord before writing
+// them to the parquet writer.
+const writeBatchSize = 1024
+
+// WriteRecord writes the rows of an arrow record to a parquet writer, returning
+// the number of rows written.
+//
+// Fields of the record are matched by name with the fields of the writer's
+// schema, which may be created from the arrow schema with ParquetSchemaOf.
+// Arrow structs, lists, and maps are written to parquet groups, LIST, and MAP
+// groups, and the values of dictionary arrays are written in place of their
+// indexes. Values of temporal types are written as the integers of their
+// arrow representation.
+//
+// The function returns an error if the record does not match the schema.
+func WriteRecord(w parquet.RowWriterWithSchema, record arrow.Record) (int64, error) {
+	schema := w.Schema()
+	fields := record.Schema().Fields()
+	columns := record.Columns()
+	numRows := int(record.NumRows())
+
+	values := make(map[string]interface{}, len(fields))
+	rows := make([]parquet.Row, 0, writeBatchSize)
+	buffer := parquet.Row(nil)
+	written := int64(0)
+
+	flush := func() error {
+		n, err := w.WriteRows(rows)
+		written += int64(n)
+		rows, buffer = rows[:0], buffer[:0]
+		return err
+	}
+
+	for i := 0; i < numRows; i++ {
+		for j, f := range fields {
+			values[f.Name] = valueOf(columns[j], i)
+		}
+
+		start := len(buffer)
+		row, err := schema.DeconstructMap(buffer, values)
+		if err != nil {
+			return written, fmt.Errorf("row %d: %w", i, err)
+		}
+		buffer = row
+		rows = append(rows, buffer[start:len(buffer):len(buffer)])
+
+		if len(rows) == cap(rows) {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	if len(rows) > 0 {
+		if err := flush(); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// WriteTable writes the rows of an arrow table to a parquet writer, returning
+// the number of rows written. The table is written as a sequence of records,
+// see WriteRecord for details.
+func WriteTable(w parquet.RowWriterWithSchema, table arrow.Table) (int64, error) {
+	reader := array.NewTableReader(table, writeBatchSize)
+	defer reader.Release()
+	written := int64(0)
+
+	for reader.Next() {
+		n, err := WriteRecord(w, reader.Record())
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, reader.Err()
+}
+
+// valueOf returns the value at index i of an arrow array in the generic
+// representation of parquet.Schema.DeconstructMap.
+func valueOf(a arrow.Array, i int) interface{} {
+	if a.IsNull(i) {
+		return nil
+	}
+
+	switch a := a.(type) {
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Int8:
+		return a.Value(i)
+	case *array.Int16:
+		return a.Value(i)
+	case *array.Int32:
+		return a.Value(i)
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return a.Value(i)
+	case *array.Uint16:
+		return a.Value(i)
+	case *array.Uint32:
+		return a.Value(i)
+	case *array.Uint64:
+		return a.Value(i)
+	case *array.Float32:
+		return a.Value(i)
+	case *array.Float64:
+		return a.Value(i)
+	case *array.Date32:
+		return int32(a.Value(i))
+	case *array.Time32:
+		return int32(a.Value(i))
+	case *array.Time64:
+		return int64(a.Value(i))
+	case *array.Timestamp:
+		return int64(a.Value(i))
+	case *array.String:
+		return a.Value(i)
+	case *array.Binary:
+		return a.Value(i)
+	case *array.FixedSizeBinary:
+		return a.Value(i)
+	case *array.Dictionary:
+		return valueOf(a.Dictionary(), a.GetValueIndex(i))
+	case *array.Struct:
+		fields := a.DataType().(*arrow.StructType).Fields()
+		group := make(map[string]interface{}, len(fields))
+		for j, f := range fields {
+			group[f.Name] = valueOf(a.Field(j), i)
+		}
+		return group
+	case *array.Map:
+		start, end := a.ValueOffsets(i)
+		return listValueOf(a.ListValues(), start, end)
+	case *array.List:
+		start, end := a.ValueOffsets(i)
+		return listValueOf(a.ListValues(), start, end)
+	default:
+		// Arrays of types that cannot be converted produce values which are
+		// rejected by DeconstructMap with an error naming their type.
+		return a
+	}
+}
+
+func listValueOf(values arrow.Array, start, end int64) []interface{} {
+	list := make([]interface{}, end-start)
+	for i := range list {
+		list[i] = valueOf(values, int(start)+i)
+	}
+	return list
+}
@@ -0,0 +1,741 @@
+package parquet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// AvroCodec converts records of an Avro schema between the Avro binary
+// encoding and parquet rows, which allows writing Avro records to parquet
+// files without depending on an Avro library, and reading them back:
+//
+//	codec, err := parquet.NewAvroCodec(avroSchema)
+//	if err != nil {
+//		...
+//	}
+//	writer := parquet.NewWriter(output, codec.Schema())
+//	row := parquet.Row{}
+//	for _, record := range records {
+//		row, err = codec.Decode(row[:0], record)
+//		if err != nil {
+//			...
+//		}
+//		if _, err := writer.WriteRows([]parquet.Row{row}); err != nil {
+//			...
+//		}
+//	}
+//
+// The records are the binary encoding of single Avro values, without the
+// framing of object container files; messages of the Confluent wire format
+// must have their 5 bytes header removed.
+//
+// The Avro schema must be a record, which is mapped to a parquet schema of the
+// same name where:
+//
+//   - records are groups, arrays are lists, and maps are maps with string keys
+//   - unions of null and another type are optional columns of that type, other
+//     unions are not supported
+//   - booleans, ints, longs, floats, and doubles are columns of the matching
+//     physical types, strings are STRING columns, and bytes are byte arrays
+//   - enums are ENUM columns holding the symbols, fixed are fixed length byte
+//     arrays
+//
+// The Avro logical types are mapped to the parquet logical types: date to
+// DATE, time-millis and time-micros to TIME, timestamp-millis,
+// timestamp-micros, and timestamp-nanos to TIMESTAMP, uuid to UUID, and
+// decimal to DECIMAL. Decimals of the bytes type are stored as fixed length
+// byte arrays large enough for their precision. Other logical types are
+// ignored, as mandated by the Avro specification.
+type AvroCodec struct {
+	schema *Schema
+	record *avroType
+}
+
+// NewAvroCodec constructs a codec for records of the Avro schema passed as
+// argument in its JSON representation.
+//
+// The function returns an error if the Avro schema is invalid, or if it uses
+// features which cannot be mapped to a parquet schema, such as recursive types
+// or unions of several non-null types.
+func NewAvroCodec(avroSchema []byte) (*AvroCodec, error) {
+	var v interface{}
+	if err := json.Unmarshal(avroSchema, &v); err != nil {
+		return nil, fmt.Errorf("decoding avro schema: %w", err)
+	}
+	p := avroParser{names: make(map[string]*avroType)}
+	record, err := p.parse(v, "")
+	if err != nil {
+		return nil, fmt.Errorf("parsing avro schema: %w", err)
+	}
+	if record.kind != "record" {
+		return nil, fmt.Errorf("parsing avro schema: the schema must be a record but it is of type %s", record.kind)
+	}
+	node, err := record.node()
+	if err != nil {
+		return nil, fmt.Errorf("mapping avro schema %s to parquet: %w", record.name, err)
+	}
+	return &AvroCodec{schema: NewSchema(avroShortName(record.name), node), record: record}, nil
+}
+
+// Schema returns the parquet schema that the Avro schema was mapped to.
+func (c *AvroCodec) Schema() *Schema { return c.schema }
+
+// Decode decodes a record in the Avro binary encoding and appends it as a
+// parquet row to row. The row does not retain references to the record.
+func (c *AvroCodec) Decode(row Row, record []byte) (Row, error) {
+	d := avroDecoder{data: record}
+	v, err := c.record.decode(&d)
+	if err != nil {
+		return row, fmt.Errorf("decoding avro record: %w", err)
+	}
+	if len(d.data) > 0 {
+		return row, fmt.Errorf("decoding avro record: %d bytes remain after the end of the record", len(d.data))
+	}
+	return c.schema.DeconstructMap(row, v.(map[string]interface{}))
+}
+
+// Encode appends the Avro binary encoding of a parquet row to record.
+func (c *AvroCodec) Encode(record []byte, row Row) ([]byte, error) {
+	v, err := c.schema.ReconstructMap(row)
+	if err != nil {
+		return record, err
+	}
+	record, err = c.record.encode(record, v)
+	if err != nil {
+		return record, fmt.Errorf("encoding avro record: %w", err)
+	}
+	return record, nil
+}
+
+// avroType is the representation of a parsed Avro schema. The values of the
+// types are converted to and from the generic representation of
+// Schema.DeconstructMap and Schema.ReconstructMap.
+type avroType struct {
+	kind        string
+	logicalType string
+	name        string
+	// Attributes of decimal logical types.
+	precision int
+	scale     int
+	// Size of fixed types, or of the parquet column of bytes decimals.
+	size    int
+	fields  []avroField
+	symbols []string
+	index   map[string]int
+	items   *avroType
+	values  *avroType
+	union   []*avroType
+	// Set while mapping a record to a parquet node to detect recursive types.
+	mapping bool
+}
+
+type avroField struct {
+	name string
+	typ  *avroType
+}
+
+type avroParser struct {
+	names map[string]*avroType
+}
+
+func (p *avroParser) parse(v interface{}, namespace string) (*avroType, error) {
+	switch s := v.(type) {
+	case string:
+		if avroIsPrimitive(s) {
+			return &avroType{kind: s}, nil
+		}
+		if t := p.lookup(s, namespace); t != nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", s)
+
+	case []interface{}:
+		t := &avroType{kind: "union", union: make([]*avroType, len(s))}
+		for i, branch := range s {
+			var err error
+			if t.union[i], err = p.parse(branch, namespace); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+
+	case map[string]interface{}:
+		kind, ok := s["type"].(string)
+		if !ok {
+			return p.parse(s["type"], namespace)
+		}
+
+		var t *avroType
+		switch kind {
+		case "record", "error", "enum", "fixed":
+			name, err := p.define(s, namespace)
+			if err != nil {
+				return nil, err
+			}
+			t = &avroType{kind: kind, name: name}
+			p.names[name] = t
+			if kind == "error" {
+				t.kind = "record"
+			}
+			if i := strings.LastIndexByte(name, '.'); i >= 0 {
+				namespace = name[:i]
+			} else {
+				namespace = ""
+			}
+		case "array", "map":
+			t = &avroType{kind: kind}
+		default:
+			if !avroIsPrimitive(kind) {
+				return p.parse(kind, namespace)
+			}
+			t = &avroType{kind: kind}
+		}
+
+		switch t.kind {
+		case "record":
+			fields, _ := s["fields"].([]interface{})
+			names := make(map[string]struct{}, len(fields))
+			for _, f := range fields {
+				field, _ := f.(map[string]interface{})
+				name, _ := field["name"].(string)
+				if name == "" {
+					return nil, fmt.Errorf("record %s has a field with no name", t.name)
+				}
+				if _, exists := names[name]; exists {
+					return nil, fmt.Errorf("record %s has duplicate field %q", t.name, name)
+				}
+				names[name] = struct{}{}
+				typ, err := p.parse(field["type"], namespace)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %w", t.name, name, err)
+				}
+				t.fields = append(t.fields, avroField{name: name, typ: typ})
+			}
+		case "enum":
+			symbols, _ := s["symbols"].([]interface{})
+			t.index = make(map[string]int, len(symbols))
+			for i, symbol := range symbols {
+				name, _ := symbol.(string)
+				t.symbols = append(t.symbols, name)
+				t.index[name] = i
+			}
+		case "fixed":
+			size, _ := s["size"].(float64)
+			if size < 0 || size != math.Trunc(size) {
+				return nil, fmt.Errorf("fixed %s has invalid size %v", t.name, s["size"])
+			}
+			t.size = int(size)
+		case "array":
+			items, err := p.parse(s["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			t.items = items
+		case "map":
+			values, err := p.parse(s["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			t.values = values
+		}
+
+		t.logicalType, _ = s["logicalType"].(string)
+		if t.logicalType == "decimal" {
+			precision, _ := s["precision"].(float64)
+			scale, _ := s["scale"].(float64)
+			t.precision, t.scale = int(precision), int(scale)
+			if t.precision <= 0 || t.scale < 0 || t.scale > t.precision {
+				// Invalid decimals are ignored like unknown logical types.
+				t.logicalType = ""
+			} else if t.kind == "bytes" {
+				t.size = avroDecimalSize(t.precision)
+			}
+		}
+		return t, nil
+
+	default:
+		return nil, fmt.Errorf("invalid type definition: %v", v)
+	}
+}
+
+// define returns the full name of the named type defined by s.
+func (p *avroParser) define(s map[string]interface{}, namespace string) (string, error) {
+	name, _ := s["name"].(string)
+	if name == "" {
+		return "", fmt.Errorf("named type of kind %s has no name", s["type"])
+	}
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+	if !strings.Contains(name, ".") && namespace != "" {
+		name = namespace + "." + name
+	}
+	if _, exists := p.names[name]; exists {
+		return "", fmt.Errorf("type %s is defined more than once", name)
+	}
+	return name, nil
+}
+
+func (p *avroParser) lookup(name, namespace string) *avroType {
+	if namespace != "" && !strings.Contains(name, ".") {
+		if t := p.names[namespace+"."+name]; t != nil {
+			return t
+		}
+	}
+	return p.names[name]
+}
+
+func avroIsPrimitive(kind string) bool {
+	switch kind {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+func avroShortName(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// avroDecimalSize returns the size of the two's complement representation of
+// unscaled decimal values of the given precision.
+func avroDecimalSize(precision int) int {
+	return int(math.Ceil((float64(precision)*math.Log2(10) + 1) / 8))
+}
+
+// nonNullBranch returns the branch of unions of null and another type, and
+// whether the union contains null.
+func (t *avroType) nonNullBranch() (branch int, optional bool, err error) {
+	branch = -1
+	for i, b := range t.union {
+		switch {
+		case b.kind == "null":
+			optional = true
+		case branch >= 0:
+			return -1, false, fmt.Errorf("unions of several non-null types are not supported")
+		default:
+			branch = i
+		}
+	}
+	if branch < 0 {
+		return -1, false, fmt.Errorf("unions of only null are not supported")
+	}
+	return branch, optional, nil
+}
+
+func (t *avroType) node() (Node, error) {
+	switch t.kind {
+	case "boolean":
+		return Leaf(BooleanType), nil
+	case "int":
+		switch t.logicalType {
+		case "date":
+			return Date(), nil
+		case "time-millis":
+			return Time(Millisecond), nil
+		}
+		return Int(32), nil
+	case "long":
+		switch t.logicalType {
+		case "time-micros":
+			return Time(Microsecond), nil
+		case "timestamp-millis":
+			return Timestamp(Millisecond), nil
+		case "timestamp-micros":
+			return Timestamp(Microsecond), nil
+		case "timestamp-nanos":
+			return Timestamp(Nanosecond), nil
+		}
+		return Int(64), nil
+	case "float":
+		return Leaf(FloatType), nil
+	case "double":
+		return Leaf(DoubleType), nil
+	case "bytes":
+		if t.logicalType == "decimal" {
+			return Decimal(t.scale, t.precision, FixedLenByteArrayType(t.size)), nil
+		}
+		return Leaf(ByteArrayType), nil
+	case "string":
+		if t.logicalType == "uuid" {
+			return UUID(), nil
+		}
+		return String(), nil
+	case "enum":
+		return Enum(), nil
+	case "fixed":
+		switch {
+		case t.logicalType == "decimal":
+			return Decimal(t.scale, t.precision, FixedLenByteArrayType(t.size)), nil
+		case t.logicalType == "uuid" && t.size == 16:
+			return UUID(), nil
+		}
+		return Leaf(FixedLenByteArrayType(t.size)), nil
+	case "array":
+		items, err := t.items.node()
+		if err != nil {
+			return nil, fmt.Errorf("array items: %w", err)
+		}
+		return List(items), nil
+	case "map":
+		values, err := t.values.node()
+		if err != nil {
+			return nil, fmt.Errorf("map values: %w", err)
+		}
+		return Map(String(), values), nil
+	case "union":
+		branch, optional, err := t.nonNullBranch()
+		if err != nil {
+			return nil, err
+		}
+		node, err := t.union[branch].node()
+		if err != nil || !optional {
+			return node, err
+		}
+		return Optional(node), nil
+	case "record":
+		if t.mapping {
+			return nil, fmt.Errorf("recursive record %s is not supported", t.name)
+		}
+		t.mapping = true
+		defer func() { t.mapping = false }()
+		if len(t.fields) == 0 {
+			return nil, fmt.Errorf("record %s has no fields", t.name)
+		}
+		group := make(Group, len(t.fields))
+		for _, field := range t.fields {
+			node, err := field.typ.node()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.name, err)
+			}
+			group[field.name] = node
+		}
+		return group, nil
+	default:
+		return nil, fmt.Errorf("type %s is only supported in unions", t.kind)
+	}
+}
+
+type avroDecoder struct {
+	data []byte
+}
+
+func (d *avroDecoder) long() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		if n == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, fmt.Errorf("invalid variable-length integer")
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *avroDecoder) read(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+func (d *avroDecoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > int64(len(d.data)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return d.read(int(n))
+}
+
+// blocks calls f for each item of the blocks of arrays and maps.
+func (d *avroDecoder) blocks(f func() error) error {
+	for {
+		n, err := d.long()
+		if err != nil || n == 0 {
+			return err
+		}
+		if n < 0 {
+			// Negative counts are followed by the size of the block in bytes.
+			n = -n
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		for ; n > 0; n-- {
+			if err := f(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t *avroType) decode(d *avroDecoder) (interface{}, error) {
+	switch t.kind {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := d.read(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+	case "int":
+		v, err := d.long()
+		if err == nil && (v < math.MinInt32 || v > math.MaxInt32) {
+			err = fmt.Errorf("int value out of range: %d", v)
+		}
+		return int32(v), err
+	case "long":
+		return d.long()
+	case "float":
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case "double":
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case "bytes":
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if t.logicalType == "decimal" {
+			return avroSignExtend(b, t.size)
+		}
+		return copyBytes(b), nil
+	case "string":
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if t.logicalType == "uuid" {
+			return uuid.ParseBytes(b)
+		}
+		return string(b), nil
+	case "enum":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(t.symbols)) {
+			return nil, fmt.Errorf("enum index out of range: %d", i)
+		}
+		return t.symbols[i], nil
+	case "fixed":
+		b, err := d.read(t.size)
+		return copyBytes(b), err
+	case "array":
+		items := []interface{}{}
+		err := d.blocks(func() error {
+			item, err := t.items.decode(d)
+			items = append(items, item)
+			return err
+		})
+		return items, err
+	case "map":
+		entries := []interface{}{}
+		err := d.blocks(func() error {
+			key, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			value, err := t.values.decode(d)
+			entries = append(entries, map[string]interface{}{"key": string(key), "value": value})
+			return err
+		})
+		return entries, err
+	case "union":
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(t.union)) {
+			return nil, fmt.Errorf("union index out of range: %d", i)
+		}
+		return t.union[i].decode(d)
+	case "record":
+		record := make(map[string]interface{}, len(t.fields))
+		for _, field := range t.fields {
+			v, err := field.typ.decode(d)
+			if err != nil {
+				return nil, fmt.Errorf("%s → %w", field.name, err)
+			}
+			record[field.name] = v
+		}
+		return record, nil
+	default:
+		return nil, fmt.Errorf("cannot decode avro values of type %s", t.kind)
+	}
+}
+
+// avroSignExtend converts the big-endian two's complement integer b to a
+// representation of the given size.
+func avroSignExtend(b []byte, size int) ([]byte, error) {
+	if len(b) > size {
+		return nil, fmt.Errorf("decimal value of %d bytes exceeds the precision of its type", len(b))
+	}
+	ext := make([]byte, size)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		for i := range ext {
+			ext[i] = 0xFF
+		}
+	}
+	copy(ext[size-len(b):], b)
+	return ext, nil
+}
+
+// avroTrimSignExtension returns the minimal representation of the big-endian
+// two's complement integer b, which is what Avro writers produce for decimals.
+func avroTrimSignExtension(b []byte) []byte {
+	for len(b) > 1 && ((b[0] == 0x00 && b[1]&0x80 == 0) || (b[0] == 0xFF && b[1]&0x80 != 0)) {
+		b = b[1:]
+	}
+	return b
+}
+
+func appendAvroLong(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
+
+func appendAvroBytes(b []byte, data []byte) []byte {
+	return append(appendAvroLong(b, int64(len(data))), data...)
+}
+
+func (t *avroType) encode(b []byte, v interface{}) ([]byte, error) {
+	switch t.kind {
+	case "null":
+		return b, nil
+	case "boolean":
+		if x, ok := v.(bool); ok {
+			if x {
+				return append(b, 1), nil
+			}
+			return append(b, 0), nil
+		}
+	case "int":
+		if x, ok := v.(int32); ok {
+			return appendAvroLong(b, int64(x)), nil
+		}
+	case "long":
+		if x, ok := v.(int64); ok {
+			return appendAvroLong(b, x), nil
+		}
+	case "float":
+		if x, ok := v.(float32); ok {
+			var buf [4]byte
+			binary.LittleEndian.PutUint32(buf[:], math.Float32bits(x))
+			return append(b, buf[:]...), nil
+		}
+	case "double":
+		if x, ok := v.(float64); ok {
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(x))
+			return append(b, buf[:]...), nil
+		}
+	case "bytes":
+		if x, ok := v.([]byte); ok {
+			if t.logicalType == "decimal" {
+				x = avroTrimSignExtension(x)
+			}
+			return appendAvroBytes(b, x), nil
+		}
+	case "string":
+		switch x := v.(type) {
+		case string:
+			return appendAvroBytes(b, []byte(x)), nil
+		case uuid.UUID:
+			return appendAvroBytes(b, []byte(x.String())), nil
+		}
+	case "enum":
+		if x, ok := v.(string); ok {
+			i, ok := t.index[x]
+			if !ok {
+				return b, fmt.Errorf("%q is not a symbol of enum %s", x, t.name)
+			}
+			return appendAvroLong(b, int64(i)), nil
+		}
+	case "fixed":
+		switch x := v.(type) {
+		case []byte:
+			if len(x) == t.size {
+				return append(b, x...), nil
+			}
+		case uuid.UUID:
+			if t.size == len(x) {
+				return append(b, x[:]...), nil
+			}
+		}
+	case "array":
+		if items, ok := v.([]interface{}); ok || v == nil {
+			if len(items) > 0 {
+				b = appendAvroLong(b, int64(len(items)))
+				for _, item := range items {
+					var err error
+					if b, err = t.items.encode(b, item); err != nil {
+						return b, err
+					}
+				}
+			}
+			return append(b, 0), nil
+		}
+	case "map":
+		if entries, ok := v.([]interface{}); ok || v == nil {
+			if len(entries) > 0 {
+				b = appendAvroLong(b, int64(len(entries)))
+				for _, entry := range entries {
+					kv, _ := entry.(map[string]interface{})
+					key, ok := kv["key"].(string)
+					if !ok {
+						return b, fmt.Errorf("cannot encode map key of type %T", kv["key"])
+					}
+					var err error
+					if b, err = t.values.encode(appendAvroBytes(b, []byte(key)), kv["value"]); err != nil {
+						return b, fmt.Errorf("%s → %w", key, err)
+					}
+				}
+			}
+			return append(b, 0), nil
+		}
+	case "union":
+		branch, _, err := t.nonNullBranch()
+		if err != nil {
+			return b, err
+		}
+		if v == nil {
+			for i, typ := range t.union {
+				if typ.kind == "null" {
+					return appendAvroLong(b, int64(i)), nil
+				}
+			}
+		}
+		return t.union[branch].encode(appendAvroLong(b, int64(branch)), v)
+	case "record":
+		if record, ok := v.(map[string]interface{}); ok {
+			for _, field := range t.fields {
+				var err error
+				if b, err = field.typ.encode(b, record[field.name]); err != nil {
+					return b, fmt.Errorf("%s → %w", field.name, err)
+				}
+			}
+			return b, nil
+		}
+	}
+	return b, fmt.Errorf("cannot encode value of type %T as avro %s", v, t.kind)
+}
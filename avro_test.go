@@ -0,0 +1,223 @@
+package parquet_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+const avroEventSchema = `{
+	"type": "record",
+	"name": "Event",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": "string"},
+		{"name": "email", "type": ["null", "string"], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "attrs", "type": {"type": "map", "values": "int"}},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["CLICK", "VIEW"]}},
+		{"name": "source", "type": {"type": "record", "name": "Source", "fields": [
+			{"name": "host", "type": "string"},
+			{"name": "port", "type": "int"}
+		]}},
+		{"name": "backup", "type": ["null", "Source"]},
+		{"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "day", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "uuid", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "price", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}},
+		{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+		{"name": "score", "type": "double"},
+		{"name": "ratio", "type": "float"},
+		{"name": "ok", "type": "boolean"}
+	]
+}`
+
+func avroEventRecord() []byte {
+	record := []byte{
+		0x02,           // id: 1
+		0x04, 'a', 'b', // name: "ab"
+		0x00,                  // email: null branch
+		0x02, 0x02, 'x', 0x00, // tags: ["x"]
+		0x02, 0x02, 'k', 0x01, 0x00, // attrs: {"k": -1}
+		0x02,                  // kind: VIEW
+		0x02, 'h', 0xA0, 0x01, // source: {"h", 80}
+		0x02, 0x02, 'g', 0x02, // backup: {"g", 1}
+		0xD0, 0x0F, // time: 1000
+		0x02, // day: 1
+		0x48, // uuid: string of 36 bytes
+	}
+	record = append(record, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"...)
+	record = append(record,
+		0x04, 0xFF, 0x6A, // price: -1.50
+		1, 2, 3, 4, // hash
+		0, 0, 0, 0, 0, 0, 0xE0, 0x3F, // score: 0.5
+		0, 0, 0x80, 0x3E, // ratio: 0.25
+		0x01, // ok: true
+	)
+	return record
+}
+
+func TestAvroCodec(t *testing.T) {
+	codec, err := parquet.NewAvroCodec([]byte(avroEventSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := parquet.Group{
+		"host": parquet.String(),
+		"port": parquet.Int(32),
+	}
+	want := parquet.NewSchema("Event", parquet.Group{
+		"id":     parquet.Int(64),
+		"name":   parquet.String(),
+		"email":  parquet.Optional(parquet.String()),
+		"tags":   parquet.List(parquet.String()),
+		"attrs":  parquet.Map(parquet.String(), parquet.Int(32)),
+		"kind":   parquet.Enum(),
+		"source": source,
+		"backup": parquet.Optional(source),
+		"time":   parquet.Timestamp(parquet.Millisecond),
+		"day":    parquet.Date(),
+		"uuid":   parquet.UUID(),
+		"price":  parquet.Decimal(2, 9, parquet.FixedLenByteArrayType(4)),
+		"hash":   parquet.Leaf(parquet.FixedLenByteArrayType(4)),
+		"score":  parquet.Leaf(parquet.DoubleType),
+		"ratio":  parquet.Leaf(parquet.FloatType),
+		"ok":     parquet.Leaf(parquet.BooleanType),
+	})
+	if got := codec.Schema(); want.String() != got.String() {
+		t.Fatalf("wrong schema:\nwant: %s\ngot:  %s", want, got)
+	}
+
+	record := avroEventRecord()
+	row, err := codec.Decode(nil, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := codec.Schema().ReconstructMap(row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantValues := map[string]interface{}{
+		"id":     int64(1),
+		"name":   "ab",
+		"email":  nil,
+		"tags":   []interface{}{"x"},
+		"attrs":  []interface{}{map[string]interface{}{"key": "k", "value": int32(-1)}},
+		"kind":   "VIEW",
+		"source": map[string]interface{}{"host": "h", "port": int32(80)},
+		"backup": map[string]interface{}{"host": "g", "port": int32(1)},
+		"time":   int64(1000),
+		"day":    int32(1),
+		"uuid":   uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		"price":  []byte{0xFF, 0xFF, 0xFF, 0x6A},
+		"hash":   []byte{1, 2, 3, 4},
+		"score":  float64(0.5),
+		"ratio":  float32(0.25),
+		"ok":     true,
+	}
+	if !reflect.DeepEqual(wantValues, got) {
+		t.Errorf("wrong values:\nwant: %v\ngot:  %v", wantValues, got)
+	}
+
+	// The rows are written to a parquet file and read back to be encoded, the
+	// encoding must be the original record.
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, codec.Schema())
+	if _, err := w.WriteRows([]parquet.Row{row}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([]parquet.Row, 1)
+	if n, _ := f.RowGroups()[0].Rows().ReadRows(rows); n != 1 {
+		t.Fatalf("wrong number of rows read: want=1 got=%d", n)
+	}
+	encoded, err := codec.Encode(nil, rows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(record, encoded) {
+		t.Errorf("wrong encoding of the record:\nwant: %x\ngot:  %x", record, encoded)
+	}
+}
+
+func TestAvroCodecSchemaErrors(t *testing.T) {
+	for _, test := range []struct {
+		scenario string
+		schema   string
+		message  string
+	}{
+		{
+			scenario: "not a record",
+			schema:   `"string"`,
+			message:  "the schema must be a record",
+		},
+		{
+			scenario: "unknown type",
+			schema:   `{"type":"record","name":"R","fields":[{"name":"a","type":"Other"}]}`,
+			message:  `unknown type "Other"`,
+		},
+		{
+			scenario: "union of several types",
+			schema:   `{"type":"record","name":"R","fields":[{"name":"a","type":["null","int","string"]}]}`,
+			message:  "unions of several non-null types are not supported",
+		},
+		{
+			scenario: "recursive record",
+			schema:   `{"type":"record","name":"R","fields":[{"name":"next","type":["null","R"]}]}`,
+			message:  "recursive record R is not supported",
+		},
+		{
+			scenario: "duplicate field",
+			schema:   `{"type":"record","name":"R","fields":[{"name":"a","type":"int"},{"name":"a","type":"int"}]}`,
+			message:  `duplicate field "a"`,
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, err := parquet.NewAvroCodec([]byte(test.schema))
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("wrong error: want %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestAvroCodecDecodeErrors(t *testing.T) {
+	codec, err := parquet.NewAvroCodec([]byte(avroEventSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := avroEventRecord()
+
+	invalidEnum := append([]byte{}, record...)
+	invalidEnum[14] = 0x04 // kind: index 2
+
+	for _, test := range []struct {
+		scenario string
+		record   []byte
+		message  string
+	}{
+		{scenario: "truncated", record: record[:len(record)-1], message: "ok → unexpected EOF"},
+		{scenario: "trailing bytes", record: append(record[:len(record):len(record)], 0), message: "1 bytes remain"},
+		{scenario: "invalid enum", record: invalidEnum, message: "kind → enum index out of range: 2"},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, err := codec.Decode(nil, test.record)
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("wrong error: want %q, got %v", test.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,538 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/segmentio/parquet-go/deprecated"
+)
+
+// BatchReader reads the rows of a row group column by column, into batches of
+// typed slices held by the fields of a struct (struct-of-slices), bypassing the
+// construction of Row and Value objects when the column pages allow it. This
+// layout is intended to be used by vectorized query engines built on top of
+// this package.
+//
+// The batches are values of struct types where each exported field is a slice
+// receiving the values of one column of the row group, for example:
+//
+//	type Batch struct {
+//		ID        []int64  `parquet:"id"`
+//		Name      []string `parquet:"name"`
+//		NameValid []bool   `parquet:"name,valid"`
+//	}
+//
+// The name in the struct tag is the path of the column in the schema, with the
+// names of nested fields separated by dots. When omitted, the name of the field
+// is used as column name. Fields with the "valid" tag option receive a boolean
+// for each row indicating whether the value of the column was non-null; null
+// values are set to the zero-value of the element type in the value slice.
+//
+// The following types of slices are supported (named types are not):
+//
+//	BOOLEAN              | []bool
+//	INT32                | []int32, []uint32
+//	INT64                | []int64, []uint64
+//	INT96                | []deprecated.Int96
+//	FLOAT                | []float32
+//	DOUBLE               | []float64
+//	BYTE_ARRAY           | []string, [][]byte
+//	FIXED_LEN_BYTE_ARRAY | []string, [][]byte
+//
+// Only columns which are not repeated can be read into batches.
+type BatchReader struct {
+	rowGroup  RowGroup
+	batchType reflect.Type
+	columns   []batchColumn
+	rowIndex  int64
+}
+
+// NewBatchReader constructs a BatchReader reading the rows of the row group
+// passed as argument.
+func NewBatchReader(rowGroup RowGroup) *BatchReader {
+	return &BatchReader{rowGroup: rowGroup}
+}
+
+// ReadBatch reads the next rows of the row group into batch, which must be a
+// pointer to a struct of slices. The number of rows read is at most the length
+// of the slices, which must all have the same length.
+//
+// The slices are not resized, the method writes the values of the rows read
+// to the first elements of each slice. The byte slices of [][]byte fields are
+// reused when their capacity is large enough, programs that need to retain the
+// values after the next call to ReadBatch must copy them.
+//
+// The method returns io.EOF when no more rows can be read.
+func (r *BatchReader) ReadBatch(batch interface{}) (int, error) {
+	v := reflect.ValueOf(batch)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return 0, fmt.Errorf("cannot read batch into go value of type %T: not a pointer to a struct", batch)
+	}
+	v = v.Elem()
+
+	if v.Type() != r.batchType {
+		if err := r.init(v.Type()); err != nil {
+			return 0, fmt.Errorf("cannot read batch into go value of type %T: %w", batch, err)
+		}
+	}
+
+	size := -1
+	for i := range r.columns {
+		c := &r.columns[i]
+		for _, f := range [2]int{c.values, c.valid} {
+			if f < 0 {
+				continue
+			}
+			switch n := v.Field(f).Len(); {
+			case size < 0:
+				size = n
+			case size != n:
+				return 0, fmt.Errorf("cannot read batch into go value of type %T: slices have different lengths (%d != %d)", batch, size, n)
+			}
+		}
+	}
+	if size <= 0 {
+		return 0, nil
+	}
+
+	numRows := -1
+	var lastErr error
+
+	for i := range r.columns {
+		c := &r.columns[i]
+		var valid []bool
+		if c.valid >= 0 {
+			valid = v.Field(c.valid).Interface().([]bool)
+		}
+		n, err := c.read(v.Field(c.values), valid, size)
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("reading values of column %q: %w", columnPath(c.path), err)
+		}
+		if numRows >= 0 && n != numRows {
+			return 0, fmt.Errorf("reading values of column %q: column has %d rows but other columns had %d", columnPath(c.path), n, numRows)
+		}
+		numRows, lastErr = n, err
+	}
+
+	r.rowIndex += int64(numRows)
+	if numRows == 0 {
+		lastErr = io.EOF
+	}
+	return numRows, lastErr
+}
+
+// SeekToRow positions r at the given row index.
+func (r *BatchReader) SeekToRow(rowIndex int64) error {
+	for i := range r.columns {
+		if err := r.columns[i].seekToRow(rowIndex); err != nil {
+			return err
+		}
+	}
+	r.rowIndex = rowIndex
+	return nil
+}
+
+// Close closes the reader, releasing the pages of the columns that it reads.
+func (r *BatchReader) Close() (err error) {
+	for i := range r.columns {
+		if e := r.columns[i].pages.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	r.columns = nil
+	r.batchType = nil
+	return err
+}
+
+func (r *BatchReader) init(batchType reflect.Type) error {
+	columns, err := batchColumnsOf(r.rowGroup, batchType)
+	if err != nil {
+		return err
+	}
+	for i := range r.columns {
+		r.columns[i].pages.Close()
+	}
+	r.columns, r.batchType = columns, batchType
+	// The pages of all the columns reserve memory on behalf of the reader, see
+	// pagesWithMemory.
+	memory := new(memoryReservation)
+
+	for i := range columns {
+		c := &columns[i]
+		c.pages = pagesWithMemory(r.rowGroup.ColumnChunks()[c.column], memory)
+		if r.rowIndex > 0 {
+			if err := c.seekToRow(r.rowIndex); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func batchColumnsOf(rowGroup RowGroup, batchType reflect.Type) ([]batchColumn, error) {
+	schema := rowGroup.Schema()
+	columns := make([]batchColumn, 0, batchType.NumField())
+	columnsByName := make(map[string]int, batchType.NumField())
+
+	for i, n := 0, batchType.NumField(); i < n; i++ {
+		f := batchType.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+
+		name, tag := f.Name, f.Tag.Get("parquet")
+		if tag == "-" {
+			continue
+		}
+		if tagName, _ := split(tag); tagName != "" {
+			name = tagName
+		}
+		valid := false
+		forEachStructTagOption(f, func(_ reflect.Type, option, _ string) {
+			valid = valid || option == "valid"
+		})
+
+		if f.Type.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("field %s must be a slice to receive values of column %q", f.Name, name)
+		}
+		if valid && f.Type.Elem() != reflect.TypeOf(false) {
+			return nil, fmt.Errorf("field %s must be a slice of booleans to receive the validity of column %q", f.Name, name)
+		}
+
+		j, ok := columnsByName[name]
+		if !ok {
+			path := strings.Split(name, ".")
+			leaf, ok := schema.Lookup(path...)
+			if !ok {
+				return nil, fmt.Errorf("column %q does not exist in the schema", name)
+			}
+			if leaf.MaxRepetitionLevel > 0 {
+				return nil, fmt.Errorf("column %q is repeated and cannot be read into a batch", name)
+			}
+			j = len(columns)
+			columnsByName[name] = j
+			columns = append(columns, batchColumn{
+				path:               leaf.Path,
+				column:             leaf.ColumnIndex,
+				kind:               leaf.Node.Type().Kind(),
+				maxDefinitionLevel: byte(leaf.MaxDefinitionLevel),
+				values:             -1,
+				valid:              -1,
+			})
+		}
+
+		c := &columns[j]
+		if valid {
+			c.valid = i
+		} else {
+			if !batchTypeMatchesKind(f.Type.Elem(), c.kind) {
+				return nil, fmt.Errorf("field %s of type %s cannot receive values of column %q of type %s", f.Name, f.Type, name, c.kind)
+			}
+			c.values = i
+		}
+	}
+
+	for i := range columns {
+		if columns[i].values < 0 {
+			return nil, fmt.Errorf("no field receives the values of column %q", columnPath(columns[i].path))
+		}
+	}
+	return columns, nil
+}
+
+func batchTypeMatchesKind(t reflect.Type, kind Kind) bool {
+	switch kind {
+	case Boolean:
+		return t == reflect.TypeOf(false)
+	case Int32:
+		return t == reflect.TypeOf(int32(0)) || t == reflect.TypeOf(uint32(0))
+	case Int64:
+		return t == reflect.TypeOf(int64(0)) || t == reflect.TypeOf(uint64(0))
+	case Int96:
+		return t == reflect.TypeOf(deprecated.Int96{})
+	case Float:
+		return t == reflect.TypeOf(float32(0))
+	case Double:
+		return t == reflect.TypeOf(float64(0))
+	case ByteArray, FixedLenByteArray:
+		return t == reflect.TypeOf("") || t == reflect.TypeOf([]byte(nil))
+	default:
+		return false
+	}
+}
+
+// batchColumn reads the values of a column into the slices of batches.
+type batchColumn struct {
+	path               []string
+	column             int
+	kind               Kind
+	maxDefinitionLevel byte
+	// Indexes of the batch struct fields receiving the values and validity of
+	// the column, or -1 if there are none.
+	values int
+	valid  int
+
+	pages Pages
+	// Values of the current page, and their definition levels if the values
+	// reader only returns non-null values.
+	reader ValueReader
+	levels []byte
+	// Position in the current page, and number of values remaining.
+	offset int
+	remain int
+	buffer []Value
+}
+
+func (c *batchColumn) seekToRow(rowIndex int64) error {
+	c.reader, c.levels, c.offset, c.remain = nil, nil, 0, 0
+	return c.pages.SeekToRow(rowIndex)
+}
+
+func (c *batchColumn) nextPage() error {
+	page, err := c.pages.ReadPage()
+	if err != nil {
+		return err
+	}
+	c.offset, c.remain = 0, int(page.NumValues())
+	c.levels = nil
+	if p, ok := page.(*optionalPage); ok {
+		c.reader, c.levels = p.base.Values(), p.definitionLevels
+	} else {
+		c.reader = page.Values()
+	}
+	return nil
+}
+
+// read reads up to size values into the first elements of dst, returning the
+// number of values read.
+func (c *batchColumn) read(dst reflect.Value, valid []bool, size int) (int, error) {
+	n := 0
+	for n < size {
+		if c.remain == 0 {
+			if err := c.nextPage(); err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		m := size - n
+		if m > c.remain {
+			m = c.remain
+		}
+
+		var err error
+		switch {
+		case c.levels != nil:
+			err = c.readOptional(dst, valid, n, m)
+		case c.maxDefinitionLevel == 0:
+			err = c.readRequired(dst, valid, n, m)
+		default:
+			err = c.readValues(dst, valid, n, m)
+		}
+		if err != nil {
+			return n, err
+		}
+
+		c.offset += m
+		c.remain -= m
+		n += m
+	}
+	return n, nil
+}
+
+func (c *batchColumn) readRequired(dst reflect.Value, valid []bool, offset, count int) error {
+	if valid != nil {
+		for i := range valid[offset : offset+count] {
+			valid[offset+i] = true
+		}
+	}
+	return c.readDense(dst.Slice(offset, offset+count))
+}
+
+// readOptional reads values of a page where the reader only produces non-null
+// values, expanding them in place according to the definition levels.
+func (c *batchColumn) readOptional(dst reflect.Value, valid []bool, offset, count int) error {
+	levels := c.levels[c.offset : c.offset+count]
+	numValues := countLevelsEqual(levels, c.maxDefinitionLevel)
+
+	if err := c.readDense(dst.Slice(offset, offset+numValues)); err != nil {
+		return err
+	}
+
+	if numValues < count {
+		zero := reflect.Zero(dst.Type().Elem())
+		j := offset + numValues - 1
+		for i := count - 1; i >= 0; i-- {
+			if levels[i] == c.maxDefinitionLevel {
+				dst.Index(offset + i).Set(dst.Index(j))
+				j--
+			} else {
+				dst.Index(offset + i).Set(zero)
+			}
+		}
+	}
+
+	if valid != nil {
+		for i, level := range levels {
+			valid[offset+i] = level == c.maxDefinitionLevel
+		}
+	}
+	return nil
+}
+
+// readValues reads values of a page which were not decoded to the internal
+// page types, falling back to reading Value objects.
+func (c *batchColumn) readValues(dst reflect.Value, valid []bool, offset, count int) error {
+	values, err := c.readBuffer(count)
+	if err != nil {
+		return err
+	}
+	if valid != nil {
+		for i, v := range values {
+			valid[offset+i] = !v.IsNull()
+		}
+	}
+	assignBatchValues(dst.Slice(offset, offset+count).Interface(), values)
+	return nil
+}
+
+// readDense reads len(dst) non-null values into dst, using the typed readers
+// of the page values when available.
+func (c *batchColumn) readDense(dst reflect.Value) error {
+	var n int
+	var err error
+
+	switch values := dst.Interface().(type) {
+	case []bool:
+		if r, ok := c.reader.(BooleanReader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadBooleans(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []int32:
+		if r, ok := c.reader.(Int32Reader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadInt32s(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []uint32:
+		if r, ok := c.reader.(interface{ ReadUint32s([]uint32) (int, error) }); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadUint32s(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []int64:
+		if r, ok := c.reader.(Int64Reader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadInt64s(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []uint64:
+		if r, ok := c.reader.(interface{ ReadUint64s([]uint64) (int, error) }); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadUint64s(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []deprecated.Int96:
+		if r, ok := c.reader.(Int96Reader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadInt96s(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []float32:
+		if r, ok := c.reader.(FloatReader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadFloats(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	case []float64:
+		if r, ok := c.reader.(DoubleReader); ok {
+			n, err = readBatchValues(len(values), func(i int) (int, error) { return r.ReadDoubles(values[i:]) })
+			return batchError(n, len(values), err)
+		}
+	}
+
+	values, err := c.readBuffer(dst.Len())
+	if err != nil {
+		return err
+	}
+	assignBatchValues(dst.Interface(), values)
+	return nil
+}
+
+func (c *batchColumn) readBuffer(count int) ([]Value, error) {
+	if cap(c.buffer) < count {
+		c.buffer = make([]Value, count)
+	}
+	values := c.buffer[:count]
+	n, err := readValues(c.reader, values)
+	return values, batchError(n, count, err)
+}
+
+func readBatchValues(count int, read func(int) (int, error)) (int, error) {
+	n := 0
+	for n < count {
+		c, err := read(n)
+		n += c
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func batchError(n, count int, err error) error {
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && n < count {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func assignBatchValues(dst interface{}, values []Value) {
+	switch dst := dst.(type) {
+	case []bool:
+		for i, v := range values {
+			dst[i] = v.Boolean()
+		}
+	case []int32:
+		for i, v := range values {
+			dst[i] = v.Int32()
+		}
+	case []uint32:
+		for i, v := range values {
+			dst[i] = v.Uint32()
+		}
+	case []int64:
+		for i, v := range values {
+			dst[i] = v.Int64()
+		}
+	case []uint64:
+		for i, v := range values {
+			dst[i] = v.Uint64()
+		}
+	case []deprecated.Int96:
+		for i, v := range values {
+			dst[i] = v.Int96()
+		}
+	case []float32:
+		for i, v := range values {
+			dst[i] = v.Float()
+		}
+	case []float64:
+		for i, v := range values {
+			dst[i] = v.Double()
+		}
+	case []string:
+		for i, v := range values {
+			dst[i] = string(v.ByteArray())
+		}
+	case [][]byte:
+		for i, v := range values {
+			if v.IsNull() {
+				dst[i] = nil
+			} else {
+				dst[i] = append(dst[i][:0], v.ByteArray()...)
+			}
+		}
+	default:
+		panic(fmt.Sprintf("cannot assign values to batch of type %T", dst))
+	}
+}
@@ -0,0 +1,130 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestBatchReader(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Name  *string `parquet:"name,optional"`
+		Tag   string  `parquet:"tag,dict"`
+		Score float32 `parquet:"score"`
+		Flag  bool    `parquet:"flag"`
+		Count uint32  `parquet:"count"`
+		Data  []byte  `parquet:"data,optional"`
+	}
+
+	type Batch struct {
+		ID        []int64   `parquet:"id"`
+		Name      []string  `parquet:"name"`
+		NameValid []bool    `parquet:"name,valid"`
+		Tag       []string  `parquet:"tag"`
+		Score     []float32 `parquet:"score"`
+		Flag      []bool    `parquet:"flag"`
+		Count     []uint32  `parquet:"count"`
+		Data      [][]byte  `parquet:"data"`
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    int64(i),
+			Tag:   fmt.Sprintf("tag-%d", i%3),
+			Score: float32(i) / 2,
+			Flag:  i%2 == 0,
+			Count: uint32(i * 3),
+		}
+		if i%3 != 0 {
+			name := fmt.Sprintf("name-%d", i)
+			rows[i].Name = &name
+			rows[i].Data = []byte(name)
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(128)); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const batchSize = 7
+	batch := Batch{
+		ID:        make([]int64, batchSize),
+		Name:      make([]string, batchSize),
+		NameValid: make([]bool, batchSize),
+		Tag:       make([]string, batchSize),
+		Score:     make([]float32, batchSize),
+		Flag:      make([]bool, batchSize),
+		Count:     make([]uint32, batchSize),
+		Data:      make([][]byte, batchSize),
+	}
+
+	r := parquet.NewBatchReader(f.RowGroups()[0])
+	defer r.Close()
+
+	numRows := 0
+	for {
+		n, err := r.ReadBatch(&batch)
+		for i := 0; i < n; i++ {
+			row := rows[numRows+i]
+			name, valid := "", row.Name != nil
+			if valid {
+				name = *row.Name
+			}
+			got := Row{
+				ID:    batch.ID[i],
+				Tag:   batch.Tag[i],
+				Score: batch.Score[i],
+				Flag:  batch.Flag[i],
+				Count: batch.Count[i],
+				Data:  batch.Data[i],
+			}
+			if batch.NameValid[i] != valid || batch.Name[i] != name {
+				t.Errorf("row %d: wrong name: want=%q/%t got=%q/%t", numRows+i, name, valid, batch.Name[i], batch.NameValid[i])
+			}
+			row.Name = nil
+			if !reflect.DeepEqual(got, row) {
+				t.Errorf("row %d mismatch:\nwant = %+v\ngot  = %+v", numRows+i, row, got)
+			}
+		}
+		numRows += n
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	if numRows != len(rows) {
+		t.Errorf("wrong number of rows read: want=%d got=%d", len(rows), numRows)
+	}
+
+	if err := r.SeekToRow(50); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.ReadBatch(&batch); n != batchSize || err != nil {
+		t.Fatalf("reading batch after seek: n=%d err=%v", n, err)
+	}
+	if batch.ID[0] != 50 {
+		t.Errorf("wrong first row after seek: want=50 got=%d", batch.ID[0])
+	}
+
+	type InvalidBatch struct {
+		ID []int32 `parquet:"id"`
+	}
+	if _, err := r.ReadBatch(&InvalidBatch{ID: make([]int32, 1)}); err == nil {
+		t.Error("expected an error reading int64 values into a slice of int32")
+	}
+}
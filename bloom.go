@@ -1,8 +1,14 @@
 package parquet
 
 import (
+	"bytes"
+	"container/list"
+	"fmt"
 	"io"
+	"math"
+	"sync"
 
+	"github.com/segmentio/encoding/thrift"
 	"github.com/segmentio/parquet-go/bloom"
 	"github.com/segmentio/parquet-go/bloom/xxhash"
 	"github.com/segmentio/parquet-go/encoding"
@@ -33,12 +39,199 @@ type BloomFilter interface {
 
 type bloomFilter struct {
 	io.SectionReader
-	hash  bloom.Hash
-	check func(io.ReaderAt, int64, uint64) (bool, error)
+	hash       bloom.Hash
+	headerSize int64
+	cache      *bloomFilterCache
+	// The filter is read in memory the first time it is checked, which avoids
+	// reading bloom filters that the program does not use, and issuing a read
+	// from the file for each value checked when probing many values. These
+	// fields are guarded by the mutex of the cache.
+	filter bloom.SplitBlockFilter
+	elem   *list.Element
+}
+
+// newBloomFilter reads the header of the bloom filter at the given offset from
+// r, and returns a filter reading its bits from file when it is first checked.
+// The returned filter is nil if the package does not support the algorithm of
+// the filter.
+func newBloomFilter(file, r io.ReaderAt, offset int64, cache *bloomFilterCache, decryption *columnDecryption) (*bloomFilter, error) {
+	var h format.BloomFilterHeader
+	var headerSize int64
+	var bits io.ReaderAt
+	var err error
+
+	if decryption != nil {
+		h, headerSize, err = readEncryptedBloomFilterHeader(r, offset, decryption)
+		bits = &encryptedBloomFilterBits{
+			file:       file,
+			offset:     offset + headerSize,
+			size:       int64(h.NumBytes),
+			decryption: decryption,
+		}
+		offset = 0
+	} else {
+		h, headerSize, err = readBloomFilterHeader(r, offset)
+		bits, offset = file, offset+headerSize
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Algorithm.Block != nil {
+		if h.Hash.XxHash != nil {
+			if h.Compression.Uncompressed != nil {
+				return &bloomFilter{
+					SectionReader: *io.NewSectionReader(bits, offset, int64(h.NumBytes)),
+					hash:          bloom.XXH64{},
+					headerSize:    headerSize,
+					cache:         cache,
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// readBloomFilterHeader reads the header of the bloom filter at the given offset
+// in r, returning it along with its size in bytes.
+func readBloomFilterHeader(r io.ReaderAt, offset int64) (format.BloomFilterHeader, int64, error) {
+	h := format.BloomFilterHeader{}
+	// Headers are small, reading a fixed size buffer retrieves them with a
+	// single call to ReadAt instead of one per byte decoded.
+	b := make([]byte, bloomFilterHeaderReadSize)
+	n, err := r.ReadAt(b, offset)
+	if n == 0 && err != nil {
+		return h, 0, fmt.Errorf("reading bloom filter header at offset %d: %w", offset, err)
+	}
+	p := thrift.CompactProtocol{}
+	d := bytes.NewReader(b[:n])
+	if err := thrift.NewDecoder(p.NewReader(d)).Decode(&h); err != nil {
+		return h, 0, fmt.Errorf("decoding bloom filter header at offset %d: %w", offset, err)
+	}
+	return h, int64(n - d.Len()), nil
+}
+
+// readEncryptedBloomFilterHeader is like readBloomFilterHeader for the headers
+// of encrypted bloom filters, the returned size is the size of the encrypted
+// module holding the header.
+func readEncryptedBloomFilterHeader(r io.ReaderAt, offset int64, decryption *columnDecryption) (format.BloomFilterHeader, int64, error) {
+	h := format.BloomFilterHeader{}
+	header, err := readModule(io.NewSectionReader(r, offset, math.MaxInt64-offset), nil, bloomFilterHeaderReadSize)
+	if err != nil {
+		return h, 0, fmt.Errorf("reading encrypted bloom filter header at offset %d: %w", offset, err)
+	}
+	headerSize := int64(len(header))
+	if header, err = decryption.decryptInPlace(header, bloomFilterHeaderModule, -1); err != nil {
+		return h, 0, fmt.Errorf("decrypting bloom filter header at offset %d: %w", offset, err)
+	}
+	if err := thrift.Unmarshal(new(thrift.CompactProtocol), header, &h); err != nil {
+		return h, 0, fmt.Errorf("decoding bloom filter header at offset %d: %w", offset, err)
+	}
+	if h.NumBytes < 0 {
+		return h, 0, fmt.Errorf("invalid size of bloom filter at offset %d: %d", offset, h.NumBytes)
+	}
+	return h, headerSize, nil
+}
+
+// encryptedBloomFilterBits exposes the decrypted bits of an encrypted bloom
+// filter, which must be read in full to be authenticated. The bits are read
+// and decrypted the first time they are accessed.
+type encryptedBloomFilterBits struct {
+	file       io.ReaderAt
+	offset     int64
+	size       int64
+	decryption *columnDecryption
+
+	once sync.Once
+	bits []byte
+	err  error
+}
+
+func (b *encryptedBloomFilterBits) ReadAt(p []byte, off int64) (int, error) {
+	b.once.Do(func() { b.bits, b.err = b.decrypt() })
+	if b.err != nil {
+		return 0, b.err
+	}
+	return bytes.NewReader(b.bits).ReadAt(p, off)
+}
+
+func (b *encryptedBloomFilterBits) decrypt() ([]byte, error) {
+	r := io.NewSectionReader(b.file, b.offset, math.MaxInt64-b.offset)
+	bits, err := readModule(r, nil, b.size+gcmNonceSize+gcmTagSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading encrypted bloom filter at offset %d: %w", b.offset, err)
+	}
+	if bits, err = b.decryption.decryptInPlace(bits, bloomFilterBitsetModule, -1); err != nil {
+		return nil, fmt.Errorf("decrypting bloom filter at offset %d: %w", b.offset, err)
+	}
+	return bits, nil
 }
 
 func (f *bloomFilter) Check(v Value) (bool, error) {
-	return f.check(&f.SectionReader, f.Size(), v.hash(f.hash))
+	filter, err := f.load()
+	if err != nil {
+		return false, err
+	}
+	return filter.Check(v.hash(f.hash)), nil
+}
+
+func (f *bloomFilter) load() (bloom.SplitBlockFilter, error) {
+	if filter := f.cache.get(f); filter != nil {
+		return filter, nil
+	}
+	filter := make(bloom.SplitBlockFilter, f.Size()/bloom.BlockSize)
+	if _, err := f.ReadAt(filter.Bytes(), 0); err != nil {
+		return nil, err
+	}
+	return f.cache.put(f, filter), nil
+}
+
+// bloomFilterCache retains the bloom filters loaded from a file, releasing the
+// least recently used ones when their total size exceeds the limit.
+type bloomFilterCache struct {
+	mutex sync.Mutex
+	limit int64
+	size  int64
+	queue list.List
+}
+
+func (c *bloomFilterCache) get(f *bloomFilter) bloom.SplitBlockFilter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if f.elem == nil {
+		return nil
+	}
+	c.queue.MoveToFront(f.elem)
+	return f.filter
+}
+
+func (c *bloomFilterCache) put(f *bloomFilter, filter bloom.SplitBlockFilter) bloom.SplitBlockFilter {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if f.elem != nil { // loaded concurrently by another goroutine
+		c.queue.MoveToFront(f.elem)
+		return f.filter
+	}
+
+	size := int64(len(filter)) * bloom.BlockSize
+	if c.limit > 0 && size > c.limit {
+		return filter
+	}
+
+	f.filter, f.elem = filter, c.queue.PushFront(f)
+	c.size += size
+
+	for c.limit > 0 && c.size > c.limit {
+		elem := c.queue.Back()
+		evicted := elem.Value.(*bloomFilter)
+		c.queue.Remove(elem)
+		c.size -= int64(len(evicted.filter)) * bloom.BlockSize
+		evicted.filter, evicted.elem = nil, nil
+	}
+
+	return filter
 }
 
 func (v Value) hash(h bloom.Hash) uint64 {
@@ -56,19 +249,41 @@ func (v Value) hash(h bloom.Hash) uint64 {
 	}
 }
 
-func newBloomFilter(file io.ReaderAt, offset int64, header *format.BloomFilterHeader) *bloomFilter {
-	if header.Algorithm.Block != nil {
-		if header.Hash.XxHash != nil {
-			if header.Compression.Uncompressed != nil {
-				return &bloomFilter{
-					SectionReader: *io.NewSectionReader(file, offset, int64(header.NumBytes)),
-					hash:          bloom.XXH64{},
-					check:         bloom.CheckSplitBlock,
-				}
-			}
-		}
+// MayContain tests whether the column chunk may contain the given value using
+// its bloom filter, which allows programs to skip row groups that cannot match
+// equality predicates without reading their data pages. The bloom filters of
+// parquet files are read the first time that they are checked.
+//
+// Bloom filters have no false negatives, so the function returns false only if
+// the value does not exist in the column chunk. It returns true when the value
+// may exist, or when the column chunk has no bloom filter. Null values are not
+// recorded in bloom filters, the function always returns true for them.
+//
+// The value must be of the kind of the column, since values of different kinds
+// hash differently; an error is returned otherwise.
+func MayContain(chunk ColumnChunk, value Value) (bool, error) {
+	if value.IsNull() {
+		return true, nil
 	}
-	return nil
+	if kind := chunk.Type().Kind(); value.Kind() != kind {
+		return false, fmt.Errorf("cannot check value of kind %s in bloom filter of column of kind %s", value.Kind(), kind)
+	}
+	filter := chunk.BloomFilter()
+	if filter == nil {
+		return true, nil
+	}
+	return filter.Check(value)
+}
+
+// MayContainBytes is like MayContain but takes the PLAIN encoded representation
+// of the value; for example, 4 bytes in little-endian order for INT32 columns,
+// or the raw bytes for BYTE_ARRAY columns (without the length prefix).
+func MayContainBytes(chunk ColumnChunk, value []byte) (bool, error) {
+	v, err := parseValue(chunk.Type().Kind(), value)
+	if err != nil {
+		return false, err
+	}
+	return MayContain(chunk, v)
 }
 
 // The BloomFilterColumn interface is a declarative representation of bloom filters
@@ -121,6 +336,44 @@ func bloomFilterHeader(filter BloomFilterColumn) (header format.BloomFilterHeade
 	return header
 }
 
+// BloomFilterPlacement represents the location where writers place the bloom
+// filters of column chunks in parquet files.
+//
+// Readers locate bloom filters with the offsets recorded in the column chunk
+// metadata, so all placements produce valid files; however, some readers expect
+// the filters at specific locations, or perform better when the filters of all
+// row groups are contiguous.
+type BloomFilterPlacement int
+
+const (
+	// BloomFiltersBeforeRowGroup places the bloom filters of each row group
+	// right before its column chunks. This is the default placement.
+	BloomFiltersBeforeRowGroup BloomFilterPlacement = iota
+
+	// BloomFiltersAfterRowGroup places the bloom filters of each row group
+	// right after its column chunks.
+	BloomFiltersAfterRowGroup
+
+	// BloomFiltersBeforeFooter places the bloom filters of all row groups
+	// after the page index, right before the file footer. Writers retain the
+	// filters in memory until the file is closed.
+	BloomFiltersBeforeFooter
+)
+
+// String returns a human-readable representation of p.
+func (p BloomFilterPlacement) String() string {
+	switch p {
+	case BloomFiltersBeforeRowGroup:
+		return "BEFORE_ROW_GROUP"
+	case BloomFiltersAfterRowGroup:
+		return "AFTER_ROW_GROUP"
+	case BloomFiltersBeforeFooter:
+		return "BEFORE_FOOTER"
+	default:
+		return fmt.Sprintf("BloomFilterPlacement(%d)", int(p))
+	}
+}
+
 func searchBloomFilterColumn(filters []BloomFilterColumn, path columnPath) BloomFilterColumn {
 	for _, f := range filters {
 		if path.equal(f.Path()) {
@@ -130,6 +383,108 @@ func searchBloomFilterColumn(filters []BloomFilterColumn, path columnPath) Bloom
 	return nil
 }
 
+// validateBloomFilters returns an error if some of the filters are configured
+// for paths that do not name leaf columns of the schema. The paths of columns
+// nested in groups must contain the names of all their parents, for example
+// "items", "list", "element", "sku" for a field of the elements of a list.
+func validateBloomFilters(schema *Schema, filters []BloomFilterColumn) error {
+	for _, f := range filters {
+		if _, ok := schema.Lookup(f.Path()...); !ok {
+			return fmt.Errorf("bloom filter configured for column %q which is not a leaf column of the schema", columnPath(f.Path()))
+		}
+	}
+	return nil
+}
+
+// LookupColumnChunk returns the column chunk of the leaf column at the given
+// path in the row group, which programs can use to query its bloom filter or
+// indexes. The path of columns nested in groups is made of the names of all
+// their parents, as returned by the Columns method of the row group's schema.
+//
+// The boolean is false if the path does not name a leaf column of the schema.
+func LookupColumnChunk(rowGroup RowGroup, path ...string) (ColumnChunk, bool) {
+	leaf, ok := rowGroup.Schema().Lookup(path...)
+	if !ok {
+		return nil, false
+	}
+	return rowGroup.ColumnChunks()[leaf.ColumnIndex], true
+}
+
+// BloomFilterStats carries metadata about the bloom filter of a column chunk,
+// which programs can use to audit the sizing of bloom filters in parquet files.
+type BloomFilterStats struct {
+	// Index of the row group and path of the column that the filter belongs
+	// to. These fields are only set by the File.BloomFilterStats method.
+	RowGroup int
+	Path     []string
+	// Size of the filter (in bytes) and number of blocks that it is made of.
+	Size      int64
+	NumBlocks int
+	// Number of values in the column chunk, including nulls. The number of
+	// distinct values inserted in the filter is usually lower.
+	NumValues int64
+}
+
+// BitsPerValue returns the number of bits of the filter for each value of the
+// column chunk, which is zero if the column chunk is empty.
+func (s *BloomFilterStats) BitsPerValue() float64 {
+	if s.NumValues == 0 {
+		return 0
+	}
+	return float64(8*s.Size) / float64(s.NumValues)
+}
+
+// FalsePositiveRate returns the estimated probability that the filter reports
+// that it may contain a value absent from the column chunk, given the number of
+// distinct values inserted in the filter. Programs that do not know the number
+// of distinct values of the column chunk may pass NumValues to compute an upper
+// bound of the false positive rate.
+func (s *BloomFilterStats) FalsePositiveRate(numValues int64) float64 {
+	return bloom.SplitBlockFalsePositiveRate(s.NumBlocks, numValues)
+}
+
+// BloomFilterStatsOf returns statistics about the bloom filter of the column
+// chunk. The boolean is false if the column chunk has no bloom filter.
+//
+// The headers of the bloom filters of parquet files are read when the files
+// are opened, the error is always nil for their column chunks.
+func BloomFilterStatsOf(chunk ColumnChunk) (BloomFilterStats, bool, error) {
+	filter := chunk.BloomFilter()
+	if filter == nil {
+		return BloomFilterStats{}, false, nil
+	}
+	size := filter.Size()
+	return BloomFilterStats{
+		Size:      size,
+		NumBlocks: int(size / bloom.BlockSize),
+		NumValues: chunk.NumValues(),
+	}, true, nil
+}
+
+// BloomFilterStats returns statistics about the bloom filters of all the
+// column chunks of f, in the order of row groups and leaf columns. Column
+// chunks without bloom filters are omitted.
+func (f *File) BloomFilterStats() ([]BloomFilterStats, error) {
+	var stats []BloomFilterStats
+	columns := f.schema.Columns()
+
+	for i, rowGroup := range f.RowGroups() {
+		for j, chunk := range rowGroup.ColumnChunks() {
+			s, ok, err := BloomFilterStatsOf(chunk)
+			if err != nil {
+				return nil, fmt.Errorf("reading bloom filter of column %q in row group %d: %w", columnPath(columns[j]), i, err)
+			}
+			if ok {
+				s.RowGroup = i
+				s.Path = append([]string(nil), columns[j]...)
+				stats = append(stats, s)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
 const (
 	// Size of the stack buffer used to perform bulk operations on bloom filters.
 	//
@@ -137,6 +492,21 @@ const (
 	// 128 x uint64 makes a 1KiB buffer which amortizes the cost of calling
 	// methods of bloom filters while not causing too much stack growth either.
 	filterEncodeBufferSize = 128
+
+	// Size of the buffer used to read the headers of bloom filters, which is
+	// larger than the headers written by known implementations.
+	bloomFilterHeaderReadSize = 256
+
+	// Number of bits per value used to size the bloom filters generated by the
+	// package. Split block bloom filters of this size have a false positive
+	// rate of about 1%, which is the trade-off made by other implementations;
+	// the value is fixed so filters written by the writer and added to files
+	// by AddBloomFilters are sized the same way.
+	bloomFilterBitsPerValue = 10
+
+	// Number of rows of pages hashed by each goroutine when bloom filters are
+	// built concurrently.
+	filterHashBatchSize = 16 * 1024
 )
 
 type splitBlockEncoding struct {
@@ -255,3 +625,83 @@ func splitBlockEncodeUint128(filter bloom.SplitBlockFilter, values [][16]byte) {
 		i += n
 	}
 }
+
+// splitBlockHashEncoding is an encoding producing the hashes of the values that
+// are inserted in split block bloom filters, which allows computing the hashes
+// of pages concurrently before inserting them in the filters. The hashes are
+// appended to the output buffer as uint64 values.
+type splitBlockHashEncoding struct {
+	encoding.NotSupported
+}
+
+func (splitBlockHashEncoding) EncodeBoolean(dst, src []byte) ([]byte, error) {
+	dst, hashes := growHashes(dst, len(src))
+	xxhash.MultiSum64Uint8(hashes, src)
+	return dst, nil
+}
+
+func (splitBlockHashEncoding) EncodeInt32(dst, src []byte) ([]byte, error) {
+	values := unsafecast.BytesToUint32(src)
+	dst, hashes := growHashes(dst, len(values))
+	xxhash.MultiSum64Uint32(hashes, values)
+	return dst, nil
+}
+
+func (splitBlockHashEncoding) EncodeInt64(dst, src []byte) ([]byte, error) {
+	values := unsafecast.BytesToUint64(src)
+	dst, hashes := growHashes(dst, len(values))
+	xxhash.MultiSum64Uint64(hashes, values)
+	return dst, nil
+}
+
+func (e splitBlockHashEncoding) EncodeInt96(dst, src []byte) ([]byte, error) {
+	return e.EncodeFixedLenByteArray(dst, src, 12)
+}
+
+func (e splitBlockHashEncoding) EncodeFloat(dst, src []byte) ([]byte, error) {
+	return e.EncodeInt32(dst, src)
+}
+
+func (e splitBlockHashEncoding) EncodeDouble(dst, src []byte) ([]byte, error) {
+	return e.EncodeInt64(dst, src)
+}
+
+func (splitBlockHashEncoding) EncodeByteArray(dst, src []byte) ([]byte, error) {
+	numValues := 0
+	if err := plain.RangeByteArray(src, func([]byte) error { numValues++; return nil }); err != nil {
+		return dst, err
+	}
+	dst, hashes := growHashes(dst, numValues)
+	i := 0
+	return dst, plain.RangeByteArray(src, func(value []byte) error {
+		hashes[i] = xxhash.Sum64(value)
+		i++
+		return nil
+	})
+}
+
+func (splitBlockHashEncoding) EncodeFixedLenByteArray(dst, src []byte, size int) ([]byte, error) {
+	if size == 16 {
+		values := unsafecast.BytesToUint128(src)
+		dst, hashes := growHashes(dst, len(values))
+		xxhash.MultiSum64Uint128(hashes, values)
+		return dst, nil
+	}
+	dst, hashes := growHashes(dst, len(src)/size)
+	for i := range hashes {
+		hashes[i] = xxhash.Sum64(src[i*size : (i+1)*size])
+	}
+	return dst, nil
+}
+
+// growHashes extends dst with space for n hashes, returning the extended buffer
+// and the hashes to write. The length of dst must be a multiple of 8.
+func growHashes(dst []byte, n int) ([]byte, []uint64) {
+	offset := len(dst)
+	if size := offset + 8*n; size <= cap(dst) {
+		dst = dst[:size]
+	} else {
+		dst = append(dst, make([]byte, 8*n)...)
+	}
+	return dst, unsafecast.BytesToUint64(dst[offset:])
+}
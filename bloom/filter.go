@@ -2,6 +2,7 @@ package bloom
 
 import (
 	"io"
+	"math"
 	"sync"
 	"unsafe"
 )
@@ -50,6 +51,42 @@ func NumSplitBlocksOf(numValues int64, bitsPerValue uint) int {
 	return int(numBlocks)
 }
 
+// SplitBlockFalsePositiveRate returns the estimated probability that checking a
+// value absent from a split block filter of numBlocks blocks returns true, after
+// numValues distinct values were inserted in the filter.
+//
+// Each value sets one bit in each of the 8 words of the block it hashes to, the
+// estimate sums the false positive rates of blocks holding k values weighted by
+// the probability of a block holding k values, which follows a Poisson
+// distribution.
+func SplitBlockFalsePositiveRate(numBlocks int, numValues int64) float64 {
+	if numBlocks <= 0 {
+		return 1
+	}
+	if numValues <= 0 {
+		return 0
+	}
+
+	lambda := float64(numValues) / float64(numBlocks)
+	// The distribution is computed in log space to avoid the underflow of
+	// exp(-lambda) for high densities. The probability of values of k more
+	// than 10 standard deviations away from the mean is negligible.
+	deviation := 10*math.Sqrt(lambda) + 10
+	minK := math.Max(0, math.Floor(lambda-deviation))
+	maxK := math.Ceil(lambda + deviation)
+
+	rate := 0.0
+	for k := minK; k <= maxK; k++ {
+		lgamma, _ := math.Lgamma(k + 1)
+		p := math.Exp(k*math.Log(lambda) - lambda - lgamma)
+		rate += p * math.Pow(1-math.Pow(1-1.0/32, k), 8)
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	return rate
+}
+
 // Reset clears the content of the filter f.
 func (f SplitBlockFilter) Reset() {
 	for i := range f {
@@ -64,6 +101,56 @@ func (f SplitBlockFilter) Block(x uint64) *Block { return &f[fasthash1x64(x, int
 // InsertBulk adds all values from x into f.
 func (f SplitBlockFilter) InsertBulk(x []uint64) { filterInsertBulk(f, x) }
 
+// InsertBulkParallel is like InsertBulk but distributes the insertion of values
+// across up to concurrency goroutines. The values are partitioned by the range
+// of blocks they hash to, so each goroutine updates a disjoint set of blocks
+// and no synchronization is needed between them.
+//
+// The method is intended to be used when inserting large batches of values,
+// small batches are inserted by the calling goroutine.
+func (f SplitBlockFilter) InsertBulkParallel(x []uint64, concurrency int) {
+	if concurrency > len(f) {
+		concurrency = len(f)
+	}
+	if concurrency <= 1 || len(x) < minParallelInsertBulk {
+		f.InsertBulk(x)
+		return
+	}
+
+	scale := int32(len(f))
+	partition := func(x uint64) int {
+		return int(fasthash1x64(x, scale) * uint64(concurrency) / uint64(len(f)))
+	}
+
+	offsets := make([]int, concurrency+1)
+	for _, v := range x {
+		offsets[partition(v)+1]++
+	}
+	for i := 1; i < len(offsets); i++ {
+		offsets[i] += offsets[i-1]
+	}
+
+	values := make([]uint64, len(x))
+	cursors := append([]int{}, offsets[:concurrency]...)
+	for _, v := range x {
+		p := partition(v)
+		values[cursors[p]] = v
+		cursors[p]++
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		if part := values[offsets[i]:offsets[i+1]]; len(part) > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				f.InsertBulk(part)
+			}()
+		}
+	}
+	wg.Wait()
+}
+
 // Insert adds x to f.
 func (f SplitBlockFilter) Insert(x uint64) { filterInsert(f, x) }
 
@@ -90,6 +177,11 @@ func CheckSplitBlock(r io.ReaderAt, n int64, x uint64) (bool, error) {
 	return block.Check(uint32(x)), err
 }
 
+// Minimum number of values inserted by InsertBulkParallel to use multiple
+// goroutines, below which the cost of partitioning the values exceeds the
+// gains of parallelism.
+const minParallelInsertBulk = 64 * 1024
+
 var (
 	_ MutableFilter = (SplitBlockFilter)(nil)
 
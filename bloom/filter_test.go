@@ -2,6 +2,7 @@ package bloom_test
 
 import (
 	"bytes"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -108,6 +109,58 @@ func newSerializedFilter(b []byte) *serializedFilter {
 	return f
 }
 
+func TestSplitBlockFilterInsertBulkParallel(t *testing.T) {
+	const N = 200e3
+	p := rand.New(rand.NewSource(0))
+	x := make([]uint64, N)
+	for i := range x {
+		x[i] = p.Uint64()
+	}
+
+	for _, concurrency := range []int{1, 2, 3, 8} {
+		f1 := make(bloom.SplitBlockFilter, bloom.NumSplitBlocksOf(N, 10))
+		f2 := make(bloom.SplitBlockFilter, bloom.NumSplitBlocksOf(N, 10))
+		f1.InsertBulk(x)
+		f2.InsertBulkParallel(x, concurrency)
+
+		if !bytes.Equal(f1.Bytes(), f2.Bytes()) {
+			t.Errorf("concurrency=%d: filters built in parallel and sequentially differ", concurrency)
+		}
+	}
+}
+
+func TestSplitBlockFalsePositiveRate(t *testing.T) {
+	const N = 100e3
+	p := rand.New(rand.NewSource(0))
+
+	for _, bitsPerValue := range []uint{4, 8, 10, 16} {
+		f := make(bloom.SplitBlockFilter, bloom.NumSplitBlocksOf(N, bitsPerValue))
+		for i := 0; i < N; i++ {
+			f.Insert(p.Uint64())
+		}
+
+		falsePositives := 0
+		for i := 0; i < N; i++ {
+			if f.Check(p.Uint64()) {
+				falsePositives++
+			}
+		}
+
+		want := float64(falsePositives) / N
+		got := bloom.SplitBlockFalsePositiveRate(len(f), N)
+		if math.Abs(got-want) > 0.1*want+0.001 {
+			t.Errorf("bits per value=%d: estimated false positive rate %.5f differs from the measured rate %.5f", bitsPerValue, got, want)
+		}
+	}
+
+	if rate := bloom.SplitBlockFalsePositiveRate(10, 0); rate != 0 {
+		t.Errorf("empty filter has non-zero false positive rate: %g", rate)
+	}
+	if rate := bloom.SplitBlockFalsePositiveRate(1, 1e9); rate < 0.999 || rate > 1 {
+		t.Errorf("saturated filter has a false positive rate of %g", rate)
+	}
+}
+
 func BenchmarkFilterInsertBulk(b *testing.B) {
 	f := make(bloom.SplitBlockFilter, 99)
 	x := make([]uint64, 16)
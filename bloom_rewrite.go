@@ -0,0 +1,190 @@
+package parquet
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// AddBloomFilters writes to output a copy of the parquet file f with bloom
+// filters generated for the given columns, which allows adding bloom filters
+// to files written without them.
+//
+// The column chunks are copied byte-for-byte, only the values of the columns
+// that filters are generated for are read. The filters of column chunks where
+// all data pages are dictionary encoded are generated from the dictionaries,
+// which avoids reading the data pages. The existing bloom filters of the other
+// columns are preserved, and the page index and metadata of the file are
+// rewritten to account for the new location of the column chunks.
+//
+// The function returns an error if the filters are configured for paths that
+// do not name leaf columns of the file's schema, or if the column chunks of the
+// file are stored in external files.
+func AddBloomFilters(output io.Writer, f *File, filters ...BloomFilterColumn) error {
+	if err := validateBloomFilters(f.Schema(), filters); err != nil {
+		return err
+	}
+
+	rowGroups, err := f.rowGroupsMetadata()
+	if err != nil {
+		return err
+	}
+	columnIndexes, offsetIndexes, err := f.ReadPageIndex()
+	if err != nil {
+		return fmt.Errorf("reading page index: %w", err)
+	}
+
+	metadata := f.metadata
+	metadata.RowGroups = make([]format.RowGroup, len(rowGroups))
+
+	buffer := bufio.NewWriterSize(output, DefaultWriteBufferSize)
+	w := offsetTrackingWriter{}
+	w.Reset(buffer)
+
+	if _, err := w.WriteString("PAR1"); err != nil {
+		return err
+	}
+
+	for i := range rowGroups {
+		rowGroup := &metadata.RowGroups[i]
+		*rowGroup = rowGroups[i]
+		rowGroup.Columns = append([]format.ColumnChunk{}, rowGroups[i].Columns...)
+		rowGroup.FileOffset = w.offset
+
+		for j := range rowGroup.Columns {
+			var offsetIndex *format.OffsetIndex
+			if offsetIndexes != nil {
+				offsetIndex = &offsetIndexes[i*len(rowGroup.Columns)+j]
+			}
+			if err := copyColumnChunk(&w, f.reader, &rowGroup.Columns[j], offsetIndex); err != nil {
+				return fmt.Errorf("copying column chunk %d of row group %d: %w", j, i, err)
+			}
+		}
+
+		// The bloom filters are written after the column chunks of the row
+		// group, since their size is only known once they were generated.
+		chunks := f.RowGroups()[i].ColumnChunks()
+
+		for j := range rowGroup.Columns {
+			c := &rowGroup.Columns[j]
+			chunk := chunks[j].(*fileColumnChunk)
+			path := columnPath(chunk.column.Path())
+			offset := c.MetaData.BloomFilterOffset
+			c.MetaData.BloomFilterOffset = 0
+
+			if filter := searchBloomFilterColumn(filters, path); filter != nil {
+				bits, err := generateBloomFilter(filter, chunk)
+				if err != nil {
+					return fmt.Errorf("generating bloom filter of column %q in row group %d: %w", path, i, err)
+				}
+				c.MetaData.BloomFilterOffset = w.offset
+				if err := writeBloomFilter(&w, filter, bits); err != nil {
+					return err
+				}
+			} else if offset > 0 {
+				c.MetaData.BloomFilterOffset = w.offset
+				if err := copyBloomFilter(&w, f.reader, offset); err != nil {
+					return fmt.Errorf("copying bloom filter of column %q in row group %d: %w", path, i, err)
+				}
+			}
+		}
+	}
+
+	if err := writePageIndexAndFooter(&w, &metadata, columnIndexes, offsetIndexes); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+// rowGroupsMetadata returns the metadata of all the row groups of f, decoding
+// them if the file was opened with LazyRowGroups.
+func (f *File) rowGroupsMetadata() ([]format.RowGroup, error) {
+	if f.lazy != nil {
+		return f.lazy.metadata(f)
+	}
+	return f.metadata.RowGroups, nil
+}
+
+// generateBloomFilter returns the bits of a bloom filter holding the values of
+// the column chunk, which are read from the dictionary when all the data pages
+// use it.
+func generateBloomFilter(filter BloomFilterColumn, chunk *fileColumnChunk) ([]byte, error) {
+	metadata := &chunk.chunk.MetaData
+	typ := chunk.column.Type()
+
+	if metadata.DictionaryPageOffset != 0 && isDictionaryEncoded(metadata.EncodingStats) {
+		pages := new(filePages)
+		pages.init(chunk)
+		defer pages.Close()
+
+		if err := pages.readDictionary(); err != nil {
+			return nil, err
+		}
+		dict := pages.dataPage.dictionary
+		bits := make([]byte, filter.Size(int64(dict.Len()), bloomFilterBitsPerValue))
+		return typ.Encode(bits, dict.Page().Data(), filter.Encoding())
+	}
+
+	bits := make([]byte, filter.Size(metadata.NumValues, bloomFilterBitsPerValue))
+	buffer := typ.NewColumnBuffer(0, filterEncodeBufferSize)
+	values := make([]Value, filterEncodeBufferSize)
+
+	pages := chunk.Pages()
+	defer pages.Close()
+
+	for {
+		page, err := pages.ReadPage()
+		if err != nil {
+			if err == io.EOF {
+				return bits, nil
+			}
+			return nil, err
+		}
+
+		reader := page.Values()
+		for {
+			n, err := reader.ReadValues(values)
+			// Null values are not inserted in bloom filters, the values are
+			// compacted to retain only the non-null ones.
+			nonNull := values[:0]
+			for _, v := range values[:n] {
+				if !v.IsNull() {
+					nonNull = append(nonNull, v)
+				}
+			}
+			if len(nonNull) > 0 {
+				buffer.Reset()
+				if _, err := buffer.WriteValues(nonNull); err != nil {
+					return nil, err
+				}
+				var encodeErr error
+				if bits, encodeErr = typ.Encode(bits, buffer.Page().Data(), filter.Encoding()); encodeErr != nil {
+					return nil, encodeErr
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, err
+			}
+		}
+	}
+}
+
+func writeBloomFilter(w io.Writer, filter BloomFilterColumn, bits []byte) error {
+	h := bloomFilterHeader(filter)
+	h.NumBytes = int32(len(bits))
+	b, err := thrift.Marshal(new(thrift.CompactProtocol), &h)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	_, err = w.Write(bits)
+	return err
+}
@@ -0,0 +1,154 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/format"
+)
+
+func TestAddBloomFilters(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Name  string  `parquet:"name"`
+		Color string  `parquet:"color,dict"`
+		Note  *string `parquet:"note,optional"`
+		Tags  []int64 `parquet:"tags"`
+	}
+
+	rows := make([]Row, 3000)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    int64(i),
+			Name:  fmt.Sprintf("name-%d", i),
+			Color: fmt.Sprintf("color-%d", i%20),
+		}
+		if i%3 == 0 {
+			note := fmt.Sprintf("note-%d", i)
+			rows[i].Note = &note
+		}
+		for j := 0; j < i%3; j++ {
+			rows[i].Tags = append(rows[i].Tags, int64(10*i+j))
+		}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b,
+		parquet.PageBufferSize(4096),
+		parquet.BloomFilters(parquet.SplitBlockFilter("id")),
+	)
+	for i := range rows {
+		if err := w.Write(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+		if i%1000 == 999 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lazy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("lazy=%t", lazy), func(t *testing.T) {
+			f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()), parquet.LazyRowGroups(lazy))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			output := new(bytes.Buffer)
+			err = parquet.AddBloomFilters(output, f,
+				parquet.SplitBlockFilter("name"),
+				parquet.SplitBlockFilter("color"),
+				parquet.SplitBlockFilter("note"),
+				parquet.SplitBlockFilter("tags"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g, err := parquet.OpenFile(bytes.NewReader(output.Bytes()), int64(output.Len()), parquet.VerifyColumnChunks(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if g.NumRows() != int64(len(rows)) || len(g.RowGroups()) != 3 {
+				t.Fatalf("wrong number of rows or row groups: rows=%d row groups=%d", g.NumRows(), len(g.RowGroups()))
+			}
+
+			// The column chunks are copied byte-for-byte.
+			src := readFileMetaData(t, b.Bytes())
+			dst := readFileMetaData(t, output.Bytes())
+			chunkData := func(data []byte, c *format.ColumnMetaData) []byte {
+				offset := c.DataPageOffset
+				if c.DictionaryPageOffset != 0 && c.DictionaryPageOffset < offset {
+					offset = c.DictionaryPageOffset
+				}
+				return data[offset : offset+c.TotalCompressedSize]
+			}
+			for i := range src.RowGroups {
+				for j := range src.RowGroups[i].Columns {
+					c1 := &src.RowGroups[i].Columns[j].MetaData
+					c2 := &dst.RowGroups[i].Columns[j].MetaData
+					if !bytes.Equal(chunkData(b.Bytes(), c1), chunkData(output.Bytes(), c2)) {
+						t.Errorf("column chunk %d of row group %d was not copied byte-for-byte", j, i)
+					}
+				}
+			}
+
+			r := parquet.NewReader(g)
+			for i, want := range rows {
+				got := Row{}
+				if err := r.Read(&got); err != nil {
+					t.Fatalf("reading row %d: %v", i, err)
+				}
+				if len(got.Tags) == 0 {
+					got.Tags = nil
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want, got)
+				}
+			}
+
+			for i, row := range rows {
+				rowGroup := g.RowGroups()[i/1000]
+				mayContain := func(value interface{}, path ...string) {
+					t.Helper()
+					chunk, _ := parquet.LookupColumnChunk(rowGroup, path...)
+					if chunk.BloomFilter() == nil {
+						t.Fatalf("column %q has no bloom filter", path)
+					}
+					if ok, err := parquet.MayContain(chunk, parquet.ValueOf(value)); err != nil || !ok {
+						t.Fatalf("value %v of row %d not found in the bloom filter of column %q: %v", value, i, path, err)
+					}
+				}
+				mayContain(row.ID, "id")
+				mayContain(row.Name, "name")
+				mayContain(row.Color, "color")
+				if row.Note != nil {
+					mayContain(*row.Note, "note")
+				}
+				for _, tag := range row.Tags {
+					mayContain(tag, "tags")
+				}
+			}
+
+			chunk, _ := parquet.LookupColumnChunk(g.RowGroups()[0], "name")
+			if ok, _ := parquet.MayContain(chunk, parquet.ValueOf("name-2500")); ok {
+				t.Error("the bloom filter of the first row group contains a value of the last one")
+			}
+		})
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.AddBloomFilters(new(bytes.Buffer), f, parquet.SplitBlockFilter("missing")); err == nil {
+		t.Error("no error adding a bloom filter to a column that does not exist")
+	}
+}
@@ -1,12 +1,15 @@
 package parquet
 
 import (
+	"bytes"
 	"math/rand"
 	"testing"
 
+	"github.com/segmentio/encoding/thrift"
 	"github.com/segmentio/parquet-go/bloom"
 	"github.com/segmentio/parquet-go/deprecated"
 	"github.com/segmentio/parquet-go/encoding/plain"
+	"github.com/segmentio/parquet-go/format"
 	"github.com/segmentio/parquet-go/internal/quick"
 	"github.com/segmentio/parquet-go/internal/unsafecast"
 )
@@ -170,3 +173,44 @@ func BenchmarkSplitBlockFilter(b *testing.B) {
 
 	b.SetBytes(8 * N)
 }
+
+func TestNewBloomFilter(t *testing.T) {
+	newFile := func(h format.BloomFilterHeader) []byte {
+		b, err := thrift.Marshal(new(thrift.CompactProtocol), &h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return append(b, make([]byte, h.NumBytes)...)
+	}
+
+	supported := format.BloomFilterHeader{
+		NumBytes:    bloom.BlockSize,
+		Algorithm:   format.BloomFilterAlgorithm{Block: &format.SplitBlockAlgorithm{}},
+		Hash:        format.BloomFilterHash{XxHash: &format.XxHash{}},
+		Compression: format.BloomFilterCompression{Uncompressed: &format.BloomFilterUncompressed{}},
+	}
+	file := bytes.NewReader(newFile(supported))
+	filter, err := newBloomFilter(file, file, 0, new(bloomFilterCache), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter == nil || filter.Size() != bloom.BlockSize {
+		t.Fatalf("wrong bloom filter: %+v", filter)
+	}
+
+	unsupported := supported
+	unsupported.Compression = format.BloomFilterCompression{}
+	file = bytes.NewReader(newFile(unsupported))
+	filter, err = newBloomFilter(file, file, 0, new(bloomFilterCache), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter != nil {
+		t.Error("bloom filter with an unsupported compression was not nil")
+	}
+
+	file = bytes.NewReader([]byte{0xFF, 0xFF, 0xFF})
+	if _, err := newBloomFilter(file, file, 0, new(bloomFilterCache), nil); err == nil {
+		t.Error("no error returned for an invalid bloom filter header")
+	}
+}
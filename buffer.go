@@ -1,6 +1,8 @@
 package parquet
 
 import (
+	"fmt"
+	"io"
 	"sort"
 )
 
@@ -16,7 +18,8 @@ type Buffer struct {
 	colbuf  [][]Value
 	chunks  []ColumnChunk
 	columns []ColumnBuffer
-	sorted  []ColumnBuffer
+	sorted  []interface{ Less(i, j int) bool }
+	keys    []*sortingKeyBuffer
 }
 
 // NewBuffer constructs a new buffer, using the given list of buffer options
@@ -56,10 +59,32 @@ func (buf *Buffer) configure(schema *Schema) {
 		return
 	}
 	sortingColumns := buf.config.SortingColumns
-	buf.sorted = make([]ColumnBuffer, len(sortingColumns))
+	buf.sorted = make([]interface{ Less(i, j int) bool }, len(sortingColumns))
+	buf.keys = nil
+
+	// Sorting keys are computed from the rows when the buffer is sorted, see
+	// computeSortingKeys.
+	for i, sortingColumn := range sortingColumns {
+		if key := sortingKeyOf(sortingColumn); key != nil {
+			keys := &sortingKeyBuffer{key: key.key, compare: compareSortingKeyFuncOf(sortingColumn)}
+			buf.sorted[i] = keys
+			buf.keys = append(buf.keys, keys)
+		}
+	}
 
 	forEachLeafColumnOf(schema, func(leaf leafColumn) {
 		nullOrdering := nullsGoLast
+		sortingIndex := searchSortingColumn(sortingColumns, leaf.path)
+		if sortingIndex < len(sortingColumns) && buf.sorted[sortingIndex] != nil {
+			sortingIndex = len(sortingColumns)
+		}
+		if sortingIndex < len(sortingColumns) {
+			// The order of null values is inverted as well when the column
+			// is sorted in descending order, see reversedColumnBuffer.
+			if sortingColumns[sortingIndex].NullsFirst() != sortingColumns[sortingIndex].Descending() {
+				nullOrdering = nullsGoFirst
+			}
+		}
 		columnIndex := int(leaf.columnIndex)
 		columnType := leaf.node.Type()
 		bufferCap := buf.config.ColumnBufferCapacity
@@ -73,6 +98,9 @@ func (buf *Buffer) configure(schema *Schema) {
 		}
 
 		column := columnType.NewColumnBuffer(columnIndex, bufferCap)
+		if allocator := buf.config.Allocator; allocator != nil {
+			setColumnBufferAllocator(column, allocator)
+		}
 		switch {
 		case leaf.maxRepetitionLevel > 0:
 			column = newRepeatedColumnBuffer(column, leaf.maxRepetitionLevel, leaf.maxDefinitionLevel, nullOrdering)
@@ -81,17 +109,24 @@ func (buf *Buffer) configure(schema *Schema) {
 		}
 		buf.columns = append(buf.columns, column)
 
-		if sortingIndex := searchSortingColumn(sortingColumns, leaf.path); sortingIndex < len(sortingColumns) {
+		if sortingIndex < len(sortingColumns) {
 			if sortingColumns[sortingIndex].Descending() {
 				column = &reversedColumnBuffer{column}
 			}
-			if sortingColumns[sortingIndex].NullsFirst() {
-				nullOrdering = nullsGoFirst
-			}
 			buf.sorted[sortingIndex] = column
 		}
 	})
 
+	// Sorting columns which do not name leaf columns of the schema are
+	// ignored.
+	sorted := buf.sorted[:0]
+	for _, column := range buf.sorted {
+		if column != nil {
+			sorted = append(sorted, column)
+		}
+	}
+	buf.sorted = sorted
+
 	buf.schema = schema
 	buf.rowbuf = make([]Row, 0, 1)
 	buf.colbuf = make([][]Value, len(buf.columns))
@@ -153,6 +188,9 @@ func (buf *Buffer) Len() int {
 
 // Less returns true if row[i] < row[j] in the buffer.
 func (buf *Buffer) Less(i, j int) bool {
+	if len(buf.keys) > 0 {
+		buf.computeSortingKeys()
+	}
 	for _, col := range buf.sorted {
 		switch {
 		case col.Less(i, j):
@@ -166,9 +204,15 @@ func (buf *Buffer) Less(i, j int) bool {
 
 // Swap exchanges the rows at indexes i and j.
 func (buf *Buffer) Swap(i, j int) {
+	if len(buf.keys) > 0 {
+		buf.computeSortingKeys()
+	}
 	for _, col := range buf.columns {
 		col.Swap(i, j)
 	}
+	for _, keys := range buf.keys {
+		keys.Swap(i, j)
+	}
 }
 
 // Reset clears the content of the buffer, allowing it to be reused.
@@ -176,6 +220,97 @@ func (buf *Buffer) Reset() {
 	for _, col := range buf.columns {
 		col.Reset()
 	}
+	for _, keys := range buf.keys {
+		clearValues(keys.values)
+		keys.values = keys.values[:0]
+	}
+}
+
+// Truncate removes the rows of the buffer after the first n rows, for example
+// to retract rows appended by a transaction that failed. The column buffers are
+// truncated in place, their memory is retained to be reused by the next writes.
+// Values added to the dictionaries of the columns by the removed rows are
+// retained, the dictionaries remain consistent with the rows of the buffer.
+//
+// The method panics if n is negative or greater than the number of rows.
+func (buf *Buffer) Truncate(n int) {
+	if n < 0 || n > buf.Len() {
+		panic(fmt.Sprintf("cannot truncate buffer of %d rows to %d rows", buf.Len(), n))
+	}
+	for _, col := range buf.columns {
+		col.truncate(n)
+	}
+	buf.truncateSortingKeys(n)
+}
+
+// Delete removes the rows at indexes i through j-1 of the buffer. The rows
+// which follow are moved to fill the gap and retain their order, like Truncate
+// the values of the dictionaries are retained.
+//
+// The method panics if the indexes are out of range.
+func (buf *Buffer) Delete(i, j int) {
+	if i < 0 || i > j || j > buf.Len() {
+		panic(fmt.Sprintf("cannot delete rows [%d:%d] of buffer of %d rows", i, j, buf.Len()))
+	}
+	if i == j {
+		return
+	}
+	// Sorting keys of the rows after i are computed again when needed, the
+	// rows are moved without computing them.
+	buf.truncateSortingKeys(i)
+
+	numRows := buf.Len()
+	for _, col := range buf.columns {
+		for k := j; k < numRows; k++ {
+			col.Swap(k-(j-i), k)
+		}
+		col.truncate(numRows - (j - i))
+	}
+}
+
+func (buf *Buffer) truncateSortingKeys(n int) {
+	for _, keys := range buf.keys {
+		if n < len(keys.values) {
+			clearValues(keys.values[n:])
+			keys.values = keys.values[:n]
+		}
+	}
+}
+
+// computeSortingKeys computes the sorting keys of the rows written to the
+// buffer since the keys were last computed. Keys are computed lazily because
+// rows may be written to the buffer in many ways (e.g. by copying pages), and
+// are then kept in the same order as the rows by Swap.
+func (buf *Buffer) computeSortingKeys() {
+	numRows := buf.Len()
+	rowIndex := len(buf.keys[0].values)
+	if rowIndex >= numRows {
+		return
+	}
+
+	rows := &rowGroupRows{rowGroup: buf}
+	defer rows.Close()
+	if err := rows.SeekToRow(int64(rowIndex)); err != nil {
+		panic(err)
+	}
+
+	rowbuf := make([]Row, defaultRowBufferSize)
+	for rowIndex < numRows {
+		n, err := rows.ReadRows(rowbuf)
+		for _, row := range rowbuf[:n] {
+			for _, keys := range buf.keys {
+				keys.values = append(keys.values, keys.key(row).Clone())
+			}
+		}
+		rowIndex += n
+		if err != nil {
+			if err == io.EOF && rowIndex == numRows {
+				break
+			}
+			// Rows are read from memory, errors indicate a bug in the buffer.
+			panic(err)
+		}
+	}
 }
 
 // Write writes a row held in a Go value to the buffer.
@@ -226,6 +361,12 @@ func (buf *Buffer) WriteRows(rows []Row) (int, error) {
 }
 
 // WriteRowGroup satisfies the RowGroupWriter interface.
+//
+// When the schema of the row group differs from the schema of the buffer, the
+// rows are converted to the buffer schema as they are copied, following the
+// rules of Convert (e.g. extra columns of the row group are dropped, columns
+// missing from the row group are set to null). The method returns an error
+// wrapping ErrRowGroupSchemaMismatch if the schemas are not convertible.
 func (buf *Buffer) WriteRowGroup(rowGroup RowGroup) (int64, error) {
 	rowGroupSchema := rowGroup.Schema()
 	switch {
@@ -233,8 +374,11 @@ func (buf *Buffer) WriteRowGroup(rowGroup RowGroup) (int64, error) {
 		return 0, ErrRowGroupSchemaMissing
 	case buf.schema == nil:
 		buf.configure(rowGroupSchema)
-	case !nodesAreEqual(buf.schema, rowGroupSchema):
-		return 0, ErrRowGroupSchemaMismatch
+	default:
+		var err error
+		if rowGroup, err = convertRowGroupForWrite(rowGroup, buf.schema); err != nil {
+			return 0, err
+		}
 	}
 	if !sortingColumnsHavePrefix(rowGroup.SortingColumns(), buf.SortingColumns()) {
 		return 0, ErrRowGroupSortingColumnsMismatch
@@ -250,7 +394,17 @@ func (buf *Buffer) WriteRowGroup(rowGroup RowGroup) (int64, error) {
 //
 // The buffer and the returned reader share memory. Mutating the buffer
 // concurrently to reading rows may result in non-deterministic behavior.
-func (buf *Buffer) Rows() Rows { return &rowGroupRows{rowGroup: buf} }
+func (buf *Buffer) Rows() Rows {
+	rows := &rowGroupRows{rowGroup: buf}
+	if policy := buf.config.DuplicateRows; policy != KeepDuplicateRows && buf.schema != nil {
+		// Duplicates are only adjacent when the buffer was sorted, which is
+		// the responsibility of the program.
+		if columns := mergeSortColumnsOf(buf.schema, buf.SortingColumns()); len(columns) > 0 {
+			return newDedupRows(rows, columns, policy)
+		}
+	}
+	return rows
+}
 
 // bufferWriter is an adapter for Buffer which implements both RowWriter and
 // PageWriter to enable optimizations in CopyRows for types that support writing
@@ -81,6 +81,36 @@ func testGenericBufferRows[Row any](rows []Row) error {
 	return nil
 }
 
+func TestGenericBufferNullPolicy(t *testing.T) {
+	type Row struct {
+		Count int32   `parquet:"count,optional(nil)"`
+		Name  string  `parquet:"name,optional(nil)"`
+		Label *string `parquet:"label,optional(nil)"`
+		Total int32   `parquet:"total,optional"`
+	}
+
+	buffer := parquet.NewGenericBuffer[Row]()
+	if _, err := buffer.Write([]Row{{}, {Count: 1, Name: "A", Total: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := buffer.Rows()
+	defer rows.Close()
+
+	got := make([]parquet.Row, 2)
+	if n, err := rows.ReadRows(got); n != 2 {
+		t.Fatalf("reading rows: n=%d err=%v", n, err)
+	}
+
+	for i, want := range [][]int{{1, 1, 0, 0}, {1, 1, 0, 1}} {
+		for j, v := range got[i] {
+			if v.DefinitionLevel() != want[j] {
+				t.Errorf("row %d: wrong definition level of column %d: want=%d got=%d", i, j, want[j], v.DefinitionLevel())
+			}
+		}
+	}
+}
+
 func setNullPointers[Row any](rows []Row) {
 	if len(rows) > 0 && reflect.TypeOf(rows[0]).Kind() == reflect.Pointer {
 		for i := range rows {
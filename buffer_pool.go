@@ -0,0 +1,66 @@
+package parquet
+
+import (
+	"math/bits"
+	"sync"
+	"unsafe"
+)
+
+const (
+	// Page buffers are pooled in size classes which are powers of two between
+	// the minimum and maximum sizes; buffers of larger pages are not retained
+	// to avoid holding on to large amounts of memory after reading outliers.
+	minPageBufferSizeClass = 10 // 1 KiB
+	maxPageBufferSizeClass = 26 // 64 MiB
+	numPageBufferSizeClass = maxPageBufferSizeClass - minPageBufferSizeClass + 1
+)
+
+// Pools of buffers used to hold the compressed and decompressed data of pages
+// read from parquet files, shared by all codecs and columns. The pools retain
+// pointers to the first byte of buffers instead of slices to avoid allocating
+// when converting them to interface values.
+var pageBufferPools [numPageBufferSizeClass]sync.Pool // *byte
+
+// acquirePageBuffer returns a zero-length buffer with a capacity of at least
+// size bytes, taken from the pool of the smallest size class able to hold it.
+func acquirePageBuffer(size int) []byte {
+	class := pageBufferSizeClass(size)
+	if class > maxPageBufferSizeClass {
+		return make([]byte, 0, size)
+	}
+	if p, _ := pageBufferPools[class-minPageBufferSizeClass].Get().(*byte); p != nil {
+		return unsafe.Slice(p, 1<<class)[:0]
+	}
+	return make([]byte, 0, 1<<class)
+}
+
+// releasePageBuffer returns b to the pool of the largest size class that its
+// capacity can hold. The program must not use the buffer after the call.
+func releasePageBuffer(b []byte) {
+	if cap(b) < 1<<minPageBufferSizeClass {
+		return
+	}
+	class := bits.Len(uint(cap(b))) - 1
+	if class > maxPageBufferSizeClass {
+		return
+	}
+	pageBufferPools[class-minPageBufferSizeClass].Put(&b[:1][0])
+}
+
+// growPageBuffer returns a zero-length buffer with a capacity of at least size
+// bytes, which is b if it is large enough. When b is too small, it is not
+// released to the pool since it may be owned by the application, or still be
+// referenced by values read from the previous page.
+func growPageBuffer(b []byte, size int) []byte {
+	if cap(b) < size {
+		return acquirePageBuffer(size)
+	}
+	return b[:0]
+}
+
+func pageBufferSizeClass(size int) int {
+	if size <= 1<<minPageBufferSizeClass {
+		return minPageBufferSizeClass
+	}
+	return bits.Len(uint(size - 1))
+}
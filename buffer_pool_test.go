@@ -0,0 +1,60 @@
+package parquet
+
+import "testing"
+
+func TestPageBufferSizeClasses(t *testing.T) {
+	tests := []struct {
+		size     int
+		capacity int
+	}{
+		{size: 0, capacity: 1024},
+		{size: 1, capacity: 1024},
+		{size: 1024, capacity: 1024},
+		{size: 1025, capacity: 2048},
+		{size: 1 << 20, capacity: 1 << 20},
+		{size: 1<<20 + 1, capacity: 1 << 21},
+		{size: 1 << 26, capacity: 1 << 26},
+		// Buffers larger than the maximum size class are not rounded up
+		// since they are not retained by the pools.
+		{size: 1<<26 + 1, capacity: 1<<26 + 1},
+	}
+
+	for _, test := range tests {
+		b := acquirePageBuffer(test.size)
+		if len(b) != 0 {
+			t.Errorf("buffer of size %d has a non-zero length: %d", test.size, len(b))
+		}
+		if cap(b) != test.capacity {
+			t.Errorf("buffer of size %d has the wrong capacity: want=%d got=%d", test.size, test.capacity, cap(b))
+		}
+		releasePageBuffer(b)
+	}
+}
+
+func TestPageBufferRelease(t *testing.T) {
+	// Buffers which were not acquired from the pools are released to the size
+	// class that their capacity can hold, and never handed out with a larger
+	// capacity.
+	releasePageBuffer(make([]byte, 100, 3000))
+
+	for i := 0; i < 10; i++ {
+		b := acquirePageBuffer(2000)
+		if cap(b) != 2048 {
+			t.Fatalf("buffer has the wrong capacity: want=%d got=%d", 2048, cap(b))
+		}
+		b = append(b, make([]byte, cap(b))...)
+		releasePageBuffer(b)
+	}
+
+	// Buffers too small for the first size class are not retained.
+	releasePageBuffer(make([]byte, 0, 10))
+	releasePageBuffer(nil)
+
+	b := make([]byte, 0, 4096)
+	if c := growPageBuffer(b, 4096); cap(c) != cap(b) || &c[:1][0] != &b[:1][0] {
+		t.Error("buffer large enough to hold the page was not reused")
+	}
+	if c := growPageBuffer(b, 4097); cap(c) != 8192 {
+		t.Errorf("buffer too small to hold the page was not grown: capacity=%d", cap(c))
+	}
+}
@@ -570,6 +570,80 @@ type TestStruct struct {
 	A *string `parquet:"a,optional,dict"`
 }
 
+func TestBufferDropDuplicateRows(t *testing.T) {
+	type Row struct {
+		ID      int64  `parquet:"id"`
+		Version string `parquet:"version"`
+	}
+
+	input := []Row{
+		{ID: 3, Version: "a"},
+		{ID: 1, Version: "a"},
+		{ID: 2, Version: "a"},
+		{ID: 1, Version: "b"},
+		{ID: 3, Version: "b"},
+		{ID: 1, Version: "c"},
+	}
+
+	tests := []struct {
+		policy parquet.DuplicateRowPolicy
+		want   []Row
+	}{
+		{
+			policy: parquet.KeepFirstRow,
+			want:   []Row{{ID: 1, Version: "a"}, {ID: 2, Version: "a"}, {ID: 3, Version: "a"}},
+		},
+		{
+			policy: parquet.KeepLastRow,
+			want:   []Row{{ID: 1, Version: "c"}, {ID: 2, Version: "a"}, {ID: 3, Version: "b"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			buffer := parquet.NewBuffer(
+				parquet.SchemaOf(Row{}),
+				parquet.SortingColumns(parquet.Ascending("id")),
+				parquet.DropDuplicateRows(test.policy),
+			)
+			for _, row := range input {
+				if err := buffer.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sort.Stable(buffer)
+
+			output := new(bytes.Buffer)
+			writer := parquet.NewWriter(output)
+			if _, err := writer.WriteRowGroup(buffer); err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader := parquet.NewReader(bytes.NewReader(output.Bytes()))
+			defer reader.Close()
+
+			got := []Row{}
+			for {
+				var row Row
+				if err := reader.Read(&row); err != nil {
+					if err != io.EOF {
+						t.Fatal(err)
+					}
+					break
+				}
+				got = append(got, row)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("wrong rows:\nwant = %+v\ngot  = %+v", test.want, got)
+			}
+		})
+	}
+}
+
 func TestOptionalDictWriteRowGroup(t *testing.T) {
 	s := parquet.SchemaOf(&TestStruct{})
 
@@ -664,3 +738,264 @@ func BenchmarkBufferWriteRows100x(b *testing.B) {
 		return n
 	})
 }
+
+func TestBufferWriteRowGroupConvert(t *testing.T) {
+	type RowV1 struct {
+		ID      int64  `parquet:"id"`
+		Name    string `parquet:"name"`
+		Deleted bool   `parquet:"deleted"`
+	}
+	type RowV2 struct {
+		ID    int64  `parquet:"id"`
+		Name  string `parquet:"name"`
+		Email string `parquet:"email,optional"`
+	}
+
+	source := parquet.NewBuffer()
+	for i := 0; i < 100; i++ {
+		if err := source.Write(RowV1{ID: int64(i), Name: strconv.Itoa(i), Deleted: i%2 == 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := make([]RowV2, 100)
+	for i := range want {
+		want[i] = RowV2{ID: int64(i), Name: strconv.Itoa(i)}
+	}
+
+	target := parquet.NewBuffer(parquet.SchemaOf(RowV2{}))
+	if n, err := target.WriteRowGroup(source); err != nil {
+		t.Fatal(err)
+	} else if n != 100 {
+		t.Fatalf("wrong number of rows written to the buffer: want=100 got=%d", n)
+	}
+
+	output := new(bytes.Buffer)
+	writer := parquet.NewWriter(output, parquet.SchemaOf(RowV2{}))
+	if _, err := writer.WriteRowGroup(source); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rows := range []parquet.Rows{
+		target.Rows(),
+		parquet.NewReader(bytes.NewReader(output.Bytes()), parquet.SchemaOf(RowV2{})),
+	} {
+		got := make([]RowV2, 0, len(want))
+		buf := make([]parquet.Row, 7)
+		for {
+			n, err := rows.ReadRows(buf)
+			for _, row := range buf[:n] {
+				var v RowV2
+				if err := rows.Schema().Reconstruct(&v, row); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, v)
+			}
+			if err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				break
+			}
+		}
+		rows.Close()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("rows mismatch:\nwant: %+v\ngot:  %+v", want, got)
+		}
+	}
+
+	type RowV3 struct {
+		ID string `parquet:"id"`
+	}
+	if _, err := parquet.NewBuffer(parquet.SchemaOf(RowV3{})).WriteRowGroup(source); !errors.Is(err, parquet.ErrRowGroupSchemaMismatch) {
+		t.Errorf("expected an error wrapping ErrRowGroupSchemaMismatch, got %v", err)
+	}
+}
+
+type bufferDeleteRow struct {
+	Bool   bool     `parquet:"bool"`
+	Int32  int32    `parquet:"int32"`
+	Int64  int64    `parquet:"int64"`
+	Uint64 uint64   `parquet:"uint64"`
+	Float  float32  `parquet:"float"`
+	Double float64  `parquet:"double"`
+	String string   `parquet:"string"`
+	Dict   string   `parquet:"dict,dict"`
+	Fixed  [16]byte `parquet:"fixed"`
+	Opt    *string  `parquet:"opt,optional"`
+	OptInt *int64   `parquet:"optint,optional,dict"`
+	List   []string `parquet:"list"`
+}
+
+func makeBufferDeleteRow(i int) bufferDeleteRow {
+	row := bufferDeleteRow{
+		Bool:   i%3 == 0,
+		Int32:  int32(i),
+		Int64:  int64(-i),
+		Uint64: uint64(i) * 3,
+		Float:  float32(i) / 2,
+		Double: float64(i) / 4,
+		String: "string-" + strconv.Itoa(i),
+		Dict:   "dict-" + strconv.Itoa(i%7),
+	}
+	row.Fixed[0] = byte(i)
+	if i%2 == 0 {
+		s := "opt-" + strconv.Itoa(i)
+		row.Opt = &s
+	}
+	if i%4 != 1 {
+		v := int64(i % 5)
+		row.OptInt = &v
+	}
+	for j := 0; j < i%4; j++ {
+		row.List = append(row.List, strconv.Itoa(10*i+j))
+	}
+	return row
+}
+
+func readBufferDeleteRows(t *testing.T, buffer *parquet.Buffer) []bufferDeleteRow {
+	t.Helper()
+	rows := buffer.Rows()
+	defer rows.Close()
+
+	schema := parquet.SchemaOf(new(bufferDeleteRow))
+	result := []bufferDeleteRow{}
+	rowbuf := make([]parquet.Row, 10)
+	for {
+		n, err := rows.ReadRows(rowbuf)
+		for _, row := range rowbuf[:n] {
+			v := bufferDeleteRow{}
+			if err := schema.Reconstruct(&v, row); err != nil {
+				t.Fatal(err)
+			}
+			if len(v.List) == 0 {
+				v.List = nil
+			}
+			result = append(result, v)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return result
+			}
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBufferTruncateAndDelete(t *testing.T) {
+	for _, test := range []struct {
+		scenario string
+		apply    func(*parquet.Buffer)
+		want     func([]bufferDeleteRow) []bufferDeleteRow
+	}{
+		{
+			scenario: "truncate",
+			apply:    func(b *parquet.Buffer) { b.Truncate(13) },
+			want:     func(rows []bufferDeleteRow) []bufferDeleteRow { return rows[:13] },
+		},
+		{
+			scenario: "truncate all rows",
+			apply:    func(b *parquet.Buffer) { b.Truncate(0) },
+			want:     func(rows []bufferDeleteRow) []bufferDeleteRow { return nil },
+		},
+		{
+			scenario: "truncate no rows",
+			apply:    func(b *parquet.Buffer) { b.Truncate(b.Len()) },
+			want:     func(rows []bufferDeleteRow) []bufferDeleteRow { return rows },
+		},
+		{
+			scenario: "delete",
+			apply:    func(b *parquet.Buffer) { b.Delete(5, 17) },
+			want: func(rows []bufferDeleteRow) []bufferDeleteRow {
+				return append(rows[:5:5], rows[17:]...)
+			},
+		},
+		{
+			scenario: "delete last rows",
+			apply:    func(b *parquet.Buffer) { b.Delete(20, b.Len()) },
+			want:     func(rows []bufferDeleteRow) []bufferDeleteRow { return rows[:20] },
+		},
+		{
+			scenario: "delete after sort",
+			apply: func(b *parquet.Buffer) {
+				sort.Sort(b)
+				b.Delete(0, 3)
+			},
+			want: func(rows []bufferDeleteRow) []bufferDeleteRow {
+				sorted := append([]bufferDeleteRow{}, rows...)
+				sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Int64 < sorted[j].Int64 })
+				return sorted[3:]
+			},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			buffer := parquet.NewBuffer(
+				parquet.SchemaOf(new(bufferDeleteRow)),
+				parquet.SortingColumns(parquet.Ascending("int64")),
+			)
+			rows := make([]bufferDeleteRow, 30)
+			for i := range rows {
+				rows[i] = makeBufferDeleteRow(i)
+				if err := buffer.Write(&rows[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dictLen := buffer.ColumnBuffers()[7].Dictionary().Len()
+
+			test.apply(buffer)
+			want := test.want(rows)
+			if got := readBufferDeleteRows(t, buffer); !reflect.DeepEqual(want, got) && (len(want) != 0 || len(got) != 0) {
+				t.Fatalf("rows mismatch after removing rows:\nwant: %+v\ngot:  %+v", want, got)
+			}
+			if n := buffer.ColumnBuffers()[7].Dictionary().Len(); n != dictLen {
+				t.Errorf("the dictionary was modified: want=%d got=%d values", dictLen, n)
+			}
+
+			// Rows written after removing rows follow the remaining rows, they
+			// are written in a single batch to merge the values with the last
+			// bytes of the column buffers.
+			schema := buffer.Schema()
+			batch := []parquet.Row{}
+			for i := 100; i < 111; i++ {
+				row := makeBufferDeleteRow(i)
+				batch = append(batch, schema.Deconstruct(nil, &row))
+				want = append(want, row)
+			}
+			if _, err := buffer.WriteRows(batch); err != nil {
+				t.Fatal(err)
+			}
+			if got := readBufferDeleteRows(t, buffer); !reflect.DeepEqual(want, got) {
+				t.Fatalf("rows mismatch after writing rows:\nwant: %+v\ngot:  %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestBufferDeleteOutOfRange(t *testing.T) {
+	buffer := parquet.NewBuffer(parquet.SchemaOf(new(bufferDeleteRow)))
+	for i := 0; i < 10; i++ {
+		row := makeBufferDeleteRow(i)
+		buffer.Write(&row)
+	}
+	for _, test := range []struct {
+		scenario string
+		apply    func()
+	}{
+		{scenario: "truncate negative", apply: func() { buffer.Truncate(-1) }},
+		{scenario: "truncate past the end", apply: func() { buffer.Truncate(11) }},
+		{scenario: "delete inverted range", apply: func() { buffer.Delete(5, 4) }},
+		{scenario: "delete past the end", apply: func() { buffer.Delete(5, 11) }},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic removing rows out of range")
+				}
+			}()
+			test.apply()
+		})
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"time"
 
 	"github.com/segmentio/parquet-go/compress"
 	"github.com/segmentio/parquet-go/deprecated"
 	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/encoding/bitpacked"
+	"github.com/segmentio/parquet-go/encoding/rle"
 	"github.com/segmentio/parquet-go/format"
+	"github.com/segmentio/parquet-go/internal/unsafecast"
 )
 
 // Column represents a column in a parquet file.
@@ -25,9 +29,6 @@ type Column struct {
 	order       *format.ColumnOrder
 	path        columnPath
 	columns     []*Column
-	chunks      []*format.ColumnChunk
-	columnIndex []*format.ColumnIndex
-	offsetIndex []*format.OffsetIndex
 	encoding    encoding.Encoding
 	compression compress.Codec
 
@@ -96,11 +97,24 @@ func (c *Column) Pages() Pages {
 	if c.index < 0 {
 		return emptyPages{}
 	}
+	rowGroups := c.file.RowGroups()
 	r := &columnPages{
-		pages: make([]filePages, len(c.file.rowGroups)),
-	}
-	for i := range r.pages {
-		r.pages[i].init(c.file.rowGroups[i].(*fileRowGroup).columns[c.index].(*fileColumnChunk))
+		pages: make([]filePages, 0, len(rowGroups)),
+	}
+	// The pages of all row groups share the memory reservation since those of
+	// the previous row groups are not closed until the end.
+	memory := new(memoryReservation)
+	for _, rowGroup := range rowGroups {
+		g, ok := rowGroup.(*fileRowGroup)
+		if !ok {
+			// The metadata of the row groups of files opened with the
+			// LazyRowGroups option may be invalid, the error is returned
+			// after reading the pages of the previous row groups.
+			r.err = rowGroup.(*invalidRowGroup).err
+			break
+		}
+		r.pages = append(r.pages, filePages{})
+		r.pages[len(r.pages)-1].initWithMemory(g.columns[c.index].(*fileColumnChunk), memory)
 	}
 	return r
 }
@@ -108,11 +122,15 @@ func (c *Column) Pages() Pages {
 type columnPages struct {
 	pages []filePages
 	index int
+	err   error
 }
 
 func (c *columnPages) ReadPage() (Page, error) {
 	for {
 		if c.index >= len(c.pages) {
+			if c.err != nil {
+				return nil, c.err
+			}
 			return nil, io.EOF
 		}
 		p, err := c.pages[c.index].ReadPage()
@@ -196,8 +214,12 @@ func (c *Column) forEachLeaf(do func(*Column)) {
 	}
 }
 
-func openColumns(file *File) (*Column, error) {
-	cl := columnLoader{}
+// openColumns opens the columns of file, the metadata of the given row groups
+// is used to validate the columns and select their encoding and compression.
+// It is the metadata of all the row groups, or only the first one when the file
+// was opened with the LazyRowGroups option.
+func openColumns(file *File, rowGroups []format.RowGroup) (*Column, error) {
+	cl := columnLoader{rowGroups: rowGroups}
 
 	c, err := cl.open(file, nil)
 	if err != nil {
@@ -207,7 +229,7 @@ func openColumns(file *File) (*Column, error) {
 	// Validate that there aren't extra entries in the row group columns,
 	// which would otherwise indicate that there are dangling data pages
 	// in the file.
-	for index, rowGroup := range file.metadata.RowGroups {
+	for index, rowGroup := range rowGroups {
 		if cl.rowGroupColumnIndex != len(rowGroup.Columns) {
 			return nil, fmt.Errorf("row group at index %d contains %d columns but %d were referenced by the column schemas",
 				index, len(rowGroup.Columns), cl.rowGroupColumnIndex)
@@ -262,6 +284,7 @@ func (c *Column) setLevels(depth, repetition, definition, index int) (int, error
 }
 
 type columnLoader struct {
+	rowGroups           []format.RowGroup
 	schemaIndex         int
 	columnOrderIndex    int
 	rowGroupColumnIndex int
@@ -285,40 +308,16 @@ func (cl *columnLoader) open(file *File, path []string) (*Column, error) {
 			cl.columnOrderIndex++
 		}
 
-		rowGroups := file.metadata.RowGroups
 		rowGroupColumnIndex := cl.rowGroupColumnIndex
 		cl.rowGroupColumnIndex++
 
-		c.chunks = make([]*format.ColumnChunk, 0, len(rowGroups))
-		c.columnIndex = make([]*format.ColumnIndex, 0, len(rowGroups))
-		c.offsetIndex = make([]*format.OffsetIndex, 0, len(rowGroups))
-
-		for i, rowGroup := range rowGroups {
+		for i, rowGroup := range cl.rowGroups {
 			if rowGroupColumnIndex >= len(rowGroup.Columns) {
 				return nil, fmt.Errorf("row group at index %d does not have enough columns", i)
 			}
-			c.chunks = append(c.chunks, &rowGroup.Columns[rowGroupColumnIndex])
-		}
-
-		if len(file.columnIndexes) > 0 {
-			for i := range rowGroups {
-				if rowGroupColumnIndex >= len(file.columnIndexes) {
-					return nil, fmt.Errorf("row group at index %d does not have enough column index pages", i)
-				}
-				c.columnIndex = append(c.columnIndex, &file.columnIndexes[rowGroupColumnIndex])
-			}
 		}
 
-		if len(file.offsetIndexes) > 0 {
-			for i := range rowGroups {
-				if rowGroupColumnIndex >= len(file.offsetIndexes) {
-					return nil, fmt.Errorf("row group at index %d does not have enough offset index pages", i)
-				}
-				c.offsetIndex = append(c.offsetIndex, &file.offsetIndexes[rowGroupColumnIndex])
-			}
-		}
-
-		if len(c.chunks) > 0 {
+		if len(cl.rowGroups) > 0 {
 			// Pick the encoding and compression codec of the first chunk.
 			//
 			// Technically each column chunk may use a different compression
@@ -330,11 +329,12 @@ func (cl *columnLoader) open(file *File, path []string) (*Column, error) {
 			// each page of the column should iterate through the pages and read
 			// the page headers to determine which compression and encodings are
 			// applied.
-			for _, encoding := range c.chunks[0].MetaData.Encoding {
+			chunk := &cl.rowGroups[0].Columns[rowGroupColumnIndex]
+			for _, encoding := range chunk.MetaData.Encoding {
 				c.encoding = LookupEncoding(encoding)
 				break
 			}
-			c.compression = LookupCompressionCodec(c.chunks[0].MetaData.Codec)
+			c.compression = LookupCompressionCodec(chunk.MetaData.Codec)
 		}
 
 		return c, nil
@@ -356,6 +356,22 @@ func (cl *columnLoader) open(file *File, path []string) (*Column, error) {
 		}
 	}
 
+	// Groups of the LIST and MAP logical types which have the standard layout
+	// retain their type, so programs can recognize them in the schemas of
+	// files. Groups with legacy layouts are exposed as plain groups.
+	switch t := schemaElementTypeOf(c.schema).(type) {
+	case *listType:
+		if list := c.Column("list"); list != nil && list.Repeated() && list.Column("element") != nil {
+			c.typ = t
+		}
+	case *mapType:
+		if keyValue := c.Column("key_value"); keyValue != nil && keyValue.Repeated() {
+			if key := keyValue.Column("key"); key != nil && key.Required() && keyValue.Column("value") != nil {
+				c.typ = t
+			}
+		}
+	}
+
 	return c, nil
 }
 
@@ -375,8 +391,9 @@ func schemaElementTypeOf(s *format.SchemaElement) Type {
 		case lt.Enum != nil:
 			return (*enumType)(lt.Enum)
 		case lt.Decimal != nil:
-			// TODO:
-			// return (*decimalType)(lt.Decimal)
+			if t := schemaElementPhysicalTypeOf(s); t != nil {
+				return &decimalType{decimal: *lt.Decimal, Type: t}
+			}
 		case lt.Date != nil:
 			return (*dateType)(lt.Date)
 		case lt.Time != nil:
@@ -412,7 +429,16 @@ func schemaElementTypeOf(s *format.SchemaElement) Type {
 		case deprecated.Enum:
 			return &enumType{}
 		case deprecated.Decimal:
-			// TODO
+			if t := schemaElementPhysicalTypeOf(s); t != nil {
+				decimal := format.DecimalType{}
+				if s.Scale != nil {
+					decimal.Scale = *s.Scale
+				}
+				if s.Precision != nil {
+					decimal.Precision = *s.Precision
+				}
+				return &decimalType{decimal: decimal, Type: t}
+			}
 		case deprecated.Date:
 			return &dateType{}
 		case deprecated.TimeMillis:
@@ -448,9 +474,22 @@ func schemaElementTypeOf(s *format.SchemaElement) Type {
 		}
 	}
 
+	if t := schemaElementPhysicalTypeOf(s); t != nil {
+		// The column only has a physical type, use the primitive type of this
+		// package that represents it.
+		return t
+	}
+
+	// If we reach this point, we are likely reading a parquet column that was
+	// written with a non-standard type or is in a newer version of the format
+	// than this package supports.
+	return &nullType{}
+}
+
+// schemaElementPhysicalTypeOf returns the primitive type representing the
+// physical type of s, or nil if s has no physical type or it is not supported.
+func schemaElementPhysicalTypeOf(s *format.SchemaElement) Type {
 	if t := s.Type; t != nil {
-		// The column only has a physical type, convert it to one of the
-		// primitive types supported by this package.
 		switch kind := Kind(*t); kind {
 		case Boolean:
 			return BooleanType
@@ -472,11 +511,7 @@ func schemaElementTypeOf(s *format.SchemaElement) Type {
 			}
 		}
 	}
-
-	// If we reach this point, we are likely reading a parquet column that was
-	// written with a non-standard type or is in a newer version of the format
-	// than this package supports.
-	return &nullType{}
+	return nil
 }
 
 func schemaRepetitionTypeOf(s *format.SchemaElement) format.FieldRepetitionType {
@@ -500,6 +535,9 @@ type dataPage struct {
 	data             []byte
 	values           []byte
 	dictionary       Dictionary
+	// The level decoder is reused across pages to avoid allocating memory
+	// when decoding repetition and definition levels.
+	levelDecoder rle.LevelDecoder
 }
 
 func (p *dataPage) reset() {
@@ -510,12 +548,44 @@ func (p *dataPage) reset() {
 	p.dictionary = nil
 }
 
+// releaseBuffers releases the buffers of p to the page buffer pools. It must
+// only be called on pages acquired from the data page pool, since the buffers
+// of other pages may be owned by the application.
+func (p *dataPage) releaseBuffers() {
+	releasePageBuffer(p.repetitionLevels)
+	releasePageBuffer(p.definitionLevels)
+	releasePageBuffer(p.data)
+	releasePageBuffer(p.values)
+	p.repetitionLevels = nil
+	p.definitionLevels = nil
+	p.data = nil
+	p.values = nil
+}
+
 func (p *dataPage) decompress(codec compress.Codec, data []byte) (err error) {
 	p.values, err = codec.Decode(p.values, data)
 	p.data, p.values = p.values, p.data[:0]
 	return err
 }
 
+func (c *Column) decompress(page *dataPage, data []byte) error {
+	if pool := c.file.config.CompressionPool; pool != nil {
+		return pool.do(func() error { return c.decompressPage(page, data) })
+	}
+	return c.decompressPage(page, data)
+}
+
+func (c *Column) decompressPage(page *dataPage, data []byte) error {
+	metrics := c.file.config.Metrics
+	if metrics == nil {
+		return page.decompress(c.compression, data)
+	}
+	start := time.Now()
+	err := page.decompress(c.compression, data)
+	metrics.PageDecompressed(c.compression.CompressionCodec(), int64(len(page.data)), time.Since(start))
+	return err
+}
+
 // DecodeDataPageV1 decodes a data page from the header, compressed data, and
 // optional dictionary passed as arguments.
 func (c *Column) DecodeDataPageV1(header DataPageHeaderV1, data []byte, dict Dictionary) (Page, error) {
@@ -526,7 +596,7 @@ func (c *Column) decodeDataPageV1(header DataPageHeaderV1, page *dataPage) (Page
 	var err error
 
 	if isCompressed(c.compression) {
-		if err := page.decompress(c.compression, page.data); err != nil {
+		if err := c.decompress(page, page.data); err != nil {
 			return nil, fmt.Errorf("decompressing data page v1: %w", err)
 		}
 	}
@@ -538,7 +608,7 @@ func (c *Column) decodeDataPageV1(header DataPageHeaderV1, page *dataPage) (Page
 
 	if c.maxRepetitionLevel > 0 {
 		encoding := lookupLevelEncoding(header.RepetitionLevelEncoding(), c.maxRepetitionLevel)
-		page.repetitionLevels, data, err = decodeLevelsV1(encoding, numValues, page.repetitionLevels, data)
+		page.repetitionLevels, data, err = decodeLevelsV1(encoding, numValues, page.repetitionLevels, data, &page.levelDecoder)
 		if err != nil {
 			return nil, fmt.Errorf("decoding repetition levels of data page v1: %w", err)
 		}
@@ -546,7 +616,7 @@ func (c *Column) decodeDataPageV1(header DataPageHeaderV1, page *dataPage) (Page
 
 	if c.maxDefinitionLevel > 0 {
 		encoding := lookupLevelEncoding(header.DefinitionLevelEncoding(), c.maxDefinitionLevel)
-		page.definitionLevels, data, err = decodeLevelsV1(encoding, numValues, page.definitionLevels, data)
+		page.definitionLevels, data, err = decodeLevelsV1(encoding, numValues, page.definitionLevels, data, &page.levelDecoder)
 		if err != nil {
 			return nil, fmt.Errorf("decoding definition levels of data page v1: %w", err)
 		}
@@ -575,7 +645,7 @@ func (c *Column) decodeDataPageV2(header DataPageHeaderV2, page *dataPage) (Page
 	if c.maxRepetitionLevel > 0 {
 		encoding := lookupLevelEncoding(header.RepetitionLevelEncoding(), c.maxRepetitionLevel)
 		length := header.RepetitionLevelsByteLength()
-		page.repetitionLevels, data, err = decodeLevelsV2(encoding, numValues, page.repetitionLevels, data, length)
+		page.repetitionLevels, data, err = decodeLevelsV2(encoding, numValues, page.repetitionLevels, data, length, &page.levelDecoder)
 		if err != nil {
 			return nil, fmt.Errorf("decoding repetition levels of data page v2: %w", io.ErrUnexpectedEOF)
 		}
@@ -584,14 +654,14 @@ func (c *Column) decodeDataPageV2(header DataPageHeaderV2, page *dataPage) (Page
 	if c.maxDefinitionLevel > 0 {
 		encoding := lookupLevelEncoding(header.DefinitionLevelEncoding(), c.maxDefinitionLevel)
 		length := header.DefinitionLevelsByteLength()
-		page.definitionLevels, data, err = decodeLevelsV2(encoding, numValues, page.definitionLevels, data, length)
+		page.definitionLevels, data, err = decodeLevelsV2(encoding, numValues, page.definitionLevels, data, length, &page.levelDecoder)
 		if err != nil {
 			return nil, fmt.Errorf("decoding definition levels of data page v2: %w", io.ErrUnexpectedEOF)
 		}
 	}
 
 	if isCompressed(c.compression) && header.IsCompressed() {
-		if err := page.decompress(c.compression, data); err != nil {
+		if err := c.decompress(page, data); err != nil {
 			return nil, fmt.Errorf("decompressing data page v2: %w", err)
 		}
 		data = page.data
@@ -602,35 +672,72 @@ func (c *Column) decodeDataPageV2(header DataPageHeaderV2, page *dataPage) (Page
 }
 
 func (c *Column) decodeDataPage(header DataPageHeader, numValues int64, page *dataPage, data []byte) (Page, error) {
-	encoding := LookupEncoding(header.Encoding())
+	enc := LookupEncoding(header.Encoding())
 	pageType := c.Type()
 
-	if isDictionaryEncoding(encoding) {
+	if isDictionaryEncoding(enc) {
 		// In some legacy configurations, the PLAIN_DICTIONARY encoding is used
 		// on data page headers to indicate that the page contains indexes into
 		// the dictionary page, but the page is still encoded using the RLE
-		// encoding in this case, so we convert it to RLE_DICTIONARY.
-		encoding = &RLEDictionary
-		pageType = indexedPageType{newIndexedType(pageType, page.dictionary)}
+		// encoding in this case, so both are decoded as RLE_DICTIONARY.
+		return c.decodeIndexedPage(numValues, page, data)
+	}
+
+	// When the size of the decoded values can be computed, the buffer is sized
+	// upfront so the decoder does not have to grow it.
+	if sizer, ok := enc.(encoding.DecodedSizer); ok {
+		if typ := pageType.PhysicalType(); typ != nil {
+			if size, err := sizer.DecodedSize(*typ, pageType.Length(), data); err == nil {
+				page.values = growPageBuffer(page.values, size)
+			}
+		}
 	}
 
 	var err error
-	page.values, err = pageType.Decode(page.values, data, encoding)
+	page.values, err = pageType.Decode(page.values, data, enc)
 	if err != nil {
 		return nil, err
 	}
 
 	newPage := pageType.NewPage(c.Index(), int(numValues), page.values)
+	return c.newDataPage(newPage, page), nil
+}
+
+func (c *Column) decodeIndexedPage(numValues int64, page *dataPage, data []byte) (Page, error) {
+	// The indexes are decoded directly into the buffer of the page, which is
+	// sized to hold numValues indexes so the zero padding that may be needed
+	// when pages are truncated does not have to reallocate it.
+	page.values = growPageBuffer(page.values, 4*int(numValues))
+
+	indexes, err := RLEDictionary.DecodeIndexes(unsafecast.BytesToInt32(page.values), data)
+	if err != nil {
+		return nil, err
+	}
+	page.values = unsafecast.Int32ToBytes(indexes)
+
+	pageType := indexedPageType{newIndexedType(c.Type(), page.dictionary)}
+	newPage := pageType.NewPage(c.Index(), int(numValues), page.values)
+	return c.newDataPage(newPage, page), nil
+}
+
+func (c *Column) newDataPage(newPage Page, page *dataPage) Page {
 	switch {
 	case c.maxRepetitionLevel > 0:
 		newPage = newRepeatedPage(newPage.Buffer(), c.maxRepetitionLevel, c.maxDefinitionLevel, page.repetitionLevels, page.definitionLevels)
 	case c.maxDefinitionLevel > 0:
 		newPage = newOptionalPage(newPage.Buffer(), c.maxDefinitionLevel, page.definitionLevels)
 	}
-	return newPage, nil
+	return newPage
 }
 
-func decodeLevelsV1(enc encoding.Encoding, numValues int64, levels, data []byte) ([]byte, []byte, error) {
+func decodeLevelsV1(enc encoding.Encoding, numValues int64, levels, data []byte, decoder *rle.LevelDecoder) ([]byte, []byte, error) {
+	if e, ok := enc.(*bitpacked.Encoding); ok {
+		// Levels encoded with the deprecated BIT_PACKED encoding are not
+		// prefixed with their length, which is derived from the number of
+		// values and the bit width instead.
+		length := (numValues*int64(e.BitWidth) + 7) / 8
+		return decodeLevelsV2(enc, numValues, levels, data, length, decoder)
+	}
 	if len(data) < 4 {
 		return nil, data, io.ErrUnexpectedEOF
 	}
@@ -639,21 +746,33 @@ func decodeLevelsV1(enc encoding.Encoding, numValues int64, levels, data []byte)
 	if j > len(data) {
 		return nil, data, io.ErrUnexpectedEOF
 	}
-	levels, err := decodeLevels(enc, numValues, levels, data[i:j])
+	levels, err := decodeLevels(enc, numValues, levels, data[i:j], decoder)
 	return levels, data[j:], err
 }
 
-func decodeLevelsV2(enc encoding.Encoding, numValues int64, levels, data []byte, length int64) ([]byte, []byte, error) {
+func decodeLevelsV2(enc encoding.Encoding, numValues int64, levels, data []byte, length int64, decoder *rle.LevelDecoder) ([]byte, []byte, error) {
 	if length > int64(len(data)) {
 		return nil, data, io.ErrUnexpectedEOF
 	}
-	levels, err := decodeLevels(enc, numValues, levels, data[:length])
+	levels, err := decodeLevels(enc, numValues, levels, data[:length], decoder)
 	return levels, data[length:], err
 }
 
-func decodeLevels(enc encoding.Encoding, numValues int64, levels, data []byte) ([]byte, error) {
-	if cap(levels) < int(numValues) {
-		levels = make([]byte, numValues)
+func decodeLevels(enc encoding.Encoding, numValues int64, levels, data []byte, decoder *rle.LevelDecoder) ([]byte, error) {
+	levels = growPageBuffer(levels, int(numValues))
+	if e, ok := enc.(*rle.Encoding); ok {
+		// The level decoder only produces the number of levels that the page
+		// contains, which guarantees that the buffer is never grown to hold
+		// the padding of bit-packed runs.
+		decoder.Reset(data, e.BitWidth)
+		n, err := decoder.Decode(levels[:numValues])
+		if err == io.EOF {
+			err = nil
+		}
+		if err == nil && n < int(numValues) {
+			err = fmt.Errorf("decoding level expected %d values but got only %d", numValues, n)
+		}
+		return levels[:n], err
 	}
 	levels, err := enc.DecodeLevels(levels, data)
 	if err == nil {
@@ -675,7 +794,7 @@ func (c *Column) DecodeDictionary(header DictionaryPageHeader, data []byte) (Dic
 
 func (c *Column) decodeDictionary(header DictionaryPageHeader, page *dataPage, dict *dictPage) (Dictionary, error) {
 	if isCompressed(c.compression) {
-		if err := page.decompress(c.compression, page.data); err != nil {
+		if err := c.decompress(page, page.data); err != nil {
 			return nil, fmt.Errorf("decompressing dictionary page: %w", err)
 		}
 	}
@@ -78,6 +78,11 @@ type ColumnBuffer interface {
 	// extensibility in the public APIs, we might revisit in the future if we
 	// learn about valid use cases for custom column buffer types.
 	writeValues(rows array, size, offset uintptr, levels columnLevels)
+
+	// Truncates the column to its first n rows, n must not be greater than
+	// the number of rows. The method is unexported for the same reasons as
+	// writeValues, programs remove rows with Buffer.Truncate and Buffer.Delete.
+	truncate(n int)
 }
 
 type columnLevels struct {
@@ -256,6 +261,15 @@ func (col *optionalColumnBuffer) Reset() {
 	col.definitionLevels = col.definitionLevels[:0]
 }
 
+func (col *optionalColumnBuffer) truncate(n int) {
+	// The rows are materialized in their current order first, so the non-null
+	// values of the first n rows are the first values of the base column.
+	col.Page()
+	col.base.truncate(n - countLevelsNotEqual(col.definitionLevels[:n], col.maxDefinitionLevel))
+	col.rows = col.rows[:n]
+	col.definitionLevels = col.definitionLevels[:n]
+}
+
 func (col *optionalColumnBuffer) Size() int64 {
 	return int64(4*len(col.rows)+4*len(col.sortIndex)+len(col.definitionLevels)) + col.base.Size()
 }
@@ -502,6 +516,12 @@ func (col *repeatedColumnBuffer) Page() BufferedPage {
 	if col.reordered {
 		if col.reordering == nil {
 			col.reordering = col.Clone().(*repeatedColumnBuffer)
+			// The clone is allocated on the Go heap, its base must use the
+			// same allocator since the buffers are swapped after reordering.
+			if allocator := allocatorOf(col.base); allocator != nil {
+				col.reordering.base.Reset()
+				setColumnBufferAllocator(col.reordering.base, allocator)
+			}
 		}
 
 		column := col.reordering
@@ -549,6 +569,8 @@ func (col *repeatedColumnBuffer) Page() BufferedPage {
 
 		col.swapReorderingBuffer(column)
 		col.reordered = false
+		// Release the memory of the values that were reordered.
+		column.base.Reset()
 	}
 
 	return newRepeatedPage(
@@ -574,6 +596,19 @@ func (col *repeatedColumnBuffer) Reset() {
 	col.definitionLevels = col.definitionLevels[:0]
 }
 
+func (col *repeatedColumnBuffer) truncate(n int) {
+	if n < len(col.rows) {
+		// After materializing the rows in their current order, the levels and
+		// values of the row at index n start where the first n rows end.
+		col.Page()
+		row := col.rows[n]
+		col.base.truncate(int(row.baseOffset))
+		col.rows = col.rows[:n]
+		col.repetitionLevels = col.repetitionLevels[:row.offset]
+		col.definitionLevels = col.definitionLevels[:row.offset]
+	}
+}
+
 func (col *repeatedColumnBuffer) Size() int64 {
 	return sizeOfRegion(col.rows) + int64(len(col.repetitionLevels)) + int64(len(col.definitionLevels)) + col.base.Size()
 }
@@ -589,9 +624,12 @@ func (col *repeatedColumnBuffer) Less(i, j int) bool {
 	row1Length := repeatedRowLength(col.repetitionLevels[row1.offset:])
 	row2Length := repeatedRowLength(col.repetitionLevels[row2.offset:])
 
+	// The base column only holds the non-null values, x and y are the indexes
+	// of the next non-null values of each row.
+	x := int(row1.baseOffset)
+	y := int(row2.baseOffset)
+
 	for k := 0; k < row1Length && k < row2Length; k++ {
-		x := int(row1.baseOffset)
-		y := int(row2.baseOffset)
 		definitionLevel1 := col.definitionLevels[int(row1.offset)+k]
 		definitionLevel2 := col.definitionLevels[int(row2.offset)+k]
 		switch {
@@ -600,6 +638,12 @@ func (col *repeatedColumnBuffer) Less(i, j int) bool {
 		case less(col.base, y, x, col.maxDefinitionLevel, definitionLevel2, definitionLevel1):
 			return false
 		}
+		if definitionLevel1 == col.maxDefinitionLevel {
+			x++
+		}
+		if definitionLevel2 == col.maxDefinitionLevel {
+			y++
+		}
 	}
 
 	return row1Length < row2Length
@@ -718,7 +762,10 @@ func (col *repeatedColumnBuffer) ReadValuesAt(values []Value, offset int64) (int
 // See Type.NewColumnBuffer for details about how these types get created.
 // =============================================================================
 
-type booleanColumnBuffer struct{ booleanPage }
+type booleanColumnBuffer struct {
+	booleanPage
+	columnMemory
+}
 
 func newBooleanColumnBuffer(typ Type, columnIndex int16, numValues int32) *booleanColumnBuffer {
 	return &booleanColumnBuffer{
@@ -762,6 +809,24 @@ func (col *booleanColumnBuffer) Reset() {
 	col.bits = col.bits[:0]
 	col.offset = 0
 	col.numValues = 0
+	if col.release() {
+		col.bits = nil
+	}
+}
+
+func (col *booleanColumnBuffer) truncate(n int) {
+	col.numValues = int32(n)
+	col.bits = col.bits[:bitpack.ByteCount(uint(n))]
+	// Writes merge the bits of the last byte, the bits of the removed values
+	// must be cleared.
+	if r := n % 8; r != 0 {
+		col.bits[n/8] &= (1 << uint(r)) - 1
+	}
+}
+
+func (col *booleanColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, cap(col.bits))
+	col.bits = nil
 }
 
 func (col *booleanColumnBuffer) Cap() int { return 8 * cap(col.bits) }
@@ -812,7 +877,11 @@ func (col *booleanColumnBuffer) WriteValues(values []Value) (int, error) {
 func (col *booleanColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
 	numBytes := bitpack.ByteCount(uint(col.numValues) + uint(rows.len))
 	if cap(col.bits) < numBytes {
-		col.bits = append(make([]byte, 0, 2*cap(col.bits)), col.bits...)
+		if col.allocator == nil {
+			col.bits = append(make([]byte, 0, max(numBytes, 2*cap(col.bits))), col.bits...)
+		} else {
+			col.bits = col.grow(col.bits, numBytes)[:len(col.bits)]
+		}
 	}
 	col.bits = col.bits[:numBytes]
 	i := 0
@@ -907,7 +976,10 @@ func (col *booleanColumnBuffer) ReadValuesAt(values []Value, offset int64) (n in
 	}
 }
 
-type int32ColumnBuffer struct{ int32Page }
+type int32ColumnBuffer struct {
+	int32Page
+	columnMemory
+}
 
 func newInt32ColumnBuffer(typ Type, columnIndex int16, numValues int32) *int32ColumnBuffer {
 	return &int32ColumnBuffer{
@@ -941,7 +1013,30 @@ func (col *int32ColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *int32ColumnBuffer) Page() BufferedPage { return &col.int32Page }
 
-func (col *int32ColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *int32ColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *int32ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *int32ColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 4*cap(col.values))
+	col.values = nil
+}
+
+func (col *int32ColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]int32, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Int32ToBytes(col.values), 4*n)
+			col.values = unsafecast.BytesToInt32(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *int32ColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -957,11 +1052,13 @@ func (col *int32ColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 4) != 0 {
 		return 0, fmt.Errorf("cannot write INT32 values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 4)
 	col.values = append(col.values, unsafecast.BytesToInt32(b)...)
 	return len(b), nil
 }
 
 func (col *int32ColumnBuffer) WriteInt32s(values []int32) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -973,10 +1070,7 @@ func (col *int32ColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *int32ColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]int32, 0, max(n, 2*cap(col.values))), col.values...)
-	}
-
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1009,7 +1103,10 @@ func (col *int32ColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int,
 	}
 }
 
-type int64ColumnBuffer struct{ int64Page }
+type int64ColumnBuffer struct {
+	int64Page
+	columnMemory
+}
 
 func newInt64ColumnBuffer(typ Type, columnIndex int16, numValues int32) *int64ColumnBuffer {
 	return &int64ColumnBuffer{
@@ -1043,7 +1140,30 @@ func (col *int64ColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *int64ColumnBuffer) Page() BufferedPage { return &col.int64Page }
 
-func (col *int64ColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *int64ColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *int64ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *int64ColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 8*cap(col.values))
+	col.values = nil
+}
+
+func (col *int64ColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]int64, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Int64ToBytes(col.values), 8*n)
+			col.values = unsafecast.BytesToInt64(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *int64ColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1059,11 +1179,13 @@ func (col *int64ColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 8) != 0 {
 		return 0, fmt.Errorf("cannot write INT64 values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 8)
 	col.values = append(col.values, unsafecast.BytesToInt64(b)...)
 	return len(b), nil
 }
 
 func (col *int64ColumnBuffer) WriteInt64s(values []int64) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -1075,9 +1197,7 @@ func (col *int64ColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *int64ColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]int64, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1146,6 +1266,8 @@ func (col *int96ColumnBuffer) Page() BufferedPage { return &col.int96Page }
 
 func (col *int96ColumnBuffer) Reset() { col.values = col.values[:0] }
 
+func (col *int96ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
 func (col *int96ColumnBuffer) Cap() int { return cap(col.values) }
 
 func (col *int96ColumnBuffer) Len() int { return len(col.values) }
@@ -1203,7 +1325,10 @@ func (col *int96ColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int,
 	}
 }
 
-type floatColumnBuffer struct{ floatPage }
+type floatColumnBuffer struct {
+	floatPage
+	columnMemory
+}
 
 func newFloatColumnBuffer(typ Type, columnIndex int16, numValues int32) *floatColumnBuffer {
 	return &floatColumnBuffer{
@@ -1237,7 +1362,30 @@ func (col *floatColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *floatColumnBuffer) Page() BufferedPage { return &col.floatPage }
 
-func (col *floatColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *floatColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *floatColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *floatColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 4*cap(col.values))
+	col.values = nil
+}
+
+func (col *floatColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]float32, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Float32ToBytes(col.values), 4*n)
+			col.values = unsafecast.BytesToFloat32(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *floatColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1253,11 +1401,13 @@ func (col *floatColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 4) != 0 {
 		return 0, fmt.Errorf("cannot write FLOAT values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 4)
 	col.values = append(col.values, unsafecast.BytesToFloat32(b)...)
 	return len(b), nil
 }
 
 func (col *floatColumnBuffer) WriteFloats(values []float32) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -1269,9 +1419,7 @@ func (col *floatColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *floatColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]float32, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1304,7 +1452,10 @@ func (col *floatColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int,
 	}
 }
 
-type doubleColumnBuffer struct{ doublePage }
+type doubleColumnBuffer struct {
+	doublePage
+	columnMemory
+}
 
 func newDoubleColumnBuffer(typ Type, columnIndex int16, numValues int32) *doubleColumnBuffer {
 	return &doubleColumnBuffer{
@@ -1338,7 +1489,30 @@ func (col *doubleColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *doubleColumnBuffer) Page() BufferedPage { return &col.doublePage }
 
-func (col *doubleColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *doubleColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *doubleColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *doubleColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 8*cap(col.values))
+	col.values = nil
+}
+
+func (col *doubleColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]float64, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Float64ToBytes(col.values), 8*n)
+			col.values = unsafecast.BytesToFloat64(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *doubleColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1354,11 +1528,13 @@ func (col *doubleColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 8) != 0 {
 		return 0, fmt.Errorf("cannot write DOUBLE values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 8)
 	col.values = append(col.values, unsafecast.BytesToFloat64(b)...)
 	return len(b), nil
 }
 
 func (col *doubleColumnBuffer) WriteDoubles(values []float64) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -1370,9 +1546,7 @@ func (col *doubleColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *doubleColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]float64, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1407,7 +1581,9 @@ func (col *doubleColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int
 
 type byteArrayColumnBuffer struct {
 	byteArrayPage
-	offsets []uint32
+	offsets       []uint32
+	valuesMemory  columnMemory
+	offsetsMemory columnMemory
 }
 
 func newByteArrayColumnBuffer(typ Type, columnIndex int16, numValues int32) *byteArrayColumnBuffer {
@@ -1461,6 +1637,9 @@ func (col *byteArrayColumnBuffer) Page() BufferedPage {
 			values = plain.AppendByteArray(values, col.valueAt(offset))
 		}
 
+		if col.valuesMemory.allocator != nil {
+			values = append(col.valuesMemory.grow(nil, len(values))[:0], values...)
+		}
 		col.values = values
 		col.offsets = col.offsets[:0]
 
@@ -1478,6 +1657,43 @@ func (col *byteArrayColumnBuffer) Reset() {
 	col.values = col.values[:0]
 	col.offsets = col.offsets[:0]
 	col.numValues = 0
+	if col.valuesMemory.release() {
+		col.values = nil
+	}
+	if col.offsetsMemory.release() {
+		col.offsets = nil
+	}
+}
+
+func (col *byteArrayColumnBuffer) truncate(n int) {
+	if n < len(col.offsets) {
+		// Packing the values in order places the values of the first n rows
+		// before the offset of the value at index n.
+		col.Page()
+		col.values = col.values[:col.offsets[n]]
+		col.offsets = col.offsets[:n]
+		col.numValues = int32(n)
+	}
+}
+
+func (col *byteArrayColumnBuffer) setAllocator(allocator Allocator) {
+	col.valuesMemory.init(allocator, cap(col.values))
+	col.offsetsMemory.init(allocator, 4*cap(col.offsets))
+	col.values, col.offsets = nil, nil
+}
+
+func (col *byteArrayColumnBuffer) bufferAllocator() Allocator { return col.valuesMemory.allocator }
+
+// reserve grows the memory allocated with an allocator to hold one more value
+// of the given size.
+func (col *byteArrayColumnBuffer) reserve(size int) {
+	if n := len(col.offsets) + 1; n > cap(col.offsets) {
+		memory := col.offsetsMemory.grow(unsafecast.Uint32ToBytes(col.offsets), 4*n)
+		col.offsets = unsafecast.BytesToUint32(memory)[:len(col.offsets)]
+	}
+	if n := len(col.values) + plain.ByteArrayLengthSize + size; n > cap(col.values) {
+		col.values = col.valuesMemory.grow(col.values, n)[:len(col.values)]
+	}
 }
 
 func (col *byteArrayColumnBuffer) Cap() int { return cap(col.offsets) }
@@ -1549,6 +1765,9 @@ func (col *byteArrayColumnBuffer) ReadValuesAt(values []Value, offset int64) (n
 }
 
 func (col *byteArrayColumnBuffer) append(value string) {
+	if col.valuesMemory.allocator != nil {
+		col.reserve(len(value))
+	}
 	col.offsets = append(col.offsets, uint32(len(col.values)))
 	col.values = plain.AppendByteArrayString(col.values, value)
 	col.numValues++
@@ -1556,6 +1775,7 @@ func (col *byteArrayColumnBuffer) append(value string) {
 
 type fixedLenByteArrayColumnBuffer struct {
 	fixedLenByteArrayPage
+	columnMemory
 	tmp []byte
 }
 
@@ -1600,7 +1820,29 @@ func (col *fixedLenByteArrayColumnBuffer) Pages() Pages { return onePage(col.Pag
 
 func (col *fixedLenByteArrayColumnBuffer) Page() BufferedPage { return &col.fixedLenByteArrayPage }
 
-func (col *fixedLenByteArrayColumnBuffer) Reset() { col.data = col.data[:0] }
+func (col *fixedLenByteArrayColumnBuffer) Reset() {
+	col.data = col.data[:0]
+	if col.release() {
+		col.data = nil
+	}
+}
+
+func (col *fixedLenByteArrayColumnBuffer) truncate(n int) { col.data = col.data[:n*col.size] }
+
+func (col *fixedLenByteArrayColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, cap(col.data))
+	col.data = nil
+}
+
+func (col *fixedLenByteArrayColumnBuffer) reserve(n int) {
+	if n += len(col.data); n > cap(col.data) {
+		if col.allocator == nil {
+			col.data = append(make([]byte, 0, max(n, 2*cap(col.data))), col.data...)
+		} else {
+			col.data = col.grow(col.data, n)[:len(col.data)]
+		}
+	}
+}
 
 func (col *fixedLenByteArrayColumnBuffer) Cap() int { return cap(col.data) / col.size }
 
@@ -1633,11 +1875,13 @@ func (col *fixedLenByteArrayColumnBuffer) WriteFixedLenByteArrays(values []byte)
 	if m != 0 {
 		return 0, fmt.Errorf("cannot write FIXED_LEN_BYTE_ARRAY values of size %d from input of size %d", col.size, len(values))
 	}
+	col.reserve(len(values))
 	col.data = append(col.data, values...)
 	return d, nil
 }
 
 func (col *fixedLenByteArrayColumnBuffer) WriteValues(values []Value) (int, error) {
+	col.reserve(col.size * len(values))
 	for _, v := range values {
 		col.data = append(col.data, v.ByteArray()...)
 	}
@@ -1649,10 +1893,7 @@ func (col *fixedLenByteArrayColumnBuffer) writeValues(rows array, size, offset u
 	i := len(col.data)
 	j := len(col.data) + n
 
-	if cap(col.data) < j {
-		col.data = append(make([]byte, 0, max(i+n, 2*cap(col.data))), col.data...)
-	}
-
+	col.reserve(n)
 	col.data = col.data[:j]
 	newData := col.data[i:]
 
@@ -1682,7 +1923,10 @@ func (col *fixedLenByteArrayColumnBuffer) ReadValuesAt(values []Value, offset in
 	}
 }
 
-type uint32ColumnBuffer struct{ uint32Page }
+type uint32ColumnBuffer struct {
+	uint32Page
+	columnMemory
+}
 
 func newUint32ColumnBuffer(typ Type, columnIndex int16, numValues int32) *uint32ColumnBuffer {
 	return &uint32ColumnBuffer{
@@ -1716,7 +1960,30 @@ func (col *uint32ColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *uint32ColumnBuffer) Page() BufferedPage { return &col.uint32Page }
 
-func (col *uint32ColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *uint32ColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *uint32ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *uint32ColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 4*cap(col.values))
+	col.values = nil
+}
+
+func (col *uint32ColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]uint32, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Uint32ToBytes(col.values), 4*n)
+			col.values = unsafecast.BytesToUint32(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *uint32ColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1732,11 +1999,13 @@ func (col *uint32ColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 4) != 0 {
 		return 0, fmt.Errorf("cannot write INT32 values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 4)
 	col.values = append(col.values, unsafecast.BytesToUint32(b)...)
 	return len(b), nil
 }
 
 func (col *uint32ColumnBuffer) WriteUint32s(values []uint32) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -1748,9 +2017,7 @@ func (col *uint32ColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *uint32ColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]uint32, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1783,7 +2050,10 @@ func (col *uint32ColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int
 	}
 }
 
-type uint64ColumnBuffer struct{ uint64Page }
+type uint64ColumnBuffer struct {
+	uint64Page
+	columnMemory
+}
 
 func newUint64ColumnBuffer(typ Type, columnIndex int16, numValues int32) *uint64ColumnBuffer {
 	return &uint64ColumnBuffer{
@@ -1817,7 +2087,30 @@ func (col *uint64ColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *uint64ColumnBuffer) Page() BufferedPage { return &col.uint64Page }
 
-func (col *uint64ColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *uint64ColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *uint64ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *uint64ColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 8*cap(col.values))
+	col.values = nil
+}
+
+func (col *uint64ColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([]uint64, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Uint64ToBytes(col.values), 8*n)
+			col.values = unsafecast.BytesToUint64(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *uint64ColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1833,11 +2126,13 @@ func (col *uint64ColumnBuffer) Write(b []byte) (int, error) {
 	if (len(b) % 8) != 0 {
 		return 0, fmt.Errorf("cannot write INT64 values from input of size %d", len(b))
 	}
+	col.reserve(len(b) / 8)
 	col.values = append(col.values, unsafecast.BytesToUint64(b)...)
 	return len(b), nil
 }
 
 func (col *uint64ColumnBuffer) WriteUint64s(values []uint64) (int, error) {
+	col.reserve(len(values))
 	col.values = append(col.values, values...)
 	return len(values), nil
 }
@@ -1849,9 +2144,7 @@ func (col *uint64ColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *uint64ColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([]uint64, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 
@@ -1884,7 +2177,10 @@ func (col *uint64ColumnBuffer) ReadValuesAt(values []Value, offset int64) (n int
 	}
 }
 
-type be128ColumnBuffer struct{ be128Page }
+type be128ColumnBuffer struct {
+	be128Page
+	columnMemory
+}
 
 func newBE128ColumnBuffer(typ Type, columnIndex int16, numValues int32) *be128ColumnBuffer {
 	return &be128ColumnBuffer{
@@ -1922,7 +2218,30 @@ func (col *be128ColumnBuffer) Pages() Pages { return onePage(col.Page()) }
 
 func (col *be128ColumnBuffer) Page() BufferedPage { return &col.be128Page }
 
-func (col *be128ColumnBuffer) Reset() { col.values = col.values[:0] }
+func (col *be128ColumnBuffer) Reset() {
+	col.values = col.values[:0]
+	if col.release() {
+		col.values = nil
+	}
+}
+
+func (col *be128ColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
+func (col *be128ColumnBuffer) setAllocator(allocator Allocator) {
+	col.init(allocator, 16*cap(col.values))
+	col.values = nil
+}
+
+func (col *be128ColumnBuffer) reserve(n int) {
+	if n += len(col.values); n > cap(col.values) {
+		if col.allocator == nil {
+			col.values = append(make([][16]byte, 0, max(n, 2*cap(col.values))), col.values...)
+		} else {
+			memory := col.grow(unsafecast.Uint128ToBytes(col.values), 16*n)
+			col.values = unsafecast.BytesToUint128(memory)[:len(col.values)]
+		}
+	}
+}
 
 func (col *be128ColumnBuffer) Cap() int { return cap(col.values) }
 
@@ -1937,9 +2256,7 @@ func (col *be128ColumnBuffer) Swap(i, j int) {
 }
 
 func (col *be128ColumnBuffer) WriteValues(values []Value) (int, error) {
-	if n := len(col.values) + len(values); n > cap(col.values) {
-		col.values = append(make([][16]byte, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(len(values))
 	n := len(col.values)
 	col.values = col.values[:n+len(values)]
 	newValues := col.values[n:]
@@ -1950,9 +2267,7 @@ func (col *be128ColumnBuffer) WriteValues(values []Value) (int, error) {
 }
 
 func (col *be128ColumnBuffer) writeValues(rows array, size, offset uintptr, _ columnLevels) {
-	if n := len(col.values) + rows.len; n > cap(col.values) {
-		col.values = append(make([][16]byte, 0, max(n, 2*cap(col.values))), col.values...)
-	}
+	col.reserve(rows.len)
 	n := len(col.values)
 	col.values = col.values[:n+rows.len]
 	writeValuesBE128(col.values[n:], rows, size, offset)
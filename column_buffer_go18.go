@@ -185,6 +185,17 @@ func writeRowsFuncOfOptional(t reflect.Type, schema *Schema, path columnPath, wr
 	}
 }
 
+// writeRowsFuncOfNonNull is used for optional fields which are never null, for
+// example an int32 field marked "optional(nil)" in its parent struct.
+func writeRowsFuncOfNonNull(writeRows writeRowsFunc) writeRowsFunc {
+	return func(columns []ColumnBuffer, rows array, size, offset uintptr, levels columnLevels) error {
+		if rows.len > 0 {
+			levels.definitionLevel++
+		}
+		return writeRows(columns, rows, size, offset, levels)
+	}
+}
+
 func writeRowsFuncOfPointer(t reflect.Type, schema *Schema, path columnPath) writeRowsFunc {
 	elemType := t.Elem()
 	elemSize := elemType.Size()
@@ -291,13 +302,15 @@ func writeRowsFuncOfStruct(t reflect.Type, schema *Schema, path columnPath) writ
 
 	for i, f := range fields {
 		optional := false
+		nullPolicy := NullIfZero
 		columnPath := path.append(f.Name)
-		forEachStructTagOption(f, func(_ reflect.Type, option, _ string) {
+		forEachStructTagOption(f, func(_ reflect.Type, option, args string) {
 			switch option {
 			case "list":
 				columnPath = columnPath.append("list", "element")
 			case "optional":
 				optional = true
+				nullPolicy, _ = parseOptionalArgs(args)
 			}
 		})
 
@@ -305,8 +318,14 @@ func writeRowsFuncOfStruct(t reflect.Type, schema *Schema, path columnPath) writ
 		if optional {
 			switch f.Type.Kind() {
 			case reflect.Pointer, reflect.Slice:
-			default:
+			case reflect.Map:
 				writeRows = writeRowsFuncOfOptional(f.Type, schema, columnPath, writeRows)
+			default:
+				if nullPolicy == NullIfNil {
+					writeRows = writeRowsFuncOfNonNull(writeRows)
+				} else {
+					writeRows = writeRowsFuncOfOptional(f.Type, schema, columnPath, writeRows)
+				}
 			}
 		}
 
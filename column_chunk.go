@@ -1,6 +1,7 @@
 package parquet
 
 import (
+	"context"
 	"errors"
 	"io"
 )
@@ -35,6 +36,81 @@ type ColumnChunk interface {
 	NumValues() int64
 }
 
+// ColumnChunkValueReader is an iterator over the values of a column chunk,
+// reading them in batches directly from the pages of the column.
+//
+// Each value carries its repetition and definition levels, which programs can
+// retrieve by calling the RepetitionLevel and DefinitionLevel methods of Value,
+// allowing them to work with the (value, repetition level, definition level)
+// triplets of the column without reconstructing rows.
+type ColumnChunkValueReader struct {
+	pages  Pages
+	values ValueReader
+}
+
+// NewColumnChunkValueReader constructs a reader of the values of the given
+// column chunk.
+func NewColumnChunkValueReader(column ColumnChunk) *ColumnChunkValueReader {
+	return &ColumnChunkValueReader{pages: column.Pages()}
+}
+
+// ReadValues reads the next batch of values into the given slice, returning
+// the number of values read, and io.EOF when all values of the column chunk
+// have been read.
+//
+// A batch never spans more than one page, which means that fewer values than
+// the length of the slice may be returned. Values of byte array columns may
+// reference the memory of the current page, they remain valid until the next
+// call to ReadValues, SeekToRow, or Close; programs that need to retain them
+// must clone the values.
+func (r *ColumnChunkValueReader) ReadValues(values []Value) (n int, err error) {
+	for n < len(values) {
+		if r.values == nil {
+			p, err := r.pages.ReadPage()
+			if err != nil {
+				return n, err
+			}
+			r.values = p.Values()
+		}
+
+		c, err := r.values.ReadValues(values[n:])
+		n += c
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return n, err
+			}
+			// Reading the next page may release the memory of the current
+			// one, so values of the current page are returned first.
+			r.values = nil
+			if n > 0 {
+				break
+			}
+		}
+	}
+	return n, nil
+}
+
+// SeekToRow positions the reader at the first value of the row at the given
+// index in the column chunk.
+func (r *ColumnChunkValueReader) SeekToRow(rowIndex int64) error {
+	r.values = nil
+	return r.pages.SeekToRow(rowIndex)
+}
+
+// Close closes the reader, releasing the resources held by the pages of the
+// column chunk.
+func (r *ColumnChunkValueReader) Close() error {
+	r.values = nil
+	return r.pages.Close()
+}
+
+var (
+	_ ValueReader = (*ColumnChunkValueReader)(nil)
+	_ RowSeeker   = (*ColumnChunkValueReader)(nil)
+	_ io.Closer   = (*ColumnChunkValueReader)(nil)
+)
+
 type pageAndValueWriter interface {
 	PageWriter
 	ValueWriter
@@ -46,12 +122,65 @@ type columnChunkReader struct {
 	offset int         // offset of the next value in the buffer
 	reader Pages       // reader of column pages
 	values ValueReader // reader for values from the current page
+	// Context of the current read, nil if the read is not cancellable.
+	ctx context.Context
+	// Rows being read by the current call to ReadRows, which may reference the
+	// byte arrays of the current page when the column holds byte arrays, see
+	// retainValues.
+	rows       []Row
+	column     int
+	byteArrays bool
+	// True if values of the current page may have been added to rows.
+	pending bool
+	// Memory holding the byte arrays of rows which were copied from pages read
+	// during the current call.
+	arena []byte
 }
 
 func (r *columnChunkReader) buffered() int {
 	return len(r.buffer) - r.offset
 }
 
+// begin is called when starting to read values into rows.
+func (r *columnChunkReader) begin(rows []Row) {
+	r.rows = rows
+	r.arena = r.arena[:0]
+	r.pending = r.offset < len(r.buffer)
+}
+
+// end is called when done reading values into the rows passed to begin.
+func (r *columnChunkReader) end() {
+	r.rows = nil
+}
+
+// retainValues copies the byte arrays of the column referenced by the rows
+// being read to the arena of the reader. It is called before reading the next
+// page, since pages may reuse the memory of the previous page and the rows must
+// remain valid until the next read.
+func (r *columnChunkReader) retainValues() {
+	size := 0
+	for _, row := range r.rows {
+		for _, v := range row {
+			if v.Column() == r.column {
+				size += byteArraySizeOf([]Value{v})
+			}
+		}
+	}
+	if size == 0 {
+		return
+	}
+	// The arena is grown once, the byte arrays copied during previous calls to
+	// retainValues remain valid if it is reallocated.
+	r.arena = growArena(r.arena, size)
+	for _, row := range r.rows {
+		for i, v := range row {
+			if v.Column() == r.column {
+				row[i], r.arena = v.cloneTo(r.arena)
+			}
+		}
+	}
+}
+
 func (r *columnChunkReader) reset() {
 	clearValues(r.buffer)
 	r.buffer = r.buffer[:0]
@@ -72,13 +201,24 @@ func (r *columnChunkReader) seekToRow(rowIndex int64) error {
 	return r.reader.SeekToRow(rowIndex)
 }
 
+func (r *columnChunkReader) readPage() (Page, error) {
+	if r.ctx == nil {
+		return r.reader.ReadPage()
+	}
+	return ReadPageContext(r.ctx, r.reader)
+}
+
 func (r *columnChunkReader) readValues() error {
 	if r.offset < len(r.buffer) {
 		return nil
 	}
 	if r.values == nil {
+		if r.pending && r.byteArrays && r.rows != nil {
+			r.retainValues()
+		}
+		r.pending = false
 		for {
-			p, err := r.reader.ReadPage()
+			p, err := r.readPage()
 			if err != nil {
 				return err
 			}
@@ -97,6 +237,7 @@ func (r *columnChunkReader) readValues() error {
 	}
 	r.buffer = r.buffer[:n]
 	r.offset = 0
+	r.pending = r.pending || n > 0
 	return err
 }
 
@@ -1,6 +1,8 @@
 package parquet
 
 import (
+	"bytes"
+
 	"github.com/segmentio/parquet-go/deprecated"
 	"github.com/segmentio/parquet-go/encoding/plain"
 	"github.com/segmentio/parquet-go/format"
@@ -282,21 +284,65 @@ func (i *baseColumnIndexer) reset() {
 	i.nullCounts = i.nullCounts[:0]
 }
 
-func (i *baseColumnIndexer) observe(numValues, numNulls int64) {
-	i.nullPages = append(i.nullPages, numValues == numNulls)
+// observe records the null count of a page, and returns whether the page has
+// bounds, which is false for null pages. The indexers only record the bounds of
+// pages that have bounds, so the order of the column index is determined by
+// those pages only.
+func (i *baseColumnIndexer) observe(numValues, numNulls int64) bool {
+	nullPage := numValues == numNulls
+	i.nullPages = append(i.nullPages, nullPage)
 	i.nullCounts = append(i.nullCounts, numNulls)
+	return !nullPage
 }
 
+// columnIndex builds the column index from the bounds of the pages which are
+// not null pages, and their order.
 func (i *baseColumnIndexer) columnIndex(minValues, maxValues [][]byte, minOrder, maxOrder int) format.ColumnIndex {
+	// Constant bounds are both in ascending and descending order, in which
+	// case the order of the other bounds determines the order of the index.
+	if minOrder != maxOrder {
+		switch {
+		case areEqualByteArrays(minValues):
+			minOrder = maxOrder
+		case areEqualByteArrays(maxValues):
+			maxOrder = minOrder
+		}
+	}
 	return format.ColumnIndex{
 		NullPages:     i.nullPages,
 		NullCounts:    i.nullCounts,
-		MinValues:     minValues,
-		MaxValues:     maxValues,
+		MinValues:     i.nullPageBounds(minValues),
+		MaxValues:     i.nullPageBounds(maxValues),
 		BoundaryOrder: boundaryOrderOf(minOrder, maxOrder),
 	}
 }
 
+// nullPageBounds inserts the bounds of null pages, which the parquet format
+// requires to be empty byte arrays, in the bounds of the other pages.
+func (i *baseColumnIndexer) nullPageBounds(values [][]byte) [][]byte {
+	if len(values) == len(i.nullPages) {
+		return values
+	}
+	bounds := make([][]byte, len(i.nullPages))
+	for j, nullPage := range i.nullPages {
+		if nullPage {
+			bounds[j] = []byte{}
+		} else {
+			bounds[j], values = values[0], values[1:]
+		}
+	}
+	return bounds
+}
+
+func areEqualByteArrays(values [][]byte) bool {
+	for i := 1; i < len(values); i++ {
+		if !bytes.Equal(values[i], values[0]) {
+			return false
+		}
+	}
+	return true
+}
+
 type booleanColumnIndexer struct {
 	baseColumnIndexer
 	minValues []bool
@@ -314,9 +360,10 @@ func (i *booleanColumnIndexer) Reset() {
 }
 
 func (i *booleanColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Boolean())
-	i.maxValues = append(i.maxValues, max.Boolean())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Boolean())
+		i.maxValues = append(i.maxValues, max.Boolean())
+	}
 }
 
 func (i *booleanColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -345,9 +392,10 @@ func (i *int32ColumnIndexer) Reset() {
 }
 
 func (i *int32ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Int32())
-	i.maxValues = append(i.maxValues, max.Int32())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Int32())
+		i.maxValues = append(i.maxValues, max.Int32())
+	}
 }
 
 func (i *int32ColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -376,9 +424,10 @@ func (i *int64ColumnIndexer) Reset() {
 }
 
 func (i *int64ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Int64())
-	i.maxValues = append(i.maxValues, max.Int64())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Int64())
+		i.maxValues = append(i.maxValues, max.Int64())
+	}
 }
 
 func (i *int64ColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -407,9 +456,10 @@ func (i *int96ColumnIndexer) Reset() {
 }
 
 func (i *int96ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Int96())
-	i.maxValues = append(i.maxValues, max.Int96())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Int96())
+		i.maxValues = append(i.maxValues, max.Int96())
+	}
 }
 
 func (i *int96ColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -438,9 +488,10 @@ func (i *floatColumnIndexer) Reset() {
 }
 
 func (i *floatColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Float())
-	i.maxValues = append(i.maxValues, max.Float())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Float())
+		i.maxValues = append(i.maxValues, max.Float())
+	}
 }
 
 func (i *floatColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -469,9 +520,10 @@ func (i *doubleColumnIndexer) Reset() {
 }
 
 func (i *doubleColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Double())
-	i.maxValues = append(i.maxValues, max.Double())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Double())
+		i.maxValues = append(i.maxValues, max.Double())
+	}
 }
 
 func (i *doubleColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -501,15 +553,16 @@ func (i *byteArrayColumnIndexer) Reset() {
 }
 
 func (i *byteArrayColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	minValue := min.ByteArray()
-	maxValue := max.ByteArray()
-	if i.sizeLimit > 0 {
-		minValue = truncateLargeMinByteArrayValue(minValue, i.sizeLimit)
-		maxValue = truncateLargeMaxByteArrayValue(maxValue, i.sizeLimit)
+	if i.observe(numValues, numNulls) {
+		minValue := min.ByteArray()
+		maxValue := max.ByteArray()
+		if i.sizeLimit > 0 {
+			minValue = truncateLargeMinByteArrayValue(minValue, i.sizeLimit)
+			maxValue = truncateLargeMaxByteArrayValue(maxValue, i.sizeLimit)
+		}
+		i.minValues = plain.AppendByteArray(i.minValues, minValue)
+		i.maxValues = plain.AppendByteArray(i.maxValues, maxValue)
 	}
-	i.minValues = plain.AppendByteArray(i.minValues, minValue)
-	i.maxValues = plain.AppendByteArray(i.maxValues, maxValue)
 }
 
 func (i *byteArrayColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -545,9 +598,10 @@ func (i *fixedLenByteArrayColumnIndexer) Reset() {
 }
 
 func (i *fixedLenByteArrayColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.ByteArray()...)
-	i.maxValues = append(i.maxValues, max.ByteArray()...)
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.ByteArray()...)
+		i.maxValues = append(i.maxValues, max.ByteArray()...)
+	}
 }
 
 func (i *fixedLenByteArrayColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -586,9 +640,10 @@ func (i *uint32ColumnIndexer) Reset() {
 }
 
 func (i *uint32ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Uint32())
-	i.maxValues = append(i.maxValues, max.Uint32())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Uint32())
+		i.maxValues = append(i.maxValues, max.Uint32())
+	}
 }
 
 func (i *uint32ColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -617,9 +672,10 @@ func (i *uint64ColumnIndexer) Reset() {
 }
 
 func (i *uint64ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	i.minValues = append(i.minValues, min.Uint64())
-	i.maxValues = append(i.maxValues, max.Uint64())
+	if i.observe(numValues, numNulls) {
+		i.minValues = append(i.minValues, min.Uint64())
+		i.maxValues = append(i.maxValues, max.Uint64())
+	}
 }
 
 func (i *uint64ColumnIndexer) ColumnIndex() format.ColumnIndex {
@@ -648,12 +704,12 @@ func (i *be128ColumnIndexer) Reset() {
 }
 
 func (i *be128ColumnIndexer) IndexPage(numValues, numNulls int64, min, max Value) {
-	i.observe(numValues, numNulls)
-	if !min.IsNull() {
-		i.minValues = append(i.minValues, *(*[16]byte)(min.ByteArray()))
-	}
-	if !max.IsNull() {
-		i.maxValues = append(i.maxValues, *(*[16]byte)(max.ByteArray()))
+	if i.observe(numValues, numNulls) {
+		var minValue, maxValue [16]byte
+		copy(minValue[:], min.ByteArray())
+		copy(maxValue[:], max.ByteArray())
+		i.minValues = append(i.minValues, minValue)
+		i.maxValues = append(i.maxValues, maxValue)
 	}
 }
 
@@ -675,6 +731,31 @@ func truncateLargeMinByteArrayValue(value []byte, sizeLimit int) []byte {
 	return value
 }
 
+// copyColumnIndex returns a copy of index which does not share memory with the
+// buffers of the column indexer that produced it.
+func copyColumnIndex(index format.ColumnIndex) format.ColumnIndex {
+	index.NullPages = append([]bool(nil), index.NullPages...)
+	index.NullCounts = append([]int64(nil), index.NullCounts...)
+	index.MinValues = copyByteArrays(index.MinValues)
+	index.MaxValues = copyByteArrays(index.MaxValues)
+	return index
+}
+
+func copyByteArrays(values [][]byte) [][]byte {
+	size := 0
+	for _, v := range values {
+		size += len(v)
+	}
+	buffer := make([]byte, 0, size)
+	copies := make([][]byte, len(values))
+	for i, v := range values {
+		offset := len(buffer)
+		buffer = append(buffer, v...)
+		copies[i] = buffer[offset:len(buffer):len(buffer)]
+	}
+	return copies
+}
+
 func truncateLargeMaxByteArrayValue(value []byte, sizeLimit int) []byte {
 	if len(value) > sizeLimit && !isMaxByteArrayValue(value) {
 		value = value[:sizeLimit]
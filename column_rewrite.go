@@ -0,0 +1,260 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// RewriteColumn writes to output a copy of the parquet file f where the values
+// of the column at the given path are replaced by the values returned by the
+// transform function, which allows scrubbing or re-hashing a column without
+// decoding and re-encoding the other columns of the file.
+//
+// The transform function is called with the values of the column for each row
+// of the file, in order, and returns the values of the column in the rewritten
+// row. The values passed to the function are only valid until it returns, but
+// the returned values may reference them. The returned values must carry the
+// repetition and definition levels of the column, for example:
+//
+//	err := parquet.RewriteColumn(output, f, []string{"email"}, func(values []parquet.Value) ([]parquet.Value, error) {
+//		for i, v := range values {
+//			if !v.IsNull() {
+//				values[i] = parquet.ByteArrayValue(hash(v.ByteArray())).Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+//			}
+//		}
+//		return values, nil
+//	})
+//
+// The column chunks of the rewritten column are encoded with the compression
+// codec of the original column chunks, and with dictionary encoding if the
+// original column chunks were dictionary encoded. Their page index and bloom
+// filter are regenerated, the statistics of the column chunks are not retained.
+// The other column chunks and bloom filters are copied byte-for-byte, and the
+// page index and metadata of the file are rewritten to account for the new
+// location of the column chunks. Sorting columns of row groups are truncated
+// at the rewritten column, since its values may not be sorted anymore.
+//
+// The function returns an error if the path does not name a leaf column of the
+// file's schema, if the file is encrypted, or if the column chunks of the file
+// are stored in external files.
+func RewriteColumn(output io.Writer, f *File, path []string, transform func(values []Value) ([]Value, error)) error {
+	leaf, ok := f.Schema().Lookup(path...)
+	if !ok {
+		return fmt.Errorf("rewriting column %q: column not found in the schema", columnPath(path))
+	}
+	if f.isEncrypted() {
+		return fmt.Errorf("rewriting column %q: the file is encrypted", columnPath(path))
+	}
+
+	rowGroups, err := f.rowGroupsMetadata()
+	if err != nil {
+		return err
+	}
+	columnIndexes, offsetIndexes, err := f.ReadPageIndex()
+	if err != nil {
+		return fmt.Errorf("reading page index: %w", err)
+	}
+	// The page index of the rewritten column chunks is replaced, the slices
+	// are copied to avoid mutating the page index of the file.
+	columnIndexes = append([]format.ColumnIndex(nil), columnIndexes...)
+	offsetIndexes = append([]format.OffsetIndex(nil), offsetIndexes...)
+
+	metadata := f.metadata
+	metadata.RowGroups = make([]format.RowGroup, len(rowGroups))
+
+	buffer := bufio.NewWriterSize(output, DefaultWriteBufferSize)
+	w := offsetTrackingWriter{}
+	w.Reset(buffer)
+
+	if _, err := w.WriteString("PAR1"); err != nil {
+		return err
+	}
+
+	column := leaf.ColumnIndex
+	rewrite := new(bytes.Buffer)
+
+	for i := range rowGroups {
+		rowGroup := &metadata.RowGroups[i]
+		*rowGroup = rowGroups[i]
+		rowGroup.Columns = append([]format.ColumnChunk{}, rowGroups[i].Columns...)
+		rowGroup.FileOffset = w.offset
+		rowGroup.SortingColumns = truncateSortingColumns(rowGroup.SortingColumns, column)
+
+		rewrite.Reset()
+		rewritten, err := rewriteColumnChunk(rewrite, f, i, path, transform)
+		if err != nil {
+			return fmt.Errorf("rewriting column %q of row group %d: %w", columnPath(path), i, err)
+		}
+
+		original := &rowGroup.Columns[column]
+		rewrittenChunk := rewritten.metadata.RowGroups[0].Columns[0]
+		rewrittenChunk.MetaData.PathInSchema = original.MetaData.PathInSchema
+		rowGroup.TotalByteSize += rewrittenChunk.MetaData.TotalUncompressedSize - original.MetaData.TotalUncompressedSize
+		if rowGroup.TotalCompressedSize != 0 {
+			rowGroup.TotalCompressedSize += rewrittenChunk.MetaData.TotalCompressedSize - original.MetaData.TotalCompressedSize
+		}
+		*original = rewrittenChunk
+
+		for j := range rowGroup.Columns {
+			r, index := f.reader, i*len(rowGroup.Columns)+j
+			var offsetIndex *format.OffsetIndex
+			if j == column {
+				r = rewritten.reader
+				// The column index may have been omitted by the writer, for
+				// example when a page of floating point values contains only
+				// NaN values.
+				if offsetIndexes != nil {
+					offsetIndexes[index], columnIndexes[index] = format.OffsetIndex{}, format.ColumnIndex{}
+					if len(rewritten.offsetIndexes) > 0 {
+						offsetIndexes[index] = rewritten.offsetIndexes[0]
+					}
+					if len(rewritten.columnIndexes) > 0 {
+						columnIndexes[index] = rewritten.columnIndexes[0]
+					}
+				}
+			}
+			if offsetIndexes != nil {
+				offsetIndex = &offsetIndexes[index]
+			}
+			if err := copyColumnChunk(&w, r, &rowGroup.Columns[j], offsetIndex); err != nil {
+				return fmt.Errorf("copying column chunk %d of row group %d: %w", j, i, err)
+			}
+		}
+
+		for j := range rowGroup.Columns {
+			c := &rowGroup.Columns[j]
+			r := f.reader
+			if j == column {
+				r = rewritten.reader
+			}
+			if offset := c.MetaData.BloomFilterOffset; offset > 0 {
+				c.MetaData.BloomFilterOffset = w.offset
+				if err := copyBloomFilter(&w, r, offset); err != nil {
+					return fmt.Errorf("copying bloom filter of column chunk %d of row group %d: %w", j, i, err)
+				}
+			}
+		}
+	}
+
+	if err := writePageIndexAndFooter(&w, &metadata, columnIndexes, offsetIndexes); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+// rewriteColumnChunk writes to file a parquet file holding the column at the
+// given path of the row group at index i of f, with its values transformed, and
+// returns the file.
+func rewriteColumnChunk(file *bytes.Buffer, f *File, i int, path []string, transform func([]Value) ([]Value, error)) (*File, error) {
+	rowGroup := f.RowGroups()[i]
+	leaf, _ := f.Schema().Lookup(path...)
+	chunk := &rowGroup.ColumnChunks()[leaf.ColumnIndex].(*fileColumnChunk).chunk.MetaData
+
+	node := Compressed(Leaf(leaf.Node.Type()), LookupCompressionCodec(chunk.Codec))
+	for _, encoding := range chunk.Encoding {
+		if isDictionaryFormat(encoding) {
+			node = Encoded(node, &RLEDictionary)
+			break
+		}
+	}
+	schema := NewSchema(f.Schema().Name(), rewriteColumnNode(f.Schema(), path, node))
+
+	conv, err := Convert(schema, f.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	options := []WriterOption{schema}
+	if chunk.BloomFilterOffset > 0 {
+		options = append(options, BloomFilters(SplitBlockFilter(path...)))
+	}
+	w := NewWriter(file, options...)
+
+	rows := ConvertRowGroup(rowGroup, conv).Rows()
+	defer rows.Close()
+
+	buffer := make([]Row, defaultRowBufferSize)
+	output := make([]Row, defaultRowBufferSize)
+	for {
+		n, err := rows.ReadRows(buffer)
+		for j, row := range buffer[:n] {
+			values, err := transform(row)
+			if err != nil {
+				return nil, err
+			}
+			// The values are moved to the only column of the schema.
+			output[j] = output[j][:0]
+			for _, v := range values {
+				output[j] = append(output[j], v.Level(v.RepetitionLevel(), v.DefinitionLevel(), 0))
+			}
+		}
+		if n > 0 {
+			if _, err := w.WriteRows(output[:n]); err != nil {
+				return nil, err
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	rewritten, err := OpenFile(bytes.NewReader(file.Bytes()), int64(file.Len()))
+	if err != nil {
+		return nil, err
+	}
+	if numRows := rewritten.NumRows(); numRows != rowGroup.NumRows() {
+		return nil, fmt.Errorf("the rewritten column has %d rows instead of %d", numRows, rowGroup.NumRows())
+	}
+	return rewritten, nil
+}
+
+// rewriteColumnNode returns a node holding only the leaf at the given path of
+// the schema, which is replaced by the given node. The repetition of the leaf
+// and of its parent groups are retained, so the repetition and definition
+// levels of the column are the same in the returned node.
+func rewriteColumnNode(schema *Schema, path []string, leaf Node) Node {
+	nodes := make([]Node, len(path))
+	var parent Node = schema
+	for i, name := range path {
+		for _, field := range parent.Fields() {
+			if field.Name() == name {
+				nodes[i] = field
+				break
+			}
+		}
+		parent = nodes[i]
+	}
+
+	node := leaf
+	for i := len(path) - 1; i >= 0; i-- {
+		switch {
+		case nodes[i].Optional():
+			node = Optional(node)
+		case nodes[i].Repeated():
+			node = Repeated(node)
+		}
+		node = Group{path[i]: node}
+	}
+	return node
+}
+
+// truncateSortingColumns returns the sorting columns preceding the column at
+// the given index.
+func truncateSortingColumns(sortingColumns []format.SortingColumn, column int) []format.SortingColumn {
+	for i, s := range sortingColumns {
+		if int(s.ColumnIdx) == column {
+			return sortingColumns[:i:i]
+		}
+	}
+	return sortingColumns
+}
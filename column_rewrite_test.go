@@ -0,0 +1,202 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/format"
+)
+
+func TestRewriteColumn(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Name  string  `parquet:"name"`
+		Color string  `parquet:"color,dict"`
+		Note  *string `parquet:"note,optional"`
+		Tags  []int64 `parquet:"tags"`
+	}
+
+	rows := make([]Row, 3000)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    int64(i),
+			Name:  fmt.Sprintf("name-%d", i),
+			Color: fmt.Sprintf("color-%d", i%20),
+		}
+		if i%3 == 0 {
+			note := fmt.Sprintf("note-%d", i)
+			rows[i].Note = &note
+		}
+		for j := 0; j < i%3; j++ {
+			rows[i].Tags = append(rows[i].Tags, int64(10*i+j))
+		}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b,
+		parquet.PageBufferSize(4096),
+		parquet.BloomFilters(parquet.SplitBlockFilter("id"), parquet.SplitBlockFilter("name")),
+		parquet.SortingColumns(parquet.Ascending("id"), parquet.Ascending("name")),
+	)
+	for i := range rows {
+		if err := w.Write(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+		if i%1000 == 999 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path      string
+		transform func([]parquet.Value) ([]parquet.Value, error)
+		update    func(*Row)
+	}{
+		{
+			path: "name",
+			transform: func(values []parquet.Value) ([]parquet.Value, error) {
+				v := values[0]
+				values[0] = parquet.ValueOf(strings.ToUpper(v.String())).Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+				return values, nil
+			},
+			update: func(row *Row) { row.Name = strings.ToUpper(row.Name) },
+		},
+		{
+			path: "color",
+			transform: func(values []parquet.Value) ([]parquet.Value, error) {
+				v := values[0]
+				values[0] = parquet.ValueOf("redacted").Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+				return values, nil
+			},
+			update: func(row *Row) { row.Color = "redacted" },
+		},
+		{
+			path: "note",
+			transform: func(values []parquet.Value) ([]parquet.Value, error) {
+				v := values[0]
+				values[0] = parquet.Value{}.Level(v.RepetitionLevel(), 0, v.Column())
+				return values, nil
+			},
+			update: func(row *Row) { row.Note = nil },
+		},
+		{
+			path: "tags",
+			transform: func(values []parquet.Value) ([]parquet.Value, error) {
+				if values[0].IsNull() {
+					return values, nil
+				}
+				rewritten := make([]parquet.Value, 0, 2*len(values))
+				for _, v := range values {
+					rewritten = append(rewritten,
+						v,
+						parquet.ValueOf(-v.Int64()).Level(1, v.DefinitionLevel(), v.Column()),
+					)
+				}
+				return rewritten, nil
+			},
+			update: func(row *Row) {
+				var tags []int64
+				for _, tag := range row.Tags {
+					tags = append(tags, tag, -tag)
+				}
+				row.Tags = tags
+			},
+		},
+	} {
+		for _, lazy := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/lazy=%t", test.path, lazy), func(t *testing.T) {
+				f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()), parquet.LazyRowGroups(lazy))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				output := new(bytes.Buffer)
+				if err := parquet.RewriteColumn(output, f, []string{test.path}, test.transform); err != nil {
+					t.Fatal(err)
+				}
+
+				g, err := parquet.OpenFile(bytes.NewReader(output.Bytes()), int64(output.Len()), parquet.VerifyColumnChunks(true))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g.NumRows() != int64(len(rows)) || len(g.RowGroups()) != 3 {
+					t.Fatalf("wrong number of rows or row groups: rows=%d row groups=%d", g.NumRows(), len(g.RowGroups()))
+				}
+
+				// The column chunks of the other columns are copied byte-for-byte.
+				src := readFileMetaData(t, b.Bytes())
+				dst := readFileMetaData(t, output.Bytes())
+				chunkData := func(data []byte, c *format.ColumnMetaData) []byte {
+					offset := c.DataPageOffset
+					if c.DictionaryPageOffset != 0 && c.DictionaryPageOffset < offset {
+						offset = c.DictionaryPageOffset
+					}
+					return data[offset : offset+c.TotalCompressedSize]
+				}
+				for i := range src.RowGroups {
+					for j := range src.RowGroups[i].Columns {
+						c1 := &src.RowGroups[i].Columns[j].MetaData
+						c2 := &dst.RowGroups[i].Columns[j].MetaData
+						if rewritten := c1.PathInSchema[0] == test.path; rewritten == bytes.Equal(chunkData(b.Bytes(), c1), chunkData(output.Bytes(), c2)) {
+							t.Errorf("column chunk %d of row group %d: rewritten=%t", j, i, rewritten)
+						}
+					}
+				}
+
+				// The rewritten column breaks the order of sorting columns.
+				sortingColumns := dst.RowGroups[0].SortingColumns
+				switch test.path {
+				case "name":
+					if len(sortingColumns) != 1 {
+						t.Errorf("wrong number of sorting columns: %d", len(sortingColumns))
+					}
+				default:
+					if len(sortingColumns) != 2 {
+						t.Errorf("wrong number of sorting columns: %d", len(sortingColumns))
+					}
+				}
+
+				r := parquet.NewReader(g)
+				for i, want := range rows {
+					test.update(&want)
+					got := Row{}
+					if err := r.Read(&got); err != nil {
+						t.Fatalf("reading row %d: %v", i, err)
+					}
+					if len(got.Tags) == 0 {
+						got.Tags = nil
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want, got)
+					}
+				}
+
+				for i, row := range rows {
+					test.update(&row)
+					chunk, _ := parquet.LookupColumnChunk(g.RowGroups()[i/1000], "name")
+					if ok, err := parquet.MayContain(chunk, parquet.ValueOf(row.Name)); err != nil || !ok {
+						t.Fatalf("value %v of row %d not found in the bloom filter: %v", row.Name, i, err)
+					}
+				}
+			})
+		}
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := func(values []parquet.Value) ([]parquet.Value, error) { return values, nil }
+	if err := parquet.RewriteColumn(new(bytes.Buffer), f, []string{"missing"}, identity); err == nil {
+		t.Error("no error rewriting a column that does not exist")
+	}
+}
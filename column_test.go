@@ -1,7 +1,10 @@
 package parquet_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"testing"
 
@@ -456,6 +459,7 @@ const (
 	unorderedIndexOrder
 	ascendingIndexOrder
 	descendingIndexOrder
+	constantIndexOrder
 )
 
 func (o indexOrder) String() string {
@@ -466,6 +470,8 @@ func (o indexOrder) String() string {
 		return "ascending"
 	case descendingIndexOrder:
 		return "descending"
+	case constantIndexOrder:
+		return "constant"
 	default:
 		return "invalid"
 	}
@@ -488,6 +494,16 @@ func observedIndexOrder(columnType parquet.Type, minValues []parquet.Value, maxV
 	a := valueOrder(columnType, minValues)
 	b := valueOrder(columnType, maxValues)
 
+	// Constant values are both in ascending and descending order.
+	switch {
+	case a == constantIndexOrder && b == constantIndexOrder:
+		return ascendingIndexOrder
+	case a == constantIndexOrder:
+		a = b
+	case b == constantIndexOrder:
+		b = a
+	}
+
 	switch {
 	case a == ascendingIndexOrder && b == ascendingIndexOrder:
 		return ascendingIndexOrder
@@ -519,9 +535,99 @@ func valueOrder(columnType parquet.Type, values []parquet.Value) indexOrder {
 		}
 	}
 
-	if order > 0 {
+	switch {
+	case order > 0:
 		return descendingIndexOrder
+	case order < 0:
+		return ascendingIndexOrder
+	default:
+		return constantIndexOrder
 	}
+}
 
-	return ascendingIndexOrder
+func TestColumnChunkValueReader(t *testing.T) {
+	type Record struct {
+		ID   int64    `parquet:"id"`
+		Note *string  `parquet:"note,optional"`
+		Tags []string `parquet:"tags"`
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	records := make([]Record, 1000)
+	for i := range records {
+		records[i].ID = int64(i)
+		if prng.Intn(2) == 0 {
+			records[i].Note = newString(fmt.Sprintf("note-%d", i))
+		}
+		for j := prng.Intn(4); j > 0; j-- {
+			records[i].Tags = append(records[i].Tags, fmt.Sprintf("tag-%d", prng.Intn(100)))
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(records), parquet.PageBufferSize(128)); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := parquet.SchemaOf(Record{})
+	rowGroup := f.RowGroups()[0]
+
+	for columnIndex, column := range rowGroup.ColumnChunks() {
+		t.Run(schema.Columns()[columnIndex][0], func(t *testing.T) {
+			want := []parquet.Value{}
+			for i := range records {
+				for _, v := range schema.Deconstruct(nil, &records[i]) {
+					if v.Column() == columnIndex {
+						want = append(want, v)
+					}
+				}
+			}
+
+			r := parquet.NewColumnChunkValueReader(column)
+			defer r.Close()
+
+			got := []parquet.Value{}
+			batch := make([]parquet.Value, 7)
+			for {
+				n, err := r.ReadValues(batch)
+				for _, v := range batch[:n] {
+					got = append(got, v.Clone())
+				}
+				if err != nil {
+					if !errors.Is(err, io.EOF) {
+						t.Fatal(err)
+					}
+					break
+				}
+				if n == 0 {
+					t.Fatal("no progress reading column values")
+				}
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("wrong number of values: want=%d got=%d", len(want), len(got))
+			}
+			for i := range want {
+				if !parquet.Equal(want[i], got[i]) ||
+					want[i].RepetitionLevel() != got[i].RepetitionLevel() ||
+					want[i].DefinitionLevel() != got[i].DefinitionLevel() {
+					t.Fatalf("wrong value at index %d: want=%+v got=%+v", i, want[i], got[i])
+				}
+			}
+
+			if err := r.SeekToRow(500); err != nil {
+				t.Fatal(err)
+			}
+			if n, err := r.ReadValues(batch[:1]); n != 1 || err != nil {
+				t.Fatalf("reading value after seek: n=%d err=%v", n, err)
+			}
+			if batch[0].RepetitionLevel() != 0 {
+				t.Errorf("value read after seek is not at the beginning of a row: %+v", batch[0])
+			}
+		})
+	}
 }
@@ -1,6 +1,7 @@
 package parquet
 
 import (
+	"bytes"
 	"encoding/binary"
 
 	"github.com/segmentio/parquet-go/deprecated"
@@ -128,3 +129,15 @@ func lessBE128(v1, v2 *[16]byte) bool {
 	y = binary.BigEndian.Uint64(v2[8:])
 	return x < y
 }
+
+// compareSignedBigEndian compares two's complement big-endian integers of the
+// same length.
+func compareSignedBigEndian(v1, v2 []byte) int {
+	if len(v1) == 0 || len(v2) == 0 || len(v1) != len(v2) {
+		return bytes.Compare(v1, v2)
+	}
+	if c := compareInt32(int32(int8(v1[0])), int32(int8(v2[0]))); c != 0 {
+		return c
+	}
+	return bytes.Compare(v1[1:], v2[1:])
+}
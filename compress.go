@@ -2,6 +2,7 @@ package parquet
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/segmentio/parquet-go/compress"
 	"github.com/segmentio/parquet-go/compress/brotli"
@@ -10,6 +11,7 @@ import (
 	"github.com/segmentio/parquet-go/compress/snappy"
 	"github.com/segmentio/parquet-go/compress/uncompressed"
 	"github.com/segmentio/parquet-go/compress/zstd"
+	"github.com/segmentio/parquet-go/encoding"
 	"github.com/segmentio/parquet-go/format"
 )
 
@@ -94,3 +96,125 @@ func (u *unsupported) error() error {
 func isCompressed(c compress.Codec) bool {
 	return c != nil && c.CompressionCodec() != format.Uncompressed
 }
+
+// ZstdDictionary creates a configuration option which attaches a zstd
+// dictionary to the column at the given path.
+//
+// On writers, the pages of the column are compressed with ZSTD using the
+// dictionary, which improves the compression of small pages of similar data.
+// If the column was configured to use the ZSTD codec, its compression level
+// is retained.
+//
+// On files and readers, the dictionary is used to decompress the pages of the
+// column when it is compressed with ZSTD. Pages compressed with a dictionary
+// cannot be decompressed without it. Readers only apply the option when they
+// open the parquet file themselves.
+//
+// This option is additive, it may be used multiple times to attach
+// dictionaries to different columns.
+func ZstdDictionary(dict []byte, path ...string) interface {
+	FileOption
+	ReaderOption
+	WriterOption
+} {
+	return zstdDictionaryOption{path: columnPath(path).String(), dict: dict}
+}
+
+type zstdDictionaryOption struct {
+	path string
+	dict []byte
+}
+
+func (opt zstdDictionaryOption) ConfigureFile(config *FileConfig) {
+	config.ZstdDictionaries = opt.addTo(config.ZstdDictionaries)
+}
+
+func (opt zstdDictionaryOption) ConfigureReader(config *ReaderConfig) {
+	config.ZstdDictionaries = opt.addTo(config.ZstdDictionaries)
+}
+
+func (opt zstdDictionaryOption) ConfigureWriter(config *WriterConfig) {
+	config.ZstdDictionaries = opt.addTo(config.ZstdDictionaries)
+}
+
+func (opt zstdDictionaryOption) addTo(dicts map[string][]byte) map[string][]byte {
+	if dicts == nil {
+		dicts = make(map[string][]byte)
+	}
+	dicts[opt.path] = opt.dict
+	return dicts
+}
+
+// withZstdDictionary returns the codec to use for a column configured with the
+// zstd dictionary. When reading, only columns compressed with ZSTD use the
+// dictionary.
+func withZstdDictionary(codec compress.Codec, dict []byte, writing bool) compress.Codec {
+	level := zstd.DefaultLevel
+	switch c := codec.(type) {
+	case *zstd.Codec:
+		if c.Level != 0 {
+			level = c.Level
+		}
+	default:
+		if !writing {
+			return codec
+		}
+	}
+	return &zstd.Codec{Level: level, Dictionary: dict}
+}
+
+func mergeZstdDictionaries(dicts1, dicts2 map[string][]byte) map[string][]byte {
+	if len(dicts1) == 0 {
+		return dicts2
+	}
+	merged := make(map[string][]byte, len(dicts1)+len(dicts2))
+	for path, dict := range dicts2 {
+		merged[path] = dict
+	}
+	for path, dict := range dicts1 {
+		merged[path] = dict
+	}
+	return merged
+}
+
+// TrainZstdDictionary trains a zstd dictionary of at most size bytes from the
+// pages of the given column chunks, which can then be attached to columns of
+// files written with the ZstdDictionary option.
+//
+// The values of each page are encoded with enc to form the samples that the
+// dictionary is trained from, which should be the encoding that the column is
+// written with; the PLAIN encoding is used if enc is nil. Dictionaries are
+// most effective when trained on a representative subset of the pages of the
+// column rather than on all of them.
+func TrainZstdDictionary(size int, enc encoding.Encoding, chunks ...ColumnChunk) ([]byte, error) {
+	if enc == nil {
+		enc = &Plain
+	}
+
+	var samples [][]byte
+	for _, chunk := range chunks {
+		err := func() error {
+			pages := chunk.Pages()
+			defer pages.Close()
+			for {
+				page, err := pages.ReadPage()
+				if err != nil {
+					if err == io.EOF {
+						err = nil
+					}
+					return err
+				}
+				sample, err := page.Type().Encode(nil, page.Buffer().Data(), enc)
+				if err != nil {
+					return err
+				}
+				samples = append(samples, sample)
+			}
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("reading samples to train zstd dictionary: %w", err)
+		}
+	}
+
+	return zstd.TrainDictionary(samples, size)
+}
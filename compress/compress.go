@@ -36,6 +36,22 @@ type Codec interface {
 	Decode(dst, src []byte) ([]byte, error)
 }
 
+// StreamingCodec is an interface implemented by codecs which can decompress
+// data incrementally, without holding the whole uncompressed output in memory.
+//
+// Readers of parquet files use streaming decompression for pages which are too
+// large to be decompressed at once.
+type StreamingCodec interface {
+	Codec
+
+	// Returns a reader exposing the uncompressed version of the data read
+	// from r.
+	//
+	// The reader must be closed when the program is done using it, which does
+	// not close r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
 type Reader interface {
 	io.ReadCloser
 	Reset(io.Reader) error
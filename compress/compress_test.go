@@ -2,7 +2,9 @@ package compress_test
 
 import (
 	"bytes"
+	stdgzip "compress/gzip"
 	"io"
+	"math/rand"
 	"testing"
 
 	"github.com/segmentio/parquet-go/compress"
@@ -33,6 +35,11 @@ var tests = [...]struct {
 		codec:    new(gzip.Codec),
 	},
 
+	{
+		scenario: "gzip-best-compression",
+		codec:    &gzip.Codec{Level: gzip.BestCompression},
+	},
+
 	{
 		scenario: "brotli",
 		codec:    new(brotli.Codec),
@@ -43,10 +50,20 @@ var tests = [...]struct {
 		codec:    new(zstd.Codec),
 	},
 
+	{
+		scenario: "zstd-level19-window1MiB",
+		codec:    &zstd.Codec{Level: zstd.LevelFromZstd(19), WindowSize: 1 << 20},
+	},
+
 	{
 		scenario: "lz4",
 		codec:    new(lz4.Codec),
 	},
+
+	{
+		scenario: "lz4-level9",
+		codec:    &lz4.Codec{Level: lz4.Level9},
+	},
 }
 
 var testdata = bytes.Repeat([]byte("1234567890qwertyuiopasdfghjklzxcvbnm"), 10e3)
@@ -81,6 +98,188 @@ func TestCompressionCodec(t *testing.T) {
 	}
 }
 
+func TestCompressionCodecInputs(t *testing.T) {
+	random := make([]byte, 10e3)
+	prng := rand.New(rand.NewSource(0))
+	prng.Read(random)
+
+	inputs := []struct {
+		scenario string
+		data     []byte
+	}{
+		{scenario: "empty", data: []byte{}},
+		{scenario: "one byte", data: []byte{42}},
+		{scenario: "incompressible", data: random},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			for _, input := range inputs {
+				compressed, err := test.codec.Encode(nil, input.data)
+				if err != nil {
+					t.Fatalf("%s: %v", input.scenario, err)
+				}
+				output, err := test.codec.Decode(nil, compressed)
+				if err != nil {
+					t.Fatalf("%s: %v", input.scenario, err)
+				}
+				if !bytes.Equal(input.data, output) {
+					t.Errorf("%s: content mismatch after compressing and decompressing", input.scenario)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamingCodec(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		prng.Read(b)
+		return b
+	}
+
+	// The input repeats data at short and long distances, and contains large
+	// incompressible sections.
+	a, b := random(100e3), random(300e3)
+	input := append([]byte{}, a...)
+	input = append(input, b...)
+	input = append(input, a...)
+	input = append(input, testdata...)
+	input = append(input, random(200e3)...)
+
+	for _, test := range tests {
+		codec, ok := test.codec.(compress.StreamingCodec)
+		if !ok {
+			continue
+		}
+		t.Run(test.scenario, func(t *testing.T) {
+			compressed, err := codec.Encode(nil, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			readers := []struct {
+				scenario string
+				reader   io.Reader
+			}{
+				{scenario: "seeker", reader: bytes.NewReader(compressed)},
+				{scenario: "reader", reader: struct{ io.Reader }{bytes.NewReader(compressed)}},
+			}
+
+			for _, r := range readers {
+				stream, err := codec.NewReader(r.reader)
+				if err != nil {
+					t.Fatalf("%s: %v", r.scenario, err)
+				}
+				output := new(bytes.Buffer)
+				// Read with a small buffer to exercise reading the output
+				// in multiple parts.
+				if _, err := io.CopyBuffer(struct{ io.Writer }{output}, struct{ io.Reader }{stream}, make([]byte, 1000)); err != nil {
+					t.Fatalf("%s: %v", r.scenario, err)
+				}
+				if err := stream.Close(); err != nil {
+					t.Fatalf("%s: %v", r.scenario, err)
+				}
+				if !bytes.Equal(input, output.Bytes()) {
+					t.Errorf("%s: content mismatch after compressing and decompressing", r.scenario)
+				}
+			}
+		})
+	}
+}
+
+func TestSnappyStreamingInvalidInput(t *testing.T) {
+	for _, input := range [][]byte{
+		// The block announces 10 bytes, but the literal only has 3.
+		{10, 0x08, 'a', 'b', 'c'},
+		// The copy references data before the beginning of the block.
+		{10, 0x00, 'a', 0x15, 0x02},
+	} {
+		stream, err := new(snappy.Codec).NewReader(bytes.NewReader(input))
+		if err == nil {
+			_, err = io.ReadAll(stream)
+		}
+		if err == nil {
+			t.Errorf("expected an error decoding the invalid snappy block %q", input)
+		}
+	}
+}
+
+func TestCompressionCodecInvalidOptions(t *testing.T) {
+	codecs := []struct {
+		scenario string
+		codec    compress.Codec
+	}{
+		{scenario: "gzip", codec: &gzip.Codec{Level: 42}},
+		{scenario: "zstd", codec: &zstd.Codec{WindowSize: 1000}},
+	}
+
+	for _, test := range codecs {
+		t.Run(test.scenario, func(t *testing.T) {
+			if _, err := test.codec.Encode(nil, testdata); err == nil {
+				t.Error("expected an error compressing with invalid options")
+			}
+		})
+	}
+}
+
+func TestGzipConcurrency(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+	random := make([]byte, 100e3)
+	prng.Read(random)
+
+	inputs := [][]byte{
+		testdata,
+		random,
+		append(testdata[:len(testdata):len(testdata)], random...),
+	}
+
+	for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression, gzip.NoCompression} {
+		codec := &gzip.Codec{Level: level, Concurrency: 4, BlockSize: 64 * 1024}
+
+		for _, input := range inputs {
+			compressed, err := codec.Encode(nil, input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The output must be a single gzip member, which decoders that do
+			// not support concatenated members can read.
+			z, err := stdgzip.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatal(err)
+			}
+			z.Multistream(false)
+			output, err := io.ReadAll(z)
+			if err != nil {
+				t.Fatalf("level %d: %v", level, err)
+			}
+			if !bytes.Equal(input, output) {
+				t.Errorf("level %d: content mismatch after compressing and decompressing", level)
+			}
+			if _, err := z.Read(nil); err != io.EOF {
+				t.Errorf("level %d: the compressed page has more than one gzip member", level)
+			}
+
+			output, err = codec.Decode(nil, compressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(input, output) {
+				t.Errorf("level %d: content mismatch after decoding", level)
+			}
+		}
+	}
+}
+
+func TestLz4DecodeInvalidInput(t *testing.T) {
+	// The token announces 15 literals, but the input is truncated.
+	if _, err := new(lz4.Codec).Decode(nil, []byte{0xf0, 1, 2, 3}); err == nil {
+		t.Error("expected an error decoding an invalid lz4 block")
+	}
+}
+
 func BenchmarkEncode(b *testing.B) {
 	buffer := make([]byte, 0, len(testdata))
 
@@ -4,6 +4,7 @@ package gzip
 import (
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/klauspost/compress/gzip"
 	"github.com/segmentio/parquet-go/compress"
@@ -23,10 +24,28 @@ const (
 )
 
 type Codec struct {
+	// Level controls the compression-speed vs compression-density trade-offs,
+	// ranging from BestSpeed to BestCompression. The special values
+	// NoCompression and HuffmanOnly disable the search for matches, and
+	// DefaultCompression selects the default level. Note that the zero value
+	// is NoCompression.
 	Level int
 
-	r compress.Decompressor
-	w compress.Compressor
+	// Concurrency is the maximum number of goroutines used to compress a page.
+	// When greater than one, pages larger than BlockSize are split in blocks
+	// which are compressed concurrently, reducing the time spent compressing
+	// large pages at the expense of a slightly lower compression ratio. The
+	// output remains a standard gzip stream readable by all gzip decoders.
+	// Zero or one compresses pages sequentially.
+	Concurrency int
+
+	// BlockSize is the size of the blocks compressed concurrently when
+	// Concurrency is greater than one. Zero uses DefaultBlockSize.
+	BlockSize int
+
+	r      compress.Decompressor
+	w      compress.Compressor
+	blocks sync.Pool // *blockWriter
 }
 
 func (c *Codec) String() string {
@@ -38,6 +57,9 @@ func (c *Codec) CompressionCodec() format.CompressionCodec {
 }
 
 func (c *Codec) Encode(dst, src []byte) ([]byte, error) {
+	if c.Concurrency > 1 && len(src) > c.blockSize() {
+		return c.encodeParallel(dst, src)
+	}
 	return c.w.Encode(dst, src, func(w io.Writer) (compress.Writer, error) {
 		return gzip.NewWriterLevel(w, c.Level)
 	})
@@ -53,6 +75,10 @@ func (c *Codec) Decode(dst, src []byte) ([]byte, error) {
 	})
 }
 
+func (c *Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
 type reader struct {
 	*gzip.Reader
 	emptyGzip strings.Reader
@@ -0,0 +1,131 @@
+package gzip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"sync"
+
+	"github.com/klauspost/compress/flate"
+)
+
+const (
+	// DefaultBlockSize is the size of blocks compressed concurrently when the
+	// codec is configured with a concurrency greater than one.
+	DefaultBlockSize = 1024 * 1024
+
+	// Size of the deflate window, which is the amount of data preceding each
+	// block used as dictionary to compress it.
+	windowSize = 32 * 1024
+
+	gzipID1     = 0x1f
+	gzipID2     = 0x8b
+	gzipDeflate = 8
+	gzipUnknown = 0xff
+)
+
+// blockWriter holds the state used to compress one block of a page.
+type blockWriter struct {
+	output bytes.Buffer
+	writer *flate.Writer
+}
+
+// encodeParallel compresses src in blocks of the configured size, using up to
+// Concurrency goroutines.
+//
+// Each block is compressed as a deflate stream using the end of the previous
+// block as dictionary, and terminated by a sync flush which aligns it to a byte
+// boundary, except the last block which terminates the stream. Concatenating
+// the blocks produces a single deflate stream, which is wrapped in a standard
+// gzip member readable by all gzip decoders.
+func (c *Codec) encodeParallel(dst, src []byte) ([]byte, error) {
+	blockSize := c.blockSize()
+	numBlocks := (len(src) + blockSize - 1) / blockSize
+	blocks := make([]*blockWriter, numBlocks)
+	errs := make([]error, numBlocks)
+
+	defer func() {
+		for _, b := range blocks {
+			if b != nil {
+				b.output.Reset()
+				c.blocks.Put(b)
+			}
+		}
+	}()
+
+	sem := make(chan struct{}, c.Concurrency)
+	wg := sync.WaitGroup{}
+
+	for i := range blocks {
+		i := i
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			blocks[i], errs[i] = c.encodeBlock(src, i*blockSize, blockSize)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return dst[:0], err
+		}
+	}
+
+	var header [10]byte
+	header[0] = gzipID1
+	header[1] = gzipID2
+	header[2] = gzipDeflate
+	header[9] = gzipUnknown
+
+	dst = append(dst[:0], header[:]...)
+	for _, b := range blocks {
+		dst = append(dst, b.output.Bytes()...)
+	}
+
+	var trailer [8]byte
+	binary.LittleEndian.PutUint32(trailer[:4], crc32.ChecksumIEEE(src))
+	binary.LittleEndian.PutUint32(trailer[4:], uint32(len(src)))
+	return append(dst, trailer[:]...), nil
+}
+
+func (c *Codec) encodeBlock(src []byte, offset, size int) (*blockWriter, error) {
+	end := offset + size
+	if end > len(src) {
+		end = len(src)
+	}
+	start := offset - windowSize
+	if start < 0 {
+		start = 0
+	}
+	dict := src[start:offset]
+
+	b, _ := c.blocks.Get().(*blockWriter)
+	if b == nil {
+		b = new(blockWriter)
+		w, err := flate.NewWriterDict(&b.output, c.Level, dict)
+		if err != nil {
+			return nil, err
+		}
+		b.writer = w
+	} else {
+		b.writer.ResetDict(&b.output, dict)
+	}
+
+	if _, err := b.writer.Write(src[offset:end]); err != nil {
+		return b, err
+	}
+	if end == len(src) {
+		return b, b.writer.Close()
+	}
+	return b, b.writer.Flush()
+}
+
+func (c *Codec) blockSize() int {
+	if c.BlockSize > 0 {
+		return c.BlockSize
+	}
+	return DefaultBlockSize
+}
@@ -2,6 +2,9 @@
 package lz4
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/pierrec/lz4/v4"
 	"github.com/segmentio/parquet-go/format"
 )
@@ -27,6 +30,9 @@ const (
 
 type Codec struct {
 	Level Level
+
+	compressors   sync.Pool // *lz4.Compressor
+	compressorsHC sync.Pool // *lz4.CompressorHC
 }
 
 func (c *Codec) String() string {
@@ -38,38 +44,78 @@ func (c *Codec) CompressionCodec() format.CompressionCodec {
 }
 
 func (c *Codec) Encode(dst, src []byte) ([]byte, error) {
-	dst = reserveAtLeast(dst, len(src)/4)
+	if len(src) == 0 {
+		// The LZ4 block of an empty input is made of a single token with no
+		// literals, which is what other implementations produce and expect.
+		return append(dst[:0], 0), nil
+	}
 
-	compressor := lz4.CompressorHC{Level: c.Level}
-	for {
-		n, err := compressor.CompressBlock(src, dst)
-		if err != nil { // see Decode for details about error handling
-			dst = make([]byte, 2*len(dst))
-		} else {
-			return dst[:n], nil
+	// The output buffer is sized to hold the worst case of the compression
+	// so the compressors always succeed, even when the input cannot be
+	// compressed (in which case the block only contains literals).
+	dst = reserveAtLeast(dst, lz4.CompressBlockBound(len(src)))
+
+	var n int
+	var err error
+	if c.Level == Fast {
+		compressor, _ := c.compressors.Get().(*lz4.Compressor)
+		if compressor == nil {
+			compressor = new(lz4.Compressor)
+		}
+		n, err = compressor.CompressBlock(src, dst)
+		c.compressors.Put(compressor)
+	} else {
+		compressor, _ := c.compressorsHC.Get().(*lz4.CompressorHC)
+		if compressor == nil {
+			compressor = new(lz4.CompressorHC)
 		}
+		compressor.Level = c.Level
+		n, err = compressor.CompressBlock(src, dst)
+		c.compressorsHC.Put(compressor)
 	}
+	if err != nil {
+		return dst[:0], err
+	}
+	if n == 0 {
+		return dst[:0], fmt.Errorf("lz4 compression of %d bytes produced no output", len(src))
+	}
+	return dst[:n], nil
 }
 
 func (c *Codec) Decode(dst, src []byte) ([]byte, error) {
+	if len(src) == 0 || (len(src) == 1 && src[0] == 0) {
+		// Empty blocks, which the lz4 package fails to decode.
+		return dst[:0], nil
+	}
+
 	// 3x seems like a common compression ratio, so we optimistically size the
 	// output buffer to that size. Feel free to change the value if you observe
 	// different behaviors.
 	dst = reserveAtLeast(dst, 3*len(src))
 
+	// The LZ4 block format cannot represent more than 255 bytes of output per
+	// byte of input, which bounds the size of the output buffer; failing to
+	// decode into a buffer of this size means that the input is invalid.
+	maxSize := 255*len(src) + 16
+
 	for {
 		n, err := lz4.UncompressBlock(src, dst)
-		// The lz4 package does not expose the error values, they are declared
-		// in internal/lz4errors. Based on what I read of the implementation,
-		// the only condition where this function errors is if the output buffer
-		// was too short.
+		// The lz4 package does not differentiate between invalid inputs and
+		// output buffers that are too short, so we grow the buffer until it
+		// reaches the maximum size that the input could decode to.
 		//
 		// https://github.com/pierrec/lz4/blob/a5532e5996ee86d17f8ce2694c08fb5bf3c6b471/internal/lz4block/block.go#L45-L53
-		if err != nil {
-			dst = make([]byte, 2*len(dst))
-		} else {
+		if err == nil {
 			return dst[:n], nil
 		}
+		if len(dst) >= maxSize {
+			return dst[:0], fmt.Errorf("lz4 decompression of %d bytes: %w", len(src), err)
+		}
+		size := 2 * len(dst)
+		if size > maxSize {
+			size = maxSize
+		}
+		dst = make([]byte, size)
 	}
 }
 
@@ -0,0 +1,224 @@
+package snappy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+const (
+	// Amount of decompressed data accumulated past the window before the
+	// reader moves the window back to the beginning of its buffer.
+	readerChunkSize = 64 * 1024
+
+	tagLiteral = 0x00
+	tagCopy1   = 0x01
+	tagCopy2   = 0x02
+	tagCopy4   = 0x03
+)
+
+// NewReader returns a reader which decompresses the snappy block read from r
+// incrementally.
+//
+// Back-references of the snappy format may point anywhere in the previously
+// decompressed data, so the reader retains a window large enough to hold the
+// data that they reference. When r implements io.Seeker, the block is scanned
+// before being decompressed to determine the size of the window, and the
+// reader seeks back to its initial position; the window is usually small since
+// snappy compressors favor recent data. Otherwise, the window holds all the
+// decompressed data.
+func (c *Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	input := bufio.NewReader(r)
+	window := uint64(math.MaxUint64)
+
+	if s, ok := r.(io.Seeker); ok {
+		offset, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if window, err = maxCopyOffset(input); err != nil {
+			return nil, err
+		}
+		if _, err := s.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		input.Reset(r)
+	}
+
+	length, err := binary.ReadUvarint(input)
+	if err != nil {
+		return nil, snappy.ErrCorrupt
+	}
+	if window > length {
+		window = length
+	}
+	return &reader{input: input, remain: length, window: int(window)}, nil
+}
+
+type reader struct {
+	input   *bufio.Reader
+	buffer  []byte
+	offset  int
+	window  int
+	remain  uint64
+	literal int
+}
+
+func (r *reader) Close() error {
+	r.input, r.buffer = nil, nil
+	return nil
+}
+
+func (r *reader) Read(b []byte) (int, error) {
+	for r.offset == len(r.buffer) {
+		if r.remain == 0 {
+			if r.literal != 0 {
+				return 0, snappy.ErrCorrupt
+			}
+			return 0, io.EOF
+		}
+		if err := r.decode(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, r.buffer[r.offset:])
+	r.offset += n
+	return n, nil
+}
+
+// decode decompresses the next element of the block, or the next part of the
+// literal being decompressed, into the buffer of r.
+func (r *reader) decode() error {
+	if len(r.buffer) >= r.window+readerChunkSize {
+		n := copy(r.buffer, r.buffer[len(r.buffer)-r.window:])
+		r.buffer = r.buffer[:n]
+		r.offset = n
+	}
+
+	if r.literal > 0 {
+		n := r.literal
+		if n > readerChunkSize {
+			n = readerChunkSize
+		}
+		if uint64(n) > r.remain {
+			return snappy.ErrCorrupt
+		}
+		i := len(r.buffer)
+		r.grow(n)
+		if _, err := io.ReadFull(r.input, r.buffer[i:]); err != nil {
+			return snappy.ErrCorrupt
+		}
+		r.literal -= n
+		r.remain -= uint64(n)
+		return nil
+	}
+
+	tag, length, offset, err := readElement(r.input)
+	if err != nil {
+		return err
+	}
+	if tag == tagLiteral {
+		r.literal = length
+		return nil
+	}
+	if offset <= 0 || offset > len(r.buffer) || uint64(length) > r.remain {
+		return snappy.ErrCorrupt
+	}
+	i := len(r.buffer)
+	r.grow(length)
+	if offset >= length {
+		copy(r.buffer[i:], r.buffer[i-offset:])
+	} else {
+		// The copy overlaps with its output, which repeats the last offset
+		// bytes of the buffer.
+		for j := i; j < len(r.buffer); j++ {
+			r.buffer[j] = r.buffer[j-offset]
+		}
+	}
+	r.remain -= uint64(length)
+	return nil
+}
+
+func (r *reader) grow(n int) {
+	if i := len(r.buffer); cap(r.buffer)-i < n {
+		size := 2 * cap(r.buffer)
+		if size < i+n {
+			size = i + n
+		}
+		buffer := make([]byte, i, size)
+		copy(buffer, r.buffer)
+		r.buffer = buffer
+	}
+	r.buffer = r.buffer[:len(r.buffer)+n]
+}
+
+// readElement reads the tag of the next element of a snappy block from r. For
+// literals, the returned length is the number of bytes following the tag. For
+// copies, it is the number of bytes copied from the given offset.
+func readElement(r *bufio.Reader) (tag byte, length, offset int, err error) {
+	var b [4]byte
+	if b[0], err = r.ReadByte(); err != nil {
+		return 0, 0, 0, snappy.ErrCorrupt
+	}
+	tag = b[0] & 0x03
+
+	switch tag {
+	case tagLiteral:
+		length = int(b[0] >> 2)
+		if length >= 60 {
+			n := length - 59
+			if _, err := io.ReadFull(r, b[:n]); err != nil {
+				return 0, 0, 0, snappy.ErrCorrupt
+			}
+			length = int(binary.LittleEndian.Uint32(b[:]))
+		}
+		length++
+	case tagCopy1:
+		if b[1], err = r.ReadByte(); err != nil {
+			return 0, 0, 0, snappy.ErrCorrupt
+		}
+		length = 4 + int(b[0]>>2)&0x07
+		offset = int(b[0]&0xE0)<<3 | int(b[1])
+	case tagCopy2:
+		length = 1 + int(b[0]>>2)
+		if _, err := io.ReadFull(r, b[:2]); err != nil {
+			return 0, 0, 0, snappy.ErrCorrupt
+		}
+		offset = int(binary.LittleEndian.Uint16(b[:2]))
+	case tagCopy4:
+		length = 1 + int(b[0]>>2)
+		if _, err := io.ReadFull(r, b[:4]); err != nil {
+			return 0, 0, 0, snappy.ErrCorrupt
+		}
+		offset = int(binary.LittleEndian.Uint32(b[:4]))
+	}
+	return tag, length, offset, nil
+}
+
+// maxCopyOffset scans the snappy block read from r and returns the largest
+// offset of its copies.
+func maxCopyOffset(r *bufio.Reader) (uint64, error) {
+	if _, err := binary.ReadUvarint(r); err != nil {
+		return 0, snappy.ErrCorrupt
+	}
+	max := 0
+	for {
+		if _, err := r.Peek(1); err == io.EOF {
+			return uint64(max), nil
+		}
+		tag, length, offset, err := readElement(r)
+		if err != nil {
+			return 0, err
+		}
+		if tag == tagLiteral {
+			if n, _ := r.Discard(length); n != length {
+				return 0, snappy.ErrCorrupt
+			}
+		} else if offset > max {
+			max = offset
+		}
+	}
+}
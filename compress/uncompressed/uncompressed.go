@@ -4,6 +4,8 @@
 package uncompressed
 
 import (
+	"io"
+
 	"github.com/segmentio/parquet-go/format"
 )
 
@@ -25,3 +27,7 @@ func (c *Codec) Encode(dst, src []byte) ([]byte, error) {
 func (c *Codec) Decode(dst, src []byte) ([]byte, error) {
 	return append(dst[:0], src...), nil
 }
+
+func (c *Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(r), nil
+}
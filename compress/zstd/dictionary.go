@@ -0,0 +1,343 @@
+package zstd
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/klauspost/compress/huff0"
+)
+
+const (
+	// Length of the byte sequences that the dictionary trainer looks for in
+	// the samples; 8 bytes allows them to be represented by uint64 values.
+	dictionaryMatchLength = 8
+	// Length of the segments of samples that the dictionary is built from.
+	dictionarySegmentLength = 256
+	// Minimum size of the content of a dictionary, since the initial repeat
+	// offsets of the dictionary must be within its content.
+	minDictionaryContentSize = 8
+)
+
+var (
+	dictionaryMagic = [4]byte{0x37, 0xa4, 0x30, 0xec}
+
+	// Default distributions of the literal lengths, match lengths and offset
+	// codes defined by the zstd format, used as the entropy tables of trained
+	// dictionaries.
+	//
+	// https://github.com/facebook/zstd/blob/dev/doc/zstd_compression_format.md#default-distributions
+	literalLengthsDistribution = [...]int16{
+		4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1,
+		2, 2, 2, 2, 2, 2, 2, 2, 2, 3, 2, 1, 1, 1, 1, 1,
+		-1, -1, -1, -1,
+	}
+	matchLengthsDistribution = [...]int16{
+		1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1,
+		-1, -1, -1, -1, -1,
+	}
+	offsetCodesDistribution = [...]int16{
+		1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1,
+	}
+)
+
+// ErrDictionaryTraining is returned by TrainDictionary when the samples do not
+// contain enough data to train a dictionary.
+var ErrDictionaryTraining = errors.New("not enough data to train a zstd dictionary")
+
+// TrainDictionary trains a zstd dictionary of at most size bytes from the given
+// samples, which should be representative of the data compressed with it (for
+// example, the encoded pages of a column).
+//
+// The content of the dictionary is made of the segments of the samples which
+// contain the byte sequences shared by the most samples, and the literals of
+// the samples are used to generate its Huffman table. The dictionary uses the
+// default distributions of the zstd format for its other entropy tables.
+//
+// The function returns an error wrapping ErrDictionaryTraining if the samples
+// do not contain enough data to produce a dictionary.
+func TrainDictionary(samples [][]byte, size int) ([]byte, error) {
+	literals, err := dictionaryLiteralsTable(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, 512)
+	header = append(header, dictionaryMagic[:]...)
+	header = append(header, 0, 0, 0, 0) // dictionary id, set below
+	header = append(header, literals...)
+	header = appendNormalizedCounts(header, offsetCodesDistribution[:], 5)
+	header = appendNormalizedCounts(header, matchLengthsDistribution[:], 6)
+	header = appendNormalizedCounts(header, literalLengthsDistribution[:], 6)
+	// Initial repeat offsets, which are the default values of the format.
+	header = append(header,
+		1, 0, 0, 0,
+		4, 0, 0, 0,
+		8, 0, 0, 0,
+	)
+
+	if size-len(header) < minDictionaryContentSize {
+		return nil, fmt.Errorf("zstd dictionary size must be at least %d bytes: %d", len(header)+minDictionaryContentSize, size)
+	}
+
+	content := dictionaryContent(samples, size-len(header))
+	if len(content) < minDictionaryContentSize {
+		return nil, fmt.Errorf("%w: the samples have no repeated byte sequences", ErrDictionaryTraining)
+	}
+
+	// Identifiers below 32768 are reserved by the zstd format, the id is
+	// derived from the content so the same dictionary always has the same
+	// id.
+	id := 32768 + crc32.ChecksumIEEE(content)%(1<<31-32768)
+	binary.LittleEndian.PutUint32(header[4:], id)
+	return append(header, content...), nil
+}
+
+// dictionaryLiteralsTable returns the serialized Huffman table generated from
+// the bytes of the samples. All byte values are given a code so that the table
+// can be used to compress literals which were not seen in the samples.
+func dictionaryLiteralsTable(samples [][]byte) ([]byte, error) {
+	var histogram [256]int
+	var total int
+	for _, sample := range samples {
+		for _, b := range sample {
+			histogram[b]++
+		}
+		total += len(sample)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: the samples are empty", ErrDictionaryTraining)
+	}
+
+	// The input of the Huffman encoder is synthesized from the histogram,
+	// scaled down to bound the memory footprint. When the distribution of
+	// the bytes is too flat to be compressed, a skewed distribution is used
+	// instead since the encoder only produces tables for compressible data.
+	const maxInputSize = 1 << 15
+	for _, skewed := range []bool{false, true} {
+		input := make([]byte, 0, maxInputSize+256)
+		for b, count := range histogram {
+			n := 1 + (count*maxInputSize)/total
+			if skewed && b == 0 {
+				n += maxInputSize
+			}
+			for i := 0; i < n; i++ {
+				input = append(input, byte(b))
+			}
+		}
+
+		s := &huff0.Scratch{Reuse: huff0.ReusePolicyNone}
+		_, _, err := huff0.Compress1X(input, s)
+		switch err {
+		case nil:
+			return s.OutTable, nil
+		case huff0.ErrIncompressible, huff0.ErrUseRLE:
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w: cannot generate a Huffman table for the samples", ErrDictionaryTraining)
+}
+
+// dictionaryContent selects segments of the samples to form the content of a
+// dictionary of up to size bytes.
+//
+// The segments are scored by summing the number of samples that each of their
+// byte sequences appears in, and greedily selected by decreasing score. Once a
+// segment is selected, the byte sequences that it contains do not contribute
+// to the score of other segments anymore. Since the scores never increase, the
+// scores of segments are lazily updated when they reach the top of the queue.
+func dictionaryContent(samples [][]byte, size int) []byte {
+	frequencies := make(map[uint64]*dictionarySequence)
+
+	for i, sample := range samples {
+		forEachDictionarySequence(sample, func(key uint64) {
+			seq := frequencies[key]
+			if seq == nil {
+				seq = new(dictionarySequence)
+				frequencies[key] = seq
+			}
+			if seq.lastSample != i+1 {
+				seq.lastSample = i + 1
+				seq.count++
+			}
+		})
+	}
+
+	score := func(segment []byte) (score int) {
+		forEachDictionarySequence(segment, func(key uint64) {
+			// Sequences seen in a single sample do not help compression
+			// since they are not repeated across pages.
+			if seq := frequencies[key]; seq.count > 1 {
+				score += seq.count
+			}
+		})
+		return score
+	}
+
+	queue := make(dictionarySegmentQueue, 0, 64)
+	for _, sample := range samples {
+		for i := 0; i+dictionaryMatchLength <= len(sample); i += dictionarySegmentLength / 2 {
+			j := i + dictionarySegmentLength
+			if j > len(sample) {
+				j = len(sample)
+			}
+			segment := sample[i:j:j]
+			if s := score(segment); s > 0 {
+				queue = append(queue, dictionarySegment{data: segment, score: s})
+			}
+		}
+	}
+	heap.Init(&queue)
+
+	var selected [][]byte
+	var length int
+
+	for len(queue) > 0 && length < size {
+		top := &queue[0]
+		if s := score(top.data); s != top.score {
+			if top.score = s; s == 0 {
+				heap.Pop(&queue)
+			} else {
+				heap.Fix(&queue, 0)
+			}
+			continue
+		}
+
+		segment := heap.Pop(&queue).(dictionarySegment).data
+		if n := size - length; len(segment) > n {
+			segment = segment[len(segment)-n:]
+		}
+		forEachDictionarySequence(segment, func(key uint64) {
+			frequencies[key].count = 0
+		})
+		selected = append(selected, segment)
+		length += len(segment)
+	}
+
+	// The zstd encoder favors matches at short distances, which are at the
+	// end of the dictionary, so the best segments are placed last.
+	content := make([]byte, 0, length)
+	for i := len(selected) - 1; i >= 0; i-- {
+		content = append(content, selected[i]...)
+	}
+	return content
+}
+
+func forEachDictionarySequence(data []byte, do func(uint64)) {
+	for i := 0; i+dictionaryMatchLength <= len(data); i++ {
+		do(binary.LittleEndian.Uint64(data[i:]))
+	}
+}
+
+type dictionarySequence struct {
+	count      int
+	lastSample int
+}
+
+type dictionarySegment struct {
+	data  []byte
+	score int
+}
+
+type dictionarySegmentQueue []dictionarySegment
+
+func (q dictionarySegmentQueue) Len() int { return len(q) }
+
+func (q dictionarySegmentQueue) Less(i, j int) bool { return q[i].score > q[j].score }
+
+func (q dictionarySegmentQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *dictionarySegmentQueue) Push(x interface{}) {
+	*q = append(*q, x.(dictionarySegment))
+}
+
+func (q *dictionarySegmentQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// appendNormalizedCounts appends the FSE table description of the normalized
+// counts to b, as described in the zstd format; counts of -1 represent symbols
+// with a "less than 1" probability.
+//
+// https://github.com/facebook/zstd/blob/dev/doc/zstd_compression_format.md#fse-table-description
+func appendNormalizedCounts(b []byte, counts []int16, tableLog uint) []byte {
+	const minTableLog = 5
+	tableSize := 1 << tableLog
+	remaining := tableSize + 1
+	threshold := tableSize
+	nbBits := tableLog + 1
+	bitStream := uint32(tableLog - minTableLog)
+	bitCount := uint(4)
+	previousIs0 := false
+
+	flush := func() {
+		if bitCount > 16 {
+			b = append(b, byte(bitStream), byte(bitStream>>8))
+			bitStream >>= 16
+			bitCount -= 16
+		}
+	}
+
+	for symbol := 0; symbol < len(counts) && remaining > 1; {
+		if previousIs0 {
+			start := symbol
+			for symbol < len(counts) && counts[symbol] == 0 {
+				symbol++
+			}
+			if symbol == len(counts) {
+				break
+			}
+			for symbol >= start+24 {
+				start += 24
+				bitStream += 0xFFFF << bitCount
+				b = append(b, byte(bitStream), byte(bitStream>>8))
+				bitStream >>= 16
+			}
+			for symbol >= start+3 {
+				start += 3
+				bitStream += 3 << bitCount
+				bitCount += 2
+			}
+			bitStream += uint32(symbol-start) << bitCount
+			bitCount += 2
+			flush()
+		}
+
+		count := int(counts[symbol])
+		symbol++
+		max := (2*threshold - 1) - remaining
+		if count < 0 {
+			remaining += count
+		} else {
+			remaining -= count
+		}
+		count++ // +1 for extra accuracy
+		if count >= threshold {
+			count += max
+		}
+		bitStream += uint32(count) << bitCount
+		bitCount += nbBits
+		if count < max {
+			bitCount--
+		}
+		previousIs0 = count == 1
+		for remaining < threshold {
+			nbBits--
+			threshold >>= 1
+		}
+		flush()
+	}
+
+	b = append(b, byte(bitStream), byte(bitStream>>8))
+	return b[:len(b)-2+int(bitCount+7)/8]
+}
@@ -0,0 +1,79 @@
+package zstd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/segmentio/parquet-go/compress/zstd"
+)
+
+func makeDictionarySamples(n int, prng *rand.Rand) [][]byte {
+	samples := make([][]byte, n)
+	for i := range samples {
+		b := new(bytes.Buffer)
+		for j := 0; j < 4; j++ {
+			fmt.Fprintf(b, `{"user_id":%d,"url":"https://www.example.com/products/%d?ref=homepage","status":"ok"}`, prng.Int63(), prng.Intn(1000))
+		}
+		samples[i] = b.Bytes()
+	}
+	return samples
+}
+
+func TestTrainDictionary(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+	dict, err := zstd.TrainDictionary(makeDictionarySamples(100, prng), 4096)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dict) > 4096 {
+		t.Errorf("dictionary is larger than requested: %d", len(dict))
+	}
+
+	withDict := &zstd.Codec{Dictionary: dict}
+	withoutDict := &zstd.Codec{}
+
+	sizeWithDict, sizeWithoutDict := 0, 0
+	for _, sample := range makeDictionarySamples(100, prng) {
+		compressed, err := withDict.Encode(nil, sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decompressed, err := withDict.Decode(nil, compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decompressed, sample) {
+			t.Fatal("decompressed sample mismatch")
+		}
+		if _, err := withoutDict.Decode(nil, compressed); err == nil {
+			t.Fatal("decompressing without the dictionary should fail")
+		}
+
+		sizeWithDict += len(compressed)
+		compressed, err = withoutDict.Encode(nil, sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizeWithoutDict += len(compressed)
+	}
+
+	t.Logf("compressed size: %d with dictionary, %d without", sizeWithDict, sizeWithoutDict)
+	if sizeWithDict >= sizeWithoutDict {
+		t.Errorf("the dictionary did not improve compression: %d >= %d", sizeWithDict, sizeWithoutDict)
+	}
+}
+
+func TestTrainDictionaryNotEnoughData(t *testing.T) {
+	for _, samples := range [][][]byte{
+		nil,
+		{[]byte("hello"), []byte("world")},
+	} {
+		_, err := zstd.TrainDictionary(samples, 4096)
+		if !errors.Is(err, zstd.ErrDictionaryTraining) {
+			t.Errorf("expected an error wrapping ErrDictionaryTraining, got %v", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package zstd
 
 import (
+	"io"
 	"sync"
 
 	"github.com/klauspost/compress/zstd"
@@ -31,11 +32,75 @@ const (
 
 const (
 	DefaultLevel = SpeedDefault
+
+	// MinWindowSize and MaxWindowSize are the bounds of the window sizes that
+	// codecs can be configured with.
+	MinWindowSize = zstd.MinWindowSize
+	MaxWindowSize = zstd.MaxWindowSize
 )
 
+// LevelFromZstd returns the compression level closest to the given level of the
+// zstd command line tool, which ranges from 1 to 22.
+func LevelFromZstd(level int) Level {
+	return zstd.EncoderLevelFromZstd(level)
+}
+
+// Encoder is the interface implemented by zstd encoders that codecs can be
+// configured with. The *zstd.Encoder type of github.com/klauspost/compress
+// implements this interface.
+//
+// Encoders must be safe to use concurrently from multiple goroutines.
+type Encoder interface {
+	// EncodeAll compresses src as a zstd frame appended to dst, returning the
+	// extended buffer.
+	EncodeAll(src, dst []byte) []byte
+}
+
+// Decoder is the interface implemented by zstd decoders that codecs can be
+// configured with. The *zstd.Decoder type of github.com/klauspost/compress
+// implements this interface.
+//
+// Decoders must be safe to use concurrently from multiple goroutines.
+type Decoder interface {
+	// DecodeAll decompresses the zstd frames of src appended to dst, returning
+	// the extended buffer.
+	DecodeAll(src, dst []byte) ([]byte, error)
+}
+
 type Codec struct {
+	// Level controls the compression-speed vs compression-density trade-offs.
+	// Zero uses DefaultLevel.
 	Level Level
 
+	// WindowSize is the maximum distance at which the encoder looks for matches
+	// in previous data, which must be a power of two between MinWindowSize and
+	// MaxWindowSize. Larger windows may improve compression of large pages at
+	// the expense of memory usage when compressing and decompressing. Zero
+	// uses the default window size of the compression level.
+	WindowSize int
+
+	// Dictionary is a zstd dictionary used to compress and decompress pages,
+	// which improves the compression of small pages of similar data. The
+	// dictionary must use the zstd dictionary format, as generated by
+	// TrainDictionary or the zstd command line tool.
+	//
+	// Pages compressed with a dictionary can only be decompressed by codecs
+	// configured with the same dictionary. Pages compressed without one can
+	// be decompressed by all codecs.
+	Dictionary []byte
+
+	// Encoder and Decoder allow the application to supply the zstd encoder and
+	// decoder used by the codec, for example to use a custom concurrency or
+	// window configuration, or a cgo-backed implementation. When nil, the codec
+	// manages pools of encoders and decoders of its own.
+	//
+	// The Level, WindowSize and Dictionary fields do not apply to encoders and
+	// decoders supplied by the application, which must be configured to use
+	// the dictionary if pages are compressed with one. Readers created by
+	// NewReader always use a decoder managed by the codec.
+	Encoder Encoder
+	Decoder Decoder
+
 	encoders sync.Pool // *zstd.Encoder
 	decoders sync.Pool // *zstd.Decoder
 }
@@ -49,15 +114,25 @@ func (c *Codec) CompressionCodec() format.CompressionCodec {
 }
 
 func (c *Codec) Encode(dst, src []byte) ([]byte, error) {
+	if c.Encoder != nil {
+		return c.Encoder.EncodeAll(src, dst[:0]), nil
+	}
 	e, _ := c.encoders.Get().(*zstd.Encoder)
 	if e == nil {
-		var err error
-		e, err = zstd.NewWriter(nil,
+		options := []zstd.EOption{
 			zstd.WithEncoderConcurrency(1),
 			zstd.WithEncoderLevel(c.level()),
 			zstd.WithZeroFrames(true),
 			zstd.WithEncoderCRC(false),
-		)
+		}
+		if c.WindowSize != 0 {
+			options = append(options, zstd.WithWindowSize(c.WindowSize))
+		}
+		if len(c.Dictionary) != 0 {
+			options = append(options, zstd.WithEncoderDict(c.Dictionary))
+		}
+		var err error
+		e, err = zstd.NewWriter(nil, options...)
 		if err != nil {
 			return dst[:0], err
 		}
@@ -67,12 +142,19 @@ func (c *Codec) Encode(dst, src []byte) ([]byte, error) {
 }
 
 func (c *Codec) Decode(dst, src []byte) ([]byte, error) {
+	if c.Decoder != nil {
+		return c.Decoder.DecodeAll(src, dst[:0])
+	}
 	d, _ := c.decoders.Get().(*zstd.Decoder)
 	if d == nil {
-		var err error
-		d, err = zstd.NewReader(nil,
+		options := []zstd.DOption{
 			zstd.WithDecoderConcurrency(1),
-		)
+		}
+		if len(c.Dictionary) != 0 {
+			options = append(options, zstd.WithDecoderDicts(c.Dictionary))
+		}
+		var err error
+		d, err = zstd.NewReader(nil, options...)
 		if err != nil {
 			return dst[:0], err
 		}
@@ -81,6 +163,23 @@ func (c *Codec) Decode(dst, src []byte) ([]byte, error) {
 	return d.DecodeAll(src, dst[:0])
 }
 
+// NewReader returns a reader decompressing the zstd frames read from r, which
+// holds at most the window of the frames in memory.
+func (c *Codec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	options := []zstd.DOption{
+		zstd.WithDecoderConcurrency(1),
+		zstd.WithDecoderLowmem(true),
+	}
+	if len(c.Dictionary) != 0 {
+		options = append(options, zstd.WithDecoderDicts(c.Dictionary))
+	}
+	d, err := zstd.NewReader(r, options...)
+	if err != nil {
+		return nil, err
+	}
+	return d.IOReadCloser(), nil
+}
+
 func (c *Codec) level() Level {
 	if c.Level != 0 {
 		return c.Level
@@ -0,0 +1,76 @@
+package zstd_test
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+
+	kzstd "github.com/klauspost/compress/zstd"
+	"github.com/segmentio/parquet-go/compress/zstd"
+)
+
+type countingEncoder struct {
+	zstd.Encoder
+	calls int64
+}
+
+func (e *countingEncoder) EncodeAll(src, dst []byte) []byte {
+	atomic.AddInt64(&e.calls, 1)
+	return e.Encoder.EncodeAll(src, dst)
+}
+
+type countingDecoder struct {
+	zstd.Decoder
+	calls int64
+}
+
+func (d *countingDecoder) DecodeAll(src, dst []byte) ([]byte, error) {
+	atomic.AddInt64(&d.calls, 1)
+	return d.Decoder.DecodeAll(src, dst)
+}
+
+func TestCodecEncoderDecoder(t *testing.T) {
+	e, err := kzstd.NewWriter(nil, kzstd.WithEncoderConcurrency(2), kzstd.WithWindowSize(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	d, err := kzstd.NewReader(nil, kzstd.WithDecoderConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	encoder := &countingEncoder{Encoder: e}
+	decoder := &countingDecoder{Decoder: d}
+	codec := &zstd.Codec{Encoder: encoder, Decoder: decoder}
+
+	data := bytes.Repeat([]byte("1234567890qwertyuiopasdfghjklzxcvbnm"), 1000)
+	compressed, err := codec.Encode(nil, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompressed, err := codec.Decode(nil, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, data) {
+		t.Fatal("decompressed data mismatch")
+	}
+	if encoder.calls != 1 || decoder.calls != 1 {
+		t.Errorf("the codec did not use the encoder and decoder: encodes=%d decodes=%d", encoder.calls, decoder.calls)
+	}
+
+	// Pages compressed with the application encoder are readable by codecs
+	// managing their own decoders, and conversely.
+	if b, err := new(zstd.Codec).Decode(nil, compressed); err != nil || !bytes.Equal(b, data) {
+		t.Errorf("decompressing with the default decoder: %v", err)
+	}
+	if compressed, err = new(zstd.Codec).Encode(nil, data); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := codec.Decode(nil, compressed); err != nil || !bytes.Equal(b, data) {
+		t.Errorf("decompressing with the application decoder: %v", err)
+	}
+}
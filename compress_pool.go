@@ -0,0 +1,125 @@
+package parquet
+
+import (
+	"sync"
+	"time"
+)
+
+// CompressionPool is used to bound the number of pages compressed or
+// decompressed concurrently across parquet writers and readers.
+//
+// Programs which write or read dozens of files concurrently can share a single
+// pool between all of them to cap the CPU time spent on compression, instead of
+// letting every writer and reader compete for the processors. When all the
+// workers of the pool are busy, the goroutines which need to compress or
+// decompress a page are queued until a worker becomes available. The work is
+// performed by the goroutine which submitted it; the pool does not start
+// goroutines of its own.
+//
+// The Stats method reports metrics on the pool, which can be used to observe
+// how much time writers and readers spend waiting in its queue.
+//
+// CompressionPool values are safe to use concurrently from multiple goroutines.
+type CompressionPool struct {
+	mutex   sync.Mutex
+	cond    sync.Cond
+	workers int
+	stats   CompressionPoolStats
+}
+
+// CompressionPoolStats is a snapshot of the metrics of a CompressionPool.
+type CompressionPoolStats struct {
+	// Number of pages being compressed or decompressed.
+	Active int
+	// Number of pages waiting for a worker to become available.
+	Queued int
+	// Total number of pages compressed or decompressed by the pool.
+	Completed int64
+	// Total number of pages which had to wait for a worker.
+	Waited int64
+	// Cumulative time spent by pages waiting for a worker.
+	WaitTime time.Duration
+}
+
+// NewCompressionPool constructs a CompressionPool allowing up to workers pages
+// to be compressed or decompressed at once. The number of workers is set to
+// one if it is less than one.
+func NewCompressionPool(workers int) *CompressionPool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &CompressionPool{workers: workers}
+	p.cond.L = &p.mutex
+	return p
+}
+
+// Workers returns the maximum number of pages processed concurrently by the
+// pool.
+func (p *CompressionPool) Workers() int { return p.workers }
+
+// Stats returns a snapshot of the metrics of the pool.
+func (p *CompressionPool) Stats() CompressionPoolStats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.stats
+}
+
+func (p *CompressionPool) do(task func() error) error {
+	p.acquire()
+	defer p.release()
+	return task()
+}
+
+func (p *CompressionPool) acquire() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stats.Active >= p.workers {
+		start := time.Now()
+		p.stats.Queued++
+		for p.stats.Active >= p.workers {
+			p.cond.Wait()
+		}
+		p.stats.Queued--
+		p.stats.Waited++
+		p.stats.WaitTime += time.Since(start)
+	}
+
+	p.stats.Active++
+}
+
+func (p *CompressionPool) release() {
+	p.mutex.Lock()
+	p.stats.Active--
+	p.stats.Completed++
+	p.mutex.Unlock()
+	p.cond.Signal()
+}
+
+// CompressionWorkers creates a configuration option which installs the pool
+// to bound the number of pages compressed or decompressed concurrently.
+//
+// When passed to a reader constructor which opens the file itself, the pool
+// is also installed on the file.
+func CompressionWorkers(pool *CompressionPool) interface {
+	FileOption
+	ReaderOption
+	WriterOption
+} {
+	return compressionWorkers{pool}
+}
+
+type compressionWorkers struct{ pool *CompressionPool }
+
+func (c compressionWorkers) ConfigureFile(config *FileConfig) { config.CompressionPool = c.pool }
+
+func (c compressionWorkers) ConfigureReader(config *ReaderConfig) { config.CompressionPool = c.pool }
+
+func (c compressionWorkers) ConfigureWriter(config *WriterConfig) { config.CompressionPool = c.pool }
+
+func coalesceCompressionPool(p1, p2 *CompressionPool) *CompressionPool {
+	if p1 != nil {
+		return p1
+	}
+	return p2
+}
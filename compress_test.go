@@ -0,0 +1,257 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/compress"
+	"github.com/segmentio/parquet-go/format"
+)
+
+func TestZstdDictionary(t *testing.T) {
+	type Row struct {
+		ID  int64  `parquet:"id"`
+		URL string `parquet:"url"`
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	generateRows := func(n int) []Row {
+		rows := make([]Row, n)
+		for i := range rows {
+			rows[i] = Row{
+				ID:  int64(i),
+				URL: fmt.Sprintf("https://www.example.com/products/%d?ref=homepage&session=%x", prng.Intn(1000), prng.Int63()),
+			}
+		}
+		return rows
+	}
+
+	write := func(rows []Row, options ...parquet.WriterOption) []byte {
+		b := new(bytes.Buffer)
+		options = append(options, parquet.Compression(&parquet.Zstd), parquet.PageBufferSize(1024))
+		w := parquet.NewWriter(b, options...)
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return b.Bytes()
+	}
+
+	columnSize := func(data []byte) int64 {
+		return readFileMetaData(t, data).RowGroups[0].Columns[1].MetaData.TotalCompressedSize
+	}
+
+	samples := write(generateRows(1000))
+	f, err := parquet.OpenFile(bytes.NewReader(samples), int64(len(samples)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	column, _ := parquet.SchemaOf(new(Row)).Lookup("url")
+	dict, err := parquet.TrainZstdDictionary(4096, &parquet.DeltaLengthByteArray, f.RowGroups()[0].ColumnChunks()[column.ColumnIndex])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := generateRows(1000)
+	withoutDict := write(rows)
+	withDict := write(rows, parquet.ZstdDictionary(dict, "url"))
+
+	if sizeWithDict, sizeWithoutDict := columnSize(withDict), columnSize(withoutDict); sizeWithDict >= sizeWithoutDict {
+		t.Errorf("the dictionary did not improve compression: %d >= %d", sizeWithDict, sizeWithoutDict)
+	}
+
+	r := parquet.NewReader(bytes.NewReader(withDict), parquet.ZstdDictionary(dict, "url"))
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+
+	r = parquet.NewReader(bytes.NewReader(withDict))
+	if err := r.Read(new(Row)); err == nil {
+		t.Error("reading pages compressed with a dictionary should fail without it")
+	}
+}
+
+func TestLz4RawCompression(t *testing.T) {
+	t.Run("testdata", func(t *testing.T) {
+		f, err := os.Open("testdata/lz4_raw_compressed.parquet")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		type Row struct {
+			C0  int64    `parquet:"c0"`
+			C1  []byte   `parquet:"c1"`
+			V11 *float64 `parquet:"v11,optional"`
+		}
+
+		r := parquet.NewReader(f)
+		want := []string{"abc", "def", "abc", "def"}
+		for i := range want {
+			row := Row{}
+			if err := r.Read(&row); err != nil {
+				t.Fatal(err)
+			}
+			if string(row.C1) != want[i] {
+				t.Errorf("row %d: wrong value of c1: want=%q got=%q", i, want[i], row.C1)
+			}
+		}
+	})
+
+	t.Run("roundtrip", func(t *testing.T) {
+		type Row struct {
+			ID   int64  `parquet:"id"`
+			Data []byte `parquet:"data,lz4"`
+		}
+
+		// Random data cannot be compressed, which must still produce valid
+		// LZ4 blocks; empty values produce pages of empty blocks.
+		prng := rand.New(rand.NewSource(0))
+		rows := make([]Row, 100)
+		for i := range rows {
+			rows[i].ID = int64(i)
+			if i%2 == 0 {
+				rows[i].Data = make([]byte, 64)
+				prng.Read(rows[i].Data)
+			}
+		}
+
+		b := new(bytes.Buffer)
+		w := parquet.NewWriter(b, parquet.Compression(&parquet.Lz4Raw))
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, column := range readFileMetaData(t, b.Bytes()).RowGroups[0].Columns {
+			if codec := column.MetaData.Codec; codec != format.Lz4Raw {
+				t.Errorf("column %q has the wrong compression codec: %s", column.MetaData.PathInSchema, codec)
+			}
+		}
+
+		r := parquet.NewReader(bytes.NewReader(b.Bytes()))
+		for i, want := range rows {
+			got := Row{}
+			if err := r.Read(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != want.ID || !bytes.Equal(got.Data, want.Data) {
+				t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+			}
+		}
+	})
+}
+
+// concurrencyCodec wraps a codec to record the maximum number of pages that
+// it compressed concurrently.
+type concurrencyCodec struct {
+	compress.Codec
+	active    int32
+	maxActive int32
+}
+
+func (c *concurrencyCodec) Encode(dst, src []byte) ([]byte, error) {
+	n := atomic.AddInt32(&c.active, 1)
+	defer atomic.AddInt32(&c.active, -1)
+	for {
+		max := atomic.LoadInt32(&c.maxActive)
+		if n <= max || atomic.CompareAndSwapInt32(&c.maxActive, max, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return c.Codec.Encode(dst, src)
+}
+
+func TestCompressionPool(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	const numWriters = 8
+	const numRows = 1000
+	pool := parquet.NewCompressionPool(2)
+	codec := &concurrencyCodec{Codec: &parquet.Snappy}
+	files := make([][]byte, numWriters)
+	errs := make(chan error, numWriters)
+
+	for i := range files {
+		go func(i int) {
+			buffer := new(bytes.Buffer)
+			writer := parquet.NewWriter(buffer,
+				parquet.SchemaOf(Row{}),
+				parquet.Compression(codec),
+				parquet.CompressionWorkers(pool),
+				parquet.PageBufferSize(256),
+			)
+			for j := 0; j < numRows; j++ {
+				if err := writer.Write(&Row{Value: int64(j)}); err != nil {
+					errs <- err
+					return
+				}
+			}
+			err := writer.Close()
+			files[i] = buffer.Bytes()
+			errs <- err
+		}(i)
+	}
+
+	for range files {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if max := atomic.LoadInt32(&codec.maxActive); max > int32(pool.Workers()) {
+		t.Errorf("too many pages compressed concurrently: max=%d workers=%d", max, pool.Workers())
+	}
+
+	stats := pool.Stats()
+	if stats.Active != 0 || stats.Queued != 0 {
+		t.Errorf("pool is not idle after closing the writers: active=%d queued=%d", stats.Active, stats.Queued)
+	}
+	if stats.Completed < numWriters {
+		t.Errorf("too few pages compressed by the pool: %d", stats.Completed)
+	}
+	if stats.Waited == 0 || stats.WaitTime == 0 {
+		t.Errorf("no pages waited for a worker: waited=%d wait-time=%s", stats.Waited, stats.WaitTime)
+	}
+
+	compressed := stats.Completed
+	reader := parquet.NewReader(bytes.NewReader(files[0]), parquet.CompressionWorkers(pool))
+	defer reader.Close()
+
+	for i := 0; i < numRows; i++ {
+		row := Row{}
+		if err := reader.Read(&row); err != nil {
+			t.Fatal(err)
+		}
+		if row.Value != int64(i) {
+			t.Fatalf("row %d mismatch: want=%d got=%d", i, i, row.Value)
+		}
+	}
+
+	if stats := pool.Stats(); stats.Completed == compressed {
+		t.Error("no pages were decompressed by the pool")
+	}
+}
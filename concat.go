@@ -0,0 +1,244 @@
+package parquet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// ConcatFiles writes to output a parquet file holding the row groups of all the
+// files passed as arguments, in order.
+//
+// The column chunks and bloom filters are copied byte-for-byte, without being
+// decoded or re-encoded, which makes the operation bound by I/O throughput.
+// The page index and footer of the output file are rebuilt to account for the
+// new location of the column chunks. The key/value metadata of the files are
+// merged, when a key is present in multiple files the value of the first file
+// that defines it is retained.
+//
+// The files must have the same schema, and must not be encrypted, since the
+// modules of encrypted files are bound to the ordinal of their row group. The
+// function returns an error if these conditions are not met, or if the column
+// chunks of the files are stored in external files.
+func ConcatFiles(output io.Writer, files ...*File) error {
+	if len(files) == 0 {
+		return fmt.Errorf("concatenating parquet files: no files to concatenate")
+	}
+
+	schema := files[0].Schema()
+	for i, f := range files {
+		if f.isEncrypted() {
+			return fmt.Errorf("concatenating parquet files: file %d is encrypted", i)
+		}
+		if !nodesAreEqual(schema, f.Schema()) {
+			return fmt.Errorf("concatenating parquet files: file %d: %w", i, ErrRowGroupSchemaMismatch)
+		}
+	}
+
+	metadata := files[0].metadata
+	metadata.NumRows = 0
+	metadata.RowGroups = nil
+	metadata.KeyValueMetadata = concatKeyValueMetadata(files)
+
+	numColumns := len(schema.Columns())
+	columnIndexes := []format.ColumnIndex{}
+	offsetIndexes := []format.OffsetIndex{}
+
+	buffer := bufio.NewWriterSize(output, DefaultWriteBufferSize)
+	w := offsetTrackingWriter{}
+	w.Reset(buffer)
+
+	if _, err := w.WriteString("PAR1"); err != nil {
+		return err
+	}
+
+	for i, f := range files {
+		rowGroups, err := f.rowGroupsMetadata()
+		if err != nil {
+			return fmt.Errorf("file %d: %w", i, err)
+		}
+		fileColumnIndexes, fileOffsetIndexes, err := f.ReadPageIndex()
+		if err != nil {
+			return fmt.Errorf("file %d: reading page index: %w", i, err)
+		}
+		// Column chunks of files without a page index are given empty
+		// indexes, which are omitted from the output.
+		if fileColumnIndexes == nil {
+			fileColumnIndexes = make([]format.ColumnIndex, len(rowGroups)*numColumns)
+		}
+		if fileOffsetIndexes == nil {
+			fileOffsetIndexes = make([]format.OffsetIndex, len(rowGroups)*numColumns)
+		}
+
+		for j := range rowGroups {
+			rowGroup := rowGroups[j]
+			rowGroup.Columns = append([]format.ColumnChunk{}, rowGroup.Columns...)
+			rowGroup.FileOffset = w.offset
+			rowGroup.Ordinal = int16(len(metadata.RowGroups))
+
+			for k := range rowGroup.Columns {
+				if err := copyColumnChunk(&w, f.reader, &rowGroup.Columns[k], &fileOffsetIndexes[j*numColumns+k]); err != nil {
+					return fmt.Errorf("file %d: copying column chunk %d of row group %d: %w", i, k, j, err)
+				}
+			}
+
+			for k := range rowGroup.Columns {
+				c := &rowGroup.Columns[k]
+				if offset := c.MetaData.BloomFilterOffset; offset > 0 {
+					c.MetaData.BloomFilterOffset = w.offset
+					if err := copyBloomFilter(&w, f.reader, offset); err != nil {
+						return fmt.Errorf("file %d: copying bloom filter of column chunk %d of row group %d: %w", i, k, j, err)
+					}
+				}
+			}
+
+			metadata.NumRows += rowGroup.NumRows
+			metadata.RowGroups = append(metadata.RowGroups, rowGroup)
+		}
+
+		columnIndexes = append(columnIndexes, fileColumnIndexes...)
+		offsetIndexes = append(offsetIndexes, fileOffsetIndexes...)
+	}
+
+	if err := writePageIndexAndFooter(&w, &metadata, columnIndexes, offsetIndexes); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+// isEncrypted returns true if the file was written with modular encryption.
+func (f *File) isEncrypted() bool {
+	algorithm := f.metadata.EncryptionAlgorithm
+	return f.decryption != nil || algorithm.AesGcmV1 != nil || algorithm.AesGcmCtrV1 != nil
+}
+
+// concatKeyValueMetadata merges the key/value metadata of files, retaining the
+// value of the first file for keys that appear in multiple files.
+func concatKeyValueMetadata(files []*File) []format.KeyValue {
+	var keyValueMetadata []format.KeyValue
+	for _, f := range files {
+		for _, kv := range f.metadata.KeyValueMetadata {
+			if _, ok := lookupKeyValueMetadata(keyValueMetadata, kv.Key); !ok {
+				keyValueMetadata = append(keyValueMetadata, kv)
+				sortKeyValueMetadata(keyValueMetadata)
+			}
+		}
+	}
+	return keyValueMetadata
+}
+
+// copyColumnChunk copies the pages of the column chunk c from r to w, and
+// updates the offsets of c and of its offset index to their location in w.
+func copyColumnChunk(w *offsetTrackingWriter, r io.ReaderAt, c *format.ColumnChunk, offsetIndex *format.OffsetIndex) error {
+	if c.FilePath != "" {
+		return fmt.Errorf("column chunk is stored in the external file %q", c.FilePath)
+	}
+
+	offset := c.MetaData.DataPageOffset
+	if c.MetaData.DictionaryPageOffset != 0 && c.MetaData.DictionaryPageOffset < offset {
+		offset = c.MetaData.DictionaryPageOffset
+	}
+	delta := w.offset - offset
+
+	if _, err := io.Copy(w, io.NewSectionReader(r, offset, c.MetaData.TotalCompressedSize)); err != nil {
+		return err
+	}
+
+	c.MetaData.DataPageOffset += delta
+	if c.MetaData.DictionaryPageOffset != 0 {
+		c.MetaData.DictionaryPageOffset += delta
+	}
+	if c.MetaData.IndexPageOffset != 0 {
+		c.MetaData.IndexPageOffset += delta
+	}
+	if c.FileOffset != 0 {
+		c.FileOffset += delta
+	}
+	if offsetIndex != nil {
+		offsetIndex.PageLocations = append([]format.PageLocation{}, offsetIndex.PageLocations...)
+		for i := range offsetIndex.PageLocations {
+			offsetIndex.PageLocations[i].Offset += delta
+		}
+	}
+	return nil
+}
+
+// copyBloomFilter copies the header and bits of the bloom filter at the given
+// offset in r to w.
+func copyBloomFilter(w *offsetTrackingWriter, r io.ReaderAt, offset int64) error {
+	h, headerSize, err := readBloomFilterHeader(r, offset)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, io.NewSectionReader(r, offset, headerSize+int64(h.NumBytes)))
+	return err
+}
+
+// writePageIndexAndFooter writes the page index of the column chunks of the
+// file metadata to w, followed by the footer. The indexes are ordered by row
+// group then column, empty indexes are omitted, and the slices are nil when
+// the file has no page index.
+func writePageIndexAndFooter(w *offsetTrackingWriter, metadata *format.FileMetaData, columnIndexes []format.ColumnIndex, offsetIndexes []format.OffsetIndex) error {
+	protocol := new(thrift.CompactProtocol)
+	encoder := thrift.NewEncoder(protocol.NewWriter(w))
+
+	forEachColumnChunk := func(do func(*format.ColumnChunk, int) error) error {
+		for i := range metadata.RowGroups {
+			columns := metadata.RowGroups[i].Columns
+			for j := range columns {
+				if err := do(&columns[j], i*len(columns)+j); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	err := forEachColumnChunk(func(c *format.ColumnChunk, index int) error {
+		c.ColumnIndexOffset, c.ColumnIndexLength = 0, 0
+		// The column index of column chunks may have been omitted, for
+		// example when a page of floating point values contains only NaN.
+		if columnIndexes != nil && len(columnIndexes[index].NullPages) > 0 {
+			c.ColumnIndexOffset = w.offset
+			if err := encoder.Encode(&columnIndexes[index]); err != nil {
+				return err
+			}
+			c.ColumnIndexLength = int32(w.offset - c.ColumnIndexOffset)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = forEachColumnChunk(func(c *format.ColumnChunk, index int) error {
+		c.OffsetIndexOffset, c.OffsetIndexLength = 0, 0
+		if offsetIndexes != nil && len(offsetIndexes[index].PageLocations) > 0 {
+			c.OffsetIndexOffset = w.offset
+			if err := encoder.Encode(&offsetIndexes[index]); err != nil {
+				return err
+			}
+			c.OffsetIndexLength = int32(w.offset - c.OffsetIndexOffset)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	footer, err := thrift.Marshal(protocol, metadata)
+	if err != nil {
+		return err
+	}
+	length := len(footer)
+	footer = append(footer, 0, 0, 0, 0)
+	footer = append(footer, "PAR1"...)
+	binary.LittleEndian.PutUint32(footer[length:], uint32(length))
+
+	_, err = w.Write(footer)
+	return err
+}
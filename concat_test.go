@@ -0,0 +1,157 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestConcatFiles(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Name  string  `parquet:"name"`
+		Color string  `parquet:"color,dict"`
+		Tags  []int64 `parquet:"tags"`
+	}
+
+	writeFile := func(firstID, numRows, rowsPerRowGroup int, metadata map[string]string) ([]Row, []byte) {
+		rows := make([]Row, numRows)
+		for i := range rows {
+			id := firstID + i
+			rows[i] = Row{
+				ID:    int64(id),
+				Name:  fmt.Sprintf("name-%d", id),
+				Color: fmt.Sprintf("color-%d", id%20),
+			}
+			for j := 0; j < id%3; j++ {
+				rows[i].Tags = append(rows[i].Tags, int64(10*id+j))
+			}
+		}
+
+		options := []parquet.WriterOption{
+			parquet.PageBufferSize(4096),
+			parquet.BloomFilters(parquet.SplitBlockFilter("name")),
+		}
+		for k, v := range metadata {
+			options = append(options, parquet.KeyValueMetadata(k, v))
+		}
+
+		b := new(bytes.Buffer)
+		w := parquet.NewWriter(b, options...)
+		for i := range rows {
+			if err := w.Write(&rows[i]); err != nil {
+				t.Fatal(err)
+			}
+			if i%rowsPerRowGroup == rowsPerRowGroup-1 {
+				if err := w.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return rows, b.Bytes()
+	}
+
+	rows1, data1 := writeFile(0, 2000, 1000, map[string]string{"a": "1", "b": "1"})
+	rows2, data2 := writeFile(2000, 500, 1000, map[string]string{"b": "2", "c": "2"})
+	rows3, data3 := writeFile(2500, 1500, 500, nil)
+	rows := append(append(append([]Row{}, rows1...), rows2...), rows3...)
+
+	files := make([]*parquet.File, 3)
+	for i, data := range [][]byte{data1, data2, data3} {
+		f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.LazyRowGroups(i == 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[i] = f
+	}
+
+	output := new(bytes.Buffer)
+	if err := parquet.ConcatFiles(output, files...); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(output.Bytes()), int64(output.Len()), parquet.VerifyColumnChunks(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.NumRows() != int64(len(rows)) || len(f.RowGroups()) != 6 {
+		t.Fatalf("wrong number of rows or row groups: rows=%d row groups=%d", f.NumRows(), len(f.RowGroups()))
+	}
+
+	for key, want := range map[string]string{"a": "1", "b": "1", "c": "2"} {
+		if got, ok := f.Lookup(key); !ok || got != want {
+			t.Errorf("wrong value for metadata key %q: want=%q got=%q (found=%t)", key, want, got, ok)
+		}
+	}
+
+	r := parquet.NewReader(f)
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatalf("reading row %d: %v", i, err)
+		}
+		if len(got.Tags) == 0 {
+			got.Tags = nil
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+
+	firstRowIndex := int64(0)
+	for i, rowGroup := range f.RowGroups() {
+		// Seeking uses the offset index, which must point to the new location
+		// of the pages.
+		rowIndex := rowGroup.NumRows() / 2
+		rowGroupRows := rowGroup.Rows()
+		if err := rowGroupRows.SeekToRow(rowIndex); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]parquet.Row, 1)
+		if _, err := rowGroupRows.ReadRows(buf); err != nil {
+			t.Fatal(err)
+		}
+		rowGroupRows.Close()
+		if id, want := buf[0][0].Int64(), rows[firstRowIndex+rowIndex].ID; id != want {
+			t.Errorf("row group %d: wrong row after seeking to %d: want=%d got=%d", i, rowIndex, want, id)
+		}
+
+		chunk, _ := parquet.LookupColumnChunk(rowGroup, "id")
+		if index := chunk.ColumnIndex(); index == nil || index.NumPages() == 0 {
+			t.Errorf("row group %d: missing column index", i)
+		} else if min := index.MinValue(0).Int64(); min != rows[firstRowIndex].ID {
+			t.Errorf("row group %d: wrong min value in column index: want=%d got=%d", i, rows[firstRowIndex].ID, min)
+		}
+
+		chunk, _ = parquet.LookupColumnChunk(rowGroup, "name")
+		name := rows[firstRowIndex].Name
+		if ok, err := parquet.MayContain(chunk, parquet.ValueOf(name)); err != nil || !ok {
+			t.Errorf("row group %d: value %q not found in the bloom filter: %v", i, name, err)
+		}
+		firstRowIndex += rowGroup.NumRows()
+	}
+
+	type Other struct {
+		ID int64 `parquet:"id"`
+	}
+	b := new(bytes.Buffer)
+	if err := writeParquetFile(b, makeRows([]Other{{ID: 1}})); err != nil {
+		t.Fatal(err)
+	}
+	other, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.ConcatFiles(new(bytes.Buffer), files[0], other); err == nil {
+		t.Error("no error concatenating files with different schemas")
+	}
+	if err := parquet.ConcatFiles(new(bytes.Buffer)); err == nil {
+		t.Error("no error concatenating an empty list of files")
+	}
+}
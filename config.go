@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/segmentio/parquet-go/compress"
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/hyperloglog"
 )
 
 const (
@@ -17,6 +19,13 @@ const (
 	DefaultDataPageStatistics   = false
 	DefaultSkipPageIndex        = false
 	DefaultSkipBloomFilters     = false
+	DefaultVerifyColumnChunks   = false
+	DefaultLazyRowGroups        = false
+	DefaultValidateUTF8         = false
+	DefaultAdaptiveEncoding     = false
+	DefaultReadCacheBlockSize   = 256 * 1024
+	DefaultWideningCasts        = false
+	DefaultNarrowingCasts       = false
 )
 
 // The FileConfig type carries configuration options for parquet files.
@@ -30,16 +39,34 @@ const (
 //	})
 //
 type FileConfig struct {
-	SkipPageIndex    bool
-	SkipBloomFilters bool
+	SkipPageIndex        bool
+	SkipBloomFilters     bool
+	VerifyColumnChunks   bool
+	LazyRowGroups        bool
+	ValidateUTF8         bool
+	OnPageError          func(*PageError)
+	Metrics              ReaderMetrics
+	MemoryLimiter        *MemoryLimiter
+	CompressionPool      *CompressionPool
+	ReadCache            PageCache
+	ReadCacheName        string
+	ReadCacheBlockSize   int
+	StreamingPageSize    int
+	BloomFilterCacheSize int
+	ZstdDictionaries     map[string][]byte
+	Decryption           *DecryptionConfig
 }
 
 // DefaultFileConfig returns a new FileConfig value initialized with the
 // default file configuration.
 func DefaultFileConfig() *FileConfig {
 	return &FileConfig{
-		SkipPageIndex:    DefaultSkipPageIndex,
-		SkipBloomFilters: DefaultSkipBloomFilters,
+		SkipPageIndex:      DefaultSkipPageIndex,
+		SkipBloomFilters:   DefaultSkipBloomFilters,
+		VerifyColumnChunks: DefaultVerifyColumnChunks,
+		LazyRowGroups:      DefaultLazyRowGroups,
+		ValidateUTF8:       DefaultValidateUTF8,
+		ReadCacheBlockSize: DefaultReadCacheBlockSize,
 	}
 }
 
@@ -64,14 +91,34 @@ func (c *FileConfig) Apply(options ...FileOption) {
 // ConfigureFile applies configuration options from c to config.
 func (c *FileConfig) ConfigureFile(config *FileConfig) {
 	*config = FileConfig{
-		SkipPageIndex:    config.SkipPageIndex,
-		SkipBloomFilters: config.SkipBloomFilters,
+		SkipPageIndex:        config.SkipPageIndex,
+		SkipBloomFilters:     config.SkipBloomFilters,
+		VerifyColumnChunks:   config.VerifyColumnChunks,
+		LazyRowGroups:        config.LazyRowGroups,
+		ValidateUTF8:         config.ValidateUTF8,
+		OnPageError:          coalescePageErrorHandler(c.OnPageError, config.OnPageError),
+		Metrics:              coalesceReaderMetrics(c.Metrics, config.Metrics),
+		MemoryLimiter:        coalesceMemoryLimiter(c.MemoryLimiter, config.MemoryLimiter),
+		CompressionPool:      coalesceCompressionPool(c.CompressionPool, config.CompressionPool),
+		ReadCache:            coalescePageCache(c.ReadCache, config.ReadCache),
+		ReadCacheName:        coalesceString(c.ReadCacheName, config.ReadCacheName),
+		ReadCacheBlockSize:   coalesceInt(c.ReadCacheBlockSize, config.ReadCacheBlockSize),
+		StreamingPageSize:    coalesceInt(c.StreamingPageSize, config.StreamingPageSize),
+		BloomFilterCacheSize: coalesceInt(c.BloomFilterCacheSize, config.BloomFilterCacheSize),
+		ZstdDictionaries:     mergeZstdDictionaries(c.ZstdDictionaries, config.ZstdDictionaries),
+		Decryption:           coalesceDecryption(c.Decryption, config.Decryption),
 	}
 }
 
 // Validate returns a non-nil error if the configuration of c is invalid.
 func (c *FileConfig) Validate() error {
-	return nil
+	const baseName = "parquet.(*FileConfig)."
+	return errorInvalidConfiguration(
+		validatePositiveInt(baseName+"ReadCacheBlockSize", c.ReadCacheBlockSize),
+		validateNonNegativeInt(baseName+"StreamingPageSize", c.StreamingPageSize),
+		validateNonNegativeInt(baseName+"BloomFilterCacheSize", c.BloomFilterCacheSize),
+		c.Decryption.Validate(),
+	)
 }
 
 // The ReaderConfig type carries configuration options for parquet readers.
@@ -84,7 +131,18 @@ func (c *FileConfig) Validate() error {
 //	})
 //
 type ReaderConfig struct {
-	Schema *Schema
+	Schema            *Schema
+	Metrics           ReaderMetrics
+	MemoryLimiter     *MemoryLimiter
+	CompressionPool   *CompressionPool
+	Int96Timestamps   bool
+	WideningCasts     bool
+	NarrowingCasts    bool
+	DeletedRows       DeleteMask
+	ValidateUTF8      bool
+	StreamingPageSize int
+	ZstdDictionaries  map[string][]byte
+	Decryption        *DecryptionConfig
 }
 
 // DefaultReaderConfig returns a new ReaderConfig value initialized with the
@@ -114,13 +172,68 @@ func (c *ReaderConfig) Apply(options ...ReaderOption) {
 // ConfigureReader applies configuration options from c to config.
 func (c *ReaderConfig) ConfigureReader(config *ReaderConfig) {
 	*config = ReaderConfig{
-		Schema: coalesceSchema(c.Schema, config.Schema),
+		Schema:            coalesceSchema(c.Schema, config.Schema),
+		Metrics:           coalesceReaderMetrics(c.Metrics, config.Metrics),
+		MemoryLimiter:     coalesceMemoryLimiter(c.MemoryLimiter, config.MemoryLimiter),
+		CompressionPool:   coalesceCompressionPool(c.CompressionPool, config.CompressionPool),
+		Int96Timestamps:   c.Int96Timestamps || config.Int96Timestamps,
+		WideningCasts:     c.WideningCasts || config.WideningCasts,
+		NarrowingCasts:    c.NarrowingCasts || config.NarrowingCasts,
+		DeletedRows:       coalesceDeleteMask(c.DeletedRows, config.DeletedRows),
+		ValidateUTF8:      c.ValidateUTF8 || config.ValidateUTF8,
+		StreamingPageSize: coalesceInt(c.StreamingPageSize, config.StreamingPageSize),
+		ZstdDictionaries:  mergeZstdDictionaries(c.ZstdDictionaries, config.ZstdDictionaries),
+		Decryption:        coalesceDecryption(c.Decryption, config.Decryption),
 	}
 }
 
 // Validate returns a non-nil error if the configuration of c is invalid.
 func (c *ReaderConfig) Validate() error {
-	return nil
+	const baseName = "parquet.(*ReaderConfig)."
+	return errorInvalidConfiguration(
+		validateNonNegativeInt(baseName+"StreamingPageSize", c.StreamingPageSize),
+		c.Decryption.Validate(),
+	)
+}
+
+// fileOptions returns the list of file options to apply when a reader opens
+// the parquet file itself.
+func (c *ReaderConfig) fileOptions() []FileOption {
+	var options []FileOption
+	if c.Metrics != nil {
+		options = append(options, Metrics(c.Metrics))
+	}
+	if c.MemoryLimiter != nil {
+		options = append(options, MemoryLimit(c.MemoryLimiter))
+	}
+	if c.CompressionPool != nil {
+		options = append(options, CompressionWorkers(c.CompressionPool))
+	}
+	if c.ValidateUTF8 {
+		options = append(options, ValidateUTF8(true))
+	}
+	if c.StreamingPageSize > 0 {
+		options = append(options, StreamingPageSize(c.StreamingPageSize))
+	}
+	if len(c.ZstdDictionaries) > 0 {
+		dicts := c.ZstdDictionaries
+		options = append(options, fileOption(func(config *FileConfig) {
+			config.ZstdDictionaries = mergeZstdDictionaries(dicts, config.ZstdDictionaries)
+		}))
+	}
+	if c.Decryption != nil {
+		options = append(options, Decryption(c.Decryption))
+	}
+	return options
+}
+
+// convertConfig returns the configuration of schema conversions applied by
+// readers to convert the rows of the files they read.
+func (c *ReaderConfig) convertConfig() *ConvertConfig {
+	return &ConvertConfig{
+		WideningCasts:  c.WideningCasts,
+		NarrowingCasts: c.NarrowingCasts,
+	}
 }
 
 // The WriterConfig type carries configuration options for parquet writers.
@@ -133,18 +246,30 @@ func (c *ReaderConfig) Validate() error {
 //	})
 //
 type WriterConfig struct {
-	CreatedBy            string
-	ColumnPageBuffers    PageBufferPool
-	ColumnIndexSizeLimit int
-	PageBufferSize       int
-	WriteBufferSize      int
-	DataPageVersion      int
-	DataPageStatistics   bool
-	KeyValueMetadata     map[string]string
-	Schema               *Schema
-	SortingColumns       []SortingColumn
-	BloomFilters         []BloomFilterColumn
-	Compression          compress.Codec
+	CreatedBy              string
+	ColumnPageBuffers      PageBufferPool
+	ColumnIndexSizeLimit   int
+	PageBufferSize         int
+	WriteBufferSize        int
+	DataPageVersion        int
+	DataPageStatistics     bool
+	KeyValueMetadata       map[string]string
+	Schema                 *Schema
+	SortingColumns         []SortingColumn
+	BloomFilters           []BloomFilterColumn
+	BloomFilterConcurrency int
+	BloomFilterPlacement   BloomFilterPlacement
+	DistinctCountPrecision int
+	ColumnAggregators      []AggregatorColumn
+	Encryption             *EncryptionConfig
+	Compression            compress.Codec
+	CompressionPool        *CompressionPool
+	CompressionThreshold   float64
+	ValidateUTF8           bool
+	AdaptiveEncoding       bool
+	PreferredEncodings     map[Kind][]encoding.Encoding
+	ZstdDictionaries       map[string][]byte
+	OnRowGroupFlush        func(*RowGroupStats)
 }
 
 // DefaultWriterConfig returns a new WriterConfig value initialized with the
@@ -158,6 +283,8 @@ func DefaultWriterConfig() *WriterConfig {
 		WriteBufferSize:      DefaultWriteBufferSize,
 		DataPageVersion:      DefaultDataPageVersion,
 		DataPageStatistics:   DefaultDataPageStatistics,
+		ValidateUTF8:         DefaultValidateUTF8,
+		AdaptiveEncoding:     DefaultAdaptiveEncoding,
 	}
 }
 
@@ -190,19 +317,40 @@ func (c *WriterConfig) ConfigureWriter(config *WriterConfig) {
 			keyValueMetadata[k] = v
 		}
 	}
+	preferredEncodings := config.PreferredEncodings
+	if len(c.PreferredEncodings) > 0 {
+		if preferredEncodings == nil {
+			preferredEncodings = make(map[Kind][]encoding.Encoding, len(c.PreferredEncodings))
+		}
+		for k, v := range c.PreferredEncodings {
+			preferredEncodings[k] = v
+		}
+	}
 	*config = WriterConfig{
-		CreatedBy:            coalesceString(c.CreatedBy, config.CreatedBy),
-		ColumnPageBuffers:    coalescePageBufferPool(c.ColumnPageBuffers, config.ColumnPageBuffers),
-		ColumnIndexSizeLimit: coalesceInt(c.ColumnIndexSizeLimit, config.ColumnIndexSizeLimit),
-		PageBufferSize:       coalesceInt(c.PageBufferSize, config.PageBufferSize),
-		WriteBufferSize:      coalesceInt(c.WriteBufferSize, config.WriteBufferSize),
-		DataPageVersion:      coalesceInt(c.DataPageVersion, config.DataPageVersion),
-		DataPageStatistics:   config.DataPageStatistics,
-		KeyValueMetadata:     keyValueMetadata,
-		Schema:               coalesceSchema(c.Schema, config.Schema),
-		SortingColumns:       coalesceSortingColumns(c.SortingColumns, config.SortingColumns),
-		BloomFilters:         coalesceBloomFilters(c.BloomFilters, config.BloomFilters),
-		Compression:          coalesceCompression(c.Compression, config.Compression),
+		CreatedBy:              coalesceString(c.CreatedBy, config.CreatedBy),
+		ColumnPageBuffers:      coalescePageBufferPool(c.ColumnPageBuffers, config.ColumnPageBuffers),
+		ColumnIndexSizeLimit:   coalesceInt(c.ColumnIndexSizeLimit, config.ColumnIndexSizeLimit),
+		PageBufferSize:         coalesceInt(c.PageBufferSize, config.PageBufferSize),
+		WriteBufferSize:        coalesceInt(c.WriteBufferSize, config.WriteBufferSize),
+		DataPageVersion:        coalesceInt(c.DataPageVersion, config.DataPageVersion),
+		DataPageStatistics:     config.DataPageStatistics,
+		KeyValueMetadata:       keyValueMetadata,
+		Schema:                 coalesceSchema(c.Schema, config.Schema),
+		SortingColumns:         coalesceSortingColumns(c.SortingColumns, config.SortingColumns),
+		BloomFilters:           coalesceBloomFilters(c.BloomFilters, config.BloomFilters),
+		BloomFilterConcurrency: coalesceInt(c.BloomFilterConcurrency, config.BloomFilterConcurrency),
+		BloomFilterPlacement:   coalesceBloomFilterPlacement(c.BloomFilterPlacement, config.BloomFilterPlacement),
+		DistinctCountPrecision: coalesceInt(c.DistinctCountPrecision, config.DistinctCountPrecision),
+		ColumnAggregators:      coalesceColumnAggregators(c.ColumnAggregators, config.ColumnAggregators),
+		Encryption:             coalesceEncryption(c.Encryption, config.Encryption),
+		Compression:            coalesceCompression(c.Compression, config.Compression),
+		CompressionPool:        coalesceCompressionPool(c.CompressionPool, config.CompressionPool),
+		CompressionThreshold:   coalesceFloat64(c.CompressionThreshold, config.CompressionThreshold),
+		ValidateUTF8:           config.ValidateUTF8,
+		AdaptiveEncoding:       config.AdaptiveEncoding,
+		PreferredEncodings:     preferredEncodings,
+		ZstdDictionaries:       mergeZstdDictionaries(c.ZstdDictionaries, config.ZstdDictionaries),
+		OnRowGroupFlush:        coalesceRowGroupFlushHandler(c.OnRowGroupFlush, config.OnRowGroupFlush),
 	}
 }
 
@@ -214,6 +362,15 @@ func (c *WriterConfig) Validate() error {
 		validatePositiveInt(baseName+"ColumnIndexSizeLimit", c.ColumnIndexSizeLimit),
 		validatePositiveInt(baseName+"PageBufferSize", c.PageBufferSize),
 		validateOneOfInt(baseName+"DataPageVersion", c.DataPageVersion, 1, 2),
+		validateNonNegativeInt(baseName+"BloomFilterConcurrency", c.BloomFilterConcurrency),
+		validateOneOfInt(baseName+"BloomFilterPlacement", int(c.BloomFilterPlacement),
+			int(BloomFiltersBeforeRowGroup),
+			int(BloomFiltersAfterRowGroup),
+			int(BloomFiltersBeforeFooter),
+		),
+		validateDistinctCountPrecision(baseName+"DistinctCountPrecision", c.DistinctCountPrecision),
+		validateNonNegativeFloat64(baseName+"CompressionThreshold", c.CompressionThreshold),
+		c.Encryption.Validate(),
 	)
 }
 
@@ -230,6 +387,9 @@ type RowGroupConfig struct {
 	ColumnBufferCapacity int
 	SortingColumns       []SortingColumn
 	Schema               *Schema
+	Allocator            Allocator
+	DuplicateRows        DuplicateRowPolicy
+	MergeConcurrency     int
 }
 
 // DefaultRowGroupConfig returns a new RowGroupConfig value initialized with the
@@ -256,6 +416,7 @@ func (c *RowGroupConfig) Validate() error {
 	const baseName = "parquet.(*RowGroupConfig)."
 	return errorInvalidConfiguration(
 		validatePositiveInt(baseName+"ColumnBufferCapacity", c.ColumnBufferCapacity),
+		validateNonNegativeInt(baseName+"MergeConcurrency", c.MergeConcurrency),
 	)
 }
 
@@ -270,9 +431,66 @@ func (c *RowGroupConfig) ConfigureRowGroup(config *RowGroupConfig) {
 		ColumnBufferCapacity: coalesceInt(c.ColumnBufferCapacity, config.ColumnBufferCapacity),
 		SortingColumns:       coalesceSortingColumns(c.SortingColumns, config.SortingColumns),
 		Schema:               coalesceSchema(c.Schema, config.Schema),
+		Allocator:            coalesceAllocator(c.Allocator, config.Allocator),
+		DuplicateRows:        coalesceDuplicateRowPolicy(c.DuplicateRows, config.DuplicateRows),
+		MergeConcurrency:     coalesceInt(c.MergeConcurrency, config.MergeConcurrency),
+	}
+}
+
+// The ConvertConfig type carries configuration options for schema conversions.
+//
+// ConvertConfig implements the ConvertOption interface so it can be used
+// directly as argument to the Convert function when needed, for example:
+//
+//	conv, err := parquet.Convert(to, from, &parquet.ConvertConfig{
+//		WideningCasts: true,
+//	})
+//
+type ConvertConfig struct {
+	WideningCasts  bool
+	NarrowingCasts bool
+}
+
+// DefaultConvertConfig returns a new ConvertConfig value initialized with the
+// default conversion configuration.
+func DefaultConvertConfig() *ConvertConfig {
+	return &ConvertConfig{
+		WideningCasts:  DefaultWideningCasts,
+		NarrowingCasts: DefaultNarrowingCasts,
+	}
+}
+
+// NewConvertConfig constructs a new conversion configuration applying the
+// options passed as arguments.
+//
+// The function returns an non-nil error if some of the options carried invalid
+// configuration values.
+func NewConvertConfig(options ...ConvertOption) (*ConvertConfig, error) {
+	config := DefaultConvertConfig()
+	config.Apply(options...)
+	return config, config.Validate()
+}
+
+// Apply applies the given list of options to c.
+func (c *ConvertConfig) Apply(options ...ConvertOption) {
+	for _, opt := range options {
+		opt.ConfigureConvert(c)
+	}
+}
+
+// ConfigureConvert applies configuration options from c to config.
+func (c *ConvertConfig) ConfigureConvert(config *ConvertConfig) {
+	*config = ConvertConfig{
+		WideningCasts:  c.WideningCasts || config.WideningCasts,
+		NarrowingCasts: c.NarrowingCasts || config.NarrowingCasts,
 	}
 }
 
+// Validate returns a non-nil error if the configuration of c is invalid.
+func (c *ConvertConfig) Validate() error {
+	return nil
+}
+
 // FileOption is an interface implemented by types that carry configuration
 // options for parquet files.
 type FileOption interface {
@@ -297,10 +515,17 @@ type RowGroupOption interface {
 	ConfigureRowGroup(*RowGroupConfig)
 }
 
+// ConvertOption is an interface implemented by types that carry configuration
+// options for schema conversions.
+type ConvertOption interface {
+	ConfigureConvert(*ConvertConfig)
+}
+
 // SkipPageIndex is a file configuration option which prevents automatically
 // reading the page index when opening a parquet file, when set to true. This is
 // useful as an optimization when programs know that they will not need to
-// consume the page index.
+// consume the page index, or only need the index of a few columns, which they
+// can read with ReadColumnIndex and ReadOffsetIndex.
 //
 // Defaults to false.
 func SkipPageIndex(skip bool) FileOption {
@@ -317,6 +542,125 @@ func SkipBloomFilters(skip bool) FileOption {
 	return fileOption(func(config *FileConfig) { config.SkipBloomFilters = skip })
 }
 
+// BloomFilterCacheSize is a file configuration option which limits the memory
+// used to retain the bloom filters of the file's column chunks.
+//
+// Only the headers of bloom filters are read when opening a file, their bits
+// are read the first time they are checked, and retained in memory for the
+// following checks. When the total size of the filters loaded exceeds the
+// limit, the least recently used ones are released, and read again if they are
+// checked after that.
+//
+// Defaults to zero, which retains all the bloom filters that were loaded.
+func BloomFilterCacheSize(size int) FileOption {
+	return fileOption(func(config *FileConfig) { config.BloomFilterCacheSize = size })
+}
+
+// VerifyColumnChunks is a file configuration option which enables verifying
+// that the offsets and sizes recorded in the file metadata are consistent with
+// the layout of the file, when set to true.
+//
+// When enabled, the column chunks and offset indexes are verified when opening
+// the file, and the position and size of each page are verified against the
+// metadata while reading. Inconsistencies are reported with errors wrapping
+// ErrInconsistentMetadata which describe the column and page where they were
+// detected, instead of the less specific decoding errors that would otherwise
+// occur later on.
+//
+// Defaults to false.
+func VerifyColumnChunks(verify bool) FileOption {
+	return fileOption(func(config *FileConfig) { config.VerifyColumnChunks = verify })
+}
+
+// LazyRowGroups is a file configuration option which defers decoding the
+// metadata of row groups until they are accessed, when set to true.
+//
+// Opening a file only decodes the metadata of its first row group, which is
+// needed to resolve the encoding and compression of columns. The other row
+// groups, along with their page index and bloom filters, are decoded the first
+// time they are retrieved with the File.RowGroup method. This reduces the cost
+// of opening files with many row groups when programs only access a few.
+//
+// When enabled, the ColumnIndexes and OffsetIndexes methods of File return nil,
+// the page index is accessed through the column chunks instead. Calling the
+// File.RowGroups method decodes the metadata of all row groups.
+//
+// Defaults to false.
+func LazyRowGroups(enabled bool) FileOption {
+	return fileOption(func(config *FileConfig) { config.LazyRowGroups = enabled })
+}
+
+// OnPageError is a file configuration option which enables a tolerant read mode
+// where errors encountered while reading, decompressing, or decoding pages are
+// reported to the given handler instead of aborting the read. When the handler
+// is called, the page is skipped and reading continues with the next page of
+// the column chunk.
+//
+// Skipping pages means that the values of the affected rows are missing from
+// the column, which may cause columns of a row group to become misaligned when
+// reading rows. The PageError passed to the handler carries the range of rows
+// covered by the corrupted page, which applications can use to discard the
+// rows that were affected.
+//
+// When the handler is nil, page errors are returned to the application, which
+// is the default behavior.
+func OnPageError(handler func(*PageError)) FileOption {
+	return fileOption(func(config *FileConfig) { config.OnPageError = handler })
+}
+
+// Int96Timestamps is a reader configuration option which enables decoding
+// legacy INT96 timestamp columns (as written by Spark or Impala) to TIMESTAMP
+// columns of nanosecond precision, instead of exposing the raw 12 bytes values.
+//
+// The option only applies when the reader uses the schema of the file; when an
+// explicit schema is given, INT96 columns are converted if the corresponding
+// column of the schema is a TIMESTAMP (e.g. a time.Time struct field).
+//
+// Defaults to false.
+func Int96Timestamps(enabled bool) ReaderOption {
+	return readerOption(func(config *ReaderConfig) { config.Int96Timestamps = enabled })
+}
+
+// WideningCasts is a configuration option which allows schema conversions to
+// cast columns to types able to represent a wider range of values:
+//
+//	INT32             -> INT64
+//	FLOAT             -> DOUBLE
+//	TIMESTAMP(MILLIS) -> TIMESTAMP(MICROS) -> TIMESTAMP(NANOS)
+//	TIME(MILLIS)      -> TIME(MICROS)      -> TIME(NANOS)
+//
+// The option can be passed to Convert, or to readers which convert rows of
+// the files they read to the schema of the application.
+//
+// Defaults to false.
+func WideningCasts(enabled bool) interface {
+	ConvertOption
+	ReaderOption
+} {
+	return castOption(func(config *ConvertConfig) { config.WideningCasts = enabled })
+}
+
+// NarrowingCasts is a configuration option which allows schema conversions to
+// cast columns to types representing a narrower range of values, which may
+// lose precision:
+//
+//	INT64            -> INT32
+//	DOUBLE           -> FLOAT
+//	TIMESTAMP(NANOS) -> TIMESTAMP(MICROS) -> TIMESTAMP(MILLIS)
+//	TIME(NANOS)      -> TIME(MICROS)      -> TIME(MILLIS)
+//
+// Integer values which cannot be represented in the target type cause the
+// conversion to fail with an error, floating point values are rounded and
+// timestamps are truncated to the precision of the target type.
+//
+// Defaults to false.
+func NarrowingCasts(enabled bool) interface {
+	ConvertOption
+	ReaderOption
+} {
+	return castOption(func(config *ConvertConfig) { config.NarrowingCasts = enabled })
+}
+
 // PageBufferSize configures the size of column page buffers on parquet writers.
 //
 // Note that the page buffer size refers to the in-memory buffers where pages
@@ -359,7 +703,9 @@ func ColumnPageBuffers(buffers PageBufferPool) WriterOption {
 }
 
 // ColumnIndexSizeLimit creates a configuration option to customize the size
-// limit of page boundaries recorded in column indexes.
+// limit of page boundaries recorded in column indexes, and in data page
+// statistics when they are enabled. Truncated statistics are recorded as not
+// being exact.
 //
 // Defaults to 16.
 func ColumnIndexSizeLimit(sizeLimit int) WriterOption {
@@ -384,6 +730,54 @@ func DataPageStatistics(enabled bool) WriterOption {
 	return writerOption(func(config *WriterConfig) { config.DataPageStatistics = enabled })
 }
 
+// AdaptiveEncoding creates a configuration option which enables selecting the
+// encoding of each data page based on the size of the encoded values, instead
+// of using the default encoding of the column type.
+//
+// The option applies to columns which have no explicit encoding in the schema.
+// The values of each page are encoded with the candidate encodings supported
+// by the column type (PLAIN, DELTA_BINARY_PACKED, DELTA_LENGTH_BYTE_ARRAY,
+// DELTA_BYTE_ARRAY, or BYTE_STREAM_SPLIT), and the encoding producing the
+// smallest output is retained. The first page of each column chunk is also
+// used to estimate the size of a dictionary; when dictionary encoding would be
+// smaller, the column chunk is dictionary encoded.
+//
+// Selecting the encodings adds CPU overhead to writing pages since each page
+// is encoded multiple times.
+//
+// Defaults to false.
+func AdaptiveEncoding(enabled bool) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.AdaptiveEncoding = enabled })
+}
+
+// PreferredEncodings creates a configuration option which sets the ordered list
+// of encodings that writers use for columns of the given kind.
+//
+// The option applies to columns which have no explicit encoding in the schema.
+// Encodings of the list which do not support the kind are ignored, the columns
+// use the first remaining encoding. If an encoding fails to encode a page, the
+// writer falls back to the next encodings of the list, and to the default
+// encoding of the kind when none of them succeeded. When combined with the
+// AdaptiveEncoding option, the list defines the candidate encodings.
+//
+// For example, this option can be used to prefer DELTA encodings for all
+// integer columns:
+//
+//	writer := parquet.NewWriter(output,
+//		parquet.PreferredEncodings(parquet.Int32, &parquet.DeltaBinaryPacked),
+//		parquet.PreferredEncodings(parquet.Int64, &parquet.DeltaBinaryPacked),
+//	)
+func PreferredEncodings(kind Kind, encodings ...encoding.Encoding) WriterOption {
+	encodings = append([]encoding.Encoding{}, encodings...)
+	return writerOption(func(config *WriterConfig) {
+		if config.PreferredEncodings == nil {
+			config.PreferredEncodings = map[Kind][]encoding.Encoding{kind: encodings}
+		} else {
+			config.PreferredEncodings[kind] = encodings
+		}
+	})
+}
+
 // KeyValueMetadata creates a configuration option which adds key/value metadata
 // to add to the metadata of parquet files.
 //
@@ -414,17 +808,149 @@ func KeyValueMetadata(key, value string) WriterOption {
 // of a parquet schema can be significant, so by default no filters are created
 // and applications need to explicitly declare the columns that they want to
 // create filters for.
+//
+// The filters are configured with the full path of leaf columns, including the
+// names of the groups they are nested in; for example "items", "list",
+// "element", "sku" for a field of the elements of a LIST. Writers panic if the
+// paths do not name leaf columns of their schema.
 func BloomFilters(filters ...BloomFilterColumn) WriterOption {
 	filters = append([]BloomFilterColumn{}, filters...)
 	return writerOption(func(config *WriterConfig) { config.BloomFilters = filters })
 }
 
+// BloomFilterConcurrency creates a configuration option which sets the number
+// of goroutines used to build the bloom filters of column chunks.
+//
+// Writers build bloom filters when flushing row groups, from the dictionaries
+// of dictionary encoded columns or from the pages buffered for the other
+// columns. With a concurrency greater than one, the values are hashed in
+// batches distributed across goroutines, then inserted in disjoint ranges of
+// blocks of the filters, which reduces the latency of flushing row groups of
+// high-throughput writers.
+//
+// Defaults to zero, which builds the filters in the goroutine writing rows.
+func BloomFilterConcurrency(concurrency int) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.BloomFilterConcurrency = concurrency })
+}
+
+// BloomFilterLocation creates a configuration option which sets where writers
+// place the bloom filters of column chunks in the files they produce.
+//
+// The offsets of the filters are recorded in the column chunk metadata for all
+// placements. With BloomFiltersBeforeFooter, the filters of all row groups are
+// retained in memory until the writer is closed.
+//
+// Defaults to BloomFiltersBeforeRowGroup.
+func BloomFilterLocation(placement BloomFilterPlacement) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.BloomFilterPlacement = placement })
+}
+
+// DistinctCountSketches creates a configuration option which enables the
+// generation of HyperLogLog sketches estimating the number of distinct values of
+// column chunks, with the given precision.
+//
+// The sketches are stored in the key/value metadata of the files, they can be
+// read with DistinctCountSketchOf and merged across row groups to estimate the
+// cardinality of columns without scanning their values. A sketch of precision p
+// occupies 2^p bytes before base64 encoding, for each column of each row group;
+// precisions of 10 to 12 give a relative error of 3.3 to 1.6 percent while
+// keeping the file metadata small.
+//
+// When enabled, the writer also records the exact number of distinct values in
+// the statistics of column chunks where all data pages are dictionary encoded.
+//
+// Defaults to zero, which disables the sketches.
+func DistinctCountSketches(precision int) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.DistinctCountPrecision = precision })
+}
+
+// ColumnAggregators creates a configuration option which defines the custom
+// aggregators that parquet writers pass the values of columns to.
+//
+// Aggregators observe the values of the data pages written to their column,
+// and contribute key/value metadata to the column chunks of each row group and
+// to the file footer, for example:
+//
+//	parquet.ColumnAggregators(
+//		parquet.Aggregate(newMaxLengthAggregator, "name"),
+//	)
+//
+// As with bloom filters, the aggregators are configured with the full path of
+// leaf columns. Writers panic if the paths do not name leaf columns of their
+// schema.
+func ColumnAggregators(aggregators ...AggregatorColumn) WriterOption {
+	aggregators = append([]AggregatorColumn{}, aggregators...)
+	return writerOption(func(config *WriterConfig) { config.ColumnAggregators = aggregators })
+}
+
+// Encryption creates a configuration option which enables parquet modular
+// encryption of the files produced by writers, for example:
+//
+//	parquet.Encryption(&parquet.EncryptionConfig{
+//		FooterKey:         footerKey,
+//		FooterKeyMetadata: []byte("footer-key-id"),
+//		ColumnKeys: map[string]parquet.ColumnEncryptionKey{
+//			"ssn": {Key: ssnKey, KeyMetadata: []byte("ssn-key-id")},
+//		},
+//	})
+//
+// Encrypted files have an encrypted footer by default, readers need the footer
+// key to open them, see Decryption. Files written with a plaintext footer can be
+// opened by readers without keys, see EncryptionConfig.PlaintextFooter. Writers
+// panic if column keys are configured for paths which do not name leaf columns
+// of their schema.
+func Encryption(config *EncryptionConfig) WriterOption {
+	return writerOption(func(c *WriterConfig) { c.Encryption = config })
+}
+
 // Compression creates a configuration option which sets the default compression
 // codec used by a writer for columns where none were defined.
+//
+// The codecs of the compress sub-packages expose their tuning parameters as
+// fields, which allows programs to trade CPU for compression ratio, for example:
+//
+//	writer := parquet.NewWriter(output, parquet.Compression(&zstd.Codec{
+//		Level:      zstd.SpeedBetterCompression,
+//		WindowSize: 1 << 20,
+//	}))
+//
+// Codecs should be shared by writers since they retain compression state which
+// is reused across pages.
 func Compression(codec compress.Codec) WriterOption {
 	return writerOption(func(config *WriterConfig) { config.Compression = codec })
 }
 
+// CompressionThreshold creates a configuration option which makes writers store
+// data pages uncompressed when compression does not reduce their size enough.
+// A page is written uncompressed if its compressed size is greater than the
+// uncompressed size multiplied by the threshold; for example, a threshold of
+// 0.9 only keeps pages which compression made at least 10% smaller. Storing
+// these pages uncompressed saves space and the CPU time spent decompressing
+// them when reading the file.
+//
+// The option only applies to data pages in version 2, which record in their
+// header whether they are compressed; data pages in version 1 and dictionary
+// pages are always compressed with the codec of the column chunk.
+//
+// Defaults to zero, which disables the fallback.
+func CompressionThreshold(threshold float64) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.CompressionThreshold = threshold })
+}
+
+// OnRowGroupFlush creates a configuration option which installs a handler
+// called after each row group is written to the file, with statistics about
+// the compression of its column chunks.
+//
+// The compressed and uncompressed sizes of each column chunk, and the codec and
+// encodings they were written with, help identify the columns that would
+// benefit from a different codec or encoding.
+//
+// The handler is called synchronously by the writer, which does not retain the
+// statistics passed to it so the handler may keep them.
+func OnRowGroupFlush(handler func(*RowGroupStats)) WriterOption {
+	return writerOption(func(config *WriterConfig) { config.OnRowGroupFlush = handler })
+}
+
 // ColumnBufferCapacity creates a configuration option which defines the size of
 // row group column buffers.
 //
@@ -469,6 +995,16 @@ type readerOption func(*ReaderConfig)
 
 func (opt readerOption) ConfigureReader(config *ReaderConfig) { opt(config) }
 
+type castOption func(*ConvertConfig)
+
+func (opt castOption) ConfigureConvert(config *ConvertConfig) { opt(config) }
+
+func (opt castOption) ConfigureReader(config *ReaderConfig) {
+	c := config.convertConfig()
+	opt(c)
+	config.WideningCasts, config.NarrowingCasts = c.WideningCasts, c.NarrowingCasts
+}
+
 type writerOption func(*WriterConfig)
 
 func (opt writerOption) ConfigureWriter(config *WriterConfig) { opt(config) }
@@ -491,6 +1027,13 @@ func coalesceInt64(i1, i2 int64) int64 {
 	return i2
 }
 
+func coalesceFloat64(f1, f2 float64) float64 {
+	if f1 != 0 {
+		return f1
+	}
+	return f2
+}
+
 func coalesceString(s1, s2 string) string {
 	if s1 != "" {
 		return s1
@@ -498,6 +1041,13 @@ func coalesceString(s1, s2 string) string {
 	return s2
 }
 
+func coalesceStrings(s1, s2 []string) []string {
+	if s1 != nil {
+		return s1
+	}
+	return s2
+}
+
 func coalesceBytes(b1, b2 []byte) []byte {
 	if b1 != nil {
 		return b1
@@ -512,6 +1062,20 @@ func coalescePageBufferPool(p1, p2 PageBufferPool) PageBufferPool {
 	return p2
 }
 
+func coalescePageErrorHandler(h1, h2 func(*PageError)) func(*PageError) {
+	if h1 != nil {
+		return h1
+	}
+	return h2
+}
+
+func coalesceRowGroupFlushHandler(h1, h2 func(*RowGroupStats)) func(*RowGroupStats) {
+	if h1 != nil {
+		return h1
+	}
+	return h2
+}
+
 func coalesceSchema(s1, s2 *Schema) *Schema {
 	if s1 != nil {
 		return s1
@@ -519,6 +1083,27 @@ func coalesceSchema(s1, s2 *Schema) *Schema {
 	return s2
 }
 
+func coalesceNode(n1, n2 Node) Node {
+	if n1 != nil {
+		return n1
+	}
+	return n2
+}
+
+func coalesceAllocator(a1, a2 Allocator) Allocator {
+	if a1 != nil {
+		return a1
+	}
+	return a2
+}
+
+func coalesceDuplicateRowPolicy(p1, p2 DuplicateRowPolicy) DuplicateRowPolicy {
+	if p1 != KeepDuplicateRows {
+		return p1
+	}
+	return p2
+}
+
 func coalesceSortingColumns(s1, s2 []SortingColumn) []SortingColumn {
 	if s1 != nil {
 		return s1
@@ -533,6 +1118,34 @@ func coalesceBloomFilters(f1, f2 []BloomFilterColumn) []BloomFilterColumn {
 	return f2
 }
 
+func coalesceColumnAggregators(a1, a2 []AggregatorColumn) []AggregatorColumn {
+	if a1 != nil {
+		return a1
+	}
+	return a2
+}
+
+func coalesceEncryption(e1, e2 *EncryptionConfig) *EncryptionConfig {
+	if e1 != nil {
+		return e1
+	}
+	return e2
+}
+
+func coalesceDecryption(d1, d2 *DecryptionConfig) *DecryptionConfig {
+	if d1 != nil {
+		return d1
+	}
+	return d2
+}
+
+func coalesceBloomFilterPlacement(p1, p2 BloomFilterPlacement) BloomFilterPlacement {
+	if p1 != 0 {
+		return p1
+	}
+	return p2
+}
+
 func coalesceCompression(c1, c2 compress.Codec) compress.Codec {
 	if c1 != nil {
 		return c1
@@ -547,6 +1160,13 @@ func validatePositiveInt(optionName string, optionValue int) error {
 	return errorInvalidOptionValue(optionName, optionValue)
 }
 
+func validateNonNegativeInt(optionName string, optionValue int) error {
+	if optionValue >= 0 {
+		return nil
+	}
+	return errorInvalidOptionValue(optionName, optionValue)
+}
+
 func validatePositiveInt64(optionName string, optionValue int64) error {
 	if optionValue > 0 {
 		return nil
@@ -554,6 +1174,13 @@ func validatePositiveInt64(optionName string, optionValue int64) error {
 	return errorInvalidOptionValue(optionName, optionValue)
 }
 
+func validateNonNegativeFloat64(optionName string, optionValue float64) error {
+	if optionValue >= 0 {
+		return nil
+	}
+	return errorInvalidOptionValue(optionName, optionValue)
+}
+
 func validateOneOfInt(optionName string, optionValue int, supportedValues ...int) error {
 	for _, value := range supportedValues {
 		if value == optionValue {
@@ -563,6 +1190,13 @@ func validateOneOfInt(optionName string, optionValue int, supportedValues ...int
 	return errorInvalidOptionValue(optionName, optionValue)
 }
 
+func validateDistinctCountPrecision(optionName string, optionValue int) error {
+	if optionValue == 0 || (optionValue >= hyperloglog.MinPrecision && optionValue <= hyperloglog.MaxPrecision) {
+		return nil
+	}
+	return errorInvalidOptionValue(optionName, optionValue)
+}
+
 func validateNotNil(optionName string, optionValue interface{}) error {
 	if optionValue != nil {
 		return nil
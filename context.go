@@ -0,0 +1,79 @@
+package parquet
+
+import (
+	"context"
+	"io"
+)
+
+// ReaderAtContext is an interface implemented by io.ReaderAt values which
+// support cancellation of reads, for example readers of remote storage which
+// abort in-flight requests when the context is canceled.
+//
+// When a file is read through one of the context-aware APIs (OpenFileContext,
+// ReadPageContext, ReadRowsContext, etc...), the context is passed down to the
+// underlying reader if it implements this interface. Otherwise, the context is
+// only checked before each read, which bounds the delay before a cancellation
+// is observed to the duration of a single call to ReadAt.
+type ReaderAtContext interface {
+	ReadAtContext(ctx context.Context, b []byte, off int64) (int, error)
+}
+
+// PagesContext is an interface implemented by Pages which support cancellation
+// of reads. The Pages returned by column chunks of a File implement it.
+type PagesContext interface {
+	ReadPageContext(ctx context.Context) (Page, error)
+}
+
+// RowReaderContext is an interface implemented by row readers which support
+// cancellation of reads. The Rows returned by row groups of a File implement
+// it, as well as Reader and GenericReader.
+type RowReaderContext interface {
+	ReadRowsContext(ctx context.Context, rows []Row) (int, error)
+}
+
+// ReadPageContext reads the next page from pages, using ctx to cancel the read
+// if pages implement PagesContext. Otherwise, the context is only checked for
+// cancellation before reading the page.
+func ReadPageContext(ctx context.Context, pages Pages) (Page, error) {
+	if p, ok := pages.(PagesContext); ok {
+		return p.ReadPageContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return pages.ReadPage()
+}
+
+// ReadRowsContext reads rows from r, using ctx to cancel the read if r
+// implements RowReaderContext. Otherwise, the context is only checked for
+// cancellation before reading the rows.
+func ReadRowsContext(ctx context.Context, r RowReader, rows []Row) (int, error) {
+	if rc, ok := r.(RowReaderContext); ok {
+		return rc.ReadRowsContext(ctx, rows)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadRows(rows)
+}
+
+func readAtContext(ctx context.Context, r io.ReaderAt, b []byte, off int64) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if rc, ok := r.(ReaderAtContext); ok {
+		return rc.ReadAtContext(ctx, b, off)
+	}
+	return r.ReadAt(b, off)
+}
+
+// contextReaderAt is an io.ReaderAt which passes a context to the reads of
+// the underlying reader.
+type contextReaderAt struct {
+	ctx    context.Context
+	reader io.ReaderAt
+}
+
+func (r *contextReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	return readAtContext(r.ctx, r.reader, b, off)
+}
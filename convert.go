@@ -1,9 +1,13 @@
 package parquet
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"math"
+	"reflect"
 	"sync"
+	"time"
 )
 
 // ConvertError is an error type returned by calls to Convert when the conversion
@@ -51,6 +55,7 @@ type conversion struct {
 	targetColumnKinds   []Kind
 	targetToSourceIndex []int16
 	sourceToTargetIndex []int16
+	sourceConversions   []castFunc
 	schema              *Schema
 	buffers             sync.Pool
 }
@@ -88,7 +93,18 @@ func (c *conversion) Convert(target, source Row) (Row, error) {
 		sourceIndex := value.Column()
 		targetIndex := c.sourceToTargetIndex[sourceIndex]
 		if targetIndex >= 0 {
-			value.kind = ^int8(c.targetColumnKinds[targetIndex])
+			if c.sourceConversions != nil && c.sourceConversions[sourceIndex] != nil && !value.IsNull() {
+				v, err := castValue(value, c.sourceConversions[sourceIndex])
+				if err != nil {
+					return target, err
+				}
+				value = v
+			}
+			// Null values remain null, they represent missing optional or
+			// repeated values which cannot be converted.
+			if !value.IsNull() {
+				value.kind = ^int8(c.targetColumnKinds[targetIndex])
+			}
 			value.columnIndex = ^targetIndex
 			buffer.columns[targetIndex] = append(buffer.columns[targetIndex], value)
 		}
@@ -128,21 +144,35 @@ func (id identity) Schema() *Schema                   { return id.schema }
 // stripped out of the rows. Extra columns in the target schema will be set to
 // null or zero values.
 //
+// Columns must have the same physical types in both schemas, with the exception
+// of legacy INT96 timestamp columns which may be converted to TIMESTAMP columns
+// of any time unit, and of the casts enabled by the WideningCasts and
+// NarrowingCasts options. Columns of different logical types with the same
+// physical type are converted as-is (e.g. STRING to ENUM), but TIMESTAMP and
+// TIME columns of different units can only be converted by casts; an error is
+// returned if the cast that they require is not enabled.
+//
 // The returned function is intended to be used to append the converted source
 // row to the destination buffer.
-func Convert(to, from Node) (conv Conversion, err error) {
+func Convert(to, from Node, options ...ConvertOption) (conv Conversion, err error) {
+	config, err := NewConvertConfig(options...)
+	if err != nil {
+		return nil, err
+	}
+
 	schema, _ := to.(*Schema)
 	if schema == nil {
 		schema = NewSchema("", to)
 	}
 
-	if nodesAreEqual(to, from) {
+	if !conversionIsNeeded(to, from) {
 		return identity{schema}, nil
 	}
 
 	targetMapping, targetColumns := columnMappingOf(to)
 	sourceMapping, sourceColumns := columnMappingOf(from)
 
+	var sourceConversions []castFunc
 	columnIndexBuffer := make([]int16, len(targetColumns)+len(sourceColumns))
 	targetColumnKinds := make([]Kind, len(targetColumns))
 	targetToSourceIndex := columnIndexBuffer[:len(targetColumns)]
@@ -162,9 +192,16 @@ func Convert(to, from Node) (conv Conversion, err error) {
 		if targetColumn.node != nil {
 			sourceType := sourceColumn.node.Type()
 			targetType := targetColumn.node.Type()
-			if sourceType.Kind() != targetType.Kind() {
+			cast, ok := castFuncOf(sourceType, targetType, config)
+			if !ok {
 				return nil, &ConvertError{Path: path, From: sourceColumn.node, To: targetColumn.node}
 			}
+			if cast != nil {
+				if sourceConversions == nil {
+					sourceConversions = make([]castFunc, len(sourceColumns))
+				}
+				sourceConversions[i] = cast
+			}
 
 			sourceRepetition := fieldRepetitionTypeOf(sourceColumn.node)
 			targetRepetition := fieldRepetitionTypeOf(targetColumn.node)
@@ -180,10 +217,154 @@ func Convert(to, from Node) (conv Conversion, err error) {
 		targetColumnKinds:   targetColumnKinds,
 		targetToSourceIndex: targetToSourceIndex,
 		sourceToTargetIndex: sourceToTargetIndex,
+		sourceConversions:   sourceConversions,
 		schema:              schema,
 	}, nil
 }
 
+// conversionIsNeeded returns true if converting rows from one schema to the
+// other is not the identity. Schemas with the same structure and physical types
+// are considered equal, unless they have timestamp or time columns of different
+// units, which must be cast or rejected.
+func conversionIsNeeded(to, from Node) bool {
+	if !nodesAreEqual(to, from) {
+		return true
+	}
+	sourceTypes := make([]Type, 0, 16)
+	forEachLeafColumnOf(from, func(leaf leafColumn) {
+		sourceTypes = append(sourceTypes, leaf.node.Type())
+	})
+	needed := false
+	forEachLeafColumnOf(to, func(leaf leafColumn) {
+		sourceUnit, targetUnit, ok := timeUnitsOf(sourceTypes[leaf.columnIndex], leaf.node.Type())
+		needed = needed || (ok && sourceUnit != targetUnit)
+	})
+	return needed
+}
+
+// timeUnitsOf returns the time units of the source and target types, and true
+// if both are timestamp types or both are time types.
+func timeUnitsOf(sourceType, targetType Type) (sourceUnit, targetUnit time.Duration, ok bool) {
+	switch source := sourceType.(type) {
+	case *timestampType:
+		if target, isTimestamp := targetType.(*timestampType); isTimestamp {
+			return source.unit(), target.unit(), true
+		}
+	case *timeType:
+		if target, isTime := targetType.(*timeType); isTime {
+			return source.unit(), target.unit(), true
+		}
+	}
+	return 0, 0, false
+}
+
+// castFunc is the type of functions converting values between the physical
+// types of columns.
+type castFunc func(Value) (Value, error)
+
+// castFuncOf returns a function converting values of the source type to the
+// target type. The function returns a nil castFunc if the values do not need
+// to be converted, and false if the conversion is not supported.
+func castFuncOf(sourceType, targetType Type, config *ConvertConfig) (castFunc, bool) {
+	sourceKind := sourceType.Kind()
+	targetKind := targetType.Kind()
+
+	if sourceUnit, targetUnit, ok := timeUnitsOf(sourceType, targetType); ok && sourceUnit != targetUnit {
+		// Values of different units cannot be converted as-is, they must be
+		// scaled by a cast, or the conversion is rejected.
+		switch {
+		case sourceUnit < targetUnit && config.NarrowingCasts:
+		case sourceUnit > targetUnit && config.WideningCasts:
+		default:
+			return nil, false
+		}
+		return castTimeUnit(sourceUnit, targetUnit, targetKind), true
+	}
+
+	if targetTimestamp, ok := targetType.(*timestampType); ok && sourceKind == Int96 {
+		return func(v Value) (Value, error) {
+			return makeValueInt64(targetTimestamp.unixValue(v.Int96().Time())), nil
+		}, true
+	}
+
+	if sourceKind == targetKind {
+		return nil, true
+	}
+
+	switch {
+	case sourceKind == Int32 && targetKind == Int64 && config.WideningCasts:
+		if isUnsignedType(sourceType) {
+			return func(v Value) (Value, error) { return makeValueInt64(int64(v.Uint32())), nil }, true
+		}
+		return func(v Value) (Value, error) { return makeValueInt64(int64(v.Int32())), nil }, true
+
+	case sourceKind == Float && targetKind == Double && config.WideningCasts:
+		return func(v Value) (Value, error) { return makeValueDouble(float64(v.Float())), nil }, true
+
+	case sourceKind == Int64 && targetKind == Int32 && config.NarrowingCasts:
+		return castInt64ToInt32(isUnsignedType(sourceType), isUnsignedType(targetType)), true
+
+	case sourceKind == Double && targetKind == Float && config.NarrowingCasts:
+		return func(v Value) (Value, error) {
+			d := v.Double()
+			if f := float32(d); !math.IsInf(float64(f), 0) || math.IsInf(d, 0) {
+				return makeValueFloat(f), nil
+			}
+			return v, fmt.Errorf("cannot cast DOUBLE value %g to FLOAT: value out of range", d)
+		}, true
+	}
+
+	return nil, false
+}
+
+func castInt64ToInt32(sourceUnsigned, targetUnsigned bool) castFunc {
+	minValue, maxValue := int64(math.MinInt32), int64(math.MaxInt32)
+	if targetUnsigned {
+		minValue, maxValue = 0, math.MaxUint32
+	}
+	return func(v Value) (Value, error) {
+		if sourceUnsigned {
+			if u := v.Uint64(); u > uint64(maxValue) {
+				return v, fmt.Errorf("cannot cast INT64 value %d to INT32: value out of range", u)
+			}
+		} else {
+			if i := v.Int64(); i < minValue || i > maxValue {
+				return v, fmt.Errorf("cannot cast INT64 value %d to INT32: value out of range", i)
+			}
+		}
+		return makeValueInt32(int32(v.Int64())), nil
+	}
+}
+
+// castTimeUnit returns a function scaling timestamp or time values from the
+// source unit to the target unit, producing values of the target kind.
+func castTimeUnit(sourceUnit, targetUnit time.Duration, targetKind Kind) castFunc {
+	makeValue := makeValueInt64
+	if targetKind == Int32 {
+		makeValue = func(v int64) Value { return makeValueInt32(int32(v)) }
+	}
+	if sourceUnit > targetUnit {
+		scale := int64(sourceUnit / targetUnit)
+		return func(v Value) (Value, error) {
+			return makeValue(v.Int64() * scale), nil
+		}
+	}
+	scale := int64(targetUnit / sourceUnit)
+	return func(v Value) (Value, error) {
+		t := v.Int64()
+		q := t / scale
+		if t%scale < 0 {
+			q-- // round towards negative infinity to truncate the time
+		}
+		return makeValue(q), nil
+	}
+}
+
+func isUnsignedType(t Type) bool {
+	logicalType := t.LogicalType()
+	return logicalType != nil && logicalType.Integer != nil && !logicalType.Integer.IsSigned
+}
+
 // ConvertRowGroup constructs a wrapper of the given row group which applies
 // the given schema conversion to its rows.
 func ConvertRowGroup(rowGroup RowGroup, conv Conversion) RowGroup {
@@ -207,6 +388,14 @@ func ConvertRowGroup(rowGroup RowGroup, conv Conversion) RowGroup {
 				numValues: numRows,
 				numNulls:  numRows,
 			}
+		} else if convert := sourceConversionOf(conv, j); convert != nil {
+			columns[i] = &convertedColumnChunk{
+				base:               rowGroupColumns[j],
+				typ:                leaf.node.Type(),
+				maxRepetitionLevel: leaf.maxRepetitionLevel,
+				maxDefinitionLevel: leaf.maxDefinitionLevel,
+				convert:            convert,
+			}
 		} else {
 			columns[i] = rowGroupColumns[j]
 		}
@@ -250,6 +439,199 @@ func ConvertRowGroup(rowGroup RowGroup, conv Conversion) RowGroup {
 	}
 }
 
+// convertRowGroupForWrite returns a row group exposing the rows of rowGroup in
+// the schema of a buffer or writer, or rowGroup itself when the schemas are
+// equal. Unlike convertRowGroupTo, it does not panic on schemas which cannot be
+// converted, the error wraps ErrRowGroupSchemaMismatch instead.
+func convertRowGroupForWrite(rowGroup RowGroup, schema *Schema) (RowGroup, error) {
+	rowGroupSchema := rowGroup.Schema()
+	if nodesAreEqual(schema, rowGroupSchema) {
+		return rowGroup, nil
+	}
+	conv, err := Convert(schema, rowGroupSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRowGroupSchemaMismatch, err)
+	}
+	return ConvertRowGroup(rowGroup, conv), nil
+}
+
+// int96TimestampSchemaOf returns a schema where the INT96 columns of the given
+// schema are replaced by TIMESTAMP columns of nanosecond precision. The schema
+// is returned unchanged if it has no INT96 columns.
+func int96TimestampSchemaOf(schema *Schema) *Schema {
+	node, changed := int96TimestampNodeOf(schema)
+	if !changed {
+		return schema
+	}
+	return NewSchema(schema.Name(), node)
+}
+
+func int96TimestampNodeOf(node Node) (Node, bool) {
+	if node.Leaf() {
+		if node.Type().Kind() != Int96 {
+			return node, false
+		}
+		return &int96TimestampNode{node}, true
+	}
+
+	fields := node.Fields()
+	converted := make([]Field, len(fields))
+	changed := false
+	for i, field := range fields {
+		n, ok := int96TimestampNodeOf(field)
+		if ok {
+			converted[i] = &convertedField{Node: n, field: field}
+			changed = true
+		} else {
+			converted[i] = field
+		}
+	}
+	if !changed {
+		return node, false
+	}
+	return &convertedGroup{Node: node, fields: converted}, true
+}
+
+var int96TimestampType = Timestamp(Nanosecond).Type()
+
+type int96TimestampNode struct{ Node }
+
+func (n *int96TimestampNode) Type() Type { return int96TimestampType }
+
+func (n *int96TimestampNode) GoType() reflect.Type { return goTypeOf(n) }
+
+// convertedGroup is a group node with fields replaced by the conversion of the
+// original fields, preserving their order.
+type convertedGroup struct {
+	Node
+	fields []Field
+}
+
+func (g *convertedGroup) Fields() []Field { return g.fields }
+
+func (g *convertedGroup) GoType() reflect.Type { return goTypeOf(g) }
+
+type convertedField struct {
+	Node
+	field Field
+}
+
+func (f *convertedField) Name() string { return f.field.Name() }
+
+func (f *convertedField) Value(base reflect.Value) reflect.Value { return f.field.Value(base) }
+
+// castValue applies the cast function to v, retaining its levels and column
+// index.
+func castValue(v Value, cast castFunc) (Value, error) {
+	c, err := cast(v)
+	if err != nil {
+		return v, err
+	}
+	c.repetitionLevel = v.repetitionLevel
+	c.definitionLevel = v.definitionLevel
+	c.columnIndex = v.columnIndex
+	return c, nil
+}
+
+func sourceConversionOf(conv Conversion, columnIndex int) castFunc {
+	if c, ok := conv.(*conversion); ok && c.sourceConversions != nil {
+		return c.sourceConversions[columnIndex]
+	}
+	return nil
+}
+
+// convertedColumnChunk is a wrapper of column chunks applying a conversion to
+// the values of the pages they contain.
+type convertedColumnChunk struct {
+	base               ColumnChunk
+	typ                Type
+	maxRepetitionLevel byte
+	maxDefinitionLevel byte
+	convert            castFunc
+}
+
+func (c *convertedColumnChunk) Type() Type               { return c.typ }
+func (c *convertedColumnChunk) Column() int              { return c.base.Column() }
+func (c *convertedColumnChunk) Pages() Pages             { return c.pagesWithMemory(nil) }
+func (c *convertedColumnChunk) ColumnIndex() ColumnIndex { return nil }
+func (c *convertedColumnChunk) OffsetIndex() OffsetIndex { return c.base.OffsetIndex() }
+func (c *convertedColumnChunk) BloomFilter() BloomFilter { return nil }
+func (c *convertedColumnChunk) NumValues() int64         { return c.base.NumValues() }
+
+func (c *convertedColumnChunk) pagesWithMemory(m *memoryReservation) Pages {
+	return &convertedPages{chunk: c, base: pagesWithMemory(c.base, m)}
+}
+
+type convertedPages struct {
+	chunk  *convertedColumnChunk
+	base   Pages
+	values []Value
+}
+
+func (p *convertedPages) ReadPage() (Page, error) {
+	return p.ReadPageContext(context.Background())
+}
+
+func (p *convertedPages) ReadPageContext(ctx context.Context) (Page, error) {
+	page, err := ReadPageContext(ctx, p.base)
+	if err != nil {
+		return nil, err
+	}
+
+	numValues := int(page.NumValues())
+	if cap(p.values) < numValues {
+		p.values = make([]Value, numValues)
+	} else {
+		p.values = p.values[:numValues]
+	}
+	defer clearValues(p.values)
+
+	n, err := readValues(page.Values(), p.values)
+	if err != nil {
+		return nil, err
+	}
+	values := p.values[:n]
+
+	for i, v := range values {
+		if !v.IsNull() {
+			if values[i], err = castValue(v, p.chunk.convert); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	column := p.chunk.typ.NewColumnBuffer(page.Column(), n)
+	switch {
+	case p.chunk.maxRepetitionLevel > 0:
+		column = newRepeatedColumnBuffer(column, p.chunk.maxRepetitionLevel, p.chunk.maxDefinitionLevel, nullsGoLast)
+	case p.chunk.maxDefinitionLevel > 0:
+		column = newOptionalColumnBuffer(column, p.chunk.maxDefinitionLevel, nullsGoLast)
+	}
+	if _, err := column.WriteValues(values); err != nil {
+		return nil, err
+	}
+	return column.Page(), nil
+}
+
+func (p *convertedPages) SeekToRow(rowIndex int64) error { return p.base.SeekToRow(rowIndex) }
+
+func (p *convertedPages) Close() error { return p.base.Close() }
+
+func readValues(r ValueReader, values []Value) (int, error) {
+	n := 0
+	for n < len(values) {
+		c, err := r.ReadValues(values[n:])
+		n += c
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+	}
+	return n, nil
+}
+
 func maskMissingRowGroupColumns(r RowGroup, numColumns int, conv Conversion) RowGroup {
 	rowGroupColumns := r.ColumnChunks()
 	columns := make([]ColumnChunk, len(rowGroupColumns))
@@ -374,7 +756,11 @@ func (c *convertedRowGroup) ColumnChunks() []ColumnChunk     { return c.columns
 func (c *convertedRowGroup) Schema() *Schema                 { return c.conv.Schema() }
 func (c *convertedRowGroup) SortingColumns() []SortingColumn { return c.sorting }
 func (c *convertedRowGroup) Rows() Rows {
-	rows := c.rowGroup.Rows()
+	return c.rowsWithMemory(nil)
+}
+
+func (c *convertedRowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	rows := rowsWithMemory(c.rowGroup, m)
 	return &convertedRows{
 		Closer: rows,
 		rows:   rows,
@@ -396,12 +782,16 @@ type convertedRows struct {
 }
 
 func (c *convertedRows) ReadRows(rows []Row) (int, error) {
+	return c.ReadRowsContext(context.Background(), rows)
+}
+
+func (c *convertedRows) ReadRowsContext(ctx context.Context, rows []Row) (int, error) {
 	maxRowLen := 0
 	defer func() {
 		clearValues(c.buf[:maxRowLen])
 	}()
 
-	n, err := c.rows.ReadRows(rows)
+	n, err := ReadRowsContext(ctx, c.rows, rows)
 
 	for i, row := range rows[:n] {
 		var err error
@@ -1,6 +1,7 @@
 package parquet_test
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -129,4 +130,253 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+var castTests = [...]struct {
+	scenario string
+	options  []parquet.ConvertOption
+	from     interface{}
+	to       interface{}
+	err      bool
+}{
+	{
+		scenario: "int32 to int64 without widening casts",
+		from:     struct{ Value int32 }{Value: 42},
+		to:       struct{ Value int64 }{},
+		err:      true,
+	},
+
+	{
+		scenario: "int32 to int64",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from:     struct{ Value int32 }{Value: -42},
+		to:       struct{ Value int64 }{Value: -42},
+	},
+
+	{
+		scenario: "uint32 to int64",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from:     struct{ Value uint32 }{Value: math.MaxUint32},
+		to:       struct{ Value int64 }{Value: math.MaxUint32},
+	},
+
+	{
+		scenario: "float to double",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from:     struct{ Value float32 }{Value: 0.5},
+		to:       struct{ Value float64 }{Value: 0.5},
+	},
+
+	{
+		scenario: "timestamp millis to micros",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from: struct {
+			Value int64 `parquet:",timestamp(millisecond)"`
+		}{Value: 1234},
+		to: struct {
+			Value int64 `parquet:",timestamp(microsecond)"`
+		}{Value: 1234000},
+	},
+
+	{
+		scenario: "timestamp nanos to millis",
+		options:  []parquet.ConvertOption{parquet.NarrowingCasts(true)},
+		from: struct {
+			Value int64 `parquet:",timestamp(nanosecond)"`
+		}{Value: -1},
+		to: struct {
+			Value int64 `parquet:",timestamp(millisecond)"`
+		}{Value: -1},
+	},
+
+	{
+		scenario: "timestamp millis to micros without casts",
+		from: struct {
+			Value int64 `parquet:",timestamp(millisecond)"`
+		}{Value: 1234},
+		to: struct {
+			Value int64 `parquet:",timestamp(microsecond)"`
+		}{},
+		err: true,
+	},
+
+	{
+		scenario: "timestamp nanos to millis without narrowing casts",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from: struct {
+			Value int64 `parquet:",timestamp(nanosecond)"`
+		}{Value: 1},
+		to: struct {
+			Value int64 `parquet:",timestamp(millisecond)"`
+		}{},
+		err: true,
+	},
+
+	{
+		scenario: "int64 to int32",
+		options:  []parquet.ConvertOption{parquet.NarrowingCasts(true)},
+		from:     struct{ Value int64 }{Value: math.MinInt32},
+		to:       struct{ Value int32 }{Value: math.MinInt32},
+	},
+
+	{
+		scenario: "int64 to int32 out of range",
+		options:  []parquet.ConvertOption{parquet.NarrowingCasts(true)},
+		from:     struct{ Value int64 }{Value: math.MaxInt32 + 1},
+		to:       struct{ Value int32 }{},
+		err:      true,
+	},
+
+	{
+		scenario: "int64 to int32 without narrowing casts",
+		options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+		from:     struct{ Value int64 }{Value: 1},
+		to:       struct{ Value int32 }{},
+		err:      true,
+	},
+
+	{
+		scenario: "double to float",
+		options:  []parquet.ConvertOption{parquet.NarrowingCasts(true)},
+		from:     struct{ Value float64 }{Value: 0.25},
+		to:       struct{ Value float32 }{Value: 0.25},
+	},
+
+	{
+		scenario: "string to enum",
+		from:     struct{ Value string }{Value: "A"},
+		to: struct {
+			Value string `parquet:",enum"`
+		}{Value: "A"},
+	},
+}
+
+func TestConvertNullValues(t *testing.T) {
+	type Row struct {
+		ID   int64
+		Name *string
+		Tags []int64
+	}
+	from := parquet.SchemaOf(Row{})
+	to := parquet.SchemaOf(struct {
+		Name *string
+		Tags []int64
+	}{})
+
+	conv, err := parquet.Convert(to, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	row, err := conv.Convert(nil, from.Deconstruct(nil, Row{ID: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range row {
+		if !v.IsNull() {
+			t.Errorf("null value of column %d was converted to %v", v.Column(), v)
+		}
+	}
+}
+
+func TestConvertCasts(t *testing.T) {
+	for _, test := range castTests {
+		t.Run(test.scenario, func(t *testing.T) {
+			to := parquet.SchemaOf(test.to)
+			from := parquet.SchemaOf(test.from)
+
+			conv, err := parquet.Convert(to, from, test.options...)
+			if err == nil {
+				_, err = conv.Convert(nil, from.Deconstruct(nil, test.from))
+			}
+			if test.err {
+				if err == nil {
+					t.Fatal("expected an error but the conversion succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buffer := parquet.NewBuffer(from)
+			if err := buffer.Write(test.from); err != nil {
+				t.Fatal(err)
+			}
+
+			rows := parquet.ConvertRowGroup(buffer, conv).Rows()
+			defer rows.Close()
+
+			row := make([]parquet.Row, 1)
+			if n, err := rows.ReadRows(row); n != 1 {
+				t.Fatalf("reading converted row: n=%d err=%v", n, err)
+			}
+
+			value := reflect.New(reflect.TypeOf(test.to))
+			if err := to.Reconstruct(value.Interface(), row[0]); err != nil {
+				t.Fatal(err)
+			}
+
+			value = value.Elem()
+			if !reflect.DeepEqual(value.Interface(), test.to) {
+				t.Errorf("converted value mismatch:\nwant = %+v\ngot  = %+v", test.to, value.Interface())
+			}
+		})
+	}
+}
+
 func newString(s string) *string { return &s }
+
+func TestConvertTimeCasts(t *testing.T) {
+	millis := parquet.NewSchema("test", parquet.Group{"value": parquet.Time(parquet.Millisecond)})
+	micros := parquet.NewSchema("test", parquet.Group{"value": parquet.Time(parquet.Microsecond)})
+	nanos := parquet.NewSchema("test", parquet.Group{"value": parquet.Time(parquet.Nanosecond)})
+
+	for _, test := range []struct {
+		scenario string
+		to, from *parquet.Schema
+		options  []parquet.ConvertOption
+		value    parquet.Value
+		want     parquet.Value
+	}{
+		{
+			scenario: "millis to micros",
+			to:       micros,
+			from:     millis,
+			options:  []parquet.ConvertOption{parquet.WideningCasts(true)},
+			value:    parquet.ValueOf(int32(1234)),
+			want:     parquet.ValueOf(int64(1234000)),
+		},
+		{
+			scenario: "nanos to millis",
+			to:       millis,
+			from:     nanos,
+			options:  []parquet.ConvertOption{parquet.NarrowingCasts(true)},
+			value:    parquet.ValueOf(int64(1234567890)),
+			want:     parquet.ValueOf(int32(1234)),
+		},
+		{
+			scenario: "micros to nanos without casts",
+			to:       nanos,
+			from:     micros,
+			value:    parquet.ValueOf(int64(1)),
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			conv, err := parquet.Convert(test.to, test.from, test.options...)
+			if test.want.IsNull() {
+				if err == nil {
+					t.Fatal("expected an error but the conversion succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			row, err := conv.Convert(nil, parquet.Row{test.value.Level(0, 0, 0)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parquet.Equal(row[0], test.want) || row[0].Kind() != test.want.Kind() {
+				t.Errorf("wrong converted value: want=%v got=%v", test.want, row[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package parquet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCSVInferRows is the default number of records used to infer the
+	// schema of CSV data, see CSVInferRows.
+	DefaultCSVInferRows = 1000
+)
+
+// The CSVConfig type carries configuration options for reading rows from CSV
+// data.
+//
+// CSVConfig implements the CSVOption interface so it can be used directly as
+// argument to the NewCSVReader function, for example:
+//
+//	rows, err := parquet.NewCSVReader(csv.NewReader(input), &parquet.CSVConfig{
+//		Schema:     schema,
+//		Header:     true,
+//		NullValues: []string{"", "NULL"},
+//	})
+//
+type CSVConfig struct {
+	// The schema of the rows, or nil to infer the schema from the records.
+	Schema *Schema
+	// When true, the first record holds the names of the columns.
+	Header bool
+	// Fields equal to one of these values are read as null values.
+	NullValues []string
+	// Number of records used to infer the schema.
+	InferRows int
+}
+
+// DefaultCSVConfig returns a new CSVConfig value initialized with the default
+// configuration: the first record is a header, empty fields are null, and the
+// schema is inferred from DefaultCSVInferRows records.
+func DefaultCSVConfig() *CSVConfig {
+	return &CSVConfig{
+		Header:     true,
+		NullValues: []string{""},
+		InferRows:  DefaultCSVInferRows,
+	}
+}
+
+// Apply applies options to c.
+func (c *CSVConfig) Apply(options ...CSVOption) {
+	for _, opt := range options {
+		opt.ConfigureCSV(c)
+	}
+}
+
+// ConfigureCSV satisfies the CSVOption interface.
+func (c *CSVConfig) ConfigureCSV(config *CSVConfig) {
+	*config = CSVConfig{
+		Schema:     coalesceSchema(c.Schema, config.Schema),
+		Header:     config.Header,
+		NullValues: coalesceStrings(c.NullValues, config.NullValues),
+		InferRows:  coalesceInt(c.InferRows, config.InferRows),
+	}
+}
+
+// CSVSchema constructs a configuration option which sets the schema of the
+// rows read from CSV data.
+//
+// Defaults to nil, which means the schema is inferred from the records.
+func CSVSchema(schema *Schema) CSVOption {
+	return csvOption(func(c *CSVConfig) { c.Schema = schema })
+}
+
+// CSVHeader constructs a configuration option which sets whether the first
+// record of CSV data holds the names of the columns.
+//
+// Defaults to true.
+func CSVHeader(header bool) CSVOption {
+	return csvOption(func(c *CSVConfig) { c.Header = header })
+}
+
+// CSVNullValues constructs a configuration option which sets the field values
+// read as null values.
+//
+// Defaults to the empty string.
+func CSVNullValues(values ...string) CSVOption {
+	return csvOption(func(c *CSVConfig) { c.NullValues = values })
+}
+
+// CSVInferRows constructs a configuration option which sets the number of
+// records used to infer the schema of CSV data.
+//
+// Defaults to DefaultCSVInferRows.
+func CSVInferRows(numRows int) CSVOption {
+	return csvOption(func(c *CSVConfig) { c.InferRows = numRows })
+}
+
+// CSVOption is an interface implemented by types that carry configuration
+// options for reading rows from CSV data.
+type CSVOption interface {
+	ConfigureCSV(*CSVConfig)
+}
+
+type csvOption func(*CSVConfig)
+
+func (f csvOption) ConfigureCSV(c *CSVConfig) { f(c) }
+
+// CSVReader reads parquet rows from CSV data, which allows converting CSV data
+// to parquet by copying the rows to a Writer:
+//
+//	rows, err := parquet.NewCSVReader(csv.NewReader(input))
+//	if err != nil {
+//		...
+//	}
+//	writer := parquet.NewWriter(output, rows.Schema())
+//	if _, err := parquet.CopyRows(writer, rows); err != nil {
+//		...
+//	}
+//	if err := writer.Close(); err != nil {
+//		...
+//	}
+//
+// The schema of the rows is either configured with CSVSchema, or inferred from
+// the first records: each column is an optional column of the first type that
+// all its non-null fields can be parsed as, trying in order 64 bits integers,
+// double precision floating point numbers, booleans, RFC 3339 timestamps with
+// nanosecond precision, dates in the "2006-01-02" format, and strings. The
+// columns are named after the header, or "column1", "column2", etc... when the
+// data has no header.
+//
+// Configured schemas must only have leaf columns at the top level, which may
+// be optional but not repeated. When the data has a header, the columns of the
+// schema are matched by name with the columns of the header, columns of the
+// header missing from the schema are ignored, and optional columns of the
+// schema missing from the header are null. Without a header, the fields of each
+// record are matched with the columns of the schema in the order of the
+// schema.
+//
+// Fields are parsed according to the logical type of their column: booleans
+// with strconv.ParseBool, numbers in decimal notation, TIMESTAMP values as RFC
+// 3339 strings, DATE values in the "2006-01-02" format, UUID values as strings
+// and other fixed length byte arrays as their raw content.
+type CSVReader struct {
+	reader  *csv.Reader
+	schema  *Schema
+	nulls   map[string]struct{}
+	columns []csvColumn
+	// Records read to infer the schema, which are returned before reading
+	// more records from the CSV reader.
+	records [][]string
+	// Number of records read from the CSV reader, including the header.
+	numRecords int64
+}
+
+type csvColumn struct {
+	name               string
+	columnIndex        int16
+	maxDefinitionLevel byte
+	// Index of the field holding the values of the column in the records, or
+	// -1 if the records have no field for the column.
+	field int
+	parse func(string) (Value, error)
+}
+
+// NewCSVReader constructs a reader of the rows held in the CSV records that r
+// reads.
+//
+// When the schema is inferred, the function reads the records needed to infer
+// it. The function returns an error if reading the header or the records
+// failed, or if the configured schema does not match the data.
+func NewCSVReader(r *csv.Reader, options ...CSVOption) (*CSVReader, error) {
+	config := DefaultCSVConfig()
+	config.Apply(options...)
+
+	c := &CSVReader{
+		reader: r,
+		nulls:  make(map[string]struct{}, len(config.NullValues)),
+	}
+	for _, null := range config.NullValues {
+		c.nulls[null] = struct{}{}
+	}
+
+	var header []string
+	if config.Header {
+		record, err := c.readRecord()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("reading csv header: %w", err)
+		}
+		header = append([]string{}, record...)
+	}
+
+	schema := config.Schema
+	if schema == nil {
+		var err error
+		// The columns of inferred schemas are ordered by name, they are matched
+		// with the fields of the records by name even when the data has no
+		// header.
+		if schema, header, err = c.inferSchema(header, config.InferRows); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.init(schema, header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Schema returns the schema of the rows read from the CSV data.
+func (c *CSVReader) Schema() *Schema { return c.schema }
+
+// ReadRows reads the next rows from the CSV data. The method returns io.EOF
+// when all the records were read.
+func (c *CSVReader) ReadRows(rows []Row) (int, error) {
+	for n := range rows {
+		var record []string
+		if len(c.records) > 0 {
+			record, c.records = c.records[0], c.records[1:]
+		} else {
+			var err error
+			if record, err = c.readRecord(); err != nil {
+				return n, err
+			}
+		}
+
+		row := rows[n][:0]
+		for i := range c.columns {
+			col := &c.columns[i]
+			v, err := c.parseField(col, record)
+			if err != nil {
+				return n, fmt.Errorf("csv record %d: column %q: %w", c.numRecords-int64(len(c.records)), col.name, err)
+			}
+			row = append(row, v)
+		}
+		rows[n] = row
+	}
+	return len(rows), nil
+}
+
+func (c *CSVReader) readRecord() ([]string, error) {
+	record, err := c.reader.Read()
+	if err == nil {
+		c.numRecords++
+	}
+	return record, err
+}
+
+func (c *CSVReader) parseField(col *csvColumn, record []string) (Value, error) {
+	if col.field >= 0 && col.field < len(record) {
+		field := record[col.field]
+		if _, null := c.nulls[field]; !null {
+			v, err := col.parse(field)
+			if err != nil {
+				return v, err
+			}
+			return v.Level(0, int(col.maxDefinitionLevel), int(col.columnIndex)), nil
+		}
+	}
+	if col.maxDefinitionLevel == 0 {
+		return Value{}, fmt.Errorf("null value in required column")
+	}
+	return Value{}.Level(0, 0, int(col.columnIndex)), nil
+}
+
+// inferSchema reads up to numRecords records to infer the types of the
+// columns, the records are retained to be returned by ReadRows. The method
+// returns the names of the columns in the order of the fields of the records.
+func (c *CSVReader) inferSchema(header []string, numRecords int) (*Schema, []string, error) {
+	if numRecords <= 0 {
+		numRecords = 1
+	}
+	for len(c.records) < numRecords {
+		record, err := c.readRecord()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, fmt.Errorf("reading csv records: %w", err)
+		}
+		// The csv reader may reuse the slices of records.
+		c.records = append(c.records, append([]string{}, record...))
+	}
+
+	numColumns := len(header)
+	if header == nil && len(c.records) > 0 {
+		numColumns = len(c.records[0])
+	}
+
+	names := header
+	if names == nil {
+		names = make([]string, numColumns)
+		for i := range names {
+			names[i] = fmt.Sprintf("column%d", i+1)
+		}
+	}
+
+	group := make(Group, numColumns)
+	for i, name := range names {
+		if _, exists := group[name]; exists {
+			return nil, nil, fmt.Errorf("cannot infer the schema of csv records: duplicate column name %q", name)
+		}
+
+		inferred, notNull := csvInferredTypes, false
+		for _, record := range c.records {
+			if i >= len(record) {
+				continue
+			}
+			if _, null := c.nulls[record[i]]; null {
+				continue
+			}
+			notNull = true
+			for len(inferred) > 1 {
+				if _, err := csvParseFuncOf(inferred[0].Type())(record[i]); err == nil {
+					break
+				}
+				inferred = inferred[1:]
+			}
+		}
+		if !notNull {
+			// Columns holding only null values are inferred as strings.
+			inferred = inferred[len(inferred)-1:]
+		}
+		group[name] = Optional(inferred[0])
+	}
+	return NewSchema("csv", group), names, nil
+}
+
+// csvInferredTypes are the types tried in order when inferring the types of
+// csv columns, the last type is used when no other types matched.
+var csvInferredTypes = []Node{
+	Int(64),
+	Leaf(DoubleType),
+	Leaf(BooleanType),
+	Timestamp(Nanosecond),
+	Date(),
+	String(),
+}
+
+func (c *CSVReader) init(schema *Schema, header []string) error {
+	fields := make(map[string]int, len(header))
+	for i, name := range header {
+		fields[name] = i
+	}
+
+	var err error
+	forEachLeafColumnOf(schema, func(leaf leafColumn) {
+		if err != nil {
+			return
+		}
+		name := leaf.path.String()
+		if len(leaf.path) > 1 || leaf.maxRepetitionLevel > 0 {
+			err = fmt.Errorf("cannot read csv records with schema %s: column %q is not a top-level optional or required column", schema.Name(), name)
+			return
+		}
+		col := csvColumn{
+			name:               name,
+			columnIndex:        leaf.columnIndex,
+			maxDefinitionLevel: leaf.maxDefinitionLevel,
+			field:              int(leaf.columnIndex),
+			parse:              csvParseFuncOf(leaf.node.Type()),
+		}
+		if header != nil {
+			i, ok := fields[name]
+			if !ok {
+				if leaf.maxDefinitionLevel == 0 {
+					err = fmt.Errorf("cannot read csv records with schema %s: required column %q is missing from the header", schema.Name(), name)
+					return
+				}
+				i = -1
+			}
+			col.field = i
+		}
+		if col.parse == nil {
+			err = fmt.Errorf("cannot read csv records with schema %s: column %q has unsupported type %s", schema.Name(), name, leaf.node.Type())
+			return
+		}
+		c.columns = append(c.columns, col)
+	})
+	c.schema = schema
+	return err
+}
+
+// csvParseFuncOf returns a function parsing the text of csv fields to values
+// of type t, or nil if the type is not supported.
+func csvParseFuncOf(t Type) func(string) (Value, error) {
+	lt := t.LogicalType()
+
+	switch t.Kind() {
+	case Boolean:
+		return func(s string) (Value, error) {
+			b, err := strconv.ParseBool(s)
+			return makeValueBoolean(b), err
+		}
+
+	case Int32:
+		switch {
+		case lt != nil && lt.Date != nil:
+			return func(s string) (Value, error) {
+				d, err := time.Parse("2006-01-02", s)
+				if err != nil {
+					return Value{}, err
+				}
+				days := d.Unix() / secondsPerDay
+				if d.Unix()%secondsPerDay < 0 {
+					days-- // round towards negative infinity for dates before 1970
+				}
+				return makeValueInt32(int32(days)), nil
+			}
+		case lt != nil && lt.Integer != nil && !lt.Integer.IsSigned:
+			return func(s string) (Value, error) {
+				u, err := strconv.ParseUint(s, 10, 32)
+				return makeValueUint32(uint32(u)), err
+			}
+		default:
+			return func(s string) (Value, error) {
+				i, err := strconv.ParseInt(s, 10, 32)
+				return makeValueInt32(int32(i)), err
+			}
+		}
+
+	case Int64:
+		switch {
+		case lt != nil && lt.Timestamp != nil:
+			timestamp := (*timestampType)(lt.Timestamp)
+			return func(s string) (Value, error) {
+				v, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return Value{}, err
+				}
+				return makeValueInt64(timestamp.unixValue(v)), nil
+			}
+		case lt != nil && lt.Integer != nil && !lt.Integer.IsSigned:
+			return func(s string) (Value, error) {
+				u, err := strconv.ParseUint(s, 10, 64)
+				return makeValueUint64(u), err
+			}
+		default:
+			return func(s string) (Value, error) {
+				i, err := strconv.ParseInt(s, 10, 64)
+				return makeValueInt64(i), err
+			}
+		}
+
+	case Float:
+		return func(s string) (Value, error) {
+			f, err := strconv.ParseFloat(s, 32)
+			return makeValueFloat(float32(f)), err
+		}
+
+	case Double:
+		return func(s string) (Value, error) {
+			f, err := strconv.ParseFloat(s, 64)
+			return makeValueDouble(f), err
+		}
+
+	case ByteArray:
+		if lt != nil && lt.Decimal != nil {
+			return nil
+		}
+		return func(s string) (Value, error) {
+			return makeValueString(ByteArray, s), nil
+		}
+
+	case FixedLenByteArray:
+		if lt != nil && lt.UUID != nil {
+			return func(s string) (Value, error) {
+				u, err := uuid.Parse(s)
+				if err != nil {
+					return Value{}, err
+				}
+				return makeValueBytes(FixedLenByteArray, u[:]), nil
+			}
+		}
+		if lt != nil && lt.Decimal != nil {
+			return nil
+		}
+		size := t.Length()
+		return func(s string) (Value, error) {
+			if len(s) != size {
+				return Value{}, fmt.Errorf("wrong field length for fixed length byte array of %d bytes: %d", size, len(s))
+			}
+			return makeValueBytes(FixedLenByteArray, []byte(s)), nil
+		}
+	}
+
+	return nil
+}
+
+var (
+	_ RowReaderWithSchema = (*CSVReader)(nil)
+)
@@ -0,0 +1,287 @@
+package parquet_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func convertCSV(t *testing.T, input string, options ...parquet.CSVOption) *parquet.File {
+	t.Helper()
+	rows, err := parquet.NewCSVReader(csv.NewReader(strings.NewReader(input)), options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, rows.Schema())
+	if _, err := parquet.CopyRows(w, rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestCSVReaderInferSchema(t *testing.T) {
+	const input = `id,name,score,active,created,day,note
+1,alice,1.5,true,2023-01-02T03:04:05Z,2023-01-02,
+2,bob,2,false,2023-01-02T03:04:05.123456789Z,1969-12-31,NULL
+3,,3,true,,2023-01-04,hello
+`
+	f := convertCSV(t, input, parquet.CSVNullValues("", "NULL"), parquet.CSVInferRows(2))
+
+	// The types are inferred from the first 2 records, the third record has a
+	// note which is not null.
+	want := parquet.NewSchema("csv", parquet.Group{
+		"id":      parquet.Optional(parquet.Int(64)),
+		"name":    parquet.Optional(parquet.String()),
+		"score":   parquet.Optional(parquet.Leaf(parquet.DoubleType)),
+		"active":  parquet.Optional(parquet.Leaf(parquet.BooleanType)),
+		"created": parquet.Optional(parquet.Timestamp(parquet.Nanosecond)),
+		"day":     parquet.Optional(parquet.Date()),
+		"note":    parquet.Optional(parquet.String()),
+	})
+	if got := f.Schema(); want.String() != got.String() {
+		t.Fatalf("wrong inferred schema:\nwant: %s\ngot:  %s", want, got)
+	}
+
+	type Row struct {
+		ID      *int64     `parquet:"id,optional"`
+		Name    *string    `parquet:"name,optional"`
+		Score   *float64   `parquet:"score,optional"`
+		Active  *bool      `parquet:"active,optional"`
+		Created *time.Time `parquet:"created,optional,timestamp(nanosecond)"`
+		Day     *int32     `parquet:"day,optional,date"`
+		Note    *string    `parquet:"note,optional"`
+	}
+
+	r := parquet.NewReader(f)
+	got := make([]Row, 3)
+	for i := range got {
+		if err := r.Read(&got[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Read(new(Row)); err != io.EOF {
+		t.Fatalf("expected io.EOF after reading all the rows, got %v", err)
+	}
+
+	if *got[0].ID != 1 || *got[1].Name != "bob" || got[2].Name != nil || *got[1].Score != 2 || *got[1].Active {
+		t.Errorf("wrong values: %+v %+v %+v", got[0], got[1], got[2])
+	}
+	if want := time.Date(2023, 1, 2, 3, 4, 5, 123456789, time.UTC); !got[1].Created.Equal(want) || got[2].Created != nil {
+		t.Errorf("wrong timestamps: %v %v", got[1].Created, got[2].Created)
+	}
+	// Dates are stored as the number of days since the unix epoch.
+	if *got[0].Day != 19359 || *got[1].Day != -1 {
+		t.Errorf("wrong dates: %v %v", *got[0].Day, *got[1].Day)
+	}
+	if got[0].Note != nil || got[1].Note != nil || *got[2].Note != "hello" {
+		t.Errorf("wrong notes: %v %v %v", got[0].Note, got[1].Note, got[2].Note)
+	}
+}
+
+func TestCSVReaderInferSchemaNoHeader(t *testing.T) {
+	// The columns are ordered by name in the inferred schema (column1,
+	// column10, column11, column12, column2, ...), the fields of the records
+	// must be matched with the columns of the same name.
+	const numColumns = 12
+	input := new(strings.Builder)
+	for i := 0; i < 2; i++ {
+		for j := 1; j <= numColumns; j++ {
+			if j > 1 {
+				input.WriteByte(',')
+			}
+			input.WriteString(strconv.Itoa(100*i + j))
+		}
+		input.WriteByte('\n')
+	}
+
+	rows, err := parquet.NewCSVReader(csv.NewReader(strings.NewReader(input.String())), parquet.CSVHeader(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := make([]parquet.Row, 3)
+	n, err := rows.ReadRows(buffer)
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF after reading all the rows, got %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("wrong number of rows: want=2 got=%d", n)
+	}
+
+	for i, row := range buffer[:n] {
+		values, err := rows.Schema().ReconstructMap(row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 1; j <= numColumns; j++ {
+			name := fmt.Sprintf("column%d", j)
+			if want, got := int64(100*i+j), values[name]; got != want {
+				t.Errorf("row %d: wrong value of column %s: want=%d got=%v", i, name, want, got)
+			}
+		}
+	}
+}
+
+func TestCSVReaderConfig(t *testing.T) {
+	// Configuration structs only override the options that they set.
+	const input = "id,note\n1,NULL\n2,a\n"
+	rows, err := parquet.NewCSVReader(csv.NewReader(strings.NewReader(input)),
+		parquet.CSVNullValues("NULL"),
+		&parquet.CSVConfig{InferRows: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := make([]parquet.Row, 2)
+	if _, err := rows.ReadRows(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if note := buffer[0][1]; !note.IsNull() {
+		t.Errorf("the NULL field was not read as a null value: %v", note)
+	}
+	if note := buffer[1][1]; string(note.ByteArray()) != "a" {
+		t.Errorf("wrong value of the second note: %v", note)
+	}
+}
+
+func TestCSVReaderSchema(t *testing.T) {
+	type Row struct {
+		ID    uint32    `parquet:"id"`
+		Name  string    `parquet:"name"`
+		UUID  uuid.UUID `parquet:"uuid"`
+		Time  time.Time `parquet:"time,timestamp(millisecond)"`
+		Ratio float32   `parquet:"ratio"`
+		Note  *string   `parquet:"note,optional"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+	u := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	ts := time.Date(2023, 5, 6, 7, 8, 9, 10e6, time.UTC)
+	note := "note"
+
+	for _, test := range []struct {
+		scenario string
+		input    string
+		options  []parquet.CSVOption
+		want     []Row
+	}{
+		{
+			scenario: "header",
+			// The columns of the header are in a different order, the extra
+			// column is ignored, and the missing optional column is null.
+			input: "extra,name,id,time,uuid,ratio\n" +
+				"x,alice,1,2023-05-06T07:08:09.01Z,6ba7b810-9dad-11d1-80b4-00c04fd430c8,0.5\n" +
+				"y,,2,2023-05-06T07:08:09.01Z,6ba7b810-9dad-11d1-80b4-00c04fd430c8,1\n",
+			options: []parquet.CSVOption{parquet.CSVSchema(schema), parquet.CSVNullValues("NULL")},
+			want: []Row{
+				{ID: 1, Name: "alice", UUID: u, Time: ts, Ratio: 0.5},
+				{ID: 2, Name: "", UUID: u, Time: ts, Ratio: 1},
+			},
+		},
+		{
+			scenario: "no header",
+			// Fields are matched with the columns in the order of the schema.
+			input: "1,alice,6ba7b810-9dad-11d1-80b4-00c04fd430c8,2023-05-06T07:08:09.01Z,0.5,note\n" +
+				"2,bob,6ba7b810-9dad-11d1-80b4-00c04fd430c8,2023-05-06T07:08:09.01Z,1,-\n",
+			options: []parquet.CSVOption{parquet.CSVSchema(schema), parquet.CSVHeader(false), parquet.CSVNullValues("-")},
+			want: []Row{
+				{ID: 1, Name: "alice", UUID: u, Time: ts, Ratio: 0.5, Note: &note},
+				{ID: 2, Name: "bob", UUID: u, Time: ts, Ratio: 1},
+			},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			f := convertCSV(t, test.input, test.options...)
+			r := parquet.NewReader(f)
+			for i, want := range test.want {
+				got := Row{}
+				if err := r.Read(&got); err != nil {
+					t.Fatal(err)
+				}
+				got.Time = got.Time.UTC()
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("row %d mismatch:\nwant: %+v\ngot:  %+v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVReaderErrors(t *testing.T) {
+	type Flat struct {
+		ID   int64   `parquet:"id"`
+		Note *string `parquet:"note,optional"`
+	}
+	type Nested struct {
+		ID   int64    `parquet:"id"`
+		Tags []string `parquet:"tags"`
+	}
+
+	for _, test := range []struct {
+		scenario string
+		input    string
+		options  []parquet.CSVOption
+		message  string
+	}{
+		{
+			scenario: "parse error",
+			input:    "id,note\n1,a\nx,b\n",
+			options:  []parquet.CSVOption{parquet.CSVSchema(parquet.SchemaOf(new(Flat)))},
+			message:  `csv record 3: column "id"`,
+		},
+		{
+			scenario: "null value in required column",
+			input:    "id,note\n,a\n",
+			options:  []parquet.CSVOption{parquet.CSVSchema(parquet.SchemaOf(new(Flat)))},
+			message:  "null value in required column",
+		},
+		{
+			scenario: "missing required column",
+			input:    "note\na\n",
+			options:  []parquet.CSVOption{parquet.CSVSchema(parquet.SchemaOf(new(Flat)))},
+			message:  `required column "id" is missing`,
+		},
+		{
+			scenario: "repeated column",
+			input:    "id,tags\n1,a\n",
+			options:  []parquet.CSVOption{parquet.CSVSchema(parquet.SchemaOf(new(Nested)))},
+			message:  `column "tags" is not a top-level`,
+		},
+		{
+			scenario: "duplicate column name",
+			input:    "id,id\n1,2\n",
+			message:  `duplicate column name "id"`,
+		},
+		{
+			scenario: "empty input",
+			input:    "",
+			message:  "reading csv header",
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			rows, err := parquet.NewCSVReader(csv.NewReader(strings.NewReader(test.input)), test.options...)
+			if err == nil {
+				buffer := make([]parquet.Row, 10)
+				_, err = rows.ReadRows(buffer)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("wrong error: want %q, got %v", test.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package parquet
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// KeyRetriever is an interface implemented by types which retrieve the keys
+// used to decrypt parquet files from the key metadata stored in the files, for
+// example by looking up the keys in a key management system.
+type KeyRetriever interface {
+	// Returns the key identified by the given key metadata. The method may be
+	// called concurrently from multiple goroutines.
+	RetrieveKey(keyMetadata []byte) ([]byte, error)
+}
+
+// KeyRetrieverFunc is an adapter which allows the use of ordinary functions as
+// key retrievers.
+type KeyRetrieverFunc func(keyMetadata []byte) ([]byte, error)
+
+// RetrieveKey calls f(keyMetadata).
+func (f KeyRetrieverFunc) RetrieveKey(keyMetadata []byte) ([]byte, error) { return f(keyMetadata) }
+
+// DecryptionConfig carries the configuration of parquet modular encryption used
+// to read encrypted files.
+//
+// Keys are looked up in the configuration first, and retrieved from the key
+// metadata stored in the file with the key retriever when they are not found.
+type DecryptionConfig struct {
+	// Key used to decrypt the footer, and the columns encrypted with the
+	// footer key.
+	FooterKey []byte
+
+	// Keys of the columns encrypted with their own key, indexed by their path
+	// with the names of the parent groups separated by dots.
+	ColumnKeys map[string][]byte
+
+	// Retrieves the keys which are not set in the configuration.
+	KeyRetriever KeyRetriever
+
+	// Prefix of the additional authenticated data of the file, which must be
+	// set to read files written without storing the prefix. When the file
+	// stores a prefix, it must be equal to this one.
+	AADPrefix []byte
+}
+
+// Validate returns a non-nil error if the configuration of c is invalid.
+func (c *DecryptionConfig) Validate() error {
+	const baseName = "parquet.(*DecryptionConfig)."
+	if c == nil {
+		return nil
+	}
+	if len(c.FooterKey) != 0 && !isValidKeyLength(len(c.FooterKey)) {
+		return errorInvalidOptionValue(baseName+"FooterKey length", len(c.FooterKey))
+	}
+	for path, key := range c.ColumnKeys {
+		if !isValidKeyLength(len(key)) {
+			return errorInvalidOptionValue(fmt.Sprintf("%sColumnKeys[%q] length", baseName, path), len(key))
+		}
+	}
+	return nil
+}
+
+// key returns the configured key, or the key retrieved from the key metadata
+// when it is empty.
+func (c *DecryptionConfig) key(key, keyMetadata []byte) ([]byte, error) {
+	switch {
+	case len(key) != 0:
+		return key, nil
+	case c.KeyRetriever != nil:
+		return c.KeyRetriever.RetrieveKey(keyMetadata)
+	default:
+		return nil, ErrMissingEncryptionKey
+	}
+}
+
+// Decryption is a configuration option which enables reading files encrypted
+// with parquet modular encryption, for example:
+//
+//	parquet.Decryption(&parquet.DecryptionConfig{
+//		KeyRetriever: parquet.KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+//			return kms.Key(string(keyMetadata))
+//		}),
+//	})
+//
+// Both files with encrypted footers and files with plaintext footers can be
+// read. The signature of plaintext footers is verified when the footer key is
+// available. Files with plaintext footers can be opened without this option,
+// in which case only their plaintext columns can be read.
+//
+// Files can be opened when some of the column keys are unavailable, reading
+// these columns returns an error wrapping ErrMissingEncryptionKey, or the error
+// of the key retriever.
+//
+// When passed to a reader constructor which opens the file itself, the option
+// is also applied to the file.
+func Decryption(config *DecryptionConfig) interface {
+	FileOption
+	ReaderOption
+} {
+	return decryptionOption{config}
+}
+
+type decryptionOption struct{ config *DecryptionConfig }
+
+func (opt decryptionOption) ConfigureFile(config *FileConfig) {
+	config.Decryption = opt.config
+}
+
+func (opt decryptionOption) ConfigureReader(config *ReaderConfig) {
+	config.Decryption = opt.config
+}
+
+// fileDecryption holds the state needed to decrypt the modules of a file.
+type fileDecryption struct {
+	config *DecryptionConfig
+	// The AAD prefix followed by the unique identifier of the file.
+	aad []byte
+	// Whether pages are encrypted with AES-CTR.
+	ctr bool
+	// Cipher of the footer key, which is nil if the key was not available
+	// when reading files with plaintext footers, in which case footerErr
+	// reports why.
+	footer    *moduleCipher
+	footerErr error
+	// Ciphers of the column keys, indexed by column path and key metadata.
+	mutex sync.Mutex
+	keys  map[string]keyCipher
+}
+
+type keyCipher struct {
+	cipher *moduleCipher
+	err    error
+}
+
+func newFileDecryption(config *DecryptionConfig, algorithm format.EncryptionAlgorithm, footerKeyMetadata []byte) (*fileDecryption, error) {
+	if config == nil {
+		config = new(DecryptionConfig)
+	}
+	var prefix, fileUnique []byte
+	var supplyPrefix, ctr bool
+	switch {
+	case algorithm.AesGcmV1 != nil:
+		a := algorithm.AesGcmV1
+		prefix, fileUnique, supplyPrefix = a.AadPrefix, a.AadFileUnique, a.SupplyAadPrefix
+	case algorithm.AesGcmCtrV1 != nil:
+		a := algorithm.AesGcmCtrV1
+		prefix, fileUnique, supplyPrefix, ctr = a.AadPrefix, a.AadFileUnique, a.SupplyAadPrefix, true
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm")
+	}
+
+	switch {
+	case len(config.AADPrefix) == 0:
+		if supplyPrefix {
+			return nil, fmt.Errorf("the AAD prefix of the encrypted file must be supplied in the decryption configuration")
+		}
+	case len(prefix) != 0 && !bytes.Equal(prefix, config.AADPrefix):
+		return nil, fmt.Errorf("the AAD prefix of the encrypted file does not match the one of the decryption configuration")
+	default:
+		prefix = config.AADPrefix
+	}
+
+	d := &fileDecryption{config: config, ctr: ctr, keys: make(map[string]keyCipher)}
+	d.aad = append(d.aad, prefix...)
+	d.aad = append(d.aad, fileUnique...)
+
+	key, err := config.key(config.FooterKey, footerKeyMetadata)
+	if err == nil {
+		d.footer, err = newModuleCipher(key, ctr)
+	}
+	if err != nil {
+		d.footerErr = fmt.Errorf("footer key: %w", err)
+	}
+	return d, nil
+}
+
+// decryptFooter returns the file metadata held in the footer of files written
+// in encrypted footer mode, which is made of the crypto metadata of the file
+// followed by the encrypted file metadata.
+func (f *File) decryptFooter(footer []byte) ([]byte, error) {
+	r := bytes.NewReader(footer)
+	cryptoMetadata := format.FileCryptoMetaData{}
+	if err := thrift.NewDecoder(f.protocol.NewReader(r)).Decode(&cryptoMetadata); err != nil {
+		return nil, fmt.Errorf("decoding crypto metadata: %w", err)
+	}
+	d, err := newFileDecryption(f.config.Decryption, cryptoMetadata.EncryptionAlgorithm, cryptoMetadata.KeyMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if d.footerErr != nil {
+		return nil, d.footerErr
+	}
+	metadata, err := d.footer.decrypt(nil, footer[len(footer)-r.Len():], footerModule, moduleAAD(d.aad, footerModule, -1, -1, -1))
+	if err != nil {
+		return nil, fmt.Errorf("decrypting file metadata: %w", err)
+	}
+	f.decryption = d
+	return metadata, nil
+}
+
+// openPlaintextFooter sets up the decryption of files written in plaintext
+// footer mode, which have an encryption algorithm in their file metadata. The
+// file metadata is followed by a signature in the footer, which is verified
+// when the footer key is available.
+func (f *File) openPlaintextFooter(footer, signature []byte) error {
+	algorithm := f.metadata.EncryptionAlgorithm
+	if f.decryption != nil || (algorithm.AesGcmV1 == nil && algorithm.AesGcmCtrV1 == nil) {
+		if len(signature) != 0 {
+			return fmt.Errorf("unexpected trailing bytes at the end of thrift input: %d", len(signature))
+		}
+		return nil
+	}
+	d, err := newFileDecryption(f.config.Decryption, algorithm, f.metadata.FooterSigningKeyMetadata)
+	if err != nil {
+		return err
+	}
+	if d.footer != nil {
+		if err := verifyFooterSignature(d.footer.gcm, d.aad, footer[:len(footer)-len(signature)], signature); err != nil {
+			return err
+		}
+	} else if !errors.Is(d.footerErr, ErrMissingEncryptionKey) {
+		return d.footerErr
+	}
+	f.decryption = d
+	return nil
+}
+
+// verifyFooterSignature verifies the signature of a plaintext footer, made of
+// the nonce and authentication tag of the encryption of the file metadata.
+func verifyFooterSignature(aead cipher.AEAD, fileAAD, metadata, signature []byte) error {
+	if len(signature) != gcmNonceSize+gcmTagSize {
+		return fmt.Errorf("invalid size of plaintext footer signature: %d", len(signature))
+	}
+	nonce, tag := signature[:gcmNonceSize], signature[gcmNonceSize:]
+	sealed := aead.Seal(nil, nonce, metadata, moduleAAD(fileAAD, footerModule, -1, -1, -1))
+	if subtle.ConstantTimeCompare(sealed[len(metadata):], tag) != 1 {
+		return fmt.Errorf("invalid signature of plaintext footer")
+	}
+	return nil
+}
+
+// decryptRowGroup decrypts the metadata of the column chunks of the row group
+// at the given index which are encrypted with their own key. The metadata of
+// columns whose key is unavailable is left untouched.
+func (d *fileDecryption) decryptRowGroup(protocol *thrift.CompactProtocol, rowGroupIndex int, rowGroup *format.RowGroup) error {
+	if d == nil {
+		return nil
+	}
+	for i := range rowGroup.Columns {
+		chunk := &rowGroup.Columns[i]
+		if len(chunk.EncryptedColumnMetadata) == 0 {
+			continue
+		}
+		c := d.column(rowGroupIndex, i, chunk)
+		if c == nil || c.err != nil {
+			continue
+		}
+		metadata, err := c.decrypt(nil, chunk.EncryptedColumnMetadata, columnMetaDataModule, -1)
+		if err != nil {
+			return fmt.Errorf("decrypting metadata of column %d in row group %d: %w", i, rowGroupIndex, err)
+		}
+		chunk.MetaData = format.ColumnMetaData{}
+		if err := thrift.Unmarshal(protocol, metadata, &chunk.MetaData); err != nil {
+			return fmt.Errorf("decoding metadata of column %d in row group %d: %w", i, rowGroupIndex, err)
+		}
+	}
+	return nil
+}
+
+// column returns the decryption state of a column chunk, or nil if the column
+// chunk is not encrypted.
+func (d *fileDecryption) column(rowGroupIndex, columnIndex int, chunk *format.ColumnChunk) *columnDecryption {
+	if d == nil {
+		return nil
+	}
+	c := &columnDecryption{file: d, rowGroup: rowGroupIndex, column: columnIndex}
+	switch crypto := &chunk.CryptoMetadata; {
+	case crypto.EncryptionWithFooterKey != nil:
+		c.cipher, c.err = d.footer, d.footerErr
+	case crypto.EncryptionWithColumnKey != nil:
+		c.cipher, c.err = d.columnKey(crypto.EncryptionWithColumnKey)
+	default:
+		return nil
+	}
+	return c
+}
+
+func (d *fileDecryption) columnKey(k *format.EncryptionWithColumnKey) (*moduleCipher, error) {
+	path := columnPath(k.PathInSchema).String()
+	cacheKey := path + "\x00" + string(k.KeyMetadata)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	c, ok := d.keys[cacheKey]
+	if !ok {
+		key, err := d.config.key(d.config.ColumnKeys[path], k.KeyMetadata)
+		if err == nil {
+			c.cipher, err = newModuleCipher(key, d.ctr)
+		}
+		if err != nil {
+			c.err = fmt.Errorf("key of column %q: %w", path, err)
+		}
+		d.keys[cacheKey] = c
+	}
+	return c.cipher, c.err
+}
+
+// columnDecryption holds the state needed to decrypt the modules of a column
+// chunk.
+type columnDecryption struct {
+	file     *fileDecryption
+	cipher   *moduleCipher
+	err      error
+	rowGroup int
+	column   int
+}
+
+// decrypt appends to dst the decryption of the module of the given type. The
+// page ordinal is only part of the AAD of data pages and their headers, it is
+// negative for other modules.
+func (c *columnDecryption) decrypt(dst, module []byte, moduleType byte, page int) ([]byte, error) {
+	if c.err != nil {
+		return dst, c.err
+	}
+	if c.rowGroup > math.MaxInt16 || c.column > math.MaxInt16 || page > math.MaxInt16 {
+		return dst, fmt.Errorf("module ordinals of encrypted files must not exceed %d", math.MaxInt16)
+	}
+	aad := moduleAAD(c.file.aad, moduleType, int16(c.rowGroup), int16(c.column), int16(page))
+	return c.cipher.decrypt(dst, module, moduleType, aad)
+}
+
+// decryptInPlace is like decrypt but writes the plaintext at the beginning of
+// the memory area of the module, returning it.
+func (c *columnDecryption) decryptInPlace(module []byte, moduleType byte, page int) ([]byte, error) {
+	const offset = moduleLengthSize + gcmNonceSize
+	if len(module) < offset {
+		return nil, errEncryptedModuleTooShort(len(module))
+	}
+	plaintext, err := c.decrypt(module[offset:offset], module, moduleType, page)
+	if err != nil {
+		return nil, err
+	}
+	return module[:copy(module, plaintext)], nil
+}
+
+// readModule reads the length-prefixed module at the current position of r
+// into buffer, returning the module. The module length must not exceed limit.
+func readModule(r io.Reader, buffer []byte, limit int64) ([]byte, error) {
+	var length [moduleLengthSize]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	size := int64(binary.LittleEndian.Uint32(length[:]))
+	if size > limit {
+		return nil, fmt.Errorf("encrypted module of %d bytes exceeds the limit of %d bytes", size, limit)
+	}
+	module := append(buffer[:0], length[:]...)
+	module = append(module, make([]byte, size)...)
+	if _, err := io.ReadFull(r, module[moduleLengthSize:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return module, nil
+}
+
+// decryptModule appends to dst the decryption of the module, which is made of
+// its length, the nonce, the ciphertext, and the authentication tag.
+func decryptModule(dst []byte, aead cipher.AEAD, module, aad []byte) ([]byte, error) {
+	if len(module) < moduleLengthSize+gcmNonceSize+gcmTagSize {
+		return dst, errEncryptedModuleTooShort(len(module))
+	}
+	if length := binary.LittleEndian.Uint32(module); int64(length) != int64(len(module)-moduleLengthSize) {
+		return dst, fmt.Errorf("encrypted module of %d bytes has a length of %d bytes", len(module)-moduleLengthSize, length)
+	}
+	nonce := module[moduleLengthSize : moduleLengthSize+gcmNonceSize]
+	return aead.Open(dst, nonce, module[moduleLengthSize+gcmNonceSize:], aad)
+}
+
+// decryptCTRModule appends to dst the decryption of a module encrypted with
+// AES-CTR, which is made of its length, the nonce, and the ciphertext. The
+// plaintext may be written over the ciphertext when dst is a zero length slice
+// of the module starting at the ciphertext.
+func decryptCTRModule(dst []byte, block cipher.Block, module []byte) ([]byte, error) {
+	if len(module) < moduleLengthSize+ctrNonceSize {
+		return dst, errEncryptedModuleTooShort(len(module))
+	}
+	if length := binary.LittleEndian.Uint32(module); int64(length) != int64(len(module)-moduleLengthSize) {
+		return dst, fmt.Errorf("encrypted module of %d bytes has a length of %d bytes", len(module)-moduleLengthSize, length)
+	}
+	nonce := module[moduleLengthSize : moduleLengthSize+ctrNonceSize]
+	ciphertext := module[moduleLengthSize+ctrNonceSize:]
+	offset := len(dst)
+	// The capacity of dst is not zeroed when it is large enough, since it may
+	// hold the ciphertext.
+	if cap(dst)-offset >= len(ciphertext) {
+		dst = dst[:offset+len(ciphertext)]
+	} else {
+		dst = append(dst, make([]byte, len(ciphertext))...)
+	}
+	cipher.NewCTR(block, ctrIV(nonce)).XORKeyStream(dst[offset:], ciphertext)
+	return dst, nil
+}
+
+func errEncryptedModuleTooShort(size int) error {
+	return fmt.Errorf("encrypted module of %d bytes is too short", size)
+}
@@ -0,0 +1,180 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+)
+
+// DuplicateRowPolicy defines which rows are kept when reading sorted rows with
+// duplicate sorting keys.
+type DuplicateRowPolicy int
+
+const (
+	// KeepDuplicateRows is the default policy, all rows are kept.
+	KeepDuplicateRows DuplicateRowPolicy = iota
+	// KeepFirstRow keeps the first of the rows sharing the same sorting key.
+	KeepFirstRow
+	// KeepLastRow keeps the last of the rows sharing the same sorting key,
+	// which gives upsert semantics to merges where later row groups hold the
+	// most recent versions of the rows.
+	KeepLastRow
+)
+
+// String returns a human-readable representation of p.
+func (p DuplicateRowPolicy) String() string {
+	switch p {
+	case KeepDuplicateRows:
+		return "KeepDuplicateRows"
+	case KeepFirstRow:
+		return "KeepFirstRow"
+	case KeepLastRow:
+		return "KeepLastRow"
+	default:
+		return fmt.Sprintf("DuplicateRowPolicy(%d)", int(p))
+	}
+}
+
+// DropDuplicateRows creates a configuration option which drops rows sharing
+// the same sorting key, keeping only the first or last of them depending on
+// the policy.
+//
+// Two rows share the same sorting key when the values of all their sorting
+// columns compare equal; the option has no effect on row groups which have no
+// sorting columns.
+//
+// When passed to MergeRowGroups, duplicates are dropped while merging, rows of
+// earlier row groups come before the rows of later row groups when their keys
+// are equal. When passed to NewBuffer, duplicates are dropped when reading the
+// rows of the buffer, which includes writing it to a file, and the buffer must
+// have been sorted beforehand.
+//
+// The NumRows method of the row groups reports the number of rows before
+// duplicates are dropped.
+func DropDuplicateRows(policy DuplicateRowPolicy) RowGroupOption {
+	return rowGroupOption(func(config *RowGroupConfig) { config.DuplicateRows = policy })
+}
+
+// dedupRows drops adjacent rows with equal sorting keys from sorted rows.
+//
+// The input is read through a merge cursor, which extracts the values of the
+// sorting columns once per row. The row kept for the current key is cloned into
+// a second cursor, since it may outlive the batch of the input it was read from.
+type dedupRows struct {
+	rows     Rows
+	compare  func(*mergeCursor, *mergeCursor) int
+	keepLast bool
+	started  bool
+	pending  bool
+	input    mergeCursor
+	last     mergeCursor
+	// Memory holding the byte arrays of the pending row, and of the rows
+	// returned by the last call to ReadRows.
+	lastArena []byte
+	arena     []byte
+	seek      int64
+	index     int64
+	err       error
+}
+
+func newDedupRows(rows Rows, columns []mergeSortColumn, policy DuplicateRowPolicy) *dedupRows {
+	numColumns := len(columns)
+	values := make([]Value, 2*numColumns)
+	keys := make([][]Value, 2*numColumns)
+	return &dedupRows{
+		rows:     rows,
+		compare:  mergeCompareFuncOf(columns),
+		keepLast: policy == KeepLastRow,
+		input: mergeCursor{
+			reader:  rows,
+			columns: columns,
+			values:  values[:numColumns:numColumns],
+			keys:    keys[:numColumns:numColumns],
+		},
+		last: mergeCursor{
+			columns: columns,
+			rows:    make([]Row, 1),
+			values:  values[numColumns:],
+			keys:    keys[numColumns:],
+		},
+	}
+}
+
+func (r *dedupRows) ReadRows(rows []Row) (n int, err error) {
+	if !r.started {
+		r.started = true
+		r.err = r.input.next()
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.arena = r.arena[:0]
+
+	for n < len(rows) {
+		if r.input.done() {
+			if !r.pending {
+				break
+			}
+			n = r.emit(rows, n)
+			continue
+		}
+
+		if !r.pending || r.compare(&r.input, &r.last) != 0 {
+			if r.pending {
+				n = r.emit(rows, n)
+			}
+			r.hold(r.input.row())
+		} else if r.keepLast {
+			r.hold(r.input.row())
+		}
+
+		if err := r.input.next(); err != nil {
+			r.err = err
+			return n, err
+		}
+	}
+
+	if n < len(rows) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// hold makes row the pending row, which is produced when a row with a different
+// key is read or the input is exhausted.
+func (r *dedupRows) hold(row Row) {
+	r.last.rows[0], r.lastArena = CloneValues(r.last.rows[0][:0], r.lastArena[:0], row)
+	r.last.load()
+	r.pending = true
+}
+
+// emit copies the pending row to rows[n] unless it is skipped by a previous
+// call to SeekToRow, and returns the updated number of rows.
+func (r *dedupRows) emit(rows []Row, n int) int {
+	if r.index >= r.seek {
+		rows[n], r.arena = CloneValues(rows[n][:0], r.arena, r.last.rows[0])
+		n++
+	}
+	r.index++
+	r.pending = false
+	return n
+}
+
+func (r *dedupRows) SeekToRow(rowIndex int64) error {
+	if rowIndex >= r.index {
+		r.seek = rowIndex
+		return nil
+	}
+	return fmt.Errorf("SeekToRow: deduplicating row reader cannot seek backward from row %d to %d", r.index, rowIndex)
+}
+
+func (r *dedupRows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *dedupRows) Schema() *Schema {
+	return r.rows.Schema()
+}
+
+var (
+	_ Rows = (*dedupRows)(nil)
+)
@@ -0,0 +1,82 @@
+package parquet
+
+import "sort"
+
+// DeleteMask is an interface representing sets of deleted rows, for example as
+// produced by the position delete files of table formats like Iceberg or Delta
+// Lake.
+//
+// When a delete mask is installed on a reader with the DeletedRows option, the
+// rows that it contains are filtered out of the rows returned by the reader.
+type DeleteMask interface {
+	// Returns true if the row at the given index was deleted.
+	IsDeleted(rowIndex int64) bool
+}
+
+// DeletePositions constructs a DeleteMask from a list of positions of deleted
+// rows. The positions must be sorted in ascending order.
+//
+// The returned mask retains the slice passed as argument, the program must not
+// modify it after calling this function.
+func DeletePositions(positions []int64) DeleteMask {
+	return deletePositions(positions)
+}
+
+type deletePositions []int64
+
+func (p deletePositions) IsDeleted(rowIndex int64) bool {
+	i := sort.Search(len(p), func(i int) bool { return p[i] >= rowIndex })
+	return i < len(p) && p[i] == rowIndex
+}
+
+// DeleteBitmap constructs a DeleteMask from a bitmap where the bit at index i
+// is set if the row at index i was deleted. Bits are numbered from the least
+// significant bit of the first word of the bitmap.
+//
+// The returned mask retains the slice passed as argument, the program must not
+// modify it after calling this function.
+func DeleteBitmap(bitmap []uint64) DeleteMask {
+	return deleteBitmap(bitmap)
+}
+
+type deleteBitmap []uint64
+
+func (b deleteBitmap) IsDeleted(rowIndex int64) bool {
+	i, j := rowIndex/64, rowIndex%64
+	return rowIndex >= 0 && i < int64(len(b)) && (b[i]&(1<<uint(j))) != 0
+}
+
+// DeletedRows is a reader configuration option which installs a delete mask to
+// filter out deleted rows when reading.
+//
+// The row indexes in the mask are relative to the beginning of the parquet file
+// for readers created with NewReader and NewGenericReader, and relative to the
+// beginning of the row group for readers created with NewRowGroupReader and
+// NewGenericRowGroupReader. Row indexes passed to SeekToRow and reported by
+// NumRows retain the same meaning, they include the deleted rows.
+func DeletedRows(mask DeleteMask) ReaderOption {
+	return readerOption(func(config *ReaderConfig) { config.DeletedRows = mask })
+}
+
+// removeDeletedRows moves the rows that are not deleted to the front of rows,
+// returning how many there are. The first row is at rowIndex in the mask.
+//
+// Rows are swapped rather than overwritten so the buffers of deleted rows are
+// retained and can be reused by the next read.
+func removeDeletedRows(rows []Row, rowIndex int64, mask DeleteMask) int {
+	n := 0
+	for i := range rows {
+		if !mask.IsDeleted(rowIndex + int64(i)) {
+			rows[n], rows[i] = rows[i], rows[n]
+			n++
+		}
+	}
+	return n
+}
+
+func coalesceDeleteMask(m1, m2 DeleteMask) DeleteMask {
+	if m1 != nil {
+		return m1
+	}
+	return m2
+}
@@ -3,6 +3,7 @@ package deprecated
 import (
 	"math/big"
 	"math/bits"
+	"time"
 	"unsafe"
 )
 
@@ -65,6 +66,45 @@ func (i Int96) Len() int {
 	}
 }
 
+// Julian day number of the unix epoch (1970-01-01).
+const julianDayOfUnixEpoch = 2440588
+
+const nanosPerDay = int64(24 * time.Hour)
+
+// Int96FromTime converts t to its legacy INT96 timestamp representation, as
+// written by Impala, Hive, and Spark.
+//
+// INT96 timestamps store the number of nanoseconds elapsed since midnight in
+// the first 8 bytes, and the Julian day number in the last 4 bytes.
+func Int96FromTime(t time.Time) Int96 {
+	nanos := t.UnixNano()
+	days := nanos / nanosPerDay
+	nanos %= nanosPerDay
+	if nanos < 0 {
+		nanos += nanosPerDay
+		days--
+	}
+	return Int96{
+		0: uint32(uint64(nanos)),
+		1: uint32(uint64(nanos) >> 32),
+		2: uint32(days + julianDayOfUnixEpoch),
+	}
+}
+
+// Time interprets i as a legacy INT96 timestamp and converts it to a time.Time
+// value in UTC.
+func (i Int96) Time() time.Time {
+	return time.Unix(0, i.UnixNano()).UTC()
+}
+
+// UnixNano interprets i as a legacy INT96 timestamp and returns the number of
+// nanoseconds elapsed since the unix epoch.
+func (i Int96) UnixNano() int64 {
+	nanos := int64(uint64(i[1])<<32 | uint64(i[0]))
+	days := int64(i[2]) - julianDayOfUnixEpoch
+	return days*nanosPerDay + nanos
+}
+
 // Int96ToBytes converts the slice of Int96 values to a slice of bytes sharing
 // the same backing array.
 func Int96ToBytes(data []Int96) []byte {
@@ -3,6 +3,7 @@ package deprecated_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/segmentio/parquet-go/deprecated"
 )
@@ -115,3 +116,37 @@ func TestMaxLenInt96(t *testing.T) {
 		})
 	}
 }
+
+func TestInt96Time(t *testing.T) {
+	tests := []struct {
+		i96  deprecated.Int96
+		time time.Time
+	}{
+		{
+			i96:  deprecated.Int96{2: 2440588},
+			time: time.Unix(0, 0).UTC(),
+		},
+
+		{
+			// 2009-02-13T23:31:30.123456789Z
+			i96:  deprecated.Int96{0: 0x74B98115, 1: 0x00004D06, 2: 2454876},
+			time: time.Date(2009, 2, 13, 23, 31, 30, 123456789, time.UTC),
+		},
+
+		{
+			i96:  deprecated.Int96{0: 0x55B43600, 1: 0x00004E94, 2: 2440587},
+			time: time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.time.String(), func(t *testing.T) {
+			if got := test.i96.Time(); !got.Equal(test.time) {
+				t.Errorf("wrong time: want=%v got=%v", test.time, got)
+			}
+			if got := deprecated.Int96FromTime(test.time); got != test.i96 {
+				t.Errorf("wrong int96: want=%v got=%v", test.i96, got)
+			}
+		})
+	}
+}
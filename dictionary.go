@@ -53,7 +53,8 @@ type Dictionary interface {
 	// is negative or greater than the highest index in the dictionary.
 	Lookup(indexes []int32, values []Value)
 
-	// Returns the min and max values found in the given indexes.
+	// Returns the min and max values found in the given indexes. The values
+	// are null if all the values of a floating point dictionary are NaN.
 	Bounds(indexes []int32) (min, max Value)
 
 	// Resets the dictionary to its initial state, removing all values.
@@ -505,9 +506,25 @@ func (d *floatDictionary) Lookup(indexes []int32, values []Value) {
 
 func (d *floatDictionary) Bounds(indexes []int32) (min, max Value) {
 	if len(indexes) > 0 {
-		minValue, maxValue := d.bounds(indexes)
-		min = d.makeValue(minValue)
-		max = d.makeValue(maxValue)
+		var minValue, maxValue float32
+		var ok bool
+		if containsNaNFloat32(d.values) {
+			// NaN values must be skipped, which the vectorized lookup of
+			// the bounds does not do.
+			values := make([]float32, len(indexes))
+			for i, j := range indexes {
+				values[i] = d.index(j)
+			}
+			minValue, maxValue, ok = boundsFloat32NaN(values)
+		} else {
+			minValue, maxValue = d.bounds(indexes)
+			minValue, maxValue = signedZeroBoundsFloat32(minValue, maxValue)
+			ok = true
+		}
+		if ok {
+			min = d.makeValue(minValue)
+			max = d.makeValue(maxValue)
+		}
 	}
 	return min, max
 }
@@ -581,9 +598,25 @@ func (d *doubleDictionary) Lookup(indexes []int32, values []Value) {
 
 func (d *doubleDictionary) Bounds(indexes []int32) (min, max Value) {
 	if len(indexes) > 0 {
-		minValue, maxValue := d.bounds(indexes)
-		min = d.makeValue(minValue)
-		max = d.makeValue(maxValue)
+		var minValue, maxValue float64
+		var ok bool
+		if containsNaNFloat64(d.values) {
+			// NaN values must be skipped, which the vectorized lookup of
+			// the bounds does not do.
+			values := make([]float64, len(indexes))
+			for i, j := range indexes {
+				values[i] = d.index(j)
+			}
+			minValue, maxValue, ok = boundsFloat64NaN(values)
+		} else {
+			minValue, maxValue = d.bounds(indexes)
+			minValue, maxValue = signedZeroBoundsFloat64(minValue, maxValue)
+			ok = true
+		}
+		if ok {
+			min = d.makeValue(minValue)
+			max = d.makeValue(maxValue)
+		}
 	}
 	return min, max
 }
@@ -1109,7 +1142,7 @@ func newIndexedPage(typ *indexedType, columnIndex int16, numValues int32, values
 			copy(tmp, values)
 			values = tmp
 		} else {
-			clear := values[len(values) : len(values)+size]
+			clear := values[len(values):size]
 			for i := range clear {
 				clear[i] = 0
 			}
@@ -1148,10 +1181,13 @@ func (page *indexedPage) Values() ValueReader { return &indexedPageValues{page:
 func (page *indexedPage) Buffer() BufferedPage { return page }
 
 func (page *indexedPage) Bounds() (min, max Value, ok bool) {
-	if ok = len(page.values) > 0; ok {
+	if len(page.values) > 0 {
+		// The bounds are null if all the values are NaN.
 		min, max = page.typ.dict.Bounds(page.values)
-		min.columnIndex = page.columnIndex
-		max.columnIndex = page.columnIndex
+		if ok = !min.IsNull(); ok {
+			min.columnIndex = page.columnIndex
+			max.columnIndex = page.columnIndex
+		}
 	}
 	return min, max, ok
 }
@@ -1244,6 +1280,10 @@ func (col *indexedColumnBuffer) Page() BufferedPage { return &col.indexedPage }
 
 func (col *indexedColumnBuffer) Reset() { col.values = col.values[:0] }
 
+// truncate retains the dictionary values of the removed rows, the indexes of
+// the remaining rows are still valid.
+func (col *indexedColumnBuffer) truncate(n int) { col.values = col.values[:n] }
+
 func (col *indexedColumnBuffer) Cap() int { return cap(col.values) }
 
 func (col *indexedColumnBuffer) Len() int { return len(col.values) }
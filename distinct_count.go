@@ -0,0 +1,105 @@
+package parquet
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/segmentio/parquet-go/hyperloglog"
+)
+
+// Prefix of the keys of the file metadata holding the distinct count sketches
+// of column chunks.
+const distinctCountSketchKeyPrefix = "parquet-go.distinct_count_sketch."
+
+func distinctCountSketchKey(rowGroup, column int) string {
+	return fmt.Sprintf("%s%d.%d", distinctCountSketchKeyPrefix, rowGroup, column)
+}
+
+// DistinctCount carries the number of distinct non-null values of a column
+// chunk, or of a column across the row groups of a file.
+type DistinctCount struct {
+	// Number of distinct values.
+	Count int64
+	// True if the count was recorded in the statistics of a column chunk,
+	// false if it was estimated with HyperLogLog sketches.
+	Exact bool
+}
+
+// DistinctCountOf returns the number of distinct values of the column chunk,
+// which is exact when recorded in the column chunk statistics, or estimated by
+// the sketch written with the DistinctCountSketches writer option otherwise.
+//
+// The boolean is false if the column chunk has neither.
+func DistinctCountOf(chunk ColumnChunk) (DistinctCount, bool, error) {
+	if c, _ := chunk.(*fileColumnChunk); c != nil && c.chunk.MetaData.Statistics.DistinctCount > 0 {
+		return DistinctCount{Count: c.chunk.MetaData.Statistics.DistinctCount, Exact: true}, true, nil
+	}
+	sketch, err := DistinctCountSketchOf(chunk)
+	if sketch == nil || err != nil {
+		return DistinctCount{}, false, err
+	}
+	return DistinctCount{Count: int64(sketch.Estimate())}, true, nil
+}
+
+// DistinctCountSketchOf returns the HyperLogLog sketch of the values of the
+// column chunk written with the DistinctCountSketches writer option. Sketches of
+// the column chunks of a column can be merged to estimate the number of
+// distinct values across row groups.
+//
+// The returned sketch is nil if the column chunk has none, for example if it
+// was not read from a file, or if the file was written without sketches.
+func DistinctCountSketchOf(chunk ColumnChunk) (*hyperloglog.Sketch, error) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return nil, nil
+	}
+	value, ok := c.file.Lookup(distinctCountSketchKey(c.rowGroupIndex, c.Column()))
+	if !ok {
+		return nil, nil
+	}
+	b, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding distinct count sketch of column %q in row group %d: %w", columnPath(c.column.Path()), c.rowGroupIndex, err)
+	}
+	sketch := new(hyperloglog.Sketch)
+	if err := sketch.UnmarshalBinary(b); err != nil {
+		return nil, fmt.Errorf("decoding distinct count sketch of column %q in row group %d: %w", columnPath(c.column.Path()), c.rowGroupIndex, err)
+	}
+	return sketch, nil
+}
+
+// DistinctCount returns the number of distinct values of the leaf column at the
+// given path across all the row groups of f, estimated by merging the sketches
+// of its column chunks. The count is exact if the file has a single row group
+// where the count was recorded in the column chunk statistics.
+//
+// The boolean is false if the column does not exist, or if some of its column
+// chunks have no sketch.
+func (f *File) DistinctCount(path ...string) (DistinctCount, bool, error) {
+	leaf, ok := f.schema.Lookup(path...)
+	if !ok {
+		return DistinctCount{}, false, nil
+	}
+
+	rowGroups := f.RowGroups()
+	if len(rowGroups) == 1 {
+		return DistinctCountOf(rowGroups[0].ColumnChunks()[leaf.ColumnIndex])
+	}
+
+	var merged *hyperloglog.Sketch
+	for _, rowGroup := range rowGroups {
+		sketch, err := DistinctCountSketchOf(rowGroup.ColumnChunks()[leaf.ColumnIndex])
+		if sketch == nil || err != nil {
+			return DistinctCount{}, false, err
+		}
+		if merged == nil {
+			merged = sketch
+		} else if err := merged.Merge(sketch); err != nil {
+			return DistinctCount{}, false, err
+		}
+	}
+	if merged == nil {
+		return DistinctCount{}, false, nil
+	}
+	return DistinctCount{Count: int64(merged.Estimate())}, true, nil
+}
@@ -0,0 +1,125 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestDistinctCountSketches(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Name  string  `parquet:"name"`
+		Color string  `parquet:"color,dict"`
+		Note  *string `parquet:"note,optional"`
+	}
+
+	const numRowGroups = 3
+	const rowsPerGroup = 2000
+
+	write := func(options ...parquet.WriterOption) *parquet.File {
+		b := new(bytes.Buffer)
+		w := parquet.NewWriter(b, append(options, parquet.PageBufferSize(4096))...)
+		for i := 0; i < numRowGroups*rowsPerGroup; i++ {
+			row := Row{
+				ID:    int64(i),
+				Name:  fmt.Sprintf("name-%d", i%500),
+				Color: fmt.Sprintf("color-%d", i%10),
+			}
+			if i%4 == 0 {
+				note := fmt.Sprintf("note-%d", i)
+				row.Note = &note
+			}
+			if err := w.Write(&row); err != nil {
+				t.Fatal(err)
+			}
+			if i%rowsPerGroup == rowsPerGroup-1 {
+				if err := w.Flush(); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	assertEstimate := func(t *testing.T, what string, got parquet.DistinctCount, want int64) {
+		t.Helper()
+		if got.Exact {
+			t.Errorf("%s: the distinct count of %d is reported as exact", what, got.Count)
+		}
+		if math.Abs(float64(got.Count-want)) > 0.05*float64(want) {
+			t.Errorf("%s: estimated %d distinct values instead of %d", what, got.Count, want)
+		}
+	}
+
+	f := write(parquet.DistinctCountSketches(12))
+
+	for i, rowGroup := range f.RowGroups() {
+		count := func(path string) parquet.DistinctCount {
+			t.Helper()
+			chunk, _ := parquet.LookupColumnChunk(rowGroup, path)
+			c, ok, err := parquet.DistinctCountOf(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Fatalf("row group %d: no distinct count for column %q", i, path)
+			}
+			return c
+		}
+
+		if c := count("color"); !c.Exact || c.Count != 10 {
+			t.Errorf("row group %d: wrong distinct count of dictionary encoded column: %+v", i, c)
+		}
+		assertEstimate(t, fmt.Sprintf("row group %d: id", i), count("id"), rowsPerGroup)
+		assertEstimate(t, fmt.Sprintf("row group %d: name", i), count("name"), 500)
+		assertEstimate(t, fmt.Sprintf("row group %d: note", i), count("note"), rowsPerGroup/4)
+	}
+
+	for _, test := range []struct {
+		path string
+		want int64
+	}{
+		{"id", numRowGroups * rowsPerGroup},
+		{"name", 500},
+		{"color", 10},
+		{"note", numRowGroups * rowsPerGroup / 4},
+	} {
+		c, ok, err := f.DistinctCount(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("no distinct count for column %q", test.path)
+		}
+		assertEstimate(t, test.path, c, test.want)
+	}
+
+	if _, ok, _ := f.DistinctCount("missing"); ok {
+		t.Error("distinct count reported for a column that does not exist")
+	}
+
+	// Without the option, the files have neither sketches nor exact counts.
+	f = write()
+	chunk, _ := parquet.LookupColumnChunk(f.RowGroups()[0], "id")
+	if c, ok, err := parquet.DistinctCountOf(chunk); ok || err != nil {
+		t.Errorf("distinct count reported for a file written without sketches: %+v (%v)", c, err)
+	}
+	if _, ok, _ := f.DistinctCount("color"); ok {
+		t.Error("distinct count reported for a file written without sketches")
+	}
+
+	if _, err := parquet.NewWriterConfig(parquet.DistinctCountSketches(3)); err == nil {
+		t.Error("invalid sketch precision was accepted")
+	}
+}
@@ -1,7 +1,10 @@
 package parquet
 
 import (
+	"fmt"
 	"math/bits"
+	"sync"
+	"sync/atomic"
 
 	"github.com/segmentio/parquet-go/encoding"
 	"github.com/segmentio/parquet-go/encoding/bitpacked"
@@ -42,7 +45,7 @@ var (
 	// DeltaByteArray is the delta byte array parquet encoding.
 	DeltaByteArray delta.ByteArrayEncoding
 
-	// ByteStreamSplit is an encoding for floating-point data.
+	// ByteStreamSplit is an encoding for floating-point and fixed-width data.
 	ByteStreamSplit bytestreamsplit.Encoding
 
 	// Table indexing the encodings supported by this package.
@@ -91,17 +94,139 @@ func isDictionaryFormat(encoding format.Encoding) bool {
 	return encoding == format.PlainDictionary || encoding == format.RLEDictionary
 }
 
+var (
+	registeredEncodingsMutex sync.Mutex
+	registeredEncodings      atomic.Value // map[format.Encoding]encoding.Encoding
+)
+
+// RegisterEncoding registers an implementation of a parquet encoding, making it
+// available to read and write parquet files. The encoding is associated with the
+// code returned by its Encoding method.
+//
+// Registering an encoding replaces the implementation previously associated
+// with the same code, including the encodings built into this package. This is
+// intended to plug experimental encodings, or alternative implementations of
+// the standard encodings, without modifying the package.
+//
+// The function is safe to call concurrently, but programs should usually call
+// it during initialization since the encodings of files that are being read or
+// written may not pick up the change.
+func RegisterEncoding(e encoding.Encoding) {
+	registeredEncodingsMutex.Lock()
+	defer registeredEncodingsMutex.Unlock()
+
+	registered, _ := registeredEncodings.Load().(map[format.Encoding]encoding.Encoding)
+	updated := make(map[format.Encoding]encoding.Encoding, len(registered)+1)
+	for code, enc := range registered {
+		updated[code] = enc
+	}
+	updated[e.Encoding()] = e
+	registeredEncodings.Store(updated)
+}
+
 // LookupEncoding returns the parquet encoding associated with the given code.
 //
-// The function never returns nil. If the encoding is not supported,
-// encoding.NotSupported is returned.
+// The function never returns nil. If no encoding was registered for the code,
+// the returned encoding reports errors wrapping encoding.ErrNotSupported which
+// indicate that the encoding was not registered.
 func LookupEncoding(enc format.Encoding) encoding.Encoding {
+	if registered, _ := registeredEncodings.Load().(map[format.Encoding]encoding.Encoding); registered != nil {
+		if e := registered[enc]; e != nil {
+			return e
+		}
+	}
 	if enc >= 0 && int(enc) < len(encodings) {
 		if e := encodings[enc]; e != nil {
 			return e
 		}
 	}
-	return encoding.NotSupported{}
+	return unregisteredEncoding(enc)
+}
+
+type unregisteredEncoding format.Encoding
+
+func (u unregisteredEncoding) String() string {
+	return "UNREGISTERED"
+}
+
+func (u unregisteredEncoding) Encoding() format.Encoding {
+	return format.Encoding(u)
+}
+
+func (u unregisteredEncoding) EncodeLevels(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeBoolean(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeInt32(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeInt64(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeInt96(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeFloat(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeDouble(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeByteArray(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) EncodeFixedLenByteArray(dst, src []byte, size int) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeLevels(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeBoolean(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeInt32(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeInt64(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeInt96(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeFloat(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeDouble(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeByteArray(dst, src []byte) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) DecodeFixedLenByteArray(dst, src []byte, size int) ([]byte, error) {
+	return dst[:0], u.error()
+}
+
+func (u unregisteredEncoding) error() error {
+	return fmt.Errorf("parquet encoding %d is not registered: %w", int32(u), encoding.ErrNotSupported)
 }
 
 func lookupLevelEncoding(enc format.Encoding, max byte) encoding.Encoding {
@@ -116,7 +241,16 @@ func lookupLevelEncoding(enc format.Encoding, max byte) encoding.Encoding {
 	}
 }
 
-func canEncode(e encoding.Encoding, k Kind) bool {
+// CanEncode reports whether e can encode values of kind k.
+//
+// Dictionary encodings can encode values of all kinds, since the values are
+// written to the dictionary page and the data pages only contain indexes.
+//
+// Programs may use this function to validate encoding configurations ahead of
+// writing pages, for example before passing encodings to Encoded or to the
+// PreferredEncodings writer option. Use encoding.CanEncodeLevels to check
+// whether an encoding supports repetition and definition levels.
+func CanEncode(e encoding.Encoding, k Kind) bool {
 	if isDictionaryEncoding(e) {
 		return true
 	}
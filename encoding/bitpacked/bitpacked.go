@@ -35,37 +35,38 @@ func (e *Encoding) wrap(err error) error {
 	return err
 }
 
+// encodeLevels packs values from the most significant bit to the least
+// significant bit of each byte, as required by the deprecated BIT_PACKED
+// encoding, as opposed to the bit-packed runs of the RLE encoding.
+//
+// https://github.com/apache/parquet-format/blob/master/Encodings.md#bit-packed-deprecated-bit_packed--4
 func encodeLevels(dst, src []byte, bitWidth uint) ([]byte, error) {
 	if bitWidth == 0 || len(src) == 0 {
 		return append(dst[:0], 0), nil
 	}
 
 	n := ((int(bitWidth) * len(src)) + 7) / 8
-	c := n + 1
 
-	if cap(dst) < c {
-		dst = make([]byte, c)
+	if cap(dst) < n {
+		dst = make([]byte, n)
 	} else {
-		dst = dst[:c]
+		dst = dst[:n]
 		for i := range dst {
 			dst[i] = 0
 		}
 	}
 
-	bitMask := byte(1<<bitWidth) - 1
-	bitShift := 8 - bitWidth
 	bitOffset := uint(0)
 
 	for _, value := range src {
-		v := bitFlip(value) >> bitShift
-		i := bitOffset / 8
-		j := bitOffset % 8
-		dst[i+0] |= (v & bitMask) << j
-		dst[i+1] |= (v >> (8 - j))
-		bitOffset += bitWidth
+		for b := int(bitWidth) - 1; b >= 0; b-- {
+			bit := (value >> uint(b)) & 1
+			dst[bitOffset/8] |= bit << (7 - (bitOffset % 8))
+			bitOffset++
+		}
 	}
 
-	return dst[:n], nil
+	return dst, nil
 }
 
 func decodeLevels(dst, src []byte, bitWidth uint) ([]byte, error) {
@@ -73,47 +74,25 @@ func decodeLevels(dst, src []byte, bitWidth uint) ([]byte, error) {
 		return append(dst[:0], 0), nil
 	}
 
-	numBits := 8 * uint(len(src))
-	numValues := int(numBits / bitWidth)
-	if (numBits % bitWidth) != 0 {
-		numValues++
-	}
+	numValues := int((8 * uint(len(src))) / bitWidth)
 
 	if cap(dst) < numValues {
 		dst = make([]byte, numValues)
 	} else {
 		dst = dst[:numValues]
-		for i := range dst {
-			dst[i] = 0
-		}
 	}
 
-	bitMask := byte(1<<bitWidth) - 1
-	bitShift := 8 - bitWidth
 	bitOffset := uint(0)
 
 	for k := range dst {
-		i := bitOffset / 8
-		j := bitOffset % 8
-		v := (src[i+0] >> j)
-		if int(i+1) < len(src) {
-			v |= (src[i+1] << (8 - j))
+		v := byte(0)
+		for b := uint(0); b < bitWidth; b++ {
+			bit := (src[bitOffset/8] >> (7 - (bitOffset % 8))) & 1
+			v = (v << 1) | bit
+			bitOffset++
 		}
-		v &= bitMask
-		dst[k] = bitFlip(v) >> bitShift
-		bitOffset += bitWidth
+		dst[k] = v
 	}
 
 	return dst, nil
 }
-
-func bitFlip(b byte) byte {
-	return (((b >> 0) & 1) << 7) |
-		(((b >> 1) & 1) << 6) |
-		(((b >> 2) & 1) << 5) |
-		(((b >> 3) & 1) << 4) |
-		(((b >> 4) & 1) << 3) |
-		(((b >> 5) & 1) << 2) |
-		(((b >> 6) & 1) << 1) |
-		(((b >> 7) & 1) << 0)
-}
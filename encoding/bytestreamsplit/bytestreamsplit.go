@@ -7,6 +7,9 @@ import (
 
 // This encoder implements a version of the Byte Stream Split encoding as described
 // in https://github.com/apache/parquet-format/blob/master/Encodings.md#byte-stream-split-byte_stream_split--9
+//
+// In addition to FLOAT and DOUBLE, the encoding supports the INT32, INT64, and
+// FIXED_LEN_BYTE_ARRAY types which were added in later versions of the spec.
 type Encoding struct {
 	encoding.NotSupported
 }
@@ -19,6 +22,24 @@ func (e *Encoding) Encoding() format.Encoding {
 	return format.ByteStreamSplit
 }
 
+func (e *Encoding) EncodeInt32(dst, src []byte) ([]byte, error) {
+	if (len(src) % 4) != 0 {
+		return dst[:0], encoding.ErrEncodeInvalidInputSize(e, "INT32", len(src))
+	}
+	dst = resize(dst, len(src))
+	encodeFloat(dst, src)
+	return dst, nil
+}
+
+func (e *Encoding) EncodeInt64(dst, src []byte) ([]byte, error) {
+	if (len(src) % 8) != 0 {
+		return dst[:0], encoding.ErrEncodeInvalidInputSize(e, "INT64", len(src))
+	}
+	dst = resize(dst, len(src))
+	encodeDouble(dst, src)
+	return dst, nil
+}
+
 func (e *Encoding) EncodeFloat(dst, src []byte) ([]byte, error) {
 	if (len(src) % 4) != 0 {
 		return dst[:0], encoding.ErrEncodeInvalidInputSize(e, "FLOAT", len(src))
@@ -37,6 +58,36 @@ func (e *Encoding) EncodeDouble(dst, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
+func (e *Encoding) EncodeFixedLenByteArray(dst, src []byte, size int) ([]byte, error) {
+	if size <= 0 || size > encoding.MaxFixedLenByteArraySize {
+		return dst[:0], encoding.Error(e, encoding.ErrInvalidArgument)
+	}
+	if (len(src) % size) != 0 {
+		return dst[:0], encoding.ErrEncodeInvalidInputSize(e, "FIXED_LEN_BYTE_ARRAY", len(src))
+	}
+	dst = resize(dst, len(src))
+	encodeFixedLenByteArray(dst, src, size)
+	return dst, nil
+}
+
+func (e *Encoding) DecodeInt32(dst, src []byte) ([]byte, error) {
+	if (len(src) % 4) != 0 {
+		return dst[:0], encoding.ErrDecodeInvalidInputSize(e, "INT32", len(src))
+	}
+	dst = resize(dst, len(src))
+	decodeFloat(dst, src)
+	return dst, nil
+}
+
+func (e *Encoding) DecodeInt64(dst, src []byte) ([]byte, error) {
+	if (len(src) % 8) != 0 {
+		return dst[:0], encoding.ErrDecodeInvalidInputSize(e, "INT64", len(src))
+	}
+	dst = resize(dst, len(src))
+	decodeDouble(dst, src)
+	return dst, nil
+}
+
 func (e *Encoding) DecodeFloat(dst, src []byte) ([]byte, error) {
 	if (len(src) % 4) != 0 {
 		return dst[:0], encoding.ErrDecodeInvalidInputSize(e, "FLOAT", len(src))
@@ -55,6 +106,54 @@ func (e *Encoding) DecodeDouble(dst, src []byte) ([]byte, error) {
 	return dst, nil
 }
 
+func (e *Encoding) DecodeFixedLenByteArray(dst, src []byte, size int) ([]byte, error) {
+	if size <= 0 || size > encoding.MaxFixedLenByteArraySize {
+		return dst[:0], encoding.Error(e, encoding.ErrInvalidArgument)
+	}
+	if (len(src) % size) != 0 {
+		return dst[:0], encoding.ErrDecodeInvalidInputSize(e, "FIXED_LEN_BYTE_ARRAY", len(src))
+	}
+	dst = resize(dst, len(src))
+	decodeFixedLenByteArray(dst, src, size)
+	return dst, nil
+}
+
+// DecodedSize satisfies the encoding.DecodedSizer interface, the streams hold
+// the same bytes as the decoded values.
+func (e *Encoding) DecodedSize(typ format.Type, size int, src []byte) (int, error) {
+	valueSize := encoding.PlainValueSize(typ, size)
+	if valueSize <= 0 || typ == format.Int96 {
+		return 0, encoding.Errorf(e, "cannot compute the decoded size of %s values: %w", typ, encoding.ErrNotSupported)
+	}
+	if (len(src) % valueSize) != 0 {
+		return 0, encoding.ErrDecodeInvalidInputSize(e, typ.String(), len(src))
+	}
+	return len(src), nil
+}
+
+// encodeFixedLenByteArray writes the k-th byte of each value to the k-th stream.
+// There is no assembly optimization since the size of values is only known at
+// runtime.
+func encodeFixedLenByteArray(dst, src []byte, size int) {
+	n := len(src) / size
+	for k := 0; k < size; k++ {
+		b := dst[k*n : (k+1)*n]
+		for i := range b {
+			b[i] = src[i*size+k]
+		}
+	}
+}
+
+func decodeFixedLenByteArray(dst, src []byte, size int) {
+	n := len(src) / size
+	for k := 0; k < size; k++ {
+		b := src[k*n : (k+1)*n]
+		for i := range b {
+			dst[i*size+k] = b[i]
+		}
+	}
+}
+
 func resize(buf []byte, size int) []byte {
 	if cap(buf) < size {
 		buf = make([]byte, size)
@@ -26,3 +26,11 @@ func TestEncodeFloat(t *testing.T) {
 func TestEncodeDouble(t *testing.T) {
 	test.EncodeDouble(t, new(bytestreamsplit.Encoding), 0, 100)
 }
+
+func TestEncodeInt32(t *testing.T) {
+	test.EncodeInt32(t, new(bytestreamsplit.Encoding), 0, 100, 32)
+}
+
+func TestEncodeInt64(t *testing.T) {
+	test.EncodeInt64(t, new(bytestreamsplit.Encoding), 0, 100, 64)
+}
@@ -0,0 +1,244 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// ChunkEncoder is an interface implemented by incremental encoders, which
+// accept the values of a page in chunks instead of requiring the whole page to
+// be held in memory.
+//
+// ChunkEncoder instances are not safe to use concurrently from multiple
+// goroutines.
+type ChunkEncoder interface {
+	// Encode serializes a chunk of values from src into dst, potentially
+	// reallocating it if it was too short to contain the output.
+	//
+	// As with the methods of the Encoding interface, the values are expected
+	// to be encoded using the PLAIN encoding. Encoders may retain part of the
+	// values until more are written or Flush is called, in which case the
+	// returned buffer may be empty.
+	Encode(dst, src []byte) ([]byte, error)
+
+	// Flush writes the output for the values retained by the encoder to dst,
+	// and resets the encoder so it can be used to encode a new page.
+	Flush(dst []byte) ([]byte, error)
+}
+
+// ChunkDecoder is an interface implemented by incremental decoders, which
+// yield the values of a page in batches of bounded size.
+//
+// The PLAIN encoding and the hybrid RLE/Bit-Packed encodings of INT32 values
+// and dictionary indexes have native incremental decoders; decoders of other
+// encodings decode the whole page at once.
+//
+// ChunkDecoder instances are not safe to use concurrently from multiple
+// goroutines.
+type ChunkDecoder interface {
+	// Reset positions the decoder at the beginning of the page in src.
+	Reset(src []byte)
+
+	// Decode deserializes up to n values into dst, potentially reallocating it
+	// if it was too short to contain the output. The values are written using
+	// the PLAIN encoding.
+	//
+	// The method returns io.EOF when there are no more values to decode.
+	Decode(dst []byte, n int) ([]byte, error)
+}
+
+// ChunkEncoding is an interface implemented by encodings which have native
+// implementations of incremental encoders and decoders.
+//
+// The methods return nil if the encoding does not support incremental encoding
+// or decoding of values of the given type. The size is the length of values of
+// FIXED_LEN_BYTE_ARRAY types, and is ignored for other types.
+type ChunkEncoding interface {
+	NewChunkEncoder(typ format.Type, size int) ChunkEncoder
+	NewChunkDecoder(typ format.Type, size int) ChunkDecoder
+}
+
+// NewChunkEncoder returns an incremental encoder for values of the given type
+// using the encoding e.
+//
+// If e does not implement ChunkEncoding, or does not support incremental
+// encoding of the type, the returned encoder buffers the values until Flush is
+// called and encodes them as a whole page.
+func NewChunkEncoder(e Encoding, typ format.Type, size int) ChunkEncoder {
+	if c, ok := e.(ChunkEncoding); ok {
+		if enc := c.NewChunkEncoder(typ, size); enc != nil {
+			return enc
+		}
+	}
+	return &bufferedChunkEncoder{encoding: e, typ: typ, size: size}
+}
+
+// NewChunkDecoder returns an incremental decoder for values of the given type
+// using the encoding e.
+//
+// If e does not implement ChunkEncoding, or does not support incremental
+// decoding of the type, the returned decoder decodes the whole page when it is
+// reset, and yields the decoded values in batches.
+func NewChunkDecoder(e Encoding, typ format.Type, size int) ChunkDecoder {
+	if c, ok := e.(ChunkEncoding); ok {
+		if dec := c.NewChunkDecoder(typ, size); dec != nil {
+			return dec
+		}
+	}
+	return &bufferedChunkDecoder{encoding: e, typ: typ, size: size}
+}
+
+// PlainValueSize returns the size of PLAIN encoded values of the given type, or
+// zero if the values have variable sizes or are not byte aligned (BYTE_ARRAY and
+// BOOLEAN).
+func PlainValueSize(typ format.Type, size int) int {
+	switch typ {
+	case format.Int32, format.Float:
+		return 4
+	case format.Int64, format.Double:
+		return 8
+	case format.Int96:
+		return 12
+	case format.FixedLenByteArray:
+		return size
+	default:
+		return 0
+	}
+}
+
+// SplitPlainValues returns the length of the prefix of src holding the first n
+// PLAIN encoded values of the given type, and the number of values that it
+// contains, which is less than n if src was too short.
+func SplitPlainValues(src []byte, typ format.Type, size, n int) (length, count int, err error) {
+	switch typ {
+	case format.Boolean:
+		// Boolean values are bit-packed, batches are rounded to whole bytes.
+		count = 8 * min(len(src), (n+7)/8)
+		length = count / 8
+	case format.ByteArray:
+		for count < n && length < len(src) {
+			if len(src)-length < 4 {
+				return length, count, fmt.Errorf("missing length of byte array value at offset %d: %w", length, ErrInvalidArgument)
+			}
+			valueLength := int(binary.LittleEndian.Uint32(src[length:]))
+			if valueLength > len(src)-(length+4) {
+				return length, count, fmt.Errorf("byte array value at offset %d of length %d exceeds the input: %w", length, valueLength, ErrInvalidArgument)
+			}
+			length += 4 + valueLength
+			count++
+		}
+	default:
+		valueSize := PlainValueSize(typ, size)
+		if valueSize <= 0 {
+			return 0, 0, fmt.Errorf("invalid size of values of type %s: %d: %w", typ, valueSize, ErrInvalidArgument)
+		}
+		count = min(len(src)/valueSize, n)
+		length = count * valueSize
+	}
+	return length, count, nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func encodeValues(e Encoding, typ format.Type, size int, dst, src []byte) ([]byte, error) {
+	switch typ {
+	case format.Boolean:
+		return e.EncodeBoolean(dst, src)
+	case format.Int32:
+		return e.EncodeInt32(dst, src)
+	case format.Int64:
+		return e.EncodeInt64(dst, src)
+	case format.Int96:
+		return e.EncodeInt96(dst, src)
+	case format.Float:
+		return e.EncodeFloat(dst, src)
+	case format.Double:
+		return e.EncodeDouble(dst, src)
+	case format.ByteArray:
+		return e.EncodeByteArray(dst, src)
+	case format.FixedLenByteArray:
+		return e.EncodeFixedLenByteArray(dst, src, size)
+	default:
+		return dst[:0], Errorf(e, "cannot encode values of type %s: %w", typ, ErrNotSupported)
+	}
+}
+
+func decodeValues(e Encoding, typ format.Type, size int, dst, src []byte) ([]byte, error) {
+	switch typ {
+	case format.Boolean:
+		return e.DecodeBoolean(dst, src)
+	case format.Int32:
+		return e.DecodeInt32(dst, src)
+	case format.Int64:
+		return e.DecodeInt64(dst, src)
+	case format.Int96:
+		return e.DecodeInt96(dst, src)
+	case format.Float:
+		return e.DecodeFloat(dst, src)
+	case format.Double:
+		return e.DecodeDouble(dst, src)
+	case format.ByteArray:
+		return e.DecodeByteArray(dst, src)
+	case format.FixedLenByteArray:
+		return e.DecodeFixedLenByteArray(dst, src, size)
+	default:
+		return dst[:0], Errorf(e, "cannot decode values of type %s: %w", typ, ErrNotSupported)
+	}
+}
+
+type bufferedChunkEncoder struct {
+	encoding Encoding
+	typ      format.Type
+	size     int
+	values   []byte
+}
+
+func (e *bufferedChunkEncoder) Encode(dst, src []byte) ([]byte, error) {
+	e.values = append(e.values, src...)
+	return dst[:0], nil
+}
+
+func (e *bufferedChunkEncoder) Flush(dst []byte) ([]byte, error) {
+	dst, err := encodeValues(e.encoding, e.typ, e.size, dst, e.values)
+	e.values = e.values[:0]
+	return dst, err
+}
+
+type bufferedChunkDecoder struct {
+	encoding Encoding
+	typ      format.Type
+	size     int
+	src      []byte
+	values   []byte
+	offset   int
+	decoded  bool
+}
+
+func (d *bufferedChunkDecoder) Reset(src []byte) {
+	d.src, d.values, d.offset, d.decoded = src, d.values[:0], 0, false
+}
+
+func (d *bufferedChunkDecoder) Decode(dst []byte, n int) ([]byte, error) {
+	if !d.decoded {
+		values, err := decodeValues(d.encoding, d.typ, d.size, d.values, d.src)
+		if err != nil {
+			return dst[:0], err
+		}
+		d.values, d.decoded = values, true
+	}
+	remain := d.values[d.offset:]
+	if len(remain) == 0 {
+		return dst[:0], io.EOF
+	}
+	length, _, err := SplitPlainValues(remain, d.typ, d.size, n)
+	d.offset += length
+	return append(dst[:0], remain[:length]...), err
+}
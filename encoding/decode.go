@@ -0,0 +1,76 @@
+package encoding
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// DecodedSizer is an interface implemented by encodings which can compute the
+// size of decoded values without decoding them.
+type DecodedSizer interface {
+	// DecodedSize returns the length of the PLAIN encoded output of decoding
+	// the values of the given type from src. The size is the length of values
+	// of FIXED_LEN_BYTE_ARRAY types, and is ignored for other types.
+	//
+	// The method only inspects the parts of the input required to compute the
+	// size, errors in other parts of the input are reported when decoding.
+	DecodedSize(typ format.Type, size int, src []byte) (int, error)
+}
+
+// ShortBufferError is returned by DecodeInto when the destination buffer does
+// not have enough capacity to hold the decoded values.
+//
+// The error wraps io.ErrShortBuffer.
+type ShortBufferError struct {
+	// The capacity required to decode the values.
+	Size int
+}
+
+func (e *ShortBufferError) Error() string {
+	return fmt.Sprintf("decoding requires a buffer of %d bytes: %s", e.Size, io.ErrShortBuffer)
+}
+
+func (e *ShortBufferError) Unwrap() error { return io.ErrShortBuffer }
+
+// DecodedSize returns the length of the PLAIN encoded output of decoding values
+// of the given type from src with the encoding e.
+//
+// The function returns an error wrapping ErrNotSupported if e does not
+// implement DecodedSizer.
+func DecodedSize(e Encoding, typ format.Type, size int, src []byte) (int, error) {
+	if s, ok := e.(DecodedSizer); ok {
+		return s.DecodedSize(typ, size, src)
+	}
+	return 0, Errorf(e, "cannot compute the decoded size of %s values: %w", typ, ErrNotSupported)
+}
+
+// DecodeInto decodes values of the given type from src into dst, never growing
+// dst beyond its capacity. If dst is too short to hold the decoded values, the
+// function returns a *ShortBufferError indicating the capacity required.
+//
+// When e implements DecodedSizer, the size is computed before decoding, and
+// the function does not allocate memory if the capacity of dst is too short.
+// Otherwise, the values are decoded before determining whether they fit in
+// the buffer, in which case the decoder may have allocated a larger buffer
+// which gets discarded.
+func DecodeInto(e Encoding, typ format.Type, size int, dst, src []byte) ([]byte, error) {
+	if s, ok := e.(DecodedSizer); ok {
+		n, err := s.DecodedSize(typ, size, src)
+		if err != nil {
+			return dst[:0], err
+		}
+		if n > cap(dst) {
+			return dst[:0], &ShortBufferError{Size: n}
+		}
+	}
+	out, err := decodeValues(e, typ, size, dst[:0], src)
+	if err != nil {
+		return dst[:0], err
+	}
+	if cap(out) > 0 && (cap(dst) == 0 || &out[:1][0] != &dst[:1][0]) {
+		return dst[:0], &ShortBufferError{Size: len(out)}
+	}
+	return out, nil
+}
@@ -49,6 +49,25 @@ func (e *BinaryPackedEncoding) DecodeInt64(dst, src []byte) ([]byte, error) {
 	return dst, e.wrap(err)
 }
 
+// DecodedSize satisfies the encoding.DecodedSizer interface, the number of
+// values is read from the header of the input.
+func (e *BinaryPackedEncoding) DecodedSize(typ format.Type, size int, src []byte) (int, error) {
+	valueSize := 0
+	switch typ {
+	case format.Int32:
+		valueSize = 4
+	case format.Int64:
+		valueSize = 8
+	default:
+		return 0, encoding.Errorf(e, "cannot compute the decoded size of %s values: %w", typ, encoding.ErrNotSupported)
+	}
+	_, _, totalValues, _, _, err := decodeBinaryPackedHeader(src)
+	if err != nil {
+		return 0, e.wrap(err)
+	}
+	return valueSize * totalValues, nil
+}
+
 func (e *BinaryPackedEncoding) wrap(err error) error {
 	if err != nil {
 		err = encoding.Error(e, err)
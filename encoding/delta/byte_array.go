@@ -16,6 +16,14 @@ const (
 
 type ByteArrayEncoding struct {
 	encoding.NotSupported
+	// MaxPrefixLength limits the length of prefixes shared with the previous
+	// values, bounding the number of bytes compared when encoding long values.
+	// Zero means no limit.
+	MaxPrefixLength int
+	// MinPrefixLength is the length below which prefixes shared with the
+	// previous values are not reused, and the values are written as a whole
+	// in the suffixes instead. Zero means prefixes of any length are reused.
+	MinPrefixLength int
 }
 
 func (e *ByteArrayEncoding) String() string {
@@ -52,13 +60,16 @@ func (e *ByteArrayEncoding) EncodeByteArray(dst, src []byte) ([]byte, error) {
 		}
 		v := src[i : i+n : i+n]
 		p := 0
+		b := e.prefixBase(lastValue)
 
 		if len(v) <= maxLinearSearchPrefixLength {
-			p = linearSearchPrefixLength(lastValue, v)
+			p = linearSearchPrefixLength(b, v)
 		} else {
-			p = binarySearchPrefixLength(lastValue, v)
+			p = binarySearchPrefixLength(b, v)
 		}
 
+		p = e.prefixLength(p)
+
 		prefix.values = append(prefix.values, int32(p))
 		length.values = append(length.values, int32(n-p))
 		lastValue = v
@@ -110,7 +121,7 @@ func (e *ByteArrayEncoding) EncodeFixedLenByteArray(dst, src []byte, size int) (
 
 	for i := size; i <= len(src); i += size {
 		v := src[i-size : i : i]
-		p := linearSearchPrefixLength(lastValue, v)
+		p := e.prefixLength(linearSearchPrefixLength(e.prefixBase(lastValue), v))
 		n := size - p
 		prefix.values = append(prefix.values, int32(p))
 		length.values = append(length.values, int32(n))
@@ -187,6 +198,20 @@ func (e *ByteArrayEncoding) DecodeFixedLenByteArray(dst, src []byte, size int) (
 	return decodeFixedLenByteArray(dst, src, size, prefix.values, suffix.values)
 }
 
+func (e *ByteArrayEncoding) prefixBase(lastValue []byte) []byte {
+	if e.MaxPrefixLength > 0 && len(lastValue) > e.MaxPrefixLength {
+		lastValue = lastValue[:e.MaxPrefixLength]
+	}
+	return lastValue
+}
+
+func (e *ByteArrayEncoding) prefixLength(p int) int {
+	if p < e.MinPrefixLength {
+		p = 0
+	}
+	return p
+}
+
 func linearSearchPrefixLength(base, data []byte) (n int) {
 	for n < len(base) && n < len(data) && base[n] == data[n] {
 		n++
@@ -2,7 +2,10 @@ package delta
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -129,6 +132,78 @@ func testSearchPrefixLength(t *testing.T, prefixLength func(base, data []byte) i
 	}
 }
 
+func TestByteArrayEncodingPrefixLength(t *testing.T) {
+	values := []string{
+		"https://example.com/",
+		"https://example.com/a",
+		"https://example.com/a/b",
+		"https://example.org/",
+		strings.Repeat("https://example.com/path/", 10),
+		strings.Repeat("https://example.com/path/", 10) + "?q=1",
+	}
+
+	plain := make([]byte, 0, 1024)
+	for _, v := range values {
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(v)))
+		plain = append(plain, length[:]...)
+		plain = append(plain, v...)
+	}
+
+	tests := []struct {
+		scenario string
+		encoding ByteArrayEncoding
+		prefixes []int32
+	}{
+		{
+			scenario: "default",
+			prefixes: []int32{0, 20, 21, 16, 16, 250},
+		},
+		{
+			scenario: "max prefix length",
+			encoding: ByteArrayEncoding{MaxPrefixLength: 20},
+			prefixes: []int32{0, 20, 20, 16, 16, 20},
+		},
+		{
+			scenario: "min prefix length",
+			encoding: ByteArrayEncoding{MinPrefixLength: 20},
+			prefixes: []int32{0, 20, 21, 0, 0, 250},
+		},
+		{
+			scenario: "no prefixes",
+			encoding: ByteArrayEncoding{MaxPrefixLength: 1, MinPrefixLength: 2},
+			prefixes: []int32{0, 0, 0, 0, 0, 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			encoded, err := test.encoding.EncodeByteArray(nil, plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prefix := getInt32Buffer()
+			defer putInt32Buffer(prefix)
+
+			if _, err := prefix.decode(encoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(prefix.values, test.prefixes) {
+				t.Errorf("wrong prefix lengths:\nwant = %v\ngot  = %v", test.prefixes, prefix.values)
+			}
+
+			decoded, err := test.encoding.DecodeByteArray(nil, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decoded, plain) {
+				t.Error("decoded values mismatch")
+			}
+		})
+	}
+}
+
 func BenchmarkLinearSearchPrefixLength(b *testing.B) {
 	benchmarkSearchPrefixLength(b, linearSearchPrefixLength)
 }
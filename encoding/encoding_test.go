@@ -3,6 +3,7 @@ package encoding_test
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
 	"math"
 	"math/bits"
@@ -17,6 +18,7 @@ import (
 	"github.com/segmentio/parquet-go/encoding/delta"
 	"github.com/segmentio/parquet-go/encoding/plain"
 	"github.com/segmentio/parquet-go/encoding/rle"
+	"github.com/segmentio/parquet-go/format"
 	"github.com/segmentio/parquet-go/internal/unsafecast"
 )
 
@@ -498,6 +500,145 @@ func testCanEncode(t testing.TB, e encoding.Encoding, test func(encoding.Encodin
 	}
 }
 
+func TestChunkEncoding(t *testing.T) {
+	int32Values := []byte{}
+	byteArrayValues := []byte{}
+	fixedLenByteArrayValues := []byte{}
+	for i := 0; i < 100; i++ {
+		int32Values = plain.AppendInt32(int32Values, int32(i))
+		byteArrayValues = plain.AppendByteArray(byteArrayValues, bytes.Repeat([]byte("A"), i%10))
+		fixedLenByteArrayValues = append(fixedLenByteArrayValues, byte(i), byte(i>>1), byte(i>>2))
+	}
+
+	for _, test := range [...]struct {
+		scenario  string
+		typ       format.Type
+		size      int
+		values    []byte
+		canEncode func(encoding.Encoding) bool
+	}{
+		{"int32", format.Int32, 0, int32Values, encoding.CanEncodeInt32},
+		{"byte array", format.ByteArray, 0, byteArrayValues, encoding.CanEncodeByteArray},
+		{"fixed length byte array", format.FixedLenByteArray, 3, fixedLenByteArrayValues, encoding.CanEncodeFixedLenByteArray},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			for _, e := range encodings {
+				t.Run(e.String(), func(t *testing.T) {
+					testCanEncode(t, e, test.canEncode)
+					setBitWidth(e, 8)
+					testChunkEncoding(t, e, test.typ, test.size, test.values)
+				})
+			}
+		})
+	}
+}
+
+func testChunkEncoding(t *testing.T, e encoding.Encoding, typ format.Type, size int, values []byte) {
+	const chunkSize = 7
+	var err error
+	var buffer []byte
+	var encoded []byte
+
+	enc := encoding.NewChunkEncoder(e, typ, size)
+	for offset := 0; offset < len(values); {
+		length, _, err := encoding.SplitPlainValues(values[offset:], typ, size, chunkSize)
+		assertNoError(t, err)
+		buffer, err = enc.Encode(buffer, values[offset:offset+length])
+		assertNoError(t, err)
+		encoded = append(encoded, buffer...)
+		offset += length
+	}
+	buffer, err = enc.Flush(buffer)
+	assertNoError(t, err)
+	encoded = append(encoded, buffer...)
+
+	dec := encoding.NewChunkDecoder(e, typ, size)
+	dec.Reset(encoded)
+	decoded := []byte{}
+	for {
+		buffer, err = dec.Decode(buffer, chunkSize)
+		if err == io.EOF {
+			break
+		}
+		assertNoError(t, err)
+		if _, count, _ := encoding.SplitPlainValues(buffer, typ, size, math.MaxInt32); count > chunkSize {
+			t.Fatalf("too many values decoded: %d > %d", count, chunkSize)
+		}
+		decoded = append(decoded, buffer...)
+	}
+	assertBytesEqual(t, values, decoded)
+}
+
+func TestDecodeInto(t *testing.T) {
+	int32Values := []byte{}
+	int64Values := []byte{}
+	for i := 0; i < 100; i++ {
+		int32Values = plain.AppendInt32(int32Values, int32(i))
+		int64Values = plain.AppendInt64(int64Values, int64(i))
+	}
+
+	for _, test := range [...]struct {
+		scenario  string
+		typ       format.Type
+		values    []byte
+		canEncode func(encoding.Encoding) bool
+	}{
+		{"int32", format.Int32, int32Values, encoding.CanEncodeInt32},
+		{"int64", format.Int64, int64Values, encoding.CanEncodeInt64},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			for _, e := range encodings {
+				t.Run(e.String(), func(t *testing.T) {
+					testCanEncode(t, e, test.canEncode)
+					setBitWidth(e, 8)
+					testDecodeInto(t, e, test.typ, test.values)
+				})
+			}
+		})
+	}
+}
+
+func testDecodeInto(t *testing.T, e encoding.Encoding, typ format.Type, values []byte) {
+	var encoded []byte
+	var err error
+	switch typ {
+	case format.Int32:
+		encoded, err = e.EncodeInt32(nil, values)
+	case format.Int64:
+		encoded, err = e.EncodeInt64(nil, values)
+	}
+	assertNoError(t, err)
+
+	_, err = encoding.DecodeInto(e, typ, 0, make([]byte, 0, 1), encoded)
+	shortBuffer, ok := err.(*encoding.ShortBufferError)
+	if !ok {
+		t.Fatalf("expected a short buffer error but got %v", err)
+	}
+	if !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("short buffer error does not wrap io.ErrShortBuffer: %v", err)
+	}
+	if shortBuffer.Size < len(values) {
+		t.Fatalf("required buffer size is too short: %d < %d", shortBuffer.Size, len(values))
+	}
+
+	buffer := make([]byte, 0, shortBuffer.Size)
+	decoded, err := encoding.DecodeInto(e, typ, 0, buffer, encoded)
+	assertNoError(t, err)
+	// Run-length encodings may decode padding values beyond the end of the
+	// page.
+	assertBytesEqual(t, values, decoded[:len(values)])
+
+	if _, ok := e.(encoding.DecodedSizer); ok {
+		allocs := testing.AllocsPerRun(10, func() {
+			decoded, err = encoding.DecodeInto(e, typ, 0, buffer, encoded)
+		})
+		assertNoError(t, err)
+		if allocs != 0 {
+			t.Errorf("too many memory allocations: %g", allocs)
+		}
+	}
+}
+
 func assertNoError(t *testing.T, err error) {
 	t.Helper()
 	if err != nil {
@@ -59,7 +59,7 @@ func CanEncodeBoolean(e Encoding) bool {
 	return !errors.Is(err, ErrNotSupported)
 }
 
-// CanEncodeInt8 reports whether e can encode LEVELS values.
+// CanEncodeLevels reports whether e can encode LEVELS values.
 func CanEncodeLevels(e Encoding) bool {
 	_, err := e.EncodeLevels(nil, nil)
 	return !errors.Is(err, ErrNotSupported)
@@ -0,0 +1,77 @@
+package plain
+
+import (
+	"io"
+	"math"
+
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// NewChunkEncoder satisfies the encoding.ChunkEncoding interface.
+//
+// Since the PLAIN encoding is the identity of the input format, chunks are
+// encoded as soon as they are written and the encoder never retains values.
+// BOOLEAN values are not supported since chunks may not be byte aligned.
+func (e *Encoding) NewChunkEncoder(typ format.Type, size int) encoding.ChunkEncoder {
+	if typ == format.Boolean {
+		return nil
+	}
+	return &chunkEncoder{encoding: e, typ: typ, size: size}
+}
+
+// NewChunkDecoder satisfies the encoding.ChunkEncoding interface.
+func (e *Encoding) NewChunkDecoder(typ format.Type, size int) encoding.ChunkDecoder {
+	if typ == format.Boolean {
+		return nil
+	}
+	return &chunkDecoder{encoding: e, typ: typ, size: size}
+}
+
+type chunkEncoder struct {
+	encoding *Encoding
+	typ      format.Type
+	size     int
+}
+
+func (e *chunkEncoder) Encode(dst, src []byte) ([]byte, error) {
+	length, _, err := encoding.SplitPlainValues(src, e.typ, e.size, math.MaxInt32)
+	if err != nil {
+		return dst[:0], encoding.Error(e.encoding, err)
+	}
+	if length != len(src) {
+		return dst[:0], encoding.ErrEncodeInvalidInputSize(e.encoding, e.typ.String(), len(src))
+	}
+	return append(dst[:0], src...), nil
+}
+
+func (e *chunkEncoder) Flush(dst []byte) ([]byte, error) {
+	return dst[:0], nil
+}
+
+type chunkDecoder struct {
+	encoding *Encoding
+	typ      format.Type
+	size     int
+	src      []byte
+}
+
+func (d *chunkDecoder) Reset(src []byte) {
+	d.src = src
+}
+
+func (d *chunkDecoder) Decode(dst []byte, n int) ([]byte, error) {
+	if len(d.src) == 0 {
+		return dst[:0], io.EOF
+	}
+	length, _, err := encoding.SplitPlainValues(d.src, d.typ, d.size, n)
+	if err != nil {
+		return dst[:0], encoding.Error(d.encoding, err)
+	}
+	if length == 0 && n > 0 {
+		return dst[:0], encoding.ErrDecodeInvalidInputSize(d.encoding, d.typ.String(), len(d.src))
+	}
+	dst = append(dst[:0], d.src[:length]...)
+	d.src = d.src[length:]
+	return dst, nil
+}
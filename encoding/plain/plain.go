@@ -140,6 +140,16 @@ func (e *Encoding) DecodeFixedLenByteArray(dst, src []byte, size int) ([]byte, e
 	return append(dst[:0], src...), nil
 }
 
+// DecodedSize satisfies the encoding.DecodedSizer interface, PLAIN encoded
+// values have the same size as their decoded representation.
+func (e *Encoding) DecodedSize(typ format.Type, size int, src []byte) (int, error) {
+	valueSize := encoding.PlainValueSize(typ, size)
+	if valueSize > 0 && (len(src)%valueSize) != 0 {
+		return 0, encoding.ErrDecodeInvalidInputSize(e, typ.String(), len(src))
+	}
+	return len(src), nil
+}
+
 func Boolean(v bool) []byte { return AppendBoolean(nil, 0, v) }
 
 func Int32(v int32) []byte { return AppendInt32(nil, v) }
@@ -285,6 +295,56 @@ func NextByteArray(b []byte) (v, r []byte, err error) {
 	return b[ByteArrayLengthSize:n:n], b[n:len(b):len(b)], nil
 }
 
+// DecodeByteArrayOffsets decodes the PLAIN encoded BYTE_ARRAY values of src
+// into a sequence of offsets and the concatenation of the values, which is the
+// layout used by Arrow binary arrays. The value at index i is held in
+// data[offsets[i]:offsets[i+1]].
+//
+// The offsets and data buffers are truncated and reused if they have enough
+// capacity. The returned offsets always start with zero and have one more
+// element than the number of values.
+func DecodeByteArrayOffsets(offsets []int32, data, src []byte) ([]int32, []byte, error) {
+	if len(src) > math.MaxInt32 {
+		return offsets[:0], data[:0], ErrTooLarge(len(src))
+	}
+	if cap(data) < len(src) {
+		// The length prefixes are removed so the values always fit within the
+		// length of the input.
+		data = make([]byte, 0, len(src))
+	}
+	offsets, data = append(offsets[:0], 0), data[:0]
+
+	for i := 0; i < len(src); {
+		if (len(src) - i) < ByteArrayLengthSize {
+			return offsets, data, ErrTooShort(len(src) - i)
+		}
+		n := ByteArrayLength(src[i:])
+		i += ByteArrayLengthSize
+		if n > (len(src) - i) {
+			return offsets, data, ErrTooShort(len(src) - i)
+		}
+		data = append(data, src[i:i+n]...)
+		offsets = append(offsets, int32(len(data)))
+		i += n
+	}
+
+	return offsets, data, nil
+}
+
+// AppendByteArrayOffsets appends the PLAIN encoding of the BYTE_ARRAY values
+// held in the offsets and data pair to b, which is the inverse operation of
+// DecodeByteArrayOffsets.
+func AppendByteArrayOffsets(b []byte, offsets []int32, data []byte) ([]byte, error) {
+	for i := 1; i < len(offsets); i++ {
+		j, k := offsets[i-1], offsets[i]
+		if j < 0 || j > k || int(k) > len(data) {
+			return b, fmt.Errorf("invalid offsets of byte array value at index %d: [%d:%d]", i-1, j, k)
+		}
+		b = AppendByteArray(b, data[j:k])
+	}
+	return b, nil
+}
+
 func ErrTooShort(length int) error {
 	return fmt.Errorf("input of length %d is too short to contain a PLAIN encoded byte array value: %w", length, io.ErrUnexpectedEOF)
 }
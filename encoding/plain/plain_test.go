@@ -2,6 +2,7 @@ package plain_test
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/segmentio/parquet-go/encoding/plain"
@@ -56,3 +57,34 @@ func TestValidateByteArray(t *testing.T) {
 		}
 	})
 }
+
+func TestDecodeByteArrayOffsets(t *testing.T) {
+	var src []byte
+	src = plain.AppendByteArrayString(src, "Hello")
+	src = plain.AppendByteArrayString(src, "")
+	src = plain.AppendByteArrayString(src, "World")
+	src = plain.AppendByteArrayString(src, "!")
+
+	offsets, data, err := plain.DecodeByteArrayOffsets(nil, nil, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{0, 5, 5, 10, 11}; !reflect.DeepEqual(offsets, want) {
+		t.Errorf("wrong offsets: want=%v got=%v", want, offsets)
+	}
+	if want := "HelloWorld!"; string(data) != want {
+		t.Errorf("wrong data: want=%q got=%q", want, data)
+	}
+
+	b, err := plain.AppendByteArrayOffsets(nil, offsets, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, src) {
+		t.Errorf("wrong encoding of offsets:\nwant = %q\ngot  = %q", src, b)
+	}
+
+	if _, _, err := plain.DecodeByteArrayOffsets(offsets, data, src[:len(src)-1]); err == nil {
+		t.Error("expected non-nil error")
+	}
+}
@@ -0,0 +1,192 @@
+package rle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/format"
+	"github.com/segmentio/parquet-go/internal/bitpack"
+	"github.com/segmentio/parquet-go/internal/unsafecast"
+)
+
+// NewChunkEncoder satisfies the encoding.ChunkEncoding interface.
+//
+// The encoding does not have an incremental encoder, the method always returns
+// nil.
+func (e *Encoding) NewChunkEncoder(typ format.Type, size int) encoding.ChunkEncoder {
+	return nil
+}
+
+// NewChunkDecoder satisfies the encoding.ChunkEncoding interface.
+//
+// Runs of INT32 values are decoded incrementally, other types are not
+// supported.
+func (e *Encoding) NewChunkDecoder(typ format.Type, size int) encoding.ChunkDecoder {
+	if typ != format.Int32 {
+		return nil
+	}
+	return &int32ChunkDecoder{encoding: e, bitWidth: uint(e.BitWidth)}
+}
+
+// NewChunkEncoder satisfies the encoding.ChunkEncoding interface.
+//
+// The bit width of the dictionary indexes is written before the runs, so the
+// indexes cannot be encoded until all of them are known; the method always
+// returns nil.
+func (e *DictionaryEncoding) NewChunkEncoder(typ format.Type, size int) encoding.ChunkEncoder {
+	return nil
+}
+
+// NewChunkDecoder satisfies the encoding.ChunkEncoding interface.
+//
+// The dictionary indexes are decoded incrementally, which lets programs map
+// them to the dictionary values in batches instead of holding the indexes of
+// the whole page in memory.
+func (e *DictionaryEncoding) NewChunkDecoder(typ format.Type, size int) encoding.ChunkDecoder {
+	if typ != format.Int32 {
+		return nil
+	}
+	return &int32ChunkDecoder{encoding: e, bitWidthPrefix: true}
+}
+
+// int32ChunkDecoder decodes runs of INT32 values encoded with the hybrid
+// RLE/Bit-Packed encoding, the bit width of the values is either fixed or read
+// from the first byte of the input.
+type int32ChunkDecoder struct {
+	encoding       encoding.Encoding
+	bitWidthPrefix bool
+	bitWidth       uint
+	src            []byte
+	err            error
+	// Number of values remaining in the current run, including the values
+	// of the last bit-packed group that were already decoded.
+	count     uint
+	value     [4]byte
+	bitpacked bool
+	// Buffer holding a decoded group of bit-packed values, with offset being
+	// the index of the next value to return.
+	group  [8]int32
+	offset int
+	// Buffer used to pad the input of bit-packed groups.
+	buffer []byte
+}
+
+func (d *int32ChunkDecoder) Reset(src []byte) {
+	d.src, d.err, d.count, d.bitpacked, d.offset = src, nil, 0, false, len(d.group)
+	if d.bitWidthPrefix {
+		d.bitWidth = 0
+		if len(src) > 0 {
+			d.bitWidth, d.src = uint(src[0]), src[1:]
+		}
+	}
+	if d.bitWidth > 32 {
+		d.err = encoding.Error(d.encoding, errDecodeInvalidBitWidth("INT32", d.bitWidth))
+	}
+}
+
+func (d *int32ChunkDecoder) Decode(dst []byte, n int) ([]byte, error) {
+	dst = dst[:0]
+	if d.err != nil {
+		return dst, d.err
+	}
+
+	for remain := n; remain > 0; remain = n - len(dst)/4 {
+		if d.count == 0 {
+			if len(d.src) == 0 {
+				break
+			}
+			if err := d.decodeHeader(); err != nil {
+				d.err = encoding.Error(d.encoding, err)
+				return dst, d.err
+			}
+			continue
+		}
+
+		k := uint(remain)
+		if k > d.count {
+			k = d.count
+		}
+
+		if !d.bitpacked {
+			dst = appendRepeat(dst, d.value[:], k)
+			d.count -= k
+			continue
+		}
+
+		if d.offset < len(d.group) {
+			if m := uint(len(d.group) - d.offset); k > m {
+				k = m
+			}
+			dst = append(dst, unsafecast.Int32ToBytes(d.group[d.offset:d.offset+int(k)])...)
+			d.offset += int(k)
+			d.count -= k
+			continue
+		}
+
+		if groups := k / 8; groups > 0 {
+			// Whole groups are unpacked directly into the output buffer.
+			length := int(groups * d.bitWidth)
+			offset := len(dst)
+			dst = resize(dst, offset+4*8*int(groups))
+			bitpack.UnpackInt32(unsafecast.BytesToInt32(dst[offset:]), d.pad(d.src[:length]), d.bitWidth)
+			d.src = d.src[length:]
+			d.count -= 8 * groups
+		} else {
+			bitpack.UnpackInt32(d.group[:], d.pad(d.src[:d.bitWidth]), d.bitWidth)
+			d.src = d.src[d.bitWidth:]
+			d.offset = 0
+		}
+	}
+
+	if len(dst) == 0 && n > 0 {
+		return dst, io.EOF
+	}
+	return dst, nil
+}
+
+// pad returns a view of the bit-packed input in which can be read past the end
+// of the input, as required by the bitpack.UnpackInt32 function.
+func (d *int32ChunkDecoder) pad(in []byte) []byte {
+	if (cap(in) - len(in)) >= bitpack.PaddingInt32 {
+		return in[:cap(in)]
+	}
+	d.buffer = resize(d.buffer, len(in)+bitpack.PaddingInt32)
+	copy(d.buffer, in)
+	return d.buffer
+}
+
+func (d *int32ChunkDecoder) decodeHeader() error {
+	u, n := binary.Uvarint(d.src)
+	if n == 0 {
+		return fmt.Errorf("decoding run-length block header: %w", io.ErrUnexpectedEOF)
+	}
+	if n < 0 {
+		return fmt.Errorf("overflow after decoding %d/%d bytes of run-length block header", -n, len(d.src))
+	}
+	d.src = d.src[n:]
+
+	count, bitpacked := uint(u>>1), (u&1) != 0
+	if count > maxSupportedValueCount {
+		return fmt.Errorf("decoded run-length block cannot have more than %d values", maxSupportedValueCount)
+	}
+
+	if bitpacked {
+		if size := count * d.bitWidth; size > uint(len(d.src)) {
+			return fmt.Errorf("decoding bit-packed block of %d values: %w", 8*count, io.ErrUnexpectedEOF)
+		}
+		d.count, d.bitpacked = 8*count, true
+		return nil
+	}
+
+	size := bitpack.ByteCount(d.bitWidth)
+	if size > len(d.src) {
+		return fmt.Errorf("decoding run-length block of %d values: %w", count, io.ErrUnexpectedEOF)
+	}
+	d.value = [4]byte{}
+	copy(d.value[:], d.src[:size])
+	d.src = d.src[size:]
+	d.count, d.bitpacked = count, false
+	return nil
+}
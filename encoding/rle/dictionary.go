@@ -39,6 +39,33 @@ func (e *DictionaryEncoding) DecodeInt32(dst, src []byte) ([]byte, error) {
 	return dst, e.wrap(err)
 }
 
+// DecodeIndexes decodes the dictionary indexes of a data page from src into
+// dst, returning the slice of dst holding the indexes. The dst slice is grown
+// if its capacity is too short to hold the output.
+//
+// The method is equivalent to DecodeInt32 but writes directly to a slice of
+// int32, which saves programs from converting between byte and int32 slices.
+func (e *DictionaryEncoding) DecodeIndexes(dst []int32, src []byte) ([]int32, error) {
+	if len(src) == 0 {
+		return dst[:0], nil
+	}
+	buf, err := decodeInt32(unsafecast.Int32ToBytes(dst[:0]), src[1:], uint(src[0]))
+	return unsafecast.BytesToInt32(buf), e.wrap(err)
+}
+
+// DecodedSize satisfies the encoding.DecodedSizer interface, the number of
+// indexes is computed from the headers of the runs without decoding them.
+func (e *DictionaryEncoding) DecodedSize(typ format.Type, size int, src []byte) (int, error) {
+	if typ != format.Int32 {
+		return 0, encoding.Errorf(e, "cannot compute the decoded size of %s values: %w", typ, encoding.ErrNotSupported)
+	}
+	if len(src) == 0 {
+		return 0, nil
+	}
+	n, err := decodedSizeInt32(src[1:], uint(src[0]))
+	return n, e.wrap(err)
+}
+
 func (e *DictionaryEncoding) wrap(err error) error {
 	if err != nil {
 		err = encoding.Error(e, err)
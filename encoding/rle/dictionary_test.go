@@ -0,0 +1,118 @@
+package rle_test
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go/encoding/rle"
+	"github.com/segmentio/parquet-go/format"
+	"github.com/segmentio/parquet-go/internal/unsafecast"
+)
+
+func TestDictionaryEncodingDecodeIndexes(t *testing.T) {
+	indexes := make([]int32, 1000)
+	for i := range indexes {
+		switch {
+		case i < 100:
+			indexes[i] = 42
+		default:
+			indexes[i] = int32(i % 37)
+		}
+	}
+
+	e := new(rle.DictionaryEncoding)
+	src, err := e.EncodeInt32(nil, unsafecast.Int32ToBytes(indexes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := e.DecodeInt32(nil, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, capacity := range []int{0, 10, len(indexes), 2 * len(indexes)} {
+		buf := make([]int32, 0, capacity)
+		got, err := e.DecodeIndexes(buf, src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got[:len(indexes)], indexes) {
+			t.Errorf("capacity=%d: decoded indexes mismatch", capacity)
+		}
+		if !reflect.DeepEqual(unsafecast.Int32ToBytes(got), want) {
+			t.Errorf("capacity=%d: output differs from DecodeInt32", capacity)
+		}
+		if capacity >= len(got) && &got[0] != &buf[:1][0] {
+			t.Errorf("capacity=%d: buffer was reallocated", capacity)
+		}
+	}
+
+	got, err := e.DecodeIndexes(make([]int32, 10), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("decoding empty input returned %d indexes", len(got))
+	}
+}
+
+func TestDictionaryEncodingChunkDecoder(t *testing.T) {
+	indexes := make([]int32, 1000)
+	for i := range indexes {
+		switch {
+		case i < 100:
+			indexes[i] = 42
+		case i < 500:
+			indexes[i] = int32(i % 37)
+		case i < 600:
+			indexes[i] = 7
+		default:
+			indexes[i] = int32(i % 1000)
+		}
+	}
+
+	e := new(rle.DictionaryEncoding)
+	src, err := e.EncodeInt32(nil, unsafecast.Int32ToBytes(indexes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := e.DecodeInt32(nil, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := e.NewChunkDecoder(format.Int32, 0)
+	for _, chunkSize := range []int{1, 3, 8, 13, 64, 2000} {
+		d.Reset(src)
+		got := []byte{}
+		buf := []byte{}
+		for {
+			buf, err = d.Decode(buf, chunkSize)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buf) == 0 || len(buf) > 4*chunkSize {
+				t.Fatalf("chunkSize=%d: wrong number of values decoded: %d", chunkSize, len(buf)/4)
+			}
+			got = append(got, buf...)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("chunkSize=%d: output differs from DecodeInt32", chunkSize)
+		}
+	}
+
+	d.Reset(src[:len(src)/2])
+	for err = nil; err == nil; {
+		_, err = d.Decode(nil, 100)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("decoding truncated input: want %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
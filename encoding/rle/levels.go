@@ -0,0 +1,163 @@
+package rle
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/parquet-go/internal/bytealg"
+)
+
+// LevelDecoder is a decoder of repetition and definition levels encoded with
+// the hybrid RLE/Bit-Packed encoding.
+//
+// Unlike the DecodeLevels method of Encoding, the decoder writes the levels to
+// a buffer provided by the caller and never decodes more levels than the buffer
+// can hold, which lets programs decode the levels of pages without allocating
+// memory. Decoders are reset to decode a new page, retaining no reference to
+// the previous input.
+//
+// The zero-value is a valid decoder with no input.
+type LevelDecoder struct {
+	src      []byte
+	bitWidth uint
+	// Number of levels remaining in the current run, including the values
+	// of the last bit-packed group that were already decoded.
+	count     uint
+	value     byte
+	bitpacked bool
+	// Buffer holding a decoded group of bit-packed values, with offset being
+	// the index of the next value to return.
+	group  [8]byte
+	input  [8]byte
+	offset int
+}
+
+// Reset positions the decoder at the beginning of data, holding levels encoded
+// with the given bit width.
+func (d *LevelDecoder) Reset(data []byte, bitWidth int) {
+	*d = LevelDecoder{
+		src:      data,
+		bitWidth: uint(bitWidth),
+		offset:   len(d.group),
+	}
+}
+
+// Decode decodes levels into dst, returning the number of levels written.
+//
+// The method returns io.EOF when all levels have been decoded. Note that runs
+// of bit-packed values are padded to multiples of 8, the caller is expected to
+// only decode the number of levels that it knows exist in the page.
+func (d *LevelDecoder) Decode(dst []byte) (int, error) {
+	if d.bitWidth > 8 {
+		return 0, errDecodeInvalidBitWidth("INT8", d.bitWidth)
+	}
+
+	n := 0
+
+	for n < len(dst) {
+		if d.count == 0 {
+			if len(d.src) == 0 {
+				if n == 0 {
+					return 0, io.EOF
+				}
+				break
+			}
+			if err := d.decodeHeader(); err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		if !d.bitpacked {
+			k := uint(len(dst) - n)
+			if k > d.count {
+				k = d.count
+			}
+			bytealg.Broadcast(dst[n:n+int(k)], d.value)
+			n += int(k)
+			d.count -= k
+			continue
+		}
+
+		if d.offset < len(d.group) {
+			k := copy(dst[n:], d.group[d.offset:])
+			d.offset += k
+			d.count -= uint(k)
+			n += k
+			continue
+		}
+
+		// Whole groups are decoded directly into the output buffer when it
+		// has enough room; the optimized functions may load 8 bytes at a
+		// time, which must not go past the end of the input.
+		groups := uint(len(dst)-n) / 8
+		if limit := d.count / 8; groups > limit {
+			groups = limit
+		}
+		if len(d.src) < 8 {
+			groups = 0
+		} else if limit := uint(len(d.src)-8)/d.bitWidth + 1; groups > limit {
+			groups = limit
+		}
+
+		if groups > 0 {
+			size := groups * d.bitWidth
+			decodeBytesBitpack(dst[n:n+int(8*groups)], d.src[:size], 8*groups, d.bitWidth)
+			d.src = d.src[size:]
+			d.count -= 8 * groups
+			n += int(8 * groups)
+		} else {
+			// The input is copied to a buffer of 8 bytes to guarantee that
+			// loads remain in bounds.
+			d.input = [8]byte{}
+			copy(d.input[:], d.src[:d.bitWidth])
+			decodeBytesBitpack(d.group[:], d.input[:], 8, d.bitWidth)
+			d.src = d.src[d.bitWidth:]
+			d.offset = 0
+		}
+	}
+
+	return n, nil
+}
+
+func (d *LevelDecoder) decodeHeader() error {
+	u, n := binary.Uvarint(d.src)
+	if n == 0 {
+		return fmt.Errorf("decoding run-length block header: %w", io.ErrUnexpectedEOF)
+	}
+	if n < 0 {
+		return fmt.Errorf("overflow after decoding %d/%d bytes of run-length block header", -n, len(d.src))
+	}
+	d.src = d.src[n:]
+
+	count, bitpacked := uint(u>>1), (u&1) != 0
+	if count > maxSupportedValueCount {
+		return fmt.Errorf("decoded run-length block cannot have more than %d values", maxSupportedValueCount)
+	}
+
+	if bitpacked && d.bitWidth != 0 {
+		count *= 8
+		if size := count * d.bitWidth / 8; size > uint(len(d.src)) {
+			return fmt.Errorf("decoding bit-packed block of %d values: %w", count, io.ErrUnexpectedEOF)
+		}
+		d.count, d.bitpacked = count, true
+		return nil
+	}
+
+	if bitpacked {
+		// Values bit-packed with a zero bit width are all zero and take no
+		// space in the input.
+		count *= 8
+	}
+
+	value := byte(0)
+	if d.bitWidth != 0 && !bitpacked {
+		if len(d.src) == 0 {
+			return fmt.Errorf("decoding run-length block of %d values: %w", count, io.ErrUnexpectedEOF)
+		}
+		value, d.src = d.src[0], d.src[1:]
+	}
+	d.count, d.value, d.bitpacked = count, value, false
+	return nil
+}
@@ -0,0 +1,69 @@
+package rle_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/segmentio/parquet-go/encoding/rle"
+)
+
+func TestLevelDecoder(t *testing.T) {
+	levels := make([]byte, 1000)
+	for i := range levels {
+		switch {
+		case i < 100:
+			levels[i] = 3
+		case i < 500:
+			levels[i] = byte(i % 7)
+		default:
+			levels[i] = byte(i / 100 % 2)
+		}
+	}
+
+	for _, bitWidth := range []int{3, 4, 8} {
+		enc := &rle.Encoding{BitWidth: bitWidth}
+		data, err := enc.EncodeLevels(nil, levels)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoder := new(rle.LevelDecoder)
+		for _, chunkSize := range []int{1, 3, 8, 17, 64, len(levels)} {
+			decoder.Reset(data, bitWidth)
+			decoded := make([]byte, 0, len(levels))
+			buffer := make([]byte, chunkSize)
+
+			for len(decoded) < len(levels) {
+				n, err := decoder.Decode(buffer)
+				decoded = append(decoded, buffer[:n]...)
+				if err != nil {
+					t.Fatalf("bitWidth=%d chunkSize=%d: %v", bitWidth, chunkSize, err)
+				}
+			}
+
+			if !bytes.Equal(decoded[:len(levels)], levels) {
+				t.Fatalf("bitWidth=%d chunkSize=%d: levels mismatch", bitWidth, chunkSize)
+			}
+		}
+
+		decoder.Reset(data, bitWidth)
+		buffer := make([]byte, len(levels)+8)
+		if n, err := decoder.Decode(buffer); err != nil {
+			t.Fatal(err)
+		} else if n < len(levels) {
+			t.Fatalf("bitWidth=%d: too few levels decoded: %d", bitWidth, n)
+		}
+		if _, err := decoder.Decode(buffer); err != io.EOF {
+			t.Fatalf("bitWidth=%d: expected io.EOF but got %v", bitWidth, err)
+		}
+
+		allocs := testing.AllocsPerRun(10, func() {
+			decoder.Reset(data, bitWidth)
+			decoder.Decode(buffer[:len(levels)])
+		})
+		if allocs != 0 {
+			t.Errorf("bitWidth=%d: too many memory allocations: %g", bitWidth, allocs)
+		}
+	}
+}
@@ -156,12 +156,11 @@ func encodeBytes(dst, src []byte, bitWidth uint) ([]byte, error) {
 		words := unsafe.Slice((*uint64)(unsafe.Pointer(&src[0])), len(src)/8)
 
 		for i := 0; i < len(words); {
-			j := i
+			// Definition levels of sparse or mostly required columns contain
+			// long runs of identical values, which are detected with vector
+			// comparisons when the CPU supports them.
 			pattern := broadcast8x1(words[i])
-
-			for j < len(words) && words[j] == pattern {
-				j++
-			}
+			j := i + encodeBytesIndexNotEqual(words[i:], pattern)
 
 			if i < j {
 				dst = appendRunLengthBytes(dst, 8*(j-i), byte(pattern))
@@ -395,6 +394,41 @@ func decodeInt32(dst, src []byte, bitWidth uint) ([]byte, error) {
 	return dst, nil
 }
 
+// decodedSizeInt32 returns the size of the output of decodeInt32 by walking the
+// headers of the runs in src.
+func decodedSizeInt32(src []byte, bitWidth uint) (int, error) {
+	if bitWidth > 32 {
+		return 0, errDecodeInvalidBitWidth("INT32", bitWidth)
+	}
+
+	size := 0
+
+	for i := 0; i < len(src); {
+		u, n := binary.Uvarint(src[i:])
+		if n == 0 {
+			return 0, fmt.Errorf("decoding run-length block header: %w", io.ErrUnexpectedEOF)
+		}
+		if n < 0 {
+			return 0, fmt.Errorf("overflow after decoding %d/%d bytes of run-length block header", -n+i, len(src))
+		}
+		i += n
+
+		count, bitpacked := uint(u>>1), (u&1) != 0
+		if count > maxSupportedValueCount {
+			return 0, fmt.Errorf("decoded run-length block cannot have more than %d values", maxSupportedValueCount)
+		}
+		if bitpacked {
+			size += 4 * 8 * int(count)
+			i += int(count * bitWidth)
+		} else {
+			size += 4 * int(count)
+			i += bitpack.ByteCount(bitWidth)
+		}
+	}
+
+	return size, nil
+}
+
 func errEncodeInvalidBitWidth(typ string, bitWidth uint) error {
 	return errInvalidBitWidth("encode", typ, bitWidth)
 }
@@ -527,6 +561,13 @@ func encodeBytesBitpackDefault(dst []byte, src []uint64, bitWidth uint) int {
 	return n
 }
 
+func encodeBytesIndexNotEqualDefault(words []uint64, pattern uint64) (n int) {
+	for n < len(words) && words[n] == pattern {
+		n++
+	}
+	return n
+}
+
 func decodeBytesBitpackDefault(dst, src []byte, count, bitWidth uint) {
 	dst = dst[:0]
 
@@ -10,6 +10,7 @@ var (
 	encodeInt32IndexEqual8Contiguous func(words [][8]int32) int
 	encodeInt32Bitpack               func(dst []byte, src [][8]int32, bitWidth uint) int
 	encodeBytesBitpack               func(dst []byte, src []uint64, bitWidth uint) int
+	encodeBytesIndexNotEqual         func(words []uint64, pattern uint64) int
 	decodeBytesBitpack               func(dst, src []byte, count, bitWidth uint)
 )
 
@@ -23,6 +24,12 @@ func init() {
 		encodeInt32Bitpack = encodeInt32BitpackDefault
 	}
 
+	if cpu.X86.HasAVX2 {
+		encodeBytesIndexNotEqual = encodeBytesIndexNotEqualAVX2
+	} else {
+		encodeBytesIndexNotEqual = encodeBytesIndexNotEqualDefault
+	}
+
 	switch {
 	case cpu.X86.HasBMI2:
 		encodeBytesBitpack = encodeBytesBitpackBMI2
@@ -58,3 +65,6 @@ func encodeInt32BitpackAVX2(dst []byte, src [][8]int32, bitWidth uint) int {
 
 //go:noescape
 func decodeBytesBitpackBMI2(dst, src []byte, count, bitWidth uint)
+
+//go:noescape
+func encodeBytesIndexNotEqualAVX2(words []uint64, pattern uint64) int
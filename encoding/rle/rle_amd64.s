@@ -82,6 +82,58 @@ done:
     MOVQ SI, ret+24(FP)
     RET
 
+// func encodeBytesIndexNotEqualAVX2(words []uint64, pattern uint64) int
+TEXT ·encodeBytesIndexNotEqualAVX2(SB), NOSPLIT, $0-40
+    MOVQ words_base+0(FP), AX
+    MOVQ words_len+8(FP), BX
+    MOVQ pattern+24(FP), DX
+    XORQ SI, SI
+
+    MOVQ BX, DI
+    SHRQ $3, DI
+    SHLQ $3, DI
+    CMPQ DI, $0
+    JE tail
+
+    MOVQ DX, X0
+    VPBROADCASTQ X0, Y0
+loop:
+    VMOVDQU (AX)(SI*8), Y1
+    VMOVDQU 32(AX)(SI*8), Y2
+    VPCMPEQQ Y0, Y1, Y1
+    VPCMPEQQ Y0, Y2, Y2
+    VPAND Y1, Y2, Y3
+    VMOVMSKPD Y3, CX
+    CMPL CX, $0xF
+    JNE found
+    ADDQ $8, SI
+    CMPQ SI, DI
+    JNE loop
+    VZEROUPPER
+    JMP tail
+found:
+    // At least one of the 8 words differs from the pattern, the masks of the
+    // two vectors are combined to locate the first one.
+    VMOVMSKPD Y1, CX
+    VMOVMSKPD Y2, R8
+    VZEROUPPER
+    SHLL $4, R8
+    ORL R8, CX
+    NOTL CX
+    BSFL CX, CX
+    ADDQ CX, SI
+    JMP done
+tail:
+    CMPQ SI, BX
+    JE done
+    CMPQ (AX)(SI*8), DX
+    JNE done
+    INCQ SI
+    JMP tail
+done:
+    MOVQ SI, ret+32(FP)
+    RET
+
 // func encodeInt32IndexEqual8ContiguousSSE(words [][8]int32) int
 TEXT ·encodeInt32IndexEqual8ContiguousSSE(SB), NOSPLIT, $0-32
     MOVQ words_base+0(FP), AX
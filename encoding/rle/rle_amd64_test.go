@@ -20,3 +20,15 @@ func BenchmarkEncodeInt32IndexEqual8ContiguousAVX2(b *testing.B) {
 func BenchmarkEncodeInt32IndexEqual8ContiguousSSE(b *testing.B) {
 	benchmarkEncodeInt32IndexEqual8Contiguous(b, encodeInt32IndexEqual8ContiguousSSE)
 }
+
+func TestEncodeBytesIndexNotEqualAVX2(t *testing.T) {
+	testEncodeBytesIndexNotEqual(t, encodeBytesIndexNotEqualAVX2)
+}
+
+func BenchmarkEncodeBytesIndexNotEqualAVX2(b *testing.B) {
+	benchmarkEncodeBytesIndexNotEqual(b, encodeBytesIndexNotEqualAVX2)
+}
+
+func BenchmarkEncodeBytesIndexNotEqualDefault(b *testing.B) {
+	benchmarkEncodeBytesIndexNotEqual(b, encodeBytesIndexNotEqualDefault)
+}
@@ -17,6 +17,10 @@ func encodeInt32Bitpack(dst []byte, src [][8]int32, bitWidth uint) int {
 	return encodeInt32BitpackDefault(dst, src, bitWidth)
 }
 
+func encodeBytesIndexNotEqual(words []uint64, pattern uint64) int {
+	return encodeBytesIndexNotEqualDefault(words, pattern)
+}
+
 func decodeBytesBitpack(dst, src []byte, count, bitWidth uint) {
 	decodeBytesBitpackDefault(dst, src, count, bitWidth)
 }
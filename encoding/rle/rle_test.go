@@ -48,6 +48,43 @@ func testEncodeInt32IndexEqual8Contiguous(t *testing.T, f func([][8]int32) int)
 	}
 }
 
+func TestEncodeBytesIndexNotEqual(t *testing.T) {
+	testEncodeBytesIndexNotEqual(t, encodeBytesIndexNotEqual)
+}
+
+func testEncodeBytesIndexNotEqual(t *testing.T, f func([]uint64, uint64) int) {
+	t.Helper()
+
+	const pattern = 0x0101010101010101
+
+	for size := 0; size < 50; size++ {
+		for index := 0; index <= size; index++ {
+			words := make([]uint64, size)
+			for i := range words {
+				words[i] = pattern
+			}
+			if index < size {
+				words[index] = pattern + 1
+			}
+			if got := f(words, pattern); got != index {
+				t.Fatalf("size=%d: want=%d got=%d", size, index, got)
+			}
+		}
+	}
+}
+
+func BenchmarkEncodeBytesIndexNotEqual(b *testing.B) {
+	benchmarkEncodeBytesIndexNotEqual(b, encodeBytesIndexNotEqual)
+}
+
+func benchmarkEncodeBytesIndexNotEqual(b *testing.B, f func([]uint64, uint64) int) {
+	words := make([]uint64, 1000)
+	for i := 0; i < b.N; i++ {
+		_ = f(words, 0)
+	}
+	b.SetBytes(8 * int64(len(words)))
+}
+
 func BenchmarkEncodeInt32IndexEqual8Contiguous(b *testing.B) {
 	benchmarkEncodeInt32IndexEqual8Contiguous(b, encodeInt32IndexEqual8Contiguous)
 }
@@ -0,0 +1,121 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/encoding/plain"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// experimentalEncoding is a test encoding using a code which is not defined by
+// the parquet format.
+type experimentalEncoding struct {
+	encoding.NotSupported
+	plain plain.Encoding
+}
+
+func (e *experimentalEncoding) String() string { return "EXPERIMENTAL" }
+
+func (e *experimentalEncoding) Encoding() format.Encoding { return 100 }
+
+func (e *experimentalEncoding) EncodeInt64(dst, src []byte) ([]byte, error) {
+	return e.plain.EncodeInt64(dst, src)
+}
+
+func (e *experimentalEncoding) DecodeInt64(dst, src []byte) ([]byte, error) {
+	return e.plain.DecodeInt64(dst, src)
+}
+
+func TestRegisterEncoding(t *testing.T) {
+	schema := parquet.NewSchema("test", parquet.Group{
+		"value": parquet.Encoded(parquet.Leaf(parquet.Int64Type), new(experimentalEncoding)),
+	})
+
+	rows := make([]parquet.Row, 10)
+	for i := range rows {
+		rows[i] = parquet.Row{parquet.ValueOf(int64(i)).Level(0, 0, 0)}
+	}
+
+	buffer := new(bytes.Buffer)
+	w := parquet.NewWriter(buffer, schema)
+	if _, err := w.WriteRows(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	readRows := func() ([]parquet.Row, error) {
+		f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		if err != nil {
+			return nil, err
+		}
+		r := parquet.NewReader(f)
+		defer r.Close()
+		values := make([]parquet.Row, len(rows))
+		n, err := r.ReadRows(values)
+		if n == len(rows) {
+			err = nil
+		}
+		return values[:n], err
+	}
+
+	_, err := readRows()
+	if !errors.Is(err, encoding.ErrNotSupported) || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("expected an error reporting that the encoding is not registered, got %v", err)
+	}
+
+	// Registering the encoding found before the test restores the global
+	// state, so other tests see the code as not registered.
+	previous := parquet.LookupEncoding(100)
+	t.Cleanup(func() { parquet.RegisterEncoding(previous) })
+	parquet.RegisterEncoding(new(experimentalEncoding))
+
+	if enc := parquet.LookupEncoding(100); enc.String() != "EXPERIMENTAL" {
+		t.Errorf("wrong encoding returned after registration: %s", enc)
+	}
+
+	values, err := readRows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		if !values[i].Equal(rows[i]) {
+			t.Errorf("row %d mismatch: want=%v got=%v", i, rows[i], values[i])
+		}
+	}
+}
+
+func TestCanEncode(t *testing.T) {
+	tests := []struct {
+		encoding  encoding.Encoding
+		kind      parquet.Kind
+		canEncode bool
+	}{
+		{&parquet.Plain, parquet.Boolean, true},
+		{&parquet.Plain, parquet.ByteArray, true},
+		{&parquet.RLE, parquet.Boolean, true},
+		{&parquet.RLE, parquet.Int64, false},
+		{&parquet.DeltaBinaryPacked, parquet.Int32, true},
+		{&parquet.DeltaBinaryPacked, parquet.ByteArray, false},
+		{&parquet.DeltaLengthByteArray, parquet.ByteArray, true},
+		{&parquet.DeltaLengthByteArray, parquet.Double, false},
+		{&parquet.DeltaByteArray, parquet.FixedLenByteArray, true},
+		{&parquet.ByteStreamSplit, parquet.Float, true},
+		{&parquet.ByteStreamSplit, parquet.Int96, false},
+		{&parquet.RLEDictionary, parquet.Int96, true},
+		{new(experimentalEncoding), parquet.Int64, true},
+		{new(experimentalEncoding), parquet.Int32, false},
+	}
+
+	for _, test := range tests {
+		if canEncode := parquet.CanEncode(test.encoding, test.kind); canEncode != test.canEncode {
+			t.Errorf("CanEncode(%s, %s): want=%t got=%t", test.encoding, test.kind, test.canEncode, canEncode)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package parquet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// EncryptionConfig carries the configuration of parquet modular encryption,
+// which writers use to encrypt the pages, page indexes, bloom filters, and
+// footer of the files they produce.
+//
+// The footer of encrypted files is encrypted with the footer key, readers need
+// the key to access any part of the file, including its schema. Columns can be
+// encrypted with their own keys, so access to their content can be restricted
+// to the programs which hold the column keys.
+type EncryptionConfig struct {
+	// Key used to encrypt the footer, and the columns which are not given a
+	// key of their own. The key must be 16, 24, or 32 bytes long to select
+	// AES-128, AES-192, or AES-256.
+	FooterKey []byte
+
+	// Metadata stored in the file to let readers retrieve the footer key, for
+	// example the identifier of the key in a key management system.
+	FooterKeyMetadata []byte
+
+	// Encryption keys of the columns, indexed by their path with the names of
+	// the parent groups separated by dots, for example "items.list.element.sku".
+	//
+	// When the map is empty, all the columns are encrypted with the footer
+	// key. Otherwise, only the columns present in the map are encrypted, and
+	// the others are written in plaintext; columns with an empty key are
+	// encrypted with the footer key.
+	ColumnKeys map[string]ColumnEncryptionKey
+
+	// Prefix of the additional authenticated data of all the encrypted parts
+	// of the file, which lets readers verify that they are reading the file
+	// they expect, for example when the prefix is derived from the file name.
+	AADPrefix []byte
+
+	// When true, the AAD prefix is not stored in the file, readers must know
+	// the prefix in order to decrypt it.
+	SupplyAADPrefix bool
+
+	// The encryption algorithm, AES_GCM_V1 by default.
+	Algorithm EncryptionAlgorithm
+
+	// When true, the footer is written in plaintext and signed with the footer
+	// key, instead of being encrypted. Readers without keys can then read the
+	// schema and metadata of the file, as well as its plaintext columns, and
+	// readers holding the footer key detect tampering with the footer. The
+	// metadata of encrypted columns remain encrypted, the footer only retains
+	// their location in the file.
+	PlaintextFooter bool
+}
+
+// EncryptionAlgorithm enumerates the algorithms of parquet modular encryption.
+type EncryptionAlgorithm int
+
+const (
+	// AesGcmV1 is the AES_GCM_V1 algorithm, which encrypts all the modules of
+	// files with AES-GCM, authenticating both the data and the metadata.
+	AesGcmV1 EncryptionAlgorithm = iota
+
+	// AesGcmCtrV1 is the AES_GCM_CTR_V1 algorithm, which encrypts pages with
+	// AES-CTR and the other modules with AES-GCM. The content of pages is not
+	// authenticated, which lowers the cost of encryption and decryption for
+	// large scans, while the metadata remains protected against tampering.
+	AesGcmCtrV1
+)
+
+// ColumnEncryptionKey is the encryption key of a column.
+type ColumnEncryptionKey struct {
+	// The encryption key, which must be 16, 24, or 32 bytes long, or empty to
+	// encrypt the column with the footer key.
+	Key []byte
+
+	// Metadata stored in the file to let readers retrieve the key.
+	KeyMetadata []byte
+}
+
+// Validate returns a non-nil error if the configuration of c is invalid.
+func (c *EncryptionConfig) Validate() error {
+	const baseName = "parquet.(*EncryptionConfig)."
+	if c == nil {
+		return nil
+	}
+	if !isValidKeyLength(len(c.FooterKey)) {
+		return errorInvalidOptionValue(baseName+"FooterKey length", len(c.FooterKey))
+	}
+	if c.SupplyAADPrefix && len(c.AADPrefix) == 0 {
+		return errorInvalidOptionValue(baseName+"SupplyAADPrefix", c.SupplyAADPrefix)
+	}
+	if err := validateOneOfInt(baseName+"Algorithm", int(c.Algorithm), int(AesGcmV1), int(AesGcmCtrV1)); err != nil {
+		return err
+	}
+	for path, key := range c.ColumnKeys {
+		if len(key.Key) != 0 && !isValidKeyLength(len(key.Key)) {
+			return errorInvalidOptionValue(fmt.Sprintf("%sColumnKeys[%q] length", baseName, path), len(key.Key))
+		}
+	}
+	return nil
+}
+
+// validateColumnKeys returns an error if some of the column keys are configured
+// for paths that do not name leaf columns of the schema.
+func validateColumnKeys(schema *Schema, config *EncryptionConfig) error {
+	if config == nil {
+		return nil
+	}
+	leaves := make(map[string]struct{})
+	forEachLeafColumnOf(schema, func(leaf leafColumn) { leaves[leaf.path.String()] = struct{}{} })
+	for path := range config.ColumnKeys {
+		if _, ok := leaves[path]; !ok {
+			return fmt.Errorf("encryption key configured for column %q which is not a leaf column of the schema", path)
+		}
+	}
+	return nil
+}
+
+func isValidKeyLength(n int) bool { return n == 16 || n == 24 || n == 32 }
+
+// Types of the modules of encrypted files, which are part of the additional
+// authenticated data (AAD) of the modules.
+const (
+	footerModule byte = iota
+	columnMetaDataModule
+	dataPageModule
+	dictionaryPageModule
+	dataPageHeaderModule
+	dictionaryPageHeaderModule
+	columnIndexModule
+	offsetIndexModule
+	bloomFilterHeaderModule
+	bloomFilterBitsetModule
+)
+
+const (
+	// Encrypted modules are prefixed with their length on 4 bytes, and the
+	// ciphertext is surrounded by a nonce and an authentication tag, the
+	// modules encrypted with AES-CTR have no tag.
+	moduleLengthSize = 4
+	gcmNonceSize     = 12
+	gcmTagSize       = 16
+	ctrNonceSize     = 12
+
+	// Length of the random identifier of files in the AAD of their modules.
+	aadFileUniqueSize = 8
+)
+
+// fileEncryption holds the state of writers encrypting the file they produce.
+type fileEncryption struct {
+	config *EncryptionConfig
+	footer *moduleCipher
+	// The AAD prefix followed by the unique identifier of the file, see
+	// fileAAD.
+	aad []byte
+	// Ordinal of the row group being written, which is part of the AAD of the
+	// modules of column chunks.
+	rowGroup int
+}
+
+func newFileEncryption(config *EncryptionConfig) (*fileEncryption, error) {
+	footer, err := newModuleCipher(config.FooterKey, config.Algorithm == AesGcmCtrV1)
+	if err != nil {
+		return nil, err
+	}
+	return &fileEncryption{config: config, footer: footer}, nil
+}
+
+// reset is called when the writer starts writing a new file, a new identifier
+// is generated for the file when its first module is encrypted.
+func (e *fileEncryption) reset() {
+	e.aad = e.aad[:0]
+	e.rowGroup = 0
+}
+
+// fileAAD returns the AAD prefix followed by the unique identifier of the file.
+func (e *fileEncryption) fileAAD() ([]byte, error) {
+	if len(e.aad) == 0 {
+		aad := append(e.aad[:0], e.config.AADPrefix...)
+		aad = append(aad, make([]byte, aadFileUniqueSize)...)
+		if _, err := io.ReadFull(rand.Reader, aad[len(e.config.AADPrefix):]); err != nil {
+			return nil, err
+		}
+		e.aad = aad
+	}
+	return e.aad, nil
+}
+
+// column returns the encryption state of the column at the given path and
+// index, or nil if the column is written in plaintext.
+func (e *fileEncryption) column(path columnPath, columnIndex int) (*columnEncryption, error) {
+	key, keyMetadata := e.footer, []byte(nil)
+	if len(e.config.ColumnKeys) > 0 {
+		columnKey, ok := e.config.ColumnKeys[path.String()]
+		if !ok {
+			return nil, nil
+		}
+		if len(columnKey.Key) != 0 {
+			var err error
+			if key, err = newModuleCipher(columnKey.Key, e.footer.ctr); err != nil {
+				return nil, err
+			}
+			keyMetadata = columnKey.KeyMetadata
+		}
+	}
+
+	c := &columnEncryption{file: e, cipher: key, column: int16(columnIndex)}
+	if key == e.footer {
+		c.metadata.EncryptionWithFooterKey = &format.EncryptionWithFooterKey{}
+	} else {
+		c.metadata.EncryptionWithColumnKey = &format.EncryptionWithColumnKey{
+			PathInSchema: path,
+			KeyMetadata:  keyMetadata,
+		}
+	}
+	return c, nil
+}
+
+func (e *fileEncryption) algorithm(fileAAD []byte) format.EncryptionAlgorithm {
+	aadPrefix, aadFileUnique := e.config.AADPrefix, fileAAD[len(e.config.AADPrefix):]
+	if e.config.SupplyAADPrefix {
+		aadPrefix = nil
+	}
+	if e.footer.ctr {
+		return format.EncryptionAlgorithm{AesGcmCtrV1: &format.AesGcmCtrV1{
+			AadPrefix:       aadPrefix,
+			AadFileUnique:   aadFileUnique,
+			SupplyAadPrefix: e.config.SupplyAADPrefix,
+		}}
+	}
+	return format.EncryptionAlgorithm{AesGcmV1: &format.AesGcmV1{
+		AadPrefix:       aadPrefix,
+		AadFileUnique:   aadFileUnique,
+		SupplyAadPrefix: e.config.SupplyAADPrefix,
+	}}
+}
+
+// encryptFooter returns the footer of the file, composed of the crypto metadata
+// of the file followed by the encrypted file metadata.
+func (e *fileEncryption) encryptFooter(protocol *thrift.CompactProtocol, metadata *format.FileMetaData) ([]byte, error) {
+	fileAAD, err := e.fileAAD()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := thrift.Marshal(protocol, metadata)
+	if err != nil {
+		return nil, err
+	}
+	footer, err := thrift.Marshal(protocol, &format.FileCryptoMetaData{
+		EncryptionAlgorithm: e.algorithm(fileAAD),
+		KeyMetadata:         e.config.FooterKeyMetadata,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e.footer.encrypt(footer, plaintext, footerModule, moduleAAD(fileAAD, footerModule, -1, -1, -1))
+}
+
+// signFooter returns the footer of files written in plaintext footer mode,
+// composed of the file metadata followed by its signature.
+func (e *fileEncryption) signFooter(protocol *thrift.CompactProtocol, metadata *format.FileMetaData) ([]byte, error) {
+	fileAAD, err := e.fileAAD()
+	if err != nil {
+		return nil, err
+	}
+	metadata.EncryptionAlgorithm = e.algorithm(fileAAD)
+	metadata.FooterSigningKeyMetadata = e.config.FooterKeyMetadata
+	footer, err := thrift.Marshal(protocol, metadata)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signFooter(e.footer.gcm, fileAAD, footer)
+	if err != nil {
+		return nil, err
+	}
+	return append(footer, signature...), nil
+}
+
+// columnEncryption holds the state needed to encrypt the modules of a column.
+type columnEncryption struct {
+	file     *fileEncryption
+	cipher   *moduleCipher
+	column   int16
+	metadata format.ColumnCryptoMetaData
+	// Buffer holding the encrypted modules.
+	buffer []byte
+}
+
+// encrypt appends the encrypted module of the given type to dst. The row group
+// ordinal is the one of the row group being written when it is negative, and
+// the page ordinal is only part of the AAD of data pages and their headers.
+func (c *columnEncryption) encrypt(dst, plaintext []byte, module byte, rowGroup, page int) ([]byte, error) {
+	if rowGroup < 0 {
+		rowGroup = c.file.rowGroup
+	}
+	if rowGroup > math.MaxInt16 {
+		return dst, fmt.Errorf("cannot encrypt more than %d row groups", math.MaxInt16+1)
+	}
+	if page > math.MaxInt16 {
+		return dst, fmt.Errorf("cannot encrypt more than %d pages in a column chunk", math.MaxInt16+1)
+	}
+	fileAAD, err := c.file.fileAAD()
+	if err != nil {
+		return dst, err
+	}
+	return c.cipher.encrypt(dst, plaintext, module, moduleAAD(fileAAD, module, int16(rowGroup), c.column, int16(page)))
+}
+
+// encryptPage encrypts the header and the data of a page of the column as
+// separate modules, the compressed size of the page recorded in the header is
+// the size of the encrypted data. The method returns the encrypted header and
+// data, which are retained in an internal buffer until the next call.
+func (c *writerColumn) encryptPage(pageHeader *format.PageHeader, data ...[]byte) (header, page []byte, err error) {
+	e := c.encryption
+	headerModule, pageModule := dataPageHeaderModule, dataPageModule
+	// Data pages are identified by their ordinal in the column chunk, which
+	// is the number of data pages written before them.
+	ordinal := len(c.offsetIndex.PageLocations)
+	if pageHeader.Type == format.DictionaryPage {
+		headerModule, pageModule, ordinal = dictionaryPageHeaderModule, dictionaryPageModule, -1
+	}
+
+	buffer := e.buffer[:0]
+	for _, b := range data {
+		buffer = append(buffer, b...)
+	}
+	pageOffset := len(buffer)
+	if buffer, err = e.encrypt(buffer, buffer[:pageOffset], pageModule, -1, ordinal); err != nil {
+		return nil, nil, err
+	}
+	headerOffset := len(buffer)
+	pageHeader.CompressedPageSize = int32(headerOffset - pageOffset)
+	// The checksum of encrypted pages is computed on the encrypted data, so
+	// readers can verify it before decrypting the pages.
+	pageHeader.CRC = int32(crc32.ChecksumIEEE(buffer[pageOffset:headerOffset]))
+
+	c.buffers.header.Reset()
+	if err := c.header.encoder.Encode(pageHeader); err != nil {
+		return nil, nil, err
+	}
+	if buffer, err = e.encrypt(buffer, c.buffers.header.Bytes(), headerModule, -1, ordinal); err != nil {
+		return nil, nil, err
+	}
+	e.buffer = buffer
+	return buffer[headerOffset:], buffer[pageOffset:headerOffset], nil
+}
+
+// writeEncryptedPage encrypts and buffers a page of the column, returning the
+// size of the encrypted header.
+func (c *writerColumn) writeEncryptedPage(pageHeader *format.PageHeader, data ...[]byte) (int32, error) {
+	header, page, err := c.encryptPage(pageHeader, data...)
+	if err != nil {
+		return 0, err
+	}
+	err = c.writePage(int64(len(header)+len(page)), func(output io.Writer) (int64, error) {
+		n, err := output.Write(header)
+		if err != nil {
+			return int64(n), err
+		}
+		m, err := output.Write(page)
+		return int64(n + m), err
+	})
+	return int32(len(header)), err
+}
+
+// writeEncryptedModule writes the encryption of the thrift representation of v
+// to w, as a module of the given type of the column chunk in a row group.
+func (c *writerColumn) writeEncryptedModule(w io.Writer, v interface{}, module byte, rowGroup int) (int, error) {
+	e := c.encryption
+	plaintext, err := thrift.Marshal(&c.header.protocol, v)
+	if err != nil {
+		return 0, err
+	}
+	if e.buffer, err = e.encrypt(e.buffer[:0], plaintext, module, rowGroup, -1); err != nil {
+		return 0, err
+	}
+	return w.Write(e.buffer)
+}
+
+// moduleCipher encrypts and decrypts the modules of files with a key. When ctr
+// is true, pages are encrypted with AES-CTR, and the other modules with AES-GCM.
+type moduleCipher struct {
+	block cipher.Block
+	gcm   cipher.AEAD
+	ctr   bool
+}
+
+func newModuleCipher(key []byte, ctr bool) (*moduleCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleCipher{block: block, gcm: gcm, ctr: ctr}, nil
+}
+
+// usesCTR returns true if modules of the given type are encrypted with AES-CTR.
+func (c *moduleCipher) usesCTR(module byte) bool {
+	return c.ctr && (module == dataPageModule || module == dictionaryPageModule)
+}
+
+// encrypt appends to dst the encryption of plaintext as a module of the given
+// type with the given AAD, which is only used by AES-GCM.
+func (c *moduleCipher) encrypt(dst, plaintext []byte, module byte, aad []byte) ([]byte, error) {
+	if c.usesCTR(module) {
+		return encryptCTRModule(dst, c.block, plaintext)
+	}
+	return encryptModule(dst, c.gcm, plaintext, aad)
+}
+
+// decrypt appends to dst the decryption of a module of the given type.
+func (c *moduleCipher) decrypt(dst, module []byte, moduleType byte, aad []byte) ([]byte, error) {
+	if c.usesCTR(moduleType) {
+		return decryptCTRModule(dst, c.block, module)
+	}
+	return decryptModule(dst, c.gcm, module, aad)
+}
+
+// encryptModule appends to dst the encryption of plaintext with the given AAD,
+// prefixed by its length and nonce and followed by the authentication tag.
+func encryptModule(dst []byte, aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	offset := len(dst)
+	dst = append(dst, make([]byte, moduleLengthSize+gcmNonceSize)...)
+	nonce := dst[offset+moduleLengthSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return dst[:offset], err
+	}
+	dst = aead.Seal(dst, nonce, plaintext, aad)
+	binary.LittleEndian.PutUint32(dst[offset:], uint32(len(dst)-(offset+moduleLengthSize)))
+	return dst, nil
+}
+
+// signFooter returns the signature of the file metadata of files written in
+// plaintext footer mode, made of the nonce and authentication tag of the
+// encryption of the metadata with the footer key.
+func signFooter(aead cipher.AEAD, fileAAD, metadata []byte) ([]byte, error) {
+	nonce := make([]byte, gcmNonceSize, gcmNonceSize+len(metadata)+gcmTagSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, metadata, moduleAAD(fileAAD, footerModule, -1, -1, -1))
+	return append(sealed[:gcmNonceSize], sealed[len(sealed)-gcmTagSize:]...), nil
+}
+
+// encryptCTRModule appends to dst the encryption of plaintext with AES-CTR,
+// prefixed by its length and nonce.
+func encryptCTRModule(dst []byte, block cipher.Block, plaintext []byte) ([]byte, error) {
+	offset := len(dst)
+	dst = append(dst, make([]byte, moduleLengthSize+ctrNonceSize+len(plaintext))...)
+	nonce := dst[offset+moduleLengthSize : offset+moduleLengthSize+ctrNonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return dst[:offset], err
+	}
+	cipher.NewCTR(block, ctrIV(nonce)).XORKeyStream(dst[offset+moduleLengthSize+ctrNonceSize:], plaintext)
+	binary.LittleEndian.PutUint32(dst[offset:], uint32(len(dst)-(offset+moduleLengthSize)))
+	return dst, nil
+}
+
+// ctrIV returns the initialization vector of AES-CTR, made of the nonce followed
+// by a 32 bits big-endian counter starting at one.
+func ctrIV(nonce []byte) []byte {
+	iv := make([]byte, aes.BlockSize)
+	copy(iv, nonce)
+	iv[aes.BlockSize-1] = 1
+	return iv
+}
+
+// moduleAAD returns the additional authenticated data of a module, made of the
+// AAD of the file followed by the module type, and the ordinals of the row
+// group, column, and page of the module when they are not negative.
+func moduleAAD(fileAAD []byte, module byte, rowGroup, column, page int16) []byte {
+	aad := make([]byte, 0, len(fileAAD)+7)
+	aad = append(aad, fileAAD...)
+	aad = append(aad, module)
+	for _, ordinal := range [...]int16{rowGroup, column, page} {
+		if ordinal < 0 {
+			break
+		}
+		aad = append(aad, byte(ordinal), byte(ordinal>>8))
+	}
+	return aad
+}
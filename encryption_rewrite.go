@@ -0,0 +1,179 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// RewriteKeyMetadata writes to output a copy of the encrypted parquet file of
+// the given size read from input, where the key metadata of the footer key and
+// column keys are replaced by the values returned by the rewrite function. The
+// function is called once for each distinct key metadata of the file.
+//
+// The keys encrypting the file must remain unchanged, only the metadata used to
+// retrieve them is rewritten, for example to re-wrap the keys with new master
+// keys when rotating the master keys of a key management system. The pages,
+// page index, and bloom filters are copied byte-for-byte without being
+// decrypted, only the footer of the file is decrypted and encrypted again, or
+// signed again for files written in plaintext footer mode, which requires the
+// footer key to be available with the decryption configuration.
+//
+// The function returns an error if the file is not encrypted.
+func RewriteKeyMetadata(output io.Writer, input io.ReaderAt, size int64, config *DecryptionConfig, rewrite func(keyMetadata []byte) ([]byte, error)) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	b := make([]byte, 8)
+	if _, err := input.ReadAt(b[:4], 0); err != nil {
+		return fmt.Errorf("reading magic header of parquet file: %w", err)
+	}
+	magic := string(b[:4])
+	if magic != "PAR1" && magic != "PARE" {
+		return fmt.Errorf("invalid magic header of parquet file: %q", b[:4])
+	}
+	if _, err := input.ReadAt(b[:8], size-8); err != nil {
+		return fmt.Errorf("reading magic footer of parquet file: %w", err)
+	}
+	if string(b[4:8]) != magic {
+		return fmt.Errorf("invalid magic footer of parquet file: %q", b[4:8])
+	}
+	footerSize := int64(binary.LittleEndian.Uint32(b[:4]))
+	footer := make([]byte, footerSize)
+	if _, err := input.ReadAt(footer, size-(footerSize+8)); err != nil {
+		return fmt.Errorf("reading footer of parquet file: %w", err)
+	}
+
+	r := keyMetadataRewriter{rewrite: rewrite, rewritten: make(map[string][]byte)}
+	var err error
+	if magic == "PARE" {
+		footer, err = r.rewriteEncryptedFooter(config, footer)
+	} else {
+		footer, err = r.rewritePlaintextFooter(config, footer)
+	}
+	if err != nil {
+		return err
+	}
+
+	buffer := bufio.NewWriterSize(output, DefaultWriteBufferSize)
+	if _, err := io.Copy(buffer, io.NewSectionReader(input, 0, size-(footerSize+8))); err != nil {
+		return fmt.Errorf("copying content of parquet file: %w", err)
+	}
+	binary.LittleEndian.PutUint32(b[:4], uint32(len(footer)))
+	if _, err := buffer.Write(footer); err != nil {
+		return err
+	}
+	if _, err := buffer.Write(b[:8]); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+type keyMetadataRewriter struct {
+	protocol  thrift.CompactProtocol
+	rewrite   func([]byte) ([]byte, error)
+	rewritten map[string][]byte
+}
+
+func (r *keyMetadataRewriter) keyMetadata(keyMetadata []byte) ([]byte, error) {
+	newKeyMetadata, ok := r.rewritten[string(keyMetadata)]
+	if !ok {
+		var err error
+		if newKeyMetadata, err = r.rewrite(keyMetadata); err != nil {
+			return nil, err
+		}
+		r.rewritten[string(keyMetadata)] = newKeyMetadata
+	}
+	return newKeyMetadata, nil
+}
+
+// rewriteColumns rewrites the key metadata of the column chunks encrypted with
+// their own key.
+func (r *keyMetadataRewriter) rewriteColumns(metadata *format.FileMetaData) (err error) {
+	for i := range metadata.RowGroups {
+		for j := range metadata.RowGroups[i].Columns {
+			k := metadata.RowGroups[i].Columns[j].CryptoMetadata.EncryptionWithColumnKey
+			if k == nil {
+				continue
+			}
+			if k.KeyMetadata, err = r.keyMetadata(k.KeyMetadata); err != nil {
+				return fmt.Errorf("rewriting key metadata of column %q: %w", columnPath(k.PathInSchema), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *keyMetadataRewriter) rewriteEncryptedFooter(config *DecryptionConfig, footer []byte) ([]byte, error) {
+	f := &File{config: &FileConfig{Decryption: config}}
+	plaintext, err := f.decryptFooter(footer)
+	if err != nil {
+		return nil, fmt.Errorf("reading encrypted footer of parquet file: %w", err)
+	}
+	metadata := format.FileMetaData{}
+	if err := thrift.Unmarshal(&r.protocol, plaintext, &metadata); err != nil {
+		return nil, fmt.Errorf("reading parquet file metadata: %w", err)
+	}
+	if err := r.rewriteColumns(&metadata); err != nil {
+		return nil, err
+	}
+
+	cryptoMetadata := format.FileCryptoMetaData{}
+	if err := thrift.NewDecoder(r.protocol.NewReader(bytes.NewReader(footer))).Decode(&cryptoMetadata); err != nil {
+		return nil, fmt.Errorf("decoding crypto metadata: %w", err)
+	}
+	if cryptoMetadata.KeyMetadata, err = r.keyMetadata(cryptoMetadata.KeyMetadata); err != nil {
+		return nil, fmt.Errorf("rewriting key metadata of footer: %w", err)
+	}
+
+	if plaintext, err = thrift.Marshal(&r.protocol, &metadata); err != nil {
+		return nil, err
+	}
+	newFooter, err := thrift.Marshal(&r.protocol, &cryptoMetadata)
+	if err != nil {
+		return nil, err
+	}
+	d := f.decryption
+	return d.footer.encrypt(newFooter, plaintext, footerModule, moduleAAD(d.aad, footerModule, -1, -1, -1))
+}
+
+func (r *keyMetadataRewriter) rewritePlaintextFooter(config *DecryptionConfig, footer []byte) ([]byte, error) {
+	f := &File{config: &FileConfig{Decryption: config}}
+	signature, err := f.decodeFooter(footer, &f.metadata)
+	if err != nil {
+		return nil, fmt.Errorf("reading parquet file metadata: %w", err)
+	}
+	if f.metadata.EncryptionAlgorithm.AesGcmV1 == nil && f.metadata.EncryptionAlgorithm.AesGcmCtrV1 == nil {
+		return nil, fmt.Errorf("cannot rewrite the key metadata of a parquet file which is not encrypted")
+	}
+	if err := f.openPlaintextFooter(footer, signature); err != nil {
+		return nil, err
+	}
+	d := f.decryption
+	if d.footer == nil {
+		return nil, d.footerErr
+	}
+
+	metadata := &f.metadata
+	if err := r.rewriteColumns(metadata); err != nil {
+		return nil, err
+	}
+	if metadata.FooterSigningKeyMetadata, err = r.keyMetadata(metadata.FooterSigningKeyMetadata); err != nil {
+		return nil, fmt.Errorf("rewriting key metadata of footer: %w", err)
+	}
+
+	newFooter, err := thrift.Marshal(&r.protocol, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if signature, err = signFooter(d.footer.gcm, d.aad, newFooter); err != nil {
+		return nil, err
+	}
+	return append(newFooter, signature...), nil
+}
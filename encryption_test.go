@@ -0,0 +1,890 @@
+package parquet_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/deprecated"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// testDecryptor decrypts the modules of files written with modular encryption,
+// independently from the implementation of the writer.
+type testDecryptor struct {
+	t             *testing.T
+	aadPrefix     []byte
+	aadFileUnique []byte
+	fileAAD       []byte
+	// Whether pages are encrypted with AES-CTR.
+	ctr bool
+}
+
+func newTestDecryptor(t *testing.T, algorithm format.EncryptionAlgorithm) *testDecryptor {
+	d := &testDecryptor{t: t}
+	switch {
+	case algorithm.AesGcmV1 != nil:
+		d.aadPrefix, d.aadFileUnique = algorithm.AesGcmV1.AadPrefix, algorithm.AesGcmV1.AadFileUnique
+	case algorithm.AesGcmCtrV1 != nil:
+		d.aadPrefix, d.aadFileUnique = algorithm.AesGcmCtrV1.AadPrefix, algorithm.AesGcmCtrV1.AadFileUnique
+		d.ctr = true
+	default:
+		t.Fatal("missing encryption algorithm")
+	}
+	d.fileAAD = append(append([]byte{}, d.aadPrefix...), d.aadFileUnique...)
+	return d
+}
+
+func (d *testDecryptor) decrypt(key, module []byte, moduleType byte, ordinals ...int16) []byte {
+	d.t.Helper()
+	length := binary.LittleEndian.Uint32(module)
+	if int(length)+4 != len(module) {
+		d.t.Fatalf("wrong length of encrypted module of type %d: %d+4 != %d", moduleType, length, len(module))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	if d.ctr && (moduleType == 2 || moduleType == 3) {
+		iv := append(append([]byte{}, module[4:16]...), 0, 0, 0, 1)
+		plaintext := make([]byte, len(module)-16)
+		cipher.NewCTR(block, iv).XORKeyStream(plaintext, module[16:])
+		return plaintext
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	aad := append(append([]byte{}, d.fileAAD...), moduleType)
+	for _, ordinal := range ordinals {
+		aad = append(aad, byte(ordinal), byte(ordinal>>8))
+	}
+	plaintext, err := gcm.Open(nil, module[4:16], module[16:], aad)
+	if err != nil {
+		d.t.Fatalf("decrypting module of type %d %v: %v", moduleType, ordinals, err)
+	}
+	return plaintext
+}
+
+// module returns the length-prefixed module at the beginning of b.
+func module(b []byte) []byte {
+	return b[:4+binary.LittleEndian.Uint32(b)]
+}
+
+type encryptedRow struct {
+	ID    int64  `parquet:"id"`
+	SSN   string `parquet:"ssn"`
+	Name  string `parquet:"name"`
+	Email string `parquet:"email"`
+}
+
+var (
+	testFooterKey = []byte("0123456789abcdef")
+	testSSNKey    = []byte("0123456789abcdef0123456789abcdef")
+	testAADPrefix = []byte("test-file")
+)
+
+const (
+	numEncryptedRowGroups = 2
+	numEncryptedRows      = 100
+)
+
+// writeEncryptedFile writes a file where the "id" column is encrypted with the
+// footer key, the "ssn" column with its own key, and the other columns are not
+// encrypted.
+func writeEncryptedFile(t *testing.T, algorithm parquet.EncryptionAlgorithm) []byte {
+	return writeEncryptedFileWithFooter(t, algorithm, false)
+}
+
+// writeEncryptedFileWithFooter is like writeEncryptedFile but writes the footer
+// in plaintext when plaintextFooter is true.
+func writeEncryptedFileWithFooter(t *testing.T, algorithm parquet.EncryptionAlgorithm, plaintextFooter bool) []byte {
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b,
+		parquet.Compression(&parquet.Uncompressed),
+		parquet.PageBufferSize(256),
+		parquet.BloomFilters(parquet.SplitBlockFilter("ssn")),
+		parquet.Encryption(&parquet.EncryptionConfig{
+			FooterKey:         testFooterKey,
+			FooterKeyMetadata: []byte("footer-key"),
+			ColumnKeys: map[string]parquet.ColumnEncryptionKey{
+				"id":  {},
+				"ssn": {Key: testSSNKey, KeyMetadata: []byte("ssn-key")},
+			},
+			AADPrefix:       testAADPrefix,
+			Algorithm:       algorithm,
+			PlaintextFooter: plaintextFooter,
+		}),
+	)
+
+	for i := 0; i < numEncryptedRowGroups*numEncryptedRows; i++ {
+		if err := w.Write(makeEncryptedRow(i)); err != nil {
+			t.Fatal(err)
+		}
+		if i%numEncryptedRows == numEncryptedRows-1 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func makeEncryptedRow(i int) *encryptedRow {
+	return &encryptedRow{
+		ID:    int64(i),
+		SSN:   fmt.Sprintf("123-45-%04d", i),
+		Name:  fmt.Sprintf("name-%d", i),
+		Email: fmt.Sprintf("name-%d@example.com", i),
+	}
+}
+
+var encryptionAlgorithms = []struct {
+	scenario  string
+	algorithm parquet.EncryptionAlgorithm
+}{
+	{scenario: "AES_GCM_V1", algorithm: parquet.AesGcmV1},
+	{scenario: "AES_GCM_CTR_V1", algorithm: parquet.AesGcmCtrV1},
+}
+
+func TestWriterEncryption(t *testing.T) {
+	for _, test := range encryptionAlgorithms {
+		t.Run(test.scenario, func(t *testing.T) { testWriterEncryption(t, test.algorithm) })
+	}
+}
+
+func testWriterEncryption(t *testing.T, algorithm parquet.EncryptionAlgorithm) {
+	footerKey, ssnKey, aadPrefix := testFooterKey, testSSNKey, testAADPrefix
+	const numRowGroups = numEncryptedRowGroups
+	const rowsPerGroup = numEncryptedRows
+
+	data := writeEncryptedFile(t, algorithm)
+	if string(data[:4]) != "PARE" || string(data[len(data)-4:]) != "PARE" {
+		t.Fatalf("wrong magic bytes of encrypted file: %q %q", data[:4], data[len(data)-4:])
+	}
+	if bytes.Contains(data, []byte("123-45-0042")) || bytes.Contains(data, []byte("email")) {
+		t.Fatal("encrypted file contains plaintext values or metadata")
+	}
+
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-(footerSize+8) : len(data)-8]
+	protocol := new(thrift.CompactProtocol)
+	cryptoMetadata := format.FileCryptoMetaData{}
+	if err := thrift.NewDecoder(protocol.NewReader(bytes.NewReader(footer))).Decode(&cryptoMetadata); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDecryptor(t, cryptoMetadata.EncryptionAlgorithm)
+	if d.ctr != (algorithm == parquet.AesGcmCtrV1) {
+		t.Fatalf("wrong encryption algorithm: %+v", cryptoMetadata.EncryptionAlgorithm)
+	}
+	if !bytes.Equal(d.aadPrefix, aadPrefix) || len(d.aadFileUnique) == 0 {
+		t.Fatalf("wrong AAD of the file: prefix=%q unique=%x", d.aadPrefix, d.aadFileUnique)
+	}
+	if string(cryptoMetadata.KeyMetadata) != "footer-key" {
+		t.Errorf("wrong footer key metadata: %q", cryptoMetadata.KeyMetadata)
+	}
+
+	// The encrypted footer module follows the crypto metadata.
+	cryptoMetadataBytes, err := thrift.Marshal(protocol, &cryptoMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := format.FileMetaData{}
+	if err := thrift.Unmarshal(protocol, d.decrypt(footerKey, footer[len(cryptoMetadataBytes):], 0), &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.NumRows != numRowGroups*rowsPerGroup || len(metadata.RowGroups) != numRowGroups {
+		t.Fatalf("wrong file metadata: %d rows in %d row groups", metadata.NumRows, len(metadata.RowGroups))
+	}
+
+	for i, rowGroup := range metadata.RowGroups {
+		rg := int16(i)
+		if rowGroup.Ordinal != rg {
+			t.Errorf("row group %d: wrong ordinal %d", i, rowGroup.Ordinal)
+		}
+
+		for j, column := range rowGroup.Columns {
+			col := int16(j)
+			var key []byte
+
+			switch j {
+			case 0: // id, encrypted with the footer key
+				if column.CryptoMetadata.EncryptionWithFooterKey == nil {
+					t.Fatalf("row group %d: column %d is not encrypted with the footer key", i, j)
+				}
+				key = footerKey
+			case 1: // ssn, encrypted with its own key
+				columnKey := column.CryptoMetadata.EncryptionWithColumnKey
+				if columnKey == nil {
+					t.Fatalf("row group %d: column %d is not encrypted with a column key", i, j)
+				}
+				if string(columnKey.KeyMetadata) != "ssn-key" || len(columnKey.PathInSchema) != 1 || columnKey.PathInSchema[0] != "ssn" {
+					t.Errorf("row group %d: column %d: wrong crypto metadata: %+v", i, j, columnKey)
+				}
+				if column.MetaData.NumValues != 0 {
+					t.Errorf("row group %d: column %d: metadata of column encrypted with its own key is in the footer", i, j)
+				}
+				if err := thrift.Unmarshal(protocol, d.decrypt(ssnKey, column.EncryptedColumnMetadata, 1, rg, col), &column.MetaData); err != nil {
+					t.Fatal(err)
+				}
+				key = ssnKey
+			default: // name and email, not encrypted
+				if column.CryptoMetadata.EncryptionWithFooterKey != nil || column.CryptoMetadata.EncryptionWithColumnKey != nil {
+					t.Errorf("row group %d: column %d: plaintext column has crypto metadata", i, j)
+				}
+			}
+
+			if column.MetaData.NumValues != rowsPerGroup {
+				t.Fatalf("row group %d: column %d: wrong number of values: %d", i, j, column.MetaData.NumValues)
+			}
+			if key == nil {
+				continue
+			}
+
+			// Decrypt the pages of the column chunk.
+			chunk := data[column.MetaData.DataPageOffset : column.MetaData.DataPageOffset+column.MetaData.TotalCompressedSize]
+			values := []byte{}
+			for page := int16(0); len(chunk) > 0; page++ {
+				headerModule := module(chunk)
+				header := format.PageHeader{}
+				if err := thrift.Unmarshal(protocol, d.decrypt(key, headerModule, 4, rg, col, page), &header); err != nil {
+					t.Fatal(err)
+				}
+				pageModule := chunk[len(headerModule) : len(headerModule)+int(header.CompressedPageSize)]
+				values = append(values, d.decrypt(key, pageModule, 2, rg, col, page)...)
+				chunk = chunk[len(headerModule)+len(pageModule):]
+			}
+			if j == 0 {
+				for k := 0; k < rowsPerGroup; k++ {
+					if id := int64(binary.LittleEndian.Uint64(values[8*k:])); id != int64(i*rowsPerGroup+k) {
+						t.Fatalf("row group %d: wrong decrypted value at index %d: %d", i, k, id)
+					}
+				}
+			}
+
+			// Decrypt the page index of the column chunk.
+			columnIndex := data[column.ColumnIndexOffset : column.ColumnIndexOffset+int64(column.ColumnIndexLength)]
+			if err := thrift.Unmarshal(protocol, d.decrypt(key, columnIndex, 6, rg, col), new(format.ColumnIndex)); err != nil {
+				t.Fatal(err)
+			}
+			offsetIndex := format.OffsetIndex{}
+			encryptedOffsetIndex := data[column.OffsetIndexOffset : column.OffsetIndexOffset+int64(column.OffsetIndexLength)]
+			if err := thrift.Unmarshal(protocol, d.decrypt(key, encryptedOffsetIndex, 7, rg, col), &offsetIndex); err != nil {
+				t.Fatal(err)
+			}
+			for _, page := range offsetIndex.PageLocations {
+				if int64(len(module(data[page.Offset:]))) >= int64(page.CompressedPageSize) {
+					t.Fatalf("row group %d: column %d: page size does not include the encrypted header", i, j)
+				}
+			}
+
+			if j == 1 {
+				bloomFilter := data[column.MetaData.BloomFilterOffset:]
+				header := format.BloomFilterHeader{}
+				headerModule := module(bloomFilter)
+				if err := thrift.Unmarshal(protocol, d.decrypt(key, headerModule, 8, rg, col), &header); err != nil {
+					t.Fatal(err)
+				}
+				bitset := d.decrypt(key, module(bloomFilter[len(headerModule):]), 9, rg, col)
+				if len(bitset) != int(header.NumBytes) {
+					t.Errorf("row group %d: wrong size of bloom filter: %d != %d", i, len(bitset), header.NumBytes)
+				}
+			}
+		}
+	}
+}
+
+// withPlaintextFooter rewrites a file written in encrypted footer mode to the
+// plaintext footer mode, where the file metadata is signed with the footer key.
+func withPlaintextFooter(t *testing.T, data []byte) []byte {
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-(footerSize+8) : len(data)-8]
+	protocol := new(thrift.CompactProtocol)
+	cryptoMetadata := format.FileCryptoMetaData{}
+	r := bytes.NewReader(footer)
+	if err := thrift.NewDecoder(protocol.NewReader(r)).Decode(&cryptoMetadata); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDecryptor(t, cryptoMetadata.EncryptionAlgorithm)
+	metadata := format.FileMetaData{}
+	if err := thrift.Unmarshal(protocol, d.decrypt(testFooterKey, footer[len(footer)-r.Len():], 0), &metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata.EncryptionAlgorithm = cryptoMetadata.EncryptionAlgorithm
+	metadata.FooterSigningKeyMetadata = cryptoMetadata.KeyMetadata
+	plaintext, err := thrift.Marshal(protocol, &metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := aes.NewCipher(testFooterKey)
+	gcm, _ := cipher.NewGCM(block)
+	nonce := []byte("signature-12")
+	sealed := gcm.Seal(nil, nonce, plaintext, append(d.fileAAD, 0))
+
+	b := append([]byte{}, "PAR1"...)
+	b = append(b, data[4:len(data)-(footerSize+8)]...)
+	b = append(b, plaintext...)
+	b = append(b, nonce...)
+	b = append(b, sealed[len(plaintext):]...)
+	b = append(b, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(b[len(b)-4:], uint32(len(plaintext)+len(nonce)+16))
+	return append(b, "PAR1"...)
+}
+
+func TestWriterPlaintextFooter(t *testing.T) {
+	data := writeEncryptedFileWithFooter(t, parquet.AesGcmV1, true)
+	if magic := string(data[:4]) + string(data[len(data)-4:]); magic != "PAR1PAR1" {
+		t.Fatalf("wrong magic bytes of file with plaintext footer: %q", magic)
+	}
+
+	// The statistics of encrypted columns must not be exposed in the footer.
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := data[len(data)-(footerSize+8) : len(data)-8]
+	if bytes.Contains(footer, []byte(makeEncryptedRow(0).SSN)) {
+		t.Error("the plaintext footer contains values of an encrypted column")
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numRows := f.NumRows(); numRows != numEncryptedRowGroups*numEncryptedRows {
+		t.Errorf("wrong number of rows: want=%d got=%d", numEncryptedRowGroups*numEncryptedRows, numRows)
+	}
+	if _, ok := f.Schema().Lookup("ssn"); !ok {
+		t.Error("encrypted column missing from the schema of the file")
+	}
+}
+
+func TestReadEncryptedFile(t *testing.T) {
+	for _, test := range encryptionAlgorithms {
+		t.Run(test.scenario, func(t *testing.T) { testReadEncryptedFile(t, test.algorithm) })
+	}
+}
+
+func testReadEncryptedFile(t *testing.T, algorithm parquet.EncryptionAlgorithm) {
+	data := writeEncryptedFile(t, algorithm)
+
+	keys := map[string][]byte{"footer-key": testFooterKey, "ssn-key": testSSNKey}
+	retriever := parquet.KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+		if key, ok := keys[string(keyMetadata)]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", keyMetadata)
+	})
+
+	for _, file := range []struct {
+		scenario string
+		data     []byte
+	}{
+		{scenario: "encrypted footer", data: data},
+		{scenario: "plaintext footer", data: withPlaintextFooter(t, data)},
+		{scenario: "signed footer", data: writeEncryptedFileWithFooter(t, algorithm, true)},
+	} {
+		t.Run(file.scenario, func(t *testing.T) {
+			for _, config := range []struct {
+				scenario   string
+				decryption *parquet.DecryptionConfig
+			}{
+				{
+					scenario:   "key retriever",
+					decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+				},
+				{
+					scenario: "explicit keys",
+					decryption: &parquet.DecryptionConfig{
+						FooterKey:  testFooterKey,
+						ColumnKeys: map[string][]byte{"ssn": testSSNKey},
+						AADPrefix:  testAADPrefix,
+					},
+				},
+			} {
+				for _, lazy := range []bool{false, true} {
+					t.Run(fmt.Sprintf("%s/lazy=%t", config.scenario, lazy), func(t *testing.T) {
+						f, err := parquet.OpenFile(bytes.NewReader(file.data), int64(len(file.data)),
+							parquet.Decryption(config.decryption),
+							parquet.LazyRowGroups(lazy),
+							parquet.VerifyColumnChunks(true),
+						)
+						if err != nil {
+							t.Fatal(err)
+						}
+						assertEncryptedFileContent(t, f)
+					})
+				}
+			}
+		})
+	}
+}
+
+func assertEncryptedFileContent(t *testing.T, f *parquet.File) {
+	t.Helper()
+	r := parquet.NewReader(f)
+	for i := 0; i < numEncryptedRowGroups*numEncryptedRows; i++ {
+		row := encryptedRow{}
+		if err := r.Read(&row); err != nil {
+			t.Fatalf("reading row %d: %v", i, err)
+		}
+		if want := makeEncryptedRow(i); row != *want {
+			t.Fatalf("wrong row at index %d: want=%+v got=%+v", i, *want, row)
+		}
+	}
+	if err := r.Read(new(encryptedRow)); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last row but got %v", err)
+	}
+
+	for i := 0; i < f.NumRowGroups(); i++ {
+		rowGroup, err := f.RowGroup(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ssn, _ := parquet.LookupColumnChunk(rowGroup, "ssn")
+		value := parquet.ValueOf(makeEncryptedRow(i*numEncryptedRows + 1).SSN)
+		if ok, err := parquet.MayContain(ssn, value); err != nil || !ok {
+			t.Errorf("row group %d: value not found in the encrypted bloom filter: %t %v", i, ok, err)
+		}
+		for _, chunk := range rowGroup.ColumnChunks() {
+			columnIndex, err := parquet.ReadColumnIndex(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if columnIndex == nil || columnIndex.NumPages() == 0 {
+				t.Fatalf("row group %d: column %d: missing column index", i, chunk.Column())
+			}
+			offsetIndex, err := parquet.ReadOffsetIndex(chunk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if offsetIndex.NumPages() != columnIndex.NumPages() {
+				t.Errorf("row group %d: column %d: the offset index has %d pages but the column index has %d pages",
+					i, chunk.Column(), offsetIndex.NumPages(), columnIndex.NumPages())
+			}
+		}
+	}
+}
+
+func TestReadEncryptedFileWithoutColumnKey(t *testing.T) {
+	data := writeEncryptedFile(t, parquet.AesGcmV1)
+
+	for _, file := range []struct {
+		scenario   string
+		data       []byte
+		decryption parquet.FileOption
+	}{
+		{
+			scenario:   "encrypted footer",
+			data:       data,
+			decryption: parquet.Decryption(&parquet.DecryptionConfig{FooterKey: testFooterKey}),
+		},
+		{
+			scenario:   "plaintext footer without keys",
+			data:       withPlaintextFooter(t, data),
+			decryption: parquet.Decryption(nil),
+		},
+		{
+			scenario:   "signed footer without keys",
+			data:       writeEncryptedFileWithFooter(t, parquet.AesGcmV1, true),
+			decryption: parquet.Decryption(nil),
+		},
+	} {
+		t.Run(file.scenario, func(t *testing.T) {
+			f, err := parquet.OpenFile(bytes.NewReader(file.data), int64(len(file.data)), file.decryption)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rowGroup := f.RowGroups()[0]
+
+			name, _ := parquet.LookupColumnChunk(rowGroup, "name")
+			pages := name.Pages()
+			defer pages.Close()
+			page, err := pages.ReadPage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := make([]parquet.Value, 1)
+			if _, err := page.Values().ReadValues(values); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if name := values[0].String(); name != "name-0" {
+				t.Errorf("wrong value of plaintext column: %q", name)
+			}
+
+			ssn, _ := parquet.LookupColumnChunk(rowGroup, "ssn")
+			ssnPages := ssn.Pages()
+			defer ssnPages.Close()
+			if _, err := ssnPages.ReadPage(); !errors.Is(err, parquet.ErrMissingEncryptionKey) {
+				t.Errorf("reading pages of column without key: want=%v got=%v", parquet.ErrMissingEncryptionKey, err)
+			}
+			if _, err := parquet.ReadColumnIndex(ssn); !errors.Is(err, parquet.ErrMissingEncryptionKey) {
+				t.Errorf("reading column index of column without key: want=%v got=%v", parquet.ErrMissingEncryptionKey, err)
+			}
+		})
+	}
+}
+
+func TestReadEncryptedFileErrors(t *testing.T) {
+	data := writeEncryptedFile(t, parquet.AesGcmV1)
+	plaintextFooter := withPlaintextFooter(t, data)
+	otherKey := []byte("fedcba9876543210")
+
+	for _, test := range []struct {
+		scenario   string
+		data       []byte
+		decryption *parquet.DecryptionConfig
+	}{
+		{scenario: "missing footer key", data: data},
+		{scenario: "wrong footer key", data: data, decryption: &parquet.DecryptionConfig{FooterKey: otherKey}},
+		{scenario: "wrong AAD prefix", data: data, decryption: &parquet.DecryptionConfig{FooterKey: testFooterKey, AADPrefix: []byte("other")}},
+		{scenario: "wrong signing key", data: plaintextFooter, decryption: &parquet.DecryptionConfig{FooterKey: otherKey}},
+		{
+			scenario: "tampered plaintext footer",
+			data: func() []byte {
+				b := append([]byte{}, plaintextFooter...)
+				i := bytes.LastIndex(b, []byte("parquet-go"))
+				b[i] = 'P'
+				return b
+			}(),
+			decryption: &parquet.DecryptionConfig{FooterKey: testFooterKey},
+		},
+		{
+			scenario: "tampered signed footer",
+			data: func() []byte {
+				b := writeEncryptedFileWithFooter(t, parquet.AesGcmV1, true)
+				i := bytes.LastIndex(b, []byte("parquet-go"))
+				b[i] = 'P'
+				return b
+			}(),
+			decryption: &parquet.DecryptionConfig{FooterKey: testFooterKey},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			options := []parquet.FileOption{}
+			if test.decryption != nil {
+				options = append(options, parquet.Decryption(test.decryption))
+			}
+			if _, err := parquet.OpenFile(bytes.NewReader(test.data), int64(len(test.data)), options...); err == nil {
+				t.Error("opening the file did not fail")
+			}
+		})
+	}
+
+	t.Run("wrong column key", func(t *testing.T) {
+		f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.SkipPageIndex(true),
+			parquet.Decryption(&parquet.DecryptionConfig{
+				FooterKey:  testFooterKey,
+				ColumnKeys: map[string][]byte{"ssn": otherKey},
+			}),
+		)
+		if err == nil {
+			t.Fatalf("opening file with the wrong column key did not fail: %d row groups", f.NumRowGroups())
+		}
+	})
+}
+
+func TestWriterEncryptionConfig(t *testing.T) {
+	if _, err := parquet.NewWriterConfig(parquet.Encryption(&parquet.EncryptionConfig{FooterKey: []byte("short")})); err == nil {
+		t.Error("invalid footer key length was accepted")
+	}
+	if _, err := parquet.NewWriterConfig(parquet.Encryption(&parquet.EncryptionConfig{
+		FooterKey:  make([]byte, 16),
+		ColumnKeys: map[string]parquet.ColumnEncryptionKey{"a": {Key: make([]byte, 10)}},
+	})); err == nil {
+		t.Error("invalid column key length was accepted")
+	}
+	if _, err := parquet.NewWriterConfig(parquet.Encryption(&parquet.EncryptionConfig{
+		FooterKey: make([]byte, 16),
+		Algorithm: parquet.EncryptionAlgorithm(42),
+	})); err == nil {
+		t.Error("invalid encryption algorithm was accepted")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic when configuring a key for a missing column")
+		}
+	}()
+	type Row struct{ A int64 }
+	parquet.NewWriter(new(bytes.Buffer), parquet.SchemaOf(Row{}), parquet.Encryption(&parquet.EncryptionConfig{
+		FooterKey:  make([]byte, 16),
+		ColumnKeys: map[string]parquet.ColumnEncryptionKey{"missing": {}},
+	}))
+}
+
+func TestRewriteKeyMetadata(t *testing.T) {
+	data := writeEncryptedFile(t, parquet.AesGcmV1)
+
+	keys := map[string][]byte{"footer-key-v2": testFooterKey, "ssn-key-v2": testSSNKey}
+	retriever := parquet.KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+		if key, ok := keys[string(keyMetadata)]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", keyMetadata)
+	})
+
+	for _, file := range []struct {
+		scenario string
+		data     []byte
+	}{
+		{scenario: "encrypted footer", data: data},
+		{scenario: "plaintext footer", data: withPlaintextFooter(t, data)},
+		{scenario: "signed footer", data: writeEncryptedFileWithFooter(t, parquet.AesGcmV1, true)},
+	} {
+		t.Run(file.scenario, func(t *testing.T) {
+			rewrites := 0
+			b := new(bytes.Buffer)
+			err := parquet.RewriteKeyMetadata(b, bytes.NewReader(file.data), int64(len(file.data)),
+				&parquet.DecryptionConfig{FooterKey: testFooterKey},
+				func(keyMetadata []byte) ([]byte, error) {
+					rewrites++
+					return append(keyMetadata, "-v2"...), nil
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rewrites != 2 {
+				t.Errorf("wrong number of rewritten key metadata: want=2 got=%d", rewrites)
+			}
+
+			footerSize := int(binary.LittleEndian.Uint32(file.data[len(file.data)-8:]))
+			dataEnd := len(file.data) - (footerSize + 8)
+			if !bytes.Equal(b.Bytes()[:dataEnd], file.data[:dataEnd]) {
+				t.Error("the content of the file was modified")
+			}
+
+			rewritten := b.Bytes()
+			f, err := parquet.OpenFile(bytes.NewReader(rewritten), int64(len(rewritten)),
+				parquet.Decryption(&parquet.DecryptionConfig{KeyRetriever: retriever}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEncryptedFileContent(t, f)
+		})
+	}
+
+	t.Run("wrong footer key", func(t *testing.T) {
+		err := parquet.RewriteKeyMetadata(io.Discard, bytes.NewReader(data), int64(len(data)),
+			&parquet.DecryptionConfig{FooterKey: []byte("fedcba9876543210")},
+			func(keyMetadata []byte) ([]byte, error) { return keyMetadata, nil },
+		)
+		if err == nil {
+			t.Error("no error rewriting the key metadata with the wrong footer key")
+		}
+	})
+
+	t.Run("plaintext file", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		if err := parquet.Write(b, []encryptedRow{*makeEncryptedRow(0)}); err != nil {
+			t.Fatal(err)
+		}
+		err := parquet.RewriteKeyMetadata(io.Discard, bytes.NewReader(b.Bytes()), int64(b.Len()),
+			&parquet.DecryptionConfig{FooterKey: testFooterKey},
+			func(keyMetadata []byte) ([]byte, error) { return keyMetadata, nil },
+		)
+		if err == nil {
+			t.Error("no error rewriting the key metadata of a file which is not encrypted")
+		}
+	})
+}
+
+// The interop files were written by the parquet implementation of Apache Arrow
+// with the configurations of the encryption interop tests of parquet-mr, whose
+// files are published in the parquet-testing repository: the keys, the key
+// metadata, the AAD prefix, and the content of the columns are the same.
+var (
+	interopFooterKey  = []byte("0123456789012345")
+	interopColumnKey1 = []byte("1234567890123450")
+	interopColumnKey2 = []byte("1234567890123451")
+	interopAADPrefix  = []byte("tester")
+)
+
+const (
+	numInteropRowGroups = 5
+	numInteropRows      = 50
+)
+
+type interopRow struct {
+	Boolean   bool             `parquet:"boolean_field"`
+	Int32     int32            `parquet:"int32_field"`
+	Int64     []int64          `parquet:"int64_field"`
+	Int96     deprecated.Int96 `parquet:"int96_field"`
+	Float     float32          `parquet:"float_field"`
+	Double    float64          `parquet:"double_field"`
+	ByteArray []byte           `parquet:"ba_field,optional"`
+	FixedLen  [10]byte         `parquet:"flba_field"`
+}
+
+func makeInteropRow(i int) interopRow {
+	row := interopRow{
+		Boolean: i%2 == 0,
+		Int32:   int32(i),
+		Int64:   []int64{int64(2*i) * 1e12, int64(2*i+1) * 1e12},
+		Int96:   deprecated.Int96{uint32(i), uint32(i + 1), uint32(i + 2)},
+		Float:   float32(i) * 1.1,
+		Double:  float64(i) * 1.1111111,
+	}
+	if i%2 == 0 {
+		row.ByteArray = []byte(fmt.Sprintf("parquet%03d", i))
+	}
+	for j := range row.FixedLen {
+		row.FixedLen[j] = byte(i)
+	}
+	return row
+}
+
+func TestReadEncryptedInteropFiles(t *testing.T) {
+	keys := map[string][]byte{"kf": interopFooterKey, "kc1": interopColumnKey1, "kc2": interopColumnKey2}
+	retriever := parquet.KeyRetrieverFunc(func(keyMetadata []byte) ([]byte, error) {
+		if key, ok := keys[string(keyMetadata)]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key %q", keyMetadata)
+	})
+	explicitKeys := &parquet.DecryptionConfig{
+		FooterKey:  interopFooterKey,
+		ColumnKeys: map[string][]byte{"double_field": interopColumnKey1, "float_field": interopColumnKey2},
+	}
+
+	for _, test := range []struct {
+		file       string
+		decryption *parquet.DecryptionConfig
+	}{
+		{
+			file:       "uniform_encryption.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+		},
+		{
+			file:       "encrypt_columns_and_footer.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+		},
+		{
+			file:       "encrypt_columns_and_footer.parquet.encrypted",
+			decryption: explicitKeys,
+		},
+		{
+			// The signature of the plaintext footer is verified with the
+			// footer key.
+			file:       "encrypt_columns_plaintext_footer.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+		},
+		{
+			file:       "encrypt_columns_plaintext_footer.parquet.encrypted",
+			decryption: explicitKeys,
+		},
+		{
+			file:       "encrypt_columns_and_footer_aad.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+		},
+		{
+			file:       "encrypt_columns_and_footer_aad.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever, AADPrefix: interopAADPrefix},
+		},
+		{
+			file:       "encrypt_columns_and_footer_disable_aad_storage.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever, AADPrefix: interopAADPrefix},
+		},
+		{
+			file:       "encrypt_columns_and_footer_ctr.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{KeyRetriever: retriever},
+		},
+	} {
+		scenario := test.file
+		if test.decryption.KeyRetriever == nil {
+			scenario += "/explicit keys"
+		} else if test.decryption.AADPrefix != nil {
+			scenario += "/AAD prefix"
+		}
+		t.Run(scenario, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.Decryption(test.decryption))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := f.NumRowGroups(); n != numInteropRowGroups {
+				t.Fatalf("wrong number of row groups: want=%d got=%d", numInteropRowGroups, n)
+			}
+
+			r := parquet.NewReader(f)
+			for i := 0; i < numInteropRowGroups*numInteropRows; i++ {
+				row := interopRow{}
+				if err := r.Read(&row); err != nil {
+					t.Fatalf("reading row %d: %v", i, err)
+				}
+				if want := makeInteropRow(i % numInteropRows); !reflect.DeepEqual(row, want) {
+					t.Fatalf("wrong row at index %d:\nwant: %+v\ngot:  %+v", i, want, row)
+				}
+			}
+			if err := r.Read(new(interopRow)); err != io.EOF {
+				t.Fatalf("expected io.EOF after the last row but got %v", err)
+			}
+		})
+	}
+}
+
+func TestReadEncryptedInteropFilesErrors(t *testing.T) {
+	otherKey := []byte("fedcba9876543210")
+
+	for _, test := range []struct {
+		scenario   string
+		file       string
+		tamper     func([]byte)
+		decryption *parquet.DecryptionConfig
+	}{
+		{
+			scenario:   "missing AAD prefix",
+			file:       "encrypt_columns_and_footer_disable_aad_storage.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{FooterKey: interopFooterKey},
+		},
+		{
+			scenario:   "wrong AAD prefix",
+			file:       "encrypt_columns_and_footer_aad.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{FooterKey: interopFooterKey, AADPrefix: []byte("other")},
+		},
+		{
+			scenario:   "wrong signing key",
+			file:       "encrypt_columns_plaintext_footer.parquet.encrypted",
+			decryption: &parquet.DecryptionConfig{FooterKey: otherKey},
+		},
+		{
+			// The signature precedes the length of the footer and the magic
+			// footer: a 12 bytes nonce followed by a 16 bytes tag.
+			scenario: "tampered signature",
+			file:     "encrypt_columns_plaintext_footer.parquet.encrypted",
+			tamper: func(b []byte) {
+				b[len(b)-8-1] ^= 1
+			},
+			decryption: &parquet.DecryptionConfig{FooterKey: interopFooterKey},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", test.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.tamper != nil {
+				test.tamper(data)
+			}
+			if _, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.Decryption(test.decryption)); err == nil {
+				t.Error("opening the file did not fail")
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package parquet
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
@@ -49,8 +50,61 @@ var (
 	// ErrUnexpectedDefinitionLevels is an error returned when attempting to
 	// decode definition levels into a page which is part of a required column.
 	ErrUnexpectedDefinitionLevels = errors.New("unexpected definition levels")
+
+	// ErrInconsistentMetadata is an error returned when the VerifyColumnChunks
+	// option is enabled and the offsets or sizes recorded in the metadata of a
+	// parquet file do not match the layout of the file.
+	ErrInconsistentMetadata = errors.New("inconsistent parquet file metadata")
+
+	// ErrMissingPageIndex is an error returned when attempting to select pages
+	// by their statistics in a column chunk which has no page index.
+	ErrMissingPageIndex = errors.New("missing page index")
+
+	// ErrInvalidUTF8 is an error returned when the ValidateUTF8 option is
+	// enabled and a value of a STRING column is not a valid UTF-8 string.
+	ErrInvalidUTF8 = errors.New("invalid UTF-8 string")
+
+	// ErrMissingEncryptionKey is an error returned when attempting to read an
+	// encrypted part of a parquet file without having its encryption key.
+	ErrMissingEncryptionKey = errors.New("missing encryption key")
+
+	// ErrNullValue is an error returned by the checked accessors of Value when
+	// called on a null value.
+	ErrNullValue = errors.New("null parquet value")
+
+	// ErrInvalidConversion is an error returned by the checked accessors of
+	// Value when the value cannot be converted to the requested type without
+	// loss.
+	ErrInvalidConversion = errors.New("invalid parquet value conversion")
 )
 
+// PageError is the type of errors reported to the handler installed with the
+// OnPageError file option when a page could not be read.
+type PageError struct {
+	// Path of the column that the page belongs to.
+	Path []string
+	// Index of the leaf column that the page belongs to.
+	Column int
+	// Index of the row group that the page belongs to.
+	RowGroup int
+	// Index of the page within its column chunk.
+	Page int
+	// Index of the first row of the page within its row group, and number of
+	// rows that the page contained. NumRows is -1 when the number of rows could
+	// not be determined (e.g. data page v1 in a column chunk without an offset
+	// index).
+	FirstRowIndex int64
+	NumRows       int64
+	// The error that caused the page to be skipped.
+	Err error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("page %d of column %q in row group %d: %v", e.Page, columnPath(e.Path), e.RowGroup, e.Err)
+}
+
+func (e *PageError) Unwrap() error { return e.Err }
+
 type errno int
 
 const (
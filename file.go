@@ -2,6 +2,7 @@ package parquet
 
 import (
 	"bufio"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
@@ -22,6 +23,7 @@ const (
 // File represents a parquet file. The layout of a Parquet file can be found
 // here: https://github.com/apache/parquet-format#file-format
 type File struct {
+	config        *FileConfig
 	metadata      format.FileMetaData
 	protocol      thrift.CompactProtocol
 	reader        io.ReaderAt
@@ -31,6 +33,9 @@ type File struct {
 	columnIndexes []format.ColumnIndex
 	offsetIndexes []format.OffsetIndex
 	rowGroups     []RowGroup
+	lazy          *lazyRowGroups
+	bloomFilters  bloomFilterCache
+	decryption    *fileDecryption
 }
 
 // OpenFile opens a parquet file and reads the content between offset 0 and the given
@@ -40,47 +45,95 @@ type File struct {
 // parts of the file are left untouched; this means that successfully opening
 // a file does not validate that the pages have valid checksums.
 func OpenFile(r io.ReaderAt, size int64, options ...FileOption) (*File, error) {
+	return OpenFileContext(context.Background(), r, size, options...)
+}
+
+// OpenFileContext is like OpenFile but uses ctx to cancel the reads of the
+// file metadata. The context is only used while opening the file, subsequent
+// reads of the file are done with the context passed to the methods reading
+// pages or rows (e.g. ReadPageContext, ReadRowsContext).
+func OpenFileContext(ctx context.Context, r io.ReaderAt, size int64, options ...FileOption) (*File, error) {
 	b := make([]byte, 8)
-	f := &File{reader: r, size: size}
 	c, err := NewFileConfig(options...)
 	if err != nil {
 		return nil, err
 	}
+	readCacheName := c.ReadCacheName
+	if c.ReadCache != nil {
+		if readCacheName, err = readCacheNameOf(r, size, readCacheName); err != nil {
+			return nil, err
+		}
+	}
+	if c.Metrics != nil {
+		r = &metricsReaderAt{reader: r, metrics: c.Metrics}
+	}
+	if c.ReadCache != nil {
+		r = &cachedReaderAt{
+			reader:    r,
+			size:      size,
+			cache:     c.ReadCache,
+			file:      readCacheName,
+			blockSize: int64(c.ReadCacheBlockSize),
+		}
+	}
+	f := &File{config: c, reader: r, size: size}
+	f.bloomFilters.limit = int64(c.BloomFilterCacheSize)
+	// The reader used while opening the file carries the context, but must
+	// not be retained (e.g. by bloom filters) since the context may expire.
+	rc := &contextReaderAt{ctx: ctx, reader: r}
 
-	if _, err := r.ReadAt(b[:4], 0); err != nil {
+	if _, err := rc.ReadAt(b[:4], 0); err != nil {
 		return nil, fmt.Errorf("reading magic header of parquet file: %w", err)
 	}
-	if string(b[:4]) != "PAR1" {
+	// Files written with modular encryption in encrypted footer mode have a
+	// different magic number.
+	magic := string(b[:4])
+	if magic != "PAR1" && magic != "PARE" {
 		return nil, fmt.Errorf("invalid magic header of parquet file: %q", b[:4])
 	}
 
-	if _, err := r.ReadAt(b[:8], size-8); err != nil {
+	if _, err := rc.ReadAt(b[:8], size-8); err != nil {
 		return nil, fmt.Errorf("reading magic footer of parquet file: %w", err)
 	}
-	if string(b[4:8]) != "PAR1" {
+	if string(b[4:8]) != magic {
 		return nil, fmt.Errorf("invalid magic footer of parquet file: %q", b[4:8])
 	}
 
 	footerSize := int64(binary.LittleEndian.Uint32(b[:4]))
 	footerData := make([]byte, footerSize)
 
-	if _, err := f.reader.ReadAt(footerData, size-(footerSize+8)); err != nil {
+	if _, err := rc.ReadAt(footerData, size-(footerSize+8)); err != nil {
 		return nil, fmt.Errorf("reading footer of parquet file: %w", err)
 	}
-	if err := thrift.Unmarshal(&f.protocol, footerData, &f.metadata); err != nil {
+	if magic == "PARE" {
+		if footerData, err = f.decryptFooter(footerData); err != nil {
+			return nil, fmt.Errorf("reading encrypted footer of parquet file: %w", err)
+		}
+	}
+	if c.LazyRowGroups {
+		err = f.decodeLazyMetadata(footerData)
+	} else {
+		err = f.decodeMetadata(footerData)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("reading parquet file metadata: %w", err)
 	}
 	if len(f.metadata.Schema) == 0 {
 		return nil, ErrMissingRootColumn
 	}
 
-	if !c.SkipPageIndex {
-		if f.columnIndexes, f.offsetIndexes, err = f.ReadPageIndex(); err != nil {
+	if !c.SkipPageIndex && !c.LazyRowGroups {
+		if f.columnIndexes, f.offsetIndexes, err = f.readPageIndex(rc); err != nil {
 			return nil, fmt.Errorf("reading page index of parquet file: %w", err)
 		}
 	}
 
-	if f.root, err = openColumns(f); err != nil {
+	rowGroupsMetadata := f.metadata.RowGroups
+	if f.lazy != nil {
+		rowGroupsMetadata = f.lazy.firstRowGroup()
+	}
+
+	if f.root, err = openColumns(f, rowGroupsMetadata); err != nil {
 		return nil, fmt.Errorf("opening columns of parquet file: %w", err)
 	}
 
@@ -89,36 +142,51 @@ func OpenFile(r io.ReaderAt, size int64, options ...FileOption) (*File, error) {
 	f.schema = schema
 	f.root.forEachLeaf(func(c *Column) { columns = append(columns, c) })
 
+	if len(c.ZstdDictionaries) > 0 {
+		forEachLeafColumnOf(schema, func(leaf leafColumn) {
+			if dict, ok := c.ZstdDictionaries[leaf.path.String()]; ok {
+				column := columns[leaf.columnIndex]
+				column.compression = withZstdDictionary(column.compression, dict, false)
+			}
+		})
+	}
+
+	if f.lazy != nil {
+		f.lazy.columns = columns
+		f.lazy.dataEnd = size - (footerSize + 8)
+		sortKeyValueMetadata(f.metadata.KeyValueMetadata)
+		return f, nil
+	}
+
 	rowGroups := make([]fileRowGroup, len(f.metadata.RowGroups))
 	for i := range rowGroups {
-		rowGroups[i].init(f, schema, columns, &f.metadata.RowGroups[i])
+		var columnIndexes []format.ColumnIndex
+		var offsetIndexes []format.OffsetIndex
+		if f.hasIndexes() {
+			j := int(f.metadata.RowGroups[i].Ordinal) * len(columns)
+			columnIndexes = f.columnIndexes[j : j+len(columns)]
+			offsetIndexes = f.offsetIndexes[j : j+len(columns)]
+		}
+		rowGroups[i].init(f, schema, columns, i, &f.metadata.RowGroups[i], columnIndexes, offsetIndexes)
 	}
 	f.rowGroups = make([]RowGroup, len(rowGroups))
 	for i := range rowGroups {
 		f.rowGroups[i] = &rowGroups[i]
 	}
 
-	if !c.SkipBloomFilters {
-		h := format.BloomFilterHeader{}
-		p := thrift.CompactProtocol{}
-		s := io.NewSectionReader(r, 0, size)
-		d := thrift.NewDecoder(p.NewReader(s))
+	if c.VerifyColumnChunks {
+		dataEnd := size - (footerSize + 8)
+		for i := range rowGroups {
+			if err := rowGroups[i].verify(dataEnd); err != nil {
+				return nil, err
+			}
+		}
+	}
 
+	if !c.SkipBloomFilters {
 		for i := range rowGroups {
-			g := &rowGroups[i]
-
-			for j := range g.columns {
-				c := g.columns[j].(*fileColumnChunk)
-
-				if offset := c.chunk.MetaData.BloomFilterOffset; offset > 0 {
-					s.Seek(offset, io.SeekStart)
-					h = format.BloomFilterHeader{}
-					if err := d.Decode(&h); err != nil {
-						return nil, err
-					}
-					offset, _ = s.Seek(0, io.SeekCurrent)
-					c.bloomFilter = newBloomFilter(r, offset, &h)
-				}
+			if err := f.readBloomFilters(rc, &rowGroups[i]); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -127,6 +195,27 @@ func OpenFile(r io.ReaderAt, size int64, options ...FileOption) (*File, error) {
 	return f, nil
 }
 
+// readBloomFilters reads the headers of the bloom filters of columns in the row
+// group from r. The bits of the filters are not read until they are checked,
+// and retain the file reader rather than r, which may be bound to the context
+// used to open the file.
+func (f *File) readBloomFilters(r io.ReaderAt, g *fileRowGroup) error {
+	for j := range g.columns {
+		c := g.columns[j].(*fileColumnChunk)
+
+		// Like their page indexes, the bloom filters of column chunks
+		// encrypted with a key that is not available are omitted.
+		if offset := c.chunk.MetaData.BloomFilterOffset; offset > 0 && c.canDecrypt() {
+			filter, err := newBloomFilter(f.reader, r, offset, &f.bloomFilters, c.decryption)
+			if err != nil {
+				return err
+			}
+			c.bloomFilter = filter
+		}
+	}
+	return nil
+}
+
 // ReadPageIndex reads the page index section of the parquet file f.
 //
 // If the file did not contain a page index, the method returns two empty slices
@@ -152,21 +241,47 @@ func OpenFile(r io.ReaderAt, size int64, options ...FileOption) (*File, error) {
 // This method is useful in combination with the SkipPageIndex option to delay
 // reading the page index section until after the file was opened. Note that in
 // this case the page index is not cached within the file, programs are expected
-// to make use of independently from the parquet package.
+// to make use of independently from the parquet package. Programs that only
+// need the indexes of some column chunks should use ReadColumnIndex and
+// ReadOffsetIndex instead.
+//
+// When the file was opened with the LazyRowGroups option, the method decodes the
+// metadata of all row groups.
 func (f *File) ReadPageIndex() ([]format.ColumnIndex, []format.OffsetIndex, error) {
-	columnIndexOffset := f.metadata.RowGroups[0].Columns[0].ColumnIndexOffset
-	offsetIndexOffset := f.metadata.RowGroups[0].Columns[0].OffsetIndexOffset
+	if f.lazy != nil {
+		rowGroups, err := f.lazy.metadata(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f.readPageIndexOf(f.reader, 0, rowGroups)
+	}
+	return f.readPageIndex(f.reader)
+}
+
+func (f *File) readPageIndex(r io.ReaderAt) ([]format.ColumnIndex, []format.OffsetIndex, error) {
+	return f.readPageIndexOf(r, 0, f.metadata.RowGroups)
+}
+
+// readPageIndexOf reads the page index of the given row groups, the first of
+// which is at index firstRowGroup in the file.
+func (f *File) readPageIndexOf(r io.ReaderAt, firstRowGroup int, rowGroups []format.RowGroup) ([]format.ColumnIndex, []format.OffsetIndex, error) {
+	if len(rowGroups) == 0 || len(rowGroups[0].Columns) == 0 {
+		return nil, nil, nil
+	}
+
+	columnIndexOffset := int64(0)
+	offsetIndexOffset := rowGroups[0].Columns[0].OffsetIndexOffset
 	columnIndexLength := int64(0)
 	offsetIndexLength := int64(0)
 
-	if columnIndexOffset == 0 || offsetIndexOffset == 0 {
+	if offsetIndexOffset == 0 {
 		return nil, nil, nil
 	}
 
 	forEachColumnChunk := func(do func(int, int, *format.ColumnChunk) error) error {
-		for i := range f.metadata.RowGroups {
-			for j := range f.metadata.RowGroups[i].Columns {
-				c := &f.metadata.RowGroups[i].Columns[j]
+		for i := range rowGroups {
+			for j := range rowGroups[i].Columns {
+				c := &rowGroups[i].Columns[j]
 				if err := do(i, j, c); err != nil {
 					return err
 				}
@@ -175,14 +290,20 @@ func (f *File) ReadPageIndex() ([]format.ColumnIndex, []format.OffsetIndex, erro
 		return nil
 	}
 
+	// The column index of column chunks may be omitted, for example when a
+	// page of floating point values contains only NaN, so the column index
+	// section starts at the first column chunk which has one.
 	forEachColumnChunk(func(_, _ int, c *format.ColumnChunk) error {
+		if columnIndexOffset == 0 {
+			columnIndexOffset = c.ColumnIndexOffset
+		}
 		columnIndexLength += int64(c.ColumnIndexLength)
 		offsetIndexLength += int64(c.OffsetIndexLength)
 		return nil
 	})
 
-	numRowGroups := len(f.metadata.RowGroups)
-	numColumns := len(f.metadata.RowGroups[0].Columns)
+	numRowGroups := len(rowGroups)
+	numColumns := len(rowGroups[0].Columns)
 	numColumnChunks := numRowGroups * numColumns
 
 	columnIndexes := make([]format.ColumnIndex, numColumnChunks)
@@ -192,14 +313,23 @@ func (f *File) ReadPageIndex() ([]format.ColumnIndex, []format.OffsetIndex, erro
 	if columnIndexOffset > 0 {
 		columnIndexData := indexBuffer[:columnIndexLength]
 
-		if _, err := f.reader.ReadAt(columnIndexData, columnIndexOffset); err != nil {
+		if _, err := r.ReadAt(columnIndexData, columnIndexOffset); err != nil {
 			return nil, nil, fmt.Errorf("reading %d bytes column index at offset %d: %w", columnIndexLength, columnIndexOffset, err)
 		}
 
 		err := forEachColumnChunk(func(i, j int, c *format.ColumnChunk) error {
+			if c.ColumnIndexOffset == 0 {
+				return nil
+			}
 			offset := c.ColumnIndexOffset - columnIndexOffset
 			length := int64(c.ColumnIndexLength)
-			buffer := columnIndexData[offset : offset+length]
+			if offset < 0 || offset+length > int64(len(columnIndexData)) {
+				return fmt.Errorf("column index of rowGroup=%d columnChunk=%d/%d is out of bounds: %w", i, j, numColumns, ErrInconsistentMetadata)
+			}
+			buffer, err := f.decryptPageIndex(columnIndexData[offset:offset+length], firstRowGroup+i, j, c, columnIndexModule)
+			if err != nil || buffer == nil {
+				return err
+			}
 			if err := thrift.Unmarshal(&f.protocol, buffer, &columnIndexes[(i*numColumns)+j]); err != nil {
 				return fmt.Errorf("decoding column index: rowGroup=%d columnChunk=%d/%d: %w", i, j, numColumns, err)
 			}
@@ -213,14 +343,20 @@ func (f *File) ReadPageIndex() ([]format.ColumnIndex, []format.OffsetIndex, erro
 	if offsetIndexOffset > 0 {
 		offsetIndexData := indexBuffer[:offsetIndexLength]
 
-		if _, err := f.reader.ReadAt(offsetIndexData, offsetIndexOffset); err != nil {
+		if _, err := r.ReadAt(offsetIndexData, offsetIndexOffset); err != nil {
 			return nil, nil, fmt.Errorf("reading %d bytes offset index at offset %d: %w", offsetIndexLength, offsetIndexOffset, err)
 		}
 
 		err := forEachColumnChunk(func(i, j int, c *format.ColumnChunk) error {
 			offset := c.OffsetIndexOffset - offsetIndexOffset
 			length := int64(c.OffsetIndexLength)
-			buffer := offsetIndexData[offset : offset+length]
+			if offset < 0 || offset+length > int64(len(offsetIndexData)) {
+				return fmt.Errorf("offset index of rowGroup=%d columnChunk=%d/%d is out of bounds: %w", i, j, numColumns, ErrInconsistentMetadata)
+			}
+			buffer, err := f.decryptPageIndex(offsetIndexData[offset:offset+length], firstRowGroup+i, j, c, offsetIndexModule)
+			if err != nil || buffer == nil {
+				return err
+			}
 			if err := thrift.Unmarshal(&f.protocol, buffer, &offsetIndexes[(i*numColumns)+j]); err != nil {
 				return fmt.Errorf("decoding column index: rowGroup=%d columnChunk=%d/%d: %w", i, j, numColumns, err)
 			}
@@ -234,11 +370,111 @@ func (f *File) ReadPageIndex() ([]format.ColumnIndex, []format.OffsetIndex, erro
 	return columnIndexes, offsetIndexes, nil
 }
 
+// decryptPageIndex returns the plaintext of a section of the page index of the
+// column chunk at the given indexes, or nil if the key of the column chunk is
+// unavailable, in which case its index is left empty.
+func (f *File) decryptPageIndex(buffer []byte, rowGroupIndex, columnIndex int, chunk *format.ColumnChunk, module byte) ([]byte, error) {
+	c := f.decryption.column(rowGroupIndex, columnIndex, chunk)
+	if c == nil {
+		return buffer, nil
+	}
+	if c.err != nil {
+		return nil, nil
+	}
+	plaintext, err := c.decryptInPlace(buffer, module, -1)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting page index of rowGroup=%d columnChunk=%d: %w", rowGroupIndex, columnIndex, err)
+	}
+	return plaintext, nil
+}
+
+// ReadColumnIndex returns the column index of the column chunk.
+//
+// When the column chunk belongs to a parquet file opened with the SkipPageIndex
+// or LazyRowGroups options, the function reads and decodes only the column
+// index of this column chunk, at the offset recorded in its metadata, instead
+// of the page index of the whole file. The index is retained by the column
+// chunk, subsequent calls and its ColumnIndex method return it without reading
+// the file again, and so do the pages opened after the function returned.
+//
+// The returned index is nil if the column chunk has no column index.
+func ReadColumnIndex(chunk ColumnChunk) (ColumnIndex, error) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return chunk.ColumnIndex(), nil
+	}
+	if err := c.readColumnIndex(); err != nil {
+		return nil, err
+	}
+	return c.ColumnIndex(), nil
+}
+
+// ReadOffsetIndex returns the offset index of the column chunk, reading only
+// the offset index of this column chunk from the file if it was not loaded
+// when opening the file. See ReadColumnIndex for details.
+//
+// The returned index is nil if the column chunk has no offset index.
+func ReadOffsetIndex(chunk ColumnChunk) (OffsetIndex, error) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return chunk.OffsetIndex(), nil
+	}
+	if err := c.readOffsetIndex(); err != nil {
+		return nil, err
+	}
+	return c.OffsetIndex(), nil
+}
+
 // NumRows returns the number of rows in the file.
 func (f *File) NumRows() int64 { return f.metadata.NumRows }
 
+// NumRowGroups returns the number of row groups in the file.
+func (f *File) NumRowGroups() int {
+	if f.lazy != nil {
+		return len(f.lazy.rowGroups)
+	}
+	return len(f.rowGroups)
+}
+
+// RowGroup returns the row group at the given index in the file.
+//
+// When the file was opened with the LazyRowGroups option, the metadata of the
+// row group is decoded on the first call, and the method returns an error if
+// it was invalid.
+func (f *File) RowGroup(index int) (RowGroup, error) {
+	if n := f.NumRowGroups(); index < 0 || index >= n {
+		return nil, fmt.Errorf("row group index out of range: %d/%d", index, n)
+	}
+	if f.lazy != nil {
+		return f.lazy.rowGroup(f, index)
+	}
+	return f.rowGroups[index], nil
+}
+
 // RowGroups returns the list of row group in the file.
-func (f *File) RowGroups() []RowGroup { return f.rowGroups }
+//
+// When the file was opened with the LazyRowGroups option, the method decodes
+// the metadata of all row groups. Row groups with invalid metadata are returned
+// as empty row groups which report the decoding error when reading their rows
+// or pages. Programs that need to handle these errors upfront should use
+// NumRowGroups and RowGroup instead.
+func (f *File) RowGroups() []RowGroup {
+	if f.lazy != nil {
+		f.lazy.once.Do(func() {
+			rowGroups := make([]RowGroup, len(f.lazy.rowGroups))
+			for i := range rowGroups {
+				g, err := f.lazy.rowGroup(f, i)
+				if err != nil {
+					rowGroups[i] = newInvalidRowGroup(f.schema, err)
+				} else {
+					rowGroups[i] = g
+				}
+			}
+			f.rowGroups = rowGroups
+		})
+	}
+	return f.rowGroups
+}
 
 // Root returns the root column of f.
 func (f *File) Root() *Column { return f.root }
@@ -253,19 +489,26 @@ func (f *File) Size() int64 { return f.size }
 //
 // The method satisfies the io.ReaderAt interface.
 func (f *File) ReadAt(b []byte, off int64) (int, error) {
+	return f.ReadAtContext(context.Background(), b, off)
+}
+
+// ReadAtContext is like ReadAt but uses ctx to cancel the read.
+//
+// The method satisfies the ReaderAtContext interface.
+func (f *File) ReadAtContext(ctx context.Context, b []byte, off int64) (int, error) {
 	if off < 0 || off >= f.size {
 		return 0, io.EOF
 	}
 
 	if limit := f.size - off; limit < int64(len(b)) {
-		n, err := f.reader.ReadAt(b[:limit], off)
+		n, err := readAtContext(ctx, f.reader, b[:limit], off)
 		if err == nil {
 			err = io.EOF
 		}
 		return n, err
 	}
 
-	return f.reader.ReadAt(b, off)
+	return readAtContext(ctx, f.reader, b, off)
 }
 
 // ColumnIndexes returns the page index of the parquet file f.
@@ -326,7 +569,7 @@ type fileRowGroup struct {
 	sorting  []SortingColumn
 }
 
-func (g *fileRowGroup) init(file *File, schema *Schema, columns []*Column, rowGroup *format.RowGroup) {
+func (g *fileRowGroup) init(file *File, schema *Schema, columns []*Column, rowGroupIndex int, rowGroup *format.RowGroup, columnIndexes []format.ColumnIndex, offsetIndexes []format.OffsetIndex) {
 	g.schema = schema
 	g.rowGroup = rowGroup
 	g.columns = make([]ColumnChunk, len(rowGroup.Columns))
@@ -335,16 +578,19 @@ func (g *fileRowGroup) init(file *File, schema *Schema, columns []*Column, rowGr
 
 	for i := range g.columns {
 		fileColumnChunks[i] = fileColumnChunk{
-			file:     file,
-			column:   columns[i],
-			rowGroup: rowGroup,
-			chunk:    &rowGroup.Columns[i],
+			file:          file,
+			column:        columns[i],
+			rowGroup:      rowGroup,
+			rowGroupIndex: rowGroupIndex,
+			chunk:         &rowGroup.Columns[i],
+			decryption:    file.decryption.column(rowGroupIndex, i, &rowGroup.Columns[i]),
 		}
 
-		if file.hasIndexes() {
-			j := (int(rowGroup.Ordinal) * len(columns)) + i
-			fileColumnChunks[i].columnIndex = &file.columnIndexes[j]
-			fileColumnChunks[i].offsetIndex = &file.offsetIndexes[j]
+		if columnIndexes != nil && offsetIndexes != nil && fileColumnChunks[i].canDecrypt() {
+			if rowGroup.Columns[i].ColumnIndexOffset != 0 {
+				fileColumnChunks[i].columnIndex = &columnIndexes[i]
+			}
+			fileColumnChunks[i].offsetIndex = &offsetIndexes[i]
 		}
 
 		g.columns[i] = &fileColumnChunks[i]
@@ -359,12 +605,60 @@ func (g *fileRowGroup) init(file *File, schema *Schema, columns []*Column, rowGr
 	}
 }
 
+// verify checks that the sizes recorded in the row group metadata match those
+// of its column chunks, and that the column chunks are located within the data
+// section of the file which ends at dataEnd.
+func (g *fileRowGroup) verify(dataEnd int64) error {
+	totalByteSize := int64(0)
+	totalCompressedSize := int64(0)
+
+	for _, c := range g.columns {
+		c := c.(*fileColumnChunk)
+		if !c.canDecrypt() {
+			// The metadata of column chunks encrypted with a key that is
+			// not available cannot be verified, nor the sizes of the row
+			// group which account for them.
+			totalByteSize, totalCompressedSize = -1, -1
+			continue
+		}
+		if err := c.verify(dataEnd); err != nil {
+			return err
+		}
+		if totalByteSize >= 0 {
+			totalByteSize += c.chunk.MetaData.TotalUncompressedSize
+			totalCompressedSize += c.chunk.MetaData.TotalCompressedSize
+		}
+	}
+	if totalByteSize < 0 {
+		return nil
+	}
+
+	// Writers do not agree on whether the total byte size accounts for the
+	// page headers, so it cannot be compared to the sum of the uncompressed
+	// sizes of column chunks; we can only check that it is in a sane range.
+	if g.rowGroup.TotalByteSize < 0 || (g.rowGroup.TotalByteSize == 0 && totalByteSize != 0) {
+		return fmt.Errorf("row group %d: invalid total byte size %d for %d bytes of uncompressed data in column chunks: %w",
+			g.rowGroup.Ordinal, g.rowGroup.TotalByteSize, totalByteSize, ErrInconsistentMetadata)
+	}
+	// The total compressed size is an optional field which is zero when it was
+	// not set by the writer.
+	if g.rowGroup.TotalCompressedSize != 0 && g.rowGroup.TotalCompressedSize != totalCompressedSize {
+		return fmt.Errorf("row group %d: total compressed size is %d but the column chunks have %d bytes of compressed data: %w",
+			g.rowGroup.Ordinal, g.rowGroup.TotalCompressedSize, totalCompressedSize, ErrInconsistentMetadata)
+	}
+	return nil
+}
+
 func (g *fileRowGroup) Schema() *Schema                 { return g.schema }
 func (g *fileRowGroup) NumRows() int64                  { return g.rowGroup.NumRows }
 func (g *fileRowGroup) ColumnChunks() []ColumnChunk     { return g.columns }
 func (g *fileRowGroup) SortingColumns() []SortingColumn { return g.sorting }
 func (g *fileRowGroup) Rows() Rows                      { return &rowGroupRows{rowGroup: g} }
 
+func (g *fileRowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	return &rowGroupRows{rowGroup: g, memory: m}
+}
+
 type fileSortingColumn struct {
 	column     *Column
 	descending bool
@@ -376,13 +670,24 @@ func (s *fileSortingColumn) Descending() bool { return s.descending }
 func (s *fileSortingColumn) NullsFirst() bool { return s.nullsFirst }
 
 type fileColumnChunk struct {
-	file        *File
-	column      *Column
-	bloomFilter *bloomFilter
-	rowGroup    *format.RowGroup
-	columnIndex *format.ColumnIndex
-	offsetIndex *format.OffsetIndex
-	chunk       *format.ColumnChunk
+	file          *File
+	column        *Column
+	bloomFilter   *bloomFilter
+	rowGroup      *format.RowGroup
+	rowGroupIndex int
+	columnIndex   *format.ColumnIndex
+	offsetIndex   *format.OffsetIndex
+	chunk         *format.ColumnChunk
+	decryption    *columnDecryption
+	// Synchronizes the lazy loading of the page index of the column chunk by
+	// ReadColumnIndex and ReadOffsetIndex.
+	pageIndexMutex sync.Mutex
+}
+
+// canDecrypt returns true if the column chunk is not encrypted, or if its key
+// is available.
+func (c *fileColumnChunk) canDecrypt() bool {
+	return c.decryption == nil || c.decryption.err == nil
 }
 
 func (c *fileColumnChunk) Type() Type {
@@ -399,7 +704,15 @@ func (c *fileColumnChunk) Pages() Pages {
 	return r
 }
 
+func (c *fileColumnChunk) pagesWithMemory(m *memoryReservation) Pages {
+	r := new(filePages)
+	r.initWithMemory(c, m)
+	return r
+}
+
 func (c *fileColumnChunk) ColumnIndex() ColumnIndex {
+	c.pageIndexMutex.Lock()
+	defer c.pageIndexMutex.Unlock()
 	if c.columnIndex == nil {
 		return nil
 	}
@@ -407,12 +720,65 @@ func (c *fileColumnChunk) ColumnIndex() ColumnIndex {
 }
 
 func (c *fileColumnChunk) OffsetIndex() OffsetIndex {
+	c.pageIndexMutex.Lock()
+	defer c.pageIndexMutex.Unlock()
 	if c.offsetIndex == nil {
 		return nil
 	}
 	return (*fileOffsetIndex)(c.offsetIndex)
 }
 
+func (c *fileColumnChunk) readColumnIndex() error {
+	c.pageIndexMutex.Lock()
+	defer c.pageIndexMutex.Unlock()
+	if c.columnIndex != nil || c.chunk.ColumnIndexOffset == 0 {
+		return nil
+	}
+	columnIndex := new(format.ColumnIndex)
+	if err := c.readPageIndexSection("column index", c.chunk.ColumnIndexOffset, c.chunk.ColumnIndexLength, columnIndexModule, columnIndex); err != nil {
+		return err
+	}
+	c.columnIndex = columnIndex
+	return nil
+}
+
+func (c *fileColumnChunk) readOffsetIndex() error {
+	c.pageIndexMutex.Lock()
+	defer c.pageIndexMutex.Unlock()
+	if c.offsetIndex != nil || c.chunk.OffsetIndexOffset == 0 {
+		return nil
+	}
+	offsetIndex := new(format.OffsetIndex)
+	if err := c.readPageIndexSection("offset index", c.chunk.OffsetIndexOffset, c.chunk.OffsetIndexLength, offsetIndexModule, offsetIndex); err != nil {
+		return err
+	}
+	c.offsetIndex = offsetIndex
+	return nil
+}
+
+// readPageIndexSection reads and decodes the section of the page index of the
+// column chunk located at the given offset into index. The module type is
+// used to decrypt the section when the column chunk is encrypted.
+func (c *fileColumnChunk) readPageIndexSection(name string, offset int64, length int32, module byte, index interface{}) error {
+	if length <= 0 || offset < 0 || offset+int64(length) > c.file.size {
+		return fmt.Errorf("%s of row group %d column %d at offset %d with length %d is out of bounds of the file", name, c.rowGroupIndex, c.Column(), offset, length)
+	}
+	data := make([]byte, length)
+	if _, err := c.file.reader.ReadAt(data, offset); err != nil {
+		return fmt.Errorf("reading %d bytes %s at offset %d: %w", length, name, offset, err)
+	}
+	if c.decryption != nil {
+		var err error
+		if data, err = c.decryption.decryptInPlace(data, module, -1); err != nil {
+			return fmt.Errorf("decrypting %s of row group %d column %d: %w", name, c.rowGroupIndex, c.Column(), err)
+		}
+	}
+	if err := thrift.Unmarshal(&c.file.protocol, data, index); err != nil {
+		return fmt.Errorf("decoding %s of row group %d column %d: %w", name, c.rowGroupIndex, c.Column(), err)
+	}
+	return nil
+}
+
 func (c *fileColumnChunk) BloomFilter() BloomFilter {
 	if c.bloomFilter == nil {
 		return nil
@@ -424,11 +790,69 @@ func (c *fileColumnChunk) NumValues() int64 {
 	return c.chunk.MetaData.NumValues
 }
 
+// verify checks that the offsets and sizes recorded in the metadata and offset
+// index of the column chunk are consistent, and point to locations within the
+// data section of the file which ends at dataEnd.
+func (c *fileColumnChunk) verify(dataEnd int64) error {
+	metadata := &c.chunk.MetaData
+	chunkStart := metadata.DataPageOffset
+	if metadata.DictionaryPageOffset != 0 {
+		if metadata.DictionaryPageOffset >= metadata.DataPageOffset {
+			return c.inconsistent("dictionary page offset %d is not before the data page offset %d", metadata.DictionaryPageOffset, metadata.DataPageOffset)
+		}
+		chunkStart = metadata.DictionaryPageOffset
+	}
+	if chunkStart < 4 || chunkStart >= dataEnd {
+		return c.inconsistent("column chunk offset %d is out of the bounds of the file data [4:%d]", chunkStart, dataEnd)
+	}
+	if metadata.TotalCompressedSize <= 0 || metadata.TotalCompressedSize > dataEnd-chunkStart {
+		return c.inconsistent("column chunk of %d bytes at offset %d extends past the end of the file data at offset %d", metadata.TotalCompressedSize, chunkStart, dataEnd)
+	}
+	if metadata.TotalUncompressedSize < 0 {
+		return c.inconsistent("negative total uncompressed size %d", metadata.TotalUncompressedSize)
+	}
+	if metadata.NumValues < 0 {
+		return c.inconsistent("negative number of values %d", metadata.NumValues)
+	}
+
+	if c.offsetIndex != nil {
+		chunkEnd := chunkStart + metadata.TotalCompressedSize
+		pages := c.offsetIndex.PageLocations
+
+		for i, page := range pages {
+			switch {
+			case page.CompressedPageSize <= 0:
+				return c.inconsistentPage(i, "invalid page size %d", page.CompressedPageSize)
+			case page.Offset < metadata.DataPageOffset || page.Offset+int64(page.CompressedPageSize) > chunkEnd:
+				return c.inconsistentPage(i, "page of %d bytes at offset %d is out of the bounds of the column chunk [%d:%d]", page.CompressedPageSize, page.Offset, metadata.DataPageOffset, chunkEnd)
+			case i == 0 && page.FirstRowIndex != 0:
+				return c.inconsistentPage(i, "first row index of the first page is %d", page.FirstRowIndex)
+			case i > 0 && page.Offset < pages[i-1].Offset+int64(pages[i-1].CompressedPageSize):
+				return c.inconsistentPage(i, "page at offset %d overlaps with the previous page", page.Offset)
+			case i > 0 && page.FirstRowIndex <= pages[i-1].FirstRowIndex:
+				return c.inconsistentPage(i, "first row index %d is not greater than the first row index of the previous page %d", page.FirstRowIndex, pages[i-1].FirstRowIndex)
+			case page.FirstRowIndex >= c.rowGroup.NumRows:
+				return c.inconsistentPage(i, "first row index %d is out of the bounds of the row group with %d rows", page.FirstRowIndex, c.rowGroup.NumRows)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *fileColumnChunk) inconsistent(msg string, args ...interface{}) error {
+	return fmt.Errorf("column %q in row group %d: %s: %w", columnPath(c.column.Path()), c.rowGroupIndex, fmt.Sprintf(msg, args...), ErrInconsistentMetadata)
+}
+
+func (c *fileColumnChunk) inconsistentPage(page int, msg string, args ...interface{}) error {
+	return fmt.Errorf("page %d of column %q in row group %d: %s: %w", page, columnPath(c.column.Path()), c.rowGroupIndex, fmt.Sprintf(msg, args...), ErrInconsistentMetadata)
+}
+
 type filePages struct {
 	chunk    *fileColumnChunk
 	dictPage *dictPage
 	dataPage *dataPage
 	rbuf     *bufio.Reader
+	reader   contextReaderAt
 	section  io.SectionReader
 
 	protocol thrift.CompactProtocol
@@ -439,68 +863,160 @@ type filePages struct {
 	dictOffset int64
 	index      int
 	skip       int64
+	// Index of the first row of the next data page, or -1 if it is unknown
+	// because a page of unknown size was skipped.
+	rowIndex int64
+	// Number of bytes held by the data and dictionary pages in the memory
+	// limiter of the file, reserved on behalf of memory.
+	memory     *memoryReservation
+	dataMemory int64
+	dictMemory int64
+	// Whether the values of pages must be validated as UTF-8 strings.
+	validateUTF8 bool
+	utf8         utf8Validator
+	// Buffer holding the encrypted page headers of encrypted column chunks.
+	headerModule []byte
 }
 
 func (f *filePages) init(c *fileColumnChunk) {
+	f.initWithMemory(c, nil)
+}
+
+func (f *filePages) initWithMemory(c *fileColumnChunk, m *memoryReservation) {
+	if limiter := c.file.config.MemoryLimiter; limiter != nil {
+		f.memory = m.of(limiter)
+	}
 	f.dataPage = acquireDataPage()
 	f.chunk = c
 	f.baseOffset = c.chunk.MetaData.DataPageOffset
 	f.dataOffset = f.baseOffset
+	f.validateUTF8 = c.file.config.ValidateUTF8 && isStringType(c.column.Type())
 
 	if c.chunk.MetaData.DictionaryPageOffset != 0 {
 		f.baseOffset = c.chunk.MetaData.DictionaryPageOffset
 		f.dictOffset = f.baseOffset
 	}
 
-	f.section = *io.NewSectionReader(c.file, f.baseOffset, c.chunk.MetaData.TotalCompressedSize)
+	f.reader = contextReaderAt{ctx: context.Background(), reader: c.file}
+	f.section = *io.NewSectionReader(&f.reader, f.baseOffset, c.chunk.MetaData.TotalCompressedSize)
 	f.rbuf = acquireReadBuffer(&f.section)
 	f.decoder.Reset(f.protocol.NewReader(f.rbuf))
 }
 
 func (f *filePages) ReadPage() (Page, error) {
+	return f.ReadPageContext(context.Background())
+}
+
+// ReadPageContext is like ReadPage but uses ctx to cancel the reads from the
+// file. When the read is interrupted, the position of the pages is undefined;
+// SeekToRow must be called to resume reading.
+func (f *filePages) ReadPageContext(ctx context.Context) (Page, error) {
 	if f.chunk == nil {
 		return nil, io.EOF
 	}
 
+	f.reader.ctx = ctx
+	defer func() { f.reader.ctx = context.Background() }()
+
 	for {
-		header := new(format.PageHeader)
-		if err := f.decoder.Decode(header); err != nil {
+		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
-		if err := f.readPage(header, f.dataPage, f.rbuf); err != nil {
+		header := new(format.PageHeader)
+		pageOffset := f.offset()
+		if err := f.readPageHeader(f.rbuf, &f.decoder, header, pageOffset); err != nil {
+			if err != io.EOF && f.skipPageHeader(err) {
+				continue
+			}
 			return nil, err
 		}
+		if f.chunk.file.config.VerifyColumnChunks {
+			if err := f.verify(header, pageOffset); err != nil {
+				if f.skipPageHeader(err) {
+					continue
+				}
+				return nil, err
+			}
+		}
+
+		// Data pages which end before the row that f was seeked to are
+		// discarded without being decompressed when their number of rows is
+		// known from the page header or the offset index.
+		if f.skip > 0 && header.Type != format.DictionaryPage {
+			if numRows := f.pageNumRows(header); numRows >= 0 && numRows <= f.skip {
+				if _, err := f.rbuf.Discard(int(header.CompressedPageSize)); err != nil {
+					return nil, err
+				}
+				f.advance(numRows)
+				continue
+			}
+		}
 
 		var page Page
 		var err error
 
-		switch header.Type {
-		case format.DataPageV2:
-			page, err = f.readDataPageV2(header)
-		case format.DataPage:
-			page, err = f.readDataPageV1(header)
-		case format.DictionaryPage:
-			// Sometimes parquet files do not have the dictionary page offset
-			// recorded in the column metadata. We account for this by lazily
-			// reading dictionary pages when we encounter them.
-			err = f.readDictionaryPage(header, f.dataPage)
-		default:
-			err = fmt.Errorf("cannot read values of type %s from page", header.Type)
+		if f.canStreamPage(header) {
+			// Oversized pages are not read in memory, their values are
+			// decompressed and decoded incrementally when they are read.
+			page, err = f.readStreamingPage(header)
+		} else {
+			f.reserve(header)
+			if err := f.readPage(header, f.dataPage, f.rbuf); err != nil {
+				if err != io.EOF && f.skipPage(header, err) {
+					continue
+				}
+				return nil, err
+			}
+
+			switch header.Type {
+			case format.DataPageV2:
+				page, err = f.readDataPageV2(header)
+			case format.DataPage:
+				page, err = f.readDataPageV1(header)
+			case format.DictionaryPage:
+				// Sometimes parquet files do not have the dictionary page offset
+				// recorded in the column metadata. We account for this by lazily
+				// reading dictionary pages when we encounter them.
+				err = f.readDictionaryPage(header, f.dataPage)
+			default:
+				err = fmt.Errorf("cannot read values of type %s from page", header.Type)
+			}
+		}
+
+		if err == nil && page != nil && f.validateUTF8 {
+			if err := f.utf8.validate(page); err != nil {
+				if f.skipPage(header, err) {
+					continue
+				}
+				return nil, f.pageError(f.pageNumRows(header), err)
+			}
 		}
 
 		if err != nil {
-			return nil, fmt.Errorf("decoding page %d of column %q: %w", f.index, f.columnPath(), err)
+			err = fmt.Errorf("decoding page %d of column %q: %w", f.index, f.columnPath(), err)
+			if f.skipPage(header, err) {
+				continue
+			}
+			return nil, err
 		}
 
 		if page != nil {
+			if metrics := f.chunk.file.config.Metrics; metrics != nil {
+				metrics.PageDecoded(f.chunk.Column(), f.chunk.chunk.MetaData.Codec, page.NumValues())
+			}
+
 			f.index++
+			numRows := page.NumRows()
+			if f.rowIndex >= 0 {
+				f.rowIndex += numRows
+			}
+
 			if f.skip == 0 {
 				return page, nil
 			}
 
 			// TODO: what about pages that don't embed the number of rows?
 			// (data page v1 with no offset index in the column chunk).
-			numRows := page.NumRows()
 			if numRows > f.skip {
 				seek := f.skip
 				f.skip = 0
@@ -515,17 +1031,336 @@ func (f *filePages) ReadPage() (Page, error) {
 	}
 }
 
+// skipPage is called when an error occurred reading the page of the given
+// header. The method reports the error to the page error handler of the file,
+// returning true if the page was skipped and reading may continue with the
+// next page, or false if the error must be returned to the caller.
+func (f *filePages) skipPage(header *format.PageHeader, err error) bool {
+	onPageError := f.chunk.file.config.OnPageError
+	if onPageError == nil || f.reader.ctx.Err() != nil {
+		return false
+	}
+
+	numRows := f.pageNumRows(header)
+	onPageError(f.pageError(numRows, err))
+
+	if header.Type != format.DictionaryPage {
+		f.advance(numRows)
+	}
+	return true
+}
+
+// skipPageHeader is like skipPage but is called when the page header could not
+// be decoded. Since the size of the page is unknown, the page can only be
+// skipped if the column chunk has an offset index recording the location of
+// the next page.
+func (f *filePages) skipPageHeader(err error) bool {
+	onPageError := f.chunk.file.config.OnPageError
+	if onPageError == nil || f.chunk.offsetIndex == nil || f.reader.ctx.Err() != nil {
+		return false
+	}
+
+	pages := f.chunk.offsetIndex.PageLocations
+	if f.index < 0 || f.index >= len(pages) {
+		return false
+	}
+
+	onPageError(f.pageError(f.pageNumRows(nil), err))
+	return f.seekToPage(f.index+1) == nil
+}
+
+// advance moves f past the data page that it was positioned at, which contained
+// numRows rows, or a negative value if the number of rows is unknown.
+func (f *filePages) advance(numRows int64) {
+	f.index++
+	switch {
+	case numRows < 0:
+		f.rowIndex = -1
+	case f.rowIndex >= 0:
+		f.rowIndex += numRows
+	}
+	if f.skip > 0 && numRows > 0 {
+		f.skip -= min64(f.skip, numRows)
+	}
+}
+
+// seekToPage positions f at the beginning of the data page at the given index
+// in the offset index of the column chunk, or at the end of the column chunk
+// if the index is equal to the number of pages. The number of rows to skip is
+// adjusted so the next page read still starts at the row that f was seeked to.
+func (f *filePages) seekToPage(index int) error {
+	pages := f.chunk.offsetIndex.PageLocations
+	target := f.rowIndex + f.skip
+
+	if index < len(pages) {
+		if _, err := f.section.Seek(pages[index].Offset-f.baseOffset, io.SeekStart); err != nil {
+			return err
+		}
+		f.rowIndex = pages[index].FirstRowIndex
+	} else {
+		f.section.Seek(0, io.SeekEnd)
+		f.rowIndex = f.chunk.rowGroup.NumRows
+	}
+
+	f.index = index
+	f.skip = 0
+	if target > f.rowIndex {
+		f.skip = target - f.rowIndex
+	}
+	f.rbuf.Reset(&f.section)
+	return nil
+}
+
+// SkipPage skips the next data page. When the column chunk has an offset index,
+// the page is skipped without being read, otherwise only its header is read.
+func (f *filePages) SkipPage() error {
+	if f.chunk == nil {
+		return io.EOF
+	}
+
+	if f.chunk.offsetIndex != nil {
+		if f.index >= len(f.chunk.offsetIndex.PageLocations) {
+			return io.EOF
+		}
+		return f.seekToPage(f.index + 1)
+	}
+
+	for {
+		header := new(format.PageHeader)
+		if err := f.readPageHeader(f.rbuf, &f.decoder, header, f.offset()); err != nil {
+			return err
+		}
+		// Dictionary pages are loaded instead of being skipped, since the data
+		// pages read after need them.
+		if header.Type == format.DictionaryPage && f.dataPage.dictionary == nil {
+			f.reserve(header)
+			if err := f.readPage(header, f.dataPage, f.rbuf); err != nil {
+				return err
+			}
+			if err := f.readDictionaryPage(header, f.dataPage); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := f.rbuf.Discard(int(header.CompressedPageSize)); err != nil {
+			return err
+		}
+		if header.Type != format.DictionaryPage {
+			f.advance(f.pageNumRows(header))
+			return nil
+		}
+	}
+}
+
+// NextMatching positions f at the next data page for which the predicate
+// returns true. The statistics passed to the predicate are read from the page
+// index of the column chunk, the pages are not read.
+func (f *filePages) NextMatching(predicate func(PageStats) bool) (PageStats, error) {
+	if f.chunk == nil {
+		return PageStats{}, io.EOF
+	}
+	if f.chunk.columnIndex == nil || f.chunk.offsetIndex == nil {
+		return PageStats{}, ErrMissingPageIndex
+	}
+
+	pages := f.chunk.offsetIndex.PageLocations
+	if numPages := len(f.chunk.columnIndex.NullPages); numPages != len(pages) {
+		return PageStats{}, f.chunk.inconsistent("column index has %d pages but offset index has %d pages", numPages, len(pages))
+	}
+
+	for i := f.index; i < len(pages); i++ {
+		stats := f.pageStats(i)
+		if !predicate(stats) {
+			continue
+		}
+		if i != f.index {
+			if err := f.seekToPage(i); err != nil {
+				return PageStats{}, err
+			}
+		}
+		return stats, nil
+	}
+
+	if err := f.seekToPage(len(pages)); err != nil {
+		return PageStats{}, err
+	}
+	return PageStats{}, io.EOF
+}
+
+func (f *filePages) pageStats(index int) PageStats {
+	columnIndex := fileColumnIndex{f.chunk}
+	pages := f.chunk.offsetIndex.PageLocations
+
+	lastRowIndex := f.chunk.rowGroup.NumRows
+	if index+1 < len(pages) {
+		lastRowIndex = pages[index+1].FirstRowIndex
+	}
+
+	return PageStats{
+		Index:         index,
+		FirstRowIndex: pages[index].FirstRowIndex,
+		NumRows:       lastRowIndex - pages[index].FirstRowIndex,
+		NullCount:     columnIndex.NullCount(index),
+		NullPage:      columnIndex.NullPage(index),
+		MinValue:      columnIndex.MinValue(index),
+		MaxValue:      columnIndex.MaxValue(index),
+	}
+}
+
+func (f *filePages) pageNumRows(header *format.PageHeader) int64 {
+	if header != nil {
+		switch header.Type {
+		case format.DictionaryPage:
+			return 0
+		case format.DataPageV2:
+			if header.DataPageHeaderV2 != nil {
+				return int64(header.DataPageHeaderV2.NumRows)
+			}
+		}
+	}
+	if f.chunk.offsetIndex != nil {
+		pages := f.chunk.offsetIndex.PageLocations
+		if f.index >= 0 && f.index < len(pages) {
+			lastRowIndex := f.chunk.rowGroup.NumRows
+			if f.index+1 < len(pages) {
+				lastRowIndex = pages[f.index+1].FirstRowIndex
+			}
+			return lastRowIndex - pages[f.index].FirstRowIndex
+		}
+	}
+	return -1
+}
+
+func (f *filePages) pageError(numRows int64, err error) *PageError {
+	firstRowIndex := f.rowIndex
+	if f.chunk.offsetIndex != nil {
+		if pages := f.chunk.offsetIndex.PageLocations; f.index >= 0 && f.index < len(pages) {
+			firstRowIndex = pages[f.index].FirstRowIndex
+		}
+	}
+	if firstRowIndex < 0 {
+		numRows = -1
+	}
+	return &PageError{
+		Path:          f.chunk.column.Path(),
+		Column:        f.chunk.Column(),
+		RowGroup:      f.chunk.rowGroupIndex,
+		Page:          f.index,
+		FirstRowIndex: firstRowIndex,
+		NumRows:       numRows,
+		Err:           err,
+	}
+}
+
+// offset returns the position in the file of the next byte read by f.
+func (f *filePages) offset() int64 {
+	pos, _ := f.section.Seek(0, io.SeekCurrent)
+	return f.baseOffset + pos - int64(f.rbuf.Buffered())
+}
+
+// verify checks that the page of the given header, which starts at pageOffset
+// in the file, is consistent with the column chunk metadata.
+func (f *filePages) verify(header *format.PageHeader, pageOffset int64) error {
+	dataOffset := f.offset()
+	chunkEnd := f.baseOffset + f.chunk.chunk.MetaData.TotalCompressedSize
+
+	switch {
+	case header.CompressedPageSize < 0:
+		return f.inconsistent("negative compressed page size %d", header.CompressedPageSize)
+	case header.UncompressedPageSize < 0:
+		return f.inconsistent("negative uncompressed page size %d", header.UncompressedPageSize)
+	case dataOffset+int64(header.CompressedPageSize) > chunkEnd:
+		return f.inconsistent("page of %d bytes at offset %d extends past the end of the column chunk at offset %d", header.CompressedPageSize, dataOffset, chunkEnd)
+	}
+
+	if header.Type == format.DictionaryPage {
+		if pageOffset != f.baseOffset {
+			return f.inconsistent("dictionary page found at offset %d but the column chunk starts at offset %d", pageOffset, f.baseOffset)
+		}
+		return nil
+	}
+
+	if f.chunk.offsetIndex != nil {
+		pages := f.chunk.offsetIndex.PageLocations
+		if f.index >= len(pages) {
+			return f.inconsistent("the offset index only has %d pages", len(pages))
+		}
+		page := &pages[f.index]
+		if page.Offset != pageOffset {
+			return f.inconsistent("page found at offset %d but the offset index records offset %d", pageOffset, page.Offset)
+		}
+		if pageSize := dataOffset - pageOffset + int64(header.CompressedPageSize); pageSize != int64(page.CompressedPageSize) {
+			return f.inconsistent("page has %d bytes but the offset index records %d bytes", pageSize, page.CompressedPageSize)
+		}
+	}
+	return nil
+}
+
+func (f *filePages) inconsistent(msg string, args ...interface{}) error {
+	return f.chunk.inconsistentPage(f.index, msg, args...)
+}
+
+// reserve accounts for the memory needed by the page of the given header in the
+// memory limiter of the file, blocking until enough memory is available if the
+// reservation of the pages does not hold memory already. The memory held by the
+// previous data page is released first since its buffers get reused to read the
+// new page.
+func (f *filePages) reserve(header *format.PageHeader) {
+	if f.memory == nil {
+		return
+	}
+	size := int64(header.UncompressedPageSize)
+	if header.Type == format.DictionaryPage {
+		f.memory.acquire(size)
+		f.dictMemory += size
+	} else {
+		f.memory.release(f.dataMemory)
+		f.dataMemory = 0
+		f.memory.acquire(size)
+		f.dataMemory = size
+	}
+}
+
+// readPageHeader decodes the header of the page starting at pageOffset in the
+// file, decrypting it first when the column chunk is encrypted.
+func (f *filePages) readPageHeader(r *bufio.Reader, decoder *thrift.Decoder, header *format.PageHeader, pageOffset int64) error {
+	c := f.chunk.decryption
+	if c == nil {
+		return decoder.Decode(header)
+	}
+	if c.err != nil {
+		return c.err
+	}
+	module, err := readModule(r, f.headerModule, f.chunk.chunk.MetaData.TotalCompressedSize)
+	if err != nil {
+		return err
+	}
+	f.headerModule = module
+	// The type of encrypted page headers is part of their AAD, the page at
+	// the dictionary page offset is expected to be the dictionary page.
+	moduleType, ordinal := dataPageHeaderModule, f.index
+	if f.dictOffset != 0 && pageOffset == f.dictOffset {
+		moduleType, ordinal = dictionaryPageHeaderModule, -1
+	}
+	plaintext, err := c.decryptInPlace(module, moduleType, ordinal)
+	if err != nil {
+		return fmt.Errorf("decrypting page header of column %q: %w", f.columnPath(), err)
+	}
+	return thrift.Unmarshal(&f.protocol, plaintext, header)
+}
+
 func (f *filePages) readDictionary() error {
-	chunk := io.NewSectionReader(f.chunk.file, f.baseOffset, f.chunk.chunk.MetaData.TotalCompressedSize)
+	chunk := io.NewSectionReader(&f.reader, f.baseOffset, f.chunk.chunk.MetaData.TotalCompressedSize)
 	rbuf := acquireReadBuffer(chunk)
 	defer releaseReadBuffer(rbuf)
 
 	decoder := thrift.NewDecoder(f.protocol.NewReader(rbuf))
 	header := new(format.PageHeader)
 
-	if err := decoder.Decode(header); err != nil {
+	if err := f.readPageHeader(rbuf, decoder, header, f.baseOffset); err != nil {
 		return err
 	}
+	f.reserve(header)
 
 	page := acquireDataPage()
 	defer releaseDataPage(page)
@@ -583,14 +1418,15 @@ func (f *filePages) readDataPageV2(header *format.PageHeader) (Page, error) {
 func (f *filePages) readPage(header *format.PageHeader, page *dataPage, reader *bufio.Reader) error {
 	compressedPageSize, uncompressedPageSize := int(header.CompressedPageSize), int(header.UncompressedPageSize)
 
-	if cap(page.data) < compressedPageSize {
-		page.data = make([]byte, compressedPageSize)
-	} else {
-		page.data = page.data[:compressedPageSize]
-	}
-	if cap(page.values) < uncompressedPageSize {
-		page.values = make([]byte, 0, uncompressedPageSize)
+	// The buffers are swapped when the page is decompressed, then the values
+	// are decoded in the buffer which held the compressed data, so both must
+	// be able to hold the largest of the compressed and uncompressed pages.
+	bufferSize := compressedPageSize
+	if bufferSize < uncompressedPageSize {
+		bufferSize = uncompressedPageSize
 	}
+	page.data = growPageBuffer(page.data, bufferSize)[:compressedPageSize]
+	page.values = growPageBuffer(page.values, bufferSize)
 
 	if _, err := io.ReadFull(reader, page.data); err != nil {
 		return err
@@ -618,6 +1454,18 @@ func (f *filePages) readPage(header *format.PageHeader, page *dataPage, reader *
 		}
 	}
 
+	if c := f.chunk.decryption; c != nil {
+		moduleType, ordinal := dataPageModule, f.index
+		if header.Type == format.DictionaryPage {
+			moduleType, ordinal = dictionaryPageModule, -1
+		}
+		data, err := c.decryptInPlace(page.data, moduleType, ordinal)
+		if err != nil {
+			return fmt.Errorf("decrypting page of column %q: %w", f.columnPath(), err)
+		}
+		page.data = data
+	}
+
 	return nil
 }
 
@@ -628,10 +1476,8 @@ func (f *filePages) SeekToRow(rowIndex int64) (err error) {
 	if f.chunk.offsetIndex == nil {
 		_, err = f.section.Seek(f.dataOffset-f.baseOffset, io.SeekStart)
 		f.skip = rowIndex
+		f.rowIndex = 0
 		f.index = 0
-		if f.dictOffset > 0 {
-			f.index = 1
-		}
 	} else {
 		pages := f.chunk.offsetIndex.PageLocations
 		index := sort.Search(len(pages), func(i int) bool {
@@ -642,6 +1488,7 @@ func (f *filePages) SeekToRow(rowIndex int64) (err error) {
 		}
 		_, err = f.section.Seek(pages[index].Offset-f.baseOffset, io.SeekStart)
 		f.skip = rowIndex - pages[index].FirstRowIndex
+		f.rowIndex = pages[index].FirstRowIndex
 		f.index = index
 	}
 	f.rbuf.Reset(&f.section)
@@ -649,12 +1496,16 @@ func (f *filePages) SeekToRow(rowIndex int64) (err error) {
 }
 
 func (f *filePages) Close() error {
+	if f.memory != nil {
+		f.memory.release(f.dataMemory + f.dictMemory)
+	}
 	releaseDictPage(f.dictPage)
 	releaseDataPage(f.dataPage)
 	releaseReadBuffer(f.rbuf)
 	f.chunk = nil
 	f.dictPage = nil
 	f.dataPage = nil
+	f.reader = contextReaderAt{}
 	f.section = io.SectionReader{}
 	f.rbuf = nil
 	f.baseOffset = 0
@@ -662,6 +1513,10 @@ func (f *filePages) Close() error {
 	f.dictOffset = 0
 	f.index = 0
 	f.skip = 0
+	f.rowIndex = 0
+	f.memory = nil
+	f.dataMemory = 0
+	f.dictMemory = 0
 	return nil
 }
 
@@ -700,6 +1555,7 @@ func acquireDataPage() *dataPage {
 
 func releaseDataPage(p *dataPage) {
 	if p != nil {
+		p.releaseBuffers()
 		p.reset()
 		dataPagePool.Put(p)
 	}
@@ -1,13 +1,20 @@
 package parquet_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/segmentio/encoding/thrift"
 	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/format"
 )
 
 var testdataFiles []string
@@ -15,7 +22,11 @@ var testdataFiles []string
 func init() {
 	entries, _ := os.ReadDir("testdata")
 	for _, e := range entries {
-		testdataFiles = append(testdataFiles, filepath.Join("testdata", e.Name()))
+		// Encrypted files cannot be opened without their keys, they are
+		// tested separately.
+		if filepath.Ext(e.Name()) == ".parquet" {
+			testdataFiles = append(testdataFiles, filepath.Join("testdata", e.Name()))
+		}
 	}
 }
 
@@ -143,3 +154,1161 @@ func TestFileKeyValueMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestOpenFileListAndMapTypes(t *testing.T) {
+	type Row struct {
+		List []int32         `parquet:"list,list"`
+		Map  map[string]bool `parquet:"map"`
+	}
+
+	f, err := createParquetFile(makeRows([]Row{{List: []int32{1}, Map: map[string]bool{"A": true}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `message Row {
+	required group list (LIST) {
+		repeated group list {
+			required int32 element (INT(32,true));
+		}
+	}
+	required group map (MAP) {
+		repeated group key_value {
+			required binary key (STRING);
+			required boolean value;
+		}
+	}
+}`
+
+	if got := f.Schema().String(); got != want {
+		t.Errorf("schema mismatch:\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestOpenFileOnPageError(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offsetIndex := f.RowGroups()[0].ColumnChunks()[0].OffsetIndex()
+	if offsetIndex.NumPages() < 3 {
+		t.Fatalf("not enough pages were written: %d", offsetIndex.NumPages())
+	}
+
+	// Flip the last byte of the second page to trigger a checksum mismatch.
+	corruptedPage := 1
+	corruptedOffset := offsetIndex.Offset(corruptedPage) + offsetIndex.CompressedPageSize(corruptedPage) - 1
+	data[corruptedOffset] ^= 0xFF
+
+	f, err = parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := f.RowGroups()[0].ColumnChunks()[0].Pages()
+	if err := forEachPage(pages, func(parquet.Page) error { return nil }); !errors.Is(err, parquet.ErrCorrupted) {
+		t.Errorf("expected corrupted page error but got %v", err)
+	}
+	pages.Close()
+
+	var pageErrors []*parquet.PageError
+	f, err = parquet.OpenFile(bytes.NewReader(data), int64(len(data)),
+		parquet.OnPageError(func(err *parquet.PageError) { pageErrors = append(pageErrors, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	numRows := int64(0)
+	pages = f.RowGroups()[0].ColumnChunks()[0].Pages()
+	defer pages.Close()
+	if err := forEachPage(pages, func(p parquet.Page) error { numRows += p.NumRows(); return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pageErrors) != 1 {
+		t.Fatalf("wrong number of page errors: want=1 got=%d", len(pageErrors))
+	}
+
+	pageError := pageErrors[0]
+	firstRowIndex := offsetIndex.FirstRowIndex(corruptedPage)
+	lastRowIndex := offsetIndex.FirstRowIndex(corruptedPage + 1)
+
+	if pageError.Page != corruptedPage {
+		t.Errorf("wrong page index: want=%d got=%d", corruptedPage, pageError.Page)
+	}
+	if pageError.FirstRowIndex != firstRowIndex || pageError.NumRows != lastRowIndex-firstRowIndex {
+		t.Errorf("wrong row range: want=[%d:%d] got=[%d:+%d]", firstRowIndex, lastRowIndex, pageError.FirstRowIndex, pageError.NumRows)
+	}
+	if !errors.Is(pageError, parquet.ErrCorrupted) {
+		t.Errorf("page error does not wrap the corruption error: %v", pageError)
+	}
+	if want := int64(len(rows)) - pageError.NumRows; numRows != want {
+		t.Errorf("wrong number of rows read: want=%d got=%d", want, numRows)
+	}
+}
+
+func TestFilePagesSkipPageDictionary(t *testing.T) {
+	type Row struct {
+		Value string `parquet:"value,dict"`
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = fmt.Sprintf("value-%03d", i%100)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(256)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a page index, the pages are skipped by reading their headers,
+	// the dictionary page must still be loaded for the data pages read after.
+	f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), parquet.SkipPageIndex(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := f.RowGroups()[0].ColumnChunks()[0]
+	pages := chunk.Pages()
+	defer pages.Close()
+
+	numRows := int64(0)
+	for i := 0; i < 2; i++ {
+		if err := pages.(parquet.PageSkipper).SkipPage(); err != nil {
+			t.Fatal(err)
+		}
+		page, err := pages.ReadPage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Dictionary() == nil {
+			t.Fatal("page read after skipping has no dictionary")
+		}
+		values := make([]parquet.Value, page.NumValues())
+		if _, err := page.Values().ReadValues(values); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if numRows == 0 {
+			numRows = page.NumRows()
+		}
+		// Pages have the same number of rows, the first row of the page read
+		// after skipping i+1 pages is at index (2i+1)*numRows.
+		rowIndex := (2*int64(i) + 1) * numRows
+		if want := rows[rowIndex].Value; values[0].String() != want {
+			t.Errorf("wrong first value of page %d: want=%q got=%q", 2*i+1, want, values[0])
+		}
+	}
+}
+
+func TestFilePagesNextMatching(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := f.RowGroups()[0].ColumnChunks()[0]
+	offsetIndex := chunk.OffsetIndex()
+	if offsetIndex.NumPages() < 3 {
+		t.Fatalf("not enough pages were written: %d", offsetIndex.NumPages())
+	}
+
+	t.Run("SkipPage", func(t *testing.T) {
+		pages := chunk.Pages()
+		defer pages.Close()
+
+		if err := pages.(parquet.PageSkipper).SkipPage(); err != nil {
+			t.Fatal(err)
+		}
+		page, err := pages.ReadPage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		min, _, _ := page.Bounds()
+		if want := offsetIndex.FirstRowIndex(1); min.Int64() != want {
+			t.Errorf("wrong first value of the page read after skipping: want=%d got=%d", want, min.Int64())
+		}
+	})
+
+	t.Run("NextMatching", func(t *testing.T) {
+		pages := chunk.Pages()
+		defer pages.Close()
+
+		const lower, upper = 500, 520
+		predicate := func(stats parquet.PageStats) bool {
+			return stats.MaxValue.Int64() >= lower && stats.MinValue.Int64() <= upper
+		}
+
+		numPages := 0
+		for {
+			stats, err := pages.(parquet.PageSkipper).NextMatching(predicate)
+			if err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				break
+			}
+			page, err := pages.ReadPage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			min, max, _ := page.Bounds()
+			if min.Int64() != stats.FirstRowIndex || page.NumRows() != stats.NumRows {
+				t.Errorf("page does not match its statistics: want=[%d:+%d] got=[%d:+%d]", stats.FirstRowIndex, stats.NumRows, min.Int64(), page.NumRows())
+			}
+			if max.Int64() < lower || min.Int64() > upper {
+				t.Errorf("page values out of range: [%d:%d]", min.Int64(), max.Int64())
+			}
+			numPages++
+		}
+
+		if numPages == 0 || numPages == offsetIndex.NumPages() {
+			t.Errorf("wrong number of pages matching the predicate: %d/%d", numPages, offsetIndex.NumPages())
+		}
+	})
+}
+
+func TestOpenFileLazyRowGroups(t *testing.T) {
+	for _, path := range testdataFiles {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eager, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lazy, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.LazyRowGroups(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lazy.NumRows() != eager.NumRows() {
+				t.Errorf("wrong number of rows: want=%d got=%d", eager.NumRows(), lazy.NumRows())
+			}
+			if lazy.NumRowGroups() != eager.NumRowGroups() {
+				t.Fatalf("wrong number of row groups: want=%d got=%d", eager.NumRowGroups(), lazy.NumRowGroups())
+			}
+			if lazy.Schema().String() != eager.Schema().String() {
+				t.Fatalf("schemas mismatch:\nwant = %s\ngot  = %s", eager.Schema(), lazy.Schema())
+			}
+
+			// Access the row groups in reverse order to verify that they can
+			// be decoded independently.
+			for i := lazy.NumRowGroups() - 1; i >= 0; i-- {
+				want, err := eager.RowGroup(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := lazy.RowGroup(i)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.NumRows() != want.NumRows() {
+					t.Errorf("row group %d: wrong number of rows: want=%d got=%d", i, want.NumRows(), got.NumRows())
+				}
+
+				wantChunks, gotChunks := want.ColumnChunks(), got.ColumnChunks()
+				for j := range wantChunks {
+					if gotChunks[j].NumValues() != wantChunks[j].NumValues() {
+						t.Errorf("row group %d: column %d: wrong number of values: want=%d got=%d", i, j, wantChunks[j].NumValues(), gotChunks[j].NumValues())
+					}
+					if (gotChunks[j].OffsetIndex() == nil) != (wantChunks[j].OffsetIndex() == nil) {
+						t.Errorf("row group %d: column %d: offset index mismatch", i, j)
+					} else if wantChunks[j].OffsetIndex() != nil && gotChunks[j].OffsetIndex().NumPages() != wantChunks[j].OffsetIndex().NumPages() {
+						t.Errorf("row group %d: column %d: wrong number of pages in offset index: want=%d got=%d", i, j, wantChunks[j].OffsetIndex().NumPages(), gotChunks[j].OffsetIndex().NumPages())
+					}
+				}
+			}
+
+			if _, err := lazy.RowGroup(lazy.NumRowGroups()); err == nil {
+				t.Error("expected an error accessing a row group out of range")
+			}
+
+			wantRows := make([]parquet.Row, 100)
+			gotRows := make([]parquet.Row, 100)
+			n, err := parquet.NewReader(eager).ReadRows(wantRows)
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			m, err := parquet.NewReader(lazy).ReadRows(gotRows)
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if m != n {
+				t.Fatalf("wrong number of rows read: want=%d got=%d", n, m)
+			}
+			for i := range wantRows[:n] {
+				if !gotRows[i].Equal(wantRows[i]) {
+					t.Errorf("row %d mismatch:\nwant = %v\ngot  = %v", i, wantRows[i], gotRows[i])
+				}
+			}
+		})
+	}
+}
+
+func writeRowGroups(t *testing.T, numRowGroups, rowsPerRowGroup int) []byte {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(Row{}))
+	for i := 0; i < numRowGroups; i++ {
+		for j := 0; j < rowsPerRowGroup; j++ {
+			id := int64(i*rowsPerRowGroup + j)
+			if err := w.Write(Row{ID: id, Name: fmt.Sprintf("row-%d", id)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestOpenFileLazyRowGroupsMultipleRowGroups(t *testing.T) {
+	const numRowGroups, rowsPerRowGroup = 4, 10
+	data := writeRowGroups(t, numRowGroups, rowsPerRowGroup)
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.LazyRowGroups(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rowGroups := f.RowGroups()
+	if len(rowGroups) != numRowGroups {
+		t.Fatalf("wrong number of row groups: want=%d got=%d", numRowGroups, len(rowGroups))
+	}
+	for i, rowGroup := range rowGroups {
+		if n := rowGroup.NumRows(); n != rowsPerRowGroup {
+			t.Errorf("row group %d: wrong number of rows: want=%d got=%d", i, rowsPerRowGroup, n)
+		}
+		rows := make([]parquet.Row, rowsPerRowGroup+1)
+		n, err := rowGroup.Rows().ReadRows(rows)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if n != rowsPerRowGroup {
+			t.Fatalf("row group %d: wrong number of rows read: want=%d got=%d", i, rowsPerRowGroup, n)
+		}
+		if id := rows[0][0].Int64(); id != int64(i*rowsPerRowGroup) {
+			t.Errorf("row group %d: wrong first row: want=%d got=%d", i, i*rowsPerRowGroup, id)
+		}
+	}
+
+	// The pages of the columns must span all the row groups of the file.
+	numValues := int64(0)
+	pages := f.Root().Column("id").Pages()
+	defer pages.Close()
+	for {
+		p, err := pages.ReadPage()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+		numValues += p.NumValues()
+	}
+	if numValues != numRowGroups*rowsPerRowGroup {
+		t.Errorf("wrong number of values in the column pages: want=%d got=%d", numRowGroups*rowsPerRowGroup, numValues)
+	}
+
+	columnIndexes, offsetIndexes, err := f.ReadPageIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := 2 * numRowGroups; len(columnIndexes) != n || len(offsetIndexes) != n {
+		t.Errorf("wrong number of page indexes: want=%d got=%d/%d", n, len(columnIndexes), len(offsetIndexes))
+	}
+}
+
+// failingReaderAt fails the reads at offsets past the limit once it is set.
+type failingReaderAt struct {
+	reader io.ReaderAt
+	limit  int64
+	fail   bool
+	min    int64
+}
+
+func (r *failingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	if r.fail && off >= r.limit {
+		return 0, errors.New("read failed")
+	}
+	if off < r.min {
+		r.min = off
+	}
+	return r.reader.ReadAt(b, off)
+}
+
+func TestOpenFileLazyRowGroupsInvalidRowGroup(t *testing.T) {
+	const numRowGroups, rowsPerRowGroup = 3, 10
+	data := writeRowGroups(t, numRowGroups, rowsPerRowGroup)
+
+	r := &failingReaderAt{reader: bytes.NewReader(data)}
+	f, err := parquet.OpenFile(r, int64(len(data)), parquet.LazyRowGroups(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decoding the first row group reads its page index, which is located
+	// after the pages of all row groups; failing the reads past this offset
+	// makes decoding the following row groups fail.
+	r.min = int64(len(data))
+	if _, err := f.RowGroup(0); err != nil {
+		t.Fatal(err)
+	}
+	r.limit, r.fail = r.min, true
+
+	if _, err := f.RowGroup(1); err == nil {
+		t.Fatal("expected an error decoding the second row group")
+	}
+
+	rowGroups := f.RowGroups()
+	if len(rowGroups) != numRowGroups {
+		t.Fatalf("wrong number of row groups: want=%d got=%d", numRowGroups, len(rowGroups))
+	}
+	if n := rowGroups[0].NumRows(); n != rowsPerRowGroup {
+		t.Errorf("wrong number of rows in the first row group: want=%d got=%d", rowsPerRowGroup, n)
+	}
+	for _, rowGroup := range rowGroups[1:] {
+		if n := rowGroup.NumRows(); n != 0 {
+			t.Errorf("wrong number of rows in invalid row group: want=0 got=%d", n)
+		}
+		if _, err := rowGroup.Rows().ReadRows(make([]parquet.Row, 1)); err == nil || err == io.EOF {
+			t.Errorf("expected an error reading rows of invalid row group, got %v", err)
+		}
+		if _, err := rowGroup.ColumnChunks()[0].Pages().ReadPage(); err == nil || err == io.EOF {
+			t.Errorf("expected an error reading pages of invalid row group, got %v", err)
+		}
+	}
+
+	// The pages of the first row group are returned before the error.
+	pages := f.Root().Column("id").Pages()
+	defer pages.Close()
+	numValues := int64(0)
+	for {
+		p, err := pages.ReadPage()
+		if err != nil {
+			if err == io.EOF {
+				t.Fatal("expected an error reading pages of invalid row group")
+			}
+			break
+		}
+		numValues += p.NumValues()
+	}
+	if numValues != rowsPerRowGroup {
+		t.Errorf("wrong number of values read before the error: want=%d got=%d", rowsPerRowGroup, numValues)
+	}
+}
+
+func TestReadPageIndexOfColumnChunk(t *testing.T) {
+	for _, path := range testdataFiles {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eager, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, options := range [][]parquet.FileOption{
+				{parquet.SkipPageIndex(true)},
+				{parquet.SkipPageIndex(true), parquet.LazyRowGroups(true)},
+			} {
+				r := &countingReaderAt{reader: bytes.NewReader(data)}
+				f, err := parquet.OpenFile(r, int64(len(data)), options...)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Access the column chunks in reverse order to verify that
+				// their indexes can be read independently.
+				for i := f.NumRowGroups() - 1; i >= 0; i-- {
+					want, _ := eager.RowGroup(i)
+					got, err := f.RowGroup(i)
+					if err != nil {
+						t.Fatal(err)
+					}
+					wantChunks, gotChunks := want.ColumnChunks(), got.ColumnChunks()
+
+					for j := len(gotChunks) - 1; j >= 0; j-- {
+						if gotChunks[j].ColumnIndex() != nil || gotChunks[j].OffsetIndex() != nil {
+							t.Fatalf("row group %d: column %d: page index loaded when opening the file", i, j)
+						}
+
+						r.reads = 0
+						columnIndex, err := parquet.ReadColumnIndex(gotChunks[j])
+						if err != nil {
+							t.Fatal(err)
+						}
+						offsetIndex, err := parquet.ReadOffsetIndex(gotChunks[j])
+						if err != nil {
+							t.Fatal(err)
+						}
+						if r.reads > 2 {
+							t.Errorf("row group %d: column %d: reading the page index of the column chunk took %d reads", i, j, r.reads)
+						}
+						assertColumnIndexEqual(t, fmt.Sprintf("row group %d: column %d", i, j), wantChunks[j].ColumnIndex(), columnIndex)
+
+						if wantOffsetIndex := wantChunks[j].OffsetIndex(); (offsetIndex == nil) != (wantOffsetIndex == nil) {
+							t.Errorf("row group %d: column %d: offset index mismatch", i, j)
+						} else if offsetIndex != nil {
+							if offsetIndex.NumPages() != wantOffsetIndex.NumPages() {
+								t.Errorf("row group %d: column %d: wrong number of pages in offset index: want=%d got=%d", i, j, wantOffsetIndex.NumPages(), offsetIndex.NumPages())
+							}
+							for k := 0; k < offsetIndex.NumPages(); k++ {
+								if offsetIndex.Offset(k) != wantOffsetIndex.Offset(k) || offsetIndex.FirstRowIndex(k) != wantOffsetIndex.FirstRowIndex(k) {
+									t.Errorf("row group %d: column %d: page %d: wrong offset index", i, j, k)
+								}
+							}
+						}
+
+						// The indexes are retained by the column chunks.
+						r.reads = 0
+						if _, err := parquet.ReadColumnIndex(gotChunks[j]); err != nil {
+							t.Fatal(err)
+						}
+						if _, err := parquet.ReadOffsetIndex(gotChunks[j]); err != nil {
+							t.Fatal(err)
+						}
+						if r.reads != 0 {
+							t.Errorf("row group %d: column %d: page index read again from the file", i, j)
+						}
+						if (gotChunks[j].OffsetIndex() == nil) != (offsetIndex == nil) {
+							t.Errorf("row group %d: column %d: offset index not retained by the column chunk", i, j)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func assertColumnIndexEqual(t *testing.T, what string, want, got parquet.ColumnIndex) {
+	t.Helper()
+	if (want == nil) != (got == nil) {
+		t.Errorf("%s: column index mismatch", what)
+		return
+	}
+	if want == nil {
+		return
+	}
+	if got.NumPages() != want.NumPages() {
+		t.Errorf("%s: wrong number of pages in column index: want=%d got=%d", what, want.NumPages(), got.NumPages())
+		return
+	}
+	for i := 0; i < got.NumPages(); i++ {
+		if got.NullPage(i) != want.NullPage(i) || got.NullCount(i) != want.NullCount(i) {
+			t.Errorf("%s: page %d: wrong null statistics in column index", what, i)
+		}
+		if !parquet.DeepEqual(got.MinValue(i), want.MinValue(i)) || !parquet.DeepEqual(got.MaxValue(i), want.MaxValue(i)) {
+			t.Errorf("%s: page %d: wrong bounds in column index", what, i)
+		}
+	}
+}
+
+func TestOpenFileMemoryLimit(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	limiter := parquet.NewMemoryLimiter(1)
+	openPages := func() parquet.Pages {
+		f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.MemoryLimit(limiter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f.RowGroups()[0].ColumnChunks()[0].Pages()
+	}
+
+	pages1 := openPages()
+	if _, err := pages1.ReadPage(); err != nil {
+		t.Fatal(err)
+	}
+	if limiter.InUse() == 0 {
+		t.Fatal("no memory is accounted for after reading a page")
+	}
+
+	// The second reader must wait until the memory held by the first one is
+	// released, since the limit does not allow holding two pages at once.
+	done := make(chan error)
+	pages2 := openPages()
+	go func() {
+		_, err := pages2.ReadPage()
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("reading a page did not block when the memory limit was reached")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pages1.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	pages2.Close()
+	if inUse := limiter.InUse(); inUse != 0 {
+		t.Errorf("memory is still in use after closing all pages: %d", inUse)
+	}
+}
+
+func TestOpenFileMemoryLimitMultipleColumns(t *testing.T) {
+	type Row struct {
+		A int64
+		B int64
+		C string
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i] = Row{A: int64(i), B: int64(-i), C: fmt.Sprintf("row-%d", i)}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The limit is lower than the size of a single page, readers holding the
+	// pages of some columns must still be able to read the pages of the other
+	// columns, and concurrent readers must not deadlock.
+	data := buffer.Bytes()
+	limiter := parquet.NewMemoryLimiter(1)
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.MemoryLimit(limiter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numReaders = 4
+	done := make(chan error, numReaders)
+	for i := 0; i < numReaders; i++ {
+		go func() {
+			r := f.RowGroups()[0].Rows()
+			defer r.Close()
+			n := 0
+			buf := make([]parquet.Row, 10)
+			for {
+				m, err := r.ReadRows(buf)
+				n += m
+				if err != nil {
+					if err == io.EOF {
+						err = nil
+					}
+					if err == nil && n != len(rows) {
+						err = fmt.Errorf("wrong number of rows read: want=%d got=%d", len(rows), n)
+					}
+					done <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < numReaders; i++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("readers sharing a memory limiter did not make progress")
+		}
+	}
+
+	if inUse := limiter.InUse(); inUse != 0 {
+		t.Errorf("memory is still in use after closing all readers: %d", inUse)
+	}
+}
+
+// memoryLimitRow is the type of rows used to test readers of multiple columns
+// sharing a memory limiter.
+type memoryLimitRow struct {
+	ID   int64   `parquet:"id"`
+	Name *string `parquet:"name,optional"`
+}
+
+func makeMemoryLimitRows(n int) []memoryLimitRow {
+	rows := make([]memoryLimitRow, n)
+	for i := range rows {
+		rows[i].ID = int64(i)
+		if i%2 == 0 {
+			name := fmt.Sprintf("row-%d", i)
+			rows[i].Name = &name
+		}
+	}
+	return rows
+}
+
+// openFileWithRowGroups writes each slice of rows to a row group of a file with
+// small pages, and opens the file with the given options.
+func openFileWithRowGroups(t *testing.T, rowGroups []rows, options ...parquet.FileOption) *parquet.File {
+	buffer := new(bytes.Buffer)
+	writer := parquet.NewWriter(buffer, parquet.PageBufferSize(1024))
+	for _, rows := range rowGroups {
+		for _, row := range rows {
+			if err := writer.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// readWithMemoryLimit runs read in a goroutine, failing the test if it returns
+// an error or if it does not complete because the reader blocked on the memory
+// limiter while holding memory itself.
+func readWithMemoryLimit(t *testing.T, limiter *parquet.MemoryLimiter, read func() error) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- read() }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("reader deadlocked waiting for memory that it holds")
+	}
+
+	if inUse := limiter.InUse(); inUse != 0 {
+		t.Errorf("memory is still in use after closing the reader: %d", inUse)
+	}
+}
+
+func TestOpenFileMemoryLimitMultiRowGroup(t *testing.T) {
+	values := makeMemoryLimitRows(2000)
+	limiter := parquet.NewMemoryLimiter(1500)
+	f := openFileWithRowGroups(t, []rows{makeRows(values[:1000]), makeRows(values[1000:])}, parquet.MemoryLimit(limiter))
+
+	readWithMemoryLimit(t, limiter, func() error {
+		r := parquet.MultiRowGroup(f.RowGroups()...).Rows()
+		defer r.Close()
+		buf := make([]parquet.Row, 100)
+		for n := 0; ; {
+			m, err := r.ReadRows(buf)
+			n += m
+			if err != nil {
+				if err == io.EOF && n != len(values) {
+					err = fmt.Errorf("wrong number of rows read: want=%d got=%d", len(values), n)
+				} else if err == io.EOF {
+					err = nil
+				}
+				return err
+			}
+		}
+	})
+}
+
+func TestOpenFileMemoryLimitBatchReader(t *testing.T) {
+	type Batch struct {
+		ID        []int64  `parquet:"id"`
+		Name      []string `parquet:"name"`
+		NameValid []bool   `parquet:"name,valid"`
+	}
+
+	values := makeMemoryLimitRows(1000)
+	limiter := parquet.NewMemoryLimiter(1)
+	f := openFileWithRowGroups(t, []rows{makeRows(values)}, parquet.MemoryLimit(limiter))
+
+	readWithMemoryLimit(t, limiter, func() error {
+		r := parquet.NewBatchReader(f.RowGroups()[0])
+		defer r.Close()
+		batch := Batch{
+			ID:        make([]int64, 100),
+			Name:      make([]string, 100),
+			NameValid: make([]bool, 100),
+		}
+		for i := 0; ; {
+			n, err := r.ReadBatch(&batch)
+			for j := 0; j < n; j++ {
+				if batch.ID[j] != values[i].ID || batch.NameValid[j] != (values[i].Name != nil) {
+					return fmt.Errorf("wrong values in row %d", i)
+				}
+				i++
+			}
+			if err != nil {
+				if err == io.EOF && i != len(values) {
+					err = fmt.Errorf("wrong number of rows read: want=%d got=%d", len(values), i)
+				} else if err == io.EOF {
+					err = nil
+				}
+				return err
+			}
+		}
+	})
+}
+
+func TestOpenFileVerifyColumnChunks(t *testing.T) {
+	for _, path := range testdataFiles {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.VerifyColumnChunks(true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rowGroup := range f.RowGroups() {
+				for _, chunk := range rowGroup.ColumnChunks() {
+					pages := chunk.Pages()
+					err := forEachPage(pages, func(parquet.Page) error { return nil })
+					pages.Close()
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestOpenFileVerifyColumnChunksInconsistent(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	// rewriteMetadata returns a copy of the file with its footer modified by
+	// the given function.
+	rewriteMetadata := func(modify func(*format.FileMetaData)) []byte {
+		footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+		footerOffset := len(data) - (footerSize + 8)
+
+		metadata := new(format.FileMetaData)
+		if err := thrift.Unmarshal(new(thrift.CompactProtocol), data[footerOffset:len(data)-8], metadata); err != nil {
+			t.Fatal(err)
+		}
+		modify(metadata)
+
+		footer, err := thrift.Marshal(new(thrift.CompactProtocol), metadata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := append([]byte{}, data[:footerOffset]...)
+		b = append(b, footer...)
+		b = append(b, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(b[len(b)-4:], uint32(len(footer)))
+		return append(b, "PAR1"...)
+	}
+
+	t.Run("column chunk size", func(t *testing.T) {
+		data := rewriteMetadata(func(metadata *format.FileMetaData) {
+			metadata.RowGroups[0].Columns[0].MetaData.TotalCompressedSize += int64(len(data))
+		})
+		_, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.VerifyColumnChunks(true))
+		if !errors.Is(err, parquet.ErrInconsistentMetadata) {
+			t.Errorf("expected inconsistent metadata error but got %v", err)
+		}
+	})
+
+	t.Run("page size", func(t *testing.T) {
+		data := rewriteMetadata(func(metadata *format.FileMetaData) {
+			metadata.RowGroups[0].Columns[0].MetaData.TotalCompressedSize--
+			metadata.RowGroups[0].TotalCompressedSize--
+		})
+		// Skip the page index so the error is only detected when reading the
+		// last page, which now extends past the end of the column chunk.
+		f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)),
+			parquet.VerifyColumnChunks(true),
+			parquet.SkipPageIndex(true),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages := f.RowGroups()[0].ColumnChunks()[0].Pages()
+		defer pages.Close()
+		err = forEachPage(pages, func(parquet.Page) error { return nil })
+		if !errors.Is(err, parquet.ErrInconsistentMetadata) {
+			t.Errorf("expected inconsistent metadata error but got %v", err)
+		}
+	})
+}
+
+func TestMayContain(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name"`
+		ID   int32  `parquet:"id"`
+		Tag  string `parquet:"tag"`
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.BloomFilters(
+		parquet.SplitBlockFilter("name"),
+		parquet.SplitBlockFilter("id"),
+	))
+	for i := 0; i < 100; i++ {
+		if err := w.Write(Row{Name: fmt.Sprintf("name-%d", i), ID: int32(i), Tag: "tag"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &countingReaderAt{reader: bytes.NewReader(b.Bytes())}
+	f, err := parquet.OpenFile(r, int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := f.RowGroups()[0].ColumnChunks()
+	name, id, tag := columns[0], columns[1], columns[2]
+
+	// The headers are read when opening the file, the filters are read in
+	// memory when they are first checked, then all the checks are served from
+	// memory.
+	reads := r.reads
+	for i := 0; i < 100; i++ {
+		if ok, err := parquet.MayContain(name, parquet.ValueOf(fmt.Sprintf("name-%d", i))); err != nil || !ok {
+			t.Fatalf("value name-%d of the column was not found in the bloom filter: %v", i, err)
+		}
+	}
+	if n := r.reads - reads; n != 1 {
+		t.Errorf("wrong number of reads to check the bloom filter: want=1 got=%d", n)
+	}
+
+	misses := 0
+	for i := 100; i < 1100; i++ {
+		ok, err := parquet.MayContain(name, parquet.ValueOf(fmt.Sprintf("name-%d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			misses++
+		}
+	}
+	if misses < 900 {
+		t.Errorf("the bloom filter excluded too few values: %d/1000", misses)
+	}
+
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], 42)
+	if ok, err := parquet.MayContainBytes(id, buf[:]); err != nil || !ok {
+		t.Errorf("value 42 of the column was not found in the bloom filter: %v", err)
+	}
+	if ok, err := parquet.MayContainBytes(name, []byte("name-42")); err != nil || !ok {
+		t.Errorf("value name-42 of the column was not found in the bloom filter: %v", err)
+	}
+	if _, err := parquet.MayContainBytes(id, []byte("name-42")); err == nil {
+		t.Error("expected an error checking a value of the wrong size")
+	}
+	if _, err := parquet.MayContain(id, parquet.ValueOf(int64(42))); err == nil {
+		t.Error("expected an error checking a value of the wrong kind")
+	}
+
+	// Values cannot be excluded from column chunks without bloom filters.
+	if ok, err := parquet.MayContain(tag, parquet.ValueOf("other")); err != nil || !ok {
+		t.Errorf("value was excluded from a column chunk without bloom filter: %v", err)
+	}
+}
+
+func TestBloomFilterCacheSize(t *testing.T) {
+	type Row struct {
+		A string `parquet:"a"`
+		B string `parquet:"b"`
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.BloomFilters(
+		parquet.SplitBlockFilter("a"),
+		parquet.SplitBlockFilter("b"),
+	))
+	for i := 0; i < 1000; i++ {
+		if err := w.Write(Row{A: fmt.Sprintf("a-%d", i), B: fmt.Sprintf("b-%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(options ...parquet.FileOption) (*parquet.File, *countingReaderAt) {
+		r := &countingReaderAt{reader: bytes.NewReader(b.Bytes())}
+		f, err := parquet.OpenFile(r, int64(b.Len()), options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f, r
+	}
+
+	// Only the headers of bloom filters are read when opening files.
+	_, r1 := open()
+	_, r2 := open(parquet.SkipBloomFilters(true))
+	if r1.reads != r2.reads+2 {
+		t.Errorf("bloom filters were read when opening the file: reads=%d without filters=%d", r1.reads, r2.reads)
+	}
+
+	// Alternate checks between the two filters, which are released when the
+	// cache only fits one of them.
+	check := func(f *parquet.File, r *countingReaderAt) int {
+		columns := f.RowGroups()[0].ColumnChunks()
+		reads := r.reads
+		for i := 0; i < 10; i++ {
+			for j, c := range columns {
+				v := parquet.ValueOf(fmt.Sprintf("%c-%d", 'a'+j, i))
+				if ok, err := c.BloomFilter().Check(v); err != nil || !ok {
+					t.Fatalf("value %v was not found in the bloom filter: %v", v, err)
+				}
+			}
+		}
+		return r.reads - reads
+	}
+
+	// Each filter is read once when the cache can hold both of them.
+	f, r := open()
+	if n := check(f, r); n != 2 {
+		t.Errorf("wrong number of reads with an unlimited cache: want=2 got=%d", n)
+	}
+
+	size := f.RowGroups()[0].ColumnChunks()[0].BloomFilter().Size()
+	f, r = open(parquet.BloomFilterCacheSize(int(size)))
+	if n := check(f, r); n != 20 {
+		t.Errorf("wrong number of reads with a limited cache: want=20 got=%d", n)
+	}
+
+	if _, err := parquet.NewFileConfig(parquet.BloomFilterCacheSize(-1)); err == nil {
+		t.Error("negative bloom filter cache size was accepted")
+	}
+}
+
+func TestFileBloomFilterStats(t *testing.T) {
+	type Row struct {
+		ID   int64   `parquet:"id"`
+		Name string  `parquet:"name"`
+		Note *string `parquet:"note,optional"`
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.BloomFilters(
+		parquet.SplitBlockFilter("id"),
+		parquet.SplitBlockFilter("note"),
+	))
+	for i := 0; i < 2000; i++ {
+		row := Row{ID: int64(i), Name: fmt.Sprintf("name-%d", i)}
+		if i%2 == 0 {
+			note := fmt.Sprintf("note-%d", i)
+			row.Note = &note
+		}
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+		if i == 999 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := f.BloomFilterStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 4 {
+		t.Fatalf("wrong number of bloom filter stats: %d", len(stats))
+	}
+
+	for i, s := range stats {
+		path := []string{"id", "note"}[i%2]
+		if s.RowGroup != i/2 || len(s.Path) != 1 || s.Path[0] != path {
+			t.Errorf("stats %d: wrong location: row group=%d path=%q", i, s.RowGroup, s.Path)
+		}
+		if s.NumValues != 1000 {
+			t.Errorf("stats %d: wrong number of values: %d", i, s.NumValues)
+		}
+		if s.Size != int64(s.NumBlocks)*32 || s.Size == 0 {
+			t.Errorf("stats %d: size of %d bytes does not match %d blocks", i, s.Size, s.NumBlocks)
+		}
+		if bits := s.BitsPerValue(); bits < 5 || bits > 20 {
+			t.Errorf("stats %d: unexpected number of bits per value: %g", i, bits)
+		}
+		if rate := s.FalsePositiveRate(s.NumValues); rate <= 0 || rate > 0.05 {
+			t.Errorf("stats %d: unexpected false positive rate: %g", i, rate)
+		}
+		if s.FalsePositiveRate(100*s.NumValues) <= s.FalsePositiveRate(s.NumValues) {
+			t.Errorf("stats %d: false positive rate does not increase with the number of values", i)
+		}
+	}
+
+	chunk, _ := parquet.LookupColumnChunk(f.RowGroups()[0], "name")
+	if _, ok, err := parquet.BloomFilterStatsOf(chunk); ok || err != nil {
+		t.Errorf("column without bloom filter reported stats: ok=%t err=%v", ok, err)
+	}
+}
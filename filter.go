@@ -0,0 +1,327 @@
+package parquet
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// FilterRowGroup returns a view of rowGroup exposing only the rows for which
+// keep returns true.
+//
+// The rows are filtered as they are read, which allows programs to pass the
+// returned row group to functions like MergeRowGroups or Writer.WriteRowGroup
+// without first copying the matching rows to a buffer. The rows passed to keep
+// may reference memory owned by the underlying row group, the function must
+// not retain them after returning.
+//
+// NumRows and the column chunks of the returned row group read all the rows of
+// rowGroup once to determine which ones match, the result is then retained for
+// the lifetime of the view. If reading the rows fails, NumRows returns zero and
+// the error is returned when reading the pages of the column chunks. The
+// NumValues method of column chunks reports the number of values in the
+// matching rows, which requires reading their pages once. The pages of the column chunks are slices of the
+// pages of rowGroup, but they have no column or offset indexes since the page
+// boundaries differ from those of the underlying column chunks.
+func FilterRowGroup(rowGroup RowGroup, keep func(Row) bool) RowGroup {
+	return newFilteredRowGroup(rowGroup, keep)
+}
+
+func newFilteredRowGroup(rowGroup RowGroup, keep func(Row) bool) *filteredRowGroup {
+	g := &filteredRowGroup{base: rowGroup, keep: keep}
+	baseColumns := rowGroup.ColumnChunks()
+	columns := make([]filteredColumnChunk, len(baseColumns))
+	g.columns = make([]ColumnChunk, len(baseColumns))
+	for i, base := range baseColumns {
+		columns[i] = filteredColumnChunk{rowGroup: g, base: base}
+		g.columns[i] = &columns[i]
+	}
+	return g
+}
+
+type filteredRowGroup struct {
+	base    RowGroup
+	keep    func(Row) bool
+	columns []ColumnChunk
+	// Ranges of rows of the base row group which match the filter, computed
+	// the first time they are needed. The ranges of row groups with no filter
+	// are set when they are created, see SplitRowGroup.
+	once    sync.Once
+	ranges  []RowRange
+	numRows int64
+	err     error
+}
+
+func (g *filteredRowGroup) NumRows() int64 {
+	if g.init(); g.err != nil {
+		return 0
+	}
+	return g.numRows
+}
+
+func (g *filteredRowGroup) ColumnChunks() []ColumnChunk     { return g.columns }
+func (g *filteredRowGroup) Schema() *Schema                 { return g.base.Schema() }
+func (g *filteredRowGroup) SortingColumns() []SortingColumn { return g.base.SortingColumns() }
+
+func (g *filteredRowGroup) Rows() Rows {
+	return g.rowsWithMemory(nil)
+}
+
+func (g *filteredRowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	if g.keep == nil {
+		return &slicedRows{rows: rowsWithMemory(g.base, m), rowRange: g.ranges[0]}
+	}
+	return &filteredRows{rows: rowsWithMemory(g.base, m), keep: g.keep}
+}
+
+func (g *filteredRowGroup) init() {
+	if g.keep != nil {
+		g.once.Do(func() { g.ranges, g.numRows, g.err = filterRowRanges(g.base, g.keep) })
+	}
+}
+
+// filterRowRanges reads the rows of rowGroup and returns the ranges of rows for
+// which keep returns true, along with the total number of rows they contain.
+func filterRowRanges(rowGroup RowGroup, keep func(Row) bool) ([]RowRange, int64, error) {
+	rows := rowGroup.Rows()
+	defer rows.Close()
+
+	ranges := []RowRange{}
+	buf := make([]Row, defaultRowBufferSize)
+	rowIndex, numRows := int64(0), int64(0)
+
+	for {
+		n, err := rows.ReadRows(buf)
+
+		for _, row := range buf[:n] {
+			if keep(row) {
+				if i := len(ranges) - 1; i >= 0 && ranges[i].FirstRowIndex+ranges[i].NumRows == rowIndex {
+					ranges[i].NumRows++
+				} else {
+					ranges = append(ranges, RowRange{FirstRowIndex: rowIndex, NumRows: 1})
+				}
+				numRows++
+			}
+			rowIndex++
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return ranges, numRows, err
+		}
+	}
+}
+
+// filteredRows reads the rows of a row group, skipping the rows for which keep
+// returns false. Row indexes passed to SeekToRow are relative to the filtered
+// rows.
+type filteredRows struct {
+	rows  Rows
+	keep  func(Row) bool
+	index int64
+	seek  int64
+}
+
+func (r *filteredRows) ReadRows(rows []Row) (int, error) {
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	for {
+		n, err := r.rows.ReadRows(rows)
+		k := 0
+
+		for i, row := range rows[:n] {
+			if !r.keep(row) {
+				continue
+			}
+			r.index++
+			if r.index <= r.seek {
+				continue
+			}
+			// Swap the rows instead of overwriting them so the backing
+			// arrays of dropped rows are retained for the next reads.
+			rows[k], rows[i] = rows[i], rows[k]
+			k++
+		}
+
+		// Reading more rows may release the memory referenced by the rows
+		// kept so far, so they are returned first.
+		if k > 0 || err != nil {
+			return k, err
+		}
+	}
+}
+
+func (r *filteredRows) SeekToRow(rowIndex int64) error {
+	if rowIndex < r.index {
+		if err := r.rows.SeekToRow(0); err != nil {
+			return err
+		}
+		r.index = 0
+	}
+	r.seek = rowIndex
+	return nil
+}
+
+func (r *filteredRows) Close() error    { return r.rows.Close() }
+func (r *filteredRows) Schema() *Schema { return r.rows.Schema() }
+
+type filteredColumnChunk struct {
+	rowGroup *filteredRowGroup
+	base     ColumnChunk
+	// Number of values in the rows matching the filter, computed the first
+	// time it is needed.
+	once      sync.Once
+	numValues int64
+}
+
+func (c *filteredColumnChunk) Type() Type               { return c.base.Type() }
+func (c *filteredColumnChunk) Column() int              { return c.base.Column() }
+func (c *filteredColumnChunk) Pages() Pages             { return &filteredPages{chunk: c} }
+func (c *filteredColumnChunk) ColumnIndex() ColumnIndex { return nil }
+func (c *filteredColumnChunk) OffsetIndex() OffsetIndex { return nil }
+func (c *filteredColumnChunk) BloomFilter() BloomFilter { return c.base.BloomFilter() }
+
+func (c *filteredColumnChunk) pagesWithMemory(m *memoryReservation) Pages {
+	return &filteredPages{chunk: c, memory: m}
+}
+
+func (c *filteredColumnChunk) NumValues() int64 {
+	c.once.Do(func() { c.numValues = c.countValues() })
+	return c.numValues
+}
+
+// countValues reads the pages of the column chunk and returns the number of
+// values they contain, or zero if the pages could not be read.
+func (c *filteredColumnChunk) countValues() int64 {
+	pages := c.Pages()
+	defer pages.Close()
+
+	numValues := int64(0)
+	for {
+		page, err := pages.ReadPage()
+		if err != nil {
+			if err != io.EOF {
+				numValues = 0
+			}
+			return numValues
+		}
+		numValues += page.NumValues()
+	}
+}
+
+// filteredPages reads the pages of a column chunk, returning slices of the pages
+// which hold the rows matching the filter of the row group.
+type filteredPages struct {
+	chunk  *filteredColumnChunk
+	base   Pages
+	memory *memoryReservation
+	// Current page of the base column chunk, and the index of its first row in
+	// the base column chunk.
+	page     Page
+	rowIndex int64
+	// Index of the range of rows that the next page starts in.
+	rangeIndex int
+}
+
+func (p *filteredPages) ReadPage() (Page, error) {
+	return p.ReadPageContext(context.Background())
+}
+
+func (p *filteredPages) ReadPageContext(ctx context.Context) (Page, error) {
+	g := p.chunk.rowGroup
+	if g.init(); g.err != nil {
+		return nil, g.err
+	}
+	if p.base == nil {
+		p.base = pagesWithMemory(p.chunk.base, p.memory)
+	}
+
+	for p.rangeIndex < len(g.ranges) {
+		r := g.ranges[p.rangeIndex]
+		rangeBegin, rangeEnd := r.FirstRowIndex, r.FirstRowIndex+r.NumRows
+
+		if p.page == nil {
+			// Seeking skips the pages between ranges without reading them
+			// when the column chunk has an offset index.
+			if rangeBegin > p.rowIndex {
+				if err := p.base.SeekToRow(rangeBegin); err != nil {
+					return nil, err
+				}
+				p.rowIndex = rangeBegin
+			}
+			page, err := ReadPageContext(ctx, p.base)
+			if err != nil {
+				return nil, err
+			}
+			p.page = page
+		}
+
+		pageBegin := p.rowIndex
+		pageEnd := pageBegin + p.page.NumRows()
+		if rangeBegin >= pageEnd {
+			p.page, p.rowIndex = nil, pageEnd
+			continue
+		}
+		begin, end := pageBegin, min64(pageEnd, rangeEnd)
+		if rangeBegin > begin {
+			begin = rangeBegin
+		}
+		if end == rangeEnd {
+			p.rangeIndex++
+		}
+		page := p.page
+		if end == pageEnd {
+			p.page, p.rowIndex = nil, pageEnd
+		}
+		if begin == pageBegin && end == pageEnd {
+			return page, nil
+		}
+		// When the page spans more ranges it is retained, and the next slice
+		// starts at the first row of the next range.
+		return page.Buffer().Slice(begin-pageBegin, end-pageBegin), nil
+	}
+
+	return nil, io.EOF
+}
+
+// SeekToRow positions the pages at the row at the given index among the rows
+// matching the filter.
+func (p *filteredPages) SeekToRow(rowIndex int64) error {
+	g := p.chunk.rowGroup
+	if g.init(); g.err != nil {
+		return g.err
+	}
+	if p.base == nil {
+		p.base = pagesWithMemory(p.chunk.base, p.memory)
+	}
+
+	// Translate the index of the filtered row to the index of the row in the
+	// base column chunk.
+	i, baseRowIndex := 0, int64(0)
+	for ; i < len(g.ranges); i++ {
+		if rowIndex < g.ranges[i].NumRows {
+			baseRowIndex = g.ranges[i].FirstRowIndex + rowIndex
+			break
+		}
+		rowIndex -= g.ranges[i].NumRows
+	}
+
+	p.page = nil
+	p.rangeIndex = i
+	if i == len(g.ranges) {
+		return nil
+	}
+	p.rowIndex = baseRowIndex
+	return p.base.SeekToRow(baseRowIndex)
+}
+
+func (p *filteredPages) Close() error {
+	p.page = nil
+	if p.base != nil {
+		return p.base.Close()
+	}
+	return nil
+}
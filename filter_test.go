@@ -0,0 +1,227 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+type filterRow struct {
+	ID   int64    `parquet:"id"`
+	Name string   `parquet:"name"`
+	Tags []string `parquet:"tags"`
+}
+
+func makeFilterRows(n int) []filterRow {
+	rows := make([]filterRow, n)
+	for i := range rows {
+		rows[i] = filterRow{ID: int64(i), Name: "name-" + string(rune('a'+i%26)), Tags: []string{}}
+		for j := 0; j < i%4; j++ {
+			rows[i].Tags = append(rows[i].Tags, string(rune('A'+j)))
+		}
+	}
+	return rows
+}
+
+func filterRowFile(t *testing.T, rows []filterRow) *parquet.File {
+	b := new(bytes.Buffer)
+	// The small page size produces pages spanning several filtered ranges,
+	// and ranges spanning several pages.
+	if err := writeParquetFile(b, makeRows(rows), parquet.PageBufferSize(256)); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func readFilterRows(t *testing.T, rows parquet.Rows) []filterRow {
+	t.Helper()
+	schema := parquet.SchemaOf(filterRow{})
+	buf := make([]parquet.Row, 13)
+	values := []filterRow{}
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			var v filterRow
+			if err := schema.Reconstruct(&v, row); err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, v)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			return values
+		}
+	}
+}
+
+func TestFilterRowGroup(t *testing.T) {
+	rows := makeFilterRows(1000)
+	// Rows are kept in runs of varying lengths to exercise ranges which start
+	// and end at random positions within the pages.
+	keep := func(id int64) bool { return (id/7)%3 != 0 || id%5 == 0 }
+
+	want := []filterRow{}
+	for _, row := range rows {
+		if keep(row.ID) {
+			want = append(want, row)
+		}
+	}
+
+	f := filterRowFile(t, rows)
+	filtered := parquet.FilterRowGroup(f.RowGroups()[0], func(row parquet.Row) bool {
+		return keep(row[0].Int64())
+	})
+
+	if n := filtered.NumRows(); n != int64(len(want)) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(want), n)
+	}
+
+	t.Run("values", func(t *testing.T) {
+		numTags := int64(0)
+		for _, row := range want {
+			// Empty lists are represented by a single null value.
+			if len(row.Tags) == 0 {
+				numTags++
+			}
+			numTags += int64(len(row.Tags))
+		}
+		for i, n := range []int64{int64(len(want)), int64(len(want)), numTags} {
+			if numValues := filtered.ColumnChunks()[i].NumValues(); numValues != n {
+				t.Errorf("wrong number of values in column %d: want=%d got=%d", i, n, numValues)
+			}
+		}
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		r := filtered.Rows()
+		defer r.Close()
+		if got := readFilterRows(t, r); !reflect.DeepEqual(got, want) {
+			t.Fatalf("rows mismatch: want=%d rows got=%d rows", len(want), len(got))
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		// Concatenating row groups reads the rows from their column chunks.
+		merged, err := parquet.MergeRowGroups([]parquet.RowGroup{filtered, filtered})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := merged.Rows()
+		defer r.Close()
+		if got := readFilterRows(t, r); !reflect.DeepEqual(got, append(want[:len(want):len(want)], want...)) {
+			t.Fatalf("rows mismatch: want=%d rows got=%d rows", 2*len(want), len(got))
+		}
+	})
+
+	t.Run("seek", func(t *testing.T) {
+		r := parquet.NewRowGroupRowReader(filtered)
+		defer r.Close()
+		for _, rowIndex := range []int64{500, 10, 0, int64(len(want) - 1)} {
+			if err := r.SeekToRow(rowIndex); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]parquet.Row, 1)
+			if _, err := r.ReadRows(buf); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if id := buf[0][0].Int64(); id != want[rowIndex].ID {
+				t.Errorf("wrong row at index %d: want=%d got=%d", rowIndex, want[rowIndex].ID, id)
+			}
+		}
+	})
+
+	t.Run("write", func(t *testing.T) {
+		b := new(bytes.Buffer)
+		w := parquet.NewWriter(b, parquet.SchemaOf(filterRow{}))
+		if _, err := w.WriteRowGroup(filtered); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r := parquet.NewReader(bytes.NewReader(b.Bytes()))
+		defer r.Close()
+		if got := readFilterRows(t, r); !reflect.DeepEqual(got, want) {
+			t.Fatalf("rows mismatch: want=%d rows got=%d rows", len(want), len(got))
+		}
+	})
+}
+
+func TestFilterRowGroupSeekToRow(t *testing.T) {
+	rows := makeFilterRows(100)
+	buffer := parquet.NewBuffer()
+	for _, row := range rows {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filtered := parquet.FilterRowGroup(buffer, func(row parquet.Row) bool {
+		return row[0].Int64()%2 == 1
+	})
+
+	r := filtered.Rows()
+	defer r.Close()
+
+	buf := make([]parquet.Row, 1)
+	for _, rowIndex := range []int64{3, 40, 2, 49} {
+		if err := r.SeekToRow(rowIndex); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ReadRows(buf); err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if id := buf[0][0].Int64(); id != 2*rowIndex+1 {
+			t.Errorf("wrong row at index %d: want=%d got=%d", rowIndex, 2*rowIndex+1, id)
+		}
+	}
+
+	if err := r.SeekToRow(50); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := r.ReadRows(buf); n != 0 || err != io.EOF {
+		t.Errorf("expected io.EOF after the last row, got n=%d err=%v", n, err)
+	}
+}
+
+type errorRowGroup struct {
+	parquet.RowGroup
+	err error
+}
+
+func (g errorRowGroup) Rows() parquet.Rows { return errorRows{g.RowGroup.Rows(), g.err} }
+
+type errorRows struct {
+	parquet.Rows
+	err error
+}
+
+func (r errorRows) ReadRows([]parquet.Row) (int, error) { return 0, r.err }
+
+func TestFilterRowGroupError(t *testing.T) {
+	errRead := errors.New("read error")
+	f := filterRowFile(t, makeFilterRows(10))
+	filtered := parquet.FilterRowGroup(errorRowGroup{f.RowGroups()[0], errRead}, func(parquet.Row) bool { return true })
+
+	if n := filtered.NumRows(); n != 0 {
+		t.Errorf("wrong number of rows: want=0 got=%d", n)
+	}
+	if n := filtered.ColumnChunks()[0].NumValues(); n != 0 {
+		t.Errorf("wrong number of values: want=0 got=%d", n)
+	}
+	pages := filtered.ColumnChunks()[0].Pages()
+	defer pages.Close()
+	if _, err := pages.ReadPage(); !errors.Is(err, errRead) {
+		t.Errorf("wrong error reading pages: %v", err)
+	}
+}
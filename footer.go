@@ -0,0 +1,310 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// lazyFileMetaData mirrors the format.FileMetaData type without the list of
+// row groups, which lets the thrift decoder skip over them when the file is
+// opened with the LazyRowGroups option.
+type lazyFileMetaData struct {
+	Version                  int32                      `thrift:"1,required"`
+	Schema                   []format.SchemaElement     `thrift:"2,required"`
+	NumRows                  int64                      `thrift:"3,required"`
+	KeyValueMetadata         []format.KeyValue          `thrift:"5,optional"`
+	CreatedBy                string                     `thrift:"6,optional"`
+	ColumnOrders             []format.ColumnOrder       `thrift:"7,optional"`
+	EncryptionAlgorithm      format.EncryptionAlgorithm `thrift:"8,optional"`
+	FooterSigningKeyMetadata []byte                     `thrift:"9,optional"`
+}
+
+type lazyRowGroups struct {
+	columns   []*Column
+	dataEnd   int64
+	rowGroups []lazyRowGroup
+	once      sync.Once
+}
+
+type lazyRowGroup struct {
+	once     sync.Once
+	data     []byte
+	metadata *format.RowGroup
+	rowGroup fileRowGroup
+	err      error
+}
+
+// decodeMetadata decodes the file metadata from the footer, and decrypts the
+// metadata of column chunks encrypted with their own key.
+func (f *File) decodeMetadata(footer []byte) error {
+	signature, err := f.decodeFooter(footer, &f.metadata)
+	if err != nil {
+		return err
+	}
+	if err := f.openPlaintextFooter(footer, signature); err != nil {
+		return err
+	}
+	for i := range f.metadata.RowGroups {
+		if err := f.decryption.decryptRowGroup(&f.protocol, i, &f.metadata.RowGroups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeFooter decodes the file metadata from the footer into metadata, and
+// returns the bytes which follow it, holding the signature of plaintext footers
+// of encrypted files.
+func (f *File) decodeFooter(footer []byte, metadata interface{}) (signature []byte, err error) {
+	r := bytes.NewReader(footer)
+	if err := thrift.NewDecoder(f.protocol.NewReader(r)).Decode(metadata); err != nil {
+		return nil, err
+	}
+	return footer[len(footer)-r.Len():], nil
+}
+
+// decodeLazyMetadata decodes the file metadata from the footer, only recording
+// the location of the row groups. The first row group is decoded eagerly since
+// opening the columns of the file requires its metadata.
+func (f *File) decodeLazyMetadata(footer []byte) error {
+	metadata := lazyFileMetaData{}
+	signature, err := f.decodeFooter(footer, &metadata)
+	if err != nil {
+		return err
+	}
+
+	rowGroups, err := scanRowGroups(footer)
+	if err != nil {
+		return err
+	}
+
+	f.metadata = format.FileMetaData{
+		Version:                  metadata.Version,
+		Schema:                   metadata.Schema,
+		NumRows:                  metadata.NumRows,
+		KeyValueMetadata:         metadata.KeyValueMetadata,
+		CreatedBy:                metadata.CreatedBy,
+		ColumnOrders:             metadata.ColumnOrders,
+		EncryptionAlgorithm:      metadata.EncryptionAlgorithm,
+		FooterSigningKeyMetadata: metadata.FooterSigningKeyMetadata,
+	}
+	if err := f.openPlaintextFooter(footer, signature); err != nil {
+		return err
+	}
+	f.lazy = &lazyRowGroups{rowGroups: make([]lazyRowGroup, len(rowGroups))}
+
+	for i, data := range rowGroups {
+		f.lazy.rowGroups[i].data = data
+	}
+
+	// The metadata of the first row group is decoded to select the encoding
+	// and compression of the columns, the list of row groups in f.metadata
+	// remains empty since it would otherwise be incomplete; the rowGroupsMetadata
+	// method must be used to access the metadata of all row groups.
+	if len(rowGroups) > 0 {
+		if _, err := f.lazy.rowGroups[0].decodeMetadata(f, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstRowGroup returns the metadata of the first row group of the file, or
+// nil if the file has no row groups.
+func (l *lazyRowGroups) firstRowGroup() []format.RowGroup {
+	if len(l.rowGroups) == 0 {
+		return nil
+	}
+	return []format.RowGroup{*l.rowGroups[0].metadata}
+}
+
+func (g *lazyRowGroup) decodeMetadata(f *File, index int) (*format.RowGroup, error) {
+	if g.metadata == nil {
+		metadata := new(format.RowGroup)
+		if err := thrift.Unmarshal(&f.protocol, g.data, metadata); err != nil {
+			return nil, fmt.Errorf("decoding metadata of row group %d: %w", index, err)
+		}
+		if err := f.decryption.decryptRowGroup(&f.protocol, index, metadata); err != nil {
+			return nil, err
+		}
+		g.metadata, g.data = metadata, nil
+	}
+	return g.metadata, nil
+}
+
+func (g *lazyRowGroup) decode(f *File, index int) error {
+	metadata, err := g.decodeMetadata(f, index)
+	if err != nil {
+		return err
+	}
+
+	columns := f.lazy.columns
+	if len(metadata.Columns) != len(columns) {
+		return fmt.Errorf("row group at index %d contains %d columns but %d were referenced by the column schemas",
+			index, len(metadata.Columns), len(columns))
+	}
+
+	var columnIndexes []format.ColumnIndex
+	var offsetIndexes []format.OffsetIndex
+
+	if !f.config.SkipPageIndex {
+		columnIndexes, offsetIndexes, err = f.readPageIndexOf(f.reader, index, []format.RowGroup{*metadata})
+		if err != nil {
+			return fmt.Errorf("reading page index of row group %d: %w", index, err)
+		}
+	}
+
+	g.rowGroup.init(f, f.schema, columns, index, metadata, columnIndexes, offsetIndexes)
+
+	if f.config.VerifyColumnChunks {
+		if err := g.rowGroup.verify(f.lazy.dataEnd); err != nil {
+			return err
+		}
+	}
+
+	if !f.config.SkipBloomFilters {
+		if err := f.readBloomFilters(f.reader, &g.rowGroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *lazyRowGroups) rowGroup(f *File, index int) (*fileRowGroup, error) {
+	g := &l.rowGroups[index]
+	g.once.Do(func() { g.err = g.decode(f, index) })
+	if g.err != nil {
+		return nil, g.err
+	}
+	return &g.rowGroup, nil
+}
+
+func (l *lazyRowGroups) metadata(f *File) ([]format.RowGroup, error) {
+	rowGroups := make([]format.RowGroup, len(l.rowGroups))
+	for i := range l.rowGroups {
+		// Go through the row group decoding to synchronize with concurrent
+		// accesses to the metadata.
+		g, err := l.rowGroup(f, i)
+		if err != nil {
+			return nil, err
+		}
+		rowGroups[i] = *g.rowGroup
+	}
+	return rowGroups, nil
+}
+
+// scanRowGroups returns the sections of the footer holding the thrift encoding
+// of each row group of the file metadata.
+func scanRowGroups(footer []byte) ([][]byte, error) {
+	b := bytes.NewReader(footer)
+	r := (&thrift.CompactProtocol{}).NewReader(b)
+	id := int16(0)
+
+	for {
+		f, err := r.ReadField()
+		if err != nil {
+			return nil, err
+		}
+		if f.Type == thrift.STOP {
+			return nil, nil
+		}
+		if f.Delta {
+			f.ID += id
+		}
+		id = f.ID
+
+		if f.ID != 4 {
+			if err := skipThriftField(r, f.Type); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		l, err := r.ReadList()
+		if err != nil {
+			return nil, err
+		}
+		if f.Type != thrift.LIST || l.Type != thrift.STRUCT {
+			return nil, fmt.Errorf("invalid thrift type of row groups in file metadata: %s", f.Type)
+		}
+		if int(l.Size) > b.Len() {
+			return nil, fmt.Errorf("invalid number of row groups in file metadata: %d", l.Size)
+		}
+
+		rowGroups := make([][]byte, l.Size)
+		for i := range rowGroups {
+			start := len(footer) - b.Len()
+			if err := skipThriftStruct(r); err != nil {
+				return nil, fmt.Errorf("scanning metadata of row group %d: %w", i, err)
+			}
+			rowGroups[i] = footer[start : len(footer)-b.Len()]
+		}
+		return rowGroups, nil
+	}
+}
+
+// skipThriftField skips the value of a struct field, booleans are encoded in
+// the field type and carry no value.
+func skipThriftField(r thrift.Reader, t thrift.Type) error {
+	switch t {
+	case thrift.TRUE, thrift.FALSE:
+		return nil
+	default:
+		return skipThriftValue(r, t)
+	}
+}
+
+func skipThriftValue(r thrift.Reader, t thrift.Type) (err error) {
+	switch t {
+	case thrift.TRUE, thrift.FALSE:
+		_, err = r.ReadBool()
+	case thrift.I8:
+		_, err = r.ReadInt8()
+	case thrift.I16, thrift.I32, thrift.I64:
+		_, err = r.ReadInt64()
+	case thrift.DOUBLE:
+		_, err = r.ReadFloat64()
+	case thrift.BINARY:
+		_, err = r.ReadBytes()
+	case thrift.LIST, thrift.SET:
+		var l thrift.List
+		if l, err = r.ReadList(); err == nil {
+			for i := int32(0); i < l.Size && err == nil; i++ {
+				err = skipThriftValue(r, l.Type)
+			}
+		}
+	case thrift.MAP:
+		var m thrift.Map
+		if m, err = r.ReadMap(); err == nil {
+			for i := int32(0); i < m.Size && err == nil; i++ {
+				if err = skipThriftValue(r, m.Key); err == nil {
+					err = skipThriftValue(r, m.Value)
+				}
+			}
+		}
+	case thrift.STRUCT:
+		err = skipThriftStruct(r)
+	default:
+		err = fmt.Errorf("cannot skip unknown thrift type: %s", t)
+	}
+	return err
+}
+
+func skipThriftStruct(r thrift.Reader) error {
+	for {
+		f, err := r.ReadField()
+		if err != nil {
+			return err
+		}
+		if f.Type == thrift.STOP {
+			return nil
+		}
+		if err := skipThriftField(r, f.Type); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Package hyperloglog implements HyperLogLog sketches, which estimate the number
+// of distinct values in a set using a small, fixed amount of memory.
+//
+// Sketches are built from 64 bits hashes of the values, which must be uniformly
+// distributed; for example, the xxhash of the values used by bloom filters.
+package hyperloglog
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	// MinPrecision and MaxPrecision are the bounds of the precision of
+	// sketches, which is the number of bits of hashes used to select the
+	// register that they update. A sketch of precision p has 2^p registers
+	// of one byte, and a relative standard error of about 1.04/sqrt(2^p).
+	MinPrecision = 4
+	MaxPrecision = 16
+
+	// Version of the binary representation of sketches.
+	version = 1
+)
+
+// Sketch is a HyperLogLog sketch estimating the number of distinct hashes
+// inserted in it.
+type Sketch struct {
+	precision uint8
+	registers []uint8
+}
+
+// New constructs a sketch of the given precision, which must be between
+// MinPrecision and MaxPrecision.
+func New(precision int) (*Sketch, error) {
+	if precision < MinPrecision || precision > MaxPrecision {
+		return nil, fmt.Errorf("hyperloglog precision out of range [%d:%d]: %d", MinPrecision, MaxPrecision, precision)
+	}
+	return &Sketch{
+		precision: uint8(precision),
+		registers: make([]uint8, 1<<precision),
+	}, nil
+}
+
+// Precision returns the precision of s.
+func (s *Sketch) Precision() int { return int(s.precision) }
+
+// Reset clears the content of s.
+func (s *Sketch) Reset() {
+	for i := range s.registers {
+		s.registers[i] = 0
+	}
+}
+
+// Insert adds the hash x to s.
+func (s *Sketch) Insert(x uint64) {
+	p := s.precision
+	i := x >> (64 - p)
+	// The lowest bit set after shifting bounds the rank to 64-p+1, which is
+	// the maximum number of leading zeros plus one in the remaining bits.
+	r := uint8(bits.LeadingZeros64(x<<p|1<<(p-1))) + 1
+	if r > s.registers[i] {
+		s.registers[i] = r
+	}
+}
+
+// InsertBulk adds all the hashes of x to s.
+func (s *Sketch) InsertBulk(x []uint64) {
+	for _, h := range x {
+		s.Insert(h)
+	}
+}
+
+// Merge adds the hashes inserted in other to s, so s estimates the number of
+// distinct hashes of the union of both sketches. The sketches must have the
+// same precision.
+func (s *Sketch) Merge(other *Sketch) error {
+	if s.precision != other.precision {
+		return fmt.Errorf("cannot merge hyperloglog sketches of different precisions: %d and %d", s.precision, other.precision)
+	}
+	for i, r := range other.registers {
+		if r > s.registers[i] {
+			s.registers[i] = r
+		}
+	}
+	return nil
+}
+
+// Estimate returns the estimated number of distinct hashes inserted in s.
+func (s *Sketch) Estimate() uint64 {
+	m := float64(len(s.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range s.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha(len(s.registers)) * m * m / sum
+	// Small cardinalities are estimated more accurately by linear counting of
+	// the empty registers. Hashes are 64 bits, so there is no need for a
+	// correction of large cardinalities.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// MarshalBinary returns the binary representation of s.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 2+len(s.registers))
+	b[0] = version
+	b[1] = s.precision
+	copy(b[2:], s.registers)
+	return b, nil
+}
+
+// UnmarshalBinary sets s to the sketch represented by b, which must have been
+// produced by MarshalBinary.
+func (s *Sketch) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 {
+		return fmt.Errorf("hyperloglog sketch too short: %d bytes", len(b))
+	}
+	if b[0] != version {
+		return fmt.Errorf("unsupported hyperloglog sketch version: %d", b[0])
+	}
+	precision := int(b[1])
+	if precision < MinPrecision || precision > MaxPrecision {
+		return fmt.Errorf("hyperloglog precision out of range [%d:%d]: %d", MinPrecision, MaxPrecision, precision)
+	}
+	if n := 1 << precision; len(b)-2 != n {
+		return fmt.Errorf("hyperloglog sketch of precision %d has %d registers instead of %d", precision, len(b)-2, n)
+	}
+	s.precision = uint8(precision)
+	s.registers = append(s.registers[:0], b[2:]...)
+	return nil
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
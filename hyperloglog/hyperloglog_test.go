@@ -0,0 +1,94 @@
+package hyperloglog_test
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/segmentio/parquet-go/hyperloglog"
+)
+
+func TestSketchEstimate(t *testing.T) {
+	p := rand.New(rand.NewSource(0))
+
+	for _, precision := range []int{hyperloglog.MinPrecision, 10, 14} {
+		for _, n := range []int{0, 1, 10, 1000, 100e3} {
+			s, err := hyperloglog.New(precision)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < n; i++ {
+				x := p.Uint64()
+				// Inserting hashes multiple times does not change the
+				// estimate.
+				s.Insert(x)
+				s.Insert(x)
+			}
+
+			// Allow 4 standard errors, and a small absolute error for the
+			// lowest cardinalities.
+			stdErr := 1.04 / math.Sqrt(float64(uint(1)<<precision))
+			estimate := float64(s.Estimate())
+			if math.Abs(estimate-float64(n)) > 4*stdErr*float64(n)+1 {
+				t.Errorf("precision=%d: estimated %g distinct values instead of %d", precision, estimate, n)
+			}
+		}
+	}
+}
+
+func TestSketchMerge(t *testing.T) {
+	p := rand.New(rand.NewSource(0))
+	x := make([]uint64, 20e3)
+	for i := range x {
+		x[i] = p.Uint64()
+	}
+
+	s1, _ := hyperloglog.New(12)
+	s2, _ := hyperloglog.New(12)
+	all, _ := hyperloglog.New(12)
+	s1.InsertBulk(x[:15e3])
+	s2.InsertBulk(x[5e3:])
+	all.InsertBulk(x)
+
+	if err := s1.Merge(s2); err != nil {
+		t.Fatal(err)
+	}
+	if s1.Estimate() != all.Estimate() {
+		t.Errorf("merged sketch estimates %d distinct values, the sketch of all values estimates %d", s1.Estimate(), all.Estimate())
+	}
+
+	s3, _ := hyperloglog.New(10)
+	if err := s1.Merge(s3); err == nil {
+		t.Error("merging sketches of different precisions did not fail")
+	}
+}
+
+func TestSketchMarshalBinary(t *testing.T) {
+	s, _ := hyperloglog.New(8)
+	for i := uint64(0); i < 1000; i++ {
+		s.Insert(i * 0x9E3779B97F4A7C15)
+	}
+
+	b, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := new(hyperloglog.Sketch)
+	if err := decoded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Precision() != s.Precision() || decoded.Estimate() != s.Estimate() {
+		t.Errorf("decoded sketch differs: precision=%d estimate=%d", decoded.Precision(), decoded.Estimate())
+	}
+	if b2, _ := decoded.MarshalBinary(); !bytes.Equal(b, b2) {
+		t.Error("the binary representation changed after decoding")
+	}
+
+	if err := decoded.UnmarshalBinary(b[:len(b)-1]); err == nil {
+		t.Error("decoding a truncated sketch did not fail")
+	}
+	if _, err := hyperloglog.New(hyperloglog.MaxPrecision + 1); err == nil {
+		t.Error("creating a sketch with a precision out of range did not fail")
+	}
+}
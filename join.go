@@ -0,0 +1,345 @@
+package parquet
+
+import (
+	"fmt"
+)
+
+// The JoinConfig type carries configuration options for joins of sorted rows.
+//
+// JoinConfig implements the JoinOption interface so it can be used directly as
+// argument to the JoinRows and JoinRowGroups functions, for example:
+//
+//	err := parquet.JoinRows(left, right, on, join, &parquet.JoinConfig{
+//		LeftOuter:  true,
+//		RightOuter: true,
+//	})
+//
+type JoinConfig struct {
+	// When true, rows of the left side which have no matching rows on the
+	// right side are passed to the join function with a nil right row.
+	LeftOuter bool
+	// When true, rows of the right side which have no matching rows on the
+	// left side are passed to the join function with a nil left row.
+	RightOuter bool
+	// Schemas that the rows of each side are converted to before being
+	// joined, or nil to retain all the columns of the side.
+	LeftProjection  Node
+	RightProjection Node
+}
+
+// Apply applies options to c.
+func (c *JoinConfig) Apply(options ...JoinOption) {
+	for _, opt := range options {
+		opt.ConfigureJoin(c)
+	}
+}
+
+// ConfigureJoin satisfies the JoinOption interface.
+func (c *JoinConfig) ConfigureJoin(config *JoinConfig) {
+	*config = JoinConfig{
+		LeftOuter:       c.LeftOuter || config.LeftOuter,
+		RightOuter:      c.RightOuter || config.RightOuter,
+		LeftProjection:  coalesceNode(c.LeftProjection, config.LeftProjection),
+		RightProjection: coalesceNode(c.RightProjection, config.RightProjection),
+	}
+}
+
+// JoinLeftOuter constructs a configuration option which emits the rows of the
+// left side that have no matching rows on the right side.
+//
+// Defaults to false, which means only the rows that match are emitted.
+func JoinLeftOuter(leftOuter bool) JoinOption {
+	return joinOption(func(c *JoinConfig) { c.LeftOuter = leftOuter })
+}
+
+// JoinRightOuter constructs a configuration option which emits the rows of the
+// right side that have no matching rows on the left side.
+//
+// Defaults to false, which means only the rows that match are emitted.
+func JoinRightOuter(rightOuter bool) JoinOption {
+	return joinOption(func(c *JoinConfig) { c.RightOuter = rightOuter })
+}
+
+// JoinProjection constructs a configuration option which converts the rows of
+// each side to the given schemas before joining them. A nil schema retains all
+// the columns of its side. The schemas must contain the columns of the join
+// key.
+//
+// When joining row groups with JoinRowGroups, the columns which are not part
+// of the projection are not read.
+func JoinProjection(left, right Node) JoinOption {
+	return joinOption(func(c *JoinConfig) { c.LeftProjection, c.RightProjection = left, right })
+}
+
+// JoinOption is an interface implemented by types that carry configuration
+// options for joins of sorted rows.
+type JoinOption interface {
+	ConfigureJoin(*JoinConfig)
+}
+
+type joinOption func(*JoinConfig)
+
+func (f joinOption) ConfigureJoin(c *JoinConfig) { f(c) }
+
+// JoinRows performs a sort-merge join of the rows read from left and right,
+// which must both be sorted on the columns passed as the on argument. The rows
+// are streamed from both sides, only the rows of the right side which share
+// the same key are held in memory at once.
+//
+// The join function is called with each pair of rows with equal values in the
+// key columns, in the order of the keys. When several rows of a side have the
+// same key, the function is called with each combination of the rows of both
+// sides. With the JoinLeftOuter and JoinRightOuter options, the function is
+// also called with the rows that have no matches, and a nil row for the other
+// side. Like in SQL joins, rows with a null value in a key column never match.
+// The rows passed to the function are only valid until it returns; programs
+// that need to retain them must make copies with Row.Clone.
+//
+// The key columns are looked up by path in the schemas of both sides, after
+// applying the projection configured with JoinProjection. The columns must
+// not be repeated, and must have the same kind of values on both sides. The
+// results are undefined if the rows are not sorted in the order of the key
+// columns.
+//
+// The function returns the first error returned by the join function or by
+// reading the rows. The row readers are not closed.
+func JoinRows(left, right RowReaderWithSchema, on []SortingColumn, join func(left, right Row) error, options ...JoinOption) error {
+	config := new(JoinConfig)
+	config.Apply(options...)
+
+	leftRows, err := projectJoinRows(left, config.LeftProjection)
+	if err != nil {
+		return err
+	}
+	rightRows, err := projectJoinRows(right, config.RightProjection)
+	if err != nil {
+		return err
+	}
+	return joinRows(leftRows, rightRows, on, join, config)
+}
+
+// JoinRowGroups is like JoinRows but joins the rows of two row groups. The
+// projection configured with JoinProjection is applied to the row groups, so
+// only the columns of the projection are read, which is useful to join the
+// row groups of files where the join needs few of their columns.
+func JoinRowGroups(left, right RowGroup, on []SortingColumn, join func(left, right Row) error, options ...JoinOption) error {
+	config := new(JoinConfig)
+	config.Apply(options...)
+
+	left, err := projectJoinRowGroup(left, config.LeftProjection)
+	if err != nil {
+		return err
+	}
+	right, err = projectJoinRowGroup(right, config.RightProjection)
+	if err != nil {
+		return err
+	}
+
+	// The rows of both row groups are read together, the memory of their pages
+	// is reserved on behalf of the join, see rowsWithMemory.
+	memory := new(memoryReservation)
+	leftRows := rowsWithMemory(left, memory)
+	defer leftRows.Close()
+	rightRows := rowsWithMemory(right, memory)
+	defer rightRows.Close()
+	return joinRows(leftRows, rightRows, on, join, config)
+}
+
+func projectJoinRows(rows RowReaderWithSchema, projection Node) (RowReaderWithSchema, error) {
+	if projection == nil {
+		return rows, nil
+	}
+	conv, err := Convert(projection, rows.Schema())
+	if err != nil {
+		return nil, err
+	}
+	return ConvertRowReader(rows, conv), nil
+}
+
+func projectJoinRowGroup(rowGroup RowGroup, projection Node) (RowGroup, error) {
+	if projection == nil {
+		return rowGroup, nil
+	}
+	conv, err := Convert(projection, rowGroup.Schema())
+	if err != nil {
+		return nil, err
+	}
+	return ConvertRowGroup(rowGroup, conv), nil
+}
+
+func joinRows(left, right RowReaderWithSchema, on []SortingColumn, join func(left, right Row) error, config *JoinConfig) error {
+	leftColumns := make([]mergeSortColumn, len(on))
+	rightColumns := make([]mergeSortColumn, len(on))
+	compare := make([]func(a, b Value) int, len(on))
+
+	for i, sortingColumn := range on {
+		path := sortingColumn.Path()
+		leftLeaf, ok := left.Schema().Lookup(path...)
+		if !ok {
+			return fmt.Errorf("joining rows: column %q not found in the schema of the left rows", columnPath(path))
+		}
+		rightLeaf, ok := right.Schema().Lookup(path...)
+		if !ok {
+			return fmt.Errorf("joining rows: column %q not found in the schema of the right rows", columnPath(path))
+		}
+		if leftLeaf.MaxRepetitionLevel > 0 || rightLeaf.MaxRepetitionLevel > 0 {
+			return fmt.Errorf("joining rows: column %q is repeated", columnPath(path))
+		}
+		leftType, rightType := leftLeaf.Node.Type(), rightLeaf.Node.Type()
+		if leftType.Kind() != rightType.Kind() {
+			return fmt.Errorf("joining rows: column %q has values of kind %s on the left and %s on the right", columnPath(path), leftType.Kind(), rightType.Kind())
+		}
+
+		maxDefinitionLevel := leftLeaf.MaxDefinitionLevel
+		if rightLeaf.MaxDefinitionLevel > maxDefinitionLevel {
+			maxDefinitionLevel = rightLeaf.MaxDefinitionLevel
+		}
+		compare[i] = compareValueFuncOf(leftType, &SortConfig{
+			MaxDefinitionLevel: maxDefinitionLevel,
+			Descending:         sortingColumn.Descending(),
+			NullsFirst:         sortingColumn.NullsFirst(),
+		})
+		// The compare functions are only tested for being non-nil by the
+		// cursors, which then copy the values of the key columns.
+		leftColumns[i] = mergeSortColumn{columnIndex: int16(leftLeaf.ColumnIndex), compareValue: compare[i]}
+		rightColumns[i] = mergeSortColumn{columnIndex: int16(rightLeaf.ColumnIndex), compareValue: compare[i]}
+	}
+
+	j := &joiner{
+		left:    mergeCursor{reader: left, columns: leftColumns, values: make([]Value, len(on))},
+		right:   mergeCursor{reader: right, columns: rightColumns, values: make([]Value, len(on))},
+		compare: compare,
+		key:     make([]Value, len(on)),
+		join:    join,
+		config:  config,
+	}
+	return j.run()
+}
+
+// joiner merges the rows of two cursors positioned on rows sorted by the same
+// key columns.
+type joiner struct {
+	left, right mergeCursor
+	compare     []func(a, b Value) int
+	join        func(left, right Row) error
+	config      *JoinConfig
+	// Rows of the right side sharing the key of the current group, copied to
+	// the arena since the cursor reuses its buffer of rows when advancing.
+	group []Row
+	arena []byte
+	key   []Value
+}
+
+func (j *joiner) run() error {
+	if err := j.left.next(); err != nil {
+		return err
+	}
+	if err := j.right.next(); err != nil {
+		return err
+	}
+
+	for !j.left.done() || !j.right.done() {
+		var cmp int
+		switch {
+		case j.left.done():
+			cmp = +1
+		case j.right.done():
+			cmp = -1
+		default:
+			cmp = j.compareKeys(j.left.values, j.right.values)
+		}
+
+		switch {
+		case cmp < 0 || (cmp == 0 && hasNullValue(j.left.values)):
+			// Rows with null keys compare equal to each other but never match,
+			// the rows of the right side are emitted once the left side moved
+			// past them.
+			if err := j.emitUnmatched(&j.left, j.config.LeftOuter, func(row Row) error { return j.join(row, nil) }); err != nil {
+				return err
+			}
+		case cmp > 0:
+			if err := j.emitUnmatched(&j.right, j.config.RightOuter, func(row Row) error { return j.join(nil, row) }); err != nil {
+				return err
+			}
+		default:
+			if err := j.joinGroup(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (j *joiner) emitUnmatched(c *mergeCursor, emit bool, join func(Row) error) error {
+	if emit {
+		if err := join(c.row()); err != nil {
+			return err
+		}
+	}
+	return c.next()
+}
+
+// joinGroup joins the rows of both sides which have the key of the rows that
+// the cursors are positioned on.
+func (j *joiner) joinGroup() error {
+	numRows := 0
+	j.arena = j.arena[:0]
+	for {
+		// The rows of the previous group are not referenced anymore, their
+		// slices are reused to hold the copies of the rows of this group.
+		if numRows == len(j.group) {
+			j.group = append(j.group, nil)
+		}
+		row := j.group[numRows][:0]
+		row, j.arena = CloneValues(row, j.arena, j.right.row())
+		j.group[numRows] = row
+		numRows++
+		if numRows == 1 {
+			// The key is taken from the copy of the first row, it remains
+			// valid when the cursor advances.
+			for i, column := range j.right.columns {
+				if values := row.valuesOf(column.columnIndex); len(values) > 0 {
+					j.key[i] = values[0]
+				} else {
+					j.key[i] = Value{}
+				}
+			}
+		}
+		if err := j.right.next(); err != nil {
+			return err
+		}
+		if j.right.done() || j.compareKeys(j.key, j.right.values) != 0 {
+			break
+		}
+	}
+
+	for !j.left.done() && j.compareKeys(j.left.values, j.key) == 0 {
+		for _, row := range j.group[:numRows] {
+			if err := j.join(j.left.row(), row); err != nil {
+				return err
+			}
+		}
+		if err := j.left.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *joiner) compareKeys(a, b []Value) int {
+	for i, compare := range j.compare {
+		if cmp := compare(a[i], b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func hasNullValue(values []Value) bool {
+	for _, v := range values {
+		if v.IsNull() {
+			return true
+		}
+	}
+	return false
+}
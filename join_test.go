@@ -0,0 +1,301 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+type joinLeftRow struct {
+	Key   *int64 `parquet:"key,optional"`
+	Seq   int    `parquet:"seq"`
+	Name  string `parquet:"name"`
+	Notes string `parquet:"notes"`
+}
+
+type joinRightRow struct {
+	Key   int64  `parquet:"key"`
+	Seq   int    `parquet:"seq"`
+	Color string `parquet:"color"`
+}
+
+func makeJoinRows(prng *rand.Rand, n int) ([]joinLeftRow, []joinRightRow) {
+	left := make([]joinLeftRow, n)
+	for i := range left {
+		left[i] = joinLeftRow{Seq: i, Name: fmt.Sprintf("name-%d", i), Notes: fmt.Sprintf("notes-%d", i)}
+		if prng.Intn(10) != 0 {
+			key := prng.Int63n(int64(n / 2))
+			left[i].Key = &key
+		}
+	}
+	right := make([]joinRightRow, n)
+	for i := range right {
+		right[i] = joinRightRow{Key: prng.Int63n(int64(n / 2)), Seq: i, Color: fmt.Sprintf("color-%d", i)}
+	}
+	// Rows are sorted by ascending key, with nulls last.
+	sort.SliceStable(left, func(i, j int) bool {
+		a, b := left[i].Key, left[j].Key
+		return a != nil && (b == nil || *a < *b)
+	})
+	sort.SliceStable(right, func(i, j int) bool { return right[i].Key < right[j].Key })
+	return left, right
+}
+
+func writeJoinFile(t *testing.T, rows interface{}) *parquet.File {
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.PageBufferSize(1024))
+	v := reflect.ValueOf(rows)
+	for i := 0; i < v.Len(); i++ {
+		if err := w.Write(v.Index(i).Addr().Interface()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestJoinRows(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+	left, right := makeJoinRows(prng, 500)
+	leftFile := writeJoinFile(t, left)
+	rightFile := writeJoinFile(t, right)
+	leftSchema := parquet.SchemaOf(new(joinLeftRow))
+	rightSchema := parquet.SchemaOf(new(joinRightRow))
+	on := []parquet.SortingColumn{parquet.Ascending("key")}
+
+	for _, test := range []struct {
+		scenario   string
+		leftOuter  bool
+		rightOuter bool
+	}{
+		{scenario: "inner"},
+		{scenario: "left outer", leftOuter: true},
+		{scenario: "right outer", rightOuter: true},
+		{scenario: "full outer", leftOuter: true, rightOuter: true},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			want := []string{}
+			leftMatched := make([]bool, len(left))
+			rightMatched := make([]bool, len(right))
+			for i := range left {
+				for j := range right {
+					if left[i].Key != nil && *left[i].Key == right[j].Key {
+						want = append(want, fmt.Sprintf("%d/%d", left[i].Seq, right[j].Seq))
+						leftMatched[i], rightMatched[j] = true, true
+					}
+				}
+			}
+			for i := range left {
+				if test.leftOuter && !leftMatched[i] {
+					want = append(want, fmt.Sprintf("%d/-", left[i].Seq))
+				}
+			}
+			for j := range right {
+				if test.rightOuter && !rightMatched[j] {
+					want = append(want, fmt.Sprintf("-/%d", right[j].Seq))
+				}
+			}
+			sort.Strings(want)
+
+			options := []parquet.JoinOption{
+				parquet.JoinLeftOuter(test.leftOuter),
+				parquet.JoinRightOuter(test.rightOuter),
+			}
+
+			t.Run("rows", func(t *testing.T) {
+				leftRows := parquet.NewReader(leftFile)
+				defer leftRows.Close()
+				rightRows := parquet.NewReader(rightFile)
+				defer rightRows.Close()
+
+				got := []string{}
+				prevKey := int64(-1)
+				err := parquet.JoinRows(leftRows, rightRows, on, func(l, r parquet.Row) error {
+					a, b := joinLeftRow{}, joinRightRow{}
+					ls, rs := "-", "-"
+					if l != nil {
+						if err := leftSchema.Reconstruct(&a, l); err != nil {
+							return err
+						}
+						if a.Name != fmt.Sprintf("name-%d", a.Seq) || a.Notes != fmt.Sprintf("notes-%d", a.Seq) {
+							return fmt.Errorf("wrong values in left row: %+v", a)
+						}
+						ls = fmt.Sprint(a.Seq)
+					}
+					if r != nil {
+						if err := rightSchema.Reconstruct(&b, r); err != nil {
+							return err
+						}
+						if b.Color != fmt.Sprintf("color-%d", b.Seq) {
+							return fmt.Errorf("wrong values in right row: %+v", b)
+						}
+						rs = fmt.Sprint(b.Seq)
+					}
+					if l != nil && r != nil {
+						if *a.Key != b.Key {
+							return fmt.Errorf("rows with different keys were joined: %d != %d", *a.Key, b.Key)
+						}
+						if b.Key < prevKey {
+							return fmt.Errorf("keys joined out of order: %d < %d", b.Key, prevKey)
+						}
+						prevKey = b.Key
+					}
+					got = append(got, ls+"/"+rs)
+					return nil
+				}, options...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("wrong pairs of joined rows: want %d pairs, got %d", len(want), len(got))
+				}
+			})
+
+			t.Run("row groups", func(t *testing.T) {
+				// The projection drops the name and notes columns of the left
+				// side and the color column of the right side.
+				leftProjection := parquet.Group{
+					"key": parquet.Optional(parquet.Int(64)),
+					"seq": parquet.Int(64),
+				}
+				rightProjection := parquet.Group{
+					"seq": parquet.Int(64),
+					"key": parquet.Int(64),
+				}
+				options := append(options, parquet.JoinProjection(leftProjection, rightProjection))
+
+				got := []string{}
+				err := parquet.JoinRowGroups(leftFile.RowGroups()[0], rightFile.RowGroups()[0], on, func(l, r parquet.Row) error {
+					ls, rs := "-", "-"
+					if l != nil {
+						if len(l) != 2 {
+							return fmt.Errorf("the left row was not projected: %v", l)
+						}
+						// The columns of the projection are ordered by name.
+						ls = fmt.Sprint(l[1].Int64())
+					}
+					if r != nil {
+						if len(r) != 2 {
+							return fmt.Errorf("the right row was not projected: %v", r)
+						}
+						rs = fmt.Sprint(r[1].Int64())
+					}
+					got = append(got, ls+"/"+rs)
+					return nil
+				}, options...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(want, got) {
+					t.Errorf("wrong pairs of joined rows: want %d pairs, got %d", len(want), len(got))
+				}
+			})
+		})
+	}
+}
+
+func TestJoinRowsErrors(t *testing.T) {
+	prng := rand.New(rand.NewSource(0))
+	left, right := makeJoinRows(prng, 10)
+	leftFile := writeJoinFile(t, left)
+	rightFile := writeJoinFile(t, right)
+	join := func(l, r parquet.Row) error { return nil }
+
+	stringKeys := []struct {
+		Key string `parquet:"key"`
+	}{{Key: "A"}, {Key: "B"}}
+	stringFile := writeJoinFile(t, stringKeys)
+
+	for _, test := range []struct {
+		scenario string
+		right    *parquet.File
+		on       []parquet.SortingColumn
+	}{
+		{scenario: "missing column", right: rightFile, on: []parquet.SortingColumn{parquet.Ascending("name")}},
+		{scenario: "mismatching kinds", right: stringFile, on: []parquet.SortingColumn{parquet.Ascending("key")}},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			if err := parquet.JoinRowGroups(leftFile.RowGroups()[0], test.right.RowGroups()[0], test.on, join); err == nil {
+				t.Error("no error joining the row groups")
+			}
+		})
+	}
+
+	errStop := errors.New("stop")
+	err := parquet.JoinRowGroups(leftFile.RowGroups()[0], rightFile.RowGroups()[0], []parquet.SortingColumn{parquet.Ascending("key")},
+		func(l, r parquet.Row) error { return errStop },
+		parquet.JoinLeftOuter(true),
+	)
+	if !errors.Is(err, errStop) {
+		t.Errorf("wrong error returned by the join: %v", err)
+	}
+}
+
+func TestJoinRowsByteArrayKeys(t *testing.T) {
+	type Row struct {
+		Key string `parquet:"key"`
+		Seq int    `parquet:"seq"`
+	}
+
+	// The groups of rows with the same key span several pages and batches of
+	// rows, the key of the group must remain valid when the rows are read.
+	left := make([]Row, 20)
+	right := make([]Row, 1000)
+	for i := range left {
+		left[i] = Row{Key: fmt.Sprintf("key-%03d", 5*i), Seq: i}
+	}
+	for i := range right {
+		right[i] = Row{Key: fmt.Sprintf("key-%03d", i/10), Seq: i}
+	}
+	leftFile := writeJoinFile(t, left)
+	rightFile := writeJoinFile(t, right)
+
+	numRows := 0
+	err := parquet.JoinRowGroups(leftFile.RowGroups()[0], rightFile.RowGroups()[0], []parquet.SortingColumn{parquet.Ascending("key")}, func(l, r parquet.Row) error {
+		if !parquet.Equal(l[0], r[0]) {
+			return fmt.Errorf("rows with different keys were joined: %q != %q", l[0], r[0])
+		}
+		numRows++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numRows != 200 {
+		t.Errorf("wrong number of joined rows: want=200 got=%d", numRows)
+	}
+}
+
+func TestJoinConfig(t *testing.T) {
+	schema := parquet.SchemaOf(struct {
+		Key int64 `parquet:"key"`
+	}{})
+
+	config := new(parquet.JoinConfig)
+	config.Apply(
+		parquet.JoinLeftOuter(true),
+		parquet.JoinProjection(schema, nil),
+		&parquet.JoinConfig{RightOuter: true},
+	)
+
+	if !config.LeftOuter || !config.RightOuter {
+		t.Errorf("outer join options were lost: left=%t right=%t", config.LeftOuter, config.RightOuter)
+	}
+	if config.LeftProjection != schema {
+		t.Errorf("left projection was lost")
+	}
+}
@@ -0,0 +1,723 @@
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/segmentio/parquet-go/deprecated"
+)
+
+const (
+	// DefaultJSONLinesInferRows is the default number of lines used to infer
+	// the schema of JSON Lines data, see JSONLinesInferRows.
+	DefaultJSONLinesInferRows = 1000
+)
+
+// The JSONLinesConfig type carries configuration options for reading rows from
+// JSON Lines data.
+//
+// JSONLinesConfig implements the JSONLinesOption interface so it can be used
+// directly as argument to the NewJSONLinesReader function, for example:
+//
+//	rows, err := parquet.NewJSONLinesReader(input, &parquet.JSONLinesConfig{
+//		Schema:                schema,
+//		DisallowUnknownFields: true,
+//	})
+//
+type JSONLinesConfig struct {
+	// The schema of the rows, or nil to infer the schema from the lines.
+	Schema *Schema
+	// Number of lines used to infer the schema.
+	InferRows int
+	// When true, fields of JSON objects which have no matching columns in the
+	// schema produce errors instead of being ignored.
+	DisallowUnknownFields bool
+	// When true, missing or null fields of required columns are read as zero
+	// values instead of producing errors.
+	ZeroMissingFields bool
+}
+
+// DefaultJSONLinesConfig returns a new JSONLinesConfig value initialized with
+// the default configuration: the schema is inferred from
+// DefaultJSONLinesInferRows lines, unknown fields are ignored, and missing
+// fields of required columns are errors.
+func DefaultJSONLinesConfig() *JSONLinesConfig {
+	return &JSONLinesConfig{
+		InferRows: DefaultJSONLinesInferRows,
+	}
+}
+
+// Apply applies options to c.
+func (c *JSONLinesConfig) Apply(options ...JSONLinesOption) {
+	for _, opt := range options {
+		opt.ConfigureJSONLines(c)
+	}
+}
+
+// ConfigureJSONLines satisfies the JSONLinesOption interface.
+func (c *JSONLinesConfig) ConfigureJSONLines(config *JSONLinesConfig) {
+	*config = JSONLinesConfig{
+		Schema:                coalesceSchema(c.Schema, config.Schema),
+		InferRows:             coalesceInt(c.InferRows, config.InferRows),
+		DisallowUnknownFields: c.DisallowUnknownFields || config.DisallowUnknownFields,
+		ZeroMissingFields:     c.ZeroMissingFields || config.ZeroMissingFields,
+	}
+}
+
+// JSONLinesSchema constructs a configuration option which sets the schema of
+// the rows read from JSON Lines data.
+//
+// Defaults to nil, which means the schema is inferred from the lines.
+func JSONLinesSchema(schema *Schema) JSONLinesOption {
+	return jsonLinesOption(func(c *JSONLinesConfig) { c.Schema = schema })
+}
+
+// JSONLinesInferRows constructs a configuration option which sets the number
+// of lines used to infer the schema of JSON Lines data.
+//
+// Defaults to DefaultJSONLinesInferRows.
+func JSONLinesInferRows(numRows int) JSONLinesOption {
+	return jsonLinesOption(func(c *JSONLinesConfig) { c.InferRows = numRows })
+}
+
+// JSONLinesDisallowUnknownFields constructs a configuration option which sets
+// whether fields of JSON objects that have no matching columns in the schema
+// produce errors.
+//
+// Defaults to false, which means unknown fields are ignored.
+func JSONLinesDisallowUnknownFields(disallow bool) JSONLinesOption {
+	return jsonLinesOption(func(c *JSONLinesConfig) { c.DisallowUnknownFields = disallow })
+}
+
+// JSONLinesZeroMissingFields constructs a configuration option which sets
+// whether missing or null fields of required columns are read as zero values.
+//
+// Defaults to false, which means missing fields of required columns produce
+// errors. Missing fields of optional columns are always read as null values.
+func JSONLinesZeroMissingFields(zero bool) JSONLinesOption {
+	return jsonLinesOption(func(c *JSONLinesConfig) { c.ZeroMissingFields = zero })
+}
+
+// JSONLinesOption is an interface implemented by types that carry
+// configuration options for reading rows from JSON Lines data.
+type JSONLinesOption interface {
+	ConfigureJSONLines(*JSONLinesConfig)
+}
+
+type jsonLinesOption func(*JSONLinesConfig)
+
+func (f jsonLinesOption) ConfigureJSONLines(c *JSONLinesConfig) { f(c) }
+
+// JSONLinesReader reads parquet rows from JSON Lines data, also known as
+// newline delimited JSON, where each line holds a JSON object. Like CSVReader,
+// it allows converting the data to parquet by copying the rows to a Writer:
+//
+//	rows, err := parquet.NewJSONLinesReader(input)
+//	if err != nil {
+//		...
+//	}
+//	writer := parquet.NewWriter(output, rows.Schema())
+//	if _, err := parquet.CopyRows(writer, rows); err != nil {
+//		...
+//	}
+//
+// JSON objects are mapped to rows using the representation of
+// Schema.MarshalRowJSON: groups are JSON objects, repeated columns and lists
+// are JSON arrays, and maps are JSON objects. Leaf values are decoded according
+// to the logical type of their column: TIMESTAMP values as RFC 3339 strings,
+// DATE values as "2006-01-02" strings, STRING and ENUM values as strings, JSON
+// values as any embedded JSON value, UUID values as strings, and other byte
+// arrays as base64 strings. Numbers may also be represented as JSON strings.
+// DECIMAL and INT96 columns are not supported.
+//
+// Optional columns are null when their fields are missing or null, and
+// repeated columns are empty. By default, missing fields of required columns
+// are errors, and fields with no matching columns are ignored, see
+// JSONLinesZeroMissingFields and JSONLinesDisallowUnknownFields.
+//
+// When no schema is configured, it is inferred from the first lines: objects
+// are inferred as groups and arrays as lists, where all the columns and list
+// elements are optional. Leaf columns have the first type that all their
+// values match: booleans, 64 bits integers, double precision floating point
+// numbers, or for strings, in order, timestamps with nanosecond precision,
+// dates, and strings. Fields holding values of different types, or empty
+// objects, are inferred as columns of JSON logical type. Fields holding only
+// null values are inferred as strings. Fields first seen after the lines used
+// to infer the schema are unknown fields.
+type JSONLinesReader struct {
+	reader *bufio.Reader
+	schema *Schema
+	decode jsonLinesFunc
+	// Objects read to infer the schema, which are returned before reading
+	// more lines from the reader.
+	objects []jsonLinesObject
+	// Number of lines read from the reader, including empty lines.
+	numLines int64
+}
+
+type jsonLinesObject struct {
+	line  int64
+	value map[string]interface{}
+}
+
+// NewJSONLinesReader constructs a reader of the rows held in the JSON Lines
+// data that r reads.
+//
+// When the schema is inferred, the function reads the lines needed to infer
+// it. The function returns an error if reading the lines failed, or if the
+// configured schema has columns of unsupported types.
+func NewJSONLinesReader(r io.Reader, options ...JSONLinesOption) (*JSONLinesReader, error) {
+	config := DefaultJSONLinesConfig()
+	config.Apply(options...)
+
+	j := &JSONLinesReader{reader: bufio.NewReader(r)}
+
+	schema := config.Schema
+	if schema == nil {
+		var err error
+		if schema, err = j.inferSchema(config.InferRows); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	forEachLeafColumnOf(schema, func(leaf leafColumn) {
+		if err == nil && jsonLinesParseFuncOf(leaf.node.Type()) == nil {
+			err = fmt.Errorf("cannot read json lines with schema %s: column %q has unsupported type %s", schema.Name(), leaf.path, leaf.node.Type())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	j.schema = schema
+	_, j.decode = jsonLinesFuncOf(0, schema, config)
+	return j, nil
+}
+
+// Schema returns the schema of the rows read from the JSON Lines data.
+func (j *JSONLinesReader) Schema() *Schema { return j.schema }
+
+// ReadRows reads the next rows from the JSON Lines data. The method returns
+// io.EOF when all the lines were read.
+func (j *JSONLinesReader) ReadRows(rows []Row) (int, error) {
+	for n := range rows {
+		var object jsonLinesObject
+		if len(j.objects) > 0 {
+			object, j.objects = j.objects[0], j.objects[1:]
+		} else {
+			var err error
+			if object, err = j.readObject(); err != nil {
+				return n, err
+			}
+		}
+
+		row, err := j.decode(rows[n][:0], levels{}, object.value)
+		if err != nil {
+			return n, fmt.Errorf("json line %d: %w", object.line, err)
+		}
+		rows[n] = row
+	}
+	return len(rows), nil
+}
+
+// readObject reads the next JSON object, skipping empty lines.
+func (j *JSONLinesReader) readObject() (jsonLinesObject, error) {
+	for {
+		line, err := j.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return jsonLinesObject{}, err
+		}
+		j.numLines++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		d := json.NewDecoder(bytes.NewReader(line))
+		d.UseNumber()
+		var value interface{}
+		if err := d.Decode(&value); err != nil {
+			return jsonLinesObject{}, fmt.Errorf("json line %d: %w", j.numLines, err)
+		}
+		if d.InputOffset() != int64(len(line)) {
+			return jsonLinesObject{}, fmt.Errorf("json line %d: invalid data after the JSON value", j.numLines)
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return jsonLinesObject{}, fmt.Errorf("json line %d: cannot read JSON %s as a row", j.numLines, jsonKindOf(value))
+		}
+		return jsonLinesObject{line: j.numLines, value: object}, nil
+	}
+}
+
+// inferSchema reads up to numRows objects to infer the schema of the rows, the
+// objects are retained to be returned by ReadRows.
+func (j *JSONLinesReader) inferSchema(numRows int) (*Schema, error) {
+	if numRows <= 0 {
+		numRows = 1
+	}
+	root := &jsonLinesField{kind: jsonLinesObjectKind, fields: make(map[string]*jsonLinesField)}
+	for len(j.objects) < numRows {
+		object, err := j.readObject()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("reading json lines: %w", err)
+		}
+		root.observe(object.value)
+		j.objects = append(j.objects, object)
+	}
+
+	group := make(Group, len(root.fields))
+	for name, field := range root.fields {
+		group[name] = Optional(field.node())
+	}
+	return NewSchema("json", group), nil
+}
+
+const (
+	jsonLinesNullKind = iota
+	jsonLinesBooleanKind
+	jsonLinesIntegerKind
+	jsonLinesNumberKind
+	jsonLinesStringKind
+	jsonLinesObjectKind
+	jsonLinesArrayKind
+	jsonLinesMixedKind
+)
+
+// jsonLinesField accumulates the types of the values observed in a field of
+// JSON objects to infer the type of its column.
+type jsonLinesField struct {
+	kind int
+	// Candidate types of string values, see jsonLinesInferredStrings.
+	strings []Node
+	fields  map[string]*jsonLinesField
+	elem    *jsonLinesField
+}
+
+// jsonLinesInferredStrings are the types tried in order when inferring the
+// types of columns holding strings, the last type is used when no other types
+// matched.
+var jsonLinesInferredStrings = []Node{
+	Timestamp(Nanosecond),
+	Date(),
+	String(),
+}
+
+func (f *jsonLinesField) observe(value interface{}) {
+	switch v := value.(type) {
+	case bool:
+		f.setKind(jsonLinesBooleanKind)
+	case json.Number:
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			f.setKind(jsonLinesIntegerKind)
+		} else {
+			f.setKind(jsonLinesNumberKind)
+		}
+	case string:
+		f.setKind(jsonLinesStringKind)
+		for len(f.strings) > 1 {
+			if _, err := csvParseFuncOf(f.strings[0].Type())(v); err == nil {
+				break
+			}
+			f.strings = f.strings[1:]
+		}
+	case map[string]interface{}:
+		f.setKind(jsonLinesObjectKind)
+		if f.fields == nil {
+			f.fields = make(map[string]*jsonLinesField, len(v))
+		}
+		for name, value := range v {
+			field := f.fields[name]
+			if field == nil {
+				field = new(jsonLinesField)
+				f.fields[name] = field
+			}
+			field.observe(value)
+		}
+	case []interface{}:
+		f.setKind(jsonLinesArrayKind)
+		if f.elem == nil {
+			f.elem = new(jsonLinesField)
+		}
+		for _, elem := range v {
+			f.elem.observe(elem)
+		}
+	}
+}
+
+func (f *jsonLinesField) setKind(kind int) {
+	switch {
+	case f.kind == kind:
+	case f.kind == jsonLinesNullKind:
+		f.kind = kind
+		if kind == jsonLinesStringKind {
+			f.strings = jsonLinesInferredStrings
+		}
+	case (f.kind == jsonLinesIntegerKind && kind == jsonLinesNumberKind) || (f.kind == jsonLinesNumberKind && kind == jsonLinesIntegerKind):
+		f.kind = jsonLinesNumberKind
+	default:
+		f.kind = jsonLinesMixedKind
+	}
+}
+
+func (f *jsonLinesField) node() Node {
+	switch f.kind {
+	case jsonLinesBooleanKind:
+		return Leaf(BooleanType)
+	case jsonLinesIntegerKind:
+		return Int(64)
+	case jsonLinesNumberKind:
+		return Leaf(DoubleType)
+	case jsonLinesStringKind:
+		return f.strings[0]
+	case jsonLinesObjectKind:
+		if len(f.fields) == 0 {
+			return JSON()
+		}
+		group := make(Group, len(f.fields))
+		for name, field := range f.fields {
+			group[name] = Optional(field.node())
+		}
+		return group
+	case jsonLinesArrayKind:
+		return List(Optional(f.elem.node()))
+	case jsonLinesMixedKind:
+		return JSON()
+	default:
+		return String()
+	}
+}
+
+type jsonLinesFunc func(Row, levels, interface{}) (Row, error)
+
+func jsonLinesFuncOf(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	switch {
+	case node.Optional():
+		return jsonLinesFuncOfOptional(columnIndex, node, config)
+	case node.Repeated():
+		return jsonLinesFuncOfRepeated(columnIndex, node, config)
+	case isList(node):
+		return jsonLinesFuncOfList(columnIndex, node, config)
+	case isMap(node):
+		return jsonLinesFuncOfMap(columnIndex, node, config)
+	case node.Leaf():
+		return jsonLinesFuncOfLeaf(columnIndex, node, config)
+	default:
+		return jsonLinesFuncOfGroup(columnIndex, node, config)
+	}
+}
+
+//go:noinline
+func jsonLinesFuncOfOptional(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	nextColumnIndex, decode := jsonLinesFuncOf(columnIndex, Required(node), config)
+	return nextColumnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		if value == nil {
+			return appendNullValues(row, levels, columnIndex, nextColumnIndex), nil
+		}
+		levels.definitionLevel++
+		return decode(row, levels, value)
+	}
+}
+
+//go:noinline
+func jsonLinesFuncOfRepeated(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	nextColumnIndex, decode := jsonLinesFuncOf(columnIndex, Required(node), config)
+	return nextColumnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		elems, ok := value.([]interface{})
+		if !ok && value != nil {
+			return row, fmt.Errorf("cannot read JSON %s into repeated column", jsonKindOf(value))
+		}
+		if len(elems) == 0 {
+			return appendNullValues(row, levels, columnIndex, nextColumnIndex), nil
+		}
+
+		levels.repetitionDepth++
+		levels.definitionLevel++
+
+		for i, elem := range elems {
+			var err error
+			if row, err = decode(row, levels, elem); err != nil {
+				return row, fmt.Errorf("[%d] → %w", i, err)
+			}
+			levels.repetitionLevel = levels.repetitionDepth
+		}
+
+		return row, nil
+	}
+}
+
+func jsonLinesFuncOfList(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	return jsonLinesFuncOf(columnIndex, Repeated(listElementOf(node)), config)
+}
+
+//go:noinline
+func jsonLinesFuncOfMap(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	nextColumnIndex, decode := jsonLinesFuncOf(columnIndex, Repeated(mapKeyValueOf(node)), config)
+	return nextColumnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		object, ok := value.(map[string]interface{})
+		if !ok && value != nil {
+			return row, fmt.Errorf("cannot read JSON %s into map column", jsonKindOf(value))
+		}
+		// The entries are ordered by key since JSON objects are unordered.
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]interface{}, len(keys))
+		for i, key := range keys {
+			entries[i] = map[string]interface{}{"key": key, "value": object[key]}
+		}
+		return decode(row, levels, entries)
+	}
+}
+
+//go:noinline
+func jsonLinesFuncOfGroup(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	fields := node.Fields()
+	funcs := make([]jsonLinesFunc, len(fields))
+	names := make(map[string]struct{}, len(fields))
+	for i, field := range fields {
+		columnIndex, funcs[i] = jsonLinesFuncOf(columnIndex, field, config)
+		names[field.Name()] = struct{}{}
+	}
+	return columnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		object, ok := value.(map[string]interface{})
+		if !ok && (value != nil || !config.ZeroMissingFields) {
+			if value == nil {
+				return row, fmt.Errorf("missing value of required column")
+			}
+			return row, fmt.Errorf("cannot read JSON %s into a group", jsonKindOf(value))
+		}
+		if config.DisallowUnknownFields {
+			for name := range object {
+				if _, known := names[name]; !known {
+					return row, fmt.Errorf("unknown field %q", name)
+				}
+			}
+		}
+		for i, f := range funcs {
+			var err error
+			if row, err = f(row, levels, object[fields[i].Name()]); err != nil {
+				return row, fmt.Errorf("%s → %w", fields[i].Name(), err)
+			}
+		}
+		return row, nil
+	}
+}
+
+//go:noinline
+func jsonLinesFuncOfLeaf(columnIndex int16, node Node, config *JSONLinesConfig) (int16, jsonLinesFunc) {
+	typ := node.Type()
+	parse := jsonLinesParseFuncOf(typ)
+	return columnIndex + 1, func(row Row, levels levels, value interface{}) (Row, error) {
+		var v Value
+		switch {
+		case value != nil:
+			var err error
+			if v, err = parse(value); err != nil {
+				return row, err
+			}
+		case config.ZeroMissingFields:
+			v = jsonLinesZeroValueOf(typ)
+		default:
+			return row, fmt.Errorf("missing value of required column")
+		}
+		return append(row, v.Level(int(levels.repetitionLevel), int(levels.definitionLevel), int(columnIndex))), nil
+	}
+}
+
+// jsonLinesParseFuncOf returns a function converting decoded JSON values to
+// values of type t, or nil if the type is not supported.
+func jsonLinesParseFuncOf(t Type) func(interface{}) (Value, error) {
+	lt := t.LogicalType()
+	kind := t.Kind()
+
+	switch {
+	case lt != nil && lt.Decimal != nil:
+		return nil
+
+	case lt != nil && lt.Json != nil:
+		return func(value interface{}) (Value, error) {
+			b, err := json.Marshal(value)
+			return makeValueBytes(kind, b), err
+		}
+
+	case kind == Boolean:
+		return func(value interface{}) (Value, error) {
+			b, ok := value.(bool)
+			if !ok {
+				return Value{}, fmt.Errorf("cannot read JSON %s into column of type %s", jsonKindOf(value), t)
+			}
+			return makeValueBoolean(b), nil
+		}
+
+	case (kind == ByteArray || kind == FixedLenByteArray) && (lt == nil || (lt.UTF8 == nil && lt.Enum == nil && lt.UUID == nil)):
+		return func(value interface{}) (Value, error) {
+			s, ok := value.(string)
+			if !ok {
+				return Value{}, fmt.Errorf("cannot read JSON %s into column of type %s", jsonKindOf(value), t)
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return Value{}, err
+			}
+			if kind == FixedLenByteArray && len(b) != t.Length() {
+				return Value{}, fmt.Errorf("wrong value length for fixed length byte array of %d bytes: %d", t.Length(), len(b))
+			}
+			return makeValueBytes(kind, b), nil
+		}
+	}
+
+	parse := csvParseFuncOf(t)
+	if parse == nil {
+		return nil
+	}
+	// Dates and timestamps are represented as strings, other numbers may be
+	// represented as JSON numbers or strings.
+	numeric := (kind == Int32 || kind == Int64 || kind == Float || kind == Double) &&
+		(lt == nil || (lt.Date == nil && lt.Timestamp == nil))
+
+	return func(value interface{}) (Value, error) {
+		switch v := value.(type) {
+		case string:
+			return parse(v)
+		case json.Number:
+			if numeric {
+				return parse(string(v))
+			}
+		}
+		return Value{}, fmt.Errorf("cannot read JSON %s into column of type %s", jsonKindOf(value), t)
+	}
+}
+
+func jsonLinesZeroValueOf(t Type) Value {
+	switch kind := t.Kind(); kind {
+	case Boolean:
+		return makeValueBoolean(false)
+	case Int32:
+		return makeValueInt32(0)
+	case Int64:
+		return makeValueInt64(0)
+	case Int96:
+		return makeValueInt96(deprecated.Int96{})
+	case Float:
+		return makeValueFloat(0)
+	case Double:
+		return makeValueDouble(0)
+	case FixedLenByteArray:
+		return makeValueBytes(kind, make([]byte, t.Length()))
+	default:
+		return makeValueBytes(kind, []byte{})
+	}
+}
+
+func jsonKindOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// JSONLinesWriter writes parquet rows as JSON Lines data, one JSON object per
+// row, using the representation of Schema.MarshalRowJSON where leaf values are
+// formatted according to the logical type of their column.
+//
+// The rows passed to each call to WriteRows are written to the output with a
+// single call to its Write method, the writer does not buffer rows across
+// calls, so it needs no flushing.
+type JSONLinesWriter struct {
+	output io.Writer
+	schema *Schema
+	buffer []byte
+}
+
+// NewJSONLinesWriter constructs a writer of rows of the given schema to the
+// JSON Lines data written to output.
+func NewJSONLinesWriter(output io.Writer, schema *Schema) *JSONLinesWriter {
+	return &JSONLinesWriter{output: output, schema: schema}
+}
+
+// Schema returns the schema of the rows written to the JSON Lines data.
+func (j *JSONLinesWriter) Schema() *Schema { return j.schema }
+
+// WriteRows writes rows as lines of JSON Lines data. The method returns an
+// error if a row does not match the schema, after writing the rows that
+// preceded it.
+func (j *JSONLinesWriter) WriteRows(rows []Row) (int, error) {
+	b := j.buffer[:0]
+	defer func() { j.buffer = b[:0] }()
+
+	for n, row := range rows {
+		offset := len(b)
+		var err error
+		if b, err = j.schema.appendRowJSON(b, row); err != nil {
+			b = b[:offset]
+			if _, werr := j.output.Write(b); werr != nil {
+				return 0, werr
+			}
+			return n, err
+		}
+		b = append(b, '\n')
+	}
+
+	if _, err := j.output.Write(b); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// WriteJSONLines writes the rows of a row group to output as JSON Lines data,
+// see JSONLinesWriter. The rows of all the row groups of a file can be written
+// with:
+//
+//	numRows, err := parquet.WriteJSONLines(output, parquet.MultiRowGroup(file.RowGroups()...), nil)
+//
+// When the projection is not nil, the rows are converted to its schema before
+// being written, and only the columns of the projection are read. The
+// projection may also be used to set the logical types used to format the
+// values; for example, group columns of files do not retain the LIST and MAP
+// logical types, which a projection can restore to represent them as JSON
+// arrays and objects.
+//
+// The function returns the number of rows written.
+func WriteJSONLines(output io.Writer, rowGroup RowGroup, projection Node) (int64, error) {
+	if projection != nil {
+		conv, err := Convert(projection, rowGroup.Schema())
+		if err != nil {
+			return 0, err
+		}
+		rowGroup = ConvertRowGroup(rowGroup, conv)
+	}
+	rows := rowGroup.Rows()
+	defer rows.Close()
+	return CopyRows(NewJSONLinesWriter(output, rowGroup.Schema()), rows)
+}
+
+var (
+	_ RowReaderWithSchema = (*JSONLinesReader)(nil)
+	_ RowWriterWithSchema = (*JSONLinesWriter)(nil)
+)
@@ -0,0 +1,383 @@
+package parquet_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func convertJSONLines(t *testing.T, input string, options ...parquet.JSONLinesOption) *parquet.File {
+	t.Helper()
+	rows, err := parquet.NewJSONLinesReader(strings.NewReader(input), options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, rows.Schema())
+	if _, err := parquet.CopyRows(w, rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestJSONLinesReaderInferSchema(t *testing.T) {
+	const input = `{"id":1,"level":"info","time":"2023-01-02T03:04:05Z","http":{"status":200,"latency":0.5},"tags":["a","b"],"extra":1}
+
+{"id":2,"level":"warn","http":{"status":404,"latency":1},"tags":[],"extra":"x","day":"2023-01-02"}
+{"id":3,"level":null,"http":null,"seen":true,"unknown":"ignored"}
+`
+	rows, err := parquet.NewJSONLinesReader(strings.NewReader(input), parquet.JSONLinesInferRows(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The types are inferred from the first 2 lines, the empty line is
+	// skipped and the fields of the third line are not part of the schema.
+	want := parquet.NewSchema("json", parquet.Group{
+		"id":    parquet.Optional(parquet.Int(64)),
+		"level": parquet.Optional(parquet.String()),
+		"time":  parquet.Optional(parquet.Timestamp(parquet.Nanosecond)),
+		"http": parquet.Optional(parquet.Group{
+			"status":  parquet.Optional(parquet.Int(64)),
+			"latency": parquet.Optional(parquet.Leaf(parquet.DoubleType)),
+		}),
+		"tags":  parquet.Optional(parquet.List(parquet.Optional(parquet.String()))),
+		"extra": parquet.Optional(parquet.JSON()),
+		"day":   parquet.Optional(parquet.Date()),
+	})
+	if got := rows.Schema(); want.String() != got.String() {
+		t.Fatalf("wrong inferred schema:\nwant: %s\ngot:  %s", want, got)
+	}
+
+	buffer := make([]parquet.Row, 4)
+	n, err := rows.ReadRows(buffer)
+	if err != io.EOF {
+		t.Fatalf("expected io.EOF after reading all the rows, got %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("wrong number of rows: want=3 got=%d", n)
+	}
+
+	for i, want := range []string{
+		`{"day":null,"extra":1,"http":{"latency":0.5,"status":200},"id":1,"level":"info","tags":["a","b"],"time":"2023-01-02T03:04:05Z"}`,
+		`{"day":"2023-01-02","extra":"x","http":{"latency":1,"status":404},"id":2,"level":"warn","tags":[],"time":null}`,
+		`{"day":null,"extra":null,"http":null,"id":3,"level":null,"tags":null,"time":null}`,
+	} {
+		got, err := rows.Schema().MarshalRowJSON(buffer[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("row %d mismatch:\nwant: %s\ngot:  %s", i, want, got)
+		}
+	}
+}
+
+func TestJSONLinesReaderSchema(t *testing.T) {
+	type Address struct {
+		City string  `parquet:"city"`
+		Zip  *string `parquet:"zip,optional"`
+	}
+	type Row struct {
+		ID        uint32            `parquet:"id"`
+		Name      string            `parquet:"name"`
+		UUID      uuid.UUID         `parquet:"uuid"`
+		Time      time.Time         `parquet:"time,timestamp(millisecond)"`
+		Ratio     float32           `parquet:"ratio"`
+		Data      []byte            `parquet:"data"`
+		Addresses []Address         `parquet:"addresses,list"`
+		Labels    map[string]string `parquet:"labels"`
+		Scores    []int64           `parquet:"scores"`
+		Note      *string           `parquet:"note,optional"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+	zip := "94107"
+	note := "note"
+
+	want := []Row{
+		{
+			ID:        1,
+			Name:      "alice",
+			UUID:      uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+			Time:      time.Date(2023, 5, 6, 7, 8, 9, 10e6, time.UTC),
+			Ratio:     0.5,
+			Data:      []byte("hello"),
+			Addresses: []Address{{City: "San Francisco", Zip: &zip}, {City: "Paris"}},
+			Labels:    map[string]string{"env": "prod", "app": "api"},
+			Scores:    []int64{1, 2, 3},
+			Note:      &note,
+		},
+		{
+			ID:        2,
+			Name:      "bob",
+			UUID:      uuid.MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8"),
+			Time:      time.Date(2023, 5, 7, 0, 0, 0, 0, time.UTC),
+			Ratio:     1,
+			Data:      []byte{},
+			Addresses: []Address{},
+			Labels:    map[string]string{},
+			Scores:    []int64{},
+		},
+	}
+
+	// The lines are produced by MarshalRowJSON, reading them must produce the
+	// same rows.
+	input := new(strings.Builder)
+	for i := range want {
+		b, err := schema.MarshalRowJSON(schema.Deconstruct(nil, &want[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		input.Write(b)
+		input.WriteByte('\n')
+	}
+
+	f := convertJSONLines(t, input.String(), parquet.JSONLinesSchema(schema), parquet.JSONLinesDisallowUnknownFields(true))
+	r := parquet.NewReader(f)
+	for i := range want {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		got.Time = got.Time.UTC()
+		if !reflect.DeepEqual(want[i], got) {
+			t.Errorf("row %d mismatch:\nwant: %+v\ngot:  %+v", i, want[i], got)
+		}
+	}
+}
+
+func TestJSONLinesReaderMissingFields(t *testing.T) {
+	type Nested struct {
+		Count int32 `parquet:"count"`
+	}
+	type Row struct {
+		ID     int64    `parquet:"id"`
+		Name   string   `parquet:"name"`
+		Nested Nested   `parquet:"nested"`
+		Tags   []string `parquet:"tags"`
+		Note   *string  `parquet:"note,optional"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+
+	// Numbers may be represented as strings, the missing fields of required
+	// columns are read as zero values.
+	f := convertJSONLines(t, `{"id":"42","name":null}`+"\n", parquet.JSONLinesSchema(schema), parquet.JSONLinesZeroMissingFields(true))
+	got := Row{}
+	if err := parquet.NewReader(f).Read(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Row{ID: 42, Tags: []string{}}); !reflect.DeepEqual(want, got) {
+		t.Errorf("row mismatch:\nwant: %+v\ngot:  %+v", want, got)
+	}
+}
+
+func TestJSONLinesReaderErrors(t *testing.T) {
+	type Row struct {
+		ID   int64   `parquet:"id"`
+		Note *string `parquet:"note,optional"`
+		Day  int32   `parquet:"day,date"`
+	}
+	type Decimal struct {
+		Price int64 `parquet:"price,decimal(2:18)"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+
+	for _, test := range []struct {
+		scenario string
+		input    string
+		options  []parquet.JSONLinesOption
+		message  string
+	}{
+		{
+			scenario: "missing required field",
+			input:    `{"id":1,"day":"2023-01-02"}` + "\n" + `{"note":"a","day":"2023-01-02"}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(schema)},
+			message:  "json line 2: id → missing value of required column",
+		},
+		{
+			scenario: "unknown field",
+			input:    `{"id":1,"day":"2023-01-02","other":true}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(schema), parquet.JSONLinesDisallowUnknownFields(true)},
+			message:  `unknown field "other"`,
+		},
+		{
+			scenario: "unknown field with config",
+			input:    `{"id":1,"day":"2023-01-02","other":true}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(schema), &parquet.JSONLinesConfig{DisallowUnknownFields: true}},
+			message:  `unknown field "other"`,
+		},
+		{
+			scenario: "wrong type",
+			input:    `{"id":true,"day":"2023-01-02"}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(schema)},
+			message:  "id → cannot read JSON boolean",
+		},
+		{
+			scenario: "date as number",
+			input:    `{"id":1,"day":19000}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(schema)},
+			message:  "day → cannot read JSON number",
+		},
+		{
+			scenario: "not an object",
+			input:    `{"id":1}` + "\n\n" + `[1,2]`,
+			message:  "json line 3: cannot read JSON array as a row",
+		},
+		{
+			scenario: "invalid json",
+			input:    `{"id":1} {"id":2}`,
+			message:  "json line 1: invalid data after the JSON value",
+		},
+		{
+			scenario: "unsupported type",
+			input:    `{"price":1.5}`,
+			options:  []parquet.JSONLinesOption{parquet.JSONLinesSchema(parquet.SchemaOf(new(Decimal)))},
+			message:  `column "price" has unsupported type`,
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			rows, err := parquet.NewJSONLinesReader(strings.NewReader(test.input), test.options...)
+			if err == nil {
+				buffer := make([]parquet.Row, 10)
+				_, err = rows.ReadRows(buffer)
+			}
+			if err == nil || !strings.Contains(err.Error(), test.message) {
+				t.Errorf("wrong error: want %q, got %v", test.message, err)
+			}
+		})
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	type Row struct {
+		ID     int64             `parquet:"id"`
+		Time   time.Time         `parquet:"time,timestamp(millisecond)"`
+		Tags   []string          `parquet:"tags,list"`
+		Labels map[string]string `parquet:"labels"`
+		Note   *string           `parquet:"note,optional"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+	note := "note"
+
+	rows := make([]Row, 25)
+	for i := range rows {
+		rows[i] = Row{
+			ID:     int64(i),
+			Time:   time.Date(2023, 5, 6, 7, 8, 9, i*1e6, time.UTC),
+			Tags:   []string{},
+			Labels: map[string]string{},
+		}
+		if i%2 == 0 {
+			rows[i].Tags = []string{"a", "b"}
+			rows[i].Labels = map[string]string{"k": "v"}
+			rows[i].Note = &note
+		}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, schema)
+	for i := range rows {
+		if err := w.Write(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+		if i%10 == 9 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.RowGroups()) != 3 {
+		t.Fatalf("wrong number of row groups: want=3 got=%d", len(f.RowGroups()))
+	}
+	rowGroup := parquet.MultiRowGroup(f.RowGroups()...)
+
+	t.Run("schema", func(t *testing.T) {
+		// The schema of the rows restores the LIST and MAP logical types, the
+		// lines can be read back with the same schema.
+		output := new(bytes.Buffer)
+		numRows, err := parquet.WriteJSONLines(output, rowGroup, schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if numRows != int64(len(rows)) {
+			t.Fatalf("wrong number of rows written: want=%d got=%d", len(rows), numRows)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+		if len(lines) != len(rows) {
+			t.Fatalf("wrong number of lines: want=%d got=%d", len(rows), len(lines))
+		}
+		if want := `{"id":0,"time":"2023-05-06T07:08:09Z","tags":["a","b"],"labels":{"k":"v"},"note":"note"}`; lines[0] != want {
+			t.Errorf("wrong first line:\nwant: %s\ngot:  %s", want, lines[0])
+		}
+
+		r, err := parquet.NewJSONLinesReader(output, parquet.JSONLinesSchema(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		buffer := make([]parquet.Row, len(rows)+1)
+		n, err := r.ReadRows(buffer)
+		if err != io.EOF {
+			t.Fatalf("expected io.EOF after reading all the rows, got %v", err)
+		}
+		if n != len(rows) {
+			t.Fatalf("wrong number of rows read: want=%d got=%d", len(rows), n)
+		}
+		for i := range rows {
+			got := Row{}
+			if err := schema.Reconstruct(&got, buffer[i]); err != nil {
+				t.Fatal(err)
+			}
+			got.Time = got.Time.UTC()
+			if !reflect.DeepEqual(rows[i], got) {
+				t.Errorf("row %d mismatch:\nwant: %+v\ngot:  %+v", i, rows[i], got)
+			}
+		}
+	})
+
+	t.Run("projection", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		_, err := parquet.WriteJSONLines(output, rowGroup, parquet.Group{
+			"id":   parquet.Int(64),
+			"note": parquet.Optional(parquet.String()),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(output.String(), "\n")
+		if want := `{"id":1,"note":null}`; lines[1] != want {
+			t.Errorf("wrong projected line:\nwant: %s\ngot:  %s", want, lines[1])
+		}
+		if want := `{"id":24,"note":"note"}`; lines[24] != want {
+			t.Errorf("wrong projected line:\nwant: %s\ngot:  %s", want, lines[24])
+		}
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		w := parquet.NewJSONLinesWriter(new(bytes.Buffer), parquet.SchemaOf(new(Row)))
+		row := parquet.Row{parquet.ValueOf(int64(1)).Level(0, 0, 0)}
+		if n, err := w.WriteRows([]parquet.Row{row}); err == nil || n != 0 {
+			t.Errorf("writing a row not matching the schema: n=%d err=%v", n, err)
+		}
+	})
+}
@@ -0,0 +1,469 @@
+// Package keytools implements the envelope encryption of the keys of parquet
+// modular encryption, which lets programs manage the keys of encrypted files
+// with a key management system (KMS) instead of passing them as raw bytes.
+//
+// The data keys encrypting files are generated randomly, and stored in the
+// files after being wrapped with master keys held by the KMS. With double
+// wrapping, the data keys are wrapped locally with key encryption keys, which
+// are themselves wrapped by the KMS, reducing the number of calls made to the
+// KMS when writing and reading files.
+//
+// The key material stored in the files uses the JSON format of the key tools
+// of parquet-mr, so files written by Spark and other programs using parquet-mr
+// with the same KMS can be read, and vice versa.
+package keytools
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/segmentio/parquet-go"
+)
+
+const (
+	// KeyMaterialType is the type of key material supported by the package.
+	KeyMaterialType = "PKMT1"
+
+	// DefaultKMSInstance is the default identifier and URL of KMS instances
+	// recorded in the key material of footer keys.
+	DefaultKMSInstance = "DEFAULT"
+
+	// DefaultDataKeyLength is the default length of data keys, which selects
+	// AES-128.
+	DefaultDataKeyLength = 16
+
+	// Length of the key encryption keys and their identifiers.
+	keyEncryptionKeyLength   = 16
+	keyEncryptionKeyIDLength = 16
+)
+
+// KMSClient is an interface implemented by the clients of key management
+// systems, mirroring the KmsClient interface of parquet-mr.
+//
+// The methods may be called concurrently from multiple goroutines.
+type KMSClient interface {
+	// Wraps the key with the master key of the given identifier, returning
+	// the wrapped key encoded as a string.
+	WrapKey(key []byte, masterKeyID string) (string, error)
+
+	// Unwraps a key wrapped by WrapKey with the master key of the given
+	// identifier.
+	UnwrapKey(wrappedKey, masterKeyID string) ([]byte, error)
+}
+
+// KeyMaterial is the representation of the key material stored in the key
+// metadata of encrypted files.
+type KeyMaterial struct {
+	KeyMaterialType    string `json:"keyMaterialType"`
+	InternalStorage    bool   `json:"internalStorage"`
+	IsFooterKey        bool   `json:"isFooterKey"`
+	KMSInstanceID      string `json:"kmsInstanceID,omitempty"`
+	KMSInstanceURL     string `json:"kmsInstanceURL,omitempty"`
+	MasterKeyID        string `json:"masterKeyID"`
+	WrappedDEK         string `json:"wrappedDEK"`
+	DoubleWrapping     bool   `json:"doubleWrapping"`
+	KeyEncryptionKeyID string `json:"keyEncryptionKeyID,omitempty"`
+	WrappedKEK         string `json:"wrappedKEK,omitempty"`
+}
+
+// ParseKeyMaterial parses the key material stored in the key metadata of an
+// encrypted file. Only key material stored internally in the files is
+// supported.
+func ParseKeyMaterial(keyMetadata []byte) (*KeyMaterial, error) {
+	m := new(KeyMaterial)
+	if err := json.Unmarshal(keyMetadata, m); err != nil {
+		return nil, fmt.Errorf("decoding key material: %w", err)
+	}
+	if m.KeyMaterialType != KeyMaterialType {
+		return nil, fmt.Errorf("unsupported key material type: %q", m.KeyMaterialType)
+	}
+	if !m.InternalStorage {
+		return nil, fmt.Errorf("key material stored outside of parquet files is not supported")
+	}
+	return m, nil
+}
+
+// Marshal returns the JSON representation of the key material.
+func (m *KeyMaterial) Marshal() ([]byte, error) { return json.Marshal(m) }
+
+// Config carries the configuration of the key tools.
+type Config struct {
+	// The client of the KMS holding the master keys.
+	KMS KMSClient
+
+	// Identifier and URL of the KMS instance, recorded in the key material
+	// of footer keys. Both default to DefaultKMSInstance.
+	KMSInstanceID  string
+	KMSInstanceURL string
+
+	// When true, data keys are wrapped directly by the KMS instead of being
+	// wrapped with key encryption keys.
+	SingleWrapping bool
+
+	// Length of the data keys in bytes, which must be 16, 24, or 32. Defaults
+	// to DefaultDataKeyLength.
+	DataKeyLength int
+}
+
+// NewEncryptionConfig generates the data keys of a file and wraps them with
+// the master keys of the given identifiers, returning the encryption
+// configuration of a writer producing the file. The column master keys are
+// indexed by the paths of the columns, with the names of the parent groups
+// separated by dots; columns not present in the map are not encrypted, and all
+// the columns are encrypted with the footer key when the map is empty.
+//
+// Each call generates new data keys, programs should use a new configuration
+// for each file that they write. The returned configuration may be modified,
+// for example to select the encryption algorithm.
+func (c *Config) NewEncryptionConfig(footerMasterKeyID string, columnMasterKeyIDs map[string]string) (*parquet.EncryptionConfig, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	w := &keyWrapper{config: c, keks: make(map[string]*keyEncryptionKey)}
+
+	config := &parquet.EncryptionConfig{ColumnKeys: make(map[string]parquet.ColumnEncryptionKey, len(columnMasterKeyIDs))}
+	var err error
+	if config.FooterKey, config.FooterKeyMetadata, err = w.newDataKey(footerMasterKeyID, true); err != nil {
+		return nil, fmt.Errorf("footer key: %w", err)
+	}
+	for path, masterKeyID := range columnMasterKeyIDs {
+		key := parquet.ColumnEncryptionKey{}
+		if key.Key, key.KeyMetadata, err = w.newDataKey(masterKeyID, false); err != nil {
+			return nil, fmt.Errorf("key of column %q: %w", path, err)
+		}
+		config.ColumnKeys[path] = key
+	}
+	return config, nil
+}
+
+// KeyRetriever returns a key retriever which unwraps the data keys from the key
+// material stored in encrypted files, to be used in the decryption
+// configuration of files and readers. The key encryption keys unwrapped by the
+// KMS are cached by the retriever.
+func (c *Config) KeyRetriever() parquet.KeyRetriever {
+	return &keyRetriever{config: c, keks: make(map[string][]byte)}
+}
+
+// RotateMasterKeys writes to output a copy of the encrypted parquet file of the
+// given size read from input, where the data keys are re-wrapped with new
+// master keys. The data keys are unchanged, which lets the pages of the file be
+// copied without being decrypted, see parquet.RewriteKeyMetadata.
+//
+// The masterKeyIDs map associates the identifiers of the master keys wrapping
+// the data keys of the file with the identifiers of the master keys that they
+// are re-wrapped with. Master keys absent from the map keep their identifier,
+// which re-wraps the data keys with the current version of the master keys
+// when the KMS rotates their versions under the same identifier.
+//
+// Files written with an AAD prefix that is not stored in the file can be
+// rotated by calling parquet.RewriteKeyMetadata with the function returned by
+// RotateKeyMaterial.
+func (c *Config) RotateMasterKeys(output io.Writer, input io.ReaderAt, size int64, masterKeyIDs map[string]string) error {
+	rotate, err := c.RotateKeyMaterial(masterKeyIDs)
+	if err != nil {
+		return err
+	}
+	return parquet.RewriteKeyMetadata(output, input, size, &parquet.DecryptionConfig{KeyRetriever: c.KeyRetriever()}, rotate)
+}
+
+// RotateKeyMaterial returns a function rewriting the key material of the data
+// keys of a file, re-wrapping the keys with new master keys as described in
+// RotateMasterKeys. The key encryption keys generated with double wrapping are
+// shared by all the data keys rewritten by the function, so a new function
+// should be used for each file.
+func (c *Config) RotateKeyMaterial(masterKeyIDs map[string]string) (func(keyMetadata []byte) ([]byte, error), error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	r := &keyRetriever{config: c, keks: make(map[string][]byte)}
+	w := &keyWrapper{config: c, keks: make(map[string]*keyEncryptionKey)}
+	return func(keyMetadata []byte) ([]byte, error) {
+		m, err := ParseKeyMaterial(keyMetadata)
+		if err != nil {
+			return nil, err
+		}
+		key, err := r.unwrap(m)
+		if err != nil {
+			return nil, err
+		}
+		masterKeyID, ok := masterKeyIDs[m.MasterKeyID]
+		if !ok {
+			masterKeyID = m.MasterKeyID
+		}
+		if m, err = w.wrap(key, masterKeyID, m.IsFooterKey); err != nil {
+			return nil, err
+		}
+		return m.Marshal()
+	}, nil
+}
+
+func (c *Config) validate() error {
+	if c.KMS == nil {
+		return fmt.Errorf("missing KMS client in the key tools configuration")
+	}
+	switch c.dataKeyLength() {
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("invalid length of data keys: %d", c.DataKeyLength)
+	}
+}
+
+func (c *Config) dataKeyLength() int {
+	if c.DataKeyLength == 0 {
+		return DefaultDataKeyLength
+	}
+	return c.DataKeyLength
+}
+
+func (c *Config) kmsInstance() (id, url string) {
+	id, url = c.KMSInstanceID, c.KMSInstanceURL
+	if id == "" {
+		id = DefaultKMSInstance
+	}
+	if url == "" {
+		url = DefaultKMSInstance
+	}
+	return id, url
+}
+
+// keyWrapper wraps the data keys of a file, the key encryption keys are shared
+// by the data keys wrapped with the same master key.
+type keyWrapper struct {
+	config *Config
+	keks   map[string]*keyEncryptionKey
+}
+
+type keyEncryptionKey struct {
+	id      []byte
+	key     []byte
+	wrapped string
+}
+
+// newDataKey generates a data key and returns it with the key material holding
+// the wrapped key.
+func (w *keyWrapper) newDataKey(masterKeyID string, isFooterKey bool) (key, keyMetadata []byte, err error) {
+	key = make([]byte, w.config.dataKeyLength())
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, nil, err
+	}
+	m, err := w.wrap(key, masterKeyID, isFooterKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyMetadata, err = m.Marshal()
+	return key, keyMetadata, err
+}
+
+// wrap returns the key material of the data key wrapped with the master key.
+func (w *keyWrapper) wrap(key []byte, masterKeyID string, isFooterKey bool) (*KeyMaterial, error) {
+	m := &KeyMaterial{
+		KeyMaterialType: KeyMaterialType,
+		InternalStorage: true,
+		IsFooterKey:     isFooterKey,
+		MasterKeyID:     masterKeyID,
+		DoubleWrapping:  !w.config.SingleWrapping,
+	}
+	if isFooterKey {
+		m.KMSInstanceID, m.KMSInstanceURL = w.config.kmsInstance()
+	}
+
+	if w.config.SingleWrapping {
+		wrapped, err := w.config.KMS.WrapKey(key, masterKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("wrapping data key with master key %q: %w", masterKeyID, err)
+		}
+		m.WrappedDEK = wrapped
+		return m, nil
+	}
+
+	kek := w.keks[masterKeyID]
+	if kek == nil {
+		kek = &keyEncryptionKey{
+			id:  make([]byte, keyEncryptionKeyIDLength),
+			key: make([]byte, keyEncryptionKeyLength),
+		}
+		if _, err := io.ReadFull(rand.Reader, kek.id); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(rand.Reader, kek.key); err != nil {
+			return nil, err
+		}
+		wrapped, err := w.config.KMS.WrapKey(kek.key, masterKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("wrapping key encryption key with master key %q: %w", masterKeyID, err)
+		}
+		kek.wrapped = wrapped
+		w.keks[masterKeyID] = kek
+	}
+
+	wrapped, err := encryptKeyLocally(key, kek.key, kek.id)
+	if err != nil {
+		return nil, err
+	}
+	m.WrappedDEK = wrapped
+	m.KeyEncryptionKeyID = base64.StdEncoding.EncodeToString(kek.id)
+	m.WrappedKEK = kek.wrapped
+	return m, nil
+}
+
+type keyRetriever struct {
+	config *Config
+	mutex  sync.Mutex
+	keks   map[string][]byte
+}
+
+func (r *keyRetriever) RetrieveKey(keyMetadata []byte) ([]byte, error) {
+	if r.config.KMS == nil {
+		return nil, fmt.Errorf("missing KMS client in the key tools configuration")
+	}
+	m, err := ParseKeyMaterial(keyMetadata)
+	if err != nil {
+		return nil, err
+	}
+	return r.unwrap(m)
+}
+
+// unwrap returns the data key of the key material.
+func (r *keyRetriever) unwrap(m *KeyMaterial) ([]byte, error) {
+	if !m.DoubleWrapping {
+		key, err := r.config.KMS.UnwrapKey(m.WrappedDEK, m.MasterKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("unwrapping data key with master key %q: %w", m.MasterKeyID, err)
+		}
+		return key, nil
+	}
+
+	kekID, err := base64.StdEncoding.DecodeString(m.KeyEncryptionKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key encryption key identifier: %w", err)
+	}
+	kek, err := r.keyEncryptionKey(m)
+	if err != nil {
+		return nil, err
+	}
+	return decryptKeyLocally(m.WrappedDEK, kek, kekID)
+}
+
+func (r *keyRetriever) keyEncryptionKey(m *KeyMaterial) ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kek, ok := r.keks[m.KeyEncryptionKeyID]
+	if !ok {
+		var err error
+		if kek, err = r.config.KMS.UnwrapKey(m.WrappedKEK, m.MasterKeyID); err != nil {
+			return nil, fmt.Errorf("unwrapping key encryption key with master key %q: %w", m.MasterKeyID, err)
+		}
+		r.keks[m.KeyEncryptionKeyID] = kek
+	}
+	return kek, nil
+}
+
+// encryptKeyLocally encrypts the key with AES-GCM, returning the nonce followed
+// by the ciphertext and authentication tag, encoded in base64.
+func encryptKeyLocally(key, wrappingKey, aad []byte) (string, error) {
+	gcm, err := newGCM(wrappingKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, key, aad)), nil
+}
+
+// decryptKeyLocally decrypts a key encrypted with encryptKeyLocally.
+func decryptKeyLocally(wrappedKey string, wrappingKey, aad []byte) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding wrapped key: %w", err)
+	}
+	gcm, err := newGCM(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < gcm.NonceSize() {
+		return nil, fmt.Errorf("wrapped key of %d bytes is too short", len(b))
+	}
+	key, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping key: %w", err)
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ParseColumnKeys parses the configuration of column master keys in the format
+// of the parquet.encryption.column.keys property of parquet-mr, where the
+// master key identifiers are followed by the list of column paths that they
+// encrypt, for example "key1:a,b.c;key2:d". The returned map is indexed by the
+// column paths.
+func ParseColumnKeys(s string) (map[string]string, error) {
+	columnKeys := make(map[string]string)
+	for _, keyColumns := range strings.Split(s, ";") {
+		if keyColumns = strings.TrimSpace(keyColumns); keyColumns == "" {
+			continue
+		}
+		i := strings.IndexByte(keyColumns, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("missing column list after master key identifier: %q", keyColumns)
+		}
+		masterKeyID := strings.TrimSpace(keyColumns[:i])
+		if masterKeyID == "" {
+			return nil, fmt.Errorf("missing master key identifier: %q", keyColumns)
+		}
+		for _, path := range strings.Split(keyColumns[i+1:], ",") {
+			if path = strings.TrimSpace(path); path == "" {
+				return nil, fmt.Errorf("empty column path in the columns of master key %q", masterKeyID)
+			}
+			if _, exists := columnKeys[path]; exists {
+				return nil, fmt.Errorf("multiple master keys configured for column %q", path)
+			}
+			columnKeys[path] = masterKeyID
+		}
+	}
+	return columnKeys, nil
+}
+
+// InMemoryKMS is a KMS client wrapping keys with master keys held in memory,
+// which is intended for tests and development. It is compatible with the
+// InMemoryKMS mock of parquet-mr.
+type InMemoryKMS struct {
+	// Master keys indexed by their identifiers.
+	MasterKeys map[string][]byte
+}
+
+// WrapKey wraps the key with AES-GCM using the master key, authenticating the
+// master key identifier.
+func (kms *InMemoryKMS) WrapKey(key []byte, masterKeyID string) (string, error) {
+	masterKey, ok := kms.MasterKeys[masterKeyID]
+	if !ok {
+		return "", fmt.Errorf("unknown master key %q", masterKeyID)
+	}
+	return encryptKeyLocally(key, masterKey, []byte(masterKeyID))
+}
+
+// UnwrapKey unwraps a key wrapped by WrapKey.
+func (kms *InMemoryKMS) UnwrapKey(wrappedKey, masterKeyID string) ([]byte, error) {
+	masterKey, ok := kms.MasterKeys[masterKeyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown master key %q", masterKeyID)
+	}
+	return decryptKeyLocally(wrappedKey, masterKey, []byte(masterKeyID))
+}
@@ -0,0 +1,257 @@
+package keytools_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/keytools"
+)
+
+type row struct {
+	ID   int64  `parquet:"id"`
+	SSN  string `parquet:"ssn"`
+	Name string `parquet:"name"`
+}
+
+func makeRow(i int) row {
+	return row{ID: int64(i), SSN: fmt.Sprintf("123-45-%04d", i), Name: fmt.Sprintf("name-%d", i)}
+}
+
+const numRows = 100
+
+// countingKMS counts the calls made to the KMS.
+type countingKMS struct {
+	keytools.KMSClient
+	wraps   int
+	unwraps int
+}
+
+func (kms *countingKMS) WrapKey(key []byte, masterKeyID string) (string, error) {
+	kms.wraps++
+	return kms.KMSClient.WrapKey(key, masterKeyID)
+}
+
+func (kms *countingKMS) UnwrapKey(wrappedKey, masterKeyID string) ([]byte, error) {
+	kms.unwraps++
+	return kms.KMSClient.UnwrapKey(wrappedKey, masterKeyID)
+}
+
+func newTestKMS() *keytools.InMemoryKMS {
+	return &keytools.InMemoryKMS{
+		MasterKeys: map[string][]byte{
+			"footer-master-key": []byte("0123456789abcdef"),
+			"column-master-key": []byte("fedcba9876543210"),
+		},
+	}
+}
+
+func writeFile(t *testing.T, config *keytools.Config) []byte {
+	t.Helper()
+	encryption, err := config.NewEncryptionConfig("footer-master-key", map[string]string{
+		"id":  "footer-master-key",
+		"ssn": "column-master-key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.Encryption(encryption))
+	for i := 0; i < numRows; i++ {
+		r := makeRow(i)
+		if err := w.Write(&r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func openFile(data []byte, retriever parquet.KeyRetriever) (*parquet.File, error) {
+	return parquet.OpenFile(bytes.NewReader(data), int64(len(data)),
+		parquet.Decryption(&parquet.DecryptionConfig{KeyRetriever: retriever}),
+	)
+}
+
+func readRows(f *parquet.File) error {
+	r := parquet.NewReader(f)
+	for i := 0; i < numRows; i++ {
+		got := row{}
+		if err := r.Read(&got); err != nil {
+			return fmt.Errorf("reading row %d: %w", i, err)
+		}
+		if want := makeRow(i); got != want {
+			return fmt.Errorf("wrong row at index %d: want=%+v got=%+v", i, want, got)
+		}
+	}
+	if err := r.Read(new(row)); err != io.EOF {
+		return fmt.Errorf("expected EOF after the last row, got %v", err)
+	}
+	return nil
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	for _, test := range []struct {
+		scenario       string
+		singleWrapping bool
+		wraps          int
+		unwraps        int
+	}{
+		{scenario: "double wrapping", wraps: 2, unwraps: 2},
+		{scenario: "single wrapping", singleWrapping: true, wraps: 3, unwraps: 3},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			kms := &countingKMS{KMSClient: newTestKMS()}
+			config := &keytools.Config{KMS: kms, SingleWrapping: test.singleWrapping}
+			data := writeFile(t, config)
+			if kms.wraps != test.wraps {
+				t.Errorf("wrong number of keys wrapped by the KMS: want=%d got=%d", test.wraps, kms.wraps)
+			}
+
+			f, err := openFile(data, config.KeyRetriever())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := readRows(f); err != nil {
+				t.Fatal(err)
+			}
+			if kms.unwraps != test.unwraps {
+				t.Errorf("wrong number of keys unwrapped by the KMS: want=%d got=%d", test.unwraps, kms.unwraps)
+			}
+		})
+	}
+}
+
+func TestEnvelopeEncryptionWrongMasterKey(t *testing.T) {
+	config := &keytools.Config{KMS: newTestKMS()}
+	data := writeFile(t, config)
+
+	kms := newTestKMS()
+	kms.MasterKeys["footer-master-key"] = []byte("0000000000000000")
+	_, err := openFile(data, (&keytools.Config{KMS: kms}).KeyRetriever())
+	if err == nil {
+		t.Fatal("file opened with the wrong master key")
+	}
+}
+
+func TestKeyMaterial(t *testing.T) {
+	config := &keytools.Config{KMS: newTestKMS(), KMSInstanceID: "kms-1"}
+	encryption, err := config.NewEncryptionConfig("footer-master-key", map[string]string{"ssn": "column-master-key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encryption.FooterKey) != keytools.DefaultDataKeyLength {
+		t.Errorf("wrong length of the footer key: %d", len(encryption.FooterKey))
+	}
+
+	footer, err := keytools.ParseKeyMaterial(encryption.FooterKeyMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !footer.IsFooterKey || !footer.DoubleWrapping || !footer.InternalStorage {
+		t.Errorf("wrong flags in the footer key material: %+v", footer)
+	}
+	if footer.MasterKeyID != "footer-master-key" || footer.KMSInstanceID != "kms-1" || footer.KMSInstanceURL != keytools.DefaultKMSInstance {
+		t.Errorf("wrong footer key material: %+v", footer)
+	}
+	if footer.KeyEncryptionKeyID == "" || footer.WrappedKEK == "" {
+		t.Errorf("missing key encryption key in the footer key material: %+v", footer)
+	}
+
+	column, err := keytools.ParseKeyMaterial(encryption.ColumnKeys["ssn"].KeyMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if column.IsFooterKey || column.KMSInstanceID != "" || column.KMSInstanceURL != "" {
+		t.Errorf("wrong column key material: %+v", column)
+	}
+	if column.MasterKeyID != "column-master-key" {
+		t.Errorf("wrong master key of the column: %q", column.MasterKeyID)
+	}
+
+	key, err := config.KeyRetriever().RetrieveKey(encryption.ColumnKeys["ssn"].KeyMetadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, encryption.ColumnKeys["ssn"].Key) {
+		t.Error("the retrieved key does not match the column key")
+	}
+
+	for _, keyMetadata := range []string{
+		`not json`,
+		`{"keyMaterialType":"PKMT2","internalStorage":true}`,
+		`{"keyMaterialType":"PKMT1","internalStorage":false}`,
+	} {
+		if _, err := keytools.ParseKeyMaterial([]byte(keyMetadata)); err == nil {
+			t.Errorf("no error parsing invalid key material: %s", keyMetadata)
+		}
+	}
+}
+
+func TestNewEncryptionConfigErrors(t *testing.T) {
+	if _, err := new(keytools.Config).NewEncryptionConfig("footer-master-key", nil); err == nil {
+		t.Error("no error without KMS client")
+	}
+	if _, err := (&keytools.Config{KMS: newTestKMS(), DataKeyLength: 20}).NewEncryptionConfig("footer-master-key", nil); err == nil {
+		t.Error("no error with an invalid length of data keys")
+	}
+	_, err := (&keytools.Config{KMS: newTestKMS()}).NewEncryptionConfig("missing-master-key", nil)
+	if err == nil {
+		t.Error("no error with an unknown master key")
+	}
+}
+
+func TestParseColumnKeys(t *testing.T) {
+	columnKeys, err := keytools.ParseColumnKeys("key1: a, b.c ;key2:d;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"a": "key1", "b.c": "key1", "d": "key2"}
+	if !reflect.DeepEqual(columnKeys, want) {
+		t.Errorf("wrong column keys: want=%v got=%v", want, columnKeys)
+	}
+
+	for _, s := range []string{"key1", ":a", "key1:a,", "key1:a;key2:a"} {
+		if _, err := keytools.ParseColumnKeys(s); err == nil {
+			t.Errorf("no error parsing %q", s)
+		}
+	}
+}
+
+func TestRotateMasterKeys(t *testing.T) {
+	kms := newTestKMS()
+	config := &keytools.Config{KMS: kms}
+	data := writeFile(t, config)
+
+	kms.MasterKeys["column-master-key-v2"] = []byte("0123456789abcdef0123456789abcdef")
+	b := new(bytes.Buffer)
+	if err := config.RotateMasterKeys(b, bytes.NewReader(data), int64(len(data)), map[string]string{
+		"column-master-key": "column-master-key-v2",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	delete(kms.MasterKeys, "column-master-key")
+
+	rotated := b.Bytes()
+	f, err := openFile(rotated, config.KeyRetriever())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := readRows(f); err != nil {
+		t.Fatal(err)
+	}
+
+	// The column key of the original file is wrapped with the master key
+	// which was removed from the KMS.
+	if f, err = openFile(data, config.KeyRetriever()); err != nil {
+		t.Fatal(err)
+	}
+	if err := readRows(f); err == nil {
+		t.Error("no error reading the original file without the old master key")
+	}
+}
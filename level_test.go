@@ -0,0 +1,31 @@
+package parquet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/segmentio/parquet-go/encoding/rle"
+	"github.com/segmentio/parquet-go/format"
+)
+
+func TestDecodeLevelsV1BitPacked(t *testing.T) {
+	// Levels 0 to 7 with a bit width of 3, from the example of the parquet
+	// format specification, followed by the data of the page.
+	data := []byte{0b00000101, 0b00111001, 0b01110111, 'A', 'B', 'C'}
+
+	enc := lookupLevelEncoding(format.BitPacked, 7)
+	levels, rest, err := decodeLevelsV1(enc, 8, nil, data, new(rle.LevelDecoder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0, 1, 2, 3, 4, 5, 6, 7}; !bytes.Equal(levels, want) {
+		t.Errorf("wrong levels: want=%v got=%v", want, levels)
+	}
+	if string(rest) != "ABC" {
+		t.Errorf("wrong data after levels: %q", rest)
+	}
+
+	if _, _, err := decodeLevelsV1(enc, 9, nil, data[:3], new(rle.LevelDecoder)); err == nil {
+		t.Error("expected an error decoding levels from a short input")
+	}
+}
@@ -0,0 +1,169 @@
+package parquet
+
+import "sync"
+
+// MemoryLimiter is used to bound the amount of memory held by decompressed
+// pages across parquet files read concurrently.
+//
+// A single limiter is usually shared by all the files that a program reads,
+// which creates back-pressure on the readers: when the limit is reached, the
+// goroutines reading pages are blocked until enough memory was released by
+// other readers moving past their current page, or being closed.
+//
+// Pages of a column chunk are accounted for from the time they are read until
+// the next page is read or the pages are closed; dictionary pages remain
+// accounted for until the pages are closed. Row readers hold one page of each
+// column that they read, and readers of merged or joined row groups one page of
+// each column of every row group; the memory of these pages is reserved on
+// behalf of the reader as a whole: only readers which do not hold any memory yet
+// are blocked when the limit is reached, while readers that already hold pages
+// are allowed to exceed the limit to load the pages of their other columns and
+// row groups. This guarantees that readers never wait while holding memory,
+// which would otherwise deadlock when the limit is too low to hold one page of
+// every column. For the limit to be effective, it should be large enough to hold
+// at least one page of every column of a file.
+//
+// MemoryLimiter values are safe to use concurrently from multiple goroutines.
+type MemoryLimiter struct {
+	mutex sync.Mutex
+	cond  sync.Cond
+	limit int64
+	inUse int64
+}
+
+// NewMemoryLimiter constructs a MemoryLimiter allowing up to limit bytes of
+// decompressed pages to be held at once.
+//
+// The limit is soft: a page is always admitted when no memory is in use, even
+// if it is larger than the limit, and readers which already hold memory may
+// exceed it, see MemoryLimiter. The memory held may therefore be higher than
+// the limit by up to the size of the pages held by one reader.
+func NewMemoryLimiter(limit int64) *MemoryLimiter {
+	m := &MemoryLimiter{limit: limit}
+	m.cond.L = &m.mutex
+	return m
+}
+
+// Limit returns the maximum number of bytes held by the limiter.
+func (m *MemoryLimiter) Limit() int64 { return m.limit }
+
+// InUse returns the number of bytes currently held by the limiter.
+func (m *MemoryLimiter) InUse() int64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.inUse
+}
+
+// acquire reserves size bytes on behalf of r, blocking until enough memory is
+// available unless r already holds memory.
+func (m *MemoryLimiter) acquire(r *memoryReservation, size int64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for r.size == 0 && m.inUse > 0 && m.inUse+size > m.limit {
+		m.cond.Wait()
+	}
+	m.inUse += size
+	r.size += size
+}
+
+// release returns size bytes reserved on behalf of r to the limiter.
+func (m *MemoryLimiter) release(r *memoryReservation, size int64) {
+	if size > 0 {
+		m.mutex.Lock()
+		m.inUse -= size
+		r.size -= size
+		m.mutex.Unlock()
+		m.cond.Broadcast()
+	}
+}
+
+// memoryReservation tracks the memory held in a limiter by a reader, which may
+// be shared by the pages of multiple column chunks.
+//
+// The limiter is set by the first pages reserving memory, which may be opened
+// concurrently when the rows of merged row groups are read ahead.
+type memoryReservation struct {
+	mutex   sync.Mutex
+	limiter *MemoryLimiter
+	size    int64
+}
+
+// of returns the reservation of r in limiter, which is r itself unless it
+// already reserves memory in another limiter.
+func (r *memoryReservation) of(limiter *MemoryLimiter) *memoryReservation {
+	if r == nil {
+		return &memoryReservation{limiter: limiter}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.limiter == nil {
+		r.limiter = limiter
+	}
+	if r.limiter != limiter {
+		return &memoryReservation{limiter: limiter}
+	}
+	return r
+}
+
+func (r *memoryReservation) acquire(size int64) { r.limiter.acquire(r, size) }
+
+func (r *memoryReservation) release(size int64) { r.limiter.release(r, size) }
+
+// columnChunkWithMemory is implemented by column chunks which can reserve the
+// memory of their pages on behalf of a reader of multiple column chunks.
+type columnChunkWithMemory interface {
+	pagesWithMemory(m *memoryReservation) Pages
+}
+
+// pagesWithMemory returns the pages of the column chunk, reserving their memory
+// on behalf of m when the chunk is read from a file with a memory limiter.
+//
+// Readers of multiple column chunks must open the pages of all of them with the
+// same reservation, or the pages of a column could wait for memory held by the
+// pages of another column of the same reader.
+func pagesWithMemory(chunk ColumnChunk, m *memoryReservation) Pages {
+	if c, ok := chunk.(columnChunkWithMemory); ok {
+		return c.pagesWithMemory(m)
+	}
+	return chunk.Pages()
+}
+
+// rowGroupWithMemory is implemented by row groups which can reserve the memory
+// of the pages of their rows on behalf of a reader of multiple row groups.
+type rowGroupWithMemory interface {
+	rowsWithMemory(m *memoryReservation) Rows
+}
+
+// rowsWithMemory is like pagesWithMemory but returns the rows of a row group,
+// for readers of rows from multiple row groups such as merges and joins.
+func rowsWithMemory(rowGroup RowGroup, m *memoryReservation) Rows {
+	if g, ok := rowGroup.(rowGroupWithMemory); ok {
+		return g.rowsWithMemory(m)
+	}
+	return rowGroup.Rows()
+}
+
+// MemoryLimit creates a configuration option which installs the limiter to
+// bound the memory held by decompressed pages.
+//
+// When passed to a reader constructor which opens the file itself, the limiter
+// is also installed on the file.
+func MemoryLimit(limiter *MemoryLimiter) interface {
+	FileOption
+	ReaderOption
+} {
+	return memoryLimit{limiter}
+}
+
+type memoryLimit struct{ limiter *MemoryLimiter }
+
+func (m memoryLimit) ConfigureFile(config *FileConfig) { config.MemoryLimiter = m.limiter }
+
+func (m memoryLimit) ConfigureReader(config *ReaderConfig) { config.MemoryLimiter = m.limiter }
+
+func coalesceMemoryLimiter(m1, m2 *MemoryLimiter) *MemoryLimiter {
+	if m1 != nil {
+		return m1
+	}
+	return m2
+}
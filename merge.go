@@ -1,15 +1,22 @@
 package parquet
 
 import (
-	"container/heap"
 	"fmt"
 	"io"
 )
 
+const (
+	// mergeBufferSize is the number of rows read at once from each of the row
+	// groups being merged.
+	mergeBufferSize = 64
+)
+
 type mergedRowGroup struct {
 	multiRowGroup
-	sorting   []SortingColumn
-	sortFuncs []columnSortFunc
+	sorting     []SortingColumn
+	sortColumns []mergeSortColumn
+	duplicates  DuplicateRowPolicy
+	concurrency int
 }
 
 func (m *mergedRowGroup) SortingColumns() []SortingColumn {
@@ -17,72 +24,145 @@ func (m *mergedRowGroup) SortingColumns() []SortingColumn {
 }
 
 func (m *mergedRowGroup) Rows() Rows {
+	return m.rowsWithMemory(nil)
+}
+
+func (m *mergedRowGroup) rowsWithMemory(memory *memoryReservation) Rows {
 	// The row group needs to respect a sorting order; the merged row reader
-	// uses a heap to merge rows from the row groups.
-	return &mergedRowGroupRows{rowGroup: m, schema: m.schema}
+	// uses a loser tree to merge rows from the row groups.
+	rows := &mergedRowGroupRows{rowGroup: m, schema: m.schema, memory: memory}
+	if m.duplicates != KeepDuplicateRows && len(m.sortColumns) > 0 {
+		return newDedupRows(rows, m.sortColumns, m.duplicates)
+	}
+	return rows
 }
 
+// mergedRowGroupRows merges the rows of sorted row groups with a loser tree
+// (also known as tournament tree). Each internal node of the tree holds the
+// index of the cursor which lost the comparison at this node, and the root
+// holds the index of the cursor positioned on the smallest row. Advancing
+// the winner only replays the comparisons on the path from its leaf to the
+// root, which takes log2(k) comparisons to merge k row groups, where a binary
+// heap needs up to twice as many.
+//
+// Rows which compare equal are produced in the order of the row groups they
+// come from, which makes the merge stable.
 type mergedRowGroupRows struct {
 	rowGroup *mergedRowGroup
 	schema   *Schema
-	sorting  []columnSortFunc
-	cursors  []rowGroupCursor
-	values1  []Value
-	values2  []Value
-	seek     int64
-	index    int64
-	err      error
+	compare  func(*mergeCursor, *mergeCursor) int
+	// When the first sorting column has integer keys, the keys of cursors are
+	// compared inline before calling the compare function.
+	keyed      bool
+	descending bool
+	cursors    []mergeCursor
+	tree       []int
+	// Index of a cursor which must be advanced before reading more rows, or
+	// -1 if there are none.
+	refill int
+	seek   int64
+	index  int64
+	err    error
+	// Reservation of the memory of the pages of the row groups, or nil if the
+	// reader has its own.
+	memory *memoryReservation
 }
 
 func (r *mergedRowGroupRows) init(m *mergedRowGroup) {
 	if r.schema != nil {
-		numColumns := numLeafColumnsOf(r.schema)
-		cursors := make([]bufferedRowGroupCursor, len(m.rowGroups))
-		buffers := make([][]Value, int(numColumns)*len(m.rowGroups))
+		numColumns := len(m.sortColumns)
+		r.compare = mergeCompareFuncOf(m.sortColumns)
+		if numColumns > 0 && m.sortColumns[0].key != nil {
+			r.keyed, r.descending = true, m.sortColumns[0].descending
+		}
+		r.cursors = make([]mergeCursor, len(m.rowGroups))
+		values := make([]Value, numColumns*len(m.rowGroups))
+		keys := make([][]Value, numColumns*len(m.rowGroups))
+
+		// The semaphore bounds the number of row groups read at the same time
+		// when rows are read ahead of the merge, see MergeConcurrency.
+		var sem chan struct{}
+		if m.concurrency > 1 && len(m.rowGroups) > 1 {
+			sem = make(chan struct{}, m.concurrency)
+		}
 
-		for i, rowGroup := range m.rowGroups {
-			cursors[i].reader = rowGroup.Rows()
-			cursors[i].columns, buffers = buffers[:numColumns:numColumns], buffers[numColumns:]
+		// The pages of all the row groups reserve memory on behalf of the merge,
+		// see rowsWithMemory.
+		memory := r.memory
+		if memory == nil {
+			memory = new(memoryReservation)
 		}
 
-		r.cursors = make([]rowGroupCursor, 0, len(cursors))
-		r.sorting = m.sortFuncs
-
-		for i := range cursors {
-			c := rowGroupCursor(&cursors[i])
-			// TODO: this is a bit of a weak model, it only works with types
-			// declared in this package; we may want to define an API to allow
-			// applications to participate in it.
-			if rd, ok := cursors[i].reader.(*rowGroupRows); ok {
-				rd.init()
-				// TODO: this optimization is disabled for now, there are
-				// remaining blockers:
-				//
-				// * The optimized merge of pages for non-overlapping ranges is
-				//   not yet implemented in the mergedRowGroupRows type.
-				//
-				// * Using pages min/max to determine overlapping ranges does
-				//   not work for repeated columns; sorting by repeated columns
-				//   seems to be an edge case so probably not worth optimizing,
-				//   we still need to find a way to disable the optimization in
-				//   those cases.
-				//
-				//c = optimizedRowGroupCursor{rd}
+		for i, rowGroup := range m.rowGroups {
+			c := &r.cursors[i]
+			rows := rowsWithMemory(rowGroup, memory)
+			if sem != nil {
+				rows = newPrefetchRows(rows, sem)
 			}
+			c.reader = rows
+			c.columns = m.sortColumns
+			c.values, values = values[:numColumns:numColumns], values[numColumns:]
+			c.keys, keys = keys[:numColumns:numColumns], keys[numColumns:]
 
-			if err := c.readNext(); err != nil {
-				if err == io.EOF {
-					continue
-				}
+			if err := c.next(); err != nil {
 				r.err = err
 				return
 			}
+		}
+
+		r.refill = -1
+		r.tree = make([]int, len(r.cursors))
+		if len(r.tree) > 0 {
+			r.tree[0] = r.build(1)
+		}
+	}
+}
+
+// build plays the matches of the subtree rooted at the given node, recording
+// the losers in the internal nodes, and returns the index of the winner.
+func (r *mergedRowGroupRows) build(node int) int {
+	if k := len(r.cursors); node >= k {
+		return node - k
+	}
+	winner := r.build(2 * node)
+	loser := r.build(2*node + 1)
+	if r.less(loser, winner) {
+		winner, loser = loser, winner
+	}
+	r.tree[node] = loser
+	return winner
+}
 
-			r.cursors = append(r.cursors, c)
+// replay updates the tree after the cursor at index i was advanced, playing the
+// matches from its leaf to the root.
+func (r *mergedRowGroupRows) replay(i int) {
+	winner := i
+	for node := (i + len(r.cursors)) / 2; node > 0; node /= 2 {
+		if loser := r.tree[node]; r.less(loser, winner) {
+			r.tree[node], winner = winner, loser
 		}
+	}
+	r.tree[0] = winner
+}
 
-		heap.Init(r)
+// less reports whether the cursor at index i is positioned on a row which must
+// be produced before the row of the cursor at index j. Exhausted cursors are
+// greater than all others.
+func (r *mergedRowGroupRows) less(i, j int) bool {
+	c1, c2 := &r.cursors[i], &r.cursors[j]
+	switch {
+	case c1.done():
+		return false
+	case c2.done():
+		return true
 	}
+	if r.keyed && c1.key != c2.key {
+		return (c1.key < c2.key) != r.descending
+	}
+	if cmp := r.compare(c1, c2); cmp != 0 {
+		return cmp < 0
+	}
+	return i < j
 }
 
 func (r *mergedRowGroupRows) SeekToRow(rowIndex int64) error {
@@ -101,29 +181,41 @@ func (r *mergedRowGroupRows) ReadRows(rows []Row) (n int, err error) {
 	if r.err != nil {
 		return 0, r.err
 	}
+	if i := r.refill; i >= 0 {
+		r.refill = -1
+		if err := r.cursors[i].next(); err != nil {
+			r.err = err
+			return 0, err
+		}
+		r.replay(i)
+	}
 
-	for n < len(rows) && len(r.cursors) > 0 {
-		min := r.cursors[0]
-		r.values1, err = min.readRow(r.values1[:0])
-		if err != nil {
-			return n, err
+	for n < len(rows) && len(r.tree) > 0 {
+		i := r.tree[0]
+		c := &r.cursors[i]
+		if c.done() {
+			break
 		}
 
 		if r.index >= r.seek {
-			rows[n] = append(rows[n][:0], r.values1...)
+			rows[n] = append(rows[n][:0], c.row()...)
 			n++
 		}
 		r.index++
 
-		if err := min.readNext(); err != nil {
-			if err != io.EOF {
-				r.err = err
-				return n, err
-			}
-			heap.Pop(r)
-		} else {
-			heap.Fix(r, 0)
+		// Reading the next batch of rows may overwrite the memory referenced
+		// by the rows of the current batch, so the rows produced so far are
+		// returned first.
+		if c.index+1 == len(c.rows) && n > 0 {
+			r.refill = i
+			return n, nil
+		}
+
+		if err := c.next(); err != nil {
+			r.err = err
+			return n, err
 		}
+		r.replay(i)
 	}
 
 	if n < len(rows) {
@@ -168,117 +260,305 @@ func (r *mergedRowGroupRows) Schema() *Schema {
 	return r.schema
 }
 
-func (r *mergedRowGroupRows) Len() int {
-	return len(r.cursors)
+type columnSortFunc struct {
+	columnIndex int16
+	compare     SortFunc
+	// Computes the key of rows and compares them, when sorting by a key
+	// instead of the values of a column.
+	key        func(Row) Value
+	compareKey func(a, b Value) int
 }
 
-func (r *mergedRowGroupRows) Less(i, j int) bool {
-	cursor1 := r.cursors[i]
-	cursor2 := r.cursors[j]
-
-	for _, sorting := range r.sorting {
-		r.values1 = cursor1.nextRowValuesOf(r.values1[:0], sorting.columnIndex)
-		r.values2 = cursor2.nextRowValuesOf(r.values2[:0], sorting.columnIndex)
-		comp := sorting.compare(r.values1, r.values2)
-		switch {
-		case comp < 0:
-			return true
-		case comp > 0:
-			return false
+// columnSortFuncsOf returns the sorting functions of the leaf columns of schema
+// and of the sorting keys for the given sorting columns, in the same order.
+// Sorting columns which do not name leaf columns of the schema are ignored.
+func columnSortFuncsOf(schema *Schema, sortingColumns []SortingColumn) []columnSortFunc {
+	sortFuncs := make([]columnSortFunc, len(sortingColumns))
+	for i, sortingColumn := range sortingColumns {
+		if key := sortingKeyOf(sortingColumn); key != nil {
+			sortFuncs[i] = columnSortFunc{key: key.key, compareKey: compareSortingKeyFuncOf(sortingColumn)}
 		}
 	}
-
-	return false
+	forEachLeafColumnOf(schema, func(leaf leafColumn) {
+		if sortingIndex := searchSortingColumn(sortingColumns, leaf.path); sortingIndex < len(sortingColumns) && sortFuncs[sortingIndex].key == nil {
+			sortFuncs[sortingIndex] = columnSortFunc{
+				columnIndex: leaf.columnIndex,
+				compare: sortFuncOf(
+					leaf.node.Type(),
+					&SortConfig{
+						MaxRepetitionLevel: int(leaf.maxRepetitionLevel),
+						MaxDefinitionLevel: int(leaf.maxDefinitionLevel),
+						Descending:         sortingColumns[sortingIndex].Descending(),
+						NullsFirst:         sortingColumns[sortingIndex].NullsFirst(),
+					},
+				),
+			}
+		}
+	})
+	i := 0
+	for _, f := range sortFuncs {
+		if f.compare != nil || f.key != nil {
+			sortFuncs[i] = f
+			i++
+		}
+	}
+	return sortFuncs[:i]
 }
 
-func (r *mergedRowGroupRows) Swap(i, j int) {
-	r.cursors[i], r.cursors[j] = r.cursors[j], r.cursors[i]
+// mergeSortColumn is a sorting column compiled for the comparisons of rows
+// performed when merging row groups.
+type mergeSortColumn struct {
+	columnIndex int16
+	// Compares the values of non-repeated columns, which have exactly one value
+	// in each row; nil for repeated columns, which are compared with compare.
+	compareValue func(a, b Value) int
+	compare      SortFunc
+	// Returns keys ordered like the values of required integer columns, which
+	// can be compared without calling compareValue; nil for other columns.
+	key        func(Value) int64
+	descending bool
+	// Computes the value compared by compareValue from the row when sorting
+	// by a key instead of the values of a column, see AscendingKey.
+	rowKey func(Row) Value
 }
 
-func (r *mergedRowGroupRows) Push(interface{}) {
-	panic("BUG: unreachable")
+// mergeSortColumnsOf is like columnSortFuncsOf but returns sorting columns
+// compiled for merging row groups.
+func mergeSortColumnsOf(schema *Schema, sortingColumns []SortingColumn) []mergeSortColumn {
+	columns := make([]mergeSortColumn, len(sortingColumns))
+	for i, sortingColumn := range sortingColumns {
+		if key := sortingKeyOf(sortingColumn); key != nil {
+			columns[i] = mergeSortColumn{
+				compareValue: compareSortingKeyFuncOf(sortingColumn),
+				descending:   sortingColumn.Descending(),
+				rowKey:       key.key,
+			}
+		}
+	}
+	forEachLeafColumnOf(schema, func(leaf leafColumn) {
+		if sortingIndex := searchSortingColumn(sortingColumns, leaf.path); sortingIndex < len(sortingColumns) && columns[sortingIndex].rowKey == nil {
+			config := &SortConfig{
+				MaxRepetitionLevel: int(leaf.maxRepetitionLevel),
+				MaxDefinitionLevel: int(leaf.maxDefinitionLevel),
+				Descending:         sortingColumns[sortingIndex].Descending(),
+				NullsFirst:         sortingColumns[sortingIndex].NullsFirst(),
+			}
+			column := &columns[sortingIndex]
+			column.columnIndex = leaf.columnIndex
+			column.compare = sortFuncOf(leaf.node.Type(), config)
+			column.descending = config.Descending
+			if leaf.maxRepetitionLevel == 0 {
+				column.compareValue = compareValueFuncOf(leaf.node.Type(), config)
+			}
+			if leaf.maxRepetitionLevel == 0 && leaf.maxDefinitionLevel == 0 {
+				column.key = mergeKeyFuncOf(leaf.node.Type())
+			}
+		}
+	})
+	i := 0
+	for _, c := range columns {
+		if c.compare != nil || c.rowKey != nil {
+			columns[i] = c
+			i++
+		}
+	}
+	return columns[:i]
 }
 
-func (r *mergedRowGroupRows) Pop() interface{} {
-	n := len(r.cursors) - 1
-	c := r.cursors[n]
-	r.cursors = r.cursors[:n]
-	return c
-}
+// compareValueFuncOf is like sortFuncOf but returns a function comparing single
+// values of a non-repeated column, which does not need to be given slices.
+func compareValueFuncOf(t Type, config *SortConfig) func(a, b Value) int {
+	compare := t.Compare
 
-type rowGroupCursor interface {
-	close() error
-	readRow(Row) (Row, error)
-	readNext() error
-	nextRowValuesOf([]Value, int16) []Value
-}
+	if config.Descending {
+		ascending := compare
+		compare = func(a, b Value) int { return -ascending(a, b) }
+	}
 
-type columnSortFunc struct {
-	columnIndex int16
-	compare     SortFunc
+	if makeDefinitionLevel(config.MaxDefinitionLevel) > 0 {
+		nulls := +1
+		if config.NullsFirst {
+			nulls = -1
+		}
+		notNull := compare
+		compare = func(a, b Value) int {
+			switch {
+			case a.IsNull():
+				if b.IsNull() {
+					return 0
+				}
+				return nulls
+			case b.IsNull():
+				return -nulls
+			default:
+				return notNull(a, b)
+			}
+		}
+	}
+
+	return compare
 }
 
-type bufferedRowGroupCursor struct {
-	reader  Rows
-	rowbuf  [1]Row
-	columns [][]Value
+// mergeKeyFuncOf returns a function converting values of type t to int64 keys
+// with the same ordering as the values, or nil if t is not an integer type.
+func mergeKeyFuncOf(t Type) func(Value) int64 {
+	switch t := t.(type) {
+	case int32Type, *dateType:
+		return func(v Value) int64 { return int64(v.Int32()) }
+	case int64Type, *timestampType:
+		return Value.Int64
+	case *intType:
+		switch {
+		case t.IsSigned && t.BitWidth == 64:
+			return Value.Int64
+		case t.IsSigned:
+			return func(v Value) int64 { return int64(v.Int32()) }
+		case t.BitWidth == 64:
+			// Flipping the sign bit maps the unsigned order to the signed order.
+			return func(v Value) int64 { return int64(v.Uint64() ^ (1 << 63)) }
+		default:
+			return func(v Value) int64 { return int64(v.Uint32()) }
+		}
+	}
+	return nil
 }
 
-func (cur *bufferedRowGroupCursor) close() error {
-	return cur.reader.Close()
+// mergeCompareFuncOf compiles the sorting columns into a function comparing the
+// rows that cursors are positioned on. Sorting by a single non-repeated column
+// is the common case, it is specialized to compare the values directly.
+func mergeCompareFuncOf(columns []mergeSortColumn) func(*mergeCursor, *mergeCursor) int {
+	switch {
+	case len(columns) == 0:
+		return func(*mergeCursor, *mergeCursor) int { return 0 }
+	case len(columns) == 1 && columns[0].compareValue != nil:
+		compare := columns[0].compareValue
+		return func(c1, c2 *mergeCursor) int {
+			return compare(c1.values[0], c2.values[0])
+		}
+	default:
+		return func(c1, c2 *mergeCursor) int {
+			for i := range columns {
+				var cmp int
+				if compare := columns[i].compareValue; compare != nil {
+					cmp = compare(c1.values[i], c2.values[i])
+				} else {
+					cmp = columns[i].compare(c1.keys[i], c2.keys[i])
+				}
+				if cmp != 0 {
+					return cmp
+				}
+			}
+			return 0
+		}
+	}
 }
 
-func (cur *bufferedRowGroupCursor) readRow(row Row) (Row, error) {
-	return append(row, cur.rowbuf[0]...), nil
+// mergeCursor reads rows from one of the row groups being merged. Rows are read
+// in batches of mergeBufferSize rows, and the values of the sorting columns of
+// the current row are extracted once when the cursor is advanced, so they are
+// not looked up again on each comparison.
+type mergeCursor struct {
+	reader  RowReader
+	columns []mergeSortColumn
+	rows    []Row
+	index   int
+	// Values of the sorting columns of the current row, in the same order as
+	// the sorting columns. Values of non-repeated columns are copied to values
+	// so comparing them does not need to load the row, values of repeated
+	// columns are referenced by keys.
+	values []Value
+	keys   [][]Value
+	// Key of the first sorting column, if it has a key function.
+	key int64
+	// Error returned by the last read from the row group, reported after the
+	// rows of the batch it was returned with have been consumed.
+	err error
+	eof bool
 }
 
-func (cur *bufferedRowGroupCursor) readNext() error {
-	_, err := cur.reader.ReadRows(cur.rowbuf[:])
-	if err != nil {
-		return err
-	}
-	for i, c := range cur.columns {
-		cur.columns[i] = c[:0]
+func (c *mergeCursor) done() bool { return c.eof }
+
+func (c *mergeCursor) row() Row { return c.rows[c.index] }
+
+// next advances the cursor to the next row, reading a new batch of rows from
+// the row group when the current batch was consumed. Reaching the end of the
+// row group is not an error, the cursor is then marked done.
+func (c *mergeCursor) next() error {
+	if c.eof {
+		return nil
 	}
-	for _, v := range cur.rowbuf[0] {
-		columnIndex := v.Column()
-		cur.columns[columnIndex] = append(cur.columns[columnIndex], v)
+	if c.index++; c.index >= len(c.rows) {
+		if err := c.readRows(); err != nil {
+			return err
+		}
+		if c.eof {
+			return nil
+		}
 	}
+	c.load()
 	return nil
 }
 
-func (cur *bufferedRowGroupCursor) nextRowValuesOf(values []Value, columnIndex int16) []Value {
-	return append(values, cur.columns[columnIndex]...)
+// load extracts the values of the sorting columns of the current row.
+func (c *mergeCursor) load() {
+	row := c.rows[c.index]
+	for i := range c.columns {
+		if rowKey := c.columns[i].rowKey; rowKey != nil {
+			c.values[i] = rowKey(row)
+			continue
+		}
+		values := row.valuesOf(c.columns[i].columnIndex)
+		if c.columns[i].compareValue == nil {
+			c.keys[i] = values
+		} else if len(values) > 0 {
+			c.values[i] = values[0]
+		} else {
+			c.values[i] = Value{}
+		}
+	}
+	if len(c.columns) > 0 && c.columns[0].key != nil {
+		c.key = c.columns[0].key(c.values[0])
+	}
 }
 
-/*
-type optimizedRowGroupCursor struct{ *rowGroupRows }
-
-func (cur optimizedRowGroupCursor) readRow(row Row) (Row, error) { return cur.ReadRow(row) }
-
-func (cur optimizedRowGroupCursor) readNext() error {
-	for i := range cur.columns {
-		c := &cur.columns[i]
-		if c.buffered() == 0 {
-			if err := c.readPage(); err != nil {
-				return err
-			}
+func (c *mergeCursor) readRows() error {
+	switch c.err {
+	case nil:
+	case io.EOF:
+		c.rows, c.eof = c.rows[:0], true
+		return nil
+	default:
+		return c.err
+	}
+	if c.rows == nil {
+		c.rows = make([]Row, mergeBufferSize)
+	}
+	c.rows = c.rows[:cap(c.rows)]
+	c.index = 0
+
+	for {
+		n, err := c.reader.ReadRows(c.rows)
+		if n > 0 {
+			c.rows, c.err = c.rows[:n], err
+			return nil
+		}
+		switch err {
+		case nil:
+		case io.EOF:
+			c.rows, c.eof = c.rows[:0], true
+			return nil
+		default:
+			return err
 		}
 	}
-	return nil
 }
 
-func (cur optimizedRowGroupCursor) nextRowValuesOf(values []Value, columnIndex int16) []Value {
-	col := &cur.columns[columnIndex]
-	err := col.readValues()
-	if err != nil {
-		values = append(values, Value{})
-	} else {
-		values = append(values, col.buffer[col.offset])
+func (c *mergeCursor) close() error {
+	if closer, ok := c.reader.(io.Closer); ok {
+		return closer.Close()
 	}
-	return values
+	return nil
 }
-*/
 
 var (
 	_ RowReaderWithSchema = (*mergedRowGroupRows)(nil)
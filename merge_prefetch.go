@@ -0,0 +1,168 @@
+package parquet
+
+import (
+	"io"
+	"sync"
+)
+
+// MergeConcurrency creates a configuration option which sets the number of row
+// groups that MergeRowGroups reads concurrently when merging sorted row groups.
+//
+// By default, the rows of each row group are read when the merge needs them,
+// which makes the throughput of merges bound by the latency of fetching pages
+// from the underlying storage (e.g. object stores). When the concurrency is
+// greater than one, the rows of each row group are read ahead of the merge in
+// a separate goroutine, with at most n row groups being read at the same time.
+// Rows read ahead of the merge are copied, which adds a small overhead to
+// merges of row groups held in memory.
+//
+// The option has no effect on row groups which have no sorting columns, since
+// their rows are concatenated.
+func MergeConcurrency(n int) RowGroupOption {
+	return rowGroupOption(func(config *RowGroupConfig) { config.MergeConcurrency = n })
+}
+
+// prefetchBatch is a batch of rows read ahead of a prefetchRows consumer. The
+// rows and the byte arrays they reference are owned by the batch.
+type prefetchBatch struct {
+	rows  []Row
+	arena []byte
+	err   error
+}
+
+// prefetchRows reads the rows of a base reader in a goroutine, one batch ahead
+// of the program consuming them. The semaphore bounds the number of readers
+// reading from their base at the same time, it is shared by the readers of a
+// merge.
+//
+// Two batches are used in turn: the goroutine fills one while the rows of the
+// other are consumed. A batch is given back to the goroutine when the next
+// batch is received, so the rows returned by ReadRows remain valid until the
+// next call.
+type prefetchRows struct {
+	base    Rows
+	sem     chan struct{}
+	free    chan *prefetchBatch
+	filled  chan *prefetchBatch
+	done    chan struct{}
+	wg      sync.WaitGroup
+	current *prefetchBatch
+	offset  int
+	err     error
+	closed  bool
+}
+
+func newPrefetchRows(base Rows, sem chan struct{}) *prefetchRows {
+	r := &prefetchRows{base: base, sem: sem}
+	r.start()
+	return r
+}
+
+func (r *prefetchRows) start() {
+	r.free = make(chan *prefetchBatch, 2)
+	r.filled = make(chan *prefetchBatch, 2)
+	r.done = make(chan struct{})
+	r.current, r.offset, r.err = nil, 0, nil
+
+	for i := 0; i < cap(r.free); i++ {
+		r.free <- &prefetchBatch{rows: make([]Row, mergeBufferSize)}
+	}
+
+	r.wg.Add(1)
+	go r.run()
+}
+
+func (r *prefetchRows) stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *prefetchRows) run() {
+	defer r.wg.Done()
+	buffer := make([]Row, mergeBufferSize)
+
+	for {
+		var b *prefetchBatch
+		select {
+		case b = <-r.free:
+		case <-r.done:
+			return
+		}
+
+		select {
+		case r.sem <- struct{}{}:
+		case <-r.done:
+			return
+		}
+		n, err := r.base.ReadRows(buffer)
+		<-r.sem
+
+		// The rows are copied to the batch before reading more rows from the
+		// base, since they may reference memory which is reused by the next
+		// read.
+		b.rows, b.arena, b.err = b.rows[:n], b.arena[:0], err
+		for i, row := range buffer[:n] {
+			b.rows[i], b.arena = CloneValues(b.rows[i][:0], b.arena, row)
+		}
+		r.filled <- b
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *prefetchRows) ReadRows(rows []Row) (int, error) {
+	if r.closed {
+		return 0, io.EOF
+	}
+	for r.current == nil || r.offset == len(r.current.rows) {
+		if r.current != nil {
+			if r.current.err != nil {
+				r.err = r.current.err
+			}
+			r.current.rows = r.current.rows[:cap(r.current.rows)]
+			r.free <- r.current
+			r.current = nil
+		}
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.current, r.offset = <-r.filled, 0
+	}
+
+	n := 0
+	for n < len(rows) && r.offset < len(r.current.rows) {
+		rows[n] = append(rows[n][:0], r.current.rows[r.offset]...)
+		r.offset++
+		n++
+	}
+	return n, nil
+}
+
+func (r *prefetchRows) SeekToRow(rowIndex int64) error {
+	if r.closed {
+		return io.ErrClosedPipe
+	}
+	r.stop()
+	err := r.base.SeekToRow(rowIndex)
+	r.start()
+	return err
+}
+
+func (r *prefetchRows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.stop()
+	return r.base.Close()
+}
+
+func (r *prefetchRows) Schema() *Schema {
+	return r.base.Schema()
+}
+
+var (
+	_ Rows = (*prefetchRows)(nil)
+)
@@ -7,6 +7,7 @@ import (
 	"io"
 	"math/rand"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/segmentio/parquet-go"
@@ -17,6 +18,286 @@ const (
 	rowsPerGroup = benchmarkNumRows
 )
 
+func TestMergeManyRowGroups(t *testing.T) {
+	type Row struct {
+		Key   int64   `parquet:"key"`
+		Name  string  `parquet:"name"`
+		Value *uint32 `parquet:"value,optional"`
+		Group int     `parquet:"group"`
+		Index int     `parquet:"index"`
+	}
+
+	schema := parquet.SchemaOf(Row{})
+	options := []parquet.RowGroupOption{
+		schema,
+		parquet.SortingColumns(
+			parquet.Ascending("key"),
+			parquet.Descending("name"),
+			parquet.NullsFirst(parquet.Ascending("value")),
+		),
+	}
+	less := func(a, b Row) bool {
+		switch {
+		case a.Key != b.Key:
+			return a.Key < b.Key
+		case a.Name != b.Name:
+			return a.Name > b.Name
+		case a.Value == nil || b.Value == nil:
+			return a.Value == nil && b.Value != nil
+		default:
+			return *a.Value < *b.Value
+		}
+	}
+
+	// The number of row groups is not a power of two to exercise incomplete
+	// levels of the loser tree, and keys are taken in a small range so many
+	// rows compare equal.
+	prng := rand.New(rand.NewSource(0))
+	rowGroups := make([]parquet.RowGroup, 37)
+	want := []Row{}
+
+	for i := range rowGroups {
+		rows := make([]Row, prng.Intn(200))
+		for j := range rows {
+			rows[j] = Row{
+				Key:   prng.Int63n(20),
+				Name:  fmt.Sprint(prng.Intn(3)),
+				Group: i,
+			}
+			if prng.Intn(2) == 0 {
+				v := uint32(prng.Intn(3))
+				rows[j].Value = &v
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+		for j := range rows {
+			rows[j].Index = j
+		}
+		rowGroups[i] = sortedRowGroup(options, makeRows(rows)...)
+		want = append(want, rows...)
+	}
+
+	// Rows which compare equal are expected in the order of their row groups.
+	sort.SliceStable(want, func(i, j int) bool { return less(want[i], want[j]) })
+
+	merged, err := parquet.MergeRowGroups(rowGroups, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := merged.NumRows(); n != int64(len(want)) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(want), n)
+	}
+
+	rows := merged.Rows()
+	defer rows.Close()
+
+	buf := make([]parquet.Row, 10)
+	got := []Row{}
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			var r Row
+			if err := schema.Reconstruct(&r, row); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of rows read: want=%d got=%d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Group != want[i].Group || got[i].Index != want[i].Index {
+			t.Fatalf("wrong row at index %d: want=%+v got=%+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestMergeConcurrency(t *testing.T) {
+	type Row struct {
+		Key   int64  `parquet:"key"`
+		Name  string `parquet:"name"`
+		Group int    `parquet:"group"`
+	}
+
+	schema := parquet.SchemaOf(Row{})
+	sorting := parquet.SortingColumns(parquet.Ascending("key"))
+	options := []parquet.RowGroupOption{schema, sorting}
+
+	prng := rand.New(rand.NewSource(0))
+	rowGroups := make([]parquet.RowGroup, 10)
+	want := []Row{}
+	for i := range rowGroups {
+		rows := make([]Row, prng.Intn(1000))
+		for j := range rows {
+			rows[j] = Row{Key: prng.Int63n(1000), Name: fmt.Sprintf("name-%d", j), Group: i}
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+		rowGroups[i] = sortedRowGroup(options, makeRows(rows)...)
+		want = append(want, rows...)
+	}
+	// The merge is stable, rows with equal keys are ordered by row group.
+	sort.SliceStable(want, func(i, j int) bool { return want[i].Key < want[j].Key })
+
+	for _, concurrency := range []int{0, 1, 2, 4, 20} {
+		t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+			merged, err := parquet.MergeRowGroups(rowGroups, schema, sorting, parquet.MergeConcurrency(concurrency))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows := merged.Rows()
+			defer rows.Close()
+
+			buf := make([]parquet.Row, 13)
+			got := []Row{}
+			for {
+				n, err := rows.ReadRows(buf)
+				for _, row := range buf[:n] {
+					var r Row
+					if err := schema.Reconstruct(&r, row); err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, r)
+				}
+				if err != nil {
+					if err != io.EOF {
+						t.Fatal(err)
+					}
+					break
+				}
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("wrong number of rows read: want=%d got=%d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("wrong row at index %d: want=%+v got=%+v", i, want[i], got[i])
+				}
+			}
+
+			// Closing the rows before reading them all must stop reading ahead.
+			partial := merged.Rows()
+			if _, err := partial.ReadRows(buf); err != nil {
+				t.Fatal(err)
+			}
+			if err := partial.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if _, err := parquet.MergeRowGroups(rowGroups, schema, sorting, parquet.MergeConcurrency(-1)); err == nil {
+		t.Error("no error configuring a negative merge concurrency")
+	}
+}
+
+func TestMergeDropDuplicateRows(t *testing.T) {
+	type Row struct {
+		Key   int64  `parquet:"key"`
+		Name  string `parquet:"name"`
+		Group int    `parquet:"group"`
+		Index int    `parquet:"index"`
+	}
+
+	schema := parquet.SchemaOf(Row{})
+	less := func(a, b Row) bool {
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Name < b.Name
+	}
+
+	for _, policy := range []parquet.DuplicateRowPolicy{parquet.KeepFirstRow, parquet.KeepLastRow} {
+		t.Run(policy.String(), func(t *testing.T) {
+			options := []parquet.RowGroupOption{
+				schema,
+				parquet.SortingColumns(
+					parquet.Ascending("key"),
+					parquet.Ascending("name"),
+				),
+				parquet.DropDuplicateRows(policy),
+			}
+
+			prng := rand.New(rand.NewSource(0))
+			rowGroups := make([]parquet.RowGroup, 11)
+			all := []Row{}
+
+			for i := range rowGroups {
+				rows := make([]Row, prng.Intn(300))
+				for j := range rows {
+					rows[j] = Row{
+						Key:   prng.Int63n(50),
+						Name:  fmt.Sprintf("name-%d", prng.Intn(4)),
+						Group: i,
+					}
+				}
+				sort.SliceStable(rows, func(i, j int) bool { return less(rows[i], rows[j]) })
+				for j := range rows {
+					rows[j].Index = j
+				}
+				rowGroups[i] = sortedRowGroup(options, makeRows(rows)...)
+				all = append(all, rows...)
+			}
+
+			sort.SliceStable(all, func(i, j int) bool { return less(all[i], all[j]) })
+			want := []Row{}
+			for _, row := range all {
+				switch n := len(want); {
+				case n == 0 || less(want[n-1], row):
+					want = append(want, row)
+				case policy == parquet.KeepLastRow:
+					want[n-1] = row
+				}
+			}
+
+			merged, err := parquet.MergeRowGroups(rowGroups, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rows := merged.Rows()
+			defer rows.Close()
+
+			buf := make([]parquet.Row, 7)
+			got := []Row{}
+			for {
+				n, err := rows.ReadRows(buf)
+				for _, row := range buf[:n] {
+					var r Row
+					if err := schema.Reconstruct(&r, row); err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, r)
+				}
+				if err != nil {
+					if err != io.EOF {
+						t.Fatal(err)
+					}
+					break
+				}
+			}
+
+			if len(got) != len(want) {
+				t.Fatalf("wrong number of rows read: want=%d got=%d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("wrong row at index %d: want=%+v got=%+v", i, want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
 func BenchmarkMergeRowGroups(b *testing.B) {
 	for _, test := range readerTests {
 		b.Run(test.scenario, func(b *testing.B) {
@@ -135,3 +416,193 @@ func BenchmarkMergeFiles(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkMergeManyRowGroups(b *testing.B) {
+	const numRowGroups = 64
+	const rowsPerGroup = 1000
+
+	for _, test := range readerTests {
+		b.Run(test.scenario, func(b *testing.B) {
+			schema := parquet.SchemaOf(test.model)
+
+			options := []parquet.RowGroupOption{
+				parquet.SortingColumns(
+					parquet.Ascending(schema.Columns()[0]...),
+				),
+			}
+
+			prng := rand.New(rand.NewSource(0))
+			rowGroups := make([]parquet.RowGroup, numRowGroups)
+
+			for i := range rowGroups {
+				rowGroups[i] = sortedRowGroup(options, randomRowsOf(prng, rowsPerGroup, test.model)...)
+			}
+
+			mergedRowGroup, err := parquet.MergeRowGroups(rowGroups, options...)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			rows := mergedRowGroup.Rows()
+			rbuf := make([]parquet.Row, benchmarkRowsPerStep)
+			defer func() { rows.Close() }()
+
+			benchmarkRowsPerSecond(b, func() int {
+				n, err := rows.ReadRows(rbuf)
+				if err != nil {
+					if !errors.Is(err, io.EOF) {
+						b.Fatal(err)
+					}
+					rows.Close()
+					rows = mergedRowGroup.Rows()
+				}
+				return n
+			})
+		})
+	}
+}
+
+func TestMergeRowGroupsMemoryLimit(t *testing.T) {
+	values := makeMemoryLimitRows(2000)
+	limiter := parquet.NewMemoryLimiter(1)
+	sorting := parquet.SortingColumns(parquet.Ascending("id"))
+
+	rowGroups := []parquet.RowGroup{}
+	for _, part := range [][]memoryLimitRow{values[:1000], values[1000:]} {
+		buffer := new(bytes.Buffer)
+		if err := writeParquetFile(buffer, makeRows(part), sorting, parquet.PageBufferSize(1024)); err != nil {
+			t.Fatal(err)
+		}
+		f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()), parquet.MemoryLimit(limiter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rowGroups = append(rowGroups, f.RowGroups()...)
+	}
+
+	for _, concurrency := range []int{1, 2} {
+		t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+			// The merge reads the row groups together, the pages of a row group
+			// must not wait for memory held by the pages of the other.
+			readWithMemoryLimit(t, limiter, func() error {
+				merged, err := parquet.MergeRowGroups(rowGroups, sorting, parquet.MergeConcurrency(concurrency))
+				if err != nil {
+					return err
+				}
+				rows := merged.Rows()
+				defer rows.Close()
+				buf := make([]parquet.Row, 100)
+				for n := 0; ; {
+					m, err := rows.ReadRows(buf)
+					n += m
+					if err != nil {
+						if err == io.EOF && n != len(values) {
+							err = fmt.Errorf("wrong number of rows read: want=%d got=%d", len(values), n)
+						} else if err == io.EOF {
+							err = nil
+						}
+						return err
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestMergeRowGroupsByteArraysAcrossBatches(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name"`
+	}
+
+	schema := parquet.SchemaOf(Row{})
+	sorting := parquet.SortingColumns(parquet.Ascending("name"))
+
+	// The row groups have more rows than the batches read from each of them by
+	// the merge, and their readers reuse the memory of byte arrays on each
+	// read, like the readers of file pages may do.
+	rowGroups := make([]parquet.RowGroup, 3)
+	want := []string{}
+
+	for i := range rowGroups {
+		buffer := parquet.NewBuffer(schema, sorting)
+		for j := 0; j < 500; j++ {
+			name := fmt.Sprintf("%06d-%s", j*len(rowGroups)+i, strings.Repeat("x", 20))
+			if err := buffer.Write(Row{Name: name}); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, name)
+		}
+		rowGroups[i] = reusedMemoryRowGroup{buffer}
+	}
+	sort.Strings(want)
+
+	merged, err := parquet.MergeRowGroups(rowGroups, schema, sorting)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := merged.Rows()
+	defer rows.Close()
+
+	buf := make([]parquet.Row, len(want))
+	got := []string{}
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			got = append(got, string(row[0].ByteArray()))
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("wrong number of rows read: want=%d got=%d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("wrong row at index %d: want=%q got=%q", i, want[i], got[i])
+		}
+	}
+}
+
+type reusedMemoryRowGroup struct{ parquet.RowGroup }
+
+func (r reusedMemoryRowGroup) Rows() parquet.Rows {
+	return &reusedMemoryRows{Rows: r.RowGroup.Rows()}
+}
+
+type reusedMemoryRows struct {
+	parquet.Rows
+	buffer []byte
+}
+
+func (r *reusedMemoryRows) ReadRows(rows []parquet.Row) (int, error) {
+	n, err := r.Rows.ReadRows(rows)
+
+	size := 0
+	for _, row := range rows[:n] {
+		for _, v := range row {
+			size += len(v.ByteArray())
+		}
+	}
+	if cap(r.buffer) < size {
+		r.buffer = make([]byte, 0, size)
+	}
+
+	r.buffer = r.buffer[:0]
+	for _, row := range rows[:n] {
+		for i, v := range row {
+			if v.Kind() == parquet.ByteArray {
+				offset := len(r.buffer)
+				r.buffer = append(r.buffer, v.ByteArray()...)
+				b := r.buffer[offset:len(r.buffer):len(r.buffer)]
+				row[i] = parquet.ValueOf(b).Level(v.RepetitionLevel(), v.DefinitionLevel(), v.Column())
+			}
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,80 @@
+package parquet
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// ReaderMetrics is an interface implemented by applications that want to
+// observe the activity of parquet readers, for example to export counters and
+// timings to a monitoring system.
+//
+// Implementations are installed with the Metrics option, which can be passed
+// to OpenFile as well as to the reader constructors. The methods may be called
+// concurrently from multiple goroutines if the file is read concurrently, and
+// they are called synchronously on the read path; implementations should be
+// cheap and must not block.
+type ReaderMetrics interface {
+	// Called after n bytes were read from the underlying storage.
+	BytesRead(n int64)
+
+	// Called after a data page of the given column was read and decoded,
+	// with the compression codec of the page and the number of values that
+	// it contained.
+	PageDecoded(column int, codec format.CompressionCodec, numValues int64)
+
+	// Called after a page was decompressed, with the codec used, the size of
+	// the page after decompression, and the time spent decompressing it.
+	PageDecompressed(codec format.CompressionCodec, size int64, duration time.Duration)
+
+	// Called after n rows were read by a parquet reader.
+	RowsRead(n int64)
+}
+
+// Metrics creates a configuration option which installs m to receive metrics
+// about the activity of parquet files and readers.
+//
+// When passed to a reader constructor which opens the file itself (e.g.
+// NewReader with an io.ReaderAt which is not a *File), the metrics are also
+// installed on the file.
+func Metrics(m ReaderMetrics) interface {
+	FileOption
+	ReaderOption
+} {
+	return readerMetrics{m}
+}
+
+type readerMetrics struct{ metrics ReaderMetrics }
+
+func (m readerMetrics) ConfigureFile(config *FileConfig) { config.Metrics = m.metrics }
+
+func (m readerMetrics) ConfigureReader(config *ReaderConfig) { config.Metrics = m.metrics }
+
+// metricsReaderAt is an io.ReaderAt wrapper reporting the number of bytes read
+// to a ReaderMetrics instance.
+type metricsReaderAt struct {
+	reader  io.ReaderAt
+	metrics ReaderMetrics
+}
+
+func (r *metricsReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	return r.ReadAtContext(context.Background(), b, off)
+}
+
+func (r *metricsReaderAt) ReadAtContext(ctx context.Context, b []byte, off int64) (int, error) {
+	n, err := readAtContext(ctx, r.reader, b, off)
+	if n > 0 {
+		r.metrics.BytesRead(int64(n))
+	}
+	return n, err
+}
+
+func coalesceReaderMetrics(m1, m2 ReaderMetrics) ReaderMetrics {
+	if m1 != nil {
+		return m1
+	}
+	return m2
+}
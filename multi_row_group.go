@@ -104,6 +104,10 @@ func (c *multiRowGroup) Schema() *Schema { return c.schema }
 
 func (c *multiRowGroup) Rows() Rows { return &rowGroupRows{rowGroup: c} }
 
+func (c *multiRowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	return &rowGroupRows{rowGroup: c, memory: m}
+}
+
 type multiColumnChunk struct {
 	rowGroup *multiRowGroup
 	column   int
@@ -133,6 +137,10 @@ func (c *multiColumnChunk) Pages() Pages {
 	return &multiPages{column: c}
 }
 
+func (c *multiColumnChunk) pagesWithMemory(m *memoryReservation) Pages {
+	return &multiPages{column: c, memory: m}
+}
+
 func (c *multiColumnChunk) ColumnIndex() ColumnIndex {
 	// TODO: implement
 	return nil
@@ -215,6 +223,7 @@ type multiPages struct {
 	pages  Pages
 	index  int
 	column *multiColumnChunk
+	memory *memoryReservation
 }
 
 func (m *multiPages) ReadPage() (Page, error) {
@@ -234,7 +243,7 @@ func (m *multiPages) ReadPage() (Page, error) {
 			return nil, io.EOF
 		}
 
-		m.pages = m.column.chunks[m.index].Pages()
+		m.pages = pagesWithMemory(m.column.chunks[m.index], m.memory)
 		m.index++
 	}
 }
@@ -265,7 +274,7 @@ func (m *multiPages) SeekToRow(rowIndex int64) error {
 	}
 
 	if m.index < len(rowGroups) {
-		m.pages = m.column.chunks[m.index].Pages()
+		m.pages = pagesWithMemory(m.column.chunks[m.index], m.memory)
 		m.index++
 		return m.pages.SeekToRow(rowIndex)
 	}
@@ -102,7 +102,7 @@ func Encoded(node Node, encoding encoding.Encoding) Node {
 	}
 	if encoding != nil {
 		kind := node.Type().Kind()
-		if !canEncode(encoding, kind) {
+		if !CanEncode(encoding, kind) {
 			panic("cannot apply " + encoding.Encoding().String() + " to node of type " + kind.String())
 		}
 	}
@@ -147,9 +147,64 @@ func (n *compressedNode) Compression() compress.Codec {
 }
 
 // Optional wraps the given node to make it optional.
-func Optional(node Node) Node { return &optionalNode{node} }
+//
+// When deconstructing Go values, zero values of optional fields are written as
+// nulls; use OptionalWith to change this behavior.
+func Optional(node Node) Node { return &optionalNode{Node: node} }
+
+// OptionalWith wraps the given node to make it optional, using the given policy
+// to determine which Go values are written as nulls when deconstructing them.
+func OptionalWith(node Node, policy NullPolicy) Node {
+	return &optionalNode{Node: node, nullPolicy: policy}
+}
+
+// NullPolicy values determine which Go values of optional fields are written as
+// nulls when deconstructing them into rows.
+type NullPolicy int
 
-type optionalNode struct{ Node }
+const (
+	// NullIfZero writes zero values as nulls, including empty strings, zero
+	// numbers, and zero time.Time values. This is the default policy of
+	// optional nodes.
+	NullIfZero NullPolicy = iota
+
+	// NullIfNil only writes nil pointers, slices, maps, and interfaces as
+	// nulls, the zero values of other types are written as non-null values.
+	NullIfNil
+)
+
+func (policy NullPolicy) isNull(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return value.IsNil()
+	default:
+		return policy == NullIfZero && value.IsZero()
+	}
+}
+
+func nullPolicyOf(node Node) NullPolicy {
+	for {
+		switch n := node.(type) {
+		case *optionalNode:
+			return n.nullPolicy
+		case *structField:
+			node = n.Node
+		case *groupField:
+			node = n.Node
+		case *encodedNode:
+			node = n.Node
+		case *compressedNode:
+			node = n.Node
+		default:
+			return NullIfZero
+		}
+	}
+}
+
+type optionalNode struct {
+	Node
+	nullPolicy NullPolicy
+}
 
 func (opt *optionalNode) Optional() bool       { return true }
 func (opt *optionalNode) Repeated() bool       { return false }
@@ -421,6 +476,46 @@ func encodingOf(node Node) encoding.Encoding {
 	return encoding
 }
 
+// preferredEncodingOf returns the encoding of node, selecting the first of the
+// preferred encodings of its kind if it had no explicit encoding. The second
+// return value lists the encodings to fall back to when the selected one fails
+// to encode values, ending with the default encoding of the node.
+func preferredEncodingOf(node Node, preferredEncodings map[Kind][]encoding.Encoding) (encoding.Encoding, []encoding.Encoding) {
+	defaultEncoding := encodingOf(node)
+	if node.Encoding() != nil {
+		return defaultEncoding, nil
+	}
+
+	kind := node.Type().Kind()
+	encodings := make([]encoding.Encoding, 0, len(preferredEncodings[kind])+1)
+	for _, enc := range preferredEncodings[kind] {
+		if CanEncode(enc, kind) {
+			encodings = append(encodings, enc)
+		}
+	}
+	if len(encodings) == 0 {
+		return defaultEncoding, nil
+	}
+	if isDictionaryEncoding(encodings[0]) {
+		// Columns are converted to use a dictionary, they do not fall back
+		// to other encodings.
+		return encodings[0], nil
+	}
+
+	fallbacks := encodings[1:]
+	for i := 0; i < len(fallbacks); {
+		if isDictionaryEncoding(fallbacks[i]) || fallbacks[i].Encoding() == encodings[0].Encoding() {
+			fallbacks = append(fallbacks[:i], fallbacks[i+1:]...)
+		} else {
+			i++
+		}
+	}
+	if defaultEncoding.Encoding() != encodings[0].Encoding() {
+		fallbacks = append(fallbacks, defaultEncoding)
+	}
+	return encodings[0], fallbacks
+}
+
 func forEachNodeOf(name string, node Node, do func(string, Node)) {
 	do(name, node)
 
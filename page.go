@@ -47,8 +47,11 @@ type Page interface {
 	//
 	// The third value is a boolean indicating whether the page bounds were
 	// available. Page bounds may not be known if the page contained no values
-	// or only nulls, or if they were read from a parquet file which had neither
-	// page statistics nor a page index.
+	// or only nulls (or NaN for floating point columns), or if they were read
+	// from a parquet file which had neither page statistics nor a page index.
+	//
+	// NaN values are never part of the bounds of floating point columns, and
+	// zero bounds are returned as -0 for the min and +0 for the max.
 	Bounds() (min, max Value, ok bool)
 
 	// Returns the size of the page in bytes (uncompressed).
@@ -141,12 +144,57 @@ type PageWriter interface {
 
 // Pages is an interface implemented by page readers returned by calling the
 // Pages method of ColumnChunk instances.
+//
+// Implementations may also implement the PageSkipper interface to let programs
+// skip pages without reading nor decoding them.
 type Pages interface {
 	PageReader
 	RowSeeker
 	io.Closer
 }
 
+// PageSkipper is an interface implemented by Pages which support skipping
+// pages without decoding them, for example based on the statistics recorded in
+// the page index of a column chunk. The Pages returned by column chunks of a
+// File implement it.
+//
+// Skipping pages allows programs to implement their own pruning of the data
+// they read, by only decoding the pages which may contain values of interest.
+type PageSkipper interface {
+	// Skips the next page of the sequence, without reading its content when
+	// the column chunk has an offset index. If there are no more pages, the
+	// method returns io.EOF.
+	SkipPage() error
+
+	// Positions the sequence at the next page for which the predicate returns
+	// true, skipping the other pages without reading them. The matching page
+	// is returned by the next call to ReadPage.
+	//
+	// The method returns the statistics of the matching page, or io.EOF if
+	// there are no more pages matching the predicate. If the column chunk has
+	// no page index, ErrMissingPageIndex is returned.
+	NextMatching(predicate func(PageStats) bool) (PageStats, error)
+}
+
+// PageStats carries the statistics of a data page, as recorded in the column
+// and offset indexes of a column chunk.
+type PageStats struct {
+	// Index of the page in the column chunk.
+	Index int
+	// Index of the first row of the page in the row group, and number of rows
+	// that the page contains.
+	FirstRowIndex int64
+	NumRows       int64
+	// Number of null values in the page, and whether the page contains null
+	// values only.
+	NullCount int64
+	NullPage  bool
+	// Bounds of the values in the page. Both values are null if the page
+	// contains null values only.
+	MinValue Value
+	MaxValue Value
+}
+
 func copyPagesAndClose(w PageWriter, r Pages) (int64, error) {
 	defer r.Close()
 	return CopyPages(w, r)
@@ -852,11 +900,11 @@ func (page *floatPage) min() float32 { return minFloat32(page.values) }
 
 func (page *floatPage) max() float32 { return maxFloat32(page.values) }
 
-func (page *floatPage) bounds() (min, max float32) { return boundsFloat32(page.values) }
+func (page *floatPage) bounds() (min, max float32, ok bool) { return boundsFloat32NaN(page.values) }
 
 func (page *floatPage) Bounds() (min, max Value, ok bool) {
-	if ok = len(page.values) > 0; ok {
-		minFloat32, maxFloat32 := page.bounds()
+	var minFloat32, maxFloat32 float32
+	if minFloat32, maxFloat32, ok = page.bounds(); ok {
 		min = page.makeValue(minFloat32)
 		max = page.makeValue(maxFloat32)
 	}
@@ -927,11 +975,11 @@ func (page *doublePage) min() float64 { return minFloat64(page.values) }
 
 func (page *doublePage) max() float64 { return maxFloat64(page.values) }
 
-func (page *doublePage) bounds() (min, max float64) { return boundsFloat64(page.values) }
+func (page *doublePage) bounds() (min, max float64, ok bool) { return boundsFloat64NaN(page.values) }
 
 func (page *doublePage) Bounds() (min, max Value, ok bool) {
-	if ok = len(page.values) > 0; ok {
-		minFloat64, maxFloat64 := page.bounds()
+	var minFloat64, maxFloat64 float64
+	if minFloat64, maxFloat64, ok = page.bounds(); ok {
 		min = page.makeValue(minFloat64)
 		max = page.makeValue(maxFloat64)
 	}
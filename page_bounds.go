@@ -1,6 +1,9 @@
 package parquet
 
-import "bytes"
+import (
+	"bytes"
+	"math"
+)
 
 func boundsFixedLenByteArray(data []byte, size int) (min, max []byte) {
 	if len(data) > 0 {
@@ -23,3 +26,97 @@ func boundsFixedLenByteArray(data []byte, size int) (min, max []byte) {
 	}
 	return min, max
 }
+
+// The bounds of FLOAT and DOUBLE values follow the rules of the parquet
+// specification: NaN values are ignored since they are not ordered, a zero min
+// is written as -0 and a zero max as +0, so the bounds cover both zeros.
+//
+// The returned boolean is false if all the values are NaN.
+
+func boundsFloat32NaN(data []float32) (min, max float32, ok bool) {
+	if !containsNaNFloat32(data) {
+		if ok = len(data) > 0; ok {
+			min, max = boundsFloat32(data)
+		}
+	} else {
+		for _, v := range data {
+			if v != v {
+				continue
+			}
+			if !ok {
+				min, max, ok = v, v, true
+			} else if v < min {
+				min = v
+			} else if v > max {
+				max = v
+			}
+		}
+	}
+	if ok {
+		min, max = signedZeroBoundsFloat32(min, max)
+	}
+	return min, max, ok
+}
+
+func boundsFloat64NaN(data []float64) (min, max float64, ok bool) {
+	if !containsNaNFloat64(data) {
+		if ok = len(data) > 0; ok {
+			min, max = boundsFloat64(data)
+		}
+	} else {
+		for _, v := range data {
+			if v != v {
+				continue
+			}
+			if !ok {
+				min, max, ok = v, v, true
+			} else if v < min {
+				min = v
+			} else if v > max {
+				max = v
+			}
+		}
+	}
+	if ok {
+		min, max = signedZeroBoundsFloat64(min, max)
+	}
+	return min, max, ok
+}
+
+func signedZeroBoundsFloat32(min, max float32) (float32, float32) {
+	if min == 0 {
+		min = float32(math.Copysign(0, -1))
+	}
+	if max == 0 {
+		max = 0
+	}
+	return min, max
+}
+
+func signedZeroBoundsFloat64(min, max float64) (float64, float64) {
+	if min == 0 {
+		min = math.Copysign(0, -1)
+	}
+	if max == 0 {
+		max = 0
+	}
+	return min, max
+}
+
+func containsNaNFloat32(data []float32) bool {
+	for _, v := range data {
+		if v != v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsNaNFloat64(data []float64) bool {
+	for _, v := range data {
+		if v != v {
+			return true
+		}
+	}
+	return false
+}
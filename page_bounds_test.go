@@ -3,6 +3,7 @@ package parquet
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 
@@ -144,6 +145,49 @@ func TestBoundsFloat32(t *testing.T) {
 	}
 }
 
+func TestBoundsFloatNaN(t *testing.T) {
+	nan := math.NaN()
+	negativeZero := math.Copysign(0, -1)
+
+	for _, test := range []struct {
+		values   []float64
+		min, max float64
+		ok       bool
+	}{
+		{values: nil},
+		{values: []float64{nan, nan}},
+		{values: []float64{nan, 1, -2, nan, 3}, min: -2, max: 3, ok: true},
+		{values: []float64{nan, 1}, min: 1, max: 1, ok: true},
+		{values: []float64{0, 1}, min: negativeZero, max: 1, ok: true},
+		{values: []float64{-1, negativeZero}, min: -1, max: 0, ok: true},
+		{values: []float64{nan, 0}, min: negativeZero, max: 0, ok: true},
+	} {
+		float32Values := make([]float32, len(test.values))
+		for i, v := range test.values {
+			float32Values[i] = float32(v)
+		}
+		min32, max32, ok32 := boundsFloat32NaN(float32Values)
+		min64, max64, ok64 := boundsFloat64NaN(test.values)
+
+		if ok32 != test.ok || ok64 != test.ok {
+			t.Errorf("%v: wrong ok: want=%t got=%t/%t", test.values, test.ok, ok32, ok64)
+			continue
+		}
+		if !test.ok {
+			continue
+		}
+		for _, bounds := range [][2]float64{{float64(min32), float64(max32)}, {min64, max64}} {
+			min, max := bounds[0], bounds[1]
+			if min != test.min || math.Signbit(min) != math.Signbit(test.min) {
+				t.Errorf("%v: wrong min: want=%g got=%g", test.values, test.min, min)
+			}
+			if max != test.max || math.Signbit(max) != math.Signbit(test.max) {
+				t.Errorf("%v: wrong max: want=%g got=%g", test.values, test.max, max)
+			}
+		}
+	}
+}
+
 func TestBoundsFloat64(t *testing.T) {
 	err := quick.Check(func(values []float64) bool {
 		min := float64(0)
@@ -108,6 +108,10 @@ func (buf *fileBuffer) Read(b []byte) (int, error) {
 	return n, err
 }
 
+func (buf *fileBuffer) ReadAt(b []byte, off int64) (int, error) {
+	return buf.file.ReadAt(b, off)
+}
+
 func (buf *fileBuffer) ReadFrom(r io.Reader) (int64, error) {
 	return buf.file.ReadFrom(r)
 }
@@ -131,6 +135,7 @@ func (buf *errorBuffer) WriteTo(io.Writer) (int64, error)  { return 0, buf.err }
 var (
 	defaultPageBufferPool pageBufferPool
 
+	_ io.ReaderAt     = (*fileBuffer)(nil)
 	_ io.ReaderFrom   = (*fileBuffer)(nil)
 	_ io.StringWriter = (*fileBuffer)(nil)
 
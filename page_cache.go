@@ -0,0 +1,234 @@
+package parquet
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// PageCache is an interface representing caches of byte ranges read from
+// parquet files.
+//
+// When a page cache is installed on a file with the ReadCache option, reads
+// of the file are aligned on blocks of a fixed size, which are looked up in
+// the cache before being read from the underlying io.ReaderAt. This is mostly
+// useful when reading files from remote storage (e.g. object stores), where
+// each call to ReadAt translates into a network request.
+//
+// Implementations of PageCache must be safe to use concurrently from multiple
+// goroutines. The byte slices passed to Put and returned by Get must not be
+// modified, neither by the cache nor by the application.
+type PageCache interface {
+	// Returns the data of the block at the given key, and a boolean
+	// indicating whether the block was found in the cache.
+	Get(key PageCacheKey) ([]byte, bool)
+
+	// Inserts the data of the block at the given key in the cache.
+	Put(key PageCacheKey, data []byte)
+}
+
+// PageCacheKey is the type of keys used to identify blocks of parquet files
+// in a PageCache.
+type PageCacheKey struct {
+	// Name of the file that the block belongs to, as configured with the
+	// ReadCache option or derived from the name of the file.
+	File string
+	// Offset of the block in the file.
+	Offset int64
+}
+
+// NewLRUPageCache constructs a PageCache which retains up to capacity bytes of
+// blocks, evicting the least recently used blocks when the capacity is
+// exceeded.
+func NewLRUPageCache(capacity int64) PageCache {
+	return &lruPageCache{
+		capacity: capacity,
+		entries:  make(map[PageCacheKey]*list.Element),
+	}
+}
+
+type lruPageCache struct {
+	mutex    sync.Mutex
+	capacity int64
+	size     int64
+	entries  map[PageCacheKey]*list.Element
+	queue    list.List
+}
+
+type lruPageCacheEntry struct {
+	key  PageCacheKey
+	data []byte
+}
+
+func (c *lruPageCache) Get(key PageCacheKey) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem := c.entries[key]
+	if elem == nil {
+		return nil, false
+	}
+	c.queue.MoveToFront(elem)
+	return elem.Value.(*lruPageCacheEntry).data, true
+}
+
+func (c *lruPageCache) Put(key PageCacheKey, data []byte) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if int64(len(data)) > c.capacity {
+		return
+	}
+
+	if elem := c.entries[key]; elem != nil {
+		entry := elem.Value.(*lruPageCacheEntry)
+		c.size += int64(len(data)) - int64(len(entry.data))
+		entry.data = data
+		c.queue.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.queue.PushFront(&lruPageCacheEntry{key: key, data: data})
+		c.size += int64(len(data))
+	}
+
+	for c.size > c.capacity {
+		elem := c.queue.Back()
+		entry := elem.Value.(*lruPageCacheEntry)
+		c.queue.Remove(elem)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.data))
+	}
+}
+
+// cachedReaderAt is an io.ReaderAt which reads blocks of fixed size from a
+// base reader, looking them up in a page cache first. Contiguous blocks that
+// are missing from the cache are read with a single call to the base reader,
+// which coalesces adjacent small reads into fewer, larger ones.
+type cachedReaderAt struct {
+	reader    io.ReaderAt
+	size      int64
+	cache     PageCache
+	file      string
+	blockSize int64
+}
+
+func (r *cachedReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	return r.ReadAtContext(context.Background(), b, off)
+}
+
+func (r *cachedReaderAt) ReadAtContext(ctx context.Context, b []byte, off int64) (int, error) {
+	if off < 0 || off >= r.size {
+		return 0, io.EOF
+	}
+
+	end := off + int64(len(b))
+	if end > r.size {
+		end = r.size
+	}
+
+	n := 0
+	blockOffset := off - off%r.blockSize
+
+	for blockOffset < end {
+		if data, ok := r.cache.Get(PageCacheKey{File: r.file, Offset: blockOffset}); ok {
+			n += copy(b[n:], data[off+int64(n)-blockOffset:])
+			blockOffset += r.blockSize
+			continue
+		}
+
+		// Extend the read to all the following blocks that are also missing
+		// from the cache, so they are retrieved with a single call to ReadAt.
+		readEnd := blockOffset + r.blockSize
+		for readEnd < end {
+			if _, ok := r.cache.Get(PageCacheKey{File: r.file, Offset: readEnd}); ok {
+				break
+			}
+			readEnd += r.blockSize
+		}
+		if readEnd > r.size {
+			readEnd = r.size
+		}
+
+		size := readEnd - blockOffset
+		data := make([]byte, size)
+		rn, err := readAtContext(ctx, r.reader, data, blockOffset)
+		if err != nil && err != io.EOF {
+			return n, err
+		}
+		data = data[:rn]
+
+		// Only the blocks which were read entirely are cached, the bytes past
+		// the end of a short read are not part of the file. Each block is
+		// copied to its own buffer, so the blocks retained by the cache do not
+		// hold on to the memory of the whole read.
+		for i := int64(0); i < size; i += r.blockSize {
+			j := min64(i+r.blockSize, size)
+			if j > int64(len(data)) {
+				break
+			}
+			block := make([]byte, j-i)
+			copy(block, data[i:j])
+			r.cache.Put(PageCacheKey{File: r.file, Offset: blockOffset + i}, block)
+		}
+
+		if i := off + int64(n) - blockOffset; i < int64(len(data)) {
+			n += copy(b[n:], data[i:])
+		}
+		if int64(len(data)) < size {
+			break
+		}
+		blockOffset = readEnd
+	}
+
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// ReadCache is a file configuration option which installs a page cache to
+// serve the reads of the file. The name identifies the file in the cache, it
+// must be unique among the files sharing the cache, and the same for all the
+// instances of File opened on the same content.
+//
+// When the name is empty, it is derived from the name and size of the file if
+// the io.ReaderAt it is read from has a Name method (e.g. *os.File), otherwise
+// opening the file fails, since files sharing the cache would collide.
+//
+// Reads are aligned on blocks of the size configured with ReadCacheBlockSize.
+func ReadCache(cache PageCache, name string) FileOption {
+	return fileOption(func(config *FileConfig) {
+		config.ReadCache = cache
+		config.ReadCacheName = name
+	})
+}
+
+// ReadCacheBlockSize is a file configuration option which sets the size of the
+// blocks stored in the page cache installed with ReadCache. Larger blocks mean
+// fewer reads from the underlying storage, at the expense of reading more data
+// than necessary when accessing small and distant ranges of the file.
+//
+// Defaults to 256 KiB.
+func ReadCacheBlockSize(size int) FileOption {
+	return fileOption(func(config *FileConfig) { config.ReadCacheBlockSize = size })
+}
+
+// readCacheNameOf returns the name identifying the file read from r in the
+// page cache.
+func readCacheNameOf(r io.ReaderAt, size int64, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if f, ok := r.(interface{ Name() string }); ok && f.Name() != "" {
+		return fmt.Sprintf("%s:%d", f.Name(), size), nil
+	}
+	return "", fmt.Errorf("the name of the file must be set with parquet.ReadCache when reading from %T", r)
+}
+
+func coalescePageCache(c1, c2 PageCache) PageCache {
+	if c1 != nil {
+		return c1
+	}
+	return c2
+}
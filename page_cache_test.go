@@ -0,0 +1,287 @@
+package parquet_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+type countingReaderAt struct {
+	reader io.ReaderAt
+	reads  int
+}
+
+func (r *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	r.reads++
+	return r.reader.ReadAt(b, off)
+}
+
+func TestReadCache(t *testing.T) {
+	type Row struct {
+		Name  string
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i] = Row{Name: "row", Value: int64(i)}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+	cache := parquet.NewLRUPageCache(int64(len(data)))
+
+	readRows := func(blockSize int) ([]Row, int) {
+		r := &countingReaderAt{reader: bytes.NewReader(data)}
+		f, err := parquet.OpenFile(r, int64(len(data)),
+			parquet.ReadCache(cache, "test.parquet"),
+			parquet.ReadCacheBlockSize(blockSize),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := parquet.NewReader(f)
+		found := make([]Row, 0, len(rows))
+		for {
+			row := Row{}
+			if err := reader.Read(&row); err != nil {
+				if err != io.EOF {
+					t.Fatal(err)
+				}
+				break
+			}
+			found = append(found, row)
+		}
+		return found, r.reads
+	}
+
+	found, reads := readRows(len(data))
+	if len(found) != len(rows) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(rows), len(found))
+	}
+	for i := range rows {
+		if found[i] != rows[i] {
+			t.Fatalf("row %d mismatch: want=%+v got=%+v", i, rows[i], found[i])
+		}
+	}
+	if reads != 1 {
+		t.Errorf("the file should have been read in a single block: reads=%d", reads)
+	}
+
+	if _, reads := readRows(len(data)); reads != 0 {
+		t.Errorf("the file should have been read from the cache: reads=%d", reads)
+	}
+}
+
+func TestReadCacheCoalescing(t *testing.T) {
+	data := make([]byte, 10000)
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows([]struct{ Data []byte }{{data}})); err != nil {
+		t.Fatal(err)
+	}
+	data = buffer.Bytes()
+
+	r := &countingReaderAt{reader: bytes.NewReader(data)}
+	f, err := parquet.OpenFile(r, int64(len(data)),
+		parquet.SkipPageIndex(true),
+		parquet.SkipBloomFilters(true),
+		parquet.ReadCache(parquet.NewLRUPageCache(1<<20), "test.parquet"),
+		parquet.ReadCacheBlockSize(64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading a range spanning many blocks missing from the cache must result
+	// in a single read of the underlying storage.
+	r.reads = 0
+	b := make([]byte, 5000)
+	if _, err := f.ReadAt(b, 100); err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 1 {
+		t.Errorf("wrong number of reads: want=1 got=%d", r.reads)
+	}
+	if !bytes.Equal(b, data[100:5100]) {
+		t.Error("data read from the cache does not match the file content")
+	}
+
+	r.reads = 0
+	if _, err := f.ReadAt(b[:1000], 2000); err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 0 {
+		t.Errorf("wrong number of reads: want=0 got=%d", r.reads)
+	}
+	if !bytes.Equal(b[:1000], data[2000:3000]) {
+		t.Error("data read from the cache does not match the file content")
+	}
+}
+
+type recordingPageCache struct {
+	parquet.PageCache
+	blocks [][]byte
+}
+
+func (c *recordingPageCache) Put(key parquet.PageCacheKey, data []byte) {
+	c.blocks = append(c.blocks, data)
+	c.PageCache.Put(key, data)
+}
+
+func TestReadCacheBlocksDoNotShareMemory(t *testing.T) {
+	data := make([]byte, 10000)
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows([]struct{ Data []byte }{{data}})); err != nil {
+		t.Fatal(err)
+	}
+	data = buffer.Bytes()
+
+	cache := &recordingPageCache{PageCache: parquet.NewLRUPageCache(1 << 20)}
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)),
+		parquet.SkipPageIndex(true),
+		parquet.SkipBloomFilters(true),
+		parquet.ReadCache(cache, "test.parquet"),
+		parquet.ReadCacheBlockSize(64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.blocks = nil
+	if _, err := f.ReadAt(make([]byte, 5000), 100); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.blocks) < 2 {
+		t.Fatalf("the read should have cached multiple blocks: blocks=%d", len(cache.blocks))
+	}
+	// A block sharing the memory of the coalesced read would retain all the
+	// blocks that follow it.
+	for i, block := range cache.blocks {
+		if cap(block) != len(block) {
+			t.Errorf("block %d retains memory past its end: len=%d cap=%d", i, len(block), cap(block))
+		}
+	}
+}
+
+type truncatedReaderAt struct {
+	reader io.ReaderAt
+	size   int64
+}
+
+func (r *truncatedReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	if limit := r.size - off; int64(len(b)) > limit {
+		n, err := r.reader.ReadAt(b[:limit], off)
+		if err == nil {
+			err = io.EOF
+		}
+		return n, err
+	}
+	return r.reader.ReadAt(b, off)
+}
+
+func TestReadCacheShortRead(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i) | 1
+	}
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows([]struct{ Data []byte }{{data}})); err != nil {
+		t.Fatal(err)
+	}
+	data = buffer.Bytes()
+
+	r := &truncatedReaderAt{reader: bytes.NewReader(data), size: int64(len(data))}
+	f, err := parquet.OpenFile(r, int64(len(data)),
+		parquet.SkipPageIndex(true),
+		parquet.SkipBloomFilters(true),
+		parquet.ReadCache(parquet.NewLRUPageCache(1<<20), "test.parquet"),
+		parquet.ReadCacheBlockSize(64),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The storage returns less data than the size the file was opened with,
+	// the read must report the end of the data and not cache the blocks that
+	// were not read entirely.
+	r.size = 2030
+	b := make([]byte, 1000)
+	n, err := f.ReadAt(b, 1500)
+	if err != io.EOF {
+		t.Fatalf("wrong error returned by short read: want=%v got=%v", io.EOF, err)
+	}
+	if n != 530 {
+		t.Fatalf("wrong number of bytes returned by short read: want=530 got=%d", n)
+	}
+	if !bytes.Equal(b[:n], data[1500:2030]) {
+		t.Error("data returned by the short read does not match the file content")
+	}
+
+	r.size = int64(len(data))
+	if _, err := f.ReadAt(b, 1500); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data[1500:2500]) {
+		t.Error("data read after the short read does not match the file content")
+	}
+}
+
+type namedReaderAt struct {
+	countingReaderAt
+	name string
+}
+
+func (r *namedReaderAt) Name() string { return r.name }
+
+func TestReadCacheName(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows([]struct{ Value int64 }{{1}, {2}})); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+	cache := parquet.NewLRUPageCache(1 << 20)
+
+	// Files read from readers which have no names cannot share the cache
+	// without being named.
+	_, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)), parquet.ReadCache(cache, ""))
+	if err == nil {
+		t.Fatal("opening a file with a read cache but no name succeeded")
+	}
+
+	// The name of files is derived from their path when they are read from
+	// an *os.File.
+	path := filepath.Join(t.TempDir(), "test.parquet")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := parquet.OpenFile(file, int64(len(data)), parquet.ReadCache(cache, "")); err != nil {
+		t.Fatal(err)
+	}
+	r := &namedReaderAt{countingReaderAt{reader: file}, file.Name()}
+	f, err := parquet.OpenFile(r, int64(len(data)), parquet.ReadCache(cache, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.reads != 0 {
+		t.Errorf("the file should have been read from the cache: reads=%d", r.reads)
+	}
+	if n := f.NumRows(); n != 2 {
+		t.Errorf("wrong number of rows: want=2 got=%d", n)
+	}
+}
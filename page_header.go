@@ -51,6 +51,13 @@ type DataPageHeader interface {
 	//
 	// If the page only contains only null values, an empty slice is returned.
 	MaxValue() []byte
+
+	// Returns true if the page statistics record that the values returned by
+	// MinValue and MaxValue are the exact bounds of the page. Otherwise, the
+	// bounds may have been truncated by the writer (for byte arrays), or the
+	// writer did not record whether they were exact.
+	IsMinValueExact() bool
+	IsMaxValueExact() bool
 }
 
 // DictionaryPageHeader is an implementation of the PageHeader interface
@@ -120,6 +127,14 @@ func (v1 DataPageHeaderV1) MaxValue() []byte {
 	return v1.header.Statistics.MaxValue
 }
 
+func (v1 DataPageHeaderV1) IsMinValueExact() bool {
+	return isTrue(v1.header.Statistics.IsMinValueExact)
+}
+
+func (v1 DataPageHeaderV1) IsMaxValueExact() bool {
+	return isTrue(v1.header.Statistics.IsMaxValueExact)
+}
+
 func (v1 DataPageHeaderV1) String() string {
 	return fmt.Sprintf("DATA_PAGE_HEADER{NumValues=%d,Encoding=%s}",
 		v1.header.NumValues,
@@ -180,6 +195,14 @@ func (v2 DataPageHeaderV2) MaxValue() []byte {
 	return v2.header.Statistics.MaxValue
 }
 
+func (v2 DataPageHeaderV2) IsMinValueExact() bool {
+	return isTrue(v2.header.Statistics.IsMinValueExact)
+}
+
+func (v2 DataPageHeaderV2) IsMaxValueExact() bool {
+	return isTrue(v2.header.Statistics.IsMaxValueExact)
+}
+
 func (v2 DataPageHeaderV2) IsCompressed() bool {
 	return v2.header.IsCompressed == nil || *v2.header.IsCompressed
 }
@@ -219,3 +242,5 @@ var (
 	_ DataPageHeader = DataPageHeaderV2{}
 	_ PageHeader     = unknownPageHeader{}
 )
+
+func isTrue(b *bool) bool { return b != nil && *b }
@@ -0,0 +1,645 @@
+package parquet
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/segmentio/parquet-go/compress"
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/encoding/bitpacked"
+	"github.com/segmentio/parquet-go/encoding/rle"
+	"github.com/segmentio/parquet-go/format"
+)
+
+// Approximate size of the chunks of PLAIN encoded values that streaming pages
+// decode at once.
+const streamingPageChunkSize = 64 * 1024
+
+// StreamingPageSize creates a configuration option which enables streaming the
+// values of data pages larger than size bytes (uncompressed).
+//
+// Pages are normally decompressed and decoded in memory at once, which means
+// that a single oversized page could exhaust the memory of a program reading a
+// file. When streaming is enabled, the values of large pages are decompressed
+// and decoded in chunks as they are read, so only the repetition and definition
+// levels of the page are held in memory; the page data is read from the file
+// every time the page values are read.
+//
+// Streaming applies to PLAIN encoded pages of all types except BOOLEAN, and to
+// dictionary encoded pages, which are either uncompressed or compressed with a
+// codec implementing the compress.StreamingCodec interface, like SNAPPY, GZIP
+// or ZSTD. The indexes of dictionary encoded pages are small once encoded, so
+// they are read in memory and decoded incrementally, and those pages are
+// streamed when their decoded indexes would take more than size bytes. Other
+// pages are read in memory regardless of their size. The bounds of streamed
+// pages are not known, and calling their Buffer method reads all their values
+// in memory.
+//
+// When passed to a reader constructor which opens the file itself, the option
+// is also applied to the file.
+//
+// Defaults to zero, which disables streaming.
+func StreamingPageSize(size int) interface {
+	FileOption
+	ReaderOption
+} {
+	return streamingPageSize(size)
+}
+
+type streamingPageSize int
+
+func (size streamingPageSize) ConfigureFile(config *FileConfig) {
+	config.StreamingPageSize = int(size)
+}
+
+func (size streamingPageSize) ConfigureReader(config *ReaderConfig) {
+	config.StreamingPageSize = int(size)
+}
+
+// streamingPage is an implementation of the Page interface for data pages which
+// are decompressed and decoded incrementally when reading their values.
+type streamingPage struct {
+	typ    Type
+	column int
+	codec  compress.StreamingCodec
+	reader io.ReaderAt
+	// The encoding of the page values, which are indexes into the dictionary
+	// when it is not nil.
+	encoding   encoding.Encoding
+	dictionary Dictionary
+	// Location of the page data in the file, starting after the levels of
+	// data pages in version 2.
+	offset int64
+	length int64
+	// Number of uncompressed bytes preceding the values, which are the levels
+	// of data pages in version 1.
+	skip int64
+	// The checksum of the page, seeded with the checksum of the levels of
+	// data pages in version 2.
+	checksum    uint32
+	hasChecksum bool
+	seed        uint32
+
+	maxRepetitionLevel byte
+	maxDefinitionLevel byte
+	repetitionLevels   []byte
+	definitionLevels   []byte
+
+	numRows   int64
+	numValues int64
+	numNulls  int64
+	size      int64
+}
+
+func (page *streamingPage) Type() Type { return page.typ }
+
+func (page *streamingPage) Column() int { return page.column }
+
+func (page *streamingPage) Dictionary() Dictionary { return page.dictionary }
+
+func (page *streamingPage) NumRows() int64 { return page.numRows }
+
+func (page *streamingPage) NumValues() int64 { return page.numValues }
+
+func (page *streamingPage) NumNulls() int64 { return page.numNulls }
+
+func (page *streamingPage) Bounds() (min, max Value, ok bool) { return }
+
+func (page *streamingPage) Size() int64 { return page.size }
+
+func (page *streamingPage) Values() ValueReader { return &streamingPageValues{page: page} }
+
+func (page *streamingPage) Buffer() BufferedPage {
+	buffered, err := page.buffer()
+	if err != nil {
+		return newErrorPage(page.typ, page.column, "buffering streamed page: %w", err)
+	}
+	return buffered
+}
+
+func (page *streamingPage) buffer() (BufferedPage, error) {
+	s, err := page.open()
+	if err != nil {
+		return nil, err
+	}
+	defer s.close()
+
+	data, err := io.ReadAll(s.input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verify(); err != nil {
+		return nil, err
+	}
+	values, err := page.pageType().Decode(nil, data, page.encoding)
+	if err != nil {
+		return nil, err
+	}
+	return page.newPage(page.numValues-page.numNulls, values, page.repetitionLevels, page.definitionLevels), nil
+}
+
+// pageType returns the type of the values held by the page, which are indexes
+// into the dictionary of dictionary encoded pages.
+func (page *streamingPage) pageType() Type {
+	if page.dictionary != nil {
+		return indexedPageType{newIndexedType(page.typ, page.dictionary)}
+	}
+	return page.typ
+}
+
+func (page *streamingPage) newPage(numValues int64, values, repetitionLevels, definitionLevels []byte) BufferedPage {
+	base := page.pageType().NewPage(page.column, int(numValues), values).Buffer()
+	switch {
+	case page.maxRepetitionLevel > 0:
+		return newRepeatedPage(base, page.maxRepetitionLevel, page.maxDefinitionLevel, repetitionLevels, definitionLevels)
+	case page.maxDefinitionLevel > 0:
+		return newOptionalPage(base, page.maxDefinitionLevel, definitionLevels)
+	default:
+		return base
+	}
+}
+
+// open opens a stream of the uncompressed page data, positioned at the first
+// value of the page.
+func (page *streamingPage) open() (*pageStream, error) {
+	s := &pageStream{page: page, crc: page.seed}
+	s.section = *io.NewSectionReader(page.reader, page.offset, page.length)
+
+	stream, err := page.codec.NewReader(s)
+	if err != nil {
+		return nil, err
+	}
+	s.stream = stream
+	s.input = bufio.NewReaderSize(stream, streamingPageChunkSize)
+
+	if page.skip > 0 {
+		if _, err := s.input.Discard(int(page.skip)); err != nil {
+			s.close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// pageStream reads the data of streaming pages, computing the checksum of the
+// page as it is read.
+type pageStream struct {
+	page    *streamingPage
+	section io.SectionReader
+	crc     uint32
+	stream  io.ReadCloser
+	input   *bufio.Reader
+}
+
+func (s *pageStream) Read(b []byte) (int, error) {
+	n, err := s.section.Read(b)
+	s.crc = crc32.Update(s.crc, crc32.IEEETable, b[:n])
+	return n, err
+}
+
+// Seek is implemented to allow codecs to scan the compressed data before
+// decompressing it, the checksum restarts when seeking back to the beginning
+// of the page.
+func (s *pageStream) Seek(offset int64, whence int) (int64, error) {
+	position, err := s.section.Seek(offset, whence)
+	if err == nil && position == 0 {
+		s.crc = s.page.seed
+	}
+	return position, err
+}
+
+// verify validates the checksum of the page, which must be called after all
+// the page data was read.
+func (s *pageStream) verify() error {
+	if _, err := io.Copy(io.Discard, struct{ io.Reader }{s}); err != nil {
+		return err
+	}
+	if s.page.hasChecksum && s.crc != s.page.checksum {
+		return fmt.Errorf("crc32 checksum mismatch in streamed page: want=0x%08X got=0x%08X: %w", s.page.checksum, s.crc, ErrCorrupted)
+	}
+	return nil
+}
+
+func (s *pageStream) close() {
+	s.stream.Close()
+}
+
+type streamingPageValues struct {
+	page    *streamingPage
+	stream  *pageStream
+	values  ValueReader
+	buffer  []byte
+	offset  int
+	err     error
+	indexes encoding.ChunkDecoder
+}
+
+func (r *streamingPageValues) ReadValues(values []Value) (int, error) {
+	if len(values) == 0 {
+		return 0, r.err
+	}
+	for r.err == nil {
+		if r.values != nil {
+			n, err := r.values.ReadValues(values)
+			if err == io.EOF {
+				r.values, err = nil, nil
+			}
+			if n > 0 || err != nil {
+				return n, err
+			}
+		}
+		r.err = r.readChunk()
+	}
+	return 0, r.err
+}
+
+// readChunk decodes the next chunk of values of the page, returning io.EOF
+// when all the values were read.
+func (r *streamingPageValues) readChunk() error {
+	page := r.page
+	if r.stream == nil {
+		s, err := page.open()
+		if err != nil {
+			return err
+		}
+		r.stream = s
+	}
+
+	numLevels := int(page.numValues)
+	if r.offset == numLevels {
+		err := r.stream.verify()
+		r.Close()
+		if err == nil {
+			err = io.EOF
+		}
+		return err
+	}
+
+	if page.dictionary != nil {
+		return r.readIndexes()
+	}
+
+	valueSize := 0
+	switch page.typ.Kind() {
+	case Int32, Float:
+		valueSize = 4
+	case Int64, Double:
+		valueSize = 8
+	case Int96:
+		valueSize = 12
+	case FixedLenByteArray:
+		valueSize = page.typ.Length()
+	}
+
+	data := r.buffer[:0]
+	i, j := r.offset, r.offset
+	numValues := int64(0)
+
+	for j < numLevels {
+		// Chunks end on row boundaries so the values of a row are never split
+		// between the pages that chunks are exposed as.
+		if len(data) >= streamingPageChunkSize && (page.maxRepetitionLevel == 0 || page.repetitionLevels[j] == 0) {
+			break
+		}
+		if page.maxDefinitionLevel > 0 && page.definitionLevels[j] != page.maxDefinitionLevel {
+			j++
+			continue
+		}
+		size := valueSize
+		if valueSize == 0 {
+			b, err := r.stream.input.Peek(4)
+			if err != nil {
+				return unexpectedEOF(err)
+			}
+			size = 4 + int(binary.LittleEndian.Uint32(b))
+			if int64(size) > page.size {
+				return fmt.Errorf("byte array of %d bytes exceeds the page size of %d bytes: %w", size-4, page.size, ErrCorrupted)
+			}
+		}
+		n := len(data)
+		data = append(data, make([]byte, size)...)
+		if _, err := io.ReadFull(r.stream.input, data[n:]); err != nil {
+			return unexpectedEOF(err)
+		}
+		numValues++
+		j++
+	}
+
+	// The values are decoded in a new buffer for each chunk since the values
+	// read from the page may retain references to it, only the buffer holding
+	// the encoded values is reused.
+	r.buffer = data
+	values, err := page.typ.Decode(nil, data, &Plain)
+	if err != nil {
+		return err
+	}
+	r.newChunk(i, j, numValues, values)
+	return nil
+}
+
+// readIndexes decodes the next chunk of values of a dictionary encoded page.
+// The encoded indexes are read in memory when decoding the first chunk, then
+// decoded incrementally so only the indexes of one chunk are held at a time.
+func (r *streamingPageValues) readIndexes() error {
+	page := r.page
+	if r.indexes == nil {
+		data, err := io.ReadAll(r.stream.input)
+		if err != nil {
+			return err
+		}
+		if err := r.stream.verify(); err != nil {
+			return err
+		}
+		r.indexes = encoding.NewChunkDecoder(page.encoding, format.Int32, 0)
+		r.indexes.Reset(data)
+	}
+
+	numLevels := int(page.numValues)
+	i, j := r.offset, r.offset
+	numValues := 0
+
+	for j < numLevels {
+		if 4*numValues >= streamingPageChunkSize && (page.maxRepetitionLevel == 0 || page.repetitionLevels[j] == 0) {
+			break
+		}
+		if page.maxDefinitionLevel == 0 || page.definitionLevels[j] == page.maxDefinitionLevel {
+			numValues++
+		}
+		j++
+	}
+
+	// The indexes of pages with only zero indexes are sometimes truncated,
+	// indexed pages are padded with zeros when the decoder ends early.
+	indexes := make([]byte, 0, 4*numValues)
+	for len(indexes) < cap(indexes) {
+		b, err := r.indexes.Decode(r.buffer, numValues-len(indexes)/4)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		indexes = append(indexes, b...)
+		r.buffer = b
+	}
+	r.newChunk(i, j, int64(numValues), indexes)
+	return nil
+}
+
+// newChunk positions r to read the chunk of values of the page between the
+// levels at index i and j.
+func (r *streamingPageValues) newChunk(i, j int, numValues int64, values []byte) {
+	page := r.page
+	var repetitionLevels, definitionLevels []byte
+	if page.maxRepetitionLevel > 0 {
+		repetitionLevels = page.repetitionLevels[i:j]
+	}
+	if page.maxDefinitionLevel > 0 {
+		definitionLevels = page.definitionLevels[i:j]
+	}
+	r.offset = j
+	r.values = page.newPage(numValues, values, repetitionLevels, definitionLevels).Values()
+}
+
+func (r *streamingPageValues) Close() error {
+	if r.stream != nil {
+		r.stream.close()
+		r.stream = nil
+	}
+	r.buffer = nil
+	r.indexes = nil
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// canStreamPage returns true if the data page of the given header must be read
+// as a streaming page.
+func (f *filePages) canStreamPage(header *format.PageHeader) bool {
+	size := f.chunk.file.config.StreamingPageSize
+	if size <= 0 || f.skip > 0 {
+		return false
+	}
+	// Encrypted pages must be read in full to be authenticated.
+	if f.chunk.decryption != nil {
+		return false
+	}
+
+	column := f.chunk.column
+	compressed := isCompressed(column.compression)
+
+	var pageEncoding format.Encoding
+	var numValues int64
+	switch header.Type {
+	case format.DataPage:
+		if header.DataPageHeader == nil {
+			return false
+		}
+		pageEncoding, numValues = header.DataPageHeader.Encoding, int64(header.DataPageHeader.NumValues)
+	case format.DataPageV2:
+		if header.DataPageHeaderV2 == nil {
+			return false
+		}
+		pageEncoding, numValues = header.DataPageHeaderV2.Encoding, int64(header.DataPageHeaderV2.NumValues)
+		compressed = compressed && DataPageHeaderV2{header.DataPageHeaderV2}.IsCompressed()
+	default:
+		return false
+	}
+
+	switch {
+	case pageEncoding == format.Plain:
+		if int64(header.UncompressedPageSize) <= int64(size) || column.Type().Kind() == Boolean {
+			return false
+		}
+	case isDictionaryFormat(pageEncoding):
+		if 4*numValues <= int64(size) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if column.Type().PhysicalType() == nil {
+		return false
+	}
+	if compressed {
+		_, ok := column.compression.(compress.StreamingCodec)
+		return ok
+	}
+	return true
+}
+
+// readStreamingPage reads the data page of the given header as a streaming
+// page. Only the levels of the page are read, the reader is positioned at the
+// next page when the method returns.
+func (f *filePages) readStreamingPage(header *format.PageHeader) (Page, error) {
+	column := f.chunk.column
+	page := &streamingPage{
+		typ:                column.Type(),
+		column:             column.Index(),
+		codec:              &Uncompressed,
+		reader:             f.chunk.file,
+		encoding:           &Plain,
+		offset:             f.offset(),
+		length:             int64(header.CompressedPageSize),
+		checksum:           uint32(header.CRC),
+		hasChecksum:        header.CRC != 0,
+		maxRepetitionLevel: column.maxRepetitionLevel,
+		maxDefinitionLevel: column.maxDefinitionLevel,
+		size:               int64(header.UncompressedPageSize),
+	}
+	if page.length < 0 {
+		return nil, fmt.Errorf("negative compressed page size %d", page.length)
+	}
+
+	pageEncoding := format.Plain
+	if header.DataPageHeaderV2 != nil {
+		pageEncoding = header.DataPageHeaderV2.Encoding
+	} else if header.DataPageHeader != nil {
+		pageEncoding = header.DataPageHeader.Encoding
+	}
+	if isDictionaryFormat(pageEncoding) {
+		// The dictionary is loaded from the beginning of the column chunk
+		// if the program seeked past the dictionary page.
+		if f.dataPage.dictionary == nil {
+			if err := f.readDictionary(); err != nil {
+				return nil, err
+			}
+		}
+		page.encoding = &RLEDictionary
+		page.dictionary = f.dataPage.dictionary
+	}
+
+	isCompressedPage := isCompressed(column.compression)
+	decoder := new(rle.LevelDecoder)
+
+	if header.DataPageHeaderV2 != nil {
+		h := DataPageHeaderV2{header.DataPageHeaderV2}
+		isCompressedPage = isCompressedPage && h.IsCompressed()
+		repetitionLevelsLength := h.RepetitionLevelsByteLength()
+		definitionLevelsLength := h.DefinitionLevelsByteLength()
+		levelsLength := repetitionLevelsLength + definitionLevelsLength
+		if repetitionLevelsLength < 0 || definitionLevelsLength < 0 || levelsLength > page.length {
+			return nil, fmt.Errorf("invalid lengths of levels in data page v2: %w", ErrCorrupted)
+		}
+
+		levels := make([]byte, levelsLength)
+		if _, err := io.ReadFull(f.rbuf, levels); err != nil {
+			return nil, err
+		}
+		if err := f.discard(page.length - levelsLength); err != nil {
+			return nil, err
+		}
+
+		var err error
+		numValues := h.NumValues()
+		data := levels
+		if page.maxRepetitionLevel > 0 {
+			enc := lookupLevelEncoding(h.RepetitionLevelEncoding(), page.maxRepetitionLevel)
+			page.repetitionLevels, data, err = decodeLevelsV2(enc, numValues, nil, data, repetitionLevelsLength, decoder)
+			if err != nil {
+				return nil, fmt.Errorf("decoding repetition levels of data page v2: %w", err)
+			}
+		}
+		if page.maxDefinitionLevel > 0 {
+			enc := lookupLevelEncoding(h.DefinitionLevelEncoding(), page.maxDefinitionLevel)
+			page.definitionLevels, _, err = decodeLevelsV2(enc, numValues, nil, data, definitionLevelsLength, decoder)
+			if err != nil {
+				return nil, fmt.Errorf("decoding definition levels of data page v2: %w", err)
+			}
+		}
+
+		page.offset += levelsLength
+		page.length -= levelsLength
+		page.seed = crc32.ChecksumIEEE(levels)
+		page.numRows = h.NumRows()
+		page.numValues = numValues
+		page.numNulls = h.NumNulls()
+	} else {
+		if err := f.discard(page.length); err != nil {
+			return nil, err
+		}
+		page.numValues = DataPageHeaderV1{header.DataPageHeader}.NumValues()
+		page.numRows = page.numValues
+	}
+
+	if isCompressedPage {
+		page.codec = column.compression.(compress.StreamingCodec)
+	}
+
+	if header.DataPageHeader != nil && (page.maxRepetitionLevel > 0 || page.maxDefinitionLevel > 0) {
+		h := DataPageHeaderV1{header.DataPageHeader}
+		// The levels of data pages in version 1 are compressed with the
+		// values, they are read from the beginning of the page stream.
+		s, err := page.open()
+		if err != nil {
+			return nil, err
+		}
+		defer s.close()
+
+		if page.maxRepetitionLevel > 0 {
+			enc := lookupLevelEncoding(h.RepetitionLevelEncoding(), page.maxRepetitionLevel)
+			page.repetitionLevels, err = readLevelsV1(s.input, enc, page.numValues, decoder, &page.skip)
+			if err != nil {
+				return nil, fmt.Errorf("decoding repetition levels of data page v1: %w", err)
+			}
+			page.numRows = int64(countLevelsEqual(page.repetitionLevels, 0))
+		}
+		if page.maxDefinitionLevel > 0 {
+			enc := lookupLevelEncoding(h.DefinitionLevelEncoding(), page.maxDefinitionLevel)
+			page.definitionLevels, err = readLevelsV1(s.input, enc, page.numValues, decoder, &page.skip)
+			if err != nil {
+				return nil, fmt.Errorf("decoding definition levels of data page v1: %w", err)
+			}
+			page.numNulls = int64(countLevelsNotEqual(page.definitionLevels, page.maxDefinitionLevel))
+		}
+	}
+
+	return page, nil
+}
+
+// readLevelsV1 reads levels of a data page in version 1 from r, adding the
+// number of bytes that they occupy to size.
+func readLevelsV1(r *bufio.Reader, enc encoding.Encoding, numValues int64, decoder *rle.LevelDecoder, size *int64) ([]byte, error) {
+	var length int64
+	if e, ok := enc.(*bitpacked.Encoding); ok {
+		length = (numValues*int64(e.BitWidth) + 7) / 8
+	} else {
+		var b [4]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		length = int64(binary.LittleEndian.Uint32(b[:]))
+		*size += 4
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	*size += length
+	return decodeLevels(enc, numValues, nil, data, decoder)
+}
+
+// discard skips n bytes of the column chunk, seeking past the data that was not
+// buffered yet.
+func (f *filePages) discard(n int64) error {
+	if buffered := int64(f.rbuf.Buffered()); n > buffered {
+		f.rbuf.Discard(int(buffered))
+		if _, err := f.section.Seek(n-buffered, io.SeekCurrent); err != nil {
+			return err
+		}
+		f.rbuf.Reset(&f.section)
+		return nil
+	}
+	_, err := f.rbuf.Discard(int(n))
+	return err
+}
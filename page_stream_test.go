@@ -0,0 +1,147 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/compress"
+)
+
+func TestStreamingPageSize(t *testing.T) {
+	type Row struct {
+		ID    int64    `parquet:"id"`
+		Score float64  `parquet:"score"`
+		Name  string   `parquet:"name"`
+		Note  *string  `parquet:"note,optional,plain"`
+		Tags  []string `parquet:"tags,list,plain"`
+		Kind  string   `parquet:"kind,dict"`
+		Label *string  `parquet:"label,optional,dict"`
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	rows := make([]Row, 20000)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    prng.Int63(),
+			Score: prng.Float64(),
+			Name:  fmt.Sprintf("name-%d", prng.Intn(100)),
+			Kind:  fmt.Sprintf("kind-%d", prng.Intn(20)),
+		}
+		if i%3 != 0 {
+			note := fmt.Sprintf("note-%d", i)
+			rows[i].Note = &note
+		}
+		if i%5 != 0 {
+			label := fmt.Sprintf("label-%d", i%7)
+			rows[i].Label = &label
+		}
+		for j := i % 4; j > 0; j-- {
+			rows[i].Tags = append(rows[i].Tags, fmt.Sprintf("tag-%d", prng.Intn(10)))
+		}
+	}
+
+	codecs := []compress.Codec{
+		&parquet.Uncompressed,
+		&parquet.Snappy,
+		&parquet.Gzip,
+		&parquet.Zstd,
+	}
+
+	for _, codec := range codecs {
+		for _, version := range []int{1, 2} {
+			t.Run(fmt.Sprintf("%s/v%d", codec, version), func(t *testing.T) {
+				b := new(bytes.Buffer)
+				w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+					parquet.Compression(codec),
+					parquet.DataPageVersion(version),
+					parquet.PageBufferSize(1<<20),
+				)
+				for _, row := range rows {
+					if err := w.Write(row); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()),
+					parquet.StreamingPageSize(16*1024),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Pages are streamed when they are larger than the threshold,
+				// in which case their bounds are not known.
+				streamed, streamedIndexes := 0, 0
+				for _, chunk := range f.RowGroups()[0].ColumnChunks() {
+					pages := chunk.Pages()
+					for {
+						p, err := pages.ReadPage()
+						if err != nil {
+							if err != io.EOF {
+								t.Fatal(err)
+							}
+							break
+						}
+						if _, _, ok := p.Bounds(); !ok && p.NumValues() > 0 {
+							streamed++
+							if p.Dictionary() != nil {
+								streamedIndexes++
+							}
+						}
+						values := p.Values()
+						n := int64(0)
+						for {
+							c, err := values.ReadValues(make([]parquet.Value, 1000))
+							n += int64(c)
+							if err == io.EOF {
+								break
+							}
+							if err != nil {
+								t.Fatal(err)
+							}
+						}
+						if n != p.NumValues() {
+							t.Fatalf("wrong number of values read from page: want=%d got=%d", p.NumValues(), n)
+						}
+						if n := p.Buffer().NumValues(); n != p.NumValues() {
+							t.Fatalf("wrong number of values in buffered page: want=%d got=%d", p.NumValues(), n)
+						}
+					}
+					pages.Close()
+				}
+				if streamed == 0 {
+					t.Fatal("no pages were streamed")
+				}
+				if streamedIndexes == 0 {
+					t.Fatal("no dictionary encoded pages were streamed")
+				}
+
+				r := parquet.NewReader(f)
+				for i, want := range rows {
+					got := Row{}
+					if err := r.Read(&got); err != nil {
+						t.Fatalf("reading row %d: %v", i, err)
+					}
+					if len(got.Tags) == 0 {
+						got.Tags = nil
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want, got)
+					}
+				}
+			})
+		}
+	}
+
+	if _, err := parquet.NewFileConfig(parquet.StreamingPageSize(-1)); err == nil {
+		t.Error("negative streaming page size was accepted")
+	}
+}
@@ -31,3 +31,10 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
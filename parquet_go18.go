@@ -21,7 +21,7 @@ func Read[T any](r io.ReaderAt, size int64, options ...ReaderOption) (rows []T,
 	if err != nil {
 		return nil, err
 	}
-	file, err := OpenFile(r, size)
+	file, err := OpenFile(r, size, config.fileOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,170 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// partitionSamplesPerRange is the number of rows sampled for each range
+	// when computing the bounds of key ranges, see RangePartitionBounds.
+	partitionSamplesPerRange = 100
+)
+
+// RangePartitionBounds computes the bounds of numRanges key ranges holding
+// approximately the same number of rows of the row groups, when ordered by
+// the given sorting columns. The bounds are returned as rows which can be
+// passed to MergeRangePartitions.
+//
+// The bounds are the quantiles of a sample of the rows selected with
+// SampleRows, which reads only the pages holding the sampled rows, so the
+// sizes of the ranges are estimates. The prng is used to select the rows, the
+// functions of the math/rand package are used if it is nil.
+//
+// The row groups must have the same schema. The function returns at most
+// numRanges-1 bounds, and fewer if the row groups hold fewer distinct keys.
+func RangePartitionBounds(rowGroups []RowGroup, numRanges int, sortingColumns []SortingColumn, prng *rand.Rand) ([]Row, error) {
+	if len(rowGroups) == 0 || numRanges <= 1 {
+		return nil, nil
+	}
+	schema := rowGroups[0].Schema()
+	for _, rowGroup := range rowGroups[1:] {
+		if !nodesAreEqual(schema, rowGroup.Schema()) {
+			return nil, ErrRowGroupSchemaMismatch
+		}
+	}
+
+	samples, err := SampleRows(rowGroups, numRanges*partitionSamplesPerRange, prng)
+	if err != nil {
+		return nil, err
+	}
+	compare := compareRowsFuncOf(schema, sortingColumns)
+	sort.SliceStable(samples, func(i, j int) bool { return compare(samples[i], samples[j]) < 0 })
+
+	bounds := make([]Row, 0, numRanges-1)
+	for i := 1; i < numRanges; i++ {
+		j := i * len(samples) / numRanges
+		if j == 0 || j >= len(samples) {
+			continue
+		}
+		// Equal bounds would produce empty ranges, and a bound equal to the
+		// first sample would make the first range empty.
+		if n := len(bounds); (n > 0 && compare(bounds[n-1], samples[j]) == 0) || compare(samples[0], samples[j]) == 0 {
+			continue
+		}
+		bounds = append(bounds, samples[j])
+	}
+	return bounds, nil
+}
+
+// MergeRangePartitions merges the row groups like MergeRowGroups, and writes
+// the merged rows to the writers by ranges of keys, in a single pass over the
+// rows. This is useful to compact sorted files into several output files
+// holding disjoint ranges of keys, for example:
+//
+//	bounds, err := parquet.RangePartitionBounds(rowGroups, len(writers), sortingColumns, nil)
+//	...
+//	numRows, err := parquet.MergeRangePartitions(writers, rowGroups, bounds,
+//		parquet.SortingColumns(sortingColumns...),
+//	)
+//
+// The options must configure the sorting columns that the keys are made of.
+// The bounds are rows of the schema of the merged row groups, ordered by the
+// sorting columns, only the values of the sorting columns are compared. There
+// must be at most one more writer than bounds: rows ordered before the first
+// bound are written to the first writer, rows ordered at or after the bound at
+// index i and before the next bound are written to the writer at index i+1.
+// When there are fewer bounds, the last writers receive no rows.
+//
+// The function returns the number of rows written to each writer. The writers
+// are not flushed or closed, the program must close them to complete the
+// output files.
+func MergeRangePartitions(writers []RowWriter, rowGroups []RowGroup, bounds []Row, options ...RowGroupOption) ([]int64, error) {
+	if len(bounds) >= len(writers) {
+		return nil, fmt.Errorf("cannot partition rows in %d ranges with %d writers", len(bounds)+1, len(writers))
+	}
+
+	if len(rowGroups) == 0 {
+		return make([]int64, len(writers)), nil
+	}
+
+	merged, err := MergeRowGroups(rowGroups, options...)
+	if err != nil {
+		return nil, err
+	}
+	sortingColumns := merged.SortingColumns()
+	if len(sortingColumns) == 0 {
+		return nil, fmt.Errorf("cannot partition rows by range without sorting columns")
+	}
+
+	compare := compareRowsFuncOf(merged.Schema(), sortingColumns)
+	for i := 1; i < len(bounds); i++ {
+		if compare(bounds[i-1], bounds[i]) > 0 {
+			return nil, fmt.Errorf("bounds of range partitions are not sorted: bound at index %d is ordered before bound at index %d", i, i-1)
+		}
+	}
+
+	rows := merged.Rows()
+	defer rows.Close()
+
+	numRows := make([]int64, len(writers))
+	buffer := make([]Row, defaultRowBufferSize)
+	partition := 0
+
+	for {
+		n, err := rows.ReadRows(buffer)
+
+		for i := 0; i < n; {
+			// The rows are sorted, each range of rows is written to its writer
+			// in a single call.
+			for partition < len(bounds) && compare(buffer[i], bounds[partition]) >= 0 {
+				partition++
+			}
+			j := i + 1
+			if partition < len(bounds) {
+				for j < n && compare(buffer[j], bounds[partition]) < 0 {
+					j++
+				}
+			} else {
+				j = n
+			}
+
+			written, err := writers[partition].WriteRows(buffer[i:j])
+			numRows[partition] += int64(written)
+			if err != nil {
+				return numRows, fmt.Errorf("writing rows of range partition %d: %w", partition, err)
+			}
+			i = j
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return numRows, nil
+			}
+			return numRows, err
+		}
+	}
+}
+
+// compareRowsFuncOf returns a function comparing rows of the schema by the
+// values of the sorting columns.
+func compareRowsFuncOf(schema *Schema, sortingColumns []SortingColumn) func(a, b Row) int {
+	columns := mergeSortColumnsOf(schema, sortingColumns)
+	return func(a, b Row) int {
+		for i := range columns {
+			c := &columns[i]
+			var cmp int
+			if c.rowKey != nil {
+				cmp = c.compareValue(c.rowKey(a), c.rowKey(b))
+			} else {
+				cmp = c.compare(a.valuesOf(c.columnIndex), b.valuesOf(c.columnIndex))
+			}
+			if cmp != 0 {
+				return cmp
+			}
+		}
+		return 0
+	}
+}
@@ -0,0 +1,200 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestMergeRangePartitions(t *testing.T) {
+	type Row struct {
+		Key   string `parquet:"key"`
+		Time  int64  `parquet:"time"`
+		Value int64  `parquet:"value"`
+	}
+
+	sortingColumns := []parquet.SortingColumn{
+		parquet.Ascending("key"),
+		parquet.Descending("time"),
+	}
+	less := func(a, b Row) bool {
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Time > b.Time
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	rowGroups := make([]parquet.RowGroup, 3)
+	want := []Row{}
+	for i := range rowGroups {
+		buffer := parquet.NewBuffer(
+			parquet.SchemaOf(new(Row)),
+			parquet.SortingColumns(sortingColumns...),
+		)
+		for j := 0; j < 1000; j++ {
+			row := Row{
+				Key:   fmt.Sprintf("key-%03d", prng.Intn(200)),
+				Time:  prng.Int63n(1e6),
+				Value: int64(1000*i + j),
+			}
+			if err := buffer.Write(&row); err != nil {
+				t.Fatal(err)
+			}
+			want = append(want, row)
+		}
+		sort.Sort(buffer)
+		rowGroups[i] = buffer
+	}
+	sort.SliceStable(want, func(i, j int) bool { return less(want[i], want[j]) })
+
+	for _, test := range []struct {
+		scenario string
+		bounds   func() ([]parquet.Row, error)
+		empty    int
+	}{
+		{
+			scenario: "computed bounds",
+			bounds: func() ([]parquet.Row, error) {
+				return parquet.RangePartitionBounds(rowGroups, 4, sortingColumns, rand.New(rand.NewSource(1)))
+			},
+		},
+		{
+			scenario: "provided bounds",
+			bounds: func() ([]parquet.Row, error) {
+				schema := parquet.SchemaOf(new(Row))
+				return []parquet.Row{
+					schema.Deconstruct(nil, &Row{Key: "key-050", Time: 500000}),
+					schema.Deconstruct(nil, &Row{Key: "key-100"}),
+				}, nil
+			},
+			empty: 1,
+		},
+		{
+			scenario: "no bounds",
+			bounds:   func() ([]parquet.Row, error) { return nil, nil },
+			empty:    3,
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			bounds, err := test.bounds()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			outputs := make([]*bytes.Buffer, 4)
+			writers := make([]*parquet.Writer, len(outputs))
+			rowWriters := make([]parquet.RowWriter, len(outputs))
+			for i := range outputs {
+				outputs[i] = new(bytes.Buffer)
+				writers[i] = parquet.NewWriter(outputs[i],
+					parquet.SchemaOf(new(Row)),
+					parquet.SortingColumns(sortingColumns...),
+				)
+				rowWriters[i] = writers[i]
+			}
+
+			numRows, err := parquet.MergeRangePartitions(rowWriters, rowGroups, bounds,
+				parquet.SortingColumns(sortingColumns...),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range writers {
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got := []Row{}
+			empty := 0
+			for i, output := range outputs {
+				f, err := parquet.OpenFile(bytes.NewReader(output.Bytes()), int64(output.Len()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if f.NumRows() != numRows[i] {
+					t.Errorf("wrong number of rows in output %d: want=%d got=%d", i, numRows[i], f.NumRows())
+				}
+				if f.NumRows() == 0 {
+					empty++
+					continue
+				}
+				r := parquet.NewReader(f)
+				for j := int64(0); j < f.NumRows(); j++ {
+					row := Row{}
+					if err := r.Read(&row); err != nil {
+						t.Fatal(err)
+					}
+					// The rows of each output follow the rows of the previous
+					// outputs, so the ranges of keys are disjoint.
+					if n := len(got); n > 0 && less(row, got[n-1]) {
+						t.Fatalf("row %d of output %d is out of order: %+v < %+v", j, i, row, got[n-1])
+					}
+					got = append(got, row)
+				}
+				r.Close()
+			}
+
+			if empty != test.empty {
+				t.Errorf("wrong number of empty outputs: want=%d got=%d", test.empty, empty)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("wrong number of rows: want=%d got=%d", len(want), len(got))
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMergeRangePartitionsErrors(t *testing.T) {
+	type Row struct {
+		Key int64 `parquet:"key"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+	buffer := parquet.NewBuffer(schema, parquet.SortingColumns(parquet.Ascending("key")))
+	for i := 0; i < 10; i++ {
+		buffer.Write(&Row{Key: int64(i)})
+	}
+	rowGroups := []parquet.RowGroup{buffer}
+	writers := []parquet.RowWriter{
+		parquet.NewWriter(new(bytes.Buffer), schema),
+		parquet.NewWriter(new(bytes.Buffer), schema),
+	}
+	bound := func(key int64) parquet.Row { return schema.Deconstruct(nil, &Row{Key: key}) }
+
+	for _, test := range []struct {
+		scenario string
+		bounds   []parquet.Row
+		options  []parquet.RowGroupOption
+	}{
+		{
+			scenario: "too many bounds",
+			bounds:   []parquet.Row{bound(3), bound(6)},
+			options:  []parquet.RowGroupOption{parquet.SortingColumns(parquet.Ascending("key"))},
+		},
+		{
+			scenario: "no sorting columns",
+			bounds:   []parquet.Row{bound(3)},
+		},
+		{
+			scenario: "unsorted bounds",
+			bounds:   []parquet.Row{bound(6), bound(3)},
+			options:  []parquet.RowGroupOption{parquet.SortingColumns(parquet.Ascending("key"))},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			if _, err := parquet.MergeRangePartitions(writers, rowGroups, test.bounds, test.options...); err == nil {
+				t.Error("no error partitioning the rows")
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/segmentio/parquet-go/protobuf
+
+go 1.18
+
+require (
+	github.com/segmentio/parquet-go v0.0.0
+	google.golang.org/protobuf v1.28.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.0.3 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/klauspost/compress v1.15.5 // indirect
+	github.com/mattn/go-runewidth v0.0.9 // indirect
+	github.com/olekukonko/tablewriter v0.0.5 // indirect
+	github.com/pierrec/lz4/v4 v4.1.9 // indirect
+	github.com/segmentio/encoding v0.3.5 // indirect
+	golang.org/x/sys v0.0.0-20211110154304-99a53858aa08 // indirect
+)
+
+replace github.com/segmentio/parquet-go => ../
@@ -0,0 +1,526 @@
+// Package protobuf maps protobuf messages to parquet schemas and rows.
+//
+// The package is a separate module so programs which do not use protobuf do
+// not have to depend on it.
+package protobuf
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/segmentio/parquet-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Codec converts protobuf messages of a message descriptor to and from parquet
+// rows. Messages are accessed with the protoreflect API, which means that both
+// generated and dynamic messages (such as those of the dynamicpb package) are
+// supported.
+//
+// The message descriptor is mapped to a parquet schema of the same name where:
+//
+//   - messages are groups, repeated fields are lists, and map fields are maps
+//   - fields which track presence (proto2 optional fields, proto3 optional
+//     fields, message fields, and members of oneofs) are optional columns,
+//     other fields are required and hold their default value when unset
+//   - bool, int32, int64, uint32, uint64, float, and double fields are columns
+//     of the matching types (signed and fixed variants included), string
+//     fields are STRING columns, and bytes fields are byte arrays
+//   - enums are ENUM columns holding the names of the values, or the decimal
+//     representation of numbers which have no name
+//
+// The well-known types google.protobuf.Timestamp are mapped to TIMESTAMP
+// columns with a nanosecond unit, google.protobuf.Duration to INT(64) columns
+// holding nanoseconds, and the wrapper types (google.protobuf.StringValue,
+// google.protobuf.Int64Value, etc...) to optional columns of the wrapped type.
+// Note that parquet groups order their fields by name, which may differ from
+// the order of the fields in the message descriptor.
+//
+// Codec values are safe to use concurrently from multiple goroutines.
+type Codec struct {
+	desc   protoreflect.MessageDescriptor
+	schema *parquet.Schema
+	root   *message
+}
+
+// NewCodec constructs a codec for messages of the given descriptor.
+//
+// The function returns an error if the message uses features which cannot be
+// mapped to a parquet schema, such as recursive messages.
+func NewCodec(desc protoreflect.MessageDescriptor) (*Codec, error) {
+	c := &codecBuilder{messages: make(map[protoreflect.FullName]bool)}
+	root, err := c.message(desc)
+	if err != nil {
+		return nil, err
+	}
+	return &Codec{
+		desc:   desc,
+		schema: parquet.NewSchema(string(desc.Name()), root.node),
+		root:   root,
+	}, nil
+}
+
+// Schema returns the parquet schema that the message descriptor was mapped to.
+func (c *Codec) Schema() *parquet.Schema { return c.schema }
+
+// Deconstruct appends the message as a parquet row to row. The row does not
+// retain references to the message.
+//
+// The method returns an error if the message is not of the descriptor that the
+// codec was created for.
+func (c *Codec) Deconstruct(row parquet.Row, msg proto.Message) (parquet.Row, error) {
+	m := msg.ProtoReflect()
+	if err := c.check(m); err != nil {
+		return row, err
+	}
+	return c.schema.DeconstructMap(row, c.root.deconstruct(m))
+}
+
+// Reconstruct sets the fields of the message to the values of the parquet
+// row. Fields of the message which have no values in the row are cleared.
+//
+// The method returns an error if the message is not of the descriptor that the
+// codec was created for, or if the row does not match the schema.
+func (c *Codec) Reconstruct(msg proto.Message, row parquet.Row) error {
+	m := msg.ProtoReflect()
+	if err := c.check(m); err != nil {
+		return err
+	}
+	v, err := c.schema.ReconstructMap(row)
+	if err != nil {
+		return err
+	}
+	proto.Reset(msg)
+	return c.root.reconstruct(m, v)
+}
+
+func (c *Codec) check(m protoreflect.Message) error {
+	if name := m.Descriptor().FullName(); name != c.desc.FullName() {
+		return fmt.Errorf("protobuf message %s does not match the codec of %s", name, c.desc.FullName())
+	}
+	return nil
+}
+
+// message is the mapping of a protobuf message to a parquet group. The values
+// of messages are converted to and from the generic representation of
+// parquet.Schema.DeconstructMap and parquet.Schema.ReconstructMap.
+type message struct {
+	node   parquet.Group
+	fields []*field
+}
+
+type field struct {
+	desc protoreflect.FieldDescriptor
+	name string
+	// The kind of the values of the field, or of the values of the map for
+	// map fields.
+	value *value
+	// The kind of the keys of map fields.
+	key *value
+}
+
+// value is the mapping of the values of a protobuf field to parquet nodes.
+type value struct {
+	node parquet.Node
+	// The kind of the protobuf values, and the descriptors of messages and
+	// enums.
+	kind      protoreflect.Kind
+	message   *message
+	enum      protoreflect.EnumDescriptor
+	wellKnown string
+	// Field of the wrapper types holding the wrapped value.
+	wrapped protoreflect.FieldDescriptor
+}
+
+type codecBuilder struct {
+	messages map[protoreflect.FullName]bool
+}
+
+func (c *codecBuilder) message(desc protoreflect.MessageDescriptor) (*message, error) {
+	if c.messages[desc.FullName()] {
+		return nil, fmt.Errorf("recursive message %s is not supported", desc.FullName())
+	}
+	c.messages[desc.FullName()] = true
+	defer delete(c.messages, desc.FullName())
+
+	m := &message{node: make(parquet.Group)}
+	fields := desc.Fields()
+
+	for i := 0; i < fields.Len(); i++ {
+		f, err := c.field(fields.Get(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s → %w", fields.Get(i).Name(), err)
+		}
+		if _, exists := m.node[f.name]; exists {
+			return nil, fmt.Errorf("duplicate field %q", f.name)
+		}
+		m.fields = append(m.fields, f)
+	}
+
+	for _, f := range m.fields {
+		var node parquet.Node
+		switch {
+		case f.desc.IsMap():
+			node = parquet.Map(f.key.node, f.value.node)
+		case f.desc.IsList():
+			node = parquet.List(f.value.node)
+		case f.desc.HasPresence() || f.value.wrapped != nil:
+			node = parquet.Optional(f.value.node)
+		default:
+			node = f.value.node
+		}
+		m.node[f.name] = node
+	}
+	return m, nil
+}
+
+func (c *codecBuilder) field(desc protoreflect.FieldDescriptor) (f *field, err error) {
+	f = &field{desc: desc, name: string(desc.Name())}
+	if desc.IsMap() {
+		if f.key, err = c.value(desc.MapKey()); err != nil {
+			return nil, err
+		}
+		f.value, err = c.value(desc.MapValue())
+	} else {
+		f.value, err = c.value(desc)
+	}
+	return f, err
+}
+
+func (c *codecBuilder) value(desc protoreflect.FieldDescriptor) (*value, error) {
+	v := &value{kind: desc.Kind()}
+
+	switch desc.Kind() {
+	case protoreflect.BoolKind:
+		v.node = parquet.Leaf(parquet.BooleanType)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v.node = parquet.Int(32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v.node = parquet.Int(64)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v.node = parquet.Uint(32)
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v.node = parquet.Uint(64)
+	case protoreflect.FloatKind:
+		v.node = parquet.Leaf(parquet.FloatType)
+	case protoreflect.DoubleKind:
+		v.node = parquet.Leaf(parquet.DoubleType)
+	case protoreflect.StringKind:
+		v.node = parquet.String()
+	case protoreflect.BytesKind:
+		v.node = parquet.Leaf(parquet.ByteArrayType)
+	case protoreflect.EnumKind:
+		v.node = parquet.Enum()
+		v.enum = desc.Enum()
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if c.wellKnown(v, desc.Message()) {
+			break
+		}
+		m, err := c.message(desc.Message())
+		if err != nil {
+			return nil, err
+		}
+		v.node, v.message = m.node, m
+	default:
+		return nil, fmt.Errorf("unsupported protobuf kind %s", desc.Kind())
+	}
+	return v, nil
+}
+
+// wellKnown configures v to map values of the well-known type of desc, it
+// returns false if desc is not a well-known type.
+func (c *codecBuilder) wellKnown(v *value, desc protoreflect.MessageDescriptor) bool {
+	switch name := desc.FullName(); name {
+	case "google.protobuf.Timestamp":
+		v.node, v.wellKnown = parquet.Timestamp(parquet.Nanosecond), string(name)
+	case "google.protobuf.Duration":
+		v.node, v.wellKnown = parquet.Int(64), string(name)
+	case "google.protobuf.BoolValue",
+		"google.protobuf.Int32Value",
+		"google.protobuf.Int64Value",
+		"google.protobuf.UInt32Value",
+		"google.protobuf.UInt64Value",
+		"google.protobuf.FloatValue",
+		"google.protobuf.DoubleValue",
+		"google.protobuf.StringValue",
+		"google.protobuf.BytesValue":
+		wrapped := desc.Fields().ByName("value")
+		w, err := c.value(wrapped)
+		if err != nil {
+			return false
+		}
+		v.node, v.wellKnown, v.wrapped = w.node, string(name), wrapped
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *message) deconstruct(msg protoreflect.Message) map[string]interface{} {
+	values := make(map[string]interface{}, len(m.fields))
+
+	for _, f := range m.fields {
+		switch {
+		case f.desc.IsMap():
+			entries := msg.Get(f.desc).Map()
+			keys := make([]protoreflect.MapKey, 0, entries.Len())
+			entries.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, k)
+				return true
+			})
+			// Map fields have no defined order, the entries are sorted by key
+			// so messages produce the same rows.
+			sort.Slice(keys, func(i, j int) bool { return lessMapKey(keys[i], keys[j]) })
+			list := make([]interface{}, len(keys))
+			for i, k := range keys {
+				list[i] = map[string]interface{}{
+					"key":   f.key.deconstruct(k.Value()),
+					"value": f.value.deconstruct(entries.Get(k)),
+				}
+			}
+			values[f.name] = list
+
+		case f.desc.IsList():
+			items := msg.Get(f.desc).List()
+			list := make([]interface{}, items.Len())
+			for i := range list {
+				list[i] = f.value.deconstruct(items.Get(i))
+			}
+			values[f.name] = list
+
+		case f.desc.HasPresence() && !msg.Has(f.desc):
+			values[f.name] = nil
+
+		default:
+			values[f.name] = f.value.deconstruct(msg.Get(f.desc))
+		}
+	}
+
+	return values
+}
+
+func (v *value) deconstruct(x protoreflect.Value) interface{} {
+	switch v.kind {
+	case protoreflect.EnumKind:
+		number := x.Enum()
+		if value := v.enum.Values().ByNumber(number); value != nil {
+			return string(value.Name())
+		}
+		return fmt.Sprint(int32(number))
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		m := x.Message()
+		switch v.wellKnown {
+		case "":
+			return v.message.deconstruct(m)
+		case "google.protobuf.Timestamp", "google.protobuf.Duration":
+			fields := m.Descriptor().Fields()
+			seconds := m.Get(fields.ByName("seconds")).Int()
+			nanos := m.Get(fields.ByName("nanos")).Int()
+			return seconds*1e9 + nanos
+		default:
+			return m.Get(v.wrapped).Interface()
+		}
+	default:
+		return x.Interface()
+	}
+}
+
+func (m *message) reconstruct(msg protoreflect.Message, values map[string]interface{}) error {
+	for _, f := range m.fields {
+		x := values[f.name]
+		if x == nil {
+			continue
+		}
+
+		switch {
+		case f.desc.IsMap():
+			entries := msg.Mutable(f.desc).Map()
+			for _, e := range x.([]interface{}) {
+				entry := e.(map[string]interface{})
+				k, err := f.key.reconstruct(entries.NewValue, entry["key"])
+				if err != nil {
+					return fmt.Errorf("%s → %w", f.name, err)
+				}
+				v, err := f.value.reconstruct(entries.NewValue, entry["value"])
+				if err != nil {
+					return fmt.Errorf("%s → %w", f.name, err)
+				}
+				entries.Set(k.MapKey(), v)
+			}
+
+		case f.desc.IsList():
+			items := msg.Mutable(f.desc).List()
+			for _, item := range x.([]interface{}) {
+				v, err := f.value.reconstruct(items.NewElement, item)
+				if err != nil {
+					return fmt.Errorf("%s → %w", f.name, err)
+				}
+				items.Append(v)
+			}
+
+		default:
+			v, err := f.value.reconstruct(func() protoreflect.Value { return msg.NewField(f.desc) }, x)
+			if err != nil {
+				return fmt.Errorf("%s → %w", f.name, err)
+			}
+			msg.Set(f.desc, v)
+		}
+	}
+	return nil
+}
+
+// reconstruct converts the generic representation x of a value to a protobuf
+// value, newValue is used to construct values of message types.
+func (v *value) reconstruct(newValue func() protoreflect.Value, x interface{}) (protoreflect.Value, error) {
+	switch v.kind {
+	case protoreflect.BoolKind:
+		b, _ := x.(bool)
+		return protoreflect.ValueOfBool(b), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, _ := x.(int32)
+		return protoreflect.ValueOfInt32(i), nil
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, _ := x.(int64)
+		return protoreflect.ValueOfInt64(i), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		i, _ := x.(int32)
+		return protoreflect.ValueOfUint32(uint32(i)), nil
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		i, _ := x.(int64)
+		return protoreflect.ValueOfUint64(uint64(i)), nil
+	case protoreflect.FloatKind:
+		f, _ := x.(float32)
+		return protoreflect.ValueOfFloat32(f), nil
+	case protoreflect.DoubleKind:
+		f, _ := x.(float64)
+		return protoreflect.ValueOfFloat64(f), nil
+	case protoreflect.StringKind:
+		s, _ := x.(string)
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		b, _ := x.([]byte)
+		return protoreflect.ValueOfBytes(b), nil
+	case protoreflect.EnumKind:
+		s, _ := x.(string)
+		if value := v.enum.Values().ByName(protoreflect.Name(s)); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		var number int32
+		if _, err := fmt.Sscan(s, &number); err != nil {
+			return protoreflect.Value{}, fmt.Errorf("invalid value %q of enum %s", s, v.enum.FullName())
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(number)), nil
+	}
+
+	m := newValue()
+	msg := m.Message()
+	switch v.wellKnown {
+	case "":
+		values, _ := x.(map[string]interface{})
+		if err := v.message.reconstruct(msg, values); err != nil {
+			return m, err
+		}
+	case "google.protobuf.Timestamp", "google.protobuf.Duration":
+		t, _ := x.(int64)
+		seconds, nanos := t/1e9, t%1e9
+		if nanos < 0 && v.wellKnown == "google.protobuf.Timestamp" {
+			// Timestamps have positive nanoseconds, even before the epoch.
+			seconds, nanos = seconds-1, nanos+1e9
+		}
+		fields := msg.Descriptor().Fields()
+		msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(seconds))
+		msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(nanos)))
+	default:
+		w := &value{kind: v.wrapped.Kind()}
+		wrapped, err := w.reconstruct(nil, x)
+		if err != nil {
+			return m, err
+		}
+		msg.Set(v.wrapped, wrapped)
+	}
+	return m, nil
+}
+
+func lessMapKey(a, b protoreflect.MapKey) bool {
+	switch x := a.Interface().(type) {
+	case bool:
+		return !x && b.Bool()
+	case int32, int64:
+		return a.Int() < b.Int()
+	case uint32, uint64:
+		return a.Uint() < b.Uint()
+	case string:
+		return x < b.String()
+	default:
+		return false
+	}
+}
+
+// Writer writes protobuf messages to a parquet file.
+//
+// The schema of the file is the one of the codec, which is derived from the
+// message descriptor that the writer was created with.
+type Writer struct {
+	codec  *Codec
+	writer *parquet.Writer
+	row    parquet.Row
+	rows   []parquet.Row
+}
+
+// NewWriter constructs a writer of protobuf messages of the given descriptor to
+// output.
+//
+// The function returns an error if the message descriptor cannot be mapped to
+// a parquet schema, or if the options already configure a schema.
+func NewWriter(output io.Writer, desc protoreflect.MessageDescriptor, options ...parquet.WriterOption) (*Writer, error) {
+	codec, err := NewCodec(desc)
+	if err != nil {
+		return nil, err
+	}
+	config, err := parquet.NewWriterConfig(options...)
+	if err != nil {
+		return nil, err
+	}
+	if config.Schema != nil {
+		return nil, fmt.Errorf("protobuf writers cannot be configured with a parquet schema")
+	}
+	config.Schema = codec.Schema()
+	return &Writer{codec: codec, writer: parquet.NewWriter(output, config)}, nil
+}
+
+// Schema returns the parquet schema of the written file.
+func (w *Writer) Schema() *parquet.Schema { return w.codec.Schema() }
+
+// Write writes the messages to the parquet file.
+//
+// The method returns the number of messages written, which is less than the
+// number of messages when an error is returned.
+func (w *Writer) Write(msgs ...proto.Message) (int, error) {
+	for i := range w.rows {
+		w.rows[i] = nil
+	}
+	w.rows = w.rows[:0]
+	w.row = w.row[:0]
+
+	for _, msg := range msgs {
+		var err error
+		start := len(w.row)
+		w.row, err = w.codec.Deconstruct(w.row, msg)
+		if err != nil {
+			n, _ := w.writer.WriteRows(w.rows)
+			return n, err
+		}
+		w.rows = append(w.rows, w.row[start:len(w.row):len(w.row)])
+	}
+
+	return w.writer.WriteRows(w.rows)
+}
+
+// Flush flushes the buffered messages to a new row group of the parquet file.
+func (w *Writer) Flush() error { return w.writer.Flush() }
+
+// Close flushes the buffered messages and writes the footer of the parquet
+// file.
+func (w *Writer) Close() error { return w.writer.Close() }
@@ -0,0 +1,324 @@
+package protobuf_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/protobuf"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Importing the well-known types registers their descriptors.
+var (
+	_ = durationpb.New
+	_ = timestamppb.New
+	_ = wrapperspb.String
+)
+
+func field(name string, number int32, label descriptorpb.FieldDescriptorProto_Label, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    label.Enum(),
+		Type:     typ.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+const (
+	optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+)
+
+func newFile(t *testing.T, messages ...*descriptorpb.DescriptorProto) protoreflect.FileDescriptor {
+	t.Helper()
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("event.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		Dependency: []string{
+			"google/protobuf/duration.proto",
+			"google/protobuf/timestamp.proto",
+			"google/protobuf/wrappers.proto",
+		},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Level"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("DEBUG"), Number: proto.Int32(0)},
+				{Name: proto.String("INFO"), Number: proto.Int32(1)},
+				{Name: proto.String("ERROR"), Number: proto.Int32(2)},
+			},
+		}},
+		MessageType: messages,
+	}
+	fd, err := protodesc.NewFile(file, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd
+}
+
+func eventDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	T := descriptorpb.FieldDescriptorProto_Type_value
+	typ := func(name string) descriptorpb.FieldDescriptorProto_Type {
+		return descriptorpb.FieldDescriptorProto_Type(T["TYPE_"+name])
+	}
+	fd := newFile(t,
+		&descriptorpb.DescriptorProto{
+			Name: proto.String("Event"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, optional, typ("UINT64"), ""),
+				field("name", 2, optional, typ("STRING"), ""),
+				field("level", 3, optional, typ("ENUM"), ".test.Level"),
+				field("time", 4, optional, typ("MESSAGE"), ".google.protobuf.Timestamp"),
+				field("elapsed", 5, optional, typ("MESSAGE"), ".google.protobuf.Duration"),
+				field("source", 6, optional, typ("MESSAGE"), ".google.protobuf.StringValue"),
+				field("tags", 7, repeated, typ("STRING"), ""),
+				field("labels", 8, repeated, typ("MESSAGE"), ".test.Event.LabelsEntry"),
+				field("user", 9, optional, typ("MESSAGE"), ".test.User"),
+				field("payload", 10, optional, typ("BYTES"), ""),
+				field("score", 11, optional, typ("DOUBLE"), ""),
+				field("delta", 12, optional, typ("SINT32"), ""),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("LabelsEntry"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("key", 1, optional, typ("STRING"), ""),
+					field("value", 2, optional, typ("INT64"), ""),
+				},
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}},
+		},
+		&descriptorpb.DescriptorProto{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("email", 1, optional, typ("STRING"), ""),
+				field("roles", 2, repeated, typ("ENUM"), ".test.Level"),
+			},
+		},
+	)
+	return fd.Messages().ByName("Event")
+}
+
+func TestCodecSchema(t *testing.T) {
+	codec, err := protobuf.NewCodec(eventDescriptor(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const want = `message Event {
+	required int32 delta (INT(32,true));
+	optional int64 elapsed (INT(64,true));
+	required int64 id (INT(64,false));
+	required group labels (MAP) {
+		repeated group key_value {
+			required binary key (STRING);
+			required int64 value (INT(64,true));
+		}
+	}
+	required binary level (ENUM);
+	required binary name (STRING);
+	required binary payload;
+	required double score;
+	optional binary source (STRING);
+	required group tags (LIST) {
+		repeated group list {
+			required binary element (STRING);
+		}
+	}
+	optional int64 time (TIMESTAMP(isAdjustedToUTC=true,unit=NANOS));
+	optional group user {
+		required binary email (STRING);
+		required group roles (LIST) {
+			repeated group list {
+				required binary element (ENUM);
+			}
+		}
+	}
+}`
+
+	if got := codec.Schema().String(); got != want {
+		t.Errorf("schema mismatch:\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func newEvent(t *testing.T, desc protoreflect.MessageDescriptor, i int) *dynamicpb.Message {
+	t.Helper()
+	m := dynamicpb.NewMessage(desc)
+	f := desc.Fields().ByName
+	m.Set(f("id"), protoreflect.ValueOfUint64(uint64(i)))
+	m.Set(f("name"), protoreflect.ValueOfString(strings.Repeat("x", i)))
+	m.Set(f("level"), protoreflect.ValueOfEnum(protoreflect.EnumNumber(i%3)))
+	m.Set(f("delta"), protoreflect.ValueOfInt32(int32(-i)))
+	m.Set(f("score"), protoreflect.ValueOfFloat64(float64(i)/2))
+
+	if i%2 == 0 {
+		m.Set(f("time"), protoreflect.ValueOfMessage(timestamppb.New(time.Unix(int64(i), int64(i))).ProtoReflect()))
+		m.Set(f("elapsed"), protoreflect.ValueOfMessage(durationpb.New(-time.Duration(i)*100*time.Millisecond).ProtoReflect()))
+		m.Set(f("source"), protoreflect.ValueOfMessage(wrapperspb.String("").ProtoReflect()))
+		m.Set(f("payload"), protoreflect.ValueOfBytes([]byte{byte(i)}))
+
+		user := m.Mutable(f("user")).Message()
+		user.Set(user.Descriptor().Fields().ByName("email"), protoreflect.ValueOfString("user@example.com"))
+		roles := user.Mutable(user.Descriptor().Fields().ByName("roles")).List()
+		roles.Append(protoreflect.ValueOfEnum(2))
+		roles.Append(protoreflect.ValueOfEnum(0))
+	}
+
+	tags := m.Mutable(f("tags")).List()
+	for j := 0; j < i%4; j++ {
+		tags.Append(protoreflect.ValueOfString(strings.Repeat("t", j+1)))
+	}
+	labels := m.Mutable(f("labels")).Map()
+	for j := 0; j < i%3; j++ {
+		labels.Set(protoreflect.ValueOfString(strings.Repeat("l", 3-j)).MapKey(), protoreflect.ValueOfInt64(int64(j)))
+	}
+	return m
+}
+
+func TestCodecDeconstructReconstruct(t *testing.T) {
+	desc := eventDescriptor(t)
+	codec, err := protobuf.NewCodec(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		want := newEvent(t, desc, i)
+
+		row, err := codec.Deconstruct(nil, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := dynamicpb.NewMessage(desc)
+		if err := codec.Reconstruct(got, row); err != nil {
+			t.Fatal(err)
+		}
+		if !proto.Equal(want, got) {
+			t.Errorf("message mismatch:\nwant: %v\ngot:  %v", want, got)
+		}
+	}
+}
+
+func TestCodecDeconstructSortsMapKeys(t *testing.T) {
+	desc := eventDescriptor(t)
+	codec, err := protobuf.NewCodec(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newEvent(t, desc, 2)
+	row, err := codec.Deconstruct(nil, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	leaf, _ := codec.Schema().Lookup("labels", "key_value", "key")
+	for _, v := range row {
+		if v.Column() == leaf.ColumnIndex {
+			keys = append(keys, v.String())
+		}
+	}
+	if len(keys) != 2 || keys[0] != "ll" || keys[1] != "lll" {
+		t.Errorf("map keys are not sorted: %q", keys)
+	}
+}
+
+func TestCodecMessageMismatch(t *testing.T) {
+	desc := eventDescriptor(t)
+	codec, err := protobuf.NewCodec(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec.Deconstruct(nil, timestamppb.Now()); err == nil {
+		t.Error("expected an error deconstructing a message of another type")
+	}
+}
+
+func TestNewCodecRecursiveMessage(t *testing.T) {
+	fd := newFile(t, &descriptorpb.DescriptorProto{
+		Name: proto.String("Node"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			field("children", 1, repeated, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.Node"),
+		},
+	})
+	if _, err := protobuf.NewCodec(fd.Messages().ByName("Node")); err == nil {
+		t.Error("expected an error creating a codec for a recursive message")
+	}
+}
+
+func TestWriter(t *testing.T) {
+	desc := eventDescriptor(t)
+	buf := new(bytes.Buffer)
+
+	w, err := protobuf.NewWriter(buf, desc, parquet.PageBufferSize(256))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numEvents = 100
+	events := make([]proto.Message, numEvents)
+	for i := range events {
+		events[i] = newEvent(t, desc, i)
+	}
+	if n, err := w.Write(events[:numEvents/2]...); err != nil {
+		t.Fatal(err)
+	} else if n != numEvents/2 {
+		t.Fatalf("wrong number of messages written: want=%d got=%d", numEvents/2, n)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(events[numEvents/2:]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	codec, err := protobuf.NewCodec(desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := parquet.NewReader(bytes.NewReader(buf.Bytes()), codec.Schema())
+	rows := make([]parquet.Row, 10)
+
+	for i := 0; i < numEvents; {
+		n, err := r.ReadRows(rows)
+		for _, row := range rows[:n] {
+			got := dynamicpb.NewMessage(desc)
+			if err := codec.Reconstruct(got, row); err != nil {
+				t.Fatal(err)
+			}
+			if !proto.Equal(events[i], got) {
+				t.Errorf("message %d mismatch:\nwant: %v\ngot:  %v", i, events[i], got)
+			}
+			i++
+		}
+		if err != nil {
+			if err == io.EOF && i == numEvents {
+				break
+			}
+			t.Fatalf("reading rows after %d messages: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// Operator represents the comparison operators that predicates apply to the
+// values of columns.
+type Operator int
+
+const (
+	EqualTo Operator = iota
+	LessThan
+	LessOrEqual
+	GreaterThan
+	GreaterOrEqual
+)
+
+func (op Operator) String() string {
+	switch op {
+	case EqualTo:
+		return "=="
+	case LessThan:
+		return "<"
+	case LessOrEqual:
+		return "<="
+	case GreaterThan:
+		return ">"
+	case GreaterOrEqual:
+		return ">="
+	default:
+		return fmt.Sprintf("Operator(%d)", int(op))
+	}
+}
+
+// match tells whether the result of comparing a column value to the value of a
+// predicate satisfies the operator.
+func (op Operator) match(cmp int) bool {
+	switch op {
+	case EqualTo:
+		return cmp == 0
+	case LessThan:
+		return cmp < 0
+	case LessOrEqual:
+		return cmp <= 0
+	case GreaterThan:
+		return cmp > 0
+	case GreaterOrEqual:
+		return cmp >= 0
+	default:
+		return true
+	}
+}
+
+// Predicate is a condition on the values of a leaf column, which is satisfied
+// by the rows where the column holds a value comparing to Value according to
+// the operator. Null values never satisfy predicates.
+//
+// The value must be of the kind of the column, for example an INT64 value for
+// columns of Go int64 values, or a BYTE_ARRAY value for string columns.
+type Predicate struct {
+	Path  []string
+	Op    Operator
+	Value Value
+}
+
+func (p *Predicate) String() string {
+	return fmt.Sprintf("%s %s %v", columnPath(p.Path), p.Op, p.Value)
+}
+
+// RowRange represents a range of rows in a row group.
+type RowRange struct {
+	FirstRowIndex int64
+	NumRows       int64
+}
+
+// RowGroupMatch represents a row group which may contain rows matching the
+// predicates of a RowGroupFilter.
+type RowGroupMatch struct {
+	// Index of the row group in the list passed to RowGroupFilter.Match.
+	RowGroup int
+	// Ranges of rows of the row group which may match the predicates, in
+	// ascending order. Rows outside of these ranges do not match.
+	Rows []RowRange
+}
+
+// RowGroupFilter selects the row groups, and the ranges of rows within those,
+// that may contain rows matching a conjunction of predicates.
+//
+// The filter consults the metadata of column chunks to eliminate row groups
+// without reading their data pages: the statistics of column chunks, the min
+// and max values of pages recorded in column indexes, and the bloom filters of
+// columns for equality predicates. Dictionary pages may also be read to test
+// whether the column chunks contain values matching the predicates.
+//
+// The selection is conservative: rows outside of the matches are guaranteed not
+// to match the predicates, but rows within the matches may not satisfy them and
+// must still be tested by the application.
+type RowGroupFilter struct {
+	// Predicates that the rows must all satisfy.
+	Predicates []Predicate
+
+	// ReadDictionaries enables reading the dictionary pages of column chunks
+	// where all the data pages are dictionary encoded, in which case the
+	// dictionary holds all the values of the column chunk. This is more
+	// precise than other methods, at the expense of reading and decoding the
+	// dictionaries of all row groups that are not eliminated otherwise.
+	ReadDictionaries bool
+}
+
+// Match returns the list of row groups that may contain rows matching the
+// predicates of the filter, in the order they appear in rowGroups.
+//
+// An error is returned if the predicates reference columns that do not exist
+// in the row groups, or compare them to values of the wrong kind.
+func (f *RowGroupFilter) Match(rowGroups []RowGroup) ([]RowGroupMatch, error) {
+	matches := make([]RowGroupMatch, 0, len(rowGroups))
+
+	for i, rowGroup := range rowGroups {
+		rows, err := f.match(rowGroup)
+		if err != nil {
+			return matches, fmt.Errorf("row group %d: %w", i, err)
+		}
+		if len(rows) > 0 {
+			matches = append(matches, RowGroupMatch{RowGroup: i, Rows: rows})
+		}
+	}
+
+	return matches, nil
+}
+
+func (f *RowGroupFilter) match(rowGroup RowGroup) ([]RowRange, error) {
+	numRows := rowGroup.NumRows()
+	if numRows == 0 {
+		return nil, nil
+	}
+
+	rows := []RowRange{{FirstRowIndex: 0, NumRows: numRows}}
+
+	for i := range f.Predicates {
+		p := &f.Predicates[i]
+
+		chunk, ok := LookupColumnChunk(rowGroup, p.Path...)
+		if !ok {
+			return nil, fmt.Errorf("predicate %s: column not found in schema", p)
+		}
+
+		if p.Value.IsNull() {
+			return nil, fmt.Errorf("predicate %s: cannot compare column values to null", p)
+		}
+		if kind := chunk.Type().Kind(); p.Value.Kind() != kind {
+			return nil, fmt.Errorf("predicate %s: cannot compare value of kind %s to column of kind %s", p, p.Value.Kind(), kind)
+		}
+
+		match, err := f.mayMatch(chunk, p)
+		if err != nil {
+			return nil, fmt.Errorf("predicate %s: %w", p, err)
+		}
+		if !match {
+			return nil, nil
+		}
+
+		if pages, ok := pageRowRanges(chunk, numRows, p); ok {
+			if rows = intersectRowRanges(rows, pages); len(rows) == 0 {
+				return nil, nil
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// mayMatch tests whether the column chunk may contain values matching the
+// predicate, using the statistics, bloom filter and dictionary of the chunk.
+func (f *RowGroupFilter) mayMatch(chunk ColumnChunk, p *Predicate) (bool, error) {
+	typ := chunk.Type()
+
+	if min, max, exact, ok := columnChunkBounds(chunk); ok {
+		if !boundsMayMatch(typ, min, max, exact, p.Op, p.Value) {
+			return false, nil
+		}
+	}
+
+	if p.Op == EqualTo {
+		if match, err := MayContain(chunk, p.Value); !match || err != nil {
+			return false, err
+		}
+	}
+
+	if f.ReadDictionaries {
+		return dictionaryMayMatch(chunk, p)
+	}
+
+	return true, nil
+}
+
+// columnChunkBounds returns the min and max values recorded in the statistics
+// of the column chunk, if any, and whether the statistics record that both
+// bounds are exact.
+func columnChunkBounds(chunk ColumnChunk) (min, max Value, exact, ok bool) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return min, max, false, false
+	}
+	stats := &c.chunk.MetaData.Statistics
+	if len(stats.MinValue) == 0 || len(stats.MaxValue) == 0 {
+		return min, max, false, false
+	}
+	kind := c.column.Type().Kind()
+	exact = isExactBound(kind, stats.IsMinValueExact) && isExactBound(kind, stats.IsMaxValueExact)
+	return kind.Value(stats.MinValue), kind.Value(stats.MaxValue), exact, true
+}
+
+// boundsMayMatch tests whether values between min and max may satisfy the
+// comparison to value with the given operator. Unless the bounds are exact,
+// byte array bounds are assumed to be possibly truncated.
+func boundsMayMatch(typ Type, min, max Value, exact bool, op Operator, value Value) bool {
+	var lower, upper int
+	if exact {
+		lower = typ.Compare(value, min)
+		upper = typ.Compare(value, max)
+	} else {
+		lower = compareLowerBound(typ, value, min)
+		upper = compareUpperBound(typ, value, max)
+	}
+	switch op {
+	case EqualTo:
+		return lower >= 0 && upper <= 0
+	case LessThan:
+		return lower > 0
+	case LessOrEqual:
+		return lower >= 0
+	case GreaterThan:
+		return upper < 0
+	case GreaterOrEqual:
+		return upper <= 0
+	default:
+		return true
+	}
+}
+
+// compareLowerBound compares value to the lower bound of a page or column
+// chunk. Byte array bounds may be truncated to a prefix of the min value by the
+// writer, in which case they remain valid lower bounds when comparing bytes.
+func compareLowerBound(typ Type, value, min Value) int {
+	if b, m, ok := truncatedBound(typ, value, min); ok {
+		if cmp := bytes.Compare(b[:len(m)], m); cmp != 0 {
+			return cmp
+		}
+		return +1
+	}
+	return typ.Compare(value, min)
+}
+
+// compareUpperBound compares value to the upper bound of a page or column
+// chunk. Byte array bounds may be truncated to a prefix of the max value by the
+// writer, values starting with the prefix are considered lower than the bound.
+func compareUpperBound(typ Type, value, max Value) int {
+	if b, m, ok := truncatedBound(typ, value, max); ok {
+		if cmp := bytes.Compare(b[:len(m)], m); cmp != 0 {
+			return cmp
+		}
+		return -1
+	}
+	return typ.Compare(value, max)
+}
+
+func truncatedBound(typ Type, value, bound Value) (b, m []byte, ok bool) {
+	switch typ.Kind() {
+	case ByteArray, FixedLenByteArray:
+		b, m = value.ByteArray(), bound.ByteArray()
+		return b, m, len(m) < len(b)
+	default:
+		return nil, nil, false
+	}
+}
+
+// pageRowRanges returns the ranges of rows of the pages in the column chunk
+// that may contain values matching the predicate, according to the column and
+// offset indexes of the chunk. The boolean is false if the chunk has no page
+// index.
+func pageRowRanges(chunk ColumnChunk, numRows int64, p *Predicate) ([]RowRange, bool) {
+	columnIndex, offsetIndex := chunk.ColumnIndex(), chunk.OffsetIndex()
+	if columnIndex == nil || offsetIndex == nil {
+		return nil, false
+	}
+	numPages := columnIndex.NumPages()
+	if numPages == 0 || numPages != offsetIndex.NumPages() {
+		return nil, false
+	}
+
+	typ := chunk.Type()
+	rows := make([]RowRange, 0, numPages)
+
+	for i := 0; i < numPages; i++ {
+		if columnIndex.NullPage(i) {
+			continue
+		}
+		if !boundsMayMatch(typ, columnIndex.MinValue(i), columnIndex.MaxValue(i), false, p.Op, p.Value) {
+			continue
+		}
+		firstRowIndex, lastRowIndex := offsetIndex.FirstRowIndex(i), numRows
+		if i+1 < numPages {
+			lastRowIndex = offsetIndex.FirstRowIndex(i + 1)
+		}
+		if n := len(rows); n > 0 && rows[n-1].FirstRowIndex+rows[n-1].NumRows == firstRowIndex {
+			rows[n-1].NumRows += lastRowIndex - firstRowIndex
+		} else {
+			rows = append(rows, RowRange{FirstRowIndex: firstRowIndex, NumRows: lastRowIndex - firstRowIndex})
+		}
+	}
+
+	return rows, true
+}
+
+// intersectRowRanges returns the ranges of rows contained in both a and b,
+// which must be sorted and not overlapping.
+func intersectRowRanges(a, b []RowRange) []RowRange {
+	rows := make([]RowRange, 0, len(a))
+
+	for len(a) > 0 && len(b) > 0 {
+		begin := a[0].FirstRowIndex
+		if begin < b[0].FirstRowIndex {
+			begin = b[0].FirstRowIndex
+		}
+		endA := a[0].FirstRowIndex + a[0].NumRows
+		endB := b[0].FirstRowIndex + b[0].NumRows
+		end := endA
+		if end > endB {
+			end = endB
+		}
+		if begin < end {
+			rows = append(rows, RowRange{FirstRowIndex: begin, NumRows: end - begin})
+		}
+		if endA < endB {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+
+	return rows
+}
+
+// dictionaryMayMatch reads the dictionary of the column chunk to test whether it
+// contains values matching the predicate. The dictionary is only consulted when
+// the encoding statistics of the chunk show that all data pages use it.
+func dictionaryMayMatch(chunk ColumnChunk, p *Predicate) (bool, error) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil || c.chunk.MetaData.DictionaryPageOffset == 0 || !isDictionaryEncoded(c.chunk.MetaData.EncodingStats) {
+		return true, nil
+	}
+
+	pages := new(filePages)
+	pages.init(c)
+	defer pages.Close()
+
+	if err := pages.readDictionary(); err != nil {
+		return false, fmt.Errorf("reading dictionary of column %q: %w", columnPath(c.column.Path()), err)
+	}
+
+	typ := c.Type()
+	dict := pages.dataPage.dictionary
+	for i, n := 0, dict.Len(); i < n; i++ {
+		if p.Op.match(typ.Compare(dict.Index(int32(i)), p.Value)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isDictionaryEncoded(stats []format.PageEncodingStats) bool {
+	numDataPages := 0
+
+	for _, s := range stats {
+		switch s.PageType {
+		case format.DataPage, format.DataPageV2:
+			switch s.Encoding {
+			case format.PlainDictionary, format.RLEDictionary:
+				numDataPages++
+			default:
+				return false
+			}
+		}
+	}
+
+	return numDataPages > 0
+}
@@ -0,0 +1,285 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestRowGroupFilter(t *testing.T) {
+	type Row struct {
+		ID    int64  `parquet:"id"`
+		Name  string `parquet:"name"`
+		Color string `parquet:"color,dict"`
+	}
+
+	const numRowGroups = 4
+	const rowsPerGroup = 1000
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.PageBufferSize(1024),
+		parquet.BloomFilters(parquet.SplitBlockFilter("name")),
+	)
+	for i := 0; i < numRowGroups; i++ {
+		// The first row groups have colors "amber" and "cyan", the last ones
+		// also have "blue", which only the dictionaries reveal since it is
+		// within the bounds of all pages.
+		colors := []string{"amber", "cyan"}
+		if i >= numRowGroups/2 {
+			colors = []string{"amber", "blue", "cyan"}
+		}
+		for j := 0; j < rowsPerGroup; j++ {
+			id := int64(i*rowsPerGroup + j)
+			row := Row{ID: id, Name: fmt.Sprintf("name-%d", id), Color: colors[j%len(colors)]}
+			if err := w.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := func(readDictionaries bool, predicates ...parquet.Predicate) []parquet.RowGroupMatch {
+		t.Helper()
+		filter := &parquet.RowGroupFilter{
+			Predicates:       predicates,
+			ReadDictionaries: readDictionaries,
+		}
+		matches, err := filter.Match(f.RowGroups())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	rowGroups := func(matches []parquet.RowGroupMatch) []int {
+		indexes := []int{}
+		for _, m := range matches {
+			indexes = append(indexes, m.RowGroup)
+		}
+		return indexes
+	}
+
+	id := func(op parquet.Operator, v int64) parquet.Predicate {
+		return parquet.Predicate{Path: []string{"id"}, Op: op, Value: parquet.ValueOf(v)}
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		matches := match(false, id(parquet.EqualTo, 1500))
+		if got := rowGroups(matches); !reflect.DeepEqual(got, []int{1}) {
+			t.Fatalf("wrong row groups: %v", got)
+		}
+		rows := matches[0].Rows
+		if len(rows) != 1 || rows[0].FirstRowIndex > 500 || rows[0].FirstRowIndex+rows[0].NumRows <= 500 {
+			t.Fatalf("row 500 is not in the matching rows: %+v", rows)
+		}
+		if rows[0].NumRows == rowsPerGroup {
+			t.Fatal("the page index was not used to select the rows")
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		matches := match(false, id(parquet.GreaterOrEqual, 3500))
+		if got := rowGroups(matches); !reflect.DeepEqual(got, []int{3}) {
+			t.Fatalf("wrong row groups: %v", got)
+		}
+		rows := matches[0].Rows
+		if len(rows) != 1 || rows[0].FirstRowIndex > 500 || rows[0].FirstRowIndex+rows[0].NumRows != rowsPerGroup {
+			t.Fatalf("wrong matching rows: %+v", rows)
+		}
+
+		if got := rowGroups(match(false, id(parquet.LessThan, 1000))); !reflect.DeepEqual(got, []int{0}) {
+			t.Errorf("wrong row groups for id < 1000: %v", got)
+		}
+		if got := rowGroups(match(false, id(parquet.LessOrEqual, 1000))); !reflect.DeepEqual(got, []int{0, 1}) {
+			t.Errorf("wrong row groups for id <= 1000: %v", got)
+		}
+		if got := rowGroups(match(false, id(parquet.GreaterThan, 4000))); len(got) != 0 {
+			t.Errorf("wrong row groups for id > 4000: %v", got)
+		}
+	})
+
+	t.Run("conjunction", func(t *testing.T) {
+		matches := match(false, id(parquet.GreaterThan, 1200), id(parquet.LessThan, 1300))
+		if got := rowGroups(matches); !reflect.DeepEqual(got, []int{1}) {
+			t.Fatalf("wrong row groups: %v", got)
+		}
+		for _, r := range matches[0].Rows {
+			if r.FirstRowIndex+r.NumRows <= 200 || r.FirstRowIndex > 300 {
+				t.Errorf("row range does not intersect the predicates: %+v", r)
+			}
+		}
+	})
+
+	t.Run("bloom filter", func(t *testing.T) {
+		name := func(s string) parquet.Predicate {
+			return parquet.Predicate{Path: []string{"name"}, Op: parquet.EqualTo, Value: parquet.ValueOf(s)}
+		}
+		// The names are within the bounds of all row groups, only the bloom
+		// filters can eliminate them.
+		if got := rowGroups(match(false, name("name-2500"))); !reflect.DeepEqual(got, []int{2}) {
+			t.Errorf("wrong row groups: %v", got)
+		}
+		if got := rowGroups(match(false, name("name-2500x"))); len(got) != 0 {
+			t.Errorf("wrong row groups: %v", got)
+		}
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+		blue := parquet.Predicate{Path: []string{"color"}, Op: parquet.EqualTo, Value: parquet.ValueOf("blue")}
+		if got := rowGroups(match(false, blue)); !reflect.DeepEqual(got, []int{0, 1, 2, 3}) {
+			t.Errorf("wrong row groups without dictionaries: %v", got)
+		}
+		if got := rowGroups(match(true, blue)); !reflect.DeepEqual(got, []int{2, 3}) {
+			t.Errorf("wrong row groups with dictionaries: %v", got)
+		}
+		if got := rowGroups(match(true, blue, id(parquet.LessThan, 2500))); !reflect.DeepEqual(got, []int{2}) {
+			t.Errorf("wrong row groups with dictionaries and ids: %v", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, p := range []parquet.Predicate{
+			{Path: []string{"missing"}, Op: parquet.EqualTo, Value: parquet.ValueOf(int64(0))},
+			{Path: []string{"id"}, Op: parquet.EqualTo, Value: parquet.ValueOf("0")},
+			{Path: []string{"id"}, Op: parquet.EqualTo, Value: parquet.Value{}},
+		} {
+			filter := &parquet.RowGroupFilter{Predicates: []parquet.Predicate{p}}
+			if _, err := filter.Match(f.RowGroups()); err == nil {
+				t.Errorf("predicate %s: no error returned", &p)
+			}
+		}
+	})
+}
+
+func TestRowGroupRowRangeReader(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+
+	const numRows = 10000
+
+	for _, version := range []int{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			b := new(bytes.Buffer)
+			w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+				parquet.PageBufferSize(1024),
+				parquet.DataPageVersion(version),
+			)
+			for i := 0; i < numRows; i++ {
+				if err := w.Write(Row{ID: int64(i), Name: fmt.Sprintf("name-%d", i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			metrics := new(testReaderMetrics)
+			f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()), parquet.Metrics(metrics))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rowGroup := f.RowGroups()[0]
+
+			readIDs := func(rows parquet.Rows) []int64 {
+				t.Helper()
+				defer rows.Close()
+				var ids []int64
+				buf := make([]parquet.Row, 64)
+				for {
+					n, err := rows.ReadRows(buf)
+					for _, row := range buf[:n] {
+						ids = append(ids, row[0].Int64())
+					}
+					if err != nil {
+						if err != io.EOF {
+							t.Fatal(err)
+						}
+						return ids
+					}
+				}
+			}
+
+			expectIDs := func(ranges ...[2]int64) []int64 {
+				var ids []int64
+				for _, r := range ranges {
+					for id := r[0]; id < r[1]; id++ {
+						ids = append(ids, id)
+					}
+				}
+				return ids
+			}
+
+			got := readIDs(parquet.NewRowGroupRowRangeReader(rowGroup, []parquet.RowRange{
+				{FirstRowIndex: 7000, NumRows: 10},
+				{FirstRowIndex: 100, NumRows: 50},
+				{FirstRowIndex: 120, NumRows: 100},
+				{FirstRowIndex: 9990, NumRows: 100},
+			}))
+			want := expectIDs([2]int64{100, 220}, [2]int64{7000, 7010}, [2]int64{9990, 10000})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong rows read from ranges:\nwant = %v\ngot  = %v", want, got)
+			}
+
+			if got := readIDs(parquet.NewRowGroupRowRangeReader(rowGroup, nil)); len(got) != 0 {
+				t.Errorf("rows read from empty ranges: %v", got)
+			}
+
+			rows := parquet.NewRowGroupRowRangeReader(rowGroup, []parquet.RowRange{
+				{FirstRowIndex: 100, NumRows: 100},
+				{FirstRowIndex: 500, NumRows: 10},
+			})
+			if err := rows.SeekToRow(150); err != nil {
+				t.Fatal(err)
+			}
+			got = readIDs(rows)
+			want = expectIDs([2]int64{150, 200}, [2]int64{500, 510})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("wrong rows read after seeking:\nwant = %v\ngot  = %v", want, got)
+			}
+
+			// Only the pages of the rows matching the predicates are decoded.
+			filter := &parquet.RowGroupFilter{Predicates: []parquet.Predicate{
+				{Path: []string{"id"}, Op: parquet.GreaterOrEqual, Value: parquet.ValueOf(int64(5000))},
+				{Path: []string{"id"}, Op: parquet.LessThan, Value: parquet.ValueOf(int64(5100))},
+			}}
+			matches, err := filter.Match(f.RowGroups())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(matches) != 1 {
+				t.Fatalf("wrong number of matching row groups: %d", len(matches))
+			}
+
+			metrics.pagesDecoded = 0
+			got = readIDs(parquet.NewRowGroupRowRangeReader(rowGroup, matches[0].Rows))
+			if len(got) == 0 || got[0] > 5000 || got[len(got)-1] < 5099 || len(got) >= numRows/10 {
+				t.Errorf("wrong rows read from matching ranges: %d rows in [%d:%d]", len(got), got[0], got[len(got)-1])
+			}
+
+			numPages := 0
+			for _, chunk := range rowGroup.ColumnChunks() {
+				numPages += chunk.OffsetIndex().NumPages()
+			}
+			if metrics.pagesDecoded == 0 || metrics.pagesDecoded >= int64(numPages)/4 {
+				t.Errorf("decoded %d pages out of %d", metrics.pagesDecoded, numPages)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parquet
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -37,6 +38,7 @@ type Reader struct {
 	read     reader
 	rowIndex int64
 	rowbuf   []Row
+	convert  *ConvertConfig
 }
 
 // NewReader constructs a parquet reader reading rows from the given
@@ -68,7 +70,7 @@ func NewReader(input io.ReaderAt, options ...ReaderOption) *Reader {
 		panic(err)
 	}
 
-	f, err := openFile(input)
+	f, err := openFile(input, c)
 	if err != nil {
 		panic(err)
 	}
@@ -77,19 +79,26 @@ func NewReader(input io.ReaderAt, options ...ReaderOption) *Reader {
 		file: reader{
 			schema:   f.schema,
 			rowGroup: fileRowGroupOf(f),
+			metrics:  c.Metrics,
+			deletes:  c.DeletedRows,
 		},
+		read:    reader{metrics: c.Metrics, deletes: c.DeletedRows},
+		convert: c.convertConfig(),
 	}
 
 	if c.Schema != nil {
 		r.file.schema = c.Schema
-		r.file.rowGroup = convertRowGroupTo(r.file.rowGroup, c.Schema)
+		r.file.rowGroup = convertRowGroupTo(r.file.rowGroup, c.Schema, r.convert)
+	} else if c.Int96Timestamps {
+		r.file.schema = int96TimestampSchemaOf(r.file.schema)
+		r.file.rowGroup = convertRowGroupTo(r.file.rowGroup, r.file.schema, r.convert)
 	}
 
 	r.read.init(r.file.schema, r.file.rowGroup)
 	return r
 }
 
-func openFile(input io.ReaderAt) (*File, error) {
+func openFile(input io.ReaderAt, config *ReaderConfig) (*File, error) {
 	f, _ := input.(*File)
 	if f != nil {
 		return f, nil
@@ -98,7 +107,7 @@ func openFile(input io.ReaderAt) (*File, error) {
 	if err != nil {
 		return nil, err
 	}
-	return OpenFile(input, n)
+	return OpenFile(input, n, config.fileOptions()...)
 }
 
 func fileRowGroupOf(f *File) RowGroup {
@@ -122,24 +131,32 @@ func NewRowGroupReader(rowGroup RowGroup, options ...ReaderOption) *Reader {
 		panic(err)
 	}
 
+	convert := c.convertConfig()
+
 	if c.Schema != nil {
-		rowGroup = convertRowGroupTo(rowGroup, c.Schema)
+		rowGroup = convertRowGroupTo(rowGroup, c.Schema, convert)
+	} else if c.Int96Timestamps {
+		rowGroup = convertRowGroupTo(rowGroup, int96TimestampSchemaOf(rowGroup.Schema()), convert)
 	}
 
 	r := &Reader{
 		file: reader{
 			schema:   rowGroup.Schema(),
 			rowGroup: rowGroup,
+			metrics:  c.Metrics,
+			deletes:  c.DeletedRows,
 		},
+		read:    reader{metrics: c.Metrics, deletes: c.DeletedRows},
+		convert: convert,
 	}
 
 	r.read.init(r.file.schema, r.file.rowGroup)
 	return r
 }
 
-func convertRowGroupTo(rowGroup RowGroup, schema *Schema) RowGroup {
-	if rowGroupSchema := rowGroup.Schema(); !nodesAreEqual(schema, rowGroupSchema) {
-		conv, err := Convert(schema, rowGroupSchema)
+func convertRowGroupTo(rowGroup RowGroup, schema *Schema, config *ConvertConfig) RowGroup {
+	if rowGroupSchema := rowGroup.Schema(); conversionIsNeeded(schema, rowGroupSchema) {
+		conv, err := Convert(schema, rowGroupSchema, config)
 		if err != nil {
 			// TODO: this looks like something we should not be panicking on,
 			// but the current NewReader API does not offer a mechanism to
@@ -184,6 +201,12 @@ func (r *Reader) Reset() {
 //
 // The method returns io.EOF when no more rows can be read from r.
 func (r *Reader) Read(row interface{}) error {
+	return r.ReadContext(context.Background(), row)
+}
+
+// ReadContext is like Read but uses ctx to cancel the reads from the underlying
+// file.
+func (r *Reader) ReadContext(ctx context.Context, row interface{}) error {
 	if rowType := dereference(reflect.TypeOf(row)); rowType.Kind() == reflect.Struct {
 		if r.seen != rowType {
 			if err := r.updateReadSchema(rowType); err != nil {
@@ -205,22 +228,22 @@ func (r *Reader) Read(row interface{}) error {
 		r.rowbuf = r.rowbuf[:1]
 	}
 
-	n, err := r.read.ReadRows(r.rowbuf[:])
+	n, err := r.read.ReadRowsContext(ctx, r.rowbuf[:])
 	if n == 0 {
 		return err
 	}
 
-	r.rowIndex++
+	r.rowIndex = r.read.rowIndex
 	return r.read.schema.Reconstruct(row, r.rowbuf[0])
 }
 
 func (r *Reader) updateReadSchema(rowType reflect.Type) error {
 	schema := schemaOf(rowType)
 
-	if nodesAreEqual(schema, r.file.schema) {
+	if !conversionIsNeeded(schema, r.file.schema) {
 		r.read.init(schema, r.file.rowGroup)
 	} else {
-		conv, err := Convert(schema, r.file.schema)
+		conv, err := Convert(schema, r.file.schema, r.convert)
 		if err != nil {
 			return err
 		}
@@ -238,11 +261,20 @@ func (r *Reader) updateReadSchema(rowType reflect.Type) error {
 //
 // The method returns io.EOF when no more rows can be read from r.
 func (r *Reader) ReadRows(rows []Row) (int, error) {
+	return r.ReadRowsContext(context.Background(), rows)
+}
+
+// ReadRowsContext is like ReadRows but uses ctx to cancel the reads from the
+// underlying file.
+//
+// When the read is interrupted, the reader remains positioned at the first row
+// that was not returned, and the read can be retried with a new context.
+func (r *Reader) ReadRowsContext(ctx context.Context, rows []Row) (int, error) {
 	if err := r.file.SeekToRow(r.rowIndex); err != nil {
 		return 0, err
 	}
-	n, err := r.file.ReadRows(rows)
-	r.rowIndex += int64(n)
+	n, err := r.file.ReadRowsContext(ctx, rows)
+	r.rowIndex = r.file.rowIndex
 	return n, err
 }
 
@@ -282,6 +314,8 @@ type reader struct {
 	rowGroup RowGroup
 	rows     Rows
 	rowIndex int64
+	metrics  ReaderMetrics
+	deletes  DeleteMask
 }
 
 func (r *reader) init(schema *Schema, rowGroup RowGroup) {
@@ -313,6 +347,10 @@ func (r *reader) Reset() {
 }
 
 func (r *reader) ReadRows(rows []Row) (int, error) {
+	return r.ReadRowsContext(context.Background(), rows)
+}
+
+func (r *reader) ReadRowsContext(ctx context.Context, rows []Row) (int, error) {
 	if r.rowGroup == nil {
 		return 0, io.EOF
 	}
@@ -324,9 +362,29 @@ func (r *reader) ReadRows(rows []Row) (int, error) {
 			}
 		}
 	}
-	n, err := r.rows.ReadRows(rows)
-	r.rowIndex += int64(n)
-	return n, err
+	for {
+		rowIndex := r.rowIndex
+		n, err := ReadRowsContext(ctx, r.rows, rows)
+		r.rowIndex += int64(n)
+		if err != nil && ctx.Err() != nil {
+			// The read was interrupted at an undefined position in the pages,
+			// the rows are closed so the next read resumes at the current row
+			// index.
+			r.rows.Close()
+			r.rows = nil
+		}
+		if r.deletes != nil && n > 0 {
+			// Keep reading when all the rows were deleted, so the program does
+			// not observe reads returning no rows and no errors.
+			if n = removeDeletedRows(rows[:n], rowIndex, r.deletes); n == 0 && err == nil {
+				continue
+			}
+		}
+		if r.metrics != nil && n > 0 {
+			r.metrics.RowsRead(int64(n))
+		}
+		return n, err
+	}
 }
 
 func (r *reader) SeekToRow(rowIndex int64) error {
@@ -355,6 +413,7 @@ func (r *reader) Close() (err error) {
 var (
 	_ Rows                = (*Reader)(nil)
 	_ RowReaderWithSchema = (*Reader)(nil)
+	_ RowReaderContext    = (*Reader)(nil)
 
 	_ RowReader = (*reader)(nil)
 	_ RowSeeker = (*reader)(nil)
@@ -0,0 +1,350 @@
+//go:build go1.18
+
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/segmentio/parquet-go/deprecated"
+	"github.com/segmentio/parquet-go/internal/unsafecast"
+)
+
+// directReader reads the rows of a row group with a flat schema, where all the
+// columns are required, straight into the fields of Go structs. Values are
+// decoded from the column pages into the memory of the struct fields, without
+// constructing Row or Value objects when the pages expose typed readers.
+type directReader struct {
+	rowGroup RowGroup
+	columns  []directColumn
+	memory   *memoryReservation
+	// Index of the next row read by each column, or -1 if the columns need to
+	// be repositioned before the next read.
+	rowIndex int64
+}
+
+// directReaderOf returns a directReader reading rows of the row group into Go
+// values of type t, or nil if the schema is not flat, if one of its columns is
+// not required, or if the struct fields cannot receive the column values
+// without conversion.
+func directReaderOf(t reflect.Type, schema *Schema, rowGroup RowGroup) *directReader {
+	root, ok := schema.root.(*structNode)
+	if !ok || root.gotype != t || len(root.fields) == 0 {
+		return nil
+	}
+
+	columns := make([]directColumn, len(root.fields))
+
+	for i := range root.fields {
+		f := &root.fields[i]
+		if !f.Leaf() || !f.Required() {
+			return nil
+		}
+		fieldType, fieldOffset, ok := directFieldOf(t, f.index)
+		if !ok {
+			return nil
+		}
+		decode := directDecodeFuncOf(f.Type().Kind(), fieldType)
+		if decode == nil {
+			return nil
+		}
+		columns[i] = directColumn{
+			batchColumn: batchColumn{
+				path:   []string{f.name},
+				column: i,
+				kind:   f.Type().Kind(),
+			},
+			field:  fieldOffset,
+			decode: decode,
+		}
+	}
+
+	return &directReader{
+		rowGroup: rowGroup,
+		columns:  columns,
+		memory:   new(memoryReservation),
+		rowIndex: -1,
+	}
+}
+
+// directFieldOf returns the type and offset of the field at the given index in
+// struct type t, the last return value is false if the field is in a struct
+// embedded by pointer.
+func directFieldOf(t reflect.Type, index []int) (reflect.Type, uintptr, bool) {
+	offset := uintptr(0)
+	for _, i := range index {
+		if t.Kind() != reflect.Struct {
+			return nil, 0, false
+		}
+		f := t.Field(i)
+		t, offset = f.Type, offset+f.Offset
+	}
+	return t, offset, true
+}
+
+func (r *directReader) seekToRow(rowIndex int64) error {
+	for i := range r.columns {
+		c := &r.columns[i]
+		if c.pages == nil {
+			c.pages = pagesWithMemory(r.rowGroup.ColumnChunks()[c.column], r.memory)
+		}
+		if err := c.seekToRow(rowIndex); err != nil {
+			r.rowIndex = -1
+			return err
+		}
+	}
+	r.rowIndex = rowIndex
+	return nil
+}
+
+// read reads rows into the Go values of the array, which are of the given size,
+// returning the number of rows read.
+func (r *directReader) read(rows array, size uintptr) (int, error) {
+	numRows := -1
+	var lastErr error
+
+	for i := range r.columns {
+		c := &r.columns[i]
+		n, err := c.read(rows, size)
+		if err != nil && err != io.EOF {
+			r.rowIndex = -1
+			return 0, fmt.Errorf("reading values of column %q: %w", columnPath(c.path), err)
+		}
+		if numRows >= 0 && n != numRows {
+			r.rowIndex = -1
+			return 0, fmt.Errorf("reading values of column %q: column has %d rows but other columns had %d", columnPath(c.path), n, numRows)
+		}
+		numRows, lastErr = n, err
+	}
+
+	r.rowIndex += int64(numRows)
+	if numRows == 0 {
+		lastErr = io.EOF
+	}
+	return numRows, lastErr
+}
+
+func (r *directReader) close() (err error) {
+	for i := range r.columns {
+		c := &r.columns[i]
+		if c.pages != nil {
+			if e := c.pages.Close(); e != nil && err == nil {
+				err = e
+			}
+			c.pages = nil
+		}
+	}
+	r.rowIndex = -1
+	return err
+}
+
+// directColumn decodes the values of a required column into a field of Go
+// structs. The paging logic is shared with the columns of BatchReader.
+type directColumn struct {
+	batchColumn
+	// Offset of the struct field receiving the column values.
+	field  uintptr
+	decode directDecodeFunc
+	// Scratch buffer holding values decoded by typed readers before they are
+	// copied to the struct fields.
+	scratch []byte
+}
+
+// directDecodeFunc is the type of functions decoding len(rows) values of the
+// current page of a column into the struct fields of the rows.
+type directDecodeFunc func(c *directColumn, rows array, size uintptr) error
+
+func (c *directColumn) read(rows array, size uintptr) (int, error) {
+	n := 0
+	for n < rows.len {
+		if c.remain == 0 {
+			if err := c.nextPage(); err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		m := rows.len - n
+		if m > c.remain {
+			m = c.remain
+		}
+
+		if err := c.decode(c, rows.slice(n, n+m, size, 0), size); err != nil {
+			return n, err
+		}
+
+		c.offset += m
+		c.remain -= m
+		n += m
+	}
+	return n, nil
+}
+
+func directDecodeFuncOf(kind Kind, t reflect.Type) directDecodeFunc {
+	switch kind {
+	case Boolean:
+		if t.Kind() == reflect.Bool {
+			return directDecode(booleanReaderOf, Value.Boolean)
+		}
+	case Int32:
+		switch t.Kind() {
+		case reflect.Int32:
+			return directDecode(int32ReaderOf, Value.Int32)
+		case reflect.Uint32:
+			return directDecode(uint32ReaderOf, Value.Uint32)
+		}
+	case Int64:
+		switch t.Kind() {
+		case reflect.Int64:
+			return directDecode(int64ReaderOf, Value.Int64)
+		case reflect.Uint64:
+			return directDecode(uint64ReaderOf, Value.Uint64)
+		}
+	case Int96:
+		if t == reflect.TypeOf(deprecated.Int96{}) {
+			return directDecode(int96ReaderOf, Value.Int96)
+		}
+	case Float:
+		if t.Kind() == reflect.Float32 {
+			return directDecode(floatReaderOf, Value.Float)
+		}
+	case Double:
+		if t.Kind() == reflect.Float64 {
+			return directDecode(doubleReaderOf, Value.Double)
+		}
+	case ByteArray:
+		switch {
+		case t.Kind() == reflect.String:
+			return directDecodeString
+		case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+			return directDecodeBytes
+		}
+	}
+	return nil
+}
+
+// directDecode returns a directDecodeFunc for values of type T, which must not
+// contain pointers since they are decoded into the scratch buffer of columns.
+//
+// When the page values do not support reading typed values, the function falls
+// back to reading Value objects and converting them with valueOf.
+func directDecode[T any](readerOf func(ValueReader) func([]T) (int, error), valueOf func(Value) T) directDecodeFunc {
+	return func(c *directColumn, rows array, size uintptr) error {
+		if read := readerOf(c.reader); read != nil {
+			var zero T
+			if n := rows.len * int(unsafe.Sizeof(zero)); cap(c.scratch) < n {
+				c.scratch = make([]byte, n)
+			} else {
+				c.scratch = c.scratch[:n]
+			}
+			values := unsafecast.Slice[T](c.scratch)[:rows.len]
+			n, err := readBatchValues(len(values), func(i int) (int, error) { return read(values[i:]) })
+			if err := batchError(n, len(values), err); err != nil {
+				return err
+			}
+			for i, v := range values {
+				*(*T)(rows.index(i, size, c.field)) = v
+			}
+			return nil
+		}
+
+		values, err := c.readBuffer(rows.len)
+		if err != nil {
+			return err
+		}
+		for i, v := range values {
+			*(*T)(rows.index(i, size, c.field)) = valueOf(v)
+		}
+		return nil
+	}
+}
+
+func directDecodeString(c *directColumn, rows array, size uintptr) error {
+	values, err := c.readBuffer(rows.len)
+	if err != nil {
+		return err
+	}
+	for i, v := range values {
+		// Reading into values reused across reads often finds the same
+		// strings, which avoids allocating a copy.
+		if s := (*string)(rows.index(i, size, c.field)); *s != string(v.ByteArray()) {
+			*s = string(v.ByteArray())
+		}
+	}
+	return nil
+}
+
+func directDecodeBytes(c *directColumn, rows array, size uintptr) error {
+	values, err := c.readBuffer(rows.len)
+	if err != nil {
+		return err
+	}
+	for i, v := range values {
+		*(*[]byte)(rows.index(i, size, c.field)) = copyBytes(v.ByteArray())
+	}
+	return nil
+}
+
+func booleanReaderOf(r ValueReader) func([]bool) (int, error) {
+	if r, ok := r.(BooleanReader); ok {
+		return r.ReadBooleans
+	}
+	return nil
+}
+
+func int32ReaderOf(r ValueReader) func([]int32) (int, error) {
+	if r, ok := r.(Int32Reader); ok {
+		return r.ReadInt32s
+	}
+	return nil
+}
+
+func uint32ReaderOf(r ValueReader) func([]uint32) (int, error) {
+	switch r := r.(type) {
+	case interface{ ReadUint32s([]uint32) (int, error) }:
+		return r.ReadUint32s
+	case Int32Reader:
+		return func(values []uint32) (int, error) { return r.ReadInt32s(unsafecast.Slice[int32](values)) }
+	}
+	return nil
+}
+
+func int64ReaderOf(r ValueReader) func([]int64) (int, error) {
+	if r, ok := r.(Int64Reader); ok {
+		return r.ReadInt64s
+	}
+	return nil
+}
+
+func uint64ReaderOf(r ValueReader) func([]uint64) (int, error) {
+	switch r := r.(type) {
+	case interface{ ReadUint64s([]uint64) (int, error) }:
+		return r.ReadUint64s
+	case Int64Reader:
+		return func(values []uint64) (int, error) { return r.ReadInt64s(unsafecast.Slice[int64](values)) }
+	}
+	return nil
+}
+
+func int96ReaderOf(r ValueReader) func([]deprecated.Int96) (int, error) {
+	if r, ok := r.(Int96Reader); ok {
+		return r.ReadInt96s
+	}
+	return nil
+}
+
+func floatReaderOf(r ValueReader) func([]float32) (int, error) {
+	if r, ok := r.(FloatReader); ok {
+		return r.ReadFloats
+	}
+	return nil
+}
+
+func doubleReaderOf(r ValueReader) func([]float64) (int, error) {
+	if r, ok := r.(DoubleReader); ok {
+		return r.ReadDoubles
+	}
+	return nil
+}
@@ -3,8 +3,11 @@
 package parquet
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"reflect"
+	"unsafe"
 )
 
 // GenericReader is similar to a Reader but uses a type parameter to define the
@@ -12,8 +15,10 @@ import (
 //
 // See GenericWriter for details about the benefits over the classic Reader API.
 type GenericReader[T any] struct {
-	base Reader
-	read readFunc[T]
+	base   Reader
+	read   readFunc[T]
+	batch  *BatchReader
+	direct *directReader
 }
 
 // NewGenericReader is like NewReader but returns GenericReader[T] suited to write
@@ -37,7 +42,7 @@ func NewGenericReader[T any](input io.ReaderAt, options ...ReaderOption) *Generi
 		c.Schema = schemaOf(dereference(t))
 	}
 
-	f, err := openFile(input)
+	f, err := openFile(input, c)
 	if err != nil {
 		panic(err)
 	}
@@ -47,16 +52,24 @@ func NewGenericReader[T any](input io.ReaderAt, options ...ReaderOption) *Generi
 			file: reader{
 				schema:   c.Schema,
 				rowGroup: fileRowGroupOf(f),
+				metrics:  c.Metrics,
+				deletes:  c.DeletedRows,
 			},
+			read:    reader{metrics: c.Metrics, deletes: c.DeletedRows},
+			convert: c.convertConfig(),
 		},
 	}
 
-	if !nodesAreEqual(c.Schema, f.schema) {
-		r.base.file.rowGroup = convertRowGroupTo(r.base.file.rowGroup, c.Schema)
+	converted := conversionIsNeeded(c.Schema, f.schema)
+	if converted {
+		r.base.file.rowGroup = convertRowGroupTo(r.base.file.rowGroup, c.Schema, r.base.convert)
 	}
 
 	r.base.read.init(r.base.file.schema, r.base.file.rowGroup)
 	r.read = readFuncOf[T](t, r.base.file.schema)
+	if !converted {
+		r.initDirectReads(t)
+	}
 	return r
 }
 
@@ -76,31 +89,63 @@ func NewGenericRowGroupReader[T any](rowGroup RowGroup, options ...ReaderOption)
 			file: reader{
 				schema:   c.Schema,
 				rowGroup: rowGroup,
+				metrics:  c.Metrics,
+				deletes:  c.DeletedRows,
 			},
+			read:    reader{metrics: c.Metrics, deletes: c.DeletedRows},
+			convert: c.convertConfig(),
 		},
 	}
 
-	if !nodesAreEqual(c.Schema, rowGroup.Schema()) {
-		r.base.file.rowGroup = convertRowGroupTo(r.base.file.rowGroup, c.Schema)
+	converted := conversionIsNeeded(c.Schema, rowGroup.Schema())
+	if converted {
+		r.base.file.rowGroup = convertRowGroupTo(r.base.file.rowGroup, c.Schema, r.base.convert)
 	}
 
 	r.base.read.init(r.base.file.schema, r.base.file.rowGroup)
 	r.read = readFuncOf[T](t, r.base.file.schema)
+	if !converted {
+		r.initDirectReads(t)
+	}
 	return r
 }
 
+// initDirectReads configures r to read rows directly into the fields of values
+// of type T when the schema is flat and all its columns are required, which
+// skips the construction of Row and Value objects.
+func (r *GenericReader[T]) initDirectReads(t reflect.Type) {
+	if t.Kind() != reflect.Struct || r.base.file.deletes != nil {
+		return
+	}
+	if r.direct = directReaderOf(t, r.base.file.schema, r.base.file.rowGroup); r.direct != nil {
+		r.read = (*GenericReader[T]).readDirect
+	}
+}
+
 func (r *GenericReader[T]) Reset() {
 	r.base.Reset()
 }
 
 func (r *GenericReader[T]) Read(rows []T) (int, error) {
-	return r.read(r, rows)
+	return r.read(r, context.Background(), rows)
+}
+
+// ReadContext is like Read but uses ctx to cancel the reads from the underlying
+// file.
+func (r *GenericReader[T]) ReadContext(ctx context.Context, rows []T) (int, error) {
+	return r.read(r, ctx, rows)
 }
 
 func (r *GenericReader[T]) ReadRows(rows []Row) (int, error) {
 	return r.base.ReadRows(rows)
 }
 
+// ReadRowsContext is like ReadRows but uses ctx to cancel the reads from the
+// underlying file.
+func (r *GenericReader[T]) ReadRowsContext(ctx context.Context, rows []Row) (int, error) {
+	return r.base.ReadRowsContext(ctx, rows)
+}
+
 func (r *GenericReader[T]) Schema() *Schema {
 	return r.base.Schema()
 }
@@ -113,18 +158,48 @@ func (r *GenericReader[T]) SeekToRow(rowIndex int64) error {
 	return r.base.SeekToRow(rowIndex)
 }
 
+// ReadBatch reads the next rows into a batch of columns laid out as a struct of
+// slices, without constructing the rows. See BatchReader for details about the
+// layout of batches.
+//
+// The method may be mixed with calls to Read, both continue reading at the
+// position where the other stopped. The method errors if the reader was
+// configured with the DeletedRows option.
+func (r *GenericReader[T]) ReadBatch(batch interface{}) (int, error) {
+	if r.base.file.deletes != nil {
+		return 0, fmt.Errorf("cannot read batches of rows from a reader configured with a delete mask")
+	}
+	if r.batch == nil {
+		r.batch = NewBatchReader(r.base.file.rowGroup)
+	}
+	if r.batch.rowIndex != r.base.rowIndex {
+		if err := r.batch.SeekToRow(r.base.rowIndex); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.batch.ReadBatch(batch)
+	r.base.rowIndex += int64(n)
+	return n, err
+}
+
 func (r *GenericReader[T]) Close() error {
+	if r.batch != nil {
+		r.batch.Close()
+	}
+	if r.direct != nil {
+		r.direct.close()
+	}
 	return r.base.Close()
 }
 
-func (r *GenericReader[T]) readRows(rows []T) (int, error) {
+func (r *GenericReader[T]) readRows(ctx context.Context, rows []T) (int, error) {
 	if cap(r.base.rowbuf) < len(rows) {
 		r.base.rowbuf = make([]Row, len(rows))
 	} else {
 		r.base.rowbuf = r.base.rowbuf[:len(rows)]
 	}
 
-	n, err := r.base.ReadRows(r.base.rowbuf)
+	n, err := r.base.ReadRowsContext(ctx, r.base.rowbuf)
 	if n > 0 {
 		schema := r.base.Schema()
 
@@ -137,6 +212,29 @@ func (r *GenericReader[T]) readRows(rows []T) (int, error) {
 	return n, err
 }
 
+func (r *GenericReader[T]) readDirect(ctx context.Context, rows []T) (int, error) {
+	if r.base.file.rowGroup == nil {
+		return 0, io.ErrClosedPipe
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if r.direct.rowIndex != r.base.rowIndex {
+		if err := r.direct.seekToRow(r.base.rowIndex); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.direct.read(makeArray(unsafe.Pointer(&rows[0]), len(rows)), unsafe.Sizeof(rows[0]))
+	r.base.rowIndex += int64(n)
+	if r.base.file.metrics != nil && n > 0 {
+		r.base.file.metrics.RowsRead(int64(n))
+	}
+	return n, err
+}
+
 var (
 	_ Rows                = (*GenericReader[any])(nil)
 	_ RowReaderWithSchema = (*Reader)(nil)
@@ -148,7 +246,7 @@ var (
 	_ RowReaderWithSchema = (*GenericReader[map[struct{}]struct{}])(nil)
 )
 
-type readFunc[T any] func(*GenericReader[T], []T) (int, error)
+type readFunc[T any] func(*GenericReader[T], context.Context, []T) (int, error)
 
 func readFuncOf[T any](t reflect.Type, schema *Schema) readFunc[T] {
 	switch t.Kind() {
@@ -10,8 +10,10 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/deprecated"
 )
 
 func TestGenericReader(t *testing.T) {
@@ -150,3 +152,218 @@ func benchmarkGenericReader[Row generator[Row]](b *testing.B) {
 		})
 	})
 }
+
+func TestReaderWideningCasts(t *testing.T) {
+	type FileRow struct {
+		ID    int32   `parquet:"id"`
+		Score float32 `parquet:"score"`
+		Time  int64   `parquet:"time,timestamp(millisecond)"`
+	}
+	type ReadRow struct {
+		ID    int64     `parquet:"id"`
+		Score float64   `parquet:"score"`
+		Time  time.Time `parquet:"time,timestamp(microsecond)"`
+	}
+
+	rows := []FileRow{
+		{ID: 1, Score: 0.5, Time: 1000},
+		{ID: -2, Score: 1.5, Time: 1657794030123},
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := parquet.NewGenericReader[ReadRow](bytes.NewReader(buffer.Bytes()), parquet.WideningCasts(true))
+	defer r.Close()
+
+	values := make([]ReadRow, len(rows))
+	if n, err := r.Read(values); n != len(rows) {
+		t.Fatalf("reading rows: n=%d err=%v", n, err)
+	}
+
+	for i, row := range rows {
+		want := ReadRow{
+			ID:    int64(row.ID),
+			Score: float64(row.Score),
+			Time:  time.UnixMilli(row.Time).UTC(),
+		}
+		if !reflect.DeepEqual(values[i], want) {
+			t.Errorf("row %d mismatch:\nwant = %+v\ngot  = %+v", i, want, values[i])
+		}
+	}
+}
+
+func TestGenericReaderReadBatch(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+	type Batch struct {
+		ID   []int64  `parquet:"id"`
+		Name []string `parquet:"name"`
+	}
+
+	rows := make([]Row, 20)
+	for i := range rows {
+		rows[i] = Row{ID: int64(i), Name: fmt.Sprintf("row-%d", i)}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := parquet.NewGenericReader[Row](bytes.NewReader(buffer.Bytes()))
+	defer r.Close()
+
+	head := make([]Row, 5)
+	if n, err := r.Read(head); n != len(head) {
+		t.Fatalf("reading rows: n=%d err=%v", n, err)
+	}
+
+	batch := Batch{ID: make([]int64, 10), Name: make([]string, 10)}
+	if n, err := r.ReadBatch(&batch); n != 10 || err != nil {
+		t.Fatalf("reading batch: n=%d err=%v", n, err)
+	}
+	for i := range batch.ID {
+		if want := rows[5+i]; batch.ID[i] != want.ID || batch.Name[i] != want.Name {
+			t.Errorf("batch row %d mismatch: want=%+v got={%d %s}", i, want, batch.ID[i], batch.Name[i])
+		}
+	}
+
+	tail := make([]Row, 10)
+	n, _ := r.Read(tail)
+	if !reflect.DeepEqual(tail[:n], rows[15:]) {
+		t.Errorf("rows read after the batch mismatch:\nwant = %+v\ngot  = %+v", rows[15:], tail[:n])
+	}
+}
+
+func TestGenericReaderFlatSchema(t *testing.T) {
+	type Embedded struct {
+		Label string `parquet:"label,dict"`
+	}
+	type Row struct {
+		Embedded
+		Bool    bool             `parquet:"bool"`
+		Int32   int32            `parquet:"int32,dict"`
+		Uint32  uint32           `parquet:"uint32"`
+		Int64   int64            `parquet:"int64,delta"`
+		Uint64  uint64           `parquet:"uint64"`
+		Int96   deprecated.Int96 `parquet:"int96"`
+		Float   float32          `parquet:"float"`
+		Double  float64          `parquet:"double"`
+		String  string           `parquet:"string"`
+		Bytes   []byte           `parquet:"bytes"`
+		Date    int32            `parquet:"date,date"`
+		Created int64            `parquet:"created,timestamp"`
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i] = Row{
+			Embedded: Embedded{Label: fmt.Sprintf("label-%d", prng.Intn(10))},
+			Bool:     prng.Intn(2) == 0,
+			Int32:    prng.Int31(),
+			Uint32:   prng.Uint32(),
+			Int64:    prng.Int63(),
+			Uint64:   prng.Uint64(),
+			Int96:    deprecated.Int96{prng.Uint32(), prng.Uint32(), prng.Uint32()},
+			Float:    prng.Float32(),
+			Double:   prng.Float64(),
+			String:   fmt.Sprintf("string-%d", i),
+			Bytes:    []byte(fmt.Sprintf("bytes-%d", i)),
+			Date:     prng.Int31n(20000),
+			Created:  prng.Int63(),
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	writer := parquet.NewGenericWriter[Row](buffer, parquet.PageBufferSize(256))
+	if _, err := writer.Write(rows); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := parquet.NewGenericReader[Row](bytes.NewReader(buffer.Bytes()))
+	defer reader.Close()
+
+	result := make([]Row, 0, len(rows))
+	batch := make([]Row, 7)
+	for {
+		n, err := reader.Read(batch)
+		result = append(result, batch[:n]...)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if !reflect.DeepEqual(rows, result) {
+		t.Fatal("rows mismatch")
+	}
+
+	if err := reader.SeekToRow(500); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := reader.Read(batch[:3]); n != 3 || err != nil {
+		t.Fatalf("reading rows after seek: n=%d err=%v", n, err)
+	}
+	if !reflect.DeepEqual(batch[:3], rows[500:503]) {
+		t.Errorf("rows read after seek mismatch:\nwant = %+v\ngot  = %+v", rows[500:503], batch[:3])
+	}
+
+	values := make([]parquet.Row, 2)
+	if n, err := reader.ReadRows(values); n != 2 || err != nil {
+		t.Fatalf("reading parquet rows: n=%d err=%v", n, err)
+	}
+	if n, err := reader.Read(batch[:1]); n != 1 || err != nil {
+		t.Fatalf("reading rows after parquet rows: n=%d err=%v", n, err)
+	}
+	if !reflect.DeepEqual(batch[0], rows[505]) {
+		t.Errorf("row read after parquet rows mismatch:\nwant = %+v\ngot  = %+v", rows[505], batch[0])
+	}
+}
+
+func TestGenericReaderDirectMemoryLimit(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+
+	values := make([]Row, 1000)
+	for i := range values {
+		values[i] = Row{ID: int64(i), Name: fmt.Sprintf("row-%d", i)}
+	}
+
+	limiter := parquet.NewMemoryLimiter(1)
+	f := openFileWithRowGroups(t, []rows{makeRows(values)}, parquet.MemoryLimit(limiter))
+
+	// The schema is flat and all columns are required, the rows are read
+	// directly from the column pages into the fields of the Go values.
+	readWithMemoryLimit(t, limiter, func() error {
+		r := parquet.NewGenericReader[Row](f)
+		defer r.Close()
+		found := make([]Row, 0, len(values))
+		buf := make([]Row, 100)
+		for {
+			n, err := r.Read(buf)
+			found = append(found, buf[:n]...)
+			if err != nil {
+				if err != io.EOF {
+					return err
+				}
+				break
+			}
+		}
+		if !reflect.DeepEqual(found, values) {
+			return fmt.Errorf("rows mismatch:\nwant: %+v\ngot:  %+v", values, found)
+		}
+		return nil
+	})
+}
@@ -2,14 +2,20 @@ package parquet_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
+	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/deprecated"
+	"github.com/segmentio/parquet-go/format"
 	"github.com/segmentio/parquet-go/internal/quick"
 )
 
@@ -498,3 +504,389 @@ func TestSeekToRowDictReadMultiplePages(t *testing.T) {
 		t.Fatalf("read != write")
 	}
 }
+
+func TestReaderReadRowsMultiplePages(t *testing.T) {
+	type rowType struct {
+		Name string
+		Tags []string
+	}
+
+	rows := make([]rowType, 100)
+	for i := range rows {
+		rows[i].Name = fmt.Sprintf("name-%02d", i)
+		rows[i].Tags = []string{fmt.Sprintf("tag-%02d", i), fmt.Sprintf("tag-%02d", i+1)}
+	}
+
+	buf := new(bytes.Buffer)
+	schema := parquet.SchemaOf(new(rowType))
+	w := parquet.NewWriter(buf, schema, &parquet.WriterConfig{
+		PageBufferSize: 64,
+	})
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each call to ReadRows spans multiple pages, the byte arrays of rows read
+	// from previous pages must not be overwritten by reading the next pages.
+	r := parquet.NewReader(bytes.NewReader(buf.Bytes()))
+	defer r.Close()
+	buffer := make([]parquet.Row, 17)
+	index := 0
+	for {
+		n, err := r.ReadRows(buffer)
+		for _, row := range buffer[:n] {
+			got := rowType{}
+			if err := schema.Reconstruct(&got, row); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, rows[index]) {
+				t.Fatalf("wrong row at index %d: want=%+v got=%+v", index, rows[index], got)
+			}
+			index++
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if index != len(rows) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(rows), index)
+	}
+}
+
+type testReaderMetrics struct {
+	bytesRead         int64
+	pagesDecoded      int64
+	valuesDecoded     int64
+	pagesDecompressed int64
+	rowsRead          int64
+}
+
+func (m *testReaderMetrics) BytesRead(n int64) { m.bytesRead += n }
+
+func (m *testReaderMetrics) PageDecoded(column int, codec format.CompressionCodec, numValues int64) {
+	m.pagesDecoded++
+	m.valuesDecoded += numValues
+}
+
+func (m *testReaderMetrics) PageDecompressed(codec format.CompressionCodec, size int64, duration time.Duration) {
+	m.pagesDecompressed++
+}
+
+func (m *testReaderMetrics) RowsRead(n int64) { m.rowsRead += n }
+
+func TestReaderMetrics(t *testing.T) {
+	type Row struct {
+		A int64
+		B string
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{A: int64(i), B: fmt.Sprint(i)}
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.Compression(&parquet.Snappy)); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := new(testReaderMetrics)
+	reader := parquet.NewReader(bytes.NewReader(buffer.Bytes()), parquet.Metrics(metrics))
+	defer reader.Close()
+
+	for {
+		row := Row{}
+		if err := reader.Read(&row); err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	if metrics.bytesRead < int64(buffer.Len()) {
+		t.Errorf("not enough bytes read: want>=%d got=%d", buffer.Len(), metrics.bytesRead)
+	}
+	if metrics.pagesDecoded == 0 || metrics.pagesDecompressed == 0 {
+		t.Errorf("no pages were reported: decoded=%d decompressed=%d", metrics.pagesDecoded, metrics.pagesDecompressed)
+	}
+	if want := int64(2 * len(rows)); metrics.valuesDecoded != want {
+		t.Errorf("wrong number of values decoded: want=%d got=%d", want, metrics.valuesDecoded)
+	}
+	if metrics.rowsRead != int64(len(rows)) {
+		t.Errorf("wrong number of rows read: want=%d got=%d", len(rows), metrics.rowsRead)
+	}
+}
+
+func TestReaderInt96Timestamps(t *testing.T) {
+	type Int96Row struct {
+		ID        int32            `parquet:"id,optional"`
+		Timestamp deprecated.Int96 `parquet:"timestamp_col,optional"`
+	}
+	type TimeRow struct {
+		ID        int32     `parquet:"id,optional"`
+		Timestamp time.Time `parquet:"timestamp_col,optional"`
+	}
+
+	f, err := os.Open("testdata/alltypes_plain.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var want []Int96Row
+	r := parquet.NewReader(f)
+	for {
+		row := Int96Row{}
+		if err := r.Read(&row); err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+		want = append(want, row)
+	}
+	if len(want) == 0 {
+		t.Fatal("no rows were read")
+	}
+
+	t.Run("time.Time", func(t *testing.T) {
+		r := parquet.NewReader(f)
+		for i := range want {
+			row := TimeRow{}
+			if err := r.Read(&row); err != nil {
+				t.Fatal(err)
+			}
+			if row.ID != want[i].ID || !row.Timestamp.Equal(want[i].Timestamp.Time()) {
+				t.Errorf("row %d mismatch: want=%d/%v got=%d/%v", i, want[i].ID, want[i].Timestamp.Time(), row.ID, row.Timestamp)
+			}
+		}
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		r := parquet.NewReader(f, parquet.Int96Timestamps(true))
+		leaf, ok := r.Schema().Lookup("timestamp_col")
+		if !ok {
+			t.Fatal("timestamp_col column not found")
+		}
+		if logicalType := leaf.Node.Type().LogicalType(); logicalType == nil || logicalType.Timestamp == nil {
+			t.Fatalf("timestamp_col column was not converted to a timestamp: %v", leaf.Node.Type())
+		}
+
+		rows := make([]parquet.Row, len(want))
+		n, err := r.ReadRows(rows)
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		if n != len(want) {
+			t.Fatalf("wrong number of rows: want=%d got=%d", len(want), n)
+		}
+		for i, row := range rows[:n] {
+			v := row[leaf.ColumnIndex]
+			if v.Kind() != parquet.Int64 || v.Int64() != want[i].Timestamp.UnixNano() {
+				t.Errorf("row %d mismatch: want=%d got=%v", i, want[i].Timestamp.UnixNano(), v)
+			}
+		}
+	})
+}
+
+func TestTimeStructFields(t *testing.T) {
+	type Row struct {
+		Nanos  time.Time  `parquet:"nanos"`
+		Millis time.Time  `parquet:"millis,timestamp(millisecond)"`
+		Opt    *time.Time `parquet:"opt,optional"`
+	}
+
+	now := time.Date(2022, 7, 14, 10, 20, 30, 123456789, time.UTC)
+	rows := []Row{
+		{Nanos: now, Millis: now.Truncate(time.Millisecond), Opt: &now},
+		{Nanos: time.Unix(-1, 5).UTC(), Millis: time.Unix(0, 0).UTC()},
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := parquet.NewReader(bytes.NewReader(buffer.Bytes()))
+	for i := range rows {
+		row := Row{}
+		if err := r.Read(&row); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(row, rows[i]) {
+			t.Errorf("row %d mismatch:\nwant = %+v\ngot  = %+v", i, rows[i], row)
+		}
+	}
+}
+
+func TestReaderDeletedRows(t *testing.T) {
+	type Row struct {
+		ID int64 `parquet:"id"`
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i].ID = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	positions := []int64{0, 1, 2, 10, 11, 50, 98, 99}
+	bitmap := make([]uint64, 2)
+	for _, i := range positions {
+		bitmap[i/64] |= 1 << uint(i%64)
+	}
+
+	for _, test := range []struct {
+		scenario string
+		mask     parquet.DeleteMask
+	}{
+		{scenario: "positions", mask: parquet.DeletePositions(positions)},
+		{scenario: "bitmap", mask: parquet.DeleteBitmap(bitmap)},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			var want []Row
+			for _, row := range rows {
+				if !test.mask.IsDeleted(row.ID) {
+					want = append(want, row)
+				}
+			}
+
+			r := parquet.NewReader(bytes.NewReader(buffer.Bytes()), parquet.DeletedRows(test.mask))
+			var got []Row
+			for {
+				row := Row{}
+				if err := r.Read(&row); err != nil {
+					if err != io.EOF {
+						t.Fatal(err)
+					}
+					break
+				}
+				got = append(got, row)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("rows mismatch:\nwant = %v\ngot  = %v", want, got)
+			}
+
+			if err := r.SeekToRow(0); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]parquet.Row, 3)
+			n, err := r.ReadRows(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n == 0 || buf[0][0].Int64() != want[0].ID {
+				t.Errorf("wrong first row after seeking: n=%d", n)
+			}
+		})
+	}
+}
+
+type contextReaderAt struct {
+	reader io.ReaderAt
+	reads  int
+}
+
+func (r *contextReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	return r.ReadAtContext(context.Background(), b, off)
+}
+
+func (r *contextReaderAt) ReadAtContext(ctx context.Context, b []byte, off int64) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.reads++
+	return r.reader.ReadAt(b, off)
+}
+
+func TestReaderReadRowsContext(t *testing.T) {
+	type Row struct {
+		Value int64
+	}
+
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i].Value = int64(i)
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(1024)); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+	input := &contextReaderAt{reader: bytes.NewReader(data)}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := parquet.OpenFileContext(canceled, input, int64(len(data))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled error when opening the file but got %v", err)
+	}
+
+	f, err := parquet.OpenFile(input, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := parquet.NewReader(f)
+	buf := make([]parquet.Row, 100)
+
+	n, err := reader.ReadRows(buf)
+	if n != len(buf) {
+		t.Fatalf("wrong number of rows read: want=%d got=%d (%v)", len(buf), n, err)
+	}
+
+	// Rows which were already buffered may be returned along with the error,
+	// but no reads must reach the underlying reader.
+	reads := input.reads
+	rowIndex := int64(len(buf))
+	for i := 0; i < 3; i++ {
+		n, err := reader.ReadRowsContext(canceled, buf)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context canceled error but got %v", err)
+		}
+		for _, row := range buf[:n] {
+			if v := row[0].Int64(); v != rowIndex {
+				t.Fatalf("wrong row value: want=%d got=%d", rowIndex, v)
+			}
+			rowIndex++
+		}
+	}
+	if input.reads != reads {
+		t.Errorf("the canceled reads reached the underlying reader: %d reads", input.reads-reads)
+	}
+
+	// Reading with a valid context must resume at the row following the last
+	// row that was returned.
+	for {
+		n, err := reader.ReadRowsContext(context.Background(), buf)
+		for _, row := range buf[:n] {
+			if v := row[0].Int64(); v != rowIndex {
+				t.Fatalf("wrong row value: want=%d got=%d", rowIndex, v)
+			}
+			rowIndex++
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if rowIndex != int64(len(rows)) {
+		t.Errorf("wrong number of rows read: want=%d got=%d", len(rows), rowIndex)
+	}
+}
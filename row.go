@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
+	"time"
 )
 
 const (
 	defaultRowBufferSize = 20
 )
 
+// Type of time.Time values, which are converted to and from the int64 values
+// of TIMESTAMP columns.
+var timeTimeType = reflect.TypeOf(time.Time{})
+
 // Row represents a parquet row as a slice of values.
 //
 // Each value should embed a column index, repetition level, and definition
@@ -34,6 +40,31 @@ func (row Row) Clone() Row {
 	return clone
 }
 
+// CloneRows returns copies of rows which share no pointers with them. The values
+// of all rows are stored in a single slice, and the byte arrays they reference
+// are appended to arena, which is returned extended.
+//
+// See CloneValues for details on how the arena is used.
+func CloneRows(arena []byte, rows []Row) ([]Row, []byte) {
+	numValues, size := 0, 0
+	for _, row := range rows {
+		numValues += len(row)
+		size += byteArraySizeOf(row)
+	}
+
+	clones := make([]Row, len(rows))
+	values := make([]Value, 0, numValues)
+	arena = growArena(arena, size)
+
+	for i, row := range rows {
+		offset := len(values)
+		values, arena = CloneValues(values, arena, row)
+		clones[i] = values[offset:len(values):len(values)]
+	}
+
+	return clones, arena
+}
+
 // Equal returns true if row and other contain the same sequence of values.
 func (row Row) Equal(other Row) bool {
 	if len(row) != len(other) {
@@ -56,6 +87,17 @@ func (row Row) Equal(other Row) bool {
 	return true
 }
 
+// valuesOf returns the values of the column at the given index, which are
+// contiguous in rows ordered by column index.
+func (row Row) valuesOf(columnIndex int16) []Value {
+	i := sort.Search(len(row), func(i int) bool { return row[i].Column() >= int(columnIndex) })
+	j := i
+	for j < len(row) && row[j].Column() == int(columnIndex) {
+		j++
+	}
+	return row[i:j]
+}
+
 func (row Row) startsWith(columnIndex int16) bool {
 	return len(row) > 0 && row[0].Column() == int(columnIndex)
 }
@@ -83,7 +125,9 @@ type RowReader interface {
 	// The buffer of rows passed as argument will be used to store values of
 	// each row read from the reader. If the rows are not nil, the backing array
 	// of the slices will be used as an optimization to avoid re-allocating new
-	// arrays.
+	// arrays. Implementations must overwrite the rows (e.g. by appending to
+	// rows[i][:0]) and must not retain them after returning; see RowBatch for
+	// a way to reuse rows across calls.
 	ReadRows([]Row) (int, error)
 }
 
@@ -380,10 +424,11 @@ func deconstructFuncOf(columnIndex int16, node Node) (int16, deconstructFunc) {
 
 //go:noinline
 func deconstructFuncOfOptional(columnIndex int16, node Node) (int16, deconstructFunc) {
+	nullPolicy := nullPolicyOf(node)
 	columnIndex, deconstruct := deconstructFuncOf(columnIndex, Required(node))
 	return columnIndex, func(row Row, levels levels, value reflect.Value) Row {
 		if value.IsValid() {
-			if value.IsZero() {
+			if nullPolicy.isNull(value) {
 				value = reflect.Value{}
 			} else {
 				if value.Kind() == reflect.Ptr {
@@ -487,12 +532,17 @@ func deconstructFuncOfLeaf(columnIndex int16, node Node) (int16, deconstructFunc
 		panic("row cannot be deconstructed because it has more than 127 columns")
 	}
 	kind := node.Type().Kind()
+	timestamp, _ := node.Type().(*timestampType)
 	valueColumnIndex := ^columnIndex
 	return columnIndex + 1, func(row Row, levels levels, value reflect.Value) Row {
 		v := Value{}
 
 		if value.IsValid() {
-			v = makeValue(kind, value)
+			if timestamp != nil && value.Type() == timeTimeType {
+				v = makeValueInt64(timestamp.unixValue(value.Interface().(time.Time)))
+			} else {
+				v = makeValue(kind, value)
+			}
 		}
 
 		v.repetitionLevel = levels.repetitionLevel
@@ -676,10 +726,15 @@ func reconstructFuncOfGroup(columnIndex int16, node Node) (int16, reconstructFun
 
 //go:noinline
 func reconstructFuncOfLeaf(columnIndex int16, node Node) (int16, reconstructFunc) {
+	timestamp, _ := node.Type().(*timestampType)
 	return columnIndex + 1, func(value reflect.Value, _ levels, row Row) (Row, error) {
 		if !row.startsWith(columnIndex) {
 			return row, fmt.Errorf("no values found in parquet row for column %d", columnIndex)
 		}
+		if timestamp != nil && !row[0].IsNull() && value.Type() == timeTimeType {
+			value.Set(reflect.ValueOf(timestamp.unixTime(row[0].Int64())))
+			return row[1:], nil
+		}
 		return row[1:], assignValue(value, row[0])
 	}
 }
@@ -0,0 +1,408 @@
+package parquet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RowBuilder is a type which helps build parquet rows incrementally, without
+// requiring Go types to represent the values.
+//
+// Values are set on leaf columns addressed by their path in the schema, where
+// the names of fields are separated by dots, and elements of repeated fields,
+// lists and maps are addressed by an index in square brackets. For example,
+// with the following schema:
+//
+//	message User {
+//		required int64 id;
+//		optional group profile {
+//			required binary name (STRING);
+//			optional group tags (LIST) {
+//				repeated group list {
+//					required binary element (STRING);
+//				}
+//			}
+//		}
+//	}
+//
+// The path "profile.tags[2]" addresses the third element of the tags list. The
+// key and value of map entries are addressed by the paths "m[i].key" and
+// "m[i].value". An index may designate an existing element, or be equal to the
+// number of elements to append a new one.
+//
+// The repetition and definition levels of values are computed when the row is
+// built: optional groups which had no values set are null, and lists which had
+// no elements appended are empty. Missing values of required columns cause the
+// construction of the row to fail.
+type RowBuilder struct {
+	schema Node
+	root   *rowBuilderNode
+	build  rowBuilderFunc
+}
+
+// NewRowBuilder constructs a RowBuilder building rows of the given schema,
+// which is usually a *Schema value.
+func NewRowBuilder(schema Node) *RowBuilder {
+	_, build := rowBuilderFuncOf(0, schema)
+	return &RowBuilder{
+		schema: schema,
+		root:   &rowBuilderNode{defined: true},
+		build:  build,
+	}
+}
+
+// Schema returns the schema of rows built by b.
+func (b *RowBuilder) Schema() Node { return b.schema }
+
+// Reset clears the values set on b, allowing it to be reused to build a new
+// row.
+func (b *RowBuilder) Reset() { b.root = &rowBuilderNode{defined: true} }
+
+// Set sets the value of the leaf column at the given path.
+//
+// Setting a null value on an optional column leaves it null, but marks its
+// parent groups as present. The method returns an error if the path does not
+// exist in the schema, if it does not designate a leaf column, or if the value
+// is not of the column's type.
+func (b *RowBuilder) Set(path string, value Value) error {
+	_, leaf, err := b.lookup(path, false)
+	if err != nil {
+		return err
+	}
+	if err := checkRowBuilderValue(path, leaf, value); err != nil {
+		return err
+	}
+	node, _, _ := b.lookup(path, true)
+	node.set(value)
+	return nil
+}
+
+// StartList marks the repeated field, list, or map at the given path as
+// present, so an optional list with no elements is represented as empty rather
+// than null.
+func (b *RowBuilder) StartList(path string) error {
+	if _, _, err := b.lookupList(path, false); err != nil {
+		return err
+	}
+	b.lookupList(path, true)
+	return nil
+}
+
+// Append appends a value to the repeated leaf column or list of leaf values at
+// the given path.
+func (b *RowBuilder) Append(path string, value Value) error {
+	_, elem, err := b.lookupList(path, false)
+	if err != nil {
+		return err
+	}
+	if !elem.Leaf() {
+		return fmt.Errorf("%s: cannot append a value to a list of groups", path)
+	}
+	if err := checkRowBuilderValue(path, elem, value); err != nil {
+		return err
+	}
+	list, _, _ := b.lookupList(path, true)
+	node := new(rowBuilderNode)
+	node.set(value)
+	list.elems = append(list.elems, node)
+	return nil
+}
+
+// Row builds a row from the values set on b.
+func (b *RowBuilder) Row() (Row, error) { return b.AppendRow(nil) }
+
+// AppendRow appends the values of the row built from the values set on b to
+// row, and returns the extended row.
+func (b *RowBuilder) AppendRow(row Row) (Row, error) {
+	return b.build(row, levels{}, b.root)
+}
+
+func (b *RowBuilder) lookupList(path string, create bool) (*rowBuilderNode, Node, error) {
+	node, field, err := b.lookup(path, create)
+	if err != nil {
+		return nil, nil, err
+	}
+	elem := repeatedElementOf(field)
+	if elem == nil {
+		return nil, nil, fmt.Errorf("%s: not a repeated field, list, or map", path)
+	}
+	return node, elem, nil
+}
+
+// lookup walks the path in the schema and in the tree of values set on b. When
+// create is false, the tree is not modified and the returned node may be nil,
+// which allows validating paths before setting values.
+func (b *RowBuilder) lookup(path string, create bool) (*rowBuilderNode, Node, error) {
+	node, schema := b.root, b.schema
+
+	parts := strings.Split(path, ".")
+
+	for n, part := range parts {
+		name, index, err := parseRowBuilderPathPart(part)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if schema.Leaf() {
+			return nil, nil, fmt.Errorf("%s: %s is not a group", path, part)
+		}
+
+		fields := schema.Fields()
+		i := 0
+		for i < len(fields) && fields[i].Name() != name {
+			i++
+		}
+		if i == len(fields) {
+			return nil, nil, fmt.Errorf("%s: no field named %q", path, name)
+		}
+		field := fields[i]
+
+		var child *rowBuilderNode
+		if node != nil {
+			if node.fields == nil && create {
+				node.fields = make([]*rowBuilderNode, len(fields))
+			}
+			if node.fields != nil {
+				child = node.fields[i]
+			}
+			if child == nil && create {
+				child = new(rowBuilderNode)
+				node.fields[i] = child
+			}
+			if create {
+				child.defined = true
+			}
+		}
+
+		elem := repeatedElementOf(field)
+		if index < 0 {
+			if elem != nil && n < len(parts)-1 {
+				return nil, nil, fmt.Errorf("%s: %s is repeated and must be indexed", path, name)
+			}
+			node, schema = child, field
+			continue
+		}
+		if elem == nil {
+			return nil, nil, fmt.Errorf("%s: %s is not a repeated field, list, or map", path, name)
+		}
+
+		var numElems int
+		if child != nil {
+			numElems = len(child.elems)
+		}
+		switch {
+		case index < numElems:
+			node = child.elems[index]
+		case index == numElems:
+			node = nil
+			if create {
+				node = &rowBuilderNode{defined: true}
+				child.elems = append(child.elems, node)
+			}
+		default:
+			return nil, nil, fmt.Errorf("%s: index %d out of range of %s with %d elements", path, index, name, numElems)
+		}
+		schema = elem
+	}
+
+	return node, schema, nil
+}
+
+// parseRowBuilderPathPart parses a path element of the form "name" or
+// "name[index]", returning -1 as index when the element is not indexed.
+func parseRowBuilderPathPart(part string) (name string, index int, err error) {
+	i := strings.IndexByte(part, '[')
+	if i < 0 {
+		if part == "" {
+			return "", -1, fmt.Errorf("empty field name")
+		}
+		return part, -1, nil
+	}
+	if i == 0 || !strings.HasSuffix(part, "]") {
+		return "", -1, fmt.Errorf("malformed path element %q", part)
+	}
+	index, err = strconv.Atoi(part[i+1 : len(part)-1])
+	if err != nil || index < 0 {
+		return "", -1, fmt.Errorf("invalid index in path element %q", part)
+	}
+	return part[:i], index, nil
+}
+
+func checkRowBuilderValue(path string, leaf Node, value Value) error {
+	if !leaf.Leaf() {
+		return fmt.Errorf("%s: cannot set a value on a group", path)
+	}
+	if repeatedElementOf(leaf) != nil {
+		return fmt.Errorf("%s: cannot set a value on a repeated column, use an index or append to it", path)
+	}
+	if value.IsNull() {
+		if !leaf.Optional() {
+			return fmt.Errorf("%s: cannot set a null value on a required column", path)
+		}
+		return nil
+	}
+	typ := leaf.Type()
+	if value.Kind() != typ.Kind() {
+		return fmt.Errorf("%s: cannot set a value of kind %s on a column of type %s", path, value.Kind(), typ)
+	}
+	if value.Kind() == FixedLenByteArray && len(value.ByteArray()) != typ.Length() {
+		return fmt.Errorf("%s: cannot set a value of length %d on a column of type %s", path, len(value.ByteArray()), typ)
+	}
+	return nil
+}
+
+// repeatedElementOf returns the node representing the elements of a repeated
+// field, list, or map, or nil if node is not one of those.
+func repeatedElementOf(node Node) Node {
+	switch {
+	case node.Repeated():
+		return Required(node)
+	case isList(node):
+		return Required(listElementOf(node))
+	case isMap(node):
+		return Required(mapKeyValueOf(node))
+	default:
+		return nil
+	}
+}
+
+// rowBuilderNode is the representation of values set on a RowBuilder. Nodes
+// are created when paths are set, the defined field indicates whether a group
+// or list was created by setting a path, or a leaf was set to a non-null value.
+type rowBuilderNode struct {
+	defined bool
+	isSet   bool
+	value   Value
+	fields  []*rowBuilderNode
+	elems   []*rowBuilderNode
+}
+
+func (node *rowBuilderNode) set(value Value) {
+	node.defined = !value.IsNull()
+	node.isSet = true
+	node.value = value
+}
+
+// emptyRowBuilderNode is used in place of the fields of groups which had no
+// values set, it must not be modified.
+var emptyRowBuilderNode = &rowBuilderNode{}
+
+// rowBuilderFunc appends the values of the node to the row. A nil node means
+// that a parent is null or empty, in which case null values are appended.
+type rowBuilderFunc func(Row, levels, *rowBuilderNode) (Row, error)
+
+func rowBuilderFuncOf(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	switch {
+	case node.Optional():
+		return rowBuilderFuncOfOptional(columnIndex, node)
+	case node.Repeated():
+		return rowBuilderFuncOfRepeated(columnIndex, node)
+	case isList(node):
+		return rowBuilderFuncOfList(columnIndex, node)
+	case isMap(node):
+		return rowBuilderFuncOfMap(columnIndex, node)
+	default:
+		return rowBuilderFuncOfRequired(columnIndex, node)
+	}
+}
+
+//go:noinline
+func rowBuilderFuncOfOptional(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	columnIndex, build := rowBuilderFuncOf(columnIndex, Required(node))
+	return columnIndex, func(row Row, levels levels, node *rowBuilderNode) (Row, error) {
+		if node != nil && node.defined {
+			levels.definitionLevel++
+		} else {
+			node = nil
+		}
+		return build(row, levels, node)
+	}
+}
+
+//go:noinline
+func rowBuilderFuncOfRepeated(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	columnIndex, build := rowBuilderFuncOf(columnIndex, Required(node))
+	return columnIndex, func(row Row, levels levels, node *rowBuilderNode) (Row, error) {
+		if node == nil || len(node.elems) == 0 {
+			return build(row, levels, nil)
+		}
+
+		levels.repetitionDepth++
+		levels.definitionLevel++
+
+		for i, elem := range node.elems {
+			var err error
+			if row, err = build(row, levels, elem); err != nil {
+				return row, fmt.Errorf("[%d] → %w", i, err)
+			}
+			levels.repetitionLevel = levels.repetitionDepth
+		}
+
+		return row, nil
+	}
+}
+
+func rowBuilderFuncOfRequired(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	switch {
+	case node.Leaf():
+		return rowBuilderFuncOfLeaf(columnIndex, node)
+	default:
+		return rowBuilderFuncOfGroup(columnIndex, node)
+	}
+}
+
+func rowBuilderFuncOfList(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	return rowBuilderFuncOf(columnIndex, Repeated(listElementOf(node)))
+}
+
+func rowBuilderFuncOfMap(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	return rowBuilderFuncOf(columnIndex, Repeated(mapKeyValueOf(node)))
+}
+
+//go:noinline
+func rowBuilderFuncOfGroup(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	fields := node.Fields()
+	funcs := make([]rowBuilderFunc, len(fields))
+	for i, field := range fields {
+		columnIndex, funcs[i] = rowBuilderFuncOf(columnIndex, field)
+	}
+	return columnIndex, func(row Row, levels levels, node *rowBuilderNode) (Row, error) {
+		for i, f := range funcs {
+			var field *rowBuilderNode
+			if node != nil {
+				field = emptyRowBuilderNode
+				if node.fields != nil && node.fields[i] != nil {
+					field = node.fields[i]
+				}
+			}
+			var err error
+			if row, err = f(row, levels, field); err != nil {
+				return row, fmt.Errorf("%s → %w", fields[i].Name(), err)
+			}
+		}
+		return row, nil
+	}
+}
+
+//go:noinline
+func rowBuilderFuncOfLeaf(columnIndex int16, node Node) (int16, rowBuilderFunc) {
+	if columnIndex > MaxColumnIndex {
+		panic("row cannot be built because it has more than 127 columns")
+	}
+	valueColumnIndex := ^columnIndex
+	return columnIndex + 1, func(row Row, levels levels, node *rowBuilderNode) (Row, error) {
+		v := Value{}
+
+		if node != nil {
+			if !node.isSet {
+				return row, fmt.Errorf("missing value of required column %d", columnIndex)
+			}
+			v = node.value
+		}
+
+		v.repetitionLevel = levels.repetitionLevel
+		v.definitionLevel = levels.definitionLevel
+		v.columnIndex = valueColumnIndex
+		return append(row, v), nil
+	}
+}
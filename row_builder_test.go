@@ -0,0 +1,204 @@
+package parquet_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestRowBuilder(t *testing.T) {
+	type Profile struct {
+		Name string   `parquet:"name"`
+		Tags []string `parquet:"tags,optional,list"`
+	}
+
+	type Item struct {
+		Name  string `parquet:"name"`
+		Count int32  `parquet:"count"`
+	}
+
+	type Record struct {
+		ID      int64            `parquet:"id"`
+		Profile *Profile         `parquet:"profile,optional"`
+		Scores  []float64        `parquet:"scores"`
+		Items   []Item           `parquet:"items"`
+		Attrs   map[string]int64 `parquet:"attrs"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+
+	type set struct {
+		path  string
+		value interface{}
+	}
+
+	tests := []struct {
+		scenario string
+		sets     []set
+		appends  []set
+		lists    []string
+		record   Record
+	}{
+		{
+			scenario: "required values only",
+			sets:     []set{{"id", int64(1)}},
+			record:   Record{ID: 1},
+		},
+
+		{
+			scenario: "optional group with null list",
+			sets:     []set{{"id", int64(2)}, {"profile.name", "Luke"}},
+			record:   Record{ID: 2, Profile: &Profile{Name: "Luke"}},
+		},
+
+		{
+			scenario: "optional group with empty list",
+			sets:     []set{{"id", int64(3)}, {"profile.name", "Leia"}},
+			lists:    []string{"profile.tags"},
+			record:   Record{ID: 3, Profile: &Profile{Name: "Leia", Tags: []string{}}},
+		},
+
+		{
+			scenario: "nested repeated values",
+			sets: []set{
+				{"id", int64(4)},
+				{"profile.name", "Han"},
+				{"profile.tags[0]", "pilot"},
+				{"profile.tags[1]", "smuggler"},
+				{"profile.tags[0]", "captain"},
+				{"items[0].name", "blaster"},
+				{"items[0].count", int32(1)},
+				{"items[1].count", int32(2)},
+				{"items[1].name", "dice"},
+				{"attrs[0].key", "credits"},
+				{"attrs[0].value", int64(-100)},
+			},
+			appends: []set{{"scores", 1.5}, {"scores", 2.5}, {"profile.tags", "scoundrel"}},
+			record: Record{
+				ID:      4,
+				Profile: &Profile{Name: "Han", Tags: []string{"captain", "smuggler", "scoundrel"}},
+				Scores:  []float64{1.5, 2.5},
+				Items:   []Item{{Name: "blaster", Count: 1}, {Name: "dice", Count: 2}},
+				Attrs:   map[string]int64{"credits": -100},
+			},
+		},
+	}
+
+	b := parquet.NewRowBuilder(schema)
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			b.Reset()
+
+			for _, list := range test.lists {
+				if err := b.StartList(list); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, s := range test.sets {
+				if err := b.Set(s.path, parquet.ValueOf(s.value)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, a := range test.appends {
+				if err := b.Append(a.path, parquet.ValueOf(a.value)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			row, err := b.Row()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := schema.Deconstruct(nil, &test.record)
+			if !row.Equal(want) {
+				t.Errorf("rows mismatch\nwant = %+v\ngot  = %+v", want, row)
+			}
+
+			record := Record{}
+			if err := schema.Reconstruct(&record, row); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRowBuilderErrors(t *testing.T) {
+	type Item struct {
+		Name  string `parquet:"name"`
+		Count int32  `parquet:"count"`
+	}
+
+	type Record struct {
+		ID    int64   `parquet:"id"`
+		Name  *string `parquet:"name,optional"`
+		Tags  []int32 `parquet:"tags"`
+		Items []Item  `parquet:"items"`
+	}
+
+	b := parquet.NewRowBuilder(parquet.SchemaOf(Record{}))
+
+	errors := []struct {
+		scenario string
+		err      error
+	}{
+		{"unknown field", b.Set("nope", parquet.ValueOf(int64(0)))},
+		{"field of a leaf", b.Set("id.nope", parquet.ValueOf(int64(0)))},
+		{"wrong kind", b.Set("id", parquet.ValueOf("0"))},
+		{"null required value", b.Set("id", parquet.ValueOf(nil))},
+		{"value on a group", b.Set("items[0]", parquet.ValueOf(int64(0)))},
+		{"index out of range", b.Set("tags[1]", parquet.ValueOf(int32(0)))},
+		{"negative index", b.Set("tags[-1]", parquet.ValueOf(int32(0)))},
+		{"malformed path", b.Set("tags[0", parquet.ValueOf(int32(0)))},
+		{"repeated field not indexed", b.Set("items.name", parquet.ValueOf("A"))},
+		{"repeated column not indexed", b.Set("tags", parquet.ValueOf(int32(0)))},
+		{"index on a required field", b.Set("id[0]", parquet.ValueOf(int64(0)))},
+		{"append to a required field", b.Append("id", parquet.ValueOf(int64(0)))},
+		{"append to a list of groups", b.Append("items", parquet.ValueOf("A"))},
+		{"start list on a required field", b.StartList("id")},
+	}
+
+	for _, e := range errors {
+		if e.err == nil {
+			t.Errorf("%s: expected an error", e.scenario)
+		}
+	}
+
+	// Failed calls must not have modified the builder.
+	if err := b.Set("id", parquet.ValueOf(int64(1))); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Set("name", parquet.ValueOf(nil)); err != nil {
+		t.Fatal(err)
+	}
+	row, err := b.Row()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := parquet.SchemaOf(Record{}).Deconstruct(nil, &Record{ID: 1}); !row.Equal(want) {
+		t.Errorf("rows mismatch\nwant = %+v\ngot  = %+v", want, row)
+	}
+
+	b.Reset()
+	if _, err := b.Row(); err == nil {
+		t.Error("expected an error building a row with a missing required value")
+	}
+
+	b.Reset()
+	b.Set("id", parquet.ValueOf(int64(1)))
+	b.Set("items[0].name", parquet.ValueOf("A"))
+	b.Set("items[0].count", parquet.ValueOf(int32(1)))
+	b.Set("items[1].name", parquet.ValueOf("B"))
+	if _, err := b.Row(); err == nil {
+		t.Error("expected an error building a row with a missing required value in a repeated group")
+	}
+	b.Reset()
+	b.Set("id", parquet.ValueOf(int64(1)))
+	b.StartList("items")
+	if row, err := b.Row(); err != nil {
+		t.Fatal(err)
+	} else if want := parquet.SchemaOf(Record{}).Deconstruct(nil, &Record{ID: 1}); !row.Equal(want) {
+		t.Errorf("rows mismatch\nwant = %+v\ngot  = %+v", want, row)
+	}
+}
@@ -0,0 +1,98 @@
+package parquet
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// formatByteArrayLimit is the maximum number of bytes of byte array values
+	// displayed by FormatRow, longer values are truncated.
+	formatByteArrayLimit = 64
+)
+
+// FormatRow returns a human-readable representation of row, intended to be
+// used in logs and error messages.
+//
+// The leaf columns are rendered as space separated name=value pairs, where the
+// name is the path of the column with its parts separated by dots. Values are
+// formatted according to the logical type of their column: timestamps, dates,
+// and times in RFC 3339 format, decimals and UUIDs in their canonical forms,
+// and strings and other byte arrays quoted, truncated to 64 bytes. Null values
+// are rendered as null, and values of repeated columns between brackets. For
+// example:
+//
+//	user.id=42 user.name="Luke" ts=2023-07-01T10:00:00Z tags=["a" "b"]
+//
+// Values of columns which do not exist in the schema are ignored.
+func (s *Schema) FormatRow(row Row) string {
+	b := make([]byte, 0, 128)
+	forEachLeafColumnOf(s, func(leaf leafColumn) {
+		if leaf.columnIndex > 0 {
+			b = append(b, ' ')
+		}
+		for i, name := range leaf.path {
+			if i > 0 {
+				b = append(b, '.')
+			}
+			b = append(b, name...)
+		}
+		b = append(b, '=')
+
+		typ := leaf.node.Type()
+		values := row.valuesOf(leaf.columnIndex)
+
+		if leaf.maxRepetitionLevel == 0 {
+			if len(values) == 0 {
+				b = append(b, "null"...)
+			} else {
+				b = appendFormattedValue(b, typ, values[0])
+			}
+			return
+		}
+
+		// A single null value in a repeated column indicates that the list
+		// is empty or one of its parents is null.
+		if len(values) == 1 && values[0].IsNull() {
+			values = nil
+		}
+		b = append(b, '[')
+		for i, v := range values {
+			if i > 0 {
+				b = append(b, ' ')
+			}
+			b = appendFormattedValue(b, typ, v)
+		}
+		b = append(b, ']')
+	})
+	return string(b)
+}
+
+func appendFormattedValue(b []byte, typ Type, v Value) []byte {
+	if v.IsNull() {
+		return append(b, "null"...)
+	}
+	switch v.Kind() {
+	case ByteArray, FixedLenByteArray:
+		if lt := typ.LogicalType(); lt == nil || (lt.UUID == nil && lt.Decimal == nil) {
+			return appendTruncatedQuote(b, v.ByteArray(), formatByteArrayLimit)
+		}
+	}
+	return append(b, formatValue(typ, v)...)
+}
+
+// appendTruncatedQuote appends the quoted representation of data to b, with
+// data truncated to at most limit bytes on a rune boundary.
+func appendTruncatedQuote(b, data []byte, limit int) []byte {
+	if len(data) <= limit {
+		return strconv.AppendQuote(b, string(data))
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	if n == 0 {
+		n = limit
+	}
+	return strconv.AppendQuote(b, string(data[:n])+"...")
+}
@@ -0,0 +1,64 @@
+package parquet_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSchemaFormatRow(t *testing.T) {
+	type User struct {
+		ID   int64   `parquet:"id"`
+		Name *string `parquet:"name,optional"`
+	}
+
+	type Record struct {
+		User  User      `parquet:"user"`
+		TS    time.Time `parquet:"ts"`
+		Day   int32     `parquet:"day,date"`
+		Price int64     `parquet:"price,decimal(2:18)"`
+		Key   [16]byte  `parquet:"key,uuid"`
+		Data  []byte    `parquet:"data"`
+		Tags  []string  `parquet:"tags"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+
+	tests := []struct {
+		scenario string
+		record   Record
+		format   string
+	}{
+		{
+			scenario: "empty",
+			record:   Record{TS: time.Unix(0, 0).UTC()},
+			format: `user.id=0 user.name=null ts=1970-01-01T00:00:00Z day=1970-01-01 price=0.00 ` +
+				`key=00000000-0000-0000-0000-000000000000 data="" tags=[]`,
+		},
+		{
+			scenario: "values",
+			record: Record{
+				User:  User{ID: 42, Name: newString("Luke \"Skywalker\"")},
+				TS:    time.Date(2023, 7, 1, 10, 0, 0, 0, time.UTC),
+				Day:   int32(time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC).Unix() / 86400),
+				Price: -1205,
+				Key:   uuid.MustParse("3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f"),
+				Data:  []byte(strings.Repeat("é", 40)),
+				Tags:  []string{"a", "b"},
+			},
+			format: `user.id=42 user.name="Luke \"Skywalker\"" ts=2023-07-01T10:00:00Z day=2000-02-29 price=-12.05 ` +
+				`key=3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f data="` + strings.Repeat("é", 32) + `..." tags=["a" "b"]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			if format := schema.FormatRow(schema.Deconstruct(nil, &test.record)); format != test.format {
+				t.Errorf("wrong row format\nwant = %s\ngot  = %s", test.format, format)
+			}
+		})
+	}
+}
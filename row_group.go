@@ -1,8 +1,10 @@
 package parquet
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"sort"
 )
 
 // RowGroup is an interface representing a parquet row group. From the Parquet
@@ -194,7 +196,11 @@ func MergeRowGroups(rowGroups []RowGroup, options ...RowGroupOption) (RowGroup,
 		}
 	}
 
-	m := &mergedRowGroup{sorting: config.SortingColumns}
+	m := &mergedRowGroup{
+		sorting:     config.SortingColumns,
+		duplicates:  config.DuplicateRows,
+		concurrency: config.MergeConcurrency,
+	}
 	m.init(schema, mergedRowGroups)
 
 	if len(m.sorting) == 0 {
@@ -211,24 +217,7 @@ func MergeRowGroups(rowGroups []RowGroup, options ...RowGroupOption) (RowGroup,
 		}
 	}
 
-	m.sortFuncs = make([]columnSortFunc, len(m.sorting))
-	forEachLeafColumnOf(schema, func(leaf leafColumn) {
-		if sortingIndex := searchSortingColumn(m.sorting, leaf.path); sortingIndex < len(m.sorting) {
-			m.sortFuncs[sortingIndex] = columnSortFunc{
-				columnIndex: leaf.columnIndex,
-				compare: sortFuncOf(
-					leaf.node.Type(),
-					&SortConfig{
-						MaxRepetitionLevel: int(leaf.maxRepetitionLevel),
-						MaxDefinitionLevel: int(leaf.maxDefinitionLevel),
-						Descending:         m.sorting[sortingIndex].Descending(),
-						NullsFirst:         m.sorting[sortingIndex].NullsFirst(),
-					},
-				),
-			}
-		}
-	})
-
+	m.sortColumns = mergeSortColumnsOf(schema, m.sorting)
 	return m, nil
 }
 
@@ -245,16 +234,82 @@ func (r *rowGroup) SortingColumns() []SortingColumn { return r.sorting }
 func (r *rowGroup) Schema() *Schema                 { return r.schema }
 func (r *rowGroup) Rows() Rows                      { return &rowGroupRows{rowGroup: r} }
 
+func (r *rowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	return &rowGroupRows{rowGroup: r, memory: m}
+}
+
 func NewRowGroupRowReader(rowGroup RowGroup) Rows {
 	return &rowGroupRows{rowGroup: rowGroup}
 }
 
+// NewRowGroupRowRangeReader returns a reader of the rows of the row group which
+// are within the given ranges, for example the ranges of a RowGroupMatch
+// returned by RowGroupFilter.Match.
+//
+// The column readers are positioned at the beginning of each range, which uses
+// the offset index of the column chunks to skip the pages between ranges
+// without reading them. Pages which precede the first row of a range within the
+// same column chunk are discarded without being decompressed when their number
+// of rows is known.
+//
+// The ranges may be passed in any order and may overlap, rows are returned in
+// order and at most once. SeekToRow positions the reader at the first row of the
+// ranges at or after the given row index.
+func NewRowGroupRowRangeReader(rowGroup RowGroup, ranges []RowRange) Rows {
+	return &rowGroupRows{rowGroup: rowGroup, ranges: normalizeRowRanges(ranges, rowGroup.NumRows())}
+}
+
 type rowGroupRows struct {
 	rowGroup RowGroup
 	columns  []columnChunkReader
 	seek     int64
 	inited   bool
 	closed   bool
+	// When the reader is restricted to ranges of rows, the index of the next
+	// row read and of the range that it belongs to.
+	ranges     []RowRange
+	rangeIndex int
+	rowIndex   int64
+	// Reservation of the memory of the pages of the columns, or nil if the
+	// reader has its own.
+	memory *memoryReservation
+}
+
+// normalizeRowRanges returns a sorted copy of ranges where overlapping and
+// adjacent ranges are merged and rows beyond the end of the row group removed.
+// The returned slice is never nil.
+func normalizeRowRanges(ranges []RowRange, numRows int64) []RowRange {
+	sorted := make([]RowRange, 0, len(ranges))
+	for _, r := range ranges {
+		begin, end := r.FirstRowIndex, r.FirstRowIndex+r.NumRows
+		if begin < 0 {
+			begin = 0
+		}
+		if end > numRows {
+			end = numRows
+		}
+		if begin < end {
+			sorted = append(sorted, RowRange{FirstRowIndex: begin, NumRows: end - begin})
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FirstRowIndex < sorted[j].FirstRowIndex
+	})
+
+	merged := sorted[:0]
+	for _, r := range sorted {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if lastEnd := last.FirstRowIndex + last.NumRows; r.FirstRowIndex <= lastEnd {
+				if end := r.FirstRowIndex + r.NumRows; end > lastEnd {
+					last.NumRows = end - last.FirstRowIndex
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
 }
 
 func (r *rowGroupRows) init() {
@@ -262,10 +317,21 @@ func (r *rowGroupRows) init() {
 	columns := r.rowGroup.ColumnChunks()
 	buffer := make([]Value, columnBufferSize*len(columns))
 	r.columns = make([]columnChunkReader, len(columns))
+	// The memory of the pages of all columns is reserved on behalf of the row
+	// reader so it never blocks while holding pages of other columns.
+	memory := r.memory
+	if memory == nil {
+		memory = new(memoryReservation)
+	}
 
 	for i, column := range columns {
 		r.columns[i].buffer = buffer[:0:columnBufferSize]
-		r.columns[i].reader = column.Pages()
+		r.columns[i].reader = pagesWithMemory(column, memory)
+		r.columns[i].column = i
+		switch column.Type().Kind() {
+		case ByteArray, FixedLenByteArray:
+			r.columns[i].byteArrays = true
+		}
 		buffer = buffer[columnBufferSize:]
 	}
 
@@ -280,6 +346,8 @@ func (r *rowGroupRows) Reset() {
 		r.columns[i].seekToRow(0)
 	}
 	r.seek = 0
+	r.rangeIndex = 0
+	r.rowIndex = 0
 }
 
 func (r *rowGroupRows) Close() error {
@@ -312,10 +380,21 @@ func (r *rowGroupRows) SeekToRow(rowIndex int64) error {
 	}
 
 	r.seek = rowIndex
+	r.rangeIndex = 0
+	r.rowIndex = rowIndex
 	return nil
 }
 
 func (r *rowGroupRows) ReadRows(rows []Row) (int, error) {
+	return r.readRows(context.Background(), rows)
+}
+
+// ReadRowsContext is like ReadRows but uses ctx to cancel the reads of pages.
+func (r *rowGroupRows) ReadRowsContext(ctx context.Context, rows []Row) (int, error) {
+	return r.readRows(ctx, rows)
+}
+
+func (r *rowGroupRows) readRows(ctx context.Context, rows []Row) (int, error) {
 	if !r.inited {
 		r.init()
 		if r.seek > 0 {
@@ -329,11 +408,70 @@ func (r *rowGroupRows) ReadRows(rows []Row) (int, error) {
 		return 0, io.EOF
 	}
 
+	// Contexts which are never canceled (e.g. context.Background) are not set
+	// on the column readers, which avoids the overhead of checking them.
+	if ctx.Done() != nil {
+		for i := range r.columns {
+			r.columns[i].ctx = ctx
+		}
+		defer func() {
+			for i := range r.columns {
+				r.columns[i].ctx = nil
+			}
+		}()
+	}
+
 	for i := range rows {
 		rows[i] = rows[i][:0]
 	}
 
-	return r.rowGroup.Schema().readRows(rows, 0, r.columns)
+	// Reading the rows may span multiple pages of the columns, the values read
+	// from the previous pages are retained by the column readers.
+	for i := range r.columns {
+		r.columns[i].begin(rows)
+	}
+	defer func() {
+		for i := range r.columns {
+			r.columns[i].end()
+		}
+	}()
+
+	if r.ranges == nil {
+		return r.rowGroup.Schema().readRows(rows, 0, r.columns)
+	}
+
+	for r.rangeIndex < len(r.ranges) {
+		rowRange := r.ranges[r.rangeIndex]
+		end := rowRange.FirstRowIndex + rowRange.NumRows
+		if r.rowIndex >= end {
+			r.rangeIndex++
+			continue
+		}
+
+		if r.rowIndex < rowRange.FirstRowIndex {
+			for i := range r.columns {
+				if err := r.columns[i].seekToRow(rowRange.FirstRowIndex); err != nil {
+					return 0, err
+				}
+			}
+			r.rowIndex = rowRange.FirstRowIndex
+		}
+
+		if remain := end - r.rowIndex; int64(len(rows)) > remain {
+			rows = rows[:remain]
+		}
+		n, err := r.rowGroup.Schema().readRows(rows, 0, r.columns)
+		r.rowIndex += int64(n)
+		if err == io.EOF && r.rowIndex < end {
+			err = io.ErrUnexpectedEOF
+		}
+		if err == io.EOF && r.rangeIndex+1 < len(r.ranges) {
+			err = nil
+		}
+		return n, err
+	}
+
+	return 0, io.EOF
 }
 
 /*
@@ -415,7 +553,11 @@ func (g *seekRowGroup) SortingColumns() []SortingColumn {
 }
 
 func (g *seekRowGroup) Rows() Rows {
-	rows := g.base.Rows()
+	return g.rowsWithMemory(nil)
+}
+
+func (g *seekRowGroup) rowsWithMemory(m *memoryReservation) Rows {
+	rows := rowsWithMemory(g.base, m)
 	rows.SeekToRow(g.seek)
 	return rows
 }
@@ -434,7 +576,11 @@ func (c *seekColumnChunk) Column() int {
 }
 
 func (c *seekColumnChunk) Pages() Pages {
-	pages := c.base.Pages()
+	return c.pagesWithMemory(nil)
+}
+
+func (c *seekColumnChunk) pagesWithMemory(m *memoryReservation) Pages {
+	pages := pagesWithMemory(c.base, m)
 	pages.SeekToRow(c.seek)
 	return pages
 }
@@ -515,6 +661,51 @@ func (emptyPages) ReadPage() (Page, error) { return nil, io.EOF }
 func (emptyPages) SeekToRow(int64) error   { return nil }
 func (emptyPages) Close() error            { return nil }
 
+// invalidRowGroup is used in place of row groups that could not be opened,
+// it has no rows and reports err when reading its rows or pages.
+type invalidRowGroup struct {
+	emptyRowGroup
+	err error
+}
+
+func newInvalidRowGroup(schema *Schema, err error) *invalidRowGroup {
+	empty := newEmptyRowGroup(schema)
+	rowGroup := &invalidRowGroup{
+		emptyRowGroup: *empty,
+		err:           err,
+	}
+	invalidColumnChunks := make([]invalidColumnChunk, len(empty.columns))
+	for i, c := range empty.columns {
+		invalidColumnChunks[i].emptyColumnChunk = *c.(*emptyColumnChunk)
+		invalidColumnChunks[i].err = err
+		rowGroup.columns[i] = &invalidColumnChunks[i]
+	}
+	return rowGroup
+}
+
+func (g *invalidRowGroup) Rows() Rows { return invalidRows{emptyRows{g.schema}, g.err} }
+
+type invalidColumnChunk struct {
+	emptyColumnChunk
+	err error
+}
+
+func (c *invalidColumnChunk) Pages() Pages { return invalidPages{c.err} }
+
+type invalidRows struct {
+	emptyRows
+	err error
+}
+
+func (r invalidRows) ReadRows([]Row) (int, error)          { return 0, r.err }
+func (r invalidRows) WriteRowsTo(RowWriter) (int64, error) { return 0, r.err }
+
+type invalidPages struct{ err error }
+
+func (p invalidPages) ReadPage() (Page, error) { return nil, p.err }
+func (p invalidPages) SeekToRow(int64) error   { return nil }
+func (p invalidPages) Close() error            { return nil }
+
 var (
 	_ RowReaderWithSchema = (*rowGroupRows)(nil)
 	//_ RowWriterTo         = (*rowGroupRows)(nil)
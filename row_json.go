@@ -0,0 +1,349 @@
+package parquet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// MarshalJSON satisfies the json.Marshaler interface.
+//
+// Values are represented according to their kind, since their logical type is
+// unknown: booleans and numbers are represented as JSON booleans and numbers,
+// INT96 values and non-finite floating point numbers as strings, and byte
+// arrays as base64 strings. Null values are represented as JSON null.
+//
+// To represent values according to the logical type of their column, use
+// Schema.MarshalRowJSON.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return appendJSONValue(nil, v), nil
+}
+
+// MarshalJSON satisfies the json.Marshaler interface.
+//
+// The row is represented as a flat JSON array of its values, see
+// Value.MarshalJSON. To represent the row as a JSON object following the
+// structure of its schema, use Schema.MarshalRowJSON.
+func (row Row) MarshalJSON() ([]byte, error) {
+	b := append(make([]byte, 0, 16*len(row)), '[')
+	for i, v := range row {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONValue(b, v)
+	}
+	return append(b, ']'), nil
+}
+
+// MarshalRowJSON returns the JSON representation of a row of the schema, where
+// groups are represented as JSON objects, repeated columns and lists as JSON
+// arrays, and maps as JSON objects.
+//
+// Leaf values are represented according to the logical type of their column:
+//
+//   - TIMESTAMP values and legacy INT96 timestamps as RFC 3339 strings
+//   - DATE values as "2006-01-02" strings, TIME values as "15:04:05.999999999"
+//   - STRING and ENUM values as strings, JSON values as embedded JSON
+//   - DECIMAL values as JSON numbers, UUID values as strings
+//
+// Values of other types are represented according to their kind, see
+// Value.MarshalJSON.
+//
+// The method returns an error if the row does not match the schema.
+func (s *Schema) MarshalRowJSON(row Row) ([]byte, error) {
+	return s.appendRowJSON(nil, row)
+}
+
+// appendRowJSON appends the JSON representation of a row of the schema to b,
+// see MarshalRowJSON.
+func (s *Schema) appendRowJSON(b []byte, row Row) ([]byte, error) {
+	s.jsonOnce.Do(func() { _, s.json = jsonFuncOf(0, s.root) })
+	b, row, err := s.json(b, levels{}, row)
+	if err == nil && len(row) > 0 {
+		err = fmt.Errorf("%d values remain unused after marshaling parquet row to JSON", len(row))
+	}
+	return b, err
+}
+
+type jsonFunc func([]byte, levels, Row) ([]byte, Row, error)
+
+func jsonFuncOf(columnIndex int16, node Node) (int16, jsonFunc) {
+	switch {
+	case node.Optional():
+		return jsonFuncOfOptional(columnIndex, node)
+	case node.Repeated():
+		return jsonFuncOfRepeated(columnIndex, node)
+	case isList(node):
+		return jsonFuncOfList(columnIndex, node)
+	case isMap(node):
+		return jsonFuncOfMap(columnIndex, node)
+	default:
+		return jsonFuncOfRequired(columnIndex, node)
+	}
+}
+
+//go:noinline
+func jsonFuncOfOptional(columnIndex int16, node Node) (int16, jsonFunc) {
+	nextColumnIndex, marshal := jsonFuncOf(columnIndex, Required(node))
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(b []byte, levels levels, row Row) ([]byte, Row, error) {
+		if !row.startsWith(columnIndex) {
+			return b, row, fmt.Errorf("row is missing optional column %d", columnIndex)
+		}
+		if len(row) < int(rowLength) {
+			return b, row, fmt.Errorf("expected optional column %d to have at least %d values but got %d", columnIndex, rowLength, len(row))
+		}
+
+		levels.definitionLevel++
+
+		if row[0].definitionLevel < levels.definitionLevel {
+			return append(b, "null"...), row[rowLength:], nil
+		}
+
+		return marshal(b, levels, row)
+	}
+}
+
+//go:noinline
+func jsonFuncOfRepeated(columnIndex int16, node Node) (int16, jsonFunc) {
+	nextColumnIndex, marshal := jsonFuncOf(columnIndex, Required(node))
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(b []byte, lvls levels, row Row) ([]byte, Row, error) {
+		b = append(b, '[')
+		n := 0
+		row, err := reconstructRepeated(columnIndex, rowLength, lvls, row, func(levels levels, row Row) (Row, error) {
+			if n > 0 {
+				b = append(b, ',')
+			}
+			n++
+			var err error
+			b, row, err = marshal(b, levels, row)
+			return row, err
+		})
+		return append(b, ']'), row, err
+	}
+}
+
+func jsonFuncOfRequired(columnIndex int16, node Node) (int16, jsonFunc) {
+	switch {
+	case node.Leaf():
+		return jsonFuncOfLeaf(columnIndex, node)
+	default:
+		return jsonFuncOfGroup(columnIndex, node)
+	}
+}
+
+func jsonFuncOfList(columnIndex int16, node Node) (int16, jsonFunc) {
+	return jsonFuncOf(columnIndex, Repeated(listElementOf(node)))
+}
+
+//go:noinline
+func jsonFuncOfMap(columnIndex int16, node Node) (int16, jsonFunc) {
+	keyValue := mapKeyValueOf(node)
+	fields := keyValue.Fields()
+	funcs := make([]jsonFunc, len(fields))
+	nextColumnIndex := columnIndex
+	keyIndex := 0
+	for i, field := range fields {
+		if field.Name() == "key" {
+			keyIndex = i
+		}
+		nextColumnIndex, funcs[i] = jsonFuncOf(nextColumnIndex, field)
+	}
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(b []byte, lvls levels, row Row) ([]byte, Row, error) {
+		b = append(b, '{')
+		n := 0
+		row, err := reconstructRepeated(columnIndex, rowLength, lvls, row, func(levels levels, row Row) (Row, error) {
+			if n > 0 {
+				b = append(b, ',')
+			}
+			n++
+			// The keys and values are marshaled in the order of the fields of
+			// the key/value group, the key is then moved before the value.
+			var key, value []byte
+			for i, f := range funcs {
+				var err error
+				offset := len(b)
+				if b, row, err = f(b, levels, row); err != nil {
+					return row, err
+				}
+				if i == keyIndex {
+					key = append(key, b[offset:]...)
+				} else {
+					value = append(value, b[offset:]...)
+				}
+				b = b[:offset]
+			}
+			if len(key) == 0 || key[0] != '"' {
+				key = appendJSONString(nil, string(key))
+			}
+			b = append(b, key...)
+			b = append(b, ':')
+			b = append(b, value...)
+			return row, nil
+		})
+		return append(b, '}'), row, err
+	}
+}
+
+//go:noinline
+func jsonFuncOfGroup(columnIndex int16, node Node) (int16, jsonFunc) {
+	fields := node.Fields()
+	funcs := make([]jsonFunc, len(fields))
+
+	for i, field := range fields {
+		columnIndex, funcs[i] = jsonFuncOf(columnIndex, field)
+	}
+
+	return columnIndex, func(b []byte, levels levels, row Row) ([]byte, Row, error) {
+		var err error
+		b = append(b, '{')
+
+		for i, f := range funcs {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, fields[i].Name())
+			b = append(b, ':')
+			if b, row, err = f(b, levels, row); err != nil {
+				err = fmt.Errorf("%s → %w", fields[i].Name(), err)
+				break
+			}
+		}
+
+		return append(b, '}'), row, err
+	}
+}
+
+//go:noinline
+func jsonFuncOfLeaf(columnIndex int16, node Node) (int16, jsonFunc) {
+	typ := node.Type()
+	return columnIndex + 1, func(b []byte, _ levels, row Row) ([]byte, Row, error) {
+		if !row.startsWith(columnIndex) {
+			return b, row, fmt.Errorf("no values found in parquet row for column %d", columnIndex)
+		}
+		return appendJSONValueOf(b, typ, row[0]), row[1:], nil
+	}
+}
+
+// appendJSONValueOf appends the JSON representation of v to b, according to the
+// logical type of typ.
+func appendJSONValueOf(b []byte, typ Type, v Value) []byte {
+	if v.IsNull() {
+		return append(b, "null"...)
+	}
+
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.Timestamp != nil:
+			t, _ := v.Time(typ)
+			return appendJSONTime(b, t, time.RFC3339Nano)
+		case lt.Date != nil:
+			t, _ := v.Time(typ)
+			return appendJSONTime(b, t, "2006-01-02")
+		case lt.Time != nil:
+			t, _ := v.Time(typ)
+			return appendJSONTime(b, t, "15:04:05.999999999")
+		case lt.UTF8 != nil, lt.Enum != nil:
+			return appendJSONString(b, string(v.ByteArray()))
+		case lt.Json != nil:
+			if data := v.ByteArray(); json.Valid(data) {
+				return append(b, data...)
+			}
+			return appendJSONString(b, string(v.ByteArray()))
+		case lt.UUID != nil:
+			if id, ok := v.UUID(); ok {
+				return append(append(append(b, '"'), id.String()...), '"')
+			}
+		case lt.Decimal != nil:
+			if unscaled, scale, ok := v.Decimal(typ); ok {
+				return appendJSONDecimal(b, unscaled, scale)
+			}
+		case lt.Integer != nil && !lt.Integer.IsSigned:
+			if v.Kind() == Int32 {
+				return strconv.AppendUint(b, uint64(v.Uint32()), 10)
+			}
+			return strconv.AppendUint(b, v.Uint64(), 10)
+		}
+	}
+
+	if v.Kind() == Int96 {
+		return appendJSONTime(b, v.Int96().Time(), time.RFC3339Nano)
+	}
+	return appendJSONValue(b, v)
+}
+
+// appendJSONValue appends the JSON representation of v to b, according to its
+// kind.
+func appendJSONValue(b []byte, v Value) []byte {
+	switch v.Kind() {
+	case Boolean:
+		return strconv.AppendBool(b, v.Boolean())
+	case Int32:
+		return strconv.AppendInt(b, int64(v.Int32()), 10)
+	case Int64:
+		return strconv.AppendInt(b, v.Int64(), 10)
+	case Int96:
+		return append(append(append(b, '"'), v.Int96().String()...), '"')
+	case Float:
+		return appendJSONFloat(b, float64(v.Float()), 32)
+	case Double:
+		return appendJSONFloat(b, v.Double(), 64)
+	case ByteArray, FixedLenByteArray:
+		data := v.ByteArray()
+		offset := len(b) + 1
+		b = append(b, make([]byte, base64.StdEncoding.EncodedLen(len(data))+2)...)
+		base64.StdEncoding.Encode(b[offset:], data)
+		b[offset-1], b[len(b)-1] = '"', '"'
+		return b
+	default:
+		return append(b, "null"...)
+	}
+}
+
+func appendJSONFloat(b []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(strconv.AppendFloat(append(b, '"'), f, 'g', -1, bitSize), '"')
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, bitSize)
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	data, _ := json.Marshal(s)
+	return append(b, data...)
+}
+
+func appendJSONTime(b []byte, t time.Time, layout string) []byte {
+	return append(t.AppendFormat(append(b, '"'), layout), '"')
+}
+
+// appendJSONDecimal appends the decimal value unscaled / 10^scale to b.
+func appendJSONDecimal(b []byte, unscaled *big.Int, scale int) []byte {
+	if scale <= 0 {
+		b = unscaled.Append(b, 10)
+		for i := 0; i < -scale && unscaled.Sign() != 0; i++ {
+			b = append(b, '0')
+		}
+		return b
+	}
+	if unscaled.Sign() < 0 {
+		b = append(b, '-')
+		unscaled = new(big.Int).Neg(unscaled)
+	}
+	digits := unscaled.Text(10)
+	if len(digits) <= scale {
+		b = append(b, "0."...)
+		for i := len(digits); i < scale; i++ {
+			b = append(b, '0')
+		}
+		return append(b, digits...)
+	}
+	b = append(b, digits[:len(digits)-scale]...)
+	b = append(b, '.')
+	return append(b, digits[len(digits)-scale:]...)
+}
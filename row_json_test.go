@@ -0,0 +1,137 @@
+package parquet_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func TestValueMarshalJSON(t *testing.T) {
+	tests := []struct {
+		value parquet.Value
+		json  string
+	}{
+		{parquet.Value{}, `null`},
+		{parquet.ValueOf(true), `true`},
+		{parquet.ValueOf(int32(-42)), `-42`},
+		{parquet.ValueOf(int64(1 << 40)), `1099511627776`},
+		{parquet.ValueOf(float32(0.5)), `0.5`},
+		{parquet.ValueOf(math.NaN()), `"NaN"`},
+		{parquet.ValueOf(math.Inf(-1)), `"-Inf"`},
+		{parquet.ValueOf([]byte("hello")), `"aGVsbG8="`},
+		{parquet.ValueOf([1]byte{0xff}), `"/w=="`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.json, func(t *testing.T) {
+			b, err := test.value.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != test.json {
+				t.Errorf("wrong JSON representation of %v\nwant = %s\ngot  = %s", test.value, test.json, b)
+			}
+		})
+	}
+}
+
+func TestRowMarshalJSON(t *testing.T) {
+	row := parquet.Row{
+		parquet.ValueOf(int64(1)),
+		parquet.ValueOf([]byte("A")),
+		parquet.Value{},
+	}
+	b, err := row.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[1,"QQ==",null]`; string(b) != want {
+		t.Errorf("wrong JSON representation of row\nwant = %s\ngot  = %s", want, b)
+	}
+}
+
+func TestSchemaMarshalRowJSON(t *testing.T) {
+	type Address struct {
+		City    string `parquet:"city"`
+		Country string `parquet:"country,enum"`
+	}
+
+	type Record struct {
+		ID        int64             `parquet:"id"`
+		Name      string            `parquet:"name"`
+		Email     *string           `parquet:"email,optional"`
+		CreatedAt time.Time         `parquet:"created_at"`
+		Birthday  int32             `parquet:"birthday,date"`
+		Price     int64             `parquet:"price,decimal(2:18)"`
+		Key       [16]byte          `parquet:"key,uuid"`
+		Data      []byte            `parquet:"data"`
+		Tags      []string          `parquet:"tags,list"`
+		Scores    []float64         `parquet:"scores"`
+		Address   Address           `parquet:"address"`
+		Labels    map[string]string `parquet:"labels"`
+		Counts    map[int32]int64   `parquet:"counts"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+
+	tests := []struct {
+		scenario string
+		record   Record
+		json     string
+	}{
+		{
+			scenario: "empty",
+			record:   Record{CreatedAt: time.Unix(0, 0).UTC()},
+			json: `{"id":0,"name":"","email":null,"created_at":"1970-01-01T00:00:00Z","birthday":"1970-01-01",` +
+				`"price":0.00,"key":"00000000-0000-0000-0000-000000000000","data":"","tags":[],"scores":[],` +
+				`"address":{"city":"","country":""},"labels":{},"counts":{}}`,
+		},
+		{
+			scenario: "values",
+			record: Record{
+				ID:        42,
+				Name:      "Luke \"Skywalker\"",
+				Email:     newString("luke@example.com"),
+				CreatedAt: time.Date(2022, 7, 4, 12, 30, 15, 500, time.UTC),
+				Birthday:  int32(time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC).Unix() / 86400),
+				Price:     -1205,
+				Key:       uuid.MustParse("3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f"),
+				Data:      []byte("hello"),
+				Tags:      []string{"a", "b"},
+				Scores:    []float64{1.5, math.Inf(1)},
+				Address:   Address{City: "Tatooine", Country: "OUTER_RIM"},
+				Labels:    map[string]string{"side": "light"},
+				Counts:    map[int32]int64{7: 1},
+			},
+			json: `{"id":42,"name":"Luke \"Skywalker\"","email":"luke@example.com","created_at":"2022-07-04T12:30:15.0000005Z",` +
+				`"birthday":"2000-02-29","price":-12.05,"key":"3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f","data":"aGVsbG8=",` +
+				`"tags":["a","b"],"scores":[1.5,"+Inf"],"address":{"city":"Tatooine","country":"OUTER_RIM"},` +
+				`"labels":{"side":"light"},"counts":{"7":1}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			b, err := schema.MarshalRowJSON(schema.Deconstruct(nil, &test.record))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != test.json {
+				t.Errorf("wrong JSON representation of row\nwant = %s\ngot  = %s", test.json, b)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		row := schema.Deconstruct(nil, &Record{})
+		if _, err := schema.MarshalRowJSON(row[:3]); err == nil {
+			t.Error("expected an error when marshaling a truncated row")
+		}
+		if _, err := schema.MarshalRowJSON(append(row, parquet.ValueOf(int64(1)).Level(0, 0, 100))); err == nil {
+			t.Error("expected an error when marshaling a row with extra values")
+		}
+	})
+}
@@ -0,0 +1,414 @@
+package parquet
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go/deprecated"
+)
+
+// DeconstructMap deconstructs a generic representation of a value of the schema
+// and appends it to a row.
+//
+// Groups are represented as map[string]interface{} values, and repeated
+// fields and lists as []interface{} values. Maps are represented as
+// []interface{} values where each element is a map[string]interface{} with
+// "key" and "value" entries. Optional fields which are missing or nil are
+// null.
+//
+// Leaf values may be of any Go type that converts to the physical type of their
+// column without loss: booleans, integers, floating point numbers, strings,
+// []byte, byte arrays, uuid.UUID, deprecated.Int96, or parquet.Value.
+//
+// The method returns an error if the structure of the value does not match the
+// schema.
+func (s *Schema) DeconstructMap(row Row, value map[string]interface{}) (Row, error) {
+	s.mapOnce.Do(s.makeMapFuncs)
+	return s.mapDeconstruct(row, levels{}, value)
+}
+
+// ReconstructMap reconstructs a generic representation of a row, using the same
+// representation of groups, repeated fields, lists, and maps as DeconstructMap.
+//
+// Leaf values are represented by Go values of the physical type of their
+// column: bool, int32, int64, deprecated.Int96, float32, float64, or []byte.
+// Byte arrays of columns with the STRING, ENUM, or JSON logical types are
+// represented as strings, and values of the UUID logical type as uuid.UUID.
+// Byte arrays are copied and do not retain references to the row.
+//
+// The method returns an error if the row does not match the schema.
+func (s *Schema) ReconstructMap(row Row) (map[string]interface{}, error) {
+	s.mapOnce.Do(s.makeMapFuncs)
+	value, row, err := s.mapReconstruct(levels{}, row)
+	if err == nil && len(row) > 0 {
+		err = fmt.Errorf("%d values remain unused after reconstructing parquet row to a map", len(row))
+	}
+	m, _ := value.(map[string]interface{})
+	return m, err
+}
+
+func (s *Schema) makeMapFuncs() {
+	_, s.mapDeconstruct = mapDeconstructFuncOf(0, s.root)
+	_, s.mapReconstruct = mapReconstructFuncOf(0, s.root)
+}
+
+type mapDeconstructFunc func(Row, levels, interface{}) (Row, error)
+
+func mapDeconstructFuncOf(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	switch {
+	case node.Optional():
+		return mapDeconstructFuncOfOptional(columnIndex, node)
+	case node.Repeated():
+		return mapDeconstructFuncOfRepeated(columnIndex, node)
+	case isList(node):
+		return mapDeconstructFuncOfList(columnIndex, node)
+	case isMap(node):
+		return mapDeconstructFuncOfMap(columnIndex, node)
+	default:
+		return mapDeconstructFuncOfRequired(columnIndex, node)
+	}
+}
+
+//go:noinline
+func mapDeconstructFuncOfOptional(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	nextColumnIndex, deconstruct := mapDeconstructFuncOf(columnIndex, Required(node))
+	return nextColumnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		if value == nil {
+			return appendNullValues(row, levels, columnIndex, nextColumnIndex), nil
+		}
+		levels.definitionLevel++
+		return deconstruct(row, levels, value)
+	}
+}
+
+//go:noinline
+func mapDeconstructFuncOfRepeated(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	nextColumnIndex, deconstruct := mapDeconstructFuncOf(columnIndex, Required(node))
+	return nextColumnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		elems, ok := value.([]interface{})
+		if !ok && value != nil {
+			return row, fmt.Errorf("cannot deconstruct value of type %T into repeated column %d", value, columnIndex)
+		}
+		if len(elems) == 0 {
+			return appendNullValues(row, levels, columnIndex, nextColumnIndex), nil
+		}
+
+		levels.repetitionDepth++
+		levels.definitionLevel++
+
+		for i, elem := range elems {
+			var err error
+			if row, err = deconstruct(row, levels, elem); err != nil {
+				return row, fmt.Errorf("[%d] → %w", i, err)
+			}
+			levels.repetitionLevel = levels.repetitionDepth
+		}
+
+		return row, nil
+	}
+}
+
+func mapDeconstructFuncOfRequired(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	switch {
+	case node.Leaf():
+		return mapDeconstructFuncOfLeaf(columnIndex, node)
+	default:
+		return mapDeconstructFuncOfGroup(columnIndex, node)
+	}
+}
+
+func mapDeconstructFuncOfList(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	return mapDeconstructFuncOf(columnIndex, Repeated(listElementOf(node)))
+}
+
+func mapDeconstructFuncOfMap(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	return mapDeconstructFuncOf(columnIndex, Repeated(mapKeyValueOf(node)))
+}
+
+//go:noinline
+func mapDeconstructFuncOfGroup(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	fields := node.Fields()
+	funcs := make([]mapDeconstructFunc, len(fields))
+	for i, field := range fields {
+		columnIndex, funcs[i] = mapDeconstructFuncOf(columnIndex, field)
+	}
+	return columnIndex, func(row Row, levels levels, value interface{}) (Row, error) {
+		group, ok := value.(map[string]interface{})
+		if !ok {
+			return row, fmt.Errorf("cannot deconstruct value of type %T into a group", value)
+		}
+		for i, f := range funcs {
+			var err error
+			if row, err = f(row, levels, group[fields[i].Name()]); err != nil {
+				return row, fmt.Errorf("%s → %w", fields[i].Name(), err)
+			}
+		}
+		return row, nil
+	}
+}
+
+//go:noinline
+func mapDeconstructFuncOfLeaf(columnIndex int16, node Node) (int16, mapDeconstructFunc) {
+	if columnIndex > MaxColumnIndex {
+		panic("row cannot be deconstructed because it has more than 127 columns")
+	}
+	typ := node.Type()
+	valueColumnIndex := ^columnIndex
+	return columnIndex + 1, func(row Row, levels levels, value interface{}) (Row, error) {
+		if value == nil {
+			return row, fmt.Errorf("missing value of required column %d", columnIndex)
+		}
+		v, err := mapValueOf(typ, value)
+		if err != nil {
+			return row, err
+		}
+		v.repetitionLevel = levels.repetitionLevel
+		v.definitionLevel = levels.definitionLevel
+		v.columnIndex = valueColumnIndex
+		return append(row, v), nil
+	}
+}
+
+// appendNullValues appends null values for the leaf columns in the range
+// [columnIndex:nextColumnIndex) to row.
+func appendNullValues(row Row, levels levels, columnIndex, nextColumnIndex int16) Row {
+	for i := columnIndex; i < nextColumnIndex; i++ {
+		row = append(row, Value{
+			repetitionLevel: levels.repetitionLevel,
+			definitionLevel: levels.definitionLevel,
+			columnIndex:     ^i,
+		})
+	}
+	return row
+}
+
+// mapValueOf converts a Go value to a parquet value of the given type.
+func mapValueOf(typ Type, value interface{}) (Value, error) {
+	if v, ok := value.(Value); ok {
+		if v.IsNull() || v.Kind() != typ.Kind() {
+			return Value{}, fmt.Errorf("cannot deconstruct parquet value %v into column of type %s", v, typ)
+		}
+		return v, nil
+	}
+
+	switch kind := typ.Kind(); kind {
+	case Boolean:
+		if b, ok := value.(bool); ok {
+			return makeValueBoolean(b), nil
+		}
+	case Int32:
+		if i, ok := mapIntegerOf(value); ok && i >= math.MinInt32 && i <= math.MaxUint32 {
+			return makeValueInt32(int32(i)), nil
+		}
+	case Int64:
+		if i, ok := mapIntegerOf(value); ok {
+			return makeValueInt64(i), nil
+		}
+		if u, ok := value.(uint64); ok {
+			return makeValueUint64(u), nil
+		}
+	case Int96:
+		if i, ok := value.(deprecated.Int96); ok {
+			return makeValueInt96(i), nil
+		}
+	case Float:
+		switch f := value.(type) {
+		case float32:
+			return makeValueFloat(f), nil
+		case float64:
+			if float64(float32(f)) == f || math.IsNaN(f) {
+				return makeValueFloat(float32(f)), nil
+			}
+		}
+	case Double:
+		switch f := value.(type) {
+		case float32:
+			return makeValueDouble(float64(f)), nil
+		case float64:
+			return makeValueDouble(f), nil
+		}
+	case ByteArray, FixedLenByteArray:
+		var v Value
+		switch b := value.(type) {
+		case string:
+			v = makeValueString(kind, b)
+		case []byte:
+			v = makeValueBytes(kind, b)
+		case uuid.UUID:
+			v = makeValueBytes(kind, b[:])
+		default:
+			if kind == FixedLenByteArray {
+				if a := reflect.ValueOf(value); a.Kind() == reflect.Array && a.Type().Elem().Kind() == reflect.Uint8 {
+					v = makeValueFixedLenByteArray(a)
+				}
+			}
+		}
+		if !v.IsNull() && (kind == ByteArray || len(v.ByteArray()) == typ.Length()) {
+			return v, nil
+		}
+	}
+
+	return Value{}, fmt.Errorf("cannot deconstruct value of type %T into column of type %s", value, typ)
+}
+
+func mapIntegerOf(value interface{}) (int64, bool) {
+	switch i := value.(type) {
+	case int:
+		return int64(i), true
+	case int8:
+		return int64(i), true
+	case int16:
+		return int64(i), true
+	case int32:
+		return int64(i), true
+	case int64:
+		return i, true
+	case uint:
+		return int64(i), uint64(i) <= math.MaxInt64
+	case uint8:
+		return int64(i), true
+	case uint16:
+		return int64(i), true
+	case uint32:
+		return int64(i), true
+	case uint64:
+		return int64(i), i <= math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+type mapReconstructFunc func(levels, Row) (interface{}, Row, error)
+
+func mapReconstructFuncOf(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	switch {
+	case node.Optional():
+		return mapReconstructFuncOfOptional(columnIndex, node)
+	case node.Repeated():
+		return mapReconstructFuncOfRepeated(columnIndex, node)
+	case isList(node):
+		return mapReconstructFuncOfList(columnIndex, node)
+	case isMap(node):
+		return mapReconstructFuncOfMap(columnIndex, node)
+	default:
+		return mapReconstructFuncOfRequired(columnIndex, node)
+	}
+}
+
+//go:noinline
+func mapReconstructFuncOfOptional(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	nextColumnIndex, reconstruct := mapReconstructFuncOf(columnIndex, Required(node))
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(levels levels, row Row) (interface{}, Row, error) {
+		if !row.startsWith(columnIndex) {
+			return nil, row, fmt.Errorf("row is missing optional column %d", columnIndex)
+		}
+		if len(row) < int(rowLength) {
+			return nil, row, fmt.Errorf("expected optional column %d to have at least %d values but got %d", columnIndex, rowLength, len(row))
+		}
+
+		levels.definitionLevel++
+
+		if row[0].definitionLevel < levels.definitionLevel {
+			return nil, row[rowLength:], nil
+		}
+
+		return reconstruct(levels, row)
+	}
+}
+
+//go:noinline
+func mapReconstructFuncOfRepeated(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	nextColumnIndex, reconstruct := mapReconstructFuncOf(columnIndex, Required(node))
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(lvls levels, row Row) (interface{}, Row, error) {
+		elems := []interface{}{}
+		row, err := reconstructRepeated(columnIndex, rowLength, lvls, row, func(levels levels, row Row) (Row, error) {
+			elem, row, err := reconstruct(levels, row)
+			if err == nil {
+				elems = append(elems, elem)
+			}
+			return row, err
+		})
+		return elems, row, err
+	}
+}
+
+func mapReconstructFuncOfRequired(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	switch {
+	case node.Leaf():
+		return mapReconstructFuncOfLeaf(columnIndex, node)
+	default:
+		return mapReconstructFuncOfGroup(columnIndex, node)
+	}
+}
+
+func mapReconstructFuncOfList(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	return mapReconstructFuncOf(columnIndex, Repeated(listElementOf(node)))
+}
+
+func mapReconstructFuncOfMap(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	return mapReconstructFuncOf(columnIndex, Repeated(mapKeyValueOf(node)))
+}
+
+//go:noinline
+func mapReconstructFuncOfGroup(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	fields := node.Fields()
+	funcs := make([]mapReconstructFunc, len(fields))
+	for i, field := range fields {
+		columnIndex, funcs[i] = mapReconstructFuncOf(columnIndex, field)
+	}
+	return columnIndex, func(levels levels, row Row) (interface{}, Row, error) {
+		group := make(map[string]interface{}, len(fields))
+		for i, f := range funcs {
+			value, rest, err := f(levels, row)
+			if err != nil {
+				return group, row, fmt.Errorf("%s → %w", fields[i].Name(), err)
+			}
+			group[fields[i].Name()], row = value, rest
+		}
+		return group, row, nil
+	}
+}
+
+//go:noinline
+func mapReconstructFuncOfLeaf(columnIndex int16, node Node) (int16, mapReconstructFunc) {
+	typ := node.Type()
+	lt := typ.LogicalType()
+	isString := lt != nil && (lt.UTF8 != nil || lt.Enum != nil || lt.Json != nil)
+	isUUID := lt != nil && lt.UUID != nil
+	return columnIndex + 1, func(_ levels, row Row) (interface{}, Row, error) {
+		if !row.startsWith(columnIndex) {
+			return nil, row, fmt.Errorf("no values found in parquet row for column %d", columnIndex)
+		}
+		v := row[0]
+		switch v.Kind() {
+		case Boolean:
+			return v.Boolean(), row[1:], nil
+		case Int32:
+			return v.Int32(), row[1:], nil
+		case Int64:
+			return v.Int64(), row[1:], nil
+		case Int96:
+			return v.Int96(), row[1:], nil
+		case Float:
+			return v.Float(), row[1:], nil
+		case Double:
+			return v.Double(), row[1:], nil
+		case ByteArray, FixedLenByteArray:
+			switch {
+			case isString:
+				return string(v.ByteArray()), row[1:], nil
+			case isUUID:
+				if id, ok := v.UUID(); ok {
+					return id, row[1:], nil
+				}
+			}
+			return copyBytes(v.ByteArray()), row[1:], nil
+		default:
+			return nil, row[1:], nil
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package parquet_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSchemaMapRoundTrip(t *testing.T) {
+	type Item struct {
+		Name  string `parquet:"name"`
+		Count int32  `parquet:"count"`
+	}
+
+	type Record struct {
+		ID      int64            `parquet:"id"`
+		Name    *string          `parquet:"name,optional"`
+		Key     [16]byte         `parquet:"key,uuid"`
+		Data    []byte           `parquet:"data"`
+		Scores  []float64        `parquet:"scores"`
+		Tags    []string         `parquet:"tags,list"`
+		Items   []Item           `parquet:"items"`
+		Attrs   map[string]int64 `parquet:"attrs"`
+		Enabled bool             `parquet:"enabled"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+	key := uuid.MustParse("3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f")
+
+	tests := []struct {
+		scenario string
+		record   Record
+		value    map[string]interface{}
+	}{
+		{
+			scenario: "empty",
+			record:   Record{},
+			value: map[string]interface{}{
+				"id":      int64(0),
+				"name":    nil,
+				"key":     uuid.UUID{},
+				"data":    []byte{},
+				"scores":  []interface{}{},
+				"tags":    []interface{}{},
+				"items":   []interface{}{},
+				"attrs":   []interface{}{},
+				"enabled": false,
+			},
+		},
+
+		{
+			scenario: "nested values",
+			record: Record{
+				ID:      42,
+				Name:    newString("Luke"),
+				Key:     key,
+				Data:    []byte("hello"),
+				Scores:  []float64{1.5, 2.5},
+				Tags:    []string{"a", "b", "c"},
+				Items:   []Item{{Name: "X", Count: 1}, {Name: "Y", Count: 2}},
+				Attrs:   map[string]int64{"credits": 100},
+				Enabled: true,
+			},
+			value: map[string]interface{}{
+				"id":     int64(42),
+				"name":   "Luke",
+				"key":    key,
+				"data":   []byte("hello"),
+				"scores": []interface{}{1.5, 2.5},
+				"tags":   []interface{}{"a", "b", "c"},
+				"items": []interface{}{
+					map[string]interface{}{"name": "X", "count": int32(1)},
+					map[string]interface{}{"name": "Y", "count": int32(2)},
+				},
+				"attrs": []interface{}{
+					map[string]interface{}{"key": "credits", "value": int64(100)},
+				},
+				"enabled": true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			row := schema.Deconstruct(nil, &test.record)
+
+			value, err := schema.ReconstructMap(row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(value, test.value) {
+				t.Errorf("reconstructed map mismatch\nwant = %#v\ngot  = %#v", test.value, value)
+			}
+
+			deconstructed, err := schema.DeconstructMap(nil, value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !deconstructed.Equal(row) {
+				t.Errorf("deconstructed row mismatch\nwant = %+v\ngot  = %+v", row, deconstructed)
+			}
+		})
+	}
+}
+
+func TestSchemaDeconstructMap(t *testing.T) {
+	type Record struct {
+		A int32    `parquet:"a"`
+		B *float32 `parquet:"b,optional"`
+		C [4]byte  `parquet:"c"`
+		D []int64  `parquet:"d"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+
+	row, err := schema.DeconstructMap(nil, map[string]interface{}{
+		"a": 1,
+		"c": "ABCD",
+		"d": []interface{}{uint8(2), parquet.ValueOf(int64(3))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := schema.Deconstruct(nil, &Record{A: 1, C: [4]byte{'A', 'B', 'C', 'D'}, D: []int64{2, 3}})
+	if !row.Equal(want) {
+		t.Errorf("deconstructed row mismatch\nwant = %+v\ngot  = %+v", want, row)
+	}
+
+	errors := []struct {
+		scenario string
+		value    map[string]interface{}
+	}{
+		{"missing required value", map[string]interface{}{"c": "ABCD"}},
+		{"integer overflow", map[string]interface{}{"a": int64(1) << 40, "c": "ABCD"}},
+		{"wrong type", map[string]interface{}{"a": "1", "c": "ABCD"}},
+		{"wrong length", map[string]interface{}{"a": 1, "c": "ABC"}},
+		{"lossy float conversion", map[string]interface{}{"a": 1, "b": 0.1, "c": "ABCD"}},
+		{"repeated value not a slice", map[string]interface{}{"a": 1, "c": "ABCD", "d": int64(1)}},
+		{"wrong parquet value kind", map[string]interface{}{"a": parquet.ValueOf("1"), "c": "ABCD"}},
+	}
+
+	for _, e := range errors {
+		if _, err := schema.DeconstructMap(nil, e.value); err == nil {
+			t.Errorf("%s: expected an error", e.scenario)
+		}
+	}
+}
+
+func TestSchemaReconstructMapErrors(t *testing.T) {
+	type Record struct {
+		A int32 `parquet:"a"`
+		B int32 `parquet:"b"`
+	}
+
+	schema := parquet.SchemaOf(Record{})
+	row := schema.Deconstruct(nil, &Record{A: 1, B: 2})
+
+	if _, err := schema.ReconstructMap(row[:1]); err == nil {
+		t.Error("expected an error reconstructing a truncated row")
+	}
+	if _, err := schema.ReconstructMap(append(row, parquet.ValueOf(int32(3)).Level(0, 0, 2))); err == nil {
+		t.Error("expected an error reconstructing a row with extra values")
+	}
+}
@@ -0,0 +1,81 @@
+package parquet
+
+import "sync"
+
+// RowBatch holds a batch of rows which can be reused across calls to the
+// ReadRows method of RowReader implementations, allowing programs to read rows
+// without allocating new arrays of values when they reach a steady state.
+//
+// Rows read in a batch may reference memory owned by the reader they were read
+// from (e.g. the pages of a file), programs must not retain the rows or their
+// values after the next call to ReadRows, and must clone them if they need to
+// hold on to them longer.
+type RowBatch struct {
+	rows []Row
+}
+
+// NewRowBatch constructs a batch of n rows.
+func NewRowBatch(n int) *RowBatch {
+	b := new(RowBatch)
+	b.Reset(n)
+	return b
+}
+
+// Rows returns the rows of the batch, which can be passed to ReadRows.
+func (b *RowBatch) Rows() []Row { return b.rows }
+
+// Reset resizes the batch to hold n rows. The rows are truncated to zero length
+// but retain their backing arrays, which are reused by ReadRows.
+func (b *RowBatch) Reset(n int) {
+	if cap(b.rows) < n {
+		rows := make([]Row, n)
+		copy(rows, b.rows[:cap(b.rows)])
+		b.rows = rows
+	} else {
+		b.rows = b.rows[:n]
+	}
+	for i := range b.rows {
+		b.rows[i] = b.rows[i][:0]
+	}
+}
+
+// Clear truncates the rows of the batch to zero length and clears their values,
+// which releases references to memory held by byte array values.
+func (b *RowBatch) Clear() {
+	rows := b.rows[:cap(b.rows)]
+	for i, row := range rows {
+		// Rows may have been truncated by Reset, the values must be cleared
+		// up to the capacity of the rows to release all references.
+		clearValues(row[:cap(row)])
+		rows[i] = row[:0]
+	}
+}
+
+// RowPool is a pool of row batches. Programs that read rows concurrently, or
+// hand off batches of rows between goroutines, can use a RowPool to reuse the
+// rows of batches which are no longer in use.
+//
+// The zero-value is a valid pool.
+type RowPool struct {
+	pool sync.Pool // *RowBatch
+}
+
+// Get returns a batch of n rows from the pool, or allocates a new one if the
+// pool was empty.
+func (p *RowPool) Get(n int) *RowBatch {
+	b, _ := p.pool.Get().(*RowBatch)
+	if b == nil {
+		b = new(RowBatch)
+	}
+	b.Reset(n)
+	return b
+}
+
+// Put clears the batch and returns it to the pool. The program must not use the
+// batch, its rows, or their values after calling Put.
+func (p *RowPool) Put(b *RowBatch) {
+	if b != nil {
+		b.Clear()
+		p.pool.Put(b)
+	}
+}
@@ -0,0 +1,87 @@
+package parquet_test
+
+import (
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestRowBatch(t *testing.T) {
+	type Row struct {
+		Name string   `parquet:"name"`
+		Tags []string `parquet:"tags"`
+	}
+
+	buffer := parquet.NewBuffer()
+	for i := 0; i < 1000; i++ {
+		if err := buffer.Write(&Row{Name: "name", Tags: []string{"a", "b"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows := buffer.Rows()
+	defer rows.Close()
+
+	batch := parquet.NewRowBatch(4)
+	if n, err := rows.ReadRows(batch.Rows()); n != 4 || err != nil {
+		t.Fatalf("reading rows: n=%d err=%v", n, err)
+	}
+
+	arrays := make([]*parquet.Value, 4)
+	for i, row := range batch.Rows() {
+		if len(row) != 3 {
+			t.Fatalf("wrong number of values in row %d: %d", i, len(row))
+		}
+		arrays[i] = &row[0]
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		batch.Reset(4)
+		if _, err := rows.ReadRows(batch.Rows()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("reading rows in a batch allocated memory: %g allocs", allocs)
+	}
+
+	for i, row := range batch.Rows() {
+		if &row[0] != arrays[i] {
+			t.Errorf("the backing array of row %d was not reused", i)
+		}
+	}
+
+	batch.Reset(2)
+	batch.Clear()
+	for i, row := range batch.Rows()[:4] {
+		if len(row) != 0 {
+			t.Errorf("row %d was not truncated: %v", i, row)
+		}
+		if v := row[:cap(row)][0]; !v.IsNull() || len(v.ByteArray()) != 0 {
+			t.Errorf("row %d was not cleared: %v", i, v)
+		}
+	}
+}
+
+func TestRowPool(t *testing.T) {
+	pool := new(parquet.RowPool)
+
+	batch := pool.Get(8)
+	if n := len(batch.Rows()); n != 8 {
+		t.Fatalf("wrong number of rows: %d", n)
+	}
+	for i := range batch.Rows() {
+		batch.Rows()[i] = append(batch.Rows()[i], parquet.ValueOf("hello"))
+	}
+	pool.Put(batch)
+
+	batch = pool.Get(4)
+	if n := len(batch.Rows()); n != 4 {
+		t.Fatalf("wrong number of rows: %d", n)
+	}
+	for i, row := range batch.Rows() {
+		if len(row) != 0 {
+			t.Errorf("row %d of batch returned by the pool is not empty: %v", i, row)
+		}
+	}
+}
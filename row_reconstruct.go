@@ -0,0 +1,317 @@
+package parquet
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/segmentio/parquet-go/internal/unsafecast"
+)
+
+// reconstructPlan is a compiled form of the reconstruct functions of a schema,
+// specialized for a Go type. Instead of navigating Go values using reflection,
+// plans resolve the offsets of struct fields, sizes of slice elements, and
+// assignment of leaf values when they are compiled, and write the values
+// directly to the memory of the Go value being reconstructed.
+//
+// Parts of the schema which cannot be compiled (for example maps, or struct
+// fields promoted from embedded structs) fall back to the reflection-based
+// reconstruct functions.
+type reconstructPlan func(unsafe.Pointer, levels, Row) (Row, error)
+
+// reconstructPlanOf returns the plan reconstructing values of type t, compiling
+// it on first use.
+func (s *Schema) reconstructPlanOf(t reflect.Type) reconstructPlan {
+	if plan, ok := s.plans.Load(t); ok {
+		return plan.(reconstructPlan)
+	}
+	_, plan := reconstructPlanOfNode(0, s.root, t)
+	actual, _ := s.plans.LoadOrStore(t, plan)
+	return actual.(reconstructPlan)
+}
+
+func reconstructPlanOfNode(columnIndex int16, node Node, t reflect.Type) (int16, reconstructPlan) {
+	switch {
+	case node.Optional():
+		return reconstructPlanOfOptional(columnIndex, node, t)
+	case node.Repeated():
+		return reconstructPlanOfRepeated(columnIndex, node, t)
+	case isList(node):
+		return reconstructPlanOfNode(columnIndex, Repeated(listElementOf(node)), t)
+	case isMap(node):
+		return reconstructPlanOfReflect(columnIndex, node, t, reconstructFuncOfMap)
+	case node.Leaf():
+		return reconstructPlanOfLeaf(columnIndex, node, t)
+	default:
+		return reconstructPlanOfGroup(columnIndex, node, t)
+	}
+}
+
+// reconstructPlanOfReflect adapts a reflection-based reconstruct function to
+// a plan.
+func reconstructPlanOfReflect(columnIndex int16, node Node, t reflect.Type, reconstructFuncOf func(int16, Node) (int16, reconstructFunc)) (int16, reconstructPlan) {
+	nextColumnIndex, reconstruct := reconstructFuncOf(columnIndex, node)
+	return nextColumnIndex, func(p unsafe.Pointer, levels levels, row Row) (Row, error) {
+		return reconstruct(reflect.NewAt(t, p).Elem(), levels, row)
+	}
+}
+
+//go:noinline
+func reconstructPlanOfOptional(columnIndex int16, node Node, t reflect.Type) (int16, reconstructPlan) {
+	elemType, isPtr := t, t.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = t.Elem()
+	}
+	nextColumnIndex, reconstruct := reconstructPlanOfNode(columnIndex, Required(node), elemType)
+	rowLength := nextColumnIndex - columnIndex
+	zero := reflect.Zero(t)
+	return nextColumnIndex, func(p unsafe.Pointer, levels levels, row Row) (Row, error) {
+		if !row.startsWith(columnIndex) {
+			return row, fmt.Errorf("row is missing optional column %d", columnIndex)
+		}
+		if len(row) < int(rowLength) {
+			return row, fmt.Errorf("expected optional column %d to have at least %d values but got %d", columnIndex, rowLength, len(row))
+		}
+
+		levels.definitionLevel++
+
+		if row[0].definitionLevel < levels.definitionLevel {
+			if isPtr {
+				*(*unsafe.Pointer)(p) = nil
+			} else {
+				reflect.NewAt(t, p).Elem().Set(zero)
+			}
+			return row[rowLength:], nil
+		}
+
+		if isPtr {
+			ptr := (*unsafe.Pointer)(p)
+			if *ptr == nil {
+				*ptr = unsafecast.PointerOfValue(reflect.New(elemType))
+			}
+			p = *ptr
+		}
+
+		return reconstruct(p, levels, row)
+	}
+}
+
+// sliceHeader represents the memory layout of slices, using an unsafe.Pointer
+// so the garbage collector tracks the reference to the backing array.
+type sliceHeader struct {
+	data unsafe.Pointer
+	len  int
+	cap  int
+}
+
+// emptySliceData is the backing array of empty slices created when
+// reconstructing repeated fields.
+var emptySliceData [0]byte
+
+//go:noinline
+func reconstructPlanOfRepeated(columnIndex int16, node Node, t reflect.Type) (int16, reconstructPlan) {
+	if t.Kind() != reflect.Slice {
+		return reconstructPlanOfReflect(columnIndex, node, t, reconstructFuncOfRepeated)
+	}
+	elemSize := t.Elem().Size()
+	nextColumnIndex, reconstruct := reconstructPlanOfNode(columnIndex, Required(node), t.Elem())
+	rowLength := nextColumnIndex - columnIndex
+	return nextColumnIndex, func(p unsafe.Pointer, levels levels, row Row) (Row, error) {
+		if !row.startsWith(columnIndex) {
+			return row, fmt.Errorf("row is missing repeated column %d: %+v", columnIndex, row)
+		}
+		if len(row) < int(rowLength) {
+			return row, fmt.Errorf("expected repeated column %d to have at least %d values but got %d", columnIndex, rowLength, len(row))
+		}
+
+		levels.repetitionDepth++
+		levels.definitionLevel++
+
+		slice := (*sliceHeader)(p)
+		if slice.data == nil {
+			// Empty repeated fields are reconstructed as empty slices rather
+			// than nil.
+			slice.data = unsafe.Pointer(&emptySliceData)
+		}
+		if row[0].definitionLevel < levels.definitionLevel {
+			slice.len = 0
+			return row[rowLength:], nil
+		}
+
+		// Elements beyond the length of the slice are reused, which retains the
+		// memory allocated for their own repeated fields across rows.
+		var err error
+		n := 0
+		for row.startsWith(columnIndex) && row[0].repetitionLevel == levels.repetitionLevel {
+			if n == slice.cap {
+				growSlice(t, p, n)
+			}
+			if row, err = reconstruct(unsafe.Add(slice.data, uintptr(n)*elemSize), levels, row); err != nil {
+				break
+			}
+			levels.repetitionLevel = levels.repetitionDepth
+			n++
+		}
+		slice.len = n
+		return row, err
+	}
+}
+
+// growSlice grows the capacity of the slice of type t at p, which holds n
+// elements.
+func growSlice(t reflect.Type, p unsafe.Pointer, n int) {
+	c := 2 * n
+	if c == 0 {
+		c = 10
+	}
+	slice := reflect.NewAt(t, p).Elem()
+	(*sliceHeader)(p).len = n
+	newSlice := reflect.MakeSlice(t, c, c)
+	reflect.Copy(newSlice, slice)
+	slice.Set(newSlice)
+}
+
+//go:noinline
+func reconstructPlanOfGroup(columnIndex int16, node Node, t reflect.Type) (int16, reconstructPlan) {
+	fields := node.Fields()
+	if t.Kind() != reflect.Struct {
+		return reconstructPlanOfReflect(columnIndex, node, t, reconstructFuncOfGroup)
+	}
+	for _, field := range fields {
+		f, ok := field.(*structField)
+		if !ok || len(f.index) != 1 || f.index[0] >= t.NumField() {
+			return reconstructPlanOfReflect(columnIndex, node, t, reconstructFuncOfGroup)
+		}
+	}
+
+	plans := make([]reconstructPlan, len(fields))
+	offsets := make([]uintptr, len(fields))
+
+	for i, field := range fields {
+		f := t.Field(field.(*structField).index[0])
+		offsets[i] = f.Offset
+		columnIndex, plans[i] = reconstructPlanOfNode(columnIndex, field, f.Type)
+	}
+
+	return columnIndex, func(p unsafe.Pointer, levels levels, row Row) (Row, error) {
+		var err error
+
+		for i, plan := range plans {
+			if row, err = plan(unsafe.Add(p, offsets[i]), levels, row); err != nil {
+				err = fmt.Errorf("%s → %w", fields[i].Name(), err)
+				break
+			}
+		}
+
+		return row, err
+	}
+}
+
+//go:noinline
+func reconstructPlanOfLeaf(columnIndex int16, node Node, t reflect.Type) (int16, reconstructPlan) {
+	assign := assignFuncOf(node.Type(), t)
+	zero := reflect.Zero(t)
+	return columnIndex + 1, func(p unsafe.Pointer, _ levels, row Row) (Row, error) {
+		if !row.startsWith(columnIndex) {
+			return row, fmt.Errorf("no values found in parquet row for column %d", columnIndex)
+		}
+		if row[0].IsNull() {
+			reflect.NewAt(t, p).Elem().Set(zero)
+			return row[1:], nil
+		}
+		return row[1:], assign(p, row[0])
+	}
+}
+
+// assignFuncOf returns a function assigning non-null values of the column type
+// typ to Go values of type t. The conversions are the same as assignValue, but
+// the common combinations of types are specialized to avoid using reflection.
+func assignFuncOf(typ Type, t reflect.Type) func(unsafe.Pointer, Value) error {
+	if timestamp, ok := typ.(*timestampType); ok && t == reflect.TypeOf(time.Time{}) {
+		return func(p unsafe.Pointer, v Value) error {
+			*(*time.Time)(p) = timestamp.unixTime(v.Int64())
+			return nil
+		}
+	}
+
+	switch typ.Kind() {
+	case Boolean:
+		if t.Kind() == reflect.Bool {
+			return func(p unsafe.Pointer, v Value) error { *(*bool)(p) = v.Boolean(); return nil }
+		}
+
+	case Int32:
+		switch t.Kind() {
+		case reflect.Int8, reflect.Uint8:
+			return func(p unsafe.Pointer, v Value) error { *(*int8)(p) = int8(v.Int32()); return nil }
+		case reflect.Int16, reflect.Uint16:
+			return func(p unsafe.Pointer, v Value) error { *(*int16)(p) = int16(v.Int32()); return nil }
+		case reflect.Int32, reflect.Uint32:
+			return func(p unsafe.Pointer, v Value) error { *(*int32)(p) = v.Int32(); return nil }
+		}
+
+	case Int64:
+		switch t.Kind() {
+		case reflect.Int8, reflect.Uint8:
+			return func(p unsafe.Pointer, v Value) error { *(*int8)(p) = int8(v.Int64()); return nil }
+		case reflect.Int16, reflect.Uint16:
+			return func(p unsafe.Pointer, v Value) error { *(*int16)(p) = int16(v.Int64()); return nil }
+		case reflect.Int32, reflect.Uint32:
+			return func(p unsafe.Pointer, v Value) error { *(*int32)(p) = int32(v.Int64()); return nil }
+		case reflect.Int64, reflect.Uint64:
+			return func(p unsafe.Pointer, v Value) error { *(*int64)(p) = v.Int64(); return nil }
+		case reflect.Int, reflect.Uint, reflect.Uintptr:
+			return func(p unsafe.Pointer, v Value) error { *(*int)(p) = int(v.Int64()); return nil }
+		}
+
+	case Float:
+		switch t.Kind() {
+		case reflect.Float32:
+			return func(p unsafe.Pointer, v Value) error { *(*float32)(p) = v.Float(); return nil }
+		case reflect.Float64:
+			return func(p unsafe.Pointer, v Value) error { *(*float64)(p) = float64(v.Float()); return nil }
+		}
+
+	case Double:
+		switch t.Kind() {
+		case reflect.Float32:
+			return func(p unsafe.Pointer, v Value) error { *(*float32)(p) = float32(v.Double()); return nil }
+		case reflect.Float64:
+			return func(p unsafe.Pointer, v Value) error { *(*float64)(p) = v.Double(); return nil }
+		}
+
+	case ByteArray, FixedLenByteArray:
+		switch t.Kind() {
+		case reflect.String:
+			if typ.Kind() == ByteArray {
+				return func(p unsafe.Pointer, v Value) error {
+					// Reconstructing into values reused across rows often finds
+					// the same strings, which avoids allocating a copy.
+					if s := (*string)(p); *s != string(v.ByteArray()) {
+						*s = string(v.ByteArray())
+					}
+					return nil
+				}
+			}
+		case reflect.Slice:
+			if t.Elem().Kind() == reflect.Uint8 {
+				return func(p unsafe.Pointer, v Value) error { *(*[]byte)(p) = copyBytes(v.ByteArray()); return nil }
+			}
+		case reflect.Array:
+			if typ.Kind() == FixedLenByteArray && t.Elem().Kind() == reflect.Uint8 && t.Len() == typ.Length() {
+				return func(p unsafe.Pointer, v Value) error {
+					if b := v.ByteArray(); len(b) == t.Len() {
+						copy(unsafe.Slice((*byte)(p), len(b)), b)
+						return nil
+					}
+					return assignValue(reflect.NewAt(t, p).Elem(), v)
+				}
+			}
+		}
+	}
+
+	return func(p unsafe.Pointer, v Value) error {
+		return assignValue(reflect.NewAt(t, p).Elem(), v)
+	}
+}
@@ -18,6 +18,45 @@ func TestRowClone(t *testing.T) {
 	}
 }
 
+func TestCloneRows(t *testing.T) {
+	buffer := []byte("ABCDEF")
+	rows := []parquet.Row{
+		{
+			parquet.ValueOf(buffer[:2]).Level(0, 0, 0),
+			parquet.ValueOf(int32(1)).Level(0, 0, 1),
+		},
+		{},
+		{
+			parquet.ValueOf(buffer[2:]).Level(0, 0, 0),
+			parquet.ValueOf(int32(2)).Level(0, 0, 1),
+		},
+	}
+
+	clones, arena := parquet.CloneRows(nil, rows)
+	if len(clones) != len(rows) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(rows), len(clones))
+	}
+	if string(arena) != "ABCDEF" || cap(arena) != len(buffer) {
+		t.Errorf("wrong arena: %q (cap=%d)", arena, cap(arena))
+	}
+
+	copy(buffer, "XXXXXX")
+	if s := clones[0][0].String(); s != "AB" {
+		t.Errorf("cloned value was modified: %q", s)
+	}
+	if s := clones[2][0].String(); s != "CDEF" {
+		t.Errorf("cloned value was modified: %q", s)
+	}
+
+	clones[0] = append(clones[0], parquet.ValueOf(int32(3)))
+	if !clones[2].Equal(parquet.Row{
+		parquet.ValueOf("CDEF").Level(0, 0, 0),
+		parquet.ValueOf(int32(2)).Level(0, 0, 1),
+	}) {
+		t.Errorf("appending to a cloned row modified the next row: %+v", clones[2])
+	}
+}
+
 func TestDeconstructionReconstruction(t *testing.T) {
 	type Person struct {
 		FirstName string
@@ -76,6 +115,22 @@ func TestDeconstructionReconstruction(t *testing.T) {
 			},
 		},
 
+		{
+			scenario: "optional fields with null policies",
+			input: struct {
+				Count int     `parquet:",optional(nil)"`
+				Name  string  `parquet:",optional(nil)"`
+				Label *string `parquet:",optional(nil)"`
+				Total int     `parquet:",optional(zero)"`
+			}{},
+			values: [][]parquet.Value{
+				0: {parquet.ValueOf(0).Level(0, 1, 0)},
+				1: {parquet.ValueOf("").Level(0, 1, 0)},
+				2: {parquet.ValueOf(nil).Level(0, 0, 0)},
+				3: {parquet.ValueOf(nil).Level(0, 0, 0)},
+			},
+		},
+
 		{
 			scenario: "multiple fields",
 			input: Person{
@@ -575,3 +630,87 @@ func BenchmarkReconstruct(b *testing.B) {
 		}
 	}
 }
+
+type deepLeaf struct {
+	Value int64  `parquet:"value"`
+	Label string `parquet:"label"`
+}
+
+type deepLevel3 struct {
+	Name   string     `parquet:"name"`
+	Leaves []deepLeaf `parquet:"leaves"`
+}
+
+type deepLevel2 struct {
+	ID     int32        `parquet:"id"`
+	Groups []deepLevel3 `parquet:"groups"`
+}
+
+type deepLevel1 struct {
+	Key      *string      `parquet:"key,optional"`
+	Tags     []string     `parquet:"tags"`
+	Children []deepLevel2 `parquet:"children"`
+}
+
+type deepRecord struct {
+	ID      int64        `parquet:"id"`
+	Entries []deepLevel1 `parquet:"entries"`
+}
+
+func makeDeepRecord() *deepRecord {
+	record := &deepRecord{ID: 1}
+	for i := 0; i < 3; i++ {
+		entry := deepLevel1{Key: newString("key"), Tags: []string{"a", "b"}}
+		for j := 0; j < 3; j++ {
+			child := deepLevel2{ID: int32(j)}
+			for k := 0; k < 3; k++ {
+				group := deepLevel3{Name: "group"}
+				for l := 0; l < 3; l++ {
+					group.Leaves = append(group.Leaves, deepLeaf{Value: int64(l), Label: "leaf"})
+				}
+				child.Groups = append(child.Groups, group)
+			}
+			entry.Children = append(entry.Children, child)
+		}
+		record.Entries = append(record.Entries, entry)
+	}
+	return record
+}
+
+func TestReconstructDeeplyNested(t *testing.T) {
+	full := makeDeepRecord()
+	small := &deepRecord{
+		ID: 2,
+		Entries: []deepLevel1{
+			{Tags: []string{}, Children: []deepLevel2{{ID: 1, Groups: []deepLevel3{}}}},
+		},
+	}
+	empty := &deepRecord{ID: 3, Entries: []deepLevel1{}}
+	schema := parquet.SchemaOf(full)
+
+	// Reconstructing into a value holding data from a previous row must reset
+	// the fields that are not present in the new row.
+	got := deepRecord{Entries: []deepLevel1{{}, {}, {}, {}, {Tags: []string{"x"}}}}
+	for _, record := range []*deepRecord{full, small, full, empty, small} {
+		row := schema.Deconstruct(nil, record)
+		if err := schema.Reconstruct(&got, row); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&got, record) {
+			t.Fatalf("reconstructed value mismatch:\nwant = %+v\ngot  = %+v", record, &got)
+		}
+	}
+}
+
+func BenchmarkReconstructDeeplyNested(b *testing.B) {
+	record := makeDeepRecord()
+	schema := parquet.SchemaOf(record)
+	row := schema.Deconstruct(nil, record)
+	buffer := deepRecord{}
+
+	for i := 0; i < b.N; i++ {
+		if err := schema.Reconstruct(&buffer, row); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package parquet
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// SampleRows returns n rows selected uniformly at random, without replacement,
+// among the rows of the row groups passed as arguments; all rows have the same
+// probability of being selected. The rows are returned in the order they appear
+// in the row groups, and do not share memory with them.
+//
+// The indexes of the sampled rows are chosen using the number of rows of each
+// row group before reading any data, then only the pages holding those rows are
+// read: when the column chunks have an offset index, the pages between sampled
+// rows are skipped without being read, which makes sampling few rows of large
+// files cheap. For example, to sample rows of a file:
+//
+//	rows, err := parquet.SampleRows(f.RowGroups(), 1000, rand.New(rand.NewSource(seed)))
+//
+// When n is greater than the number of rows, all the rows are returned. The
+// prng is used to select the rows, the functions of the math/rand package are
+// used if it is nil.
+func SampleRows(rowGroups []RowGroup, n int, prng *rand.Rand) ([]Row, error) {
+	int63n := rand.Int63n
+	if prng != nil {
+		int63n = prng.Int63n
+	}
+
+	totalRows := int64(0)
+	for _, rowGroup := range rowGroups {
+		totalRows += rowGroup.NumRows()
+	}
+
+	samples := sampleRowIndexes(totalRows, int64(n), int63n)
+	rows := make([]Row, 0, len(samples))
+	arena := []byte(nil)
+	firstRowIndex := int64(0)
+
+	for _, rowGroup := range rowGroups {
+		numRows := rowGroup.NumRows()
+		i := sort.Search(len(samples), func(i int) bool { return samples[i] >= firstRowIndex+numRows })
+		ranges := make([]RowRange, i)
+		for j, rowIndex := range samples[:i] {
+			ranges[j] = RowRange{FirstRowIndex: rowIndex - firstRowIndex, NumRows: 1}
+		}
+		samples, firstRowIndex = samples[i:], firstRowIndex+numRows
+
+		if len(ranges) > 0 {
+			var err error
+			if rows, arena, err = readSampleRows(rows, arena, rowGroup, ranges); err != nil {
+				return rows, err
+			}
+		}
+	}
+
+	return rows, nil
+}
+
+// sampleRowIndexes returns n distinct row indexes selected uniformly at random
+// in [0:numRows), in ascending order. The indexes are generated with Robert
+// Floyd's algorithm, which makes exactly n calls to the random number generator
+// and uses memory proportional to n.
+func sampleRowIndexes(numRows, n int64, int63n func(int64) int64) []int64 {
+	if n > numRows {
+		n = numRows
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	samples := make([]int64, 0, n)
+	if n == numRows {
+		for i := int64(0); i < numRows; i++ {
+			samples = append(samples, i)
+		}
+		return samples
+	}
+
+	selected := make(map[int64]struct{}, n)
+	for j := numRows - n; j < numRows; j++ {
+		i := int63n(j + 1)
+		if _, ok := selected[i]; ok {
+			i = j
+		}
+		selected[i] = struct{}{}
+		samples = append(samples, i)
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples
+}
+
+// readSampleRows appends copies of the rows of the row group within ranges to
+// rows, storing the byte arrays that they reference in arena.
+func readSampleRows(rows []Row, arena []byte, rowGroup RowGroup, ranges []RowRange) ([]Row, []byte, error) {
+	r := NewRowGroupRowRangeReader(rowGroup, ranges)
+	defer r.Close()
+
+	// Rows are read one at a time because a batch of rows may span multiple
+	// pages, and the values of byte arrays reference the memory of pages which
+	// may be reused when the next page is read.
+	buf := make([]Row, 1)
+	for {
+		n, err := r.ReadRows(buf)
+		if n > 0 {
+			var clones []Row
+			clones, arena = CloneRows(arena, buf[:n])
+			rows = append(rows, clones...)
+		}
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return rows, arena, err
+		}
+	}
+}
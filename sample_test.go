@@ -0,0 +1,115 @@
+package parquet_test
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSampleRows(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+
+	rows := make([]Row, 10000)
+	for i := range rows {
+		rows[i] = Row{ID: int64(i), Name: strconv.Itoa(i)}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.PageBufferSize(1024))
+	for i := range rows {
+		if err := w.Write(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+		if i%3000 == 2999 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := parquet.SchemaOf(Row{})
+
+	for _, n := range []int{0, 1, 10, 500, 10000, 20000} {
+		samples, err := parquet.SampleRows(f.RowGroups(), n, rand.New(rand.NewSource(int64(n))))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := n
+		if want > len(rows) {
+			want = len(rows)
+		}
+		if len(samples) != want {
+			t.Fatalf("wrong number of sampled rows: want=%d got=%d", want, len(samples))
+		}
+
+		lastID := int64(-1)
+		for _, sample := range samples {
+			var row Row
+			if err := schema.Reconstruct(&row, sample); err != nil {
+				t.Fatal(err)
+			}
+			if row.ID <= lastID {
+				t.Fatalf("sampled rows are not distinct or not in order: %d after %d", row.ID, lastID)
+			}
+			if row != rows[row.ID] {
+				t.Fatalf("wrong sampled row: want=%+v got=%+v", rows[row.ID], row)
+			}
+			lastID = row.ID
+		}
+	}
+}
+
+func TestSampleRowsDistribution(t *testing.T) {
+	const (
+		numRows    = 100
+		numSamples = 10
+		numTrials  = 4000
+	)
+
+	buffer := parquet.NewBuffer(parquet.SchemaOf(struct{ ID int64 }{}))
+	for i := 0; i < numRows; i++ {
+		if err := buffer.Write(struct{ ID int64 }{int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Split the rows in row groups of different sizes to verify that rows are
+	// sampled uniformly across row groups.
+	rowGroups := []parquet.RowGroup{
+		parquet.FilterRowGroup(buffer, func(row parquet.Row) bool { return row[0].Int64() < 10 }),
+		parquet.FilterRowGroup(buffer, func(row parquet.Row) bool { return row[0].Int64() >= 10 }),
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	counts := make([]int, numRows)
+	for i := 0; i < numTrials; i++ {
+		samples, err := parquet.SampleRows(rowGroups, numSamples, prng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range samples {
+			counts[row[0].Int64()]++
+		}
+	}
+
+	// Each row is expected to be sampled numTrials*numSamples/numRows = 400
+	// times, with a standard deviation of about 19.
+	for id, count := range counts {
+		if count < 300 || count > 500 {
+			t.Errorf("row %d was sampled %d times, expected about 400", id, count)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package parquet
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultScannerBufferSize = 64
+)
+
+// Scanner reads rows from a RowReaderWithSchema, such as a Reader, and copies
+// the values of the leaf columns of each row into Go values, similarly to the
+// Scan method of sql.Rows.
+//
+// Programs typically use a Scanner like this:
+//
+//	scanner := parquet.NewScanner(reader)
+//	for scanner.Next() {
+//		var id int64
+//		var name string
+//		var createdAt time.Time
+//		if err := scanner.Scan(&id, &name, &createdAt); err != nil {
+//			...
+//		}
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type Scanner struct {
+	rows    RowReaderWithSchema
+	columns []string
+	types   []Type
+	buffer  []Row
+	index   int
+	length  int
+	values  [][]Value
+	err     error
+}
+
+// NewScanner constructs a Scanner reading rows from the given reader.
+func NewScanner(rows RowReaderWithSchema) *Scanner {
+	schema := rows.Schema()
+	paths := schema.Columns()
+	s := &Scanner{
+		rows:    rows,
+		columns: make([]string, len(paths)),
+		types:   make([]Type, len(paths)),
+		buffer:  make([]Row, defaultScannerBufferSize),
+		values:  make([][]Value, len(paths)),
+	}
+	for i, path := range paths {
+		leaf, _ := schema.Lookup(path...)
+		s.columns[i] = strings.Join(path, ".")
+		s.types[i] = leaf.Node.Type()
+	}
+	return s
+}
+
+// Columns returns the paths of the leaf columns of rows read by s, with the
+// names of their parent groups separated by dots. The values of each column
+// are scanned into the destination at the same position in calls to Scan.
+func (s *Scanner) Columns() []string { return s.columns }
+
+// Next advances s to the next row, returning false when there are no more rows
+// or an error occurred, in which case it is returned by Err.
+func (s *Scanner) Next() bool {
+	for s.index++; s.index >= s.length; s.index = 0 {
+		if s.err != nil {
+			return false
+		}
+		s.length, s.err = s.rows.ReadRows(s.buffer)
+		if s.length == 0 && s.err == nil {
+			s.err = io.ErrNoProgress
+		}
+	}
+	row := s.buffer[s.index]
+	for i := range s.values {
+		s.values[i] = row.valuesOf(int16(i))
+	}
+	return true
+}
+
+// Err returns the error that caused Next to return false, or nil if all rows
+// were read.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+// Row returns the current row. The row is only valid until the next call to
+// Next.
+func (s *Scanner) Row() Row {
+	if s.index < s.length {
+		return s.buffer[s.index]
+	}
+	return nil
+}
+
+// Scan copies the values of the leaf columns of the current row into the
+// values pointed at by dest, which must have as many elements as there are
+// leaf columns; nil destinations skip the column.
+//
+// Values are converted according to the logical type of their column. The
+// following destination types are supported:
+//
+//   - *bool, *int*, *uint*, *float32, and *float64 from boolean and numeric
+//     columns, returning an error if the value does not fit the destination
+//   - *string from any column, formatting values which are not strings
+//   - *[]byte from byte array columns
+//   - *time.Time from TIMESTAMP, DATE, and TIME columns, and INT96 timestamps
+//   - *uuid.UUID from UUID columns
+//   - *big.Int from DECIMAL and integer columns, and *big.Rat from DECIMAL
+//     columns
+//   - *interface{} from any column, using the same Go types as the Min and Max
+//     methods of ColumnChunkStatistics
+//   - *parquet.Value from any column, and *[]parquet.Value which receives all
+//     values of a repeated column
+//   - sql.Scanner, with values passed as int64, float64, bool, string, []byte,
+//     time.Time, or nil
+//
+// Null values can only be scanned into *interface{}, *parquet.Value, and
+// sql.Scanner destinations (e.g. sql.NullString). Except for *[]parquet.Value,
+// scanning a repeated column which has more than one value in the row returns
+// an error.
+//
+// Values copied to the destinations do not retain references to the row.
+func (s *Scanner) Scan(dest ...interface{}) error {
+	if s.index >= s.length {
+		return fmt.Errorf("parquet scanner: Scan called without calling Next")
+	}
+	if len(dest) != len(s.values) {
+		return fmt.Errorf("parquet scanner: expected %d destination arguments in Scan, not %d", len(s.values), len(dest))
+	}
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		if err := scanColumn(d, s.types[i], s.values[i]); err != nil {
+			return fmt.Errorf("parquet scanner: column %s: %w", s.columns[i], err)
+		}
+	}
+	return nil
+}
+
+func scanColumn(dest interface{}, typ Type, values []Value) error {
+	if d, ok := dest.(*[]Value); ok {
+		*d = (*d)[:0]
+		for _, v := range values {
+			if !v.IsNull() {
+				*d = append(*d, v.Clone())
+			}
+		}
+		return nil
+	}
+	switch len(values) {
+	case 0:
+		return scanValue(dest, typ, Value{})
+	case 1:
+		return scanValue(dest, typ, values[0])
+	default:
+		return fmt.Errorf("cannot scan %d values of a repeated column into %T", len(values), dest)
+	}
+}
+
+func scanValue(dest interface{}, typ Type, v Value) error {
+	switch d := dest.(type) {
+	case *Value:
+		*d = v.Clone()
+		return nil
+	case *interface{}:
+		*d = goValueOf(typ, v)
+		return nil
+	case sql.Scanner:
+		src, err := driverValueOf(typ, v)
+		if err != nil {
+			return err
+		}
+		return d.Scan(src)
+	}
+
+	if v.IsNull() {
+		return fmt.Errorf("cannot scan null value into %T", dest)
+	}
+
+	switch d := dest.(type) {
+	case *bool:
+		b, err := v.AsBoolean()
+		*d = b
+		return err
+	case *int, *int8, *int16, *int32, *int64, *uint, *uint8, *uint16, *uint32, *uint64:
+		return scanInteger(dest, typ, v)
+	case *float32:
+		f, err := v.AsFloat()
+		*d = f
+		return err
+	case *float64:
+		f, err := v.AsDouble()
+		*d = f
+		return err
+	case *string:
+		*d = formatValue(typ, v)
+		return nil
+	case *[]byte:
+		b, err := v.AsByteArray()
+		*d = append((*d)[:0], b...)
+		return err
+	case *time.Time:
+		t, ok := v.Time(typ)
+		if !ok {
+			return fmt.Errorf("cannot scan %s value into %T", typ, dest)
+		}
+		*d = t
+		return nil
+	case *uuid.UUID:
+		id, ok := v.UUID()
+		if !ok {
+			return fmt.Errorf("cannot scan %s value into %T", typ, dest)
+		}
+		*d = id
+		return nil
+	case *big.Int:
+		if unscaled, scale, ok := v.Decimal(typ); ok {
+			if scale > 0 {
+				return fmt.Errorf("cannot scan %s value %v into %T", typ, v, dest)
+			}
+			d.Mul(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil))
+			return nil
+		}
+		signed, unsigned, isUnsigned, err := integerOf(typ, v)
+		if isUnsigned {
+			d.SetUint64(unsigned)
+		} else {
+			d.SetInt64(signed)
+		}
+		return err
+	case *big.Rat:
+		if unscaled, scale, ok := v.Decimal(typ); ok {
+			if scale > 0 {
+				d.SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+			} else {
+				exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-scale)), nil)
+				d.SetInt(exp.Mul(exp, unscaled))
+			}
+			return nil
+		}
+		return fmt.Errorf("cannot scan %s value into %T", typ, dest)
+	default:
+		return fmt.Errorf("unsupported scan destination type %T", dest)
+	}
+}
+
+// integerOf returns the integer value of v, interpreting it as unsigned if the
+// logical type of typ is an unsigned integer.
+func integerOf(typ Type, v Value) (signed int64, unsigned uint64, isUnsigned bool, err error) {
+	if signed, err = v.AsInt64(); err != nil {
+		return 0, 0, false, err
+	}
+	if isUnsigned = isUnsignedInteger(typ); isUnsigned && v.Kind() == Int32 {
+		signed = int64(v.Uint32())
+	}
+	return signed, uint64(signed), isUnsigned, nil
+}
+
+func scanInteger(dest interface{}, typ Type, v Value) error {
+	signed, unsigned, isUnsigned, err := integerOf(typ, v)
+	if err != nil {
+		return err
+	}
+	// Negative signed values do not fit in unsigned destinations, and unsigned
+	// values greater than math.MaxInt64 do not fit in signed destinations.
+	fitsSigned := !isUnsigned || unsigned <= math.MaxInt64
+	fitsUnsigned := isUnsigned || signed >= 0
+	ok := false
+
+	switch d := dest.(type) {
+	case *int:
+		if ok = fitsSigned && signed >= math.MinInt && signed <= math.MaxInt; ok {
+			*d = int(signed)
+		}
+	case *int8:
+		if ok = fitsSigned && signed >= math.MinInt8 && signed <= math.MaxInt8; ok {
+			*d = int8(signed)
+		}
+	case *int16:
+		if ok = fitsSigned && signed >= math.MinInt16 && signed <= math.MaxInt16; ok {
+			*d = int16(signed)
+		}
+	case *int32:
+		if ok = fitsSigned && signed >= math.MinInt32 && signed <= math.MaxInt32; ok {
+			*d = int32(signed)
+		}
+	case *int64:
+		if ok = fitsSigned; ok {
+			*d = signed
+		}
+	case *uint:
+		if ok = fitsUnsigned && unsigned <= math.MaxUint; ok {
+			*d = uint(unsigned)
+		}
+	case *uint8:
+		if ok = fitsUnsigned && unsigned <= math.MaxUint8; ok {
+			*d = uint8(unsigned)
+		}
+	case *uint16:
+		if ok = fitsUnsigned && unsigned <= math.MaxUint16; ok {
+			*d = uint16(unsigned)
+		}
+	case *uint32:
+		if ok = fitsUnsigned && unsigned <= math.MaxUint32; ok {
+			*d = uint32(unsigned)
+		}
+	case *uint64:
+		if ok = fitsUnsigned; ok {
+			*d = unsigned
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("cannot scan %s value %v into %T: %w", typ, v, dest, ErrInvalidConversion)
+	}
+	return nil
+}
+
+func isUnsignedInteger(typ Type) bool {
+	lt := typ.LogicalType()
+	return lt != nil && lt.Integer != nil && !lt.Integer.IsSigned
+}
+
+// formatValue returns a string representation of v according to the logical
+// type of typ.
+func formatValue(typ Type, v Value) string {
+	if v.IsNull() {
+		return ""
+	}
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.Timestamp != nil:
+			t, _ := v.Time(typ)
+			return t.Format(time.RFC3339Nano)
+		case lt.Date != nil:
+			t, _ := v.Time(typ)
+			return t.Format("2006-01-02")
+		case lt.Time != nil:
+			t, _ := v.Time(typ)
+			return t.Format("15:04:05.999999999")
+		case lt.UUID != nil:
+			if id, ok := v.UUID(); ok {
+				return id.String()
+			}
+		case lt.Decimal != nil:
+			if unscaled, scale, ok := v.Decimal(typ); ok {
+				return string(appendJSONDecimal(nil, unscaled, scale))
+			}
+		case lt.Integer != nil && !lt.Integer.IsSigned:
+			if v.Kind() == Int32 {
+				return strconv.FormatUint(uint64(v.Uint32()), 10)
+			}
+			return strconv.FormatUint(v.Uint64(), 10)
+		}
+	}
+	switch v.Kind() {
+	case Boolean:
+		return strconv.FormatBool(v.Boolean())
+	case Int32:
+		return strconv.FormatInt(int64(v.Int32()), 10)
+	case Int64:
+		return strconv.FormatInt(v.Int64(), 10)
+	case Int96:
+		return v.Int96().Time().Format(time.RFC3339Nano)
+	case Float:
+		return strconv.FormatFloat(float64(v.Float()), 'g', -1, 32)
+	case Double:
+		return strconv.FormatFloat(v.Double(), 'g', -1, 64)
+	default:
+		return string(v.ByteArray())
+	}
+}
+
+// driverValueOf returns the representation of v passed to sql.Scanner
+// destinations, which is one of the types of driver.Value.
+func driverValueOf(typ Type, v Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	if t, ok := v.Time(typ); ok {
+		return t, nil
+	}
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.UTF8 != nil, lt.Enum != nil, lt.Json != nil, lt.UUID != nil, lt.Decimal != nil:
+			return formatValue(typ, v), nil
+		case lt.Integer != nil && !lt.Integer.IsSigned:
+			if v.Kind() == Int64 && v.Int64() < 0 {
+				return nil, fmt.Errorf("unsigned value %d overflows int64", v.Uint64())
+			}
+			if v.Kind() == Int32 {
+				return int64(v.Uint32()), nil
+			}
+		}
+	}
+	switch v.Kind() {
+	case Boolean:
+		return v.Boolean(), nil
+	case Int32:
+		return int64(v.Int32()), nil
+	case Int64:
+		return v.Int64(), nil
+	case Float:
+		return float64(v.Float()), nil
+	case Double:
+		return v.Double(), nil
+	default:
+		return copyBytes(v.ByteArray()), nil
+	}
+}
@@ -0,0 +1,136 @@
+package parquet_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go"
+)
+
+func TestScanner(t *testing.T) {
+	type Record struct {
+		ID        int64     `parquet:"id"`
+		Name      string    `parquet:"name"`
+		Email     *string   `parquet:"email,optional"`
+		CreatedAt time.Time `parquet:"created_at"`
+		Birthday  int32     `parquet:"birthday,date"`
+		Price     int64     `parquet:"price,decimal(2:18)"`
+		Key       [16]byte  `parquet:"key,uuid"`
+		Tags      []string  `parquet:"tags"`
+	}
+
+	key := uuid.MustParse("3f1d9a6c-8a4e-4c8e-9d57-0a1b2c3d4e5f")
+	records := []Record{
+		{
+			ID:        1,
+			Name:      "Luke",
+			Email:     newString("luke@example.com"),
+			CreatedAt: time.Date(2022, 7, 4, 12, 30, 15, 500, time.UTC),
+			Birthday:  int32(time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC).Unix() / 86400),
+			Price:     -1205,
+			Key:       key,
+			Tags:      []string{"jedi"},
+		},
+		{
+			ID:        300,
+			Name:      "Leia",
+			CreatedAt: time.Unix(0, 0).UTC(),
+			Tags:      []string{"senator", "general"},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	w := parquet.NewWriter(buf)
+	for i := range records {
+		if err := w.Write(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := parquet.NewScanner(parquet.NewReader(bytes.NewReader(buf.Bytes())))
+	columns := []string{"id", "name", "email", "created_at", "birthday", "price", "key", "tags"}
+	if got := scanner.Columns(); !reflect.DeepEqual(got, columns) {
+		t.Fatalf("wrong columns:\nwant = %q\ngot  = %q", columns, got)
+	}
+
+	if !scanner.Next() {
+		t.Fatal("no rows:", scanner.Err())
+	}
+
+	var (
+		id        int16
+		name      string
+		email     sql.NullString
+		createdAt time.Time
+		birthday  string
+		price     big.Rat
+		id2       uuid.UUID
+		tag       interface{}
+	)
+	if err := scanner.Scan(&id, &name, &email, &createdAt, &birthday, &price, &id2, &tag); err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 || name != "Luke" || email != (sql.NullString{String: "luke@example.com", Valid: true}) {
+		t.Errorf("wrong values: id=%d name=%q email=%+v", id, name, email)
+	}
+	if !createdAt.Equal(records[0].CreatedAt) {
+		t.Errorf("wrong created_at: %v", createdAt)
+	}
+	if birthday != "2000-02-29" {
+		t.Errorf("wrong birthday: %q", birthday)
+	}
+	if price.Cmp(big.NewRat(-1205, 100)) != 0 {
+		t.Errorf("wrong price: %v", &price)
+	}
+	if id2 != key {
+		t.Errorf("wrong key: %v", id2)
+	}
+	if tag != "jedi" {
+		t.Errorf("wrong tag: %#v", tag)
+	}
+
+	if !scanner.Next() {
+		t.Fatal("missing second row:", scanner.Err())
+	}
+
+	var small int8
+	if err := scanner.Scan(&small, nil, nil, nil, nil, nil, nil, nil); !errors.Is(err, parquet.ErrInvalidConversion) {
+		t.Errorf("expected overflow error scanning 300 into int8, got %v", err)
+	}
+	if err := scanner.Scan(nil, &name, &email, nil, nil, nil, nil, &tag); err == nil {
+		t.Error("expected error scanning repeated column with two values into a single destination")
+	}
+	if err := scanner.Scan(nil, &name, &name, nil, nil, nil, nil, nil); err == nil {
+		t.Error("expected error scanning null value into *string")
+	}
+	if err := scanner.Scan(&id); err == nil {
+		t.Error("expected error scanning with the wrong number of destinations")
+	}
+
+	var tags []parquet.Value
+	if err := scanner.Scan(nil, &name, &email, nil, nil, nil, nil, &tags); err != nil {
+		t.Fatal(err)
+	}
+	if name != "Leia" || email.Valid {
+		t.Errorf("wrong values: name=%q email=%+v", name, email)
+	}
+	if len(tags) != 2 || tags[0].String() != "senator" || tags[1].String() != "general" {
+		t.Errorf("wrong tags: %v", tags)
+	}
+
+	if scanner.Next() {
+		t.Error("unexpected third row")
+	}
+	if err := scanner.Err(); err != nil {
+		t.Error(err)
+	}
+}
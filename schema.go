@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unsafe"
 
 	"github.com/google/uuid"
 	"github.com/segmentio/parquet-go/compress"
@@ -26,6 +28,21 @@ type Schema struct {
 	readRows    readRowsFunc
 	mapping     columnMapping
 	columns     [][]string
+	// The function marshaling rows to JSON is constructed on first use.
+	jsonOnce sync.Once
+	json     jsonFunc
+	// The functions converting rows from and to maps are constructed on first
+	// use.
+	mapOnce        sync.Once
+	mapDeconstruct mapDeconstructFunc
+	mapReconstruct mapReconstructFunc
+	// Plans reconstructing rows into Go values are compiled on first use for
+	// each Go type.
+	plans sync.Map // map[reflect.Type]reconstructPlan
+	// The fingerprint identifying the layout of the schema is computed on
+	// first use, see fingerprint.
+	fingerprintOnce sync.Once
+	fingerprintText string
 }
 
 // SchemaOf constructs a parquet schema from a Go value.
@@ -42,22 +59,23 @@ type Schema struct {
 //
 // The following options are also supported in the "parquet" struct tag:
 //
-//	optional  | make the parquet column optional
-//	snappy    | sets the parquet column compression codec to snappy
-//	gzip      | sets the parquet column compression codec to gzip
-//	brotli    | sets the parquet column compression codec to brotli
-//	lz4       | sets the parquet column compression codec to lz4
-//	zstd      | sets the parquet column compression codec to zstd
-//	plain     | enables the plain encoding (no-op default)
-//	dict      | enables dictionary encoding on the parquet column
-//	delta     | enables delta encoding on the parquet column
-//	list      | for slice types, use the parquet LIST logical type
-//	enum      | for string types, use the parquet ENUM logical type
-//	uuid      | for string and [16]byte types, use the parquet UUID logical type
-//	decimal   | for int32, int64 and [n]byte types, use the parquet DECIMAL logical type
-//	date      | for int32 types use the DATE logical type
-//	timestamp | for int64 types use the TIMESTAMP logical type with, by default, millisecond precision
-//	split     | for float32/float64, use the BYTE_STREAM_SPLIT encoding
+//	optional     | make the parquet column optional, writing zero values as nulls
+//	snappy       | sets the parquet column compression codec to snappy
+//	gzip         | sets the parquet column compression codec to gzip
+//	brotli       | sets the parquet column compression codec to brotli
+//	lz4          | sets the parquet column compression codec to lz4
+//	zstd         | sets the parquet column compression codec to zstd
+//	plain        | enables the plain encoding (no-op default)
+//	dict         | enables dictionary encoding on the parquet column
+//	delta        | enables delta encoding on the parquet column
+//	delta_length | for string and []byte types, use the DELTA_LENGTH_BYTE_ARRAY encoding
+//	list         | for slice types, use the parquet LIST logical type
+//	enum         | for string types, use the parquet ENUM logical type
+//	uuid         | for string and [16]byte types, use the parquet UUID logical type
+//	decimal      | for int32, int64 and [n]byte types, use the parquet DECIMAL logical type
+//	date         | for int32 types use the DATE logical type
+//	timestamp    | for int64 and time.Time types use the TIMESTAMP logical type with, by default, millisecond precision
+//	split        | for float32/float64, integer and [n]byte types, use the BYTE_STREAM_SPLIT encoding
 //
 // The date logical type is an int32 value of the number of days since the unix epoch
 //
@@ -68,6 +86,21 @@ type Schema struct {
 //    TimestrampMicros int64 `parquet:"timestamp_micros,timestamp(microsecond)"
 //  }
 //
+// Fields of type time.Time are mapped to TIMESTAMP columns with nanosecond
+// precision unless a different precision is set with the timestamp tag. When
+// reading, values of legacy INT96 timestamp columns may also be decoded into
+// time.Time fields.
+//
+// By default, zero values of optional fields are written as nulls. The optional
+// tag accepts an argument to change which values are nulls: "zero" for the
+// default behavior, or "nil" to only write nil pointers, slices, and maps as
+// nulls, and zero values of other types as non-null values. Example:
+//
+//	type Message struct {
+//		Count int64  `parquet:"count,optional(nil)"`
+//		Label *string `parquet:"label,optional(nil)"`
+//	}
+//
 // The decimal tag must be followed by two integer parameters, the first integer
 // representing the scale and the second the precision; for example:
 //
@@ -248,7 +281,8 @@ func (s *Schema) Reconstruct(value interface{}, row Row) error {
 	}
 	var err error
 	if s.reconstruct != nil {
-		row, err = s.reconstruct(v, levels{}, row)
+		reconstruct := s.reconstructPlanOf(v.Type())
+		row, err = reconstruct(unsafe.Pointer(v.UnsafeAddr()), levels{}, row)
 		if len(row) > 0 && err == nil {
 			err = fmt.Errorf("%d values remain unused after reconstructing go value of type %s from parquet row", len(row), v.Type())
 		}
@@ -282,6 +316,71 @@ func (s *Schema) Columns() [][]string {
 	return s.columns
 }
 
+// fingerprint returns a string identifying the layout of the schema, which is
+// equal for schemas with the same columns, types, encodings, and compression
+// codecs; the Go types that schemas were created from do not contribute to
+// the fingerprint.
+func (s *Schema) fingerprint() string {
+	s.fingerprintOnce.Do(func() {
+		b := new(strings.Builder)
+		PrintSchema(b, s.name, s.root)
+		forEachLeafColumnOf(s.root, func(leaf leafColumn) {
+			b.WriteString("\n")
+			b.WriteString(leaf.path.String())
+			b.WriteString(" ")
+			b.WriteString(encodingOf(leaf.node).String())
+			if codec := leaf.node.Compression(); codec != nil {
+				b.WriteString(" ")
+				b.WriteString(codec.String())
+			}
+		})
+		s.fingerprintText = b.String()
+	})
+	return s.fingerprintText
+}
+
+// Comparator constructs a function comparing rows of the schema according to
+// the sorting columns passed as arguments, which determine the order of the
+// columns in the comparison, whether values are sorted in descending order, and
+// whether null values are placed first or last. Sorting columns which do not
+// name leaf columns of the schema, nor are sorting keys, are ignored.
+//
+// The function returns a negative number when a < b, zero when the rows are
+// equal according to the sorting columns, and a positive number when a > b. It
+// orders rows the same way as a Buffer configured with the sorting columns,
+// and can be used to sort or merge rows outside of buffers, for example:
+//
+//	compare := schema.Comparator(parquet.Ascending("id"), parquet.NullsFirst(parquet.Descending("name")))
+//	sort.Slice(rows, func(i, j int) bool { return compare(rows[i], rows[j]) < 0 })
+//
+// The rows must hold the values of the sorting columns, ordered by column index
+// as produced by Deconstruct or by the readers of rows.
+func (s *Schema) Comparator(sortingColumns ...SortingColumn) func(a, b Row) int {
+	sortFuncs := columnSortFuncsOf(s, sortingColumns)
+	return func(a, b Row) int {
+		for _, sorting := range sortFuncs {
+			if sorting.key != nil {
+				if cmp := sorting.compareKey(sorting.key(a), sorting.key(b)); cmp != 0 {
+					return cmp
+				}
+				continue
+			}
+			values1 := a.valuesOf(sorting.columnIndex)
+			values2 := b.valuesOf(sorting.columnIndex)
+			if len(values1) == 0 || len(values2) == 0 {
+				if cmp := len(values1) - len(values2); cmp != 0 {
+					return cmp
+				}
+				continue
+			}
+			if cmp := sorting.compare(values1, values2); cmp != 0 {
+				return cmp
+			}
+		}
+		return 0
+	}
+}
+
 func (s *Schema) forEachNode(do func(name string, node Node)) {
 	forEachNodeOf(s.Name(), s, do)
 }
@@ -433,6 +532,7 @@ func makeStructField(f reflect.StructField) structField {
 	var (
 		field      = structField{name: f.Name, index: f.Index}
 		optional   bool
+		nullPolicy NullPolicy
 		list       bool
 		encoded    encoding.Encoding
 		compressed compress.Codec
@@ -477,6 +577,11 @@ func makeStructField(f reflect.StructField) structField {
 		switch option {
 		case "optional":
 			setOptional()
+			policy, err := parseOptionalArgs(args)
+			if err != nil {
+				throwInvalidFieldTag(f, option+args)
+			}
+			nullPolicy = policy
 
 		case "snappy":
 			setCompression(&Snappy)
@@ -524,10 +629,32 @@ func makeStructField(f reflect.StructField) structField {
 				throwInvalidFieldTag(f, option)
 			}
 
+		case "delta_length":
+			switch t.Kind() {
+			case reflect.String:
+				setEncoding(&DeltaLengthByteArray)
+			case reflect.Slice:
+				if t.Elem().Kind() == reflect.Uint8 { // []byte?
+					setEncoding(&DeltaLengthByteArray)
+				} else {
+					throwInvalidFieldTag(f, option)
+				}
+			default:
+				throwInvalidFieldTag(f, option)
+			}
+
 		case "split":
 			switch t.Kind() {
 			case reflect.Float32, reflect.Float64:
 				setEncoding(&ByteStreamSplit)
+			case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+				setEncoding(&ByteStreamSplit)
+			case reflect.Array:
+				if t.Elem().Kind() == reflect.Uint8 { // [N]byte?
+					setEncoding(&ByteStreamSplit)
+				} else {
+					throwInvalidFieldTag(f, option)
+				}
 			default:
 				throwInvalidFieldTag(f, option)
 			}
@@ -556,6 +683,7 @@ func makeStructField(f reflect.StructField) structField {
 				if t.Elem().Kind() != reflect.Uint8 || t.Len() != 16 {
 					throwInvalidFieldTag(f, option)
 				}
+				setNode(UUID())
 			default:
 				throwInvalidFieldTag(f, option)
 			}
@@ -586,13 +714,17 @@ func makeStructField(f reflect.StructField) structField {
 				throwInvalidFieldTag(f, option)
 			}
 		case "timestamp":
-			switch t.Kind() {
-			case reflect.Int64:
+			switch {
+			case t.Kind() == reflect.Int64 || t == reflect.TypeOf(time.Time{}):
 				timeUnit, err := parseTimestampArgs(args)
 				if err != nil {
 					throwInvalidFieldTag(f, args)
 				}
-				setNode(Timestamp(timeUnit))
+				if t.Kind() == reflect.Int64 {
+					setNode(Timestamp(timeUnit))
+				} else {
+					setNode(&goNode{Node: Timestamp(timeUnit), gotype: t})
+				}
 			default:
 				throwInvalidFieldTag(f, option)
 			}
@@ -618,12 +750,23 @@ func makeStructField(f reflect.StructField) structField {
 	}
 
 	if optional {
-		field.Node = Optional(field.Node)
+		field.Node = OptionalWith(field.Node, nullPolicy)
 	}
 
 	return field
 }
 
+func parseOptionalArgs(args string) (NullPolicy, error) {
+	switch args {
+	case "", "()", "(zero)":
+		return NullIfZero, nil
+	case "(nil)":
+		return NullIfNil, nil
+	default:
+		return NullIfZero, fmt.Errorf("malformed optional args: %s", args)
+	}
+}
+
 // FixedLenByteArray decimals are sized based on precision
 // this function calculates the necessary byte array size.
 func decimalFixedLenByteArraySize(precision int) int {
@@ -652,6 +795,8 @@ func nodeOf(t reflect.Type) Node {
 		return Leaf(Int96Type)
 	case reflect.TypeOf(uuid.UUID{}):
 		return UUID()
+	case reflect.TypeOf(time.Time{}):
+		return &goNode{Node: Timestamp(Nanosecond), gotype: t}
 	}
 
 	var n Node
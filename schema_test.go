@@ -1,6 +1,9 @@
 package parquet_test
 
 import (
+	"io"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/segmentio/parquet-go"
@@ -121,3 +124,85 @@ func TestSchemaOf(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaComparator(t *testing.T) {
+	type Row struct {
+		Group int64   `parquet:"group"`
+		Name  *string `parquet:"name,optional"`
+		Tags  []int32 `parquet:"tags"`
+	}
+
+	name := func(s string) *string { return &s }
+	rows := []Row{
+		{Group: 1, Name: name("b"), Tags: []int32{1}},
+		{Group: 2, Name: nil, Tags: []int32{2, 1}},
+		{Group: 1, Name: nil, Tags: []int32{3}},
+		{Group: 2, Name: name("a"), Tags: []int32{}},
+		{Group: 1, Name: name("a"), Tags: []int32{2, 2}},
+		{Group: 2, Name: name("c"), Tags: []int32{1}},
+		{Group: 1, Name: name("a"), Tags: []int32{0}},
+	}
+
+	schema := parquet.SchemaOf(Row{})
+
+	for _, test := range []struct {
+		scenario string
+		sorting  []parquet.SortingColumn
+		want     []int
+	}{
+		{
+			scenario: "ascending with nulls last",
+			sorting:  []parquet.SortingColumn{parquet.Ascending("group"), parquet.Ascending("name"), parquet.Ascending("tags")},
+			want:     []int{6, 4, 0, 2, 3, 5, 1},
+		},
+		{
+			scenario: "descending with nulls first",
+			sorting:  []parquet.SortingColumn{parquet.Descending("group"), parquet.NullsFirst(parquet.Descending("name"))},
+			want:     []int{1, 5, 3, 2, 0, 4, 6},
+		},
+		{
+			scenario: "descending with nulls last",
+			sorting:  []parquet.SortingColumn{parquet.Ascending("group"), parquet.Descending("name"), parquet.Descending("tags")},
+			want:     []int{0, 4, 6, 2, 5, 3, 1},
+		},
+		{
+			scenario: "unknown column",
+			sorting:  []parquet.SortingColumn{parquet.Ascending("missing"), parquet.Descending("tags"), parquet.Descending("group")},
+			want:     []int{2, 4, 1, 5, 0, 6, 3},
+		},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			compare := schema.Comparator(test.sorting...)
+
+			index := make([]int, len(rows))
+			values := make([]parquet.Row, len(rows))
+			for i := range rows {
+				index[i] = i
+				values[i] = schema.Deconstruct(nil, &rows[i])
+			}
+			sort.SliceStable(index, func(i, j int) bool { return compare(values[index[i]], values[index[j]]) < 0 })
+			if !reflect.DeepEqual(index, test.want) {
+				t.Errorf("wrong order of rows: want=%v got=%v", test.want, index)
+			}
+
+			// Buffers configured with the same sorting columns must produce the
+			// same order.
+			buffer := parquet.NewBuffer(schema, parquet.SortingColumns(test.sorting...))
+			for i := range rows {
+				if err := buffer.Write(&rows[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			sort.Stable(buffer)
+			sorted := make([]parquet.Row, len(rows))
+			if _, err := buffer.Rows().ReadRows(sorted); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			for i, row := range sorted {
+				if !row.Equal(values[test.want[i]]) {
+					t.Errorf("wrong row at index %d of the sorted buffer: want=%v got=%v", i, values[test.want[i]], row)
+				}
+			}
+		})
+	}
+}
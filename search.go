@@ -1,5 +1,10 @@
 package parquet
 
+import (
+	"fmt"
+	"sort"
+)
+
 // CompareNullsFirst constructs a comparison function which assumes that null
 // values are smaller than all other values.
 func CompareNullsFirst(cmp func(Value, Value) int) func(Value, Value) int {
@@ -59,8 +64,16 @@ func Search(index ColumnIndex, value Value, typ Type) int {
 //
 func Find(index ColumnIndex, value Value, cmp func(Value, Value) int) int {
 	switch {
+	case value.IsNull():
+		// Null values may only be found in pages which have no bounds, which
+		// are not ordered.
+		return linearSearch(index, value, cmp)
 	case index.IsAscending():
 		return binarySearch(index, value, cmp)
+	case index.IsDescending():
+		return binarySearch(descendingColumnIndex{index}, value, func(a, b Value) int {
+			return cmp(b, a)
+		})
 	default:
 		return linearSearch(index, value, cmp)
 	}
@@ -73,15 +86,26 @@ func binarySearch(index ColumnIndex, value Value, cmp func(Value, Value) int) in
 
 	for (j - i) > 1 {
 		k := ((j - i) / 2) + i
-		c := cmp(value, index.MinValue(k))
+		// Null pages have no bounds and are not part of the order of the
+		// column index, the search continues with the next page which has
+		// bounds; if there are none, the value is before page k.
+		p := k
+		for p < j && index.NullPage(p) {
+			p++
+		}
+		if p == j {
+			j = k
+			continue
+		}
+		c := cmp(value, index.MinValue(p))
 
 		switch {
 		case c < 0:
 			j = k
 		case c > 0:
-			i = k
+			i = p
 		default:
-			return k
+			return p
 		}
 	}
 
@@ -97,6 +121,13 @@ func binarySearch(index ColumnIndex, value Value, cmp func(Value, Value) int) in
 	return i
 }
 
+// descendingColumnIndex exposes the bounds of pages of a column index in
+// descending order as bounds in ascending order of the reverse ordering.
+type descendingColumnIndex struct{ ColumnIndex }
+
+func (index descendingColumnIndex) MinValue(i int) Value { return index.ColumnIndex.MaxValue(i) }
+func (index descendingColumnIndex) MaxValue(i int) Value { return index.ColumnIndex.MinValue(i) }
+
 func linearSearch(index ColumnIndex, value Value, cmp func(Value, Value) int) int {
 	n := index.NumPages()
 
@@ -111,3 +142,162 @@ func linearSearch(index ColumnIndex, value Value, cmp func(Value, Value) int) in
 
 	return n
 }
+
+// SearchRows uses the bounds of the column chunks and the column indexes of
+// row groups sorted by the column at the given path to find the first row which
+// may hold a value ordered at or after the given value.
+//
+// The search is performed in two steps: a binary search across row groups using
+// the statistics of their column chunks, or the bounds of their last page when
+// the column chunks have no statistics, then a binary search across the pages
+// of the selected column chunk using its column index.
+//
+// The function returns the index of the first row of the page which may hold
+// the value, counting rows from the beginning of the first row group; all the
+// rows before it hold values ordered before the value, so programs can seek to
+// the row and scan from there to find the exact position of the value. If all
+// the values are ordered before the value, the total number of rows is returned.
+//
+// The order of the column is determined by the first sorting column of the row
+// groups, which must be the column at path; the function returns an error if a
+// row group is not sorted by this column.
+func SearchRows(rowGroups []RowGroup, value Value, path ...string) (int64, error) {
+	if value.IsNull() {
+		return 0, fmt.Errorf("cannot search null value in column %q", columnPath(path))
+	}
+
+	// Empty row groups are excluded from the search since they have no bounds
+	// to compare the value to.
+	indexes := make([]int, 0, len(rowGroups))
+	offsets := make([]int64, 0, len(rowGroups)+1)
+	numRows := int64(0)
+	for i, rowGroup := range rowGroups {
+		if n := rowGroup.NumRows(); n > 0 {
+			indexes = append(indexes, i)
+			offsets = append(offsets, numRows)
+			numRows += n
+		}
+	}
+
+	searches := make([]rowGroupSearch, len(indexes))
+	var err error
+
+	i := sort.Search(len(indexes), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		s := &searches[i]
+		if err = s.init(rowGroups[indexes[i]], value, path); err != nil {
+			err = fmt.Errorf("searching row group %d: %w", indexes[i], err)
+			return true
+		}
+		return s.chunkMayReach()
+	})
+	if err != nil {
+		return 0, err
+	}
+	if i == len(indexes) {
+		return numRows, nil
+	}
+	return offsets[i] + searches[i].firstRowIndex(), nil
+}
+
+// rowGroupSearch holds the state of the search of a value in a row group sorted
+// by the column that the value is searched in.
+type rowGroupSearch struct {
+	chunk       ColumnChunk
+	columnIndex ColumnIndex
+	offsetIndex OffsetIndex
+	typ         Type
+	value       Value
+	descending  bool
+	nullsFirst  bool
+}
+
+func (s *rowGroupSearch) init(rowGroup RowGroup, value Value, path []string) error {
+	sortingColumns := rowGroup.SortingColumns()
+	if len(sortingColumns) == 0 || !columnPath(sortingColumns[0].Path()).equal(path) {
+		return fmt.Errorf("row group is not sorted by column %q", columnPath(path))
+	}
+	chunk, ok := LookupColumnChunk(rowGroup, path...)
+	if !ok {
+		return fmt.Errorf("column %q does not exist", columnPath(path))
+	}
+	typ := chunk.Type()
+	if kind := typ.Kind(); value.Kind() != kind {
+		return fmt.Errorf("cannot search value of kind %s in column %q of kind %s", value.Kind(), columnPath(path), kind)
+	}
+
+	*s = rowGroupSearch{
+		chunk:       chunk,
+		columnIndex: chunk.ColumnIndex(),
+		offsetIndex: chunk.OffsetIndex(),
+		typ:         typ,
+		value:       value,
+		descending:  sortingColumns[0].Descending(),
+		nullsFirst:  sortingColumns[0].NullsFirst(),
+	}
+	if s.columnIndex != nil && s.offsetIndex != nil && s.columnIndex.NumPages() != s.offsetIndex.NumPages() {
+		s.columnIndex, s.offsetIndex = nil, nil
+	}
+	return nil
+}
+
+// mayReach tests whether the values of a page or column chunk with the given
+// bounds and number of nulls may be ordered at or after the value searched.
+func (s *rowGroupSearch) mayReach(min, max Value, nullCount int64, nullPage bool) bool {
+	switch {
+	case nullPage:
+		return !s.nullsFirst
+	case nullCount > 0 && !s.nullsFirst:
+		return true
+	case s.descending:
+		return compareLowerBound(s.typ, s.value, min) >= 0
+	default:
+		return compareUpperBound(s.typ, s.value, max) <= 0
+	}
+}
+
+// chunkMayReach tests whether the column chunk may hold values ordered at or
+// after the value searched. Since the values are sorted, this is determined by
+// the bounds of the chunk, or of its last page.
+func (s *rowGroupSearch) chunkMayReach() bool {
+	if c, _ := s.chunk.(*fileColumnChunk); c != nil {
+		if min, max, _, ok := columnChunkBounds(c); ok {
+			stats := &c.chunk.MetaData.Statistics
+			return s.mayReach(min, max, stats.NullCount, stats.NullCount == c.chunk.MetaData.NumValues)
+		}
+	}
+	if s.columnIndex == nil || s.columnIndex.NumPages() == 0 {
+		// Without bounds, the column chunk cannot be excluded.
+		return true
+	}
+	return s.pageMayReach(s.columnIndex.NumPages() - 1)
+}
+
+func (s *rowGroupSearch) pageMayReach(i int) bool {
+	return s.mayReach(
+		s.columnIndex.MinValue(i),
+		s.columnIndex.MaxValue(i),
+		s.columnIndex.NullCount(i),
+		s.columnIndex.NullPage(i),
+	)
+}
+
+// firstRowIndex returns the index of the first row of the first page which may
+// hold values ordered at or after the value searched, or zero if the column
+// chunk has no page index.
+func (s *rowGroupSearch) firstRowIndex() int64 {
+	if s.columnIndex == nil || s.offsetIndex == nil {
+		return 0
+	}
+	numPages := s.columnIndex.NumPages()
+	i := sort.Search(numPages, s.pageMayReach)
+	if i == numPages {
+		// The bounds of the column chunk included the value, but none of its
+		// pages did; this may only happen if the statistics are inconsistent,
+		// in which case the first page is the safe choice.
+		return 0
+	}
+	return s.offsetIndex.FirstRowIndex(i)
+}
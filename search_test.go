@@ -1,11 +1,14 @@
 package parquet_test
 
 import (
+	"bytes"
 	"fmt"
 	"math"
+	"sort"
 	"testing"
 
 	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/format"
 )
 
 func assertCompare(t *testing.T, a, b parquet.Value, cmp func(parquet.Value, parquet.Value) int, want int) {
@@ -52,10 +55,89 @@ func TestSearchLinear(t *testing.T) {
 	})
 }
 
+func TestSearchBinaryNullPages(t *testing.T) {
+	testSearch(t, [][]int32{
+		nil,
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		nil,
+		{10, 10, 10, 10},
+		nil,
+		nil,
+		{21, 22, 23, 24, 25},
+		{30},
+		nil,
+	})
+}
+
+func TestSearchBinaryDescending(t *testing.T) {
+	testSearch(t, [][]int32{
+		{42, 43, 44, 45, 46, 47, 48, 49},
+		{32},
+		nil,
+		{31},
+		{21, 22, 23, 24, 25},
+		{10, 10, 10, 10},
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	})
+}
+
+func TestColumnIndexBoundaryOrder(t *testing.T) {
+	for _, test := range []struct {
+		scenario string
+		pages    [][2]int32 // min/max of non-null pages, nil for null pages
+		nulls    []int
+		want     format.BoundaryOrder
+	}{
+		{"single page", [][2]int32{{1, 2}}, nil, format.Unordered},
+		{"ascending", [][2]int32{{1, 2}, {2, 5}, {3, 9}}, nil, format.Ascending},
+		{"descending", [][2]int32{{3, 9}, {2, 5}, {1, 2}}, nil, format.Descending},
+		{"unordered", [][2]int32{{1, 2}, {0, 5}, {3, 9}}, nil, format.Unordered},
+		{"constant min and descending max", [][2]int32{{1, 9}, {1, 5}, {1, 2}}, nil, format.Descending},
+		{"constant min and ascending max", [][2]int32{{1, 2}, {1, 5}, {1, 9}}, nil, format.Ascending},
+		{"constant", [][2]int32{{1, 2}, {1, 2}}, nil, format.Ascending},
+		{"ascending with null pages", [][2]int32{{-5, -1}, {-3, 2}, {3, 9}}, []int{0, 2, 4}, format.Ascending},
+		{"descending with null pages", [][2]int32{{-1, 9}, {-3, 2}, {-5, 1}}, []int{1, 3}, format.Descending},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			indexer := parquet.Int32Type.NewColumnIndexer(0)
+			numPages := len(test.pages) + len(test.nulls)
+			nulls := test.nulls
+			pages := test.pages
+
+			for i := 0; i < numPages; i++ {
+				if len(nulls) > 0 && nulls[0] == i {
+					indexer.IndexPage(10, 10, parquet.Value{}, parquet.Value{})
+					nulls = nulls[1:]
+				} else {
+					indexer.IndexPage(10, 0, parquet.ValueOf(pages[0][0]), parquet.ValueOf(pages[0][1]))
+					pages = pages[1:]
+				}
+			}
+
+			columnIndex := indexer.ColumnIndex()
+			if columnIndex.BoundaryOrder != test.want {
+				t.Errorf("wrong boundary order: want=%s got=%s", test.want, columnIndex.BoundaryOrder)
+			}
+			if len(columnIndex.MinValues) != numPages || len(columnIndex.MaxValues) != numPages {
+				t.Fatalf("wrong number of bounds: want=%d got=%d/%d", numPages, len(columnIndex.MinValues), len(columnIndex.MaxValues))
+			}
+			for _, i := range test.nulls {
+				if len(columnIndex.MinValues[i]) != 0 || len(columnIndex.MaxValues[i]) != 0 {
+					t.Errorf("bounds of null page %d are not empty: min=%v max=%v", i, columnIndex.MinValues[i], columnIndex.MaxValues[i])
+				}
+			}
+		})
+	}
+}
+
 func testSearch(t *testing.T, pages [][]int32) {
 	indexer := parquet.Int32Type.NewColumnIndexer(0)
 
 	for _, values := range pages {
+		if values == nil {
+			indexer.IndexPage(1, 1, parquet.Value{}, parquet.Value{})
+			continue
+		}
 		min := values[0]
 		max := values[0]
 
@@ -96,3 +178,114 @@ func testSearch(t *testing.T, pages [][]int32) {
 		})
 	}
 }
+
+func TestSearchRows(t *testing.T) {
+	type Row struct {
+		Key  int64  `parquet:"key"`
+		Name string `parquet:"name"`
+	}
+
+	const numRows = 10000
+	const rowsPerGroup = 2500
+
+	for _, descending := range []bool{false, true} {
+		t.Run(fmt.Sprintf("descending=%t", descending), func(t *testing.T) {
+			// Keys are repeated three times to verify that the search finds
+			// the first of the rows holding a key.
+			keys := make([]int64, numRows)
+			for i := range keys {
+				keys[i] = 10 * int64(i/3)
+				if descending {
+					keys[i] = 10 * int64((numRows-1-i)/3)
+				}
+			}
+
+			sortingColumn := parquet.Ascending("key")
+			if descending {
+				sortingColumn = parquet.Descending("key")
+			}
+
+			b := new(bytes.Buffer)
+			w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+				parquet.PageBufferSize(512),
+				parquet.SortingColumns(sortingColumn),
+			)
+			for i, key := range keys {
+				if err := w.Write(Row{Key: key, Name: fmt.Sprintf("name-%d", i)}); err != nil {
+					t.Fatal(err)
+				}
+				if i%rowsPerGroup == rowsPerGroup-1 {
+					if err := w.Flush(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rowGroups := f.RowGroups()
+
+			maxPageRows := int64(0)
+			for _, rowGroup := range rowGroups {
+				offsetIndex := rowGroup.ColumnChunks()[0].OffsetIndex()
+				for i := 0; i < offsetIndex.NumPages(); i++ {
+					end := rowGroup.NumRows()
+					if i+1 < offsetIndex.NumPages() {
+						end = offsetIndex.FirstRowIndex(i + 1)
+					}
+					if n := end - offsetIndex.FirstRowIndex(i); n > maxPageRows {
+						maxPageRows = n
+					}
+				}
+			}
+
+			for key := int64(-5); key <= 10*numRows/3+20; key += 5 {
+				// The index of the first row holding a key ordered at or
+				// after the searched key.
+				want := sort.Search(len(keys), func(i int) bool {
+					if descending {
+						return keys[i] <= key
+					}
+					return keys[i] >= key
+				})
+
+				got, err := parquet.SearchRows(rowGroups, parquet.ValueOf(key), "key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got > int64(want) || int64(want)-got >= maxPageRows {
+					t.Fatalf("searching key %d: want row in [%d:%d] got %d", key, int64(want)-maxPageRows+1, want, got)
+				}
+			}
+		})
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.SortingColumns(parquet.Ascending("key")),
+	)
+	if err := w.Write(Row{Key: 1, Name: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parquet.SearchRows(f.RowGroups(), parquet.ValueOf("one"), "name"); err == nil {
+		t.Error("searching a column that the row groups are not sorted by did not fail")
+	}
+	if _, err := parquet.SearchRows(f.RowGroups(), parquet.ValueOf(int32(1)), "key"); err == nil {
+		t.Error("searching a value of the wrong kind did not fail")
+	}
+	if _, err := parquet.SearchRows(f.RowGroups(), parquet.Value{}, "key"); err == nil {
+		t.Error("searching a null value did not fail")
+	}
+}
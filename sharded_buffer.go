@@ -0,0 +1,197 @@
+package parquet
+
+import (
+	"sort"
+)
+
+// ShardedBuffer routes rows to in-memory buffers by the value of a key computed
+// from each row, and flushes each buffer separately, which lets programs write
+// the rows of each key (e.g. each tenant of a multi-tenant ingester) to their
+// own row groups or files.
+//
+// The size of the rows held by all the buffers is bounded: when it exceeds the
+// maximum size, the largest buffers are flushed until the size goes back under
+// the limit. The remaining buffers are flushed by calling Flush or FlushAll.
+//
+// Buffers are flushed by calling the flush function passed to the constructor
+// with the key and the buffer, which may for example write the buffer to a
+// Writer with WriteRowGroup to produce a row group, or create a new file:
+//
+//	buffer := parquet.NewShardedBuffer(64<<20,
+//		func(row parquet.Row) string {
+//			return row[tenantColumnIndex].String()
+//		},
+//		func(tenant string, rowGroup parquet.RowGroup) error {
+//			_, err := writers[tenant].WriteRowGroup(rowGroup)
+//			return err
+//		},
+//		parquet.SchemaOf(new(Event)),
+//	)
+//
+// The row group passed to the flush function is only valid until the function
+// returns; the rows of the buffer are discarded after the call, even if it
+// returned an error. When the options configure sorting columns, the rows of
+// the buffer are sorted before being flushed.
+//
+// ShardedBuffer values are not safe to use concurrently from multiple
+// goroutines.
+type ShardedBuffer struct {
+	key     func(Row) string
+	flush   func(string, RowGroup) error
+	options []RowGroupOption
+	schema  *Schema
+	maxSize int64
+	size    int64
+	shards  map[string]*bufferShard
+	rowbuf  []Row
+}
+
+type bufferShard struct {
+	buffer *Buffer
+	size   int64
+}
+
+// NewShardedBuffer constructs a buffer routing rows to buffers by the keys that
+// the key function computes, and flushing them with the flush function when the
+// size of the rows held in memory exceeds maxBufferSize bytes. If maxBufferSize
+// is zero or negative, buffers are only flushed by calls to Flush or FlushAll.
+//
+// The key function is called with rows holding the values of all the columns,
+// in the order produced by Deconstruct. The options are passed to the buffers
+// of each key. The function panics if the buffer configuration is invalid, see
+// NewBuffer for details.
+func NewShardedBuffer(maxBufferSize int64, key func(Row) string, flush func(key string, rowGroup RowGroup) error, options ...RowGroupOption) *ShardedBuffer {
+	config, err := NewRowGroupConfig(options...)
+	if err != nil {
+		panic(err)
+	}
+	return &ShardedBuffer{
+		key:     key,
+		flush:   flush,
+		options: options,
+		schema:  config.Schema,
+		maxSize: maxBufferSize,
+		shards:  make(map[string]*bufferShard),
+		rowbuf:  make([]Row, 0, 1),
+	}
+}
+
+// Schema returns the schema of the buffer, which may be nil if it was not
+// configured and no rows were written yet.
+func (b *ShardedBuffer) Schema() *Schema { return b.schema }
+
+// Size returns the estimated size of the rows held in memory by all the
+// buffers.
+func (b *ShardedBuffer) Size() int64 { return b.size }
+
+// NumShards returns the number of keys that the buffer holds rows of.
+func (b *ShardedBuffer) NumShards() int { return len(b.shards) }
+
+// Keys returns the keys that the buffer holds rows of, in lexicographic order.
+func (b *ShardedBuffer) Keys() []string {
+	keys := make([]string, 0, len(b.shards))
+	for key := range b.shards {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write writes a row held in a Go value to the buffer.
+func (b *ShardedBuffer) Write(row interface{}) error {
+	if b.schema == nil {
+		b.schema = SchemaOf(row)
+	}
+	b.rowbuf = b.rowbuf[:1]
+	defer clearRows(b.rowbuf)
+
+	b.rowbuf[0] = b.schema.Deconstruct(b.rowbuf[0], row)
+	_, err := b.WriteRows(b.rowbuf)
+	return err
+}
+
+// WriteRows writes parquet rows to the buffer.
+func (b *ShardedBuffer) WriteRows(rows []Row) (int, error) {
+	if b.schema == nil {
+		return 0, ErrRowGroupSchemaMissing
+	}
+
+	for i := 0; i < len(rows); {
+		// Consecutive rows of the same key are written to the buffer of the
+		// key in a single call.
+		key := b.key(rows[i])
+		j := i + 1
+		for j < len(rows) && b.key(rows[j]) == key {
+			j++
+		}
+
+		shard := b.shards[key]
+		if shard == nil {
+			shard = &bufferShard{buffer: NewBuffer(append(b.options[:len(b.options):len(b.options)], b.schema)...)}
+			b.shards[key] = shard
+		}
+
+		n, err := shard.buffer.WriteRows(rows[i:j])
+		size := shard.buffer.Size()
+		b.size += size - shard.size
+		shard.size = size
+		if err != nil {
+			return i + n, err
+		}
+		if err := b.flushIfFull(); err != nil {
+			return j, err
+		}
+		i = j
+	}
+
+	return len(rows), nil
+}
+
+// Flush flushes the buffer of the given key, if the buffer holds rows of the
+// key.
+func (b *ShardedBuffer) Flush(key string) error {
+	if shard := b.shards[key]; shard != nil {
+		return b.flushShard(key, shard)
+	}
+	return nil
+}
+
+// FlushAll flushes the buffers of all the keys, in lexicographic order.
+func (b *ShardedBuffer) FlushAll() error {
+	for _, key := range b.Keys() {
+		if err := b.Flush(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushIfFull flushes the largest buffers until the size of the rows held in
+// memory goes under the maximum size.
+func (b *ShardedBuffer) flushIfFull() error {
+	for b.maxSize > 0 && b.size > b.maxSize && len(b.shards) > 0 {
+		var largestKey string
+		var largest *bufferShard
+
+		for key, shard := range b.shards {
+			if largest == nil || shard.size > largest.size || (shard.size == largest.size && key < largestKey) {
+				largestKey, largest = key, shard
+			}
+		}
+
+		if err := b.flushShard(largestKey, largest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *ShardedBuffer) flushShard(key string, shard *bufferShard) error {
+	delete(b.shards, key)
+	b.size -= shard.size
+
+	if len(shard.buffer.SortingColumns()) > 0 {
+		sort.Stable(shard.buffer)
+	}
+	return b.flush(key, shard.buffer)
+}
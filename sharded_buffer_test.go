@@ -0,0 +1,178 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+type shardedRow struct {
+	Tenant string `parquet:"tenant"`
+	ID     int64  `parquet:"id"`
+	Value  string `parquet:"value"`
+}
+
+func TestShardedBuffer(t *testing.T) {
+	const numTenants = 10
+	rows := make([]shardedRow, 10000)
+	for i := range rows {
+		rows[i] = shardedRow{
+			Tenant: fmt.Sprintf("tenant-%d", (i*7)%numTenants),
+			ID:     int64(len(rows) - i),
+			Value:  fmt.Sprintf("value-%d", i),
+		}
+	}
+
+	schema := parquet.SchemaOf(new(shardedRow))
+	flushed := map[string][]shardedRow{}
+	numFlushes := 0
+
+	buffer := parquet.NewShardedBuffer(64*1024,
+		func(row parquet.Row) string {
+			return row[0].String()
+		},
+		func(tenant string, rowGroup parquet.RowGroup) error {
+			numFlushes++
+			for _, row := range readShardedRows(t, rowGroup) {
+				if row.Tenant != tenant {
+					t.Fatalf("row of tenant %q flushed with tenant %q", row.Tenant, tenant)
+				}
+				flushed[tenant] = append(flushed[tenant], row)
+			}
+			return nil
+		},
+		schema,
+		parquet.SortingColumns(parquet.Ascending("id")),
+	)
+
+	for i := range rows {
+		if err := buffer.Write(&rows[i]); err != nil {
+			t.Fatal(err)
+		}
+		if buffer.Size() > 64*1024 {
+			t.Fatalf("buffer size exceeds the limit: %d", buffer.Size())
+		}
+	}
+	if numFlushes == 0 {
+		t.Fatal("the buffer did not flush any shard before FlushAll")
+	}
+	if n := buffer.NumShards(); n != numTenants {
+		t.Errorf("wrong number of shards: want=%d got=%d", numTenants, n)
+	}
+	if err := buffer.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if buffer.NumShards() != 0 || buffer.Size() != 0 {
+		t.Errorf("buffer not empty after FlushAll: shards=%d size=%d", buffer.NumShards(), buffer.Size())
+	}
+
+	want := map[string][]shardedRow{}
+	for _, row := range rows {
+		want[row.Tenant] = append(want[row.Tenant], row)
+	}
+	for tenant, got := range flushed {
+		// Each flush produces rows sorted by id, the rows of a tenant are then
+		// sorted across flushes to compare them.
+		sort.Slice(got, func(i, j int) bool { return got[i].ID < got[j].ID })
+		sort.Slice(want[tenant], func(i, j int) bool { return want[tenant][i].ID < want[tenant][j].ID })
+		if !reflect.DeepEqual(got, want[tenant]) {
+			t.Errorf("rows of tenant %q mismatch: want=%d rows got=%d rows", tenant, len(want[tenant]), len(got))
+		}
+	}
+	if len(flushed) != len(want) {
+		t.Errorf("wrong number of tenants flushed: want=%d got=%d", len(want), len(flushed))
+	}
+}
+
+func TestShardedBufferWriter(t *testing.T) {
+	output := new(bytes.Buffer)
+	writer := parquet.NewWriter(output, parquet.SchemaOf(new(shardedRow)))
+
+	buffer := parquet.NewShardedBuffer(0,
+		func(row parquet.Row) string {
+			return row[0].String()
+		},
+		func(tenant string, rowGroup parquet.RowGroup) error {
+			_, err := writer.WriteRowGroup(rowGroup)
+			return err
+		},
+	)
+
+	rows := []parquet.Row{}
+	schema := parquet.SchemaOf(new(shardedRow))
+	for i := 0; i < 100; i++ {
+		rows = append(rows, schema.Deconstruct(nil, &shardedRow{
+			Tenant: fmt.Sprintf("tenant-%d", i%3),
+			ID:     int64(i),
+		}))
+	}
+	if _, err := buffer.WriteRows(rows); err != parquet.ErrRowGroupSchemaMissing {
+		t.Fatalf("writing rows to a buffer without schema: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if err := buffer.Write(&shardedRow{Tenant: fmt.Sprintf("tenant-%d", i%3), ID: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys := buffer.Keys(); !reflect.DeepEqual(keys, []string{"tenant-0", "tenant-1", "tenant-2"}) {
+		t.Errorf("wrong keys: %q", keys)
+	}
+	if err := buffer.Flush("tenant-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := buffer.FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenants := []string{}
+	for _, rowGroup := range f.RowGroups() {
+		rows := readShardedRows(t, rowGroup)
+		for _, row := range rows {
+			if row.Tenant != rows[0].Tenant {
+				t.Fatalf("row group of tenant %q holds rows of tenant %q", rows[0].Tenant, row.Tenant)
+			}
+		}
+		tenants = append(tenants, rows[0].Tenant)
+	}
+	if !reflect.DeepEqual(tenants, []string{"tenant-1", "tenant-0", "tenant-2"}) {
+		t.Errorf("wrong row groups: %q", tenants)
+	}
+}
+
+func readShardedRows(t *testing.T, rowGroup parquet.RowGroup) []shardedRow {
+	t.Helper()
+	rows := rowGroup.Rows()
+	defer rows.Close()
+
+	schema := parquet.SchemaOf(new(shardedRow))
+	result := []shardedRow{}
+	buffer := make([]parquet.Row, 10)
+	for {
+		n, err := rows.ReadRows(buffer)
+		for _, row := range buffer[:n] {
+			r := shardedRow{}
+			if err := schema.Reconstruct(&r, row); err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, r)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return result
+			}
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package parquet
+
+// SizeStatistics carries statistics of the sizes of the values of a column
+// chunk or page, which help estimating the memory needed to decode them before
+// reading their content.
+type SizeStatistics struct {
+	// Number of bytes of the BYTE_ARRAY values, excluding the bytes needed to
+	// store their lengths, or zero for columns of other types.
+	UnencodedByteArrayDataBytes int64
+	// Number of occurrences of each repetition and definition level, indexed
+	// by level. A histogram is nil if the maximum level of the column is zero.
+	//
+	// The number of values, including nulls, is the sum of either histogram,
+	// and the number of non-null values is the count of the maximum definition
+	// level.
+	RepetitionLevelHistogram []int64
+	DefinitionLevelHistogram []int64
+}
+
+// SizeStatisticsOf returns the size statistics of the column chunk.
+//
+// The boolean is false if the column chunk has no size statistics, for example
+// if it was not read from a file, or if the file was written by an application
+// which did not record them.
+func SizeStatisticsOf(chunk ColumnChunk) (SizeStatistics, bool) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil {
+		return SizeStatistics{}, false
+	}
+	s := &c.chunk.MetaData.SizeStatistics
+	if s.UnencodedByteArrayDataBytes == 0 && s.RepetitionLevelHistogram == nil && s.DefinitionLevelHistogram == nil {
+		return SizeStatistics{}, false
+	}
+	return SizeStatistics{
+		UnencodedByteArrayDataBytes: s.UnencodedByteArrayDataBytes,
+		RepetitionLevelHistogram:    copyLevelHistogram(s.RepetitionLevelHistogram),
+		DefinitionLevelHistogram:    copyLevelHistogram(s.DefinitionLevelHistogram),
+	}, true
+}
+
+// PageSizeStatisticsOf returns the size statistics of each data page of the
+// column chunk, read from its page index.
+//
+// The boolean is false if the page index of the column chunk has no size
+// statistics.
+func PageSizeStatisticsOf(chunk ColumnChunk) ([]SizeStatistics, bool) {
+	c, _ := chunk.(*fileColumnChunk)
+	if c == nil || c.offsetIndex == nil {
+		return nil, false
+	}
+
+	numPages := len(c.offsetIndex.PageLocations)
+	stats := make([]SizeStatistics, numPages)
+	found := false
+
+	if sizes := c.offsetIndex.UnencodedByteArrayDataBytes; len(sizes) == numPages {
+		for i, size := range sizes {
+			stats[i].UnencodedByteArrayDataBytes = size
+		}
+		found = true
+	}
+
+	if c.columnIndex != nil {
+		if splitLevelHistograms(stats, c.columnIndex.RepetitionLevelHistograms, c.column.MaxRepetitionLevel(), func(s *SizeStatistics, h []int64) {
+			s.RepetitionLevelHistogram = h
+		}) {
+			found = true
+		}
+		if splitLevelHistograms(stats, c.columnIndex.DefinitionLevelHistograms, c.column.MaxDefinitionLevel(), func(s *SizeStatistics, h []int64) {
+			s.DefinitionLevelHistogram = h
+		}) {
+			found = true
+		}
+	}
+
+	if !found {
+		return nil, false
+	}
+	return stats, true
+}
+
+// splitLevelHistograms assigns the histograms of each page, concatenated in
+// histograms, to the page statistics. The histograms are ignored if their
+// length is inconsistent with the number of pages.
+func splitLevelHistograms(stats []SizeStatistics, histograms []int64, maxLevel int, set func(*SizeStatistics, []int64)) bool {
+	size := maxLevel + 1
+	if maxLevel == 0 || len(histograms) == 0 || len(histograms) != size*len(stats) {
+		return false
+	}
+	histograms = copyLevelHistogram(histograms)
+	for i := range stats {
+		set(&stats[i], histograms[i*size:(i+1)*size:(i+1)*size])
+	}
+	return true
+}
+
+// unencodedByteArrayDataBytes returns the number of bytes of the BYTE_ARRAY
+// values of the page, excluding their lengths.
+func unencodedByteArrayDataBytes(page Page) int64 {
+	switch p := page.(type) {
+	case *optionalPage:
+		return unencodedByteArrayDataBytes(p.base)
+	case *repeatedPage:
+		return unencodedByteArrayDataBytes(p.base)
+	case *byteArrayPage:
+		// Values of byte array pages are PLAIN encoded, each is prefixed with
+		// its length on 4 bytes.
+		return int64(len(p.values)) - 4*int64(p.numValues)
+	case *indexedPage:
+		if d, _ := p.typ.dict.(*byteArrayDictionary); d != nil {
+			size := int64(0)
+			for _, i := range p.values {
+				size += int64(len(d.index(i)))
+			}
+			return size
+		}
+	}
+
+	size := int64(0)
+	values := make([]Value, 64)
+	reader := page.Values()
+	for {
+		n, err := reader.ReadValues(values)
+		for _, v := range values[:n] {
+			size += int64(len(v.ByteArray()))
+		}
+		if err != nil {
+			return size
+		}
+	}
+}
+
+// appendLevelHistogram appends to histograms the histogram of the levels of a
+// page, which has maxLevel+1 elements.
+func appendLevelHistogram(histograms []int64, levels []byte, maxLevel byte) []int64 {
+	offset := len(histograms)
+	for i := 0; i <= int(maxLevel); i++ {
+		histograms = append(histograms, 0)
+	}
+	histogram := histograms[offset:]
+	for _, level := range levels {
+		histogram[level]++
+	}
+	return histograms
+}
+
+// addLevelHistogram adds the counts of histogram to sum, which is allocated if
+// it is nil.
+func addLevelHistogram(sum, histogram []int64) []int64 {
+	if sum == nil {
+		sum = make([]int64, len(histogram))
+	}
+	for i, count := range histogram {
+		sum[i] += count
+	}
+	return sum
+}
+
+func copyLevelHistogram(histogram []int64) []int64 {
+	if histogram == nil {
+		return nil
+	}
+	return append([]int64{}, histogram...)
+}
@@ -0,0 +1,140 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSizeStatistics(t *testing.T) {
+	type Row struct {
+		ID    int64    `parquet:"id"`
+		Name  string   `parquet:"name"`
+		Color string   `parquet:"color,dict"`
+		Note  *string  `parquet:"note,optional"`
+		Tags  []string `parquet:"tags"`
+	}
+
+	const numRows = 500
+	want := map[string]parquet.SizeStatistics{
+		"id":    {},
+		"name":  {},
+		"color": {},
+		"note":  {DefinitionLevelHistogram: make([]int64, 2)},
+		"tags":  {RepetitionLevelHistogram: make([]int64, 2), DefinitionLevelHistogram: make([]int64, 2)},
+	}
+	update := func(path string, f func(*parquet.SizeStatistics)) {
+		s := want[path]
+		f(&s)
+		want[path] = s
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.PageBufferSize(512))
+	for i := 0; i < numRows; i++ {
+		row := Row{
+			ID:    int64(i),
+			Name:  fmt.Sprintf("name-%d", i),
+			Color: fmt.Sprintf("color-%d", i%3),
+			Tags:  make([]string, i%3),
+		}
+		update("name", func(s *parquet.SizeStatistics) { s.UnencodedByteArrayDataBytes += int64(len(row.Name)) })
+		update("color", func(s *parquet.SizeStatistics) { s.UnencodedByteArrayDataBytes += int64(len(row.Color)) })
+
+		if i%2 == 0 {
+			note := fmt.Sprintf("note-%d", i)
+			row.Note = &note
+			update("note", func(s *parquet.SizeStatistics) {
+				s.UnencodedByteArrayDataBytes += int64(len(note))
+				s.DefinitionLevelHistogram[1]++
+			})
+		} else {
+			update("note", func(s *parquet.SizeStatistics) { s.DefinitionLevelHistogram[0]++ })
+		}
+
+		update("tags", func(s *parquet.SizeStatistics) {
+			if len(row.Tags) == 0 {
+				s.RepetitionLevelHistogram[0]++
+				s.DefinitionLevelHistogram[0]++
+				return
+			}
+			for j := range row.Tags {
+				row.Tags[j] = fmt.Sprintf("tag-%d", j)
+				s.UnencodedByteArrayDataBytes += int64(len(row.Tags[j]))
+				if j == 0 {
+					s.RepetitionLevelHistogram[0]++
+				} else {
+					s.RepetitionLevelHistogram[1]++
+				}
+				s.DefinitionLevelHistogram[1]++
+			}
+		})
+
+		if err := w.Write(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowGroup := f.RowGroups()[0]
+
+	for path, want := range want {
+		t.Run(path, func(t *testing.T) {
+			chunk, _ := parquet.LookupColumnChunk(rowGroup, path)
+
+			stats, ok := parquet.SizeStatisticsOf(chunk)
+			if path == "id" {
+				if ok {
+					t.Errorf("size statistics reported for a required INT64 column: %+v", stats)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("no size statistics")
+			}
+			if !reflect.DeepEqual(stats, want) {
+				t.Errorf("wrong size statistics:\nwant = %+v\ngot  = %+v", want, stats)
+			}
+
+			pages, ok := parquet.PageSizeStatisticsOf(chunk)
+			if !ok {
+				t.Fatal("no page size statistics")
+			}
+			if len(pages) < 2 {
+				t.Fatalf("the column chunk was written in %d pages", len(pages))
+			}
+
+			sum := parquet.SizeStatistics{}
+			for _, page := range pages {
+				sum.UnencodedByteArrayDataBytes += page.UnencodedByteArrayDataBytes
+				sum.RepetitionLevelHistogram = addHistogram(sum.RepetitionLevelHistogram, page.RepetitionLevelHistogram)
+				sum.DefinitionLevelHistogram = addHistogram(sum.DefinitionLevelHistogram, page.DefinitionLevelHistogram)
+			}
+			if !reflect.DeepEqual(sum, want) {
+				t.Errorf("wrong sum of page size statistics:\nwant = %+v\ngot  = %+v", want, sum)
+			}
+		})
+	}
+}
+
+func addHistogram(sum, histogram []int64) []int64 {
+	if histogram == nil {
+		return sum
+	}
+	if sum == nil {
+		sum = make([]int64, len(histogram))
+	}
+	for i, count := range histogram {
+		sum[i] += count
+	}
+	return sum
+}
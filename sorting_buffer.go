@@ -0,0 +1,203 @@
+package parquet
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// SortingBuffer is a variant of Buffer which sorts rows that may not fit in
+// memory.
+//
+// Rows are accumulated in an in-memory Buffer until its size exceeds a
+// threshold, at which point the buffer is sorted and written as a parquet file
+// to temporary storage, forming a sorted run, and the buffer is reset. At read
+// time, the RowGroup method returns a view merging the sorted runs with the
+// rows that remained in memory.
+//
+// SortingBuffer values are not safe to use concurrently from multiple
+// goroutines.
+type SortingBuffer struct {
+	buffer  *Buffer
+	pool    PageBufferPool
+	maxSize int64
+	runs    []sortingRun
+	numRows int64
+}
+
+type sortingRun struct {
+	buffer io.ReadWriter
+	file   *File
+}
+
+// NewSortingBuffer constructs a sorting buffer which spills sorted runs to
+// temporary storage acquired from pool when the size of the rows held in
+// memory exceeds maxBufferSize bytes. If pool is nil, the runs are written to
+// temporary files in the default directory returned by os.TempDir.
+//
+// The pool must return buffers which are either of type *bytes.Buffer or
+// implement io.ReaderAt; the pools returned by NewPageBufferPool and
+// NewFileBufferPool satisfy this requirement.
+//
+// The options are passed to the underlying Buffer, the rows are ordered by the
+// sorting columns that they configure. The function panics if the buffer
+// configuration is invalid, see NewBuffer for details.
+func NewSortingBuffer(maxBufferSize int64, pool PageBufferPool, options ...RowGroupOption) *SortingBuffer {
+	if pool == nil {
+		pool = NewFileBufferPool(os.TempDir(), "parquet-sort-*")
+	}
+	return &SortingBuffer{
+		buffer:  NewBuffer(options...),
+		pool:    pool,
+		maxSize: maxBufferSize,
+	}
+}
+
+// Schema returns the schema of the buffer, which may be nil if it was not
+// configured and no rows were written yet.
+func (b *SortingBuffer) Schema() *Schema { return b.buffer.Schema() }
+
+// SortingColumns returns the list of columns that rows are ordered by.
+func (b *SortingBuffer) SortingColumns() []SortingColumn { return b.buffer.SortingColumns() }
+
+// NumRows returns the total number of rows written to the buffer, including
+// rows that were spilled to temporary storage.
+func (b *SortingBuffer) NumRows() int64 { return b.numRows + b.buffer.NumRows() }
+
+// NumRuns returns the number of sorted runs spilled to temporary storage.
+func (b *SortingBuffer) NumRuns() int { return len(b.runs) }
+
+// Write writes a row held in a Go value to the buffer.
+func (b *SortingBuffer) Write(row interface{}) error {
+	if err := b.buffer.Write(row); err != nil {
+		return err
+	}
+	return b.spillIfFull()
+}
+
+// WriteRows writes parquet rows to the buffer.
+func (b *SortingBuffer) WriteRows(rows []Row) (int, error) {
+	n, err := b.buffer.WriteRows(rows)
+	if err != nil {
+		return n, err
+	}
+	return n, b.spillIfFull()
+}
+
+// RowGroup returns a row group merging the sorted runs spilled to temporary
+// storage with the rows held in memory, which are sorted by the call.
+//
+// The returned row group shares memory with the buffer, it must not be used
+// after writing more rows to the buffer, or calling Reset or Close.
+func (b *SortingBuffer) RowGroup() (RowGroup, error) {
+	sort.Stable(b.buffer)
+	if len(b.runs) == 0 {
+		return b.buffer, nil
+	}
+
+	rowGroups := make([]RowGroup, 0, len(b.runs)+1)
+	for _, run := range b.runs {
+		for _, rowGroup := range run.file.RowGroups() {
+			rowGroups = append(rowGroups, sortingRunRowGroup{rowGroup, b.buffer.SortingColumns()})
+		}
+	}
+	if b.buffer.NumRows() > 0 {
+		rowGroups = append(rowGroups, b.buffer)
+	}
+	return MergeRowGroups(rowGroups,
+		b.buffer.Schema(),
+		SortingColumns(b.buffer.SortingColumns()...),
+		DropDuplicateRows(b.buffer.config.DuplicateRows),
+	)
+}
+
+// Reset clears the content of the buffer and releases the sorted runs to the
+// pool, allowing the buffer to be reused.
+func (b *SortingBuffer) Reset() {
+	b.release()
+	b.buffer.Reset()
+}
+
+// Close releases the sorted runs to the pool. The buffer must not be used after
+// calling Close.
+func (b *SortingBuffer) Close() error {
+	b.release()
+	return nil
+}
+
+func (b *SortingBuffer) release() {
+	for i, run := range b.runs {
+		b.pool.PutPageBuffer(run.buffer)
+		b.runs[i] = sortingRun{}
+	}
+	b.runs = b.runs[:0]
+	b.numRows = 0
+}
+
+func (b *SortingBuffer) spillIfFull() error {
+	if b.maxSize <= 0 || b.buffer.Size() < b.maxSize {
+		return nil
+	}
+	return b.spill()
+}
+
+// spill sorts the rows held in memory and writes them to a new run.
+func (b *SortingBuffer) spill() error {
+	sort.Stable(b.buffer)
+
+	buffer := b.pool.GetPageBuffer()
+	output := &offsetTrackingWriter{writer: buffer}
+	writer := NewWriter(output, b.buffer.Schema())
+
+	file, err := func() (*File, error) {
+		if _, err := writer.WriteRowGroup(b.buffer); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		r, err := pageBufferReaderAt(buffer)
+		if err != nil {
+			return nil, err
+		}
+		return OpenFile(r, output.offset)
+	}()
+	if err != nil {
+		b.pool.PutPageBuffer(buffer)
+		return fmt.Errorf("spilling sorted run to temporary storage: %w", err)
+	}
+
+	b.runs = append(b.runs, sortingRun{buffer: buffer, file: file})
+	b.numRows += b.buffer.NumRows()
+	b.buffer.Reset()
+	return nil
+}
+
+// sortingRunRowGroup exposes the row groups of sorted runs with the sorting
+// columns of the buffer, which the metadata of the run files may not fully
+// record (e.g. sorting keys computed from the rows).
+type sortingRunRowGroup struct {
+	RowGroup
+	sorting []SortingColumn
+}
+
+func (g sortingRunRowGroup) SortingColumns() []SortingColumn { return g.sorting }
+
+// pageBufferReaderAt returns an io.ReaderAt reading the content written to a
+// buffer acquired from a PageBufferPool.
+func pageBufferReaderAt(buffer io.ReadWriter) (io.ReaderAt, error) {
+	switch b := buffer.(type) {
+	case *bytes.Buffer:
+		return bytes.NewReader(b.Bytes()), nil
+	case io.ReaderAt:
+		return b, nil
+	default:
+		return nil, fmt.Errorf("page buffer of type %T does not support random access reads", buffer)
+	}
+}
+
+var (
+	_ RowWriterWithSchema = (*SortingBuffer)(nil)
+)
@@ -0,0 +1,220 @@
+package parquet_test
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSortingBuffer(t *testing.T) {
+	type Row struct {
+		Key   int64  `parquet:"key"`
+		Value string `parquet:"value"`
+		Index int    `parquet:"index"`
+	}
+
+	tests := []struct {
+		scenario string
+		pool     func(t *testing.T) parquet.PageBufferPool
+	}{
+		{
+			scenario: "in-memory runs",
+			pool:     func(*testing.T) parquet.PageBufferPool { return parquet.NewPageBufferPool() },
+		},
+		{
+			scenario: "on-disk runs",
+			pool: func(t *testing.T) parquet.PageBufferPool {
+				return parquet.NewFileBufferPool(t.TempDir(), "sorting-buffer-*")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			prng := rand.New(rand.NewSource(0))
+			rows := make([]Row, 5000)
+			for i := range rows {
+				rows[i] = Row{
+					Key:   prng.Int63n(100),
+					Value: string(rune('a' + prng.Intn(26))),
+					Index: i,
+				}
+			}
+
+			pool := test.pool(t)
+			buffer := parquet.NewSortingBuffer(16*1024, pool,
+				parquet.SchemaOf(Row{}),
+				parquet.SortingColumns(
+					parquet.Ascending("key"),
+					parquet.Descending("value"),
+				),
+			)
+			defer buffer.Close()
+
+			for i := range rows {
+				if err := buffer.Write(&rows[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if buffer.NumRuns() < 2 {
+				t.Fatalf("expected rows to be spilled in multiple runs, got %d", buffer.NumRuns())
+			}
+			if n := buffer.NumRows(); n != int64(len(rows)) {
+				t.Fatalf("wrong number of rows: want=%d got=%d", len(rows), n)
+			}
+
+			sort.SliceStable(rows, func(i, j int) bool {
+				if rows[i].Key != rows[j].Key {
+					return rows[i].Key < rows[j].Key
+				}
+				return rows[i].Value > rows[j].Value
+			})
+
+			rowGroup, err := buffer.RowGroup()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := rowGroup.NumRows(); n != int64(len(rows)) {
+				t.Fatalf("wrong number of rows in row group: want=%d got=%d", len(rows), n)
+			}
+
+			reader := rowGroup.Rows()
+			defer reader.Close()
+
+			schema := rowGroup.Schema()
+			rowbuf := make([]parquet.Row, 100)
+			index := 0
+
+			for {
+				n, err := reader.ReadRows(rowbuf)
+				for _, row := range rowbuf[:n] {
+					var got Row
+					if err := schema.Reconstruct(&got, row); err != nil {
+						t.Fatal(err)
+					}
+					if got != rows[index] {
+						t.Fatalf("wrong row at index %d: want=%+v got=%+v", index, rows[index], got)
+					}
+					index++
+				}
+				if err != nil {
+					if err != io.EOF {
+						t.Fatal(err)
+					}
+					break
+				}
+			}
+			if index != len(rows) {
+				t.Fatalf("wrong number of rows read: want=%d got=%d", len(rows), index)
+			}
+
+			buffer.Reset()
+			if buffer.NumRows() != 0 || buffer.NumRuns() != 0 {
+				t.Errorf("buffer not empty after reset: rows=%d runs=%d", buffer.NumRows(), buffer.NumRuns())
+			}
+		})
+	}
+}
+
+func TestSortingBufferRemovesTemporaryFiles(t *testing.T) {
+	type Row struct {
+		Key int64 `parquet:"key"`
+	}
+
+	tmpdir := t.TempDir()
+	buffer := parquet.NewSortingBuffer(1024,
+		parquet.NewFileBufferPool(tmpdir, "sorting-buffer-*"),
+		parquet.SortingColumns(parquet.Ascending("key")),
+	)
+
+	for i := 0; i < 1000; i++ {
+		if err := buffer.Write(Row{Key: int64(1000 - i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || len(entries) != buffer.NumRuns() {
+		t.Fatalf("expected one temporary file per run, got %d files for %d runs", len(entries), buffer.NumRuns())
+	}
+
+	if err := buffer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ = os.ReadDir(tmpdir); len(entries) != 0 {
+		t.Errorf("%d temporary files were not removed", len(entries))
+	}
+}
+
+func TestSortingBufferDropDuplicateRows(t *testing.T) {
+	type Row struct {
+		Key   int64 `parquet:"key"`
+		Index int   `parquet:"index"`
+	}
+
+	buffer := parquet.NewSortingBuffer(1024, parquet.NewPageBufferPool(),
+		parquet.SchemaOf(Row{}),
+		parquet.SortingColumns(parquet.Ascending("key")),
+		parquet.DropDuplicateRows(parquet.KeepLastRow),
+	)
+	defer buffer.Close()
+
+	const numKeys = 100
+	prng := rand.New(rand.NewSource(0))
+	last := make(map[int64]int)
+
+	for i := 0; i < 5000; i++ {
+		row := Row{Key: prng.Int63n(numKeys), Index: i}
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+		last[row.Key] = i
+	}
+	if buffer.NumRuns() == 0 {
+		t.Fatal("expected the buffer to spill sorted runs")
+	}
+
+	rowGroup, err := buffer.RowGroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := rowGroup.Rows()
+	defer rows.Close()
+
+	schema := parquet.SchemaOf(Row{})
+	buf := make([]parquet.Row, 10)
+	got := []Row{}
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			var r Row
+			if err := schema.Reconstruct(&r, row); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	if len(got) != numKeys {
+		t.Fatalf("wrong number of rows: want=%d got=%d", numKeys, len(got))
+	}
+	for i, row := range got {
+		if row.Key != int64(i) || row.Index != last[row.Key] {
+			t.Fatalf("wrong row at index %d: want=%+v got=%+v", i, Row{Key: int64(i), Index: last[int64(i)]}, row)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package parquet
+
+// AscendingKey constructs a SortingColumn which orders rows by a key computed
+// from each row in ascending order, instead of the values of a column of the
+// schema. This allows ordering rows by derived values (e.g. a hash of a column
+// followed by a timestamp) without materializing them in an extra column.
+//
+// The name identifies the sorting key in the list of sorting columns, and is
+// returned as a single element path by the Path method; it should not collide
+// with the path of a column of the schema. The type is used to compare the
+// keys, which must be values of the type's kind, or null values which are
+// ordered last unless the sorting column is wrapped with NullsFirst.
+//
+// The key function is called with rows holding the values of all columns, in
+// the order produced by Deconstruct. The returned value may reference the
+// memory of the row, it is cloned when it needs to be retained.
+//
+// Sorting keys are supported by Buffer, SortingBuffer, MergeRowGroups and the
+// comparators returned by Schema.Comparator. Since keys are not part of the
+// schema, they are not recorded in the sorting columns of parquet files, which
+// only list sorting columns preceding the first sorting key.
+func AscendingKey(name string, typ Type, key func(Row) Value) SortingColumn {
+	return &sortingKey{name: []string{name}, typ: typ, key: key}
+}
+
+// DescendingKey is like AscendingKey but orders the keys in descending order.
+func DescendingKey(name string, typ Type, key func(Row) Value) SortingColumn {
+	return &sortingKey{name: []string{name}, typ: typ, key: key, descending: true}
+}
+
+type sortingKey struct {
+	name       []string
+	typ        Type
+	key        func(Row) Value
+	descending bool
+}
+
+func (k *sortingKey) String() string {
+	if k.descending {
+		return "descending_key(" + k.name[0] + ")"
+	}
+	return "ascending_key(" + k.name[0] + ")"
+}
+
+func (k *sortingKey) Path() []string   { return k.name }
+func (k *sortingKey) Descending() bool { return k.descending }
+func (k *sortingKey) NullsFirst() bool { return false }
+
+// sortingKeyOf returns the sorting key of the given sorting column, or nil if
+// it sorts the values of a column.
+func sortingKeyOf(sortingColumn SortingColumn) *sortingKey {
+	switch c := sortingColumn.(type) {
+	case *sortingKey:
+		return c
+	case nullsFirst:
+		return sortingKeyOf(c.SortingColumn)
+	default:
+		return nil
+	}
+}
+
+// compareSortingKeyFuncOf returns a function comparing the keys of the given
+// sorting column, which must be a sorting key.
+func compareSortingKeyFuncOf(sortingColumn SortingColumn) func(a, b Value) int {
+	return compareValueFuncOf(sortingKeyOf(sortingColumn).typ, &SortConfig{
+		// Keys may be null, which requires comparing them like the values of
+		// an optional column.
+		MaxDefinitionLevel: 1,
+		Descending:         sortingColumn.Descending(),
+		NullsFirst:         sortingColumn.NullsFirst(),
+	})
+}
+
+// sortingKeyBuffer holds the keys computed from the rows of a Buffer, in the
+// same order as the rows.
+type sortingKeyBuffer struct {
+	key     func(Row) Value
+	compare func(a, b Value) int
+	values  []Value
+}
+
+func (b *sortingKeyBuffer) Less(i, j int) bool {
+	return b.compare(b.values[i], b.values[j]) < 0
+}
+
+func (b *sortingKeyBuffer) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
@@ -0,0 +1,221 @@
+package parquet_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+type sortingKeyRow struct {
+	Tenant string `parquet:"tenant"`
+	Time   int64  `parquet:"time"`
+}
+
+// sortingKeyBucket is the computed key by which rows are sorted in the tests,
+// which does not preserve the order of tenants.
+func sortingKeyBucket(tenant string) int64 {
+	h := int64(0)
+	for _, c := range tenant {
+		h = 31*h + int64(c)
+	}
+	return h % 7
+}
+
+func sortingKeyRows(n int) []sortingKeyRow {
+	prng := rand.New(rand.NewSource(0))
+	rows := make([]sortingKeyRow, n)
+	for i := range rows {
+		rows[i] = sortingKeyRow{
+			Tenant: string(rune('a' + prng.Intn(26))),
+			Time:   prng.Int63n(1000),
+		}
+	}
+	return rows
+}
+
+func sortedSortingKeyRows(rows []sortingKeyRow) []sortingKeyRow {
+	sorted := append([]sortingKeyRow{}, rows...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		b1, b2 := sortingKeyBucket(sorted[i].Tenant), sortingKeyBucket(sorted[j].Tenant)
+		if b1 != b2 {
+			return b1 > b2
+		}
+		return sorted[i].Time < sorted[j].Time
+	})
+	return sorted
+}
+
+func sortingKeyColumns() []parquet.SortingColumn {
+	return []parquet.SortingColumn{
+		parquet.DescendingKey("bucket", parquet.Int64Type, func(row parquet.Row) parquet.Value {
+			return parquet.ValueOf(sortingKeyBucket(row[0].String()))
+		}),
+		parquet.Ascending("time"),
+	}
+}
+
+func readSortingKeyRows(t *testing.T, rows parquet.Rows) []sortingKeyRow {
+	t.Helper()
+	defer rows.Close()
+	buf := make([]parquet.Row, 1)
+	values := []sortingKeyRow{}
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			var v sortingKeyRow
+			if err := rows.Schema().Reconstruct(&v, row); err != nil {
+				t.Fatal(err)
+			}
+			values = append(values, v)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.Fatal(err)
+			}
+			return values
+		}
+	}
+}
+
+func TestSortingKeyBuffer(t *testing.T) {
+	rows := sortingKeyRows(1000)
+	buffer := parquet.NewBuffer(parquet.SortingColumns(sortingKeyColumns()...))
+
+	// Rows are written in two steps to verify that the keys of rows written
+	// after sorting the buffer are computed.
+	for _, row := range rows[:500] {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Stable(buffer)
+	for _, row := range rows[500:] {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Stable(buffer)
+
+	want := sortedSortingKeyRows(rows)
+	if got := readSortingKeyRows(t, buffer.Rows()); !reflect.DeepEqual(got, want) {
+		t.Fatal("rows are not sorted by the computed key")
+	}
+
+	buffer.Reset()
+	for _, row := range rows[:10] {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sort.Stable(buffer)
+	if got := readSortingKeyRows(t, buffer.Rows()); !reflect.DeepEqual(got, sortedSortingKeyRows(rows[:10])) {
+		t.Fatal("rows are not sorted by the computed key after resetting the buffer")
+	}
+}
+
+func TestSortingKeyComparator(t *testing.T) {
+	rows := sortingKeyRows(100)
+	schema := parquet.SchemaOf(sortingKeyRow{})
+	compare := schema.Comparator(sortingKeyColumns()...)
+
+	parquetRows := make([]parquet.Row, len(rows))
+	for i, row := range rows {
+		parquetRows[i] = schema.Deconstruct(nil, row)
+	}
+	sort.SliceStable(parquetRows, func(i, j int) bool {
+		return compare(parquetRows[i], parquetRows[j]) < 0
+	})
+
+	want := sortedSortingKeyRows(rows)
+	for i, row := range parquetRows {
+		var got sortingKeyRow
+		if err := schema.Reconstruct(&got, row); err != nil {
+			t.Fatal(err)
+		}
+		if got != want[i] {
+			t.Fatalf("row %d mismatch: want=%+v got=%+v", i, want[i], got)
+		}
+	}
+}
+
+func TestSortingKeyMergeRowGroups(t *testing.T) {
+	rows := sortingKeyRows(1000)
+	options := parquet.SortingColumns(sortingKeyColumns()...)
+
+	rowGroups := make([]parquet.RowGroup, 3)
+	for i := range rowGroups {
+		buffer := parquet.NewBuffer(options)
+		for j := i; j < len(rows); j += len(rowGroups) {
+			if err := buffer.Write(rows[j]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		sort.Stable(buffer)
+		rowGroups[i] = buffer
+	}
+
+	merged, err := parquet.MergeRowGroups(rowGroups, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := readSortingKeyRows(t, merged.Rows())
+	if len(got) != len(rows) {
+		t.Fatalf("wrong number of rows: want=%d got=%d", len(rows), len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		b1, b2 := sortingKeyBucket(got[i-1].Tenant), sortingKeyBucket(got[i].Tenant)
+		if b1 < b2 || (b1 == b2 && got[i-1].Time > got[i].Time) {
+			t.Fatalf("rows %d and %d are not in order: %+v, %+v", i-1, i, got[i-1], got[i])
+		}
+	}
+
+	// The sorting key is not recorded in the file metadata, which therefore
+	// cannot advertise any sorting column since the key comes first.
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, options)
+	if _, err := w.WriteRowGroup(merged); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sorting := f.RowGroups()[0].SortingColumns(); len(sorting) != 0 {
+		t.Errorf("unexpected sorting columns in the file metadata: %v", sorting)
+	}
+}
+
+func TestSortingKeySortingBuffer(t *testing.T) {
+	rows := sortingKeyRows(2000)
+	buffer := parquet.NewSortingBuffer(1024, parquet.NewPageBufferPool(),
+		parquet.SchemaOf(sortingKeyRow{}),
+		parquet.SortingColumns(sortingKeyColumns()...),
+	)
+	defer buffer.Close()
+
+	for _, row := range rows {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if buffer.NumRuns() == 0 {
+		t.Fatal("no sorted runs were spilled to temporary storage")
+	}
+
+	rowGroup, err := buffer.RowGroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readSortingKeyRows(t, rowGroup.Rows()); !reflect.DeepEqual(got, sortedSortingKeyRows(rows)) {
+		t.Fatal("rows are not sorted by the computed key")
+	}
+}
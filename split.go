@@ -0,0 +1,217 @@
+package parquet
+
+import (
+	"io"
+	"sort"
+)
+
+// SplitRowGroup splits rowGroup into consecutive row groups holding at most
+// maxRows rows, and with an estimated size of at most maxBytes bytes. Limits
+// which are zero or negative are ignored.
+//
+// The returned row groups are views over ranges of rows of rowGroup, no rows
+// are copied until they are read. The ranges are cut at page boundaries when
+// possible, so that the pages of rowGroup which fall within a single range are
+// exposed as-is by the column chunks of the views, which then only need to
+// slice the pages that straddle two ranges. Pages larger than the limits are
+// cut within.
+//
+// The size of row groups is estimated from the offset indexes of the column
+// chunks, which hold the compressed size of pages for row groups read from
+// files, or the size of pages in memory for buffers. The size of each page is
+// assumed to be evenly distributed over its rows.
+//
+// When rowGroup fits within the limits, it is returned as the only element of
+// the result. Like the row groups returned by FilterRowGroup, the column chunks
+// of the views have no column or offset indexes.
+//
+// SplitRowGroup is intended to be used to rebalance parquet files with row
+// groups of sizes off target, for example:
+//
+//	for _, rowGroup := range parquet.SplitRowGroup(f.RowGroups()[0], 0, 128<<20) {
+//		if _, err := writer.WriteRowGroup(rowGroup); err != nil {
+//			...
+//		}
+//	}
+func SplitRowGroup(rowGroup RowGroup, maxRows, maxBytes int64) []RowGroup {
+	numRows := rowGroup.NumRows()
+	if numRows == 0 {
+		return nil
+	}
+
+	pages := splitPagesOf(rowGroup)
+	fits := func(begin, end int64) bool {
+		return (maxRows <= 0 || end-begin <= maxRows) && (maxBytes <= 0 || pages.size(begin, end) <= maxBytes)
+	}
+
+	ranges := []RowRange{}
+	cut := func(begin, end int64) {
+		ranges = append(ranges, RowRange{FirstRowIndex: begin, NumRows: end - begin})
+	}
+
+	// Ranges are extended to the next page boundary while they fit within the
+	// limits. When the rows up to the next page boundary do not fit in a new
+	// range, the range is cut within the pages.
+	begin, last := int64(0), int64(0)
+	for i := 0; i < len(pages.boundaries); {
+		end := pages.boundaries[i]
+		switch {
+		case fits(begin, end):
+			last = end
+			i++
+		case last > begin:
+			cut(begin, last)
+			begin = last
+		default:
+			end = begin + pages.cutWithin(begin, end, maxRows, maxBytes)
+			cut(begin, end)
+			begin, last = end, end
+		}
+	}
+	if begin < numRows {
+		cut(begin, numRows)
+	}
+
+	if len(ranges) == 1 {
+		return []RowGroup{rowGroup}
+	}
+
+	rowGroups := make([]RowGroup, len(ranges))
+	for i, r := range ranges {
+		g := newFilteredRowGroup(rowGroup, nil)
+		g.ranges = ranges[i : i+1 : i+1]
+		g.numRows = r.NumRows
+		rowGroups[i] = g
+	}
+	return rowGroups
+}
+
+// splitPages holds the page boundaries and sizes of the column chunks of a row
+// group, which are used to determine where to split it.
+type splitPages struct {
+	// Sorted row indexes where pages of at least one column end, the last one
+	// being the number of rows in the row group.
+	boundaries []int64
+	columns    [][]splitPage
+}
+
+type splitPage struct {
+	firstRowIndex int64
+	numRows       int64
+	size          int64
+}
+
+func splitPagesOf(rowGroup RowGroup) *splitPages {
+	numRows := rowGroup.NumRows()
+	pages := &splitPages{}
+	boundaries := map[int64]struct{}{numRows: {}}
+
+	for _, chunk := range rowGroup.ColumnChunks() {
+		var column []splitPage
+		if offsetIndex := chunk.OffsetIndex(); offsetIndex != nil {
+			numPages := offsetIndex.NumPages()
+			for i := 0; i < numPages; i++ {
+				firstRowIndex, lastRowIndex := offsetIndex.FirstRowIndex(i), numRows
+				if i+1 < numPages {
+					lastRowIndex = offsetIndex.FirstRowIndex(i + 1)
+				}
+				if lastRowIndex > firstRowIndex {
+					column = append(column, splitPage{
+						firstRowIndex: firstRowIndex,
+						numRows:       lastRowIndex - firstRowIndex,
+						size:          offsetIndex.CompressedPageSize(i),
+					})
+					boundaries[lastRowIndex] = struct{}{}
+				}
+			}
+		}
+		pages.columns = append(pages.columns, column)
+	}
+
+	pages.boundaries = make([]int64, 0, len(boundaries))
+	for rowIndex := range boundaries {
+		if rowIndex > 0 && rowIndex <= numRows {
+			pages.boundaries = append(pages.boundaries, rowIndex)
+		}
+	}
+	sort.Slice(pages.boundaries, func(i, j int) bool { return pages.boundaries[i] < pages.boundaries[j] })
+	return pages
+}
+
+// size returns the estimated size of the rows in [begin:end).
+func (p *splitPages) size(begin, end int64) (size int64) {
+	for _, column := range p.columns {
+		for _, page := range column {
+			pageBegin, pageEnd := page.firstRowIndex, page.firstRowIndex+page.numRows
+			if pageBegin < begin {
+				pageBegin = begin
+			}
+			if pageEnd > end {
+				pageEnd = end
+			}
+			if pageBegin < pageEnd {
+				size += page.size * (pageEnd - pageBegin) / page.numRows
+			}
+		}
+	}
+	return size
+}
+
+// cutWithin returns the number of rows from begin to cut a range at, when the
+// rows in [begin:end) exceed the limits.
+func (p *splitPages) cutWithin(begin, end, maxRows, maxBytes int64) int64 {
+	numRows := end - begin
+	if maxRows > 0 && numRows > maxRows {
+		numRows = maxRows
+	}
+	if maxBytes > 0 {
+		if size := p.size(begin, begin+numRows); size > maxBytes {
+			numRows = numRows * maxBytes / size
+		}
+	}
+	if numRows < 1 {
+		numRows = 1
+	}
+	return numRows
+}
+
+// slicedRows reads the rows of a range of a row group. Row indexes passed to
+// SeekToRow are relative to the first row of the range.
+type slicedRows struct {
+	rows     Rows
+	rowRange RowRange
+	index    int64
+	seeked   bool
+}
+
+func (r *slicedRows) ReadRows(rows []Row) (int, error) {
+	if !r.seeked {
+		if err := r.rows.SeekToRow(r.rowRange.FirstRowIndex + r.index); err != nil {
+			return 0, err
+		}
+		r.seeked = true
+	}
+	remain := r.rowRange.NumRows - r.index
+	if remain <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(rows)) > remain {
+		rows = rows[:remain]
+	}
+	n, err := r.rows.ReadRows(rows)
+	r.index += int64(n)
+	if r.index == r.rowRange.NumRows {
+		err = io.EOF
+	} else if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (r *slicedRows) SeekToRow(rowIndex int64) error {
+	r.index, r.seeked = rowIndex, false
+	return nil
+}
+
+func (r *slicedRows) Close() error    { return r.rows.Close() }
+func (r *slicedRows) Schema() *Schema { return r.rows.Schema() }
@@ -0,0 +1,124 @@
+package parquet_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestSplitRowGroup(t *testing.T) {
+	rows := makeFilterRows(1000)
+	f := filterRowFile(t, rows)
+	rowGroup := f.RowGroups()[0]
+
+	tests := []struct {
+		scenario string
+		maxRows  int64
+		maxBytes int64
+	}{
+		{scenario: "rows", maxRows: 300},
+		{scenario: "bytes", maxBytes: 2000},
+		{scenario: "rows and bytes", maxRows: 100, maxBytes: 5000},
+		{scenario: "single row", maxRows: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			splits := parquet.SplitRowGroup(rowGroup, test.maxRows, test.maxBytes)
+			if len(splits) < 2 {
+				t.Fatalf("the row group was not split: %d row groups", len(splits))
+			}
+
+			got := []filterRow{}
+			for i, split := range splits {
+				if test.maxRows > 0 && split.NumRows() > test.maxRows {
+					t.Errorf("row group %d has too many rows: %d > %d", i, split.NumRows(), test.maxRows)
+				}
+				r := split.Rows()
+				splitRows := readFilterRows(t, r)
+				r.Close()
+				if int64(len(splitRows)) != split.NumRows() {
+					t.Errorf("row group %d: wrong number of rows: want=%d got=%d", i, split.NumRows(), len(splitRows))
+				}
+				got = append(got, splitRows...)
+			}
+			if !reflect.DeepEqual(got, rows) {
+				t.Fatalf("rows mismatch: want=%d rows got=%d rows", len(rows), len(got))
+			}
+
+			// Writing the row groups reads the rows from their column chunks.
+			b := new(bytes.Buffer)
+			w := parquet.NewWriter(b, parquet.SchemaOf(filterRow{}))
+			for _, split := range splits {
+				if _, err := w.WriteRowGroup(split); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			r := parquet.NewReader(bytes.NewReader(b.Bytes()))
+			defer r.Close()
+			if got := readFilterRows(t, r); !reflect.DeepEqual(got, rows) {
+				t.Fatalf("rows mismatch after writing the row groups: want=%d rows got=%d rows", len(rows), len(got))
+			}
+		})
+	}
+
+	t.Run("seek", func(t *testing.T) {
+		splits := parquet.SplitRowGroup(rowGroup, 300, 0)
+		split := splits[1]
+		firstID := rows[0].ID + splits[0].NumRows()
+		r := split.Rows()
+		defer r.Close()
+		buf := make([]parquet.Row, 1)
+		for _, rowIndex := range []int64{10, 0, split.NumRows() - 1} {
+			if err := r.SeekToRow(rowIndex); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.ReadRows(buf); err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if id := buf[0][0].Int64(); id != firstID+rowIndex {
+				t.Errorf("wrong row at index %d: want=%d got=%d", rowIndex, firstID+rowIndex, id)
+			}
+		}
+	})
+
+	t.Run("fits", func(t *testing.T) {
+		splits := parquet.SplitRowGroup(rowGroup, 1000, 0)
+		if len(splits) != 1 || splits[0] != rowGroup {
+			t.Errorf("row group fitting within the limits was split: %d row groups", len(splits))
+		}
+	})
+}
+
+func TestSplitBuffer(t *testing.T) {
+	rows := makeFilterRows(100)
+	buffer := parquet.NewBuffer()
+	for _, row := range rows {
+		if err := buffer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Buffers have a single page per column, they are cut within the pages.
+	// The cuts are rounded down to whole rows, which may leave the remaining
+	// rows in an extra row group.
+	splits := parquet.SplitRowGroup(buffer, 0, buffer.Size()/4)
+	if len(splits) < 3 {
+		t.Fatalf("the buffer was not split in at least 3 row groups: %d row groups", len(splits))
+	}
+	got := []filterRow{}
+	for _, split := range splits {
+		r := split.Rows()
+		got = append(got, readFilterRows(t, r)...)
+		r.Close()
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("rows mismatch: want=%d rows got=%d rows", len(rows), len(got))
+	}
+}
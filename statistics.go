@@ -0,0 +1,301 @@
+package parquet
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/segmentio/parquet-go/format"
+)
+
+// ColumnChunkStatistics carries the statistics of a column chunk.
+type ColumnChunkStatistics struct {
+	// Type of the column, which determines how the bounds are converted to Go
+	// values by the Min and Max methods.
+	Type Type
+	// Bounds of the values of the column chunk, which are null if the column
+	// chunk holds null values only, or if its statistics are unknown. Bounds
+	// of byte arrays read from the column index may be truncated.
+	MinValue Value
+	MaxValue Value
+	// Whether the bounds are the exact min and max values of the column chunk.
+	// When false, MinValue is a lower bound and MaxValue is a prefix of the
+	// max value which were truncated by the writer, or may have been in files
+	// which did not record whether the bounds were exact.
+	MinValueExact bool
+	MaxValueExact bool
+	// Number of null values, and number of values including nulls, in the
+	// column chunk.
+	NullCount int64
+	NumValues int64
+}
+
+// StatisticsOf returns the statistics of the column chunk.
+//
+// The statistics are read from the metadata of column chunks of parquet files
+// when they were recorded by the writer, and computed from the column index of
+// the chunk otherwise, which does not require reading its pages.
+func StatisticsOf(chunk ColumnChunk) ColumnChunkStatistics {
+	stats := ColumnChunkStatistics{
+		Type:      chunk.Type(),
+		NumValues: chunk.NumValues(),
+	}
+
+	if c, _ := chunk.(*fileColumnChunk); c != nil {
+		if min, max, nullCount, ok := fileColumnChunkStatistics(c); ok {
+			stats.MinValue, stats.MaxValue, stats.NullCount = min, max, nullCount
+			stats.MinValueExact = isExactBound(stats.Type.Kind(), c.chunk.MetaData.Statistics.IsMinValueExact)
+			stats.MaxValueExact = isExactBound(stats.Type.Kind(), c.chunk.MetaData.Statistics.IsMaxValueExact)
+			return stats
+		}
+	}
+
+	columnIndex := chunk.ColumnIndex()
+	if columnIndex == nil {
+		return stats
+	}
+
+	// The column index does not record whether the bounds were truncated.
+	stats.MinValueExact = isExactBound(stats.Type.Kind(), nil)
+	stats.MaxValueExact = stats.MinValueExact
+
+	for i, n := 0, columnIndex.NumPages(); i < n; i++ {
+		stats.NullCount += columnIndex.NullCount(i)
+		if columnIndex.NullPage(i) {
+			continue
+		}
+		if min := columnIndex.MinValue(i); stats.MinValue.IsNull() || stats.Type.Compare(min, stats.MinValue) < 0 {
+			stats.MinValue = min
+		}
+		if max := columnIndex.MaxValue(i); stats.MaxValue.IsNull() || stats.Type.Compare(max, stats.MaxValue) > 0 {
+			stats.MaxValue = max
+		}
+	}
+
+	return stats
+}
+
+// fileColumnChunkStatistics returns the statistics recorded in the metadata of
+// the column chunk. The deprecated min and max fields are only used for types
+// where their signed ordering matches the ordering of the type.
+func fileColumnChunkStatistics(c *fileColumnChunk) (min, max Value, nullCount int64, ok bool) {
+	stats := &c.chunk.MetaData.Statistics
+	typ := c.column.Type()
+	kind := typ.Kind()
+
+	minValue, maxValue := stats.MinValue, stats.MaxValue
+	if minValue == nil && maxValue == nil {
+		switch kind {
+		case Boolean, Int32, Int64, Float, Double:
+			if lt := typ.LogicalType(); lt == nil || lt.Integer == nil || lt.Integer.IsSigned {
+				minValue, maxValue = stats.Min, stats.Max
+			}
+		}
+	}
+
+	if minValue == nil && maxValue == nil {
+		// All the values are null, the bounds are unknown but the statistics
+		// are still valid.
+		if stats.NullCount > 0 && stats.NullCount == c.chunk.MetaData.NumValues {
+			return min, max, stats.NullCount, true
+		}
+		return min, max, 0, false
+	}
+
+	if minValue != nil {
+		min = kind.Value(minValue)
+	}
+	if maxValue != nil {
+		max = kind.Value(maxValue)
+	}
+	return min, max, stats.NullCount, true
+}
+
+// ColumnStatistics carries the statistics of a column aggregated across the
+// row groups of a file.
+type ColumnStatistics struct {
+	// Path of the column in the schema.
+	Path []string
+	// Statistics aggregated from the column chunks of all row groups. The
+	// bounds are exact only if the bounds they were selected from were exact.
+	ColumnChunkStatistics
+	// Number of rows of the row groups holding the column chunks.
+	NumRows int64
+	// Whether the statistics of some column chunks were unknown, in which case
+	// the bounds are null and the null count only covers the column chunks
+	// with known statistics.
+	Incomplete bool
+}
+
+// Statistics returns the statistics of each leaf column of the file, in the
+// order of the columns of its schema, aggregated from the statistics of the
+// column chunks of all row groups as returned by StatisticsOf.
+//
+// The bounds of the columns are compared according to their logical types
+// (e.g. unsigned integers or strings), and the byte array bounds which were
+// truncated by the writer remain valid bounds of the aggregated values.
+func (f *File) Statistics() []ColumnStatistics {
+	columns := f.schema.Columns()
+	stats := make([]ColumnStatistics, len(columns))
+
+	for i, path := range columns {
+		leaf, _ := f.schema.Lookup(path...)
+		stats[i] = ColumnStatistics{
+			Path: path,
+			ColumnChunkStatistics: ColumnChunkStatistics{
+				Type: leaf.Node.Type(),
+			},
+		}
+	}
+
+	for _, rowGroup := range f.RowGroups() {
+		numRows := rowGroup.NumRows()
+		for i, chunk := range rowGroup.ColumnChunks() {
+			stats[i].NumRows += numRows
+			stats[i].merge(StatisticsOf(chunk))
+		}
+	}
+
+	return stats
+}
+
+func (s *ColumnStatistics) merge(chunk ColumnChunkStatistics) {
+	s.NumValues += chunk.NumValues
+	s.NullCount += chunk.NullCount
+
+	if chunk.MinValue.IsNull() || chunk.MaxValue.IsNull() {
+		// Column chunks holding only null values have no bounds, otherwise the
+		// statistics of the column chunk are unknown.
+		if chunk.NullCount < chunk.NumValues {
+			s.Incomplete = true
+			s.MinValue, s.MaxValue = Value{}, Value{}
+			s.MinValueExact, s.MaxValueExact = false, false
+		}
+		return
+	}
+	if s.Incomplete {
+		return
+	}
+
+	if s.MinValue.IsNull() || lowerBoundLess(s.Type, chunk.MinValue, chunk.MinValueExact, s.MinValue, s.MinValueExact) {
+		s.MinValue, s.MinValueExact = chunk.MinValue.Clone(), chunk.MinValueExact
+	}
+	if s.MaxValue.IsNull() || !upperBoundCovers(s.Type, s.MaxValue, s.MaxValueExact, chunk.MaxValue, chunk.MaxValueExact) {
+		s.MaxValue, s.MaxValueExact = chunk.MaxValue.Clone(), chunk.MaxValueExact
+	}
+}
+
+// lowerBoundLess returns whether the lower bound a is lower than the lower
+// bound b. Truncated lower bounds are prefixes of the min values, which makes
+// them valid lower bounds when compared with the type; on ties, exact bounds
+// are lower since they are the min values.
+func lowerBoundLess(typ Type, a Value, aExact bool, b Value, bExact bool) bool {
+	cmp := typ.Compare(a, b)
+	return cmp < 0 || (cmp == 0 && aExact && !bExact)
+}
+
+// upperBoundCovers returns whether the values bounded by the upper bound value
+// are also bounded by max. Truncated upper bounds are prefixes of the max
+// values, they bound all the values starting with the prefix.
+func upperBoundCovers(typ Type, max Value, maxExact bool, value Value, valueExact bool) bool {
+	switch kind := typ.Kind(); {
+	case kind != ByteArray && kind != FixedLenByteArray, maxExact && valueExact:
+		return typ.Compare(value, max) <= 0
+	}
+	v, m := value.ByteArray(), max.ByteArray()
+	n := len(v)
+	if len(m) < n {
+		n = len(m)
+	}
+	if cmp := bytes.Compare(v[:n], m[:n]); cmp != 0 {
+		return cmp < 0
+	}
+	// One of the bounds is a prefix of the other. Exact values are covered by
+	// prefixes, while prefixes are only covered by shorter prefixes.
+	return valueExact || (!maxExact && len(v) >= len(m))
+}
+
+// isExactBound returns whether a bound of statistics of the given kind is exact
+// according to the flag recorded in the statistics. When the flag is unset, the
+// bounds of byte arrays may have been truncated and are not considered exact.
+func isExactBound(kind Kind, exact *bool) bool {
+	if exact != nil {
+		return *exact
+	}
+	return kind != ByteArray && kind != FixedLenByteArray
+}
+
+// Min returns the lower bound of the values of the column chunk as a Go value,
+// or nil if the bound is unknown. See goValueOf for the types of values.
+func (s *ColumnChunkStatistics) Min() interface{} { return goValueOf(s.Type, s.MinValue) }
+
+// Max returns the upper bound of the values of the column chunk as a Go value,
+// or nil if the bound is unknown. See goValueOf for the types of values.
+func (s *ColumnChunkStatistics) Max() interface{} { return goValueOf(s.Type, s.MaxValue) }
+
+// goValueOf converts v to a Go value determined by the logical type of typ:
+//
+//   - TIMESTAMP and DATE values are converted to time.Time, in UTC
+//   - TIME values are converted to time.Duration since midnight
+//   - STRING, ENUM, and JSON values are converted to string
+//   - unsigned INT values are converted to uint64
+//
+// Values of other types are converted according to their kind: bool, int64 for
+// INT32 and INT64, float64 for FLOAT and DOUBLE, deprecated.Int96, and []byte
+// for byte arrays. Null values are converted to nil.
+func goValueOf(typ Type, v Value) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.Timestamp != nil:
+			return (*timestampType)(lt.Timestamp).unixTime(v.Int64())
+		case lt.Date != nil:
+			return time.Unix(int64(v.Int32())*secondsPerDay, 0).UTC()
+		case lt.Time != nil:
+			unit := timeUnitDuration(&lt.Time.Unit)
+			if v.Kind() == Int32 {
+				return time.Duration(v.Int32()) * unit
+			}
+			return time.Duration(v.Int64()) * unit
+		case lt.UTF8 != nil, lt.Enum != nil, lt.Json != nil:
+			return string(v.ByteArray())
+		case lt.Integer != nil && !lt.Integer.IsSigned:
+			if v.Kind() == Int32 {
+				return uint64(v.Uint32())
+			}
+			return v.Uint64()
+		}
+	}
+
+	switch v.Kind() {
+	case Boolean:
+		return v.Boolean()
+	case Int32:
+		return int64(v.Int32())
+	case Int64:
+		return v.Int64()
+	case Int96:
+		return v.Int96()
+	case Float:
+		return float64(v.Float())
+	case Double:
+		return v.Double()
+	default:
+		return copyBytes(v.ByteArray())
+	}
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+func timeUnitDuration(unit *format.TimeUnit) time.Duration {
+	switch {
+	case unit.Millis != nil:
+		return time.Millisecond
+	case unit.Micros != nil:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
@@ -0,0 +1,210 @@
+package parquet_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestStatisticsOf(t *testing.T) {
+	type Row struct {
+		ID    int64     `parquet:"id"`
+		Score float64   `parquet:"score"`
+		Name  string    `parquet:"name"`
+		Time  time.Time `parquet:"time,timestamp(millisecond)"`
+		Count uint32    `parquet:"count"`
+		Note  *string   `parquet:"note,optional"`
+		Blob  []byte    `parquet:"blob"`
+	}
+
+	base := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.PageBufferSize(256))
+	for i := 0; i < 100; i++ {
+		if err := w.Write(&Row{
+			ID:    int64(i) - 50,
+			Score: float64(i) / 4,
+			Name:  string(rune('a' + i%26)),
+			Time:  base.Add(time.Duration(i) * time.Minute),
+			Count: uint32(i) + 1<<31,
+			Blob:  []byte{byte(i)},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		path      string
+		min, max  interface{}
+		nullCount int64
+	}{
+		{"id", int64(-50), int64(49), 0},
+		{"score", float64(0), float64(99) / 4, 0},
+		{"name", "a", "z", 0},
+		{"time", base, base.Add(99 * time.Minute), 0},
+		{"count", uint64(1 << 31), uint64(99 + 1<<31), 0},
+		{"note", nil, nil, 100},
+		{"blob", []byte{0}, []byte{99}, 0},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			chunk, _ := parquet.LookupColumnChunk(f.RowGroups()[0], test.path)
+			stats := parquet.StatisticsOf(chunk)
+
+			if stats.NumValues != 100 {
+				t.Errorf("wrong number of values: %d", stats.NumValues)
+			}
+			if stats.NullCount != test.nullCount {
+				t.Errorf("wrong null count: want=%d got=%d", test.nullCount, stats.NullCount)
+			}
+			if min := stats.Min(); !equalGoValues(min, test.min) {
+				t.Errorf("wrong min: want=%#v got=%#v", test.min, min)
+			}
+			if max := stats.Max(); !equalGoValues(max, test.max) {
+				t.Errorf("wrong max: want=%#v got=%#v", test.max, max)
+			}
+		})
+	}
+}
+
+func TestFileStatistics(t *testing.T) {
+	type Row struct {
+		ID    int64   `parquet:"id"`
+		Count uint32  `parquet:"count"`
+		Name  string  `parquet:"name"`
+		Note  *string `parquet:"note,optional"`
+	}
+
+	note := "note"
+	f := writeStatisticsFile(t, [][]interface{}{
+		{
+			&Row{ID: 0, Count: 1 << 31, Name: "b"},
+			&Row{ID: 9, Count: 1<<31 + 9, Name: "c"},
+		},
+		{
+			&Row{ID: -5, Count: 0, Name: "a", Note: &note},
+			&Row{ID: 4, Count: 4, Name: "b"},
+			&Row{ID: 1, Count: 1, Name: "d"},
+		},
+		{
+			&Row{ID: 2, Count: 2, Name: "c"},
+		},
+	})
+
+	stats := f.Statistics()
+	if len(stats) != 4 {
+		t.Fatalf("wrong number of columns: %d", len(stats))
+	}
+
+	for i, test := range []struct {
+		path      string
+		min, max  interface{}
+		nullCount int64
+	}{
+		{"id", int64(-5), int64(9), 0},
+		{"count", uint64(0), uint64(1<<31 + 9), 0},
+		{"name", "a", "d", 0},
+		{"note", note, note, 5},
+	} {
+		t.Run(test.path, func(t *testing.T) {
+			s := &stats[i]
+			if len(s.Path) != 1 || s.Path[0] != test.path {
+				t.Errorf("wrong column path: %q", s.Path)
+			}
+			if s.NumRows != 6 || s.NumValues != 6 {
+				t.Errorf("wrong number of rows and values: rows=%d values=%d", s.NumRows, s.NumValues)
+			}
+			if s.NullCount != test.nullCount {
+				t.Errorf("wrong null count: want=%d got=%d", test.nullCount, s.NullCount)
+			}
+			if s.Incomplete {
+				t.Error("statistics are incomplete")
+			}
+			if min := s.Min(); !equalGoValues(min, test.min) {
+				t.Errorf("wrong min: want=%#v got=%#v", test.min, min)
+			}
+			if max := s.Max(); !equalGoValues(max, test.max) {
+				t.Errorf("wrong max: want=%#v got=%#v", test.max, max)
+			}
+		})
+	}
+}
+
+func TestFileStatisticsTruncated(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name"`
+	}
+
+	// The bounds of byte arrays read from the column index are not exact, the
+	// max values are prefixes of the values they bound.
+	for _, test := range []struct {
+		scenario string
+		names    []string
+		min, max string
+	}{
+		{"truncated", []string{"abcdefgh", "abcde"}, "abcd", "abcd"},
+		{"prefix", []string{"abcdefgh", "abc"}, "abc", "abc"},
+		{"greater", []string{"abcdefgh", "abd"}, "abcd", "abd"},
+		{"lower", []string{"abd", "abcdefgh"}, "abcd", "abd"},
+	} {
+		t.Run(test.scenario, func(t *testing.T) {
+			rowGroups := make([][]interface{}, len(test.names))
+			for i, name := range test.names {
+				rowGroups[i] = []interface{}{&Row{Name: name}}
+			}
+			f := writeStatisticsFile(t, rowGroups, parquet.ColumnIndexSizeLimit(4))
+			s := f.Statistics()[0]
+			if max := s.Max(); max != test.max || s.MaxValueExact {
+				t.Errorf("wrong max: want=%q got=%q (exact=%t)", test.max, max, s.MaxValueExact)
+			}
+			if min := s.Min(); min != test.min {
+				t.Errorf("wrong min: want=%q got=%q", test.min, min)
+			}
+		})
+	}
+}
+
+func writeStatisticsFile(t *testing.T, rowGroups [][]interface{}, options ...parquet.WriterOption) *parquet.File {
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, options...)
+	for _, rows := range rowGroups {
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func equalGoValues(a, b interface{}) bool {
+	switch x := a.(type) {
+	case []byte:
+		y, ok := b.([]byte)
+		return ok && bytes.Equal(x, y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	default:
+		return a == b
+	}
+}
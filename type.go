@@ -821,6 +821,15 @@ func (t *decimalType) ConvertedType() *deprecated.ConvertedType {
 	return &convertedTypes[deprecated.Decimal]
 }
 
+// Compare compares decimal values represented as fixed length byte arrays as
+// signed big-endian integers, as required by the DECIMAL sort order.
+func (t *decimalType) Compare(a, b Value) int {
+	if t.Type.Kind() != FixedLenByteArray {
+		return t.Type.Compare(a, b)
+	}
+	return compareSignedBigEndian(a.ByteArray(), b.ByteArray())
+}
+
 // String constructs a leaf node of UTF8 logical type.
 //
 // https://github.com/apache/parquet-format/blob/master/LogicalTypes.md#string
@@ -1218,6 +1227,18 @@ func (t *timeType) useInt64() bool {
 	return t.Unit.Micros != nil
 }
 
+// unit returns the duration of the time unit of t.
+func (t *timeType) unit() time.Duration {
+	switch {
+	case t.Unit.Millis != nil:
+		return time.Millisecond
+	case t.Unit.Micros != nil:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
 func (t *timeType) String() string {
 	return (*format.TimeType)(t).String()
 }
@@ -1391,6 +1412,41 @@ func (t *timestampType) Decode(dst, src []byte, enc encoding.Encoding) ([]byte,
 	return enc.DecodeInt64(dst, src)
 }
 
+// unixValue converts t to the number of time units since the unix epoch.
+func (t *timestampType) unixValue(v time.Time) int64 {
+	switch {
+	case t.Unit.Millis != nil:
+		return v.UnixMilli()
+	case t.Unit.Micros != nil:
+		return v.UnixMicro()
+	default:
+		return v.UnixNano()
+	}
+}
+
+// unit returns the duration of the time unit of t.
+func (t *timestampType) unit() time.Duration {
+	switch {
+	case t.Unit.Millis != nil:
+		return time.Millisecond
+	case t.Unit.Micros != nil:
+		return time.Microsecond
+	default:
+		return time.Nanosecond
+	}
+}
+
+// unixTime converts a number of time units since the unix epoch to a time.
+func (t *timestampType) unixTime(v int64) time.Time {
+	unit := int64(t.unit())
+	perSecond := int64(time.Second) / unit
+	sec, frac := v/perSecond, v%perSecond
+	if frac < 0 {
+		sec, frac = sec-1, frac+perSecond
+	}
+	return time.Unix(sec, frac*unit).UTC()
+}
+
 // List constructs a node of LIST logical type.
 //
 // https://github.com/apache/parquet-format/blob/master/LogicalTypes.md#lists
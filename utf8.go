@@ -0,0 +1,130 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/segmentio/parquet-go/deprecated"
+)
+
+// ValidateUTF8 is a configuration option which enables verifying that values of
+// columns annotated with the STRING logical type are valid UTF-8 strings.
+//
+// On files and readers, the pages are validated after being decoded; invalid
+// pages are reported with a *PageError carrying the column and range of rows of
+// the page, and wrapping ErrInvalidUTF8. When an OnPageError handler is
+// installed, the pages are skipped instead. Readers only apply the option when
+// they open the parquet file themselves.
+//
+// On writers, the pages are validated before being encoded, and the write fails
+// with an error wrapping ErrInvalidUTF8. Compressed pages copied from other
+// files are written as-is and are not validated.
+//
+// Defaults to false.
+func ValidateUTF8(enabled bool) interface {
+	FileOption
+	ReaderOption
+	WriterOption
+} {
+	return validateUTF8Option(enabled)
+}
+
+type validateUTF8Option bool
+
+func (opt validateUTF8Option) ConfigureFile(config *FileConfig) {
+	config.ValidateUTF8 = bool(opt)
+}
+
+func (opt validateUTF8Option) ConfigureReader(config *ReaderConfig) {
+	config.ValidateUTF8 = bool(opt)
+}
+
+func (opt validateUTF8Option) ConfigureWriter(config *WriterConfig) {
+	config.ValidateUTF8 = bool(opt)
+}
+
+// isStringType returns true if t is annotated with the STRING logical type, or
+// the UTF8 converted type.
+func isStringType(t Type) bool {
+	if lt := t.LogicalType(); lt != nil && lt.UTF8 != nil {
+		return true
+	}
+	ct := t.ConvertedType()
+	return ct != nil && *ct == deprecated.UTF8
+}
+
+// validateUTF8 returns an error wrapping ErrInvalidUTF8 if one of the values of
+// the page is not a valid UTF-8 string.
+func validateUTF8(page Page) error {
+	switch p := page.(type) {
+	case *optionalPage:
+		return validateUTF8(p.base)
+	case *repeatedPage:
+		return validateUTF8(p.base)
+	case *byteArrayPage:
+		for i, n := 0, 0; i < len(p.values); n++ {
+			v := p.valueAt(uint32(i))
+			if !utf8.Valid(v) {
+				return invalidUTF8(n)
+			}
+			i += 4 + len(v)
+		}
+		return nil
+	}
+
+	values := page.Values()
+	buffer := make([]Value, 64)
+	offset := 0
+
+	for {
+		n, err := values.ReadValues(buffer)
+		for i, v := range buffer[:n] {
+			if !v.IsNull() && !utf8.Valid(v.ByteArray()) {
+				return invalidUTF8(offset + i)
+			}
+		}
+		offset += n
+		if err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return err
+		}
+	}
+}
+
+// utf8Validator validates the values of the pages of a column chunk as UTF-8
+// strings. The values of dictionaries are only validated once instead of on
+// every page referencing them; since the dictionaries of writers grow as values
+// are added, the validator tracks how many values of the last dictionary seen
+// were already validated.
+type utf8Validator struct {
+	dictionary Dictionary
+	numValues  int
+}
+
+func (v *utf8Validator) reset() {
+	v.dictionary, v.numValues = nil, 0
+}
+
+func (v *utf8Validator) validate(page Page) error {
+	dict := page.Dictionary()
+	if dict == nil {
+		return validateUTF8(page)
+	}
+	if dict != v.dictionary || dict.Len() < v.numValues {
+		v.dictionary, v.numValues = dict, 0
+	}
+	for i, n := v.numValues, dict.Len(); i < n; i++ {
+		if !utf8.Valid(dict.Index(int32(i)).ByteArray()) {
+			return fmt.Errorf("value at index %d of the dictionary: %w", i, ErrInvalidUTF8)
+		}
+	}
+	v.numValues = dict.Len()
+	return nil
+}
+
+func invalidUTF8(index int) error {
+	return fmt.Errorf("value at index %d of the page: %w", index, ErrInvalidUTF8)
+}
@@ -0,0 +1,121 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestValidateUTF8(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+		Data []byte `parquet:"data"`
+	}
+
+	rows := make([]Row, 10)
+	for i := range rows {
+		rows[i] = Row{ID: int64(i), Name: "hello", Data: []byte{0xff}}
+	}
+	rows[7].Name = "\xc3\x28"
+
+	t.Run("writer", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		err := writeParquetFile(buffer, makeRows(rows), parquet.ValidateUTF8(true))
+		if !errors.Is(err, parquet.ErrInvalidUTF8) {
+			t.Fatalf("expected an error wrapping ErrInvalidUTF8, got %v", err)
+		}
+	})
+
+	buffer := new(bytes.Buffer)
+	if err := writeParquetFile(buffer, makeRows(rows)); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reader", func(t *testing.T) {
+		r := parquet.NewReader(bytes.NewReader(buffer.Bytes()), parquet.ValidateUTF8(true))
+		defer r.Close()
+
+		_, err := r.ReadRows(make([]parquet.Row, len(rows)))
+		if !errors.Is(err, parquet.ErrInvalidUTF8) {
+			t.Fatalf("expected an error wrapping ErrInvalidUTF8, got %v", err)
+		}
+		var pageErr *parquet.PageError
+		if !errors.As(err, &pageErr) {
+			t.Fatalf("expected a page error, got %T", err)
+		}
+		if path := pageErr.Path; len(path) != 1 || path[0] != "name" {
+			t.Errorf("wrong column path: %q", path)
+		}
+		if pageErr.FirstRowIndex != 0 || pageErr.NumRows != int64(len(rows)) {
+			t.Errorf("wrong row range: [%d:+%d]", pageErr.FirstRowIndex, pageErr.NumRows)
+		}
+	})
+
+	t.Run("OnPageError", func(t *testing.T) {
+		var pageErrors []*parquet.PageError
+		f, err := parquet.OpenFile(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()),
+			parquet.ValidateUTF8(true),
+			parquet.OnPageError(func(err *parquet.PageError) { pageErrors = append(pageErrors, err) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range f.RowGroups()[0].ColumnChunks() {
+			pages := chunk.Pages()
+			err := forEachPage(pages, func(parquet.Page) error { return nil })
+			pages.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(pageErrors) != 1 || !errors.Is(pageErrors[0], parquet.ErrInvalidUTF8) {
+			t.Errorf("wrong page errors reported: %v", pageErrors)
+		}
+	})
+}
+
+func TestValidateUTF8Dictionary(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name,dict"`
+	}
+
+	// The small pages share the dictionary of the column chunk, which grows as
+	// values are written; the invalid value is only added after the first
+	// pages were validated.
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i] = Row{Name: fmt.Sprintf("name-%d", i%100)}
+	}
+	rows[900].Name = "\xc3\x28"
+
+	t.Run("writer", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(256), parquet.ValidateUTF8(true))
+		if !errors.Is(err, parquet.ErrInvalidUTF8) {
+			t.Fatalf("expected an error wrapping ErrInvalidUTF8, got %v", err)
+		}
+
+		buffer.Reset()
+		if err := writeParquetFile(buffer, makeRows(rows[:900]), parquet.PageBufferSize(256), parquet.ValidateUTF8(true)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("reader", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		if err := writeParquetFile(buffer, makeRows(rows), parquet.PageBufferSize(256)); err != nil {
+			t.Fatal(err)
+		}
+		r := parquet.NewReader(bytes.NewReader(buffer.Bytes()), parquet.ValidateUTF8(true))
+		defer r.Close()
+
+		_, err := r.ReadRows(make([]parquet.Row, len(rows)))
+		if !errors.Is(err, parquet.ErrInvalidUTF8) {
+			t.Fatalf("expected an error wrapping ErrInvalidUTF8, got %v", err)
+		}
+	})
+}
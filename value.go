@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"reflect"
 	"strconv"
+	"time"
 	"unsafe"
 
 	"github.com/google/uuid"
+	"github.com/segmentio/parquet-go/bloom/xxhash"
 	"github.com/segmentio/parquet-go/deprecated"
 	"github.com/segmentio/parquet-go/internal/unsafecast"
 )
@@ -404,6 +407,160 @@ func (v Value) Uint64() uint64 { return v.u64 }
 // mutating the content will result in undefined behaviors.
 func (v Value) ByteArray() []byte { return unsafe.Slice(v.ptr, int(v.u64)) }
 
+// AsBoolean returns v as a bool, or an error if v is not a BOOLEAN value.
+func (v Value) AsBoolean() (bool, error) {
+	if v.Kind() != Boolean {
+		return false, v.conversionError(Boolean)
+	}
+	return v.Boolean(), nil
+}
+
+// AsInt32 returns v as an int32. INT64 values are converted if they are within
+// the range of int32 values, otherwise an error is returned.
+func (v Value) AsInt32() (int32, error) {
+	switch v.Kind() {
+	case Int32:
+		return v.Int32(), nil
+	case Int64:
+		if i := v.Int64(); i >= math.MinInt32 && i <= math.MaxInt32 {
+			return int32(i), nil
+		}
+	}
+	return 0, v.conversionError(Int32)
+}
+
+// AsInt64 returns v as an int64, converting INT32 values, or an error if v is
+// not an integer value.
+func (v Value) AsInt64() (int64, error) {
+	switch v.Kind() {
+	case Int32:
+		return int64(v.Int32()), nil
+	case Int64:
+		return v.Int64(), nil
+	}
+	return 0, v.conversionError(Int64)
+}
+
+// AsInt96 returns v as a deprecated.Int96, or an error if v is not an INT96
+// value.
+func (v Value) AsInt96() (deprecated.Int96, error) {
+	if v.Kind() != Int96 {
+		return deprecated.Int96{}, v.conversionError(Int96)
+	}
+	return v.Int96(), nil
+}
+
+// AsFloat returns v as a float32. DOUBLE values are converted if they can be
+// represented exactly as float32, otherwise an error is returned.
+func (v Value) AsFloat() (float32, error) {
+	switch v.Kind() {
+	case Float:
+		return v.Float(), nil
+	case Double:
+		if d := v.Double(); float64(float32(d)) == d || math.IsNaN(d) {
+			return float32(d), nil
+		}
+	}
+	return 0, v.conversionError(Float)
+}
+
+// AsDouble returns v as a float64, converting FLOAT values, or an error if v is
+// not a floating point value.
+func (v Value) AsDouble() (float64, error) {
+	switch v.Kind() {
+	case Float:
+		return float64(v.Float()), nil
+	case Double:
+		return v.Double(), nil
+	}
+	return 0, v.conversionError(Double)
+}
+
+// AsByteArray returns v as a []byte, or an error if v is not a BYTE_ARRAY or
+// FIXED_LEN_BYTE_ARRAY value. The returned byte slice must be treated as
+// read-only, see ByteArray.
+func (v Value) AsByteArray() ([]byte, error) {
+	switch v.Kind() {
+	case ByteArray, FixedLenByteArray:
+		return v.ByteArray(), nil
+	}
+	return nil, v.conversionError(ByteArray)
+}
+
+func (v Value) conversionError(kind Kind) error {
+	if v.IsNull() {
+		return fmt.Errorf("cannot convert to %s: %w", kind, ErrNullValue)
+	}
+	return fmt.Errorf("cannot convert %s value %v to %s: %w", v.Kind(), v, kind, ErrInvalidConversion)
+}
+
+// Time interprets v as a value of the TIMESTAMP, DATE, or TIME logical type of
+// typ, and converts it to a time.Time in UTC. TIME values are converted to the
+// time of day on January 1st 1970, and legacy INT96 timestamps are supported.
+//
+// The method returns false if v is null or typ is not a time type.
+func (v Value) Time(typ Type) (time.Time, bool) {
+	if v.IsNull() {
+		return time.Time{}, false
+	}
+	if lt := typ.LogicalType(); lt != nil {
+		switch {
+		case lt.Timestamp != nil:
+			return (*timestampType)(lt.Timestamp).unixTime(v.Int64()), true
+		case lt.Date != nil:
+			return time.Unix(int64(v.Int32())*secondsPerDay, 0).UTC(), true
+		case lt.Time != nil:
+			unit := timeUnitDuration(&lt.Time.Unit)
+			if v.Kind() == Int32 {
+				return time.Unix(0, int64(time.Duration(v.Int32())*unit)).UTC(), true
+			}
+			return time.Unix(0, int64(time.Duration(v.Int64())*unit)).UTC(), true
+		}
+	}
+	if v.Kind() == Int96 && typ.Kind() == Int96 {
+		return v.Int96().Time(), true
+	}
+	return time.Time{}, false
+}
+
+// Decimal interprets v as a value of the DECIMAL logical type of typ, returning
+// its unscaled value and scale; the decimal value is unscaled / 10^scale. Byte
+// arrays hold the unscaled value as a big-endian two's complement integer.
+//
+// The method returns false if v is null or typ is not a decimal type.
+func (v Value) Decimal(typ Type) (unscaled *big.Int, scale int, ok bool) {
+	lt := typ.LogicalType()
+	if v.IsNull() || lt == nil || lt.Decimal == nil {
+		return nil, 0, false
+	}
+	switch v.Kind() {
+	case Int32:
+		unscaled = big.NewInt(int64(v.Int32()))
+	case Int64:
+		unscaled = big.NewInt(v.Int64())
+	case ByteArray, FixedLenByteArray:
+		b := v.ByteArray()
+		unscaled = new(big.Int).SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+		}
+	default:
+		return nil, 0, false
+	}
+	return unscaled, int(lt.Decimal.Scale), true
+}
+
+// UUID returns v as a UUID, assuming the underlying type is a 16 bytes
+// FIXED_LEN_BYTE_ARRAY, as used by the UUID logical type.
+//
+// The method returns false if v is null or not a 16 bytes array.
+func (v Value) UUID() (uuid.UUID, bool) {
+	if v.Kind() != FixedLenByteArray || v.u64 != 16 {
+		return uuid.UUID{}, false
+	}
+	return *(*uuid.UUID)(v.ByteArray()), true
+}
+
 // RepetitionLevel returns the repetition level of v.
 func (v Value) RepetitionLevel() int { return int(v.repetitionLevel) }
 
@@ -581,6 +738,61 @@ func (v Value) Clone() Value {
 	return v
 }
 
+// CloneValues appends copies of values to dst, storing the byte arrays that
+// they reference in arena, and returns the extended dst and arena slices. The
+// copies do not share any pointers with the original values.
+//
+// The arena is grown at most once for the whole batch of values, which allows
+// programs to retain values read with zero-copy semantics beyond the lifetime
+// of the pages they were read from, without allocating memory for each byte
+// array. The arena is owned by the caller, it may be reused by passing
+// arena[:0], which invalidates the values previously cloned into it.
+func CloneValues(dst []Value, arena []byte, values []Value) ([]Value, []byte) {
+	arena = growArena(arena, byteArraySizeOf(values))
+	for _, v := range values {
+		v, arena = v.cloneTo(arena)
+		dst = append(dst, v)
+	}
+	return dst, arena
+}
+
+// cloneTo returns a copy of v with its byte array stored in arena, which must
+// have enough capacity to hold it.
+func (v Value) cloneTo(arena []byte) (Value, []byte) {
+	switch v.Kind() {
+	case ByteArray, FixedLenByteArray:
+		if v.u64 == 0 {
+			v.ptr = nil
+		} else {
+			offset := len(arena)
+			arena = append(arena, v.ByteArray()...)
+			v.ptr = unsafecast.AddressOfBytes(arena[offset:])
+		}
+	}
+	return v, arena
+}
+
+func byteArraySizeOf(values []Value) (size int) {
+	for i := range values {
+		switch values[i].Kind() {
+		case ByteArray, FixedLenByteArray:
+			size += int(values[i].u64)
+		}
+	}
+	return size
+}
+
+// growArena ensures that arena has enough capacity to append size bytes. The
+// byte arrays previously stored in arena remain valid after it was grown.
+func growArena(arena []byte, size int) []byte {
+	if (cap(arena) - len(arena)) < size {
+		newArena := make([]byte, len(arena), len(arena)+size)
+		copy(newArena, arena)
+		arena = newArena
+	}
+	return arena
+}
+
 func makeInt96(bits []byte) (i96 deprecated.Int96) {
 	return deprecated.Int96{
 		2: binary.LittleEndian.Uint32(bits[8:12]),
@@ -637,7 +849,13 @@ func assignValue(dst reflect.Value, src Value) error {
 		}
 
 	case Int96:
-		val = reflect.ValueOf(src.Int96())
+		v := src.Int96()
+		switch dst.Type() {
+		case reflect.TypeOf(time.Time{}):
+			val = reflect.ValueOf(v.Time())
+		default:
+			val = reflect.ValueOf(v)
+		}
 
 	case Float:
 		v := src.Float()
@@ -793,6 +1011,79 @@ func DeepEqual(v1, v2 Value) bool {
 		v1.columnIndex == v2.columnIndex
 }
 
+// EqualValues returns true if v1 and v2 are equal values of type typ.
+//
+// Unlike Equal, which compares the physical representation of values, the
+// comparison follows the sort order of typ, see CompareValues.
+func EqualValues(typ Type, v1, v2 Value) bool { return CompareValues(typ, v1, v2) == 0 }
+
+// CompareValues compares v1 and v2 according to the sort order of typ, which
+// accounts for logical types such as unsigned integers or decimals. It returns
+// a negative number if v1 is less than v2, a positive number if v1 is greater
+// than v2, and zero if they are equal.
+//
+// Null values are ordered before non-null values, and floating point NaN values
+// are ordered after all other floating point values and are equal to each
+// other.
+func CompareValues(typ Type, v1, v2 Value) int {
+	switch null1, null2 := v1.IsNull(), v2.IsNull(); {
+	case null1 || null2:
+		return compareBool(!null1, !null2)
+	}
+	switch typ.Kind() {
+	case Float:
+		if nan1, nan2 := isNaN32(v1.Float()), isNaN32(v2.Float()); nan1 || nan2 {
+			return compareBool(nan1, nan2)
+		}
+	case Double:
+		if nan1, nan2 := math.IsNaN(v1.Double()), math.IsNaN(v2.Double()); nan1 || nan2 {
+			return compareBool(nan1, nan2)
+		}
+	}
+	return typ.Compare(v1, v2)
+}
+
+// HashValue returns a hash of v, which is a value of type typ. Values that are
+// equal according to EqualValues have the same hash.
+//
+// The hash function is not seeded, so hashes are stable across processes and
+// may be used to partition values.
+func HashValue(typ Type, v Value) uint64 {
+	if v.IsNull() {
+		return 0
+	}
+	switch typ.Kind() {
+	case Boolean:
+		return xxhash.Sum64Uint8(v.Byte())
+	case Int32:
+		return xxhash.Sum64Uint32(v.Uint32())
+	case Int64:
+		return xxhash.Sum64Uint64(v.Uint64())
+	case Float:
+		f := v.Float()
+		switch {
+		case f == 0: // -0 == +0
+			f = 0
+		case isNaN32(f):
+			f = float32(math.NaN())
+		}
+		return xxhash.Sum64Uint32(math.Float32bits(f))
+	case Double:
+		f := v.Double()
+		switch {
+		case f == 0:
+			f = 0
+		case math.IsNaN(f):
+			f = math.NaN()
+		}
+		return xxhash.Sum64Uint64(math.Float64bits(f))
+	default:
+		return xxhash.Sum64(v.ByteArray())
+	}
+}
+
+func isNaN32(f float32) bool { return f != f }
+
 var (
 	_ fmt.Formatter = Value{}
 	_ fmt.Stringer  = Value{}
@@ -1,11 +1,15 @@
 package parquet_test
 
 import (
+	"errors"
 	"math"
 	"testing"
+	"time"
 	"unsafe"
 
+	"github.com/google/uuid"
 	"github.com/segmentio/parquet-go"
+	"github.com/segmentio/parquet-go/deprecated"
 )
 
 func TestSizeOfValue(t *testing.T) {
@@ -90,3 +94,289 @@ func TestValueClone(t *testing.T) {
 		})
 	}
 }
+
+func TestCloneValues(t *testing.T) {
+	data := []byte("Hello World!")
+	values := []parquet.Value{
+		parquet.ValueOf(data[:5]).Level(0, 1, 0),
+		parquet.ValueOf(nil).Level(0, 0, 1),
+		parquet.ValueOf(int64(42)).Level(0, 0, 2),
+		parquet.ValueOf(data[6:]).Level(1, 1, 0),
+		parquet.ValueOf([]byte{}).Level(0, 1, 3),
+		parquet.ValueOf([4]byte{1, 2, 3, 4}).Level(0, 0, 4),
+	}
+
+	arena := []byte("prefix")
+	clones, arena := parquet.CloneValues(nil, arena, values)
+
+	if string(arena) != "prefixHelloWorld!\x01\x02\x03\x04" {
+		t.Errorf("wrong arena content: %q", arena)
+	}
+	if !parquet.Row(clones).Equal(values) {
+		t.Errorf("cloned values are not equal\nwant = %+v\ngot  = %+v", values, clones)
+	}
+
+	copy(data, "XXXXXXXXXXXX")
+	if s := clones[0].String(); s != "Hello" {
+		t.Errorf("cloned value was modified: %q", s)
+	}
+
+	dst := make([]parquet.Value, 0, len(values))
+	allocs := testing.AllocsPerRun(10, func() {
+		dst, arena = parquet.CloneValues(dst[:0], arena[:0], values)
+	})
+	if allocs != 0 {
+		t.Errorf("cloning values into a large enough arena allocated memory: %g", allocs)
+	}
+}
+
+func TestValueTime(t *testing.T) {
+	now := time.Date(2022, 6, 15, 13, 45, 30, 123456789, time.UTC)
+
+	tests := []struct {
+		scenario string
+		typ      parquet.Type
+		value    parquet.Value
+		want     time.Time
+	}{
+		{
+			scenario: "timestamp millis",
+			typ:      parquet.Timestamp(parquet.Millisecond).Type(),
+			value:    parquet.ValueOf(now.UnixMilli()),
+			want:     now.Truncate(time.Millisecond),
+		},
+		{
+			scenario: "timestamp nanos before epoch",
+			typ:      parquet.Timestamp(parquet.Nanosecond).Type(),
+			value:    parquet.ValueOf(int64(-1)),
+			want:     time.Unix(0, -1).UTC(),
+		},
+		{
+			scenario: "date",
+			typ:      parquet.Date().Type(),
+			value:    parquet.ValueOf(int32(19158)),
+			want:     time.Date(2022, 6, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			scenario: "time millis",
+			typ:      parquet.Time(parquet.Millisecond).Type(),
+			value:    parquet.ValueOf(int32(1500)),
+			want:     time.Unix(1, 500e6).UTC(),
+		},
+		{
+			scenario: "time micros",
+			typ:      parquet.Time(parquet.Microsecond).Type(),
+			value:    parquet.ValueOf(int64(1500)),
+			want:     time.Unix(0, 1500e3).UTC(),
+		},
+		{
+			scenario: "int96",
+			typ:      parquet.Int96Type,
+			value:    parquet.ValueOf(deprecated.Int96FromTime(now)),
+			want:     now,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			got, ok := test.value.Time(test.typ)
+			if !ok {
+				t.Fatal("value not converted to a time")
+			}
+			if !got.Equal(test.want) || got.Location() != time.UTC {
+				t.Errorf("wrong time: want=%v got=%v", test.want, got)
+			}
+		})
+	}
+
+	if _, ok := parquet.ValueOf(int64(1)).Time(parquet.Int64Type); ok {
+		t.Error("value of a type which is not a time type converted to a time")
+	}
+	if _, ok := parquet.ValueOf(nil).Time(parquet.Date().Type()); ok {
+		t.Error("null value converted to a time")
+	}
+}
+
+func TestValueDecimal(t *testing.T) {
+	tests := []struct {
+		scenario string
+		typ      parquet.Type
+		value    parquet.Value
+		want     string
+	}{
+		{
+			scenario: "int32",
+			typ:      parquet.Decimal(2, 9, parquet.Int32Type).Type(),
+			value:    parquet.ValueOf(int32(-12345)),
+			want:     "-12345",
+		},
+		{
+			scenario: "int64",
+			typ:      parquet.Decimal(2, 18, parquet.Int64Type).Type(),
+			value:    parquet.ValueOf(int64(math.MaxInt64)),
+			want:     "9223372036854775807",
+		},
+		{
+			scenario: "positive fixed length byte array",
+			typ:      parquet.Decimal(2, 20, parquet.FixedLenByteArrayType(9)).Type(),
+			value:    parquet.ValueOf([9]byte{0x01, 0, 0, 0, 0, 0, 0, 0, 0}),
+			want:     "18446744073709551616",
+		},
+		{
+			scenario: "negative fixed length byte array",
+			typ:      parquet.Decimal(2, 4, parquet.FixedLenByteArrayType(2)).Type(),
+			value:    parquet.ValueOf([2]byte{0xff, 0x85}),
+			want:     "-123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			unscaled, scale, ok := test.value.Decimal(test.typ)
+			if !ok {
+				t.Fatal("value not converted to a decimal")
+			}
+			if scale != 2 {
+				t.Errorf("wrong scale: want=2 got=%d", scale)
+			}
+			if got := unscaled.String(); got != test.want {
+				t.Errorf("wrong unscaled value: want=%s got=%s", test.want, got)
+			}
+		})
+	}
+
+	if _, _, ok := parquet.ValueOf(int32(1)).Decimal(parquet.Int32Type); ok {
+		t.Error("value of a type which is not a decimal type converted to a decimal")
+	}
+}
+
+func TestValueUUID(t *testing.T) {
+	id := uuid.MustParse("f81d4fae-7dec-11d0-a765-00a0c91e6bf6")
+	got, ok := parquet.ValueOf(id).UUID()
+	if !ok || got != id {
+		t.Errorf("wrong UUID: want=%v got=%v (%t)", id, got, ok)
+	}
+	if _, ok := parquet.ValueOf([8]byte{}).UUID(); ok {
+		t.Error("fixed length byte array of 8 bytes converted to a UUID")
+	}
+	if _, ok := parquet.ValueOf(nil).UUID(); ok {
+		t.Error("null value converted to a UUID")
+	}
+}
+
+func TestValueCheckedAccessors(t *testing.T) {
+	call := func(v parquet.Value, kind parquet.Kind) (interface{}, error) {
+		switch kind {
+		case parquet.Boolean:
+			return v.AsBoolean()
+		case parquet.Int32:
+			return v.AsInt32()
+		case parquet.Int64:
+			return v.AsInt64()
+		case parquet.Int96:
+			return v.AsInt96()
+		case parquet.Float:
+			return v.AsFloat()
+		case parquet.Double:
+			return v.AsDouble()
+		default:
+			b, err := v.AsByteArray()
+			return string(b), err
+		}
+	}
+
+	tests := []struct {
+		value parquet.Value
+		kind  parquet.Kind
+		want  interface{}
+		err   error
+	}{
+		{parquet.ValueOf(true), parquet.Boolean, true, nil},
+		{parquet.ValueOf(int32(-1)), parquet.Boolean, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(int32(-1)), parquet.Int32, int32(-1), nil},
+		{parquet.ValueOf(int64(-1)), parquet.Int32, int32(-1), nil},
+		{parquet.ValueOf(int64(math.MaxInt32 + 1)), parquet.Int32, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(float32(1)), parquet.Int32, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(int32(math.MinInt32)), parquet.Int64, int64(math.MinInt32), nil},
+		{parquet.ValueOf(int64(math.MaxInt64)), parquet.Int64, int64(math.MaxInt64), nil},
+		{parquet.ValueOf("1"), parquet.Int64, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(deprecated.Int96{1, 2, 3}), parquet.Int96, deprecated.Int96{1, 2, 3}, nil},
+		{parquet.ValueOf(int64(1)), parquet.Int96, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(float32(0.5)), parquet.Float, float32(0.5), nil},
+		{parquet.ValueOf(0.25), parquet.Float, float32(0.25), nil},
+		{parquet.ValueOf(0.1), parquet.Float, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(float32(0.1)), parquet.Double, float64(float32(0.1)), nil},
+		{parquet.ValueOf(0.1), parquet.Double, 0.1, nil},
+		{parquet.ValueOf(int64(1)), parquet.Double, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf("hello"), parquet.ByteArray, "hello", nil},
+		{parquet.ValueOf([2]byte{'h', 'i'}), parquet.ByteArray, "hi", nil},
+		{parquet.ValueOf(true), parquet.ByteArray, nil, parquet.ErrInvalidConversion},
+		{parquet.ValueOf(nil), parquet.Boolean, nil, parquet.ErrNullValue},
+		{parquet.ValueOf(nil), parquet.Int64, nil, parquet.ErrNullValue},
+		{parquet.ValueOf(nil), parquet.ByteArray, nil, parquet.ErrNullValue},
+	}
+
+	for _, test := range tests {
+		got, err := call(test.value, test.kind)
+		if test.err != nil {
+			if !errors.Is(err, test.err) {
+				t.Errorf("converting %v to %s: want error %v, got %v", test.value, test.kind, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("converting %v to %s: %v", test.value, test.kind, err)
+		} else if got != test.want {
+			t.Errorf("converting %v to %s: want=%v got=%v", test.value, test.kind, test.want, got)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	decimal := parquet.Decimal(0, 4, parquet.FixedLenByteArrayType(2)).Type()
+	uint32Type := parquet.Uint(32).Type()
+
+	tests := []struct {
+		scenario string
+		typ      parquet.Type
+		v1, v2   parquet.Value
+		cmp      int
+	}{
+		{"nulls", parquet.Int32Type, parquet.Value{}, parquet.Value{}, 0},
+		{"null first", parquet.Int32Type, parquet.Value{}, parquet.ValueOf(int32(math.MinInt32)), -1},
+		{"null last", parquet.Int32Type, parquet.ValueOf(int32(0)), parquet.Value{}, +1},
+		{"signed", parquet.Int32Type, parquet.ValueOf(int32(-1)), parquet.ValueOf(int32(1)), -1},
+		{"unsigned", uint32Type, parquet.ValueOf(uint32(math.MaxUint32)), parquet.ValueOf(uint32(1)), +1},
+		{"zeros", parquet.DoubleType, parquet.ValueOf(math.Copysign(0, -1)), parquet.ValueOf(0.0), 0},
+		{"NaN last", parquet.DoubleType, parquet.ValueOf(math.NaN()), parquet.ValueOf(math.Inf(+1)), +1},
+		{"NaNs", parquet.FloatType, parquet.ValueOf(float32(math.NaN())), parquet.ValueOf(float32(math.NaN())), 0},
+		{"decimal", decimal, parquet.ValueOf([2]byte{0xff, 0xff}), parquet.ValueOf([2]byte{0x00, 0x01}), -1},
+		{"decimal", decimal, parquet.ValueOf([2]byte{0x80, 0x00}), parquet.ValueOf([2]byte{0xff, 0x00}), -1},
+		{"decimal", decimal, parquet.ValueOf([2]byte{0x7f, 0xff}), parquet.ValueOf([2]byte{0x7f, 0xfe}), +1},
+		{"bytes", parquet.ByteArrayType, parquet.ValueOf("ab"), parquet.ValueOf("b"), -1},
+		{"bytes", parquet.ByteArrayType, parquet.ValueOf("ab"), parquet.ValueOf([]byte("ab")), 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			if cmp := parquet.CompareValues(test.typ, test.v1, test.v2); cmp != test.cmp {
+				t.Errorf("CompareValues(%v, %v): want=%d got=%d", test.v1, test.v2, test.cmp, cmp)
+			}
+			if cmp := parquet.CompareValues(test.typ, test.v2, test.v1); cmp != -test.cmp {
+				t.Errorf("CompareValues(%v, %v): want=%d got=%d", test.v2, test.v1, -test.cmp, cmp)
+			}
+			equal := parquet.EqualValues(test.typ, test.v1, test.v2)
+			if equal != (test.cmp == 0) {
+				t.Errorf("EqualValues(%v, %v): want=%t got=%t", test.v1, test.v2, test.cmp == 0, equal)
+			}
+			hash1 := parquet.HashValue(test.typ, test.v1)
+			hash2 := parquet.HashValue(test.typ, test.v2)
+			if equal && hash1 != hash2 {
+				t.Errorf("equal values have different hashes: %016x != %016x", hash1, hash2)
+			}
+			if !equal && hash1 == hash2 {
+				t.Errorf("different values have the same hash: %016x", hash1)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package parquet
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
@@ -10,12 +11,16 @@ import (
 	"math"
 	"math/bits"
 	"sort"
+	"sync"
 
 	"github.com/segmentio/encoding/thrift"
+	"github.com/segmentio/parquet-go/bloom"
 	"github.com/segmentio/parquet-go/compress"
 	"github.com/segmentio/parquet-go/encoding"
 	"github.com/segmentio/parquet-go/encoding/plain"
 	"github.com/segmentio/parquet-go/format"
+	"github.com/segmentio/parquet-go/hyperloglog"
+	"github.com/segmentio/parquet-go/internal/unsafecast"
 )
 
 // Deprecated: A Writer uses a parquet schema and sequence of Go values to
@@ -163,6 +168,9 @@ func (w *Writer) WriteRows(rows []Row) (int, error) {
 //
 // The content of the row group is flushed to the writer; after the method
 // returns successfully, the row group will be empty and in ready to be reused.
+//
+// Row groups with a schema different from the schema of the writer are
+// converted as their rows are copied, see Buffer.WriteRowGroup for details.
 func (w *Writer) WriteRowGroup(rowGroup RowGroup) (int64, error) {
 	rowGroupSchema := rowGroup.Schema()
 	switch {
@@ -170,8 +178,11 @@ func (w *Writer) WriteRowGroup(rowGroup RowGroup) (int64, error) {
 		return 0, ErrRowGroupSchemaMissing
 	case w.schema == nil:
 		w.configure(rowGroupSchema)
-	case !nodesAreEqual(w.schema, rowGroupSchema):
-		return 0, ErrRowGroupSchemaMismatch
+	default:
+		var err error
+		if rowGroup, err = convertRowGroupForWrite(rowGroup, w.schema); err != nil {
+			return 0, err
+		}
 	}
 	if err := w.writer.flush(); err != nil {
 		return 0, err
@@ -208,6 +219,45 @@ func (w *Writer) ReadRowsFrom(rows RowReader) (written int64, err error) {
 // The returned value will be nil if no schema has yet been configured on w.
 func (w *Writer) Schema() *Schema { return w.schema }
 
+// RowGroupStats carries statistics about a row group written to a parquet file,
+// which are reported to the handler installed with the OnRowGroupFlush writer
+// option.
+type RowGroupStats struct {
+	// Index of the row group in the file.
+	RowGroup int
+	// Number of rows in the row group.
+	NumRows int64
+	// Statistics of the column chunks of the row group, in the order of the
+	// leaf columns of the schema.
+	Columns []ColumnChunkStats
+}
+
+// ColumnChunkStats carries statistics about the compression of a column chunk
+// written to a parquet file.
+type ColumnChunkStats struct {
+	// Path of the column in the schema.
+	Path []string
+	// The compression codec that the column chunk was written with.
+	Codec format.CompressionCodec
+	// The encodings used by the pages of the column chunk.
+	Encodings []format.Encoding
+	// Number of values in the column chunk, including nulls.
+	NumValues int64
+	// Total size of the pages of the column chunk, including their headers,
+	// before and after compression.
+	UncompressedSize int64
+	CompressedSize   int64
+}
+
+// CompressionRatio returns the ratio of the compressed size of the column chunk
+// to its uncompressed size, which is zero if the column chunk is empty.
+func (s *ColumnChunkStats) CompressionRatio() float64 {
+	if s.UncompressedSize == 0 {
+		return 0
+	}
+	return float64(s.CompressedSize) / float64(s.UncompressedSize)
+}
+
 type writer struct {
 	buffer *bufio.Writer
 	writer offsetTrackingWriter
@@ -215,6 +265,7 @@ type writer struct {
 
 	createdBy string
 	metadata  []format.KeyValue
+	onFlush   func(*RowGroupStats)
 
 	columns       []*writerColumn
 	columnChunk   []format.ColumnChunk
@@ -228,10 +279,42 @@ type writer struct {
 	columnIndexes  [][]format.ColumnIndex
 	offsetIndexes  [][]format.OffsetIndex
 	sortingColumns []format.SortingColumn
+
+	bloomFilterPlacement BloomFilterPlacement
+	bloomFilters         []writerBloomFilter
+	// Key/value metadata holding the distinct count sketches of the column
+	// chunks, which are added to the footer.
+	sketches []format.KeyValue
+	// Set when the file is encrypted.
+	encryption *fileEncryption
+}
+
+// writerBloomFilter retains the serialized bloom filter of a column chunk until
+// it is written before the file footer.
+type writerBloomFilter struct {
+	rowGroup int
+	column   int
+	data     []byte
 }
 
 func newWriter(output io.Writer, config *WriterConfig) *writer {
+	if err := validateBloomFilters(config.Schema, config.BloomFilters); err != nil {
+		panic(err)
+	}
+	if err := validateAggregators(config.Schema, config.ColumnAggregators); err != nil {
+		panic(err)
+	}
+	if err := validateColumnKeys(config.Schema, config.Encryption); err != nil {
+		panic(err)
+	}
 	w := new(writer)
+	if config.Encryption != nil {
+		e, err := newFileEncryption(config.Encryption)
+		if err != nil {
+			panic(err)
+		}
+		w.encryption = e
+	}
 	if config.WriteBufferSize <= 0 {
 		w.writer.Reset(output)
 	} else {
@@ -239,12 +322,15 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 		w.writer.Reset(w.buffer)
 	}
 	w.createdBy = config.CreatedBy
+	w.onFlush = config.OnRowGroupFlush
+	w.bloomFilterPlacement = config.BloomFilterPlacement
 	w.metadata = make([]format.KeyValue, 0, len(config.KeyValueMetadata))
 	for k, v := range config.KeyValueMetadata {
 		w.metadata = append(w.metadata, format.KeyValue{Key: k, Value: v})
 	}
 	sortKeyValueMetadata(w.metadata)
 	w.sortingColumns = make([]format.SortingColumn, len(config.SortingColumns))
+	sortingColumnsFound := make([]bool, len(config.SortingColumns))
 
 	config.Schema.forEachNode(func(name string, node Node) {
 		nodeType := node.Type()
@@ -299,7 +385,7 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 	buffers := new(writerBuffers)
 
 	forEachLeafColumnOf(config.Schema, func(leaf leafColumn) {
-		encoding := encodingOf(leaf.node)
+		encoding, fallbackEncodings := preferredEncodingOf(leaf.node, config.PreferredEncodings)
 		dictionary := Dictionary(nil)
 		columnType := leaf.node.Type()
 		columnIndex := int(leaf.columnIndex)
@@ -308,6 +394,9 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 		if compression == nil {
 			compression = defaultCompression
 		}
+		if dict, ok := config.ZstdDictionaries[leaf.path.String()]; ok {
+			compression = withZstdDictionary(compression, dict, true)
+		}
 
 		if isDictionaryEncoding(encoding) {
 			dictionary = columnType.NewDictionary(columnIndex, 0, make([]byte, 0, defaultDictBufferSize))
@@ -320,8 +409,11 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 			columnPath:         leaf.path,
 			columnType:         columnType,
 			columnIndex:        columnType.NewColumnIndexer(config.ColumnIndexSizeLimit),
+			columnIndexLimit:   config.ColumnIndexSizeLimit,
 			columnFilter:       searchBloomFilterColumn(config.BloomFilters, leaf.path),
 			compression:        compression,
+			compressors:        config.CompressionPool,
+			compressThreshold:  config.CompressionThreshold,
 			dictionary:         dictionary,
 			dataPageType:       dataPageType,
 			maxRepetitionLevel: leaf.maxRepetitionLevel,
@@ -329,6 +421,7 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 			bufferIndex:        int32(leaf.columnIndex),
 			bufferSize:         int32(config.PageBufferSize),
 			writePageStats:     config.DataPageStatistics,
+			validateUTF8:       config.ValidateUTF8 && isStringType(leaf.node.Type()),
 			encodings:          make([]format.Encoding, 0, 3),
 			// Data pages in version 2 can omit compression when dictionary
 			// encoding is employed; only the dictionary page needs to be
@@ -339,6 +432,20 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 		}
 
 		c.header.encoder.Reset(c.header.protocol.NewWriter(&buffers.header))
+		c.filter.concurrency = config.BloomFilterConcurrency
+		if config.DistinctCountPrecision > 0 {
+			c.sketch.hll, _ = hyperloglog.New(config.DistinctCountPrecision)
+		}
+		if c.aggregation.column = searchAggregatorColumn(config.ColumnAggregators, leaf.path); c.aggregation.column != nil {
+			c.aggregation.aggregator = c.aggregation.column.NewAggregator()
+		}
+		if w.encryption != nil {
+			e, err := w.encryption.column(leaf.path, columnIndex)
+			if err != nil {
+				panic(err)
+			}
+			c.encryption = e
+		}
 
 		if leaf.maxDefinitionLevel > 0 {
 			c.encodings = addEncoding(c.encodings, format.RLE)
@@ -349,20 +456,42 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 		}
 
 		c.page.encoding = encoding
-		c.encodings = addEncoding(c.encodings, c.page.encoding.Encoding())
+		c.page.fallbacks = fallbackEncodings
+		if config.AdaptiveEncoding && leaf.node.Encoding() == nil && dictionary == nil {
+			c.adaptive = newAdaptiveEncoding(c, config.PreferredEncodings[columnType.Kind()])
+		}
+		// When the encoding is selected adaptively, the page encodings are
+		// added to the column chunk metadata as pages get written.
+		if c.adaptive == nil {
+			c.encodings = addEncoding(c.encodings, c.page.encoding.Encoding())
+		}
 		sortPageEncodings(c.encodings)
+		if c.adaptive != nil {
+			c.adaptive.encodings = c.encodings
+		}
 
 		w.columns = append(w.columns, c)
 
-		if sortingIndex := searchSortingColumn(config.SortingColumns, leaf.path); sortingIndex < len(w.sortingColumns) {
+		if sortingIndex := searchSortingColumn(config.SortingColumns, leaf.path); sortingIndex < len(w.sortingColumns) && sortingKeyOf(config.SortingColumns[sortingIndex]) == nil {
 			w.sortingColumns[sortingIndex] = format.SortingColumn{
 				ColumnIdx:  int32(leaf.columnIndex),
 				Descending: config.SortingColumns[sortingIndex].Descending(),
 				NullsFirst: config.SortingColumns[sortingIndex].NullsFirst(),
 			}
+			sortingColumnsFound[sortingIndex] = true
 		}
 	})
 
+	// Like in writeRowGroup, the order is only known up to the first sorting
+	// column which does not name a leaf column of the schema (e.g. a sorting
+	// key computed from the rows).
+	for i, found := range sortingColumnsFound {
+		if !found {
+			w.sortingColumns = w.sortingColumns[:i]
+			break
+		}
+	}
+
 	// Pre-allocate the backing array so that in most cases where the rows
 	// contain a single value we will hit collocated memory areas when writing
 	// rows to the writer. This won't benefit repeated columns much but in that
@@ -385,7 +514,7 @@ func newWriter(output io.Writer, config *WriterConfig) *writer {
 				Encoding:         c.encodings,
 				PathInSchema:     c.columnPath,
 				Codec:            c.compression.CompressionCodec(),
-				KeyValueMetadata: nil, // TODO
+				KeyValueMetadata: nil,
 			},
 		}
 	}
@@ -411,6 +540,11 @@ func (w *writer) reset(writer io.Writer) {
 	}
 	for _, c := range w.columns {
 		c.reset()
+		// Aggregators compute statistics for the whole file, new ones are
+		// created when the writer starts writing a new file.
+		if c.aggregation.column != nil {
+			c.aggregation.aggregator = c.aggregation.column.NewAggregator()
+		}
 	}
 	for i := range w.rowGroups {
 		w.rowGroups[i] = format.RowGroup{}
@@ -421,9 +555,17 @@ func (w *writer) reset(writer io.Writer) {
 	for i := range w.offsetIndexes {
 		w.offsetIndexes[i] = nil
 	}
+	for i := range w.bloomFilters {
+		w.bloomFilters[i] = writerBloomFilter{}
+	}
 	w.rowGroups = w.rowGroups[:0]
 	w.columnIndexes = w.columnIndexes[:0]
 	w.offsetIndexes = w.offsetIndexes[:0]
+	w.bloomFilters = w.bloomFilters[:0]
+	w.sketches = w.sketches[:0]
+	if w.encryption != nil {
+		w.encryption.reset()
+	}
 }
 
 func (w *writer) close() error {
@@ -452,12 +594,21 @@ func (w *writer) writeFileHeader() error {
 		return io.ErrClosedPipe
 	}
 	if w.writer.offset == 0 {
-		_, err := w.writer.WriteString("PAR1")
+		_, err := w.writer.WriteString(w.magic())
 		return err
 	}
 	return nil
 }
 
+// magic returns the magic bytes at the beginning and end of the file, which
+// differ for files with an encrypted footer.
+func (w *writer) magic() string {
+	if w.encryption != nil && !w.encryption.config.PlaintextFooter {
+		return "PARE"
+	}
+	return "PAR1"
+}
+
 func (w *writer) configureBloomFilters(columnChunks []ColumnChunk) {
 	for i, c := range w.columns {
 		if c.columnFilter != nil {
@@ -486,8 +637,12 @@ func (w *writer) writeFileFooter() error {
 		rowGroup := &w.rowGroups[i]
 		for j := range columnIndexes {
 			column := &rowGroup.Columns[j]
+			if len(columnIndexes[j].NullPages) == 0 {
+				// The column index was omitted, see recordPageStats.
+				continue
+			}
 			column.ColumnIndexOffset = w.writer.offset
-			if err := encoder.Encode(&columnIndexes[j]); err != nil {
+			if err := w.writePageIndex(encoder, i, j, &columnIndexes[j], columnIndexModule); err != nil {
 				return err
 			}
 			column.ColumnIndexLength = int32(w.writer.offset - column.ColumnIndexOffset)
@@ -499,40 +654,179 @@ func (w *writer) writeFileFooter() error {
 		for j := range offsetIndexes {
 			column := &rowGroup.Columns[j]
 			column.OffsetIndexOffset = w.writer.offset
-			if err := encoder.Encode(&offsetIndexes[j]); err != nil {
+			if err := w.writePageIndex(encoder, i, j, &offsetIndexes[j], offsetIndexModule); err != nil {
 				return err
 			}
 			column.OffsetIndexLength = int32(w.writer.offset - column.OffsetIndexOffset)
 		}
 	}
 
+	// When configured with BloomFiltersBeforeFooter, the bloom filters of all
+	// row groups are written after the page index, right before the footer.
+	for _, f := range w.bloomFilters {
+		w.rowGroups[f.rowGroup].Columns[f.column].MetaData.BloomFilterOffset = w.writer.offset
+		if _, err := w.writer.Write(f.data); err != nil {
+			return err
+		}
+	}
+
 	numRows := int64(0)
 	for rowGroupIndex := range w.rowGroups {
 		numRows += w.rowGroups[rowGroupIndex].NumRows
 	}
 
-	footer, err := thrift.Marshal(new(thrift.CompactProtocol), &format.FileMetaData{
+	keyValueMetadata := w.metadata
+	aggregated := w.aggregatedFileMetadata()
+	if len(w.sketches) > 0 || len(aggregated) > 0 {
+		keyValueMetadata = make([]format.KeyValue, 0, len(w.metadata)+len(w.sketches)+len(aggregated))
+		keyValueMetadata = append(keyValueMetadata, w.metadata...)
+		keyValueMetadata = append(keyValueMetadata, w.sketches...)
+		keyValueMetadata = append(keyValueMetadata, aggregated...)
+		sortKeyValueMetadata(keyValueMetadata)
+	}
+
+	if w.encryption != nil {
+		if err := w.encryptColumnMetadata(protocol); err != nil {
+			return err
+		}
+	}
+
+	metadata := &format.FileMetaData{
 		Version:          1,
 		Schema:           w.schemaElements,
 		NumRows:          numRows,
 		RowGroups:        w.rowGroups,
-		KeyValueMetadata: w.metadata,
+		KeyValueMetadata: keyValueMetadata,
 		CreatedBy:        w.createdBy,
 		ColumnOrders:     w.columnOrders,
-	})
+	}
+
+	var footer []byte
+	var err error
+	switch {
+	case w.encryption == nil:
+		footer, err = thrift.Marshal(protocol, metadata)
+	case w.encryption.config.PlaintextFooter:
+		footer, err = w.encryption.signFooter(protocol, metadata)
+	default:
+		footer, err = w.encryption.encryptFooter(protocol, metadata)
+	}
 	if err != nil {
 		return err
 	}
 
 	length := len(footer)
 	footer = append(footer, 0, 0, 0, 0)
-	footer = append(footer, "PAR1"...)
+	footer = append(footer, w.magic()...)
 	binary.LittleEndian.PutUint32(footer[length:], uint32(length))
 
 	_, err = w.writer.Write(footer)
 	return err
 }
 
+// writePageIndex writes the column or offset index of a column chunk, which is
+// encrypted as a module of the given type when the column is encrypted.
+func (w *writer) writePageIndex(encoder *thrift.Encoder, rowGroup, column int, index interface{}, module byte) error {
+	if c := w.columns[column]; c.encryption != nil {
+		_, err := c.writeEncryptedModule(&w.writer, index, module, rowGroup)
+		return err
+	}
+	return encoder.Encode(index)
+}
+
+// encryptColumnMetadata sets the crypto metadata of the encrypted column chunks
+// of all row groups. The metadata of columns encrypted with their own key are
+// encrypted with it, and removed from the footer.
+//
+// In plaintext footer mode, the metadata of all the encrypted columns are
+// encrypted, and the footer retains a copy without statistics, which lets
+// readers without keys locate the column chunks.
+func (w *writer) encryptColumnMetadata(protocol *thrift.CompactProtocol) error {
+	plaintextFooter := w.encryption.config.PlaintextFooter
+	for i := range w.rowGroups {
+		columns := w.rowGroups[i].Columns
+		for j, c := range w.columns {
+			e := c.encryption
+			if e == nil {
+				continue
+			}
+			column := &columns[j]
+			column.CryptoMetadata = e.metadata
+			if e.metadata.EncryptionWithColumnKey == nil && !plaintextFooter {
+				continue
+			}
+			metadata, err := thrift.Marshal(protocol, &column.MetaData)
+			if err != nil {
+				return err
+			}
+			if column.EncryptedColumnMetadata, err = e.encrypt(nil, metadata, columnMetaDataModule, i, -1); err != nil {
+				return err
+			}
+			if plaintextFooter {
+				column.MetaData.KeyValueMetadata = nil
+				column.MetaData.Statistics = format.Statistics{}
+				column.MetaData.EncodingStats = nil
+				column.MetaData.SizeStatistics = format.SizeStatistics{}
+			} else {
+				column.MetaData = format.ColumnMetaData{}
+			}
+		}
+	}
+	return nil
+}
+
+// aggregatedFileMetadata returns the key/value metadata contributed to the file
+// footer by the aggregators of the columns.
+func (w *writer) aggregatedFileMetadata() []format.KeyValue {
+	var keyValueMetadata []format.KeyValue
+	for _, c := range w.columns {
+		if c.aggregation.aggregator != nil {
+			keyValueMetadata = append(keyValueMetadata, makeKeyValueMetadata(c.aggregation.aggregator.FileMetadata())...)
+		}
+	}
+	return keyValueMetadata
+}
+
+// writeDistinctCountSketch adds the distinct count sketch of the column to the
+// metadata of the file. Dictionary encoded pages were not inserted in the
+// sketch when they were written, the values of the dictionary are inserted
+// instead.
+func (w *writer) writeDistinctCountSketch(columnIndex int, c *writerColumn) error {
+	if c.dictionary != nil {
+		if err := c.writePageToSketch(c.dictionary.Page()); err != nil {
+			return err
+		}
+		// The dictionary holds the distinct values of the row group, so the
+		// count is exact if all the data pages use it.
+		if isDictionaryEncoded(c.columnChunk.MetaData.EncodingStats) {
+			c.columnChunk.MetaData.Statistics.DistinctCount = int64(c.dictionary.Len())
+		}
+	}
+	b, err := c.sketch.hll.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	w.sketches = append(w.sketches, format.KeyValue{
+		Key:   distinctCountSketchKey(len(w.rowGroups), columnIndex),
+		Value: base64.StdEncoding.EncodeToString(b),
+	})
+	return nil
+}
+
+// writeBloomFilters writes the bloom filters of the columns of the row group
+// being flushed at the current offset of the output.
+func (w *writer) writeBloomFilters() error {
+	for _, c := range w.columns {
+		if len(c.filter.bits) > 0 {
+			c.columnChunk.MetaData.BloomFilterOffset = w.writer.offset
+			if err := c.writeBloomFilter(&w.writer); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (w *writer) writeRowGroup(rowGroupSchema *Schema, rowGroupSortingColumns []SortingColumn) (int64, error) {
 	numRows := w.columns[0].totalRowCount()
 	if numRows == 0 {
@@ -562,17 +856,22 @@ func (w *writer) writeRowGroup(rowGroupSchema *Schema, rowGroupSortingColumns []
 	}
 	fileOffset := w.writer.offset
 
-	for _, c := range w.columns {
-		if len(c.filter.bits) > 0 {
-			c.columnChunk.MetaData.BloomFilterOffset = w.writer.offset
-			if err := c.writeBloomFilter(&w.writer); err != nil {
-				return 0, err
-			}
+	if w.bloomFilterPlacement == BloomFiltersBeforeRowGroup {
+		if err := w.writeBloomFilters(); err != nil {
+			return 0, err
 		}
 	}
 
 	for i, c := range w.columns {
-		w.columnIndex[i] = format.ColumnIndex(c.columnIndex.ColumnIndex())
+		if c.omitColumnIndex {
+			w.columnIndex[i] = format.ColumnIndex{}
+		} else {
+			// The column index references buffers of the indexer which are
+			// reused by the next row group, and must be copied.
+			w.columnIndex[i] = copyColumnIndex(c.columnIndex.ColumnIndex())
+			w.columnIndex[i].RepetitionLevelHistograms = c.levelHistograms.repetition
+			w.columnIndex[i].DefinitionLevelHistograms = c.levelHistograms.definition
+		}
 
 		if c.dictionary != nil {
 			c.columnChunk.MetaData.DictionaryPageOffset = w.writer.offset
@@ -594,6 +893,40 @@ func (w *writer) writeRowGroup(rowGroupSchema *Schema, rowGroupSortingColumns []
 		}
 	}
 
+	switch w.bloomFilterPlacement {
+	case BloomFiltersAfterRowGroup:
+		if err := w.writeBloomFilters(); err != nil {
+			return 0, err
+		}
+	case BloomFiltersBeforeFooter:
+		// The filters are serialized to be written by writeFileFooter, their
+		// offsets are only known at that time.
+		for i, c := range w.columns {
+			if len(c.filter.bits) > 0 {
+				b := new(bytes.Buffer)
+				if err := c.writeBloomFilter(b); err != nil {
+					return 0, err
+				}
+				w.bloomFilters = append(w.bloomFilters, writerBloomFilter{
+					rowGroup: len(w.rowGroups),
+					column:   i,
+					data:     b.Bytes(),
+				})
+			}
+		}
+	}
+
+	for i, c := range w.columns {
+		if c.sketch.hll != nil {
+			if err := w.writeDistinctCountSketch(i, c); err != nil {
+				return 0, fmt.Errorf("writing distinct count sketch of row group column %d: %w", i, err)
+			}
+		}
+		if c.aggregation.aggregator != nil {
+			c.columnChunk.MetaData.KeyValueMetadata = makeKeyValueMetadata(c.aggregation.aggregator.RowGroupMetadata())
+		}
+	}
+
 	totalByteSize := int64(0)
 	totalCompressedSize := int64(0)
 
@@ -606,16 +939,25 @@ func (w *writer) writeRowGroup(rowGroupSchema *Schema, rowGroupSortingColumns []
 
 	sortingColumns := w.sortingColumns
 	if len(sortingColumns) == 0 && len(rowGroupSortingColumns) > 0 {
-		sortingColumns = make([]format.SortingColumn, 0, len(rowGroupSortingColumns))
+		sortingColumns = make([]format.SortingColumn, len(rowGroupSortingColumns))
+		found := make([]bool, len(rowGroupSortingColumns))
 		forEachLeafColumnOf(rowGroupSchema, func(leaf leafColumn) {
-			if sortingIndex := searchSortingColumn(rowGroupSortingColumns, leaf.path); sortingIndex < len(sortingColumns) {
+			if sortingIndex := searchSortingColumn(rowGroupSortingColumns, leaf.path); sortingIndex < len(sortingColumns) && sortingKeyOf(rowGroupSortingColumns[sortingIndex]) == nil {
 				sortingColumns[sortingIndex] = format.SortingColumn{
 					ColumnIdx:  int32(leaf.columnIndex),
 					Descending: rowGroupSortingColumns[sortingIndex].Descending(),
 					NullsFirst: rowGroupSortingColumns[sortingIndex].NullsFirst(),
 				}
+				found[sortingIndex] = true
 			}
 		})
+		// The order is only known up to the first sorting column which does
+		// not name a leaf column of the schema.
+		n := 0
+		for n < len(found) && found[n] {
+			n++
+		}
+		sortingColumns = sortingColumns[:n]
 	}
 
 	columns := make([]format.ColumnChunk, len(w.columnChunk))
@@ -639,9 +981,36 @@ func (w *writer) writeRowGroup(rowGroupSchema *Schema, rowGroupSortingColumns []
 
 	w.columnIndexes = append(w.columnIndexes, columnIndex)
 	w.offsetIndexes = append(w.offsetIndexes, offsetIndex)
+	if w.encryption != nil {
+		w.encryption.rowGroup = len(w.rowGroups)
+	}
+
+	if w.onFlush != nil {
+		w.onFlush(newRowGroupStats(&w.rowGroups[len(w.rowGroups)-1]))
+	}
 	return numRows, nil
 }
 
+func newRowGroupStats(rowGroup *format.RowGroup) *RowGroupStats {
+	stats := &RowGroupStats{
+		RowGroup: int(rowGroup.Ordinal),
+		NumRows:  rowGroup.NumRows,
+		Columns:  make([]ColumnChunkStats, len(rowGroup.Columns)),
+	}
+	for i := range rowGroup.Columns {
+		metadata := &rowGroup.Columns[i].MetaData
+		stats.Columns[i] = ColumnChunkStats{
+			Path:             append([]string(nil), metadata.PathInSchema...),
+			Codec:            metadata.Codec,
+			Encodings:        append([]format.Encoding(nil), metadata.Encoding...),
+			NumValues:        metadata.NumValues,
+			UncompressedSize: metadata.TotalUncompressedSize,
+			CompressedSize:   metadata.TotalCompressedSize,
+		}
+	}
+	return stats
+}
+
 func (w *writer) WriteRows(rows []Row) (int, error) {
 	defer func() {
 		for i, values := range w.values {
@@ -780,6 +1149,12 @@ func (wb *writerBuffers) swapPageAndScratchBuffers() {
 	wb.page, wb.scratch = wb.scratch, wb.page[:0]
 }
 
+// restoreUncompressedPage reverts the last call to compress, the uncompressed
+// page of the given size is still held in the scratch buffer.
+func (wb *writerBuffers) restoreUncompressedPage(size int) {
+	wb.page, wb.scratch = wb.scratch[:size], wb.page[:0]
+}
+
 type writerColumn struct {
 	pool  PageBufferPool
 	pages []io.ReadWriter
@@ -790,6 +1165,7 @@ type writerColumn struct {
 	columnBuffer ColumnBuffer
 	columnFilter BloomFilterColumn
 	compression  compress.Codec
+	compressors  *CompressionPool
 	dictionary   Dictionary
 
 	dataPageType       format.PageType
@@ -804,12 +1180,34 @@ type writerColumn struct {
 	}
 
 	page struct {
-		encoding encoding.Encoding
+		encoding  encoding.Encoding
+		fallbacks []encoding.Encoding
 	}
 
 	filter struct {
-		bits  []byte
-		pages []BufferedPage
+		bits        []byte
+		pages       []BufferedPage
+		concurrency int
+	}
+
+	sketch struct {
+		hll    *hyperloglog.Sketch
+		hashes []byte
+	}
+
+	// Custom aggregator of the column configured with the ColumnAggregators
+	// option, and the buffer of values passed to it.
+	aggregation struct {
+		column     AggregatorColumn
+		aggregator ColumnAggregator
+		values     []Value
+	}
+
+	// Level histograms of the pages of the column chunk, concatenated in the
+	// order of the pages for the column index.
+	levelHistograms struct {
+		repetition []int64
+		definition []int64
 	}
 
 	numRows        int64
@@ -818,11 +1216,28 @@ type writerColumn struct {
 	bufferIndex    int32
 	bufferSize     int32
 	writePageStats bool
-	isCompressed   bool
-	encodings      []format.Encoding
+	// Size limit of the byte array bounds of the column index, which also
+	// applies to the page statistics.
+	columnIndexLimit int
+	validateUTF8     bool
+	utf8             utf8Validator
+	isCompressed     bool
+	encodings        []format.Encoding
+	adaptive         *adaptiveEncoding
+
+	// Set when a page of the current column chunk could not be represented
+	// in the column index.
+	omitColumnIndex bool
+
+	// Ratio of compressed to uncompressed size of data pages above which
+	// they are stored uncompressed, or zero to always compress.
+	compressThreshold float64
 
 	columnChunk *format.ColumnChunk
 	offsetIndex *format.OffsetIndex
+
+	// Set when the column is encrypted.
+	encryption *columnEncryption
 }
 
 func (c *writerColumn) reset() {
@@ -832,9 +1247,11 @@ func (c *writerColumn) reset() {
 	if c.columnIndex != nil {
 		c.columnIndex.Reset()
 	}
+	c.omitColumnIndex = false
 	if c.dictionary != nil {
 		c.dictionary.Reset()
 	}
+	c.utf8.reset()
 	for _, page := range c.pages {
 		c.pool.PutPageBuffer(page)
 	}
@@ -849,6 +1266,9 @@ func (c *writerColumn) reset() {
 	// buffer to avoid reallocating large memory blocks.
 	c.filter.bits = c.filter.bits[:0]
 	c.filter.pages = c.filter.pages[:0]
+	if c.sketch.hll != nil {
+		c.sketch.hll.Reset()
+	}
 	c.numRows = 0
 	c.numValues = 0
 	// Reset the fields of column chunks that change between row groups,
@@ -861,10 +1281,37 @@ func (c *writerColumn) reset() {
 	c.columnChunk.MetaData.Statistics = format.Statistics{}
 	c.columnChunk.MetaData.EncodingStats = make([]format.PageEncodingStats, 0, cap(c.columnChunk.MetaData.EncodingStats))
 	c.columnChunk.MetaData.BloomFilterOffset = 0
+	c.columnChunk.MetaData.SizeStatistics = format.SizeStatistics{}
+	c.columnChunk.MetaData.KeyValueMetadata = nil
 	// Retain the previous capacity in the new page locations array, assuming
 	// the number of pages should be roughly the same between row groups written
 	// by the writer.
 	c.offsetIndex.PageLocations = make([]format.PageLocation, 0, cap(c.offsetIndex.PageLocations))
+	c.offsetIndex.UnencodedByteArrayDataBytes = nil
+	c.levelHistograms.repetition = nil
+	c.levelHistograms.definition = nil
+
+	if a := c.adaptive; a != nil {
+		// Columns with adaptive encoding select the encodings again for each
+		// column chunk, which requires restoring their initial state.
+		if c.dictionary != nil {
+			c.dictionary = nil
+			c.columnBuffer = nil
+		}
+		c.columnType = a.columnType
+		c.page.encoding = a.encoding
+		c.page.fallbacks = a.fallbacks
+		c.isCompressed = a.isCompressed
+		c.encodings = a.encodings
+		c.columnChunk.MetaData.Encoding = a.encodings
+	}
+}
+
+func (c *writerColumn) compress(buf *writerBuffers) error {
+	if c.compressors == nil {
+		return buf.compress(c.compression)
+	}
+	return c.compressors.do(func() error { return buf.compress(c.compression) })
 }
 
 func (c *writerColumn) totalRowCount() int64 {
@@ -889,11 +1336,14 @@ func (c *writerColumn) flush() (err error) {
 }
 
 func (c *writerColumn) flushFilterPages() error {
+	if _, ok := c.columnFilter.(splitBlockFilter); ok && c.filter.concurrency > 1 {
+		return c.flushFilterPagesParallel()
+	}
 	if c.columnFilter != nil {
 		// If there is a dictionary, it contains all the values that we need to
 		// write to the filter.
 		if dict := c.dictionary; dict != nil {
-			if c.filter.bits == nil {
+			if len(c.filter.bits) == 0 {
 				c.resizeBloomFilter(int64(dict.Len()))
 			}
 			if err := c.writePageToFilter(dict.Page()); err != nil {
@@ -917,9 +1367,67 @@ func (c *writerColumn) flushFilterPages() error {
 	return nil
 }
 
+// flushFilterPagesParallel builds the split block bloom filter of the column
+// from the dictionary and the buffered pages using multiple goroutines. Pages
+// are split in batches of rows which are hashed concurrently, then the hashes
+// are inserted in the filter by goroutines owning disjoint ranges of blocks.
+func (c *writerColumn) flushFilterPagesParallel() error {
+	pages := c.filter.pages
+	if dict := c.dictionary; dict != nil {
+		pages = append([]BufferedPage{dict.Page()}, pages...)
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+
+	numValues := int64(0)
+	batches := make([]BufferedPage, 0, len(pages))
+	for _, page := range pages {
+		numValues += page.NumValues()
+		numRows := page.NumRows()
+		for i := int64(0); i < numRows; i += filterHashBatchSize {
+			j := i + filterHashBatchSize
+			if j > numRows {
+				j = numRows
+			}
+			batches = append(batches, page.Slice(i, j))
+		}
+	}
+	c.resizeBloomFilter(numValues)
+
+	hashes := make([][]byte, len(batches))
+	errs := make([]error, len(batches))
+	sem := make(chan struct{}, c.filter.concurrency)
+	wg := sync.WaitGroup{}
+
+	for i, batch := range batches {
+		i, batch := i, batch
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			hashes[i], errs[i] = batch.Type().Encode(nil, batch.Data(), splitBlockHashEncoding{})
+		}()
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	values := make([]uint64, 0, numValues)
+	for _, h := range hashes {
+		values = append(values, unsafecast.BytesToUint64(h)...)
+	}
+	bloom.MakeSplitBlockFilter(c.filter.bits).InsertBulkParallel(values, c.filter.concurrency)
+	return nil
+}
+
 func (c *writerColumn) resizeBloomFilter(numValues int64) {
-	const bitsPerValue = 10 // TODO: make this configurable
-	filterSize := c.columnFilter.Size(numValues, bitsPerValue)
+	filterSize := c.columnFilter.Size(numValues, bloomFilterBitsPerValue)
 	if cap(c.filter.bits) < filterSize {
 		c.filter.bits = make([]byte, filterSize)
 	} else {
@@ -1037,10 +1545,29 @@ func (c *writerColumn) writePageValues(page ValueReader) (numValues int64, err e
 	return numValues, err
 }
 
+func (c *writerColumn) writePageToSketch(page BufferedPage) (err error) {
+	c.sketch.hashes, err = page.Type().Encode(c.sketch.hashes[:0], page.Data(), splitBlockHashEncoding{})
+	if err == nil {
+		c.sketch.hll.InsertBulk(unsafecast.BytesToUint64(c.sketch.hashes))
+	}
+	return err
+}
+
 func (c *writerColumn) writeBloomFilter(w io.Writer) error {
-	e := thrift.NewEncoder(c.header.protocol.NewWriter(w))
 	h := bloomFilterHeader(c.columnFilter)
 	h.NumBytes = int32(len(c.filter.bits))
+	if e := c.encryption; e != nil {
+		if _, err := c.writeEncryptedModule(w, &h, bloomFilterHeaderModule, -1); err != nil {
+			return err
+		}
+		var err error
+		if e.buffer, err = e.encrypt(e.buffer[:0], c.filter.bits, bloomFilterBitsetModule, -1, -1); err != nil {
+			return err
+		}
+		_, err = w.Write(e.buffer)
+		return err
+	}
+	e := thrift.NewEncoder(c.header.protocol.NewWriter(w))
 	if err := e.Encode(&h); err != nil {
 		return err
 	}
@@ -1054,6 +1581,25 @@ func (c *writerColumn) writeBufferedPage(page BufferedPage) (int64, error) {
 		return 0, nil
 	}
 
+	if c.validateUTF8 {
+		if err := c.utf8.validate(page); err != nil {
+			return 0, fmt.Errorf("writing page of column %q at rows [%d:+%d]: %w", c.columnPath, c.numRows, page.NumRows(), err)
+		}
+	}
+
+	if c.adaptive != nil && c.dictionary == nil && page.Dictionary() == nil {
+		indexedPage, err := c.selectPageEncoding(page)
+		if err != nil {
+			return 0, err
+		}
+		if indexedPage != nil {
+			// The column was converted to use dictionary encoding, the page
+			// of indexes is held in the new column buffer.
+			defer c.columnBuffer.Reset()
+			page = indexedPage
+		}
+	}
+
 	buf := c.buffers
 	buf.reset()
 
@@ -1064,18 +1610,42 @@ func (c *writerColumn) writeBufferedPage(page BufferedPage) (int64, error) {
 		buf.encodeDefinitionLevels(page, c.maxDefinitionLevel)
 	}
 
-	if err := buf.encode(page, c.page.encoding); err != nil {
+	err := buf.encode(page, c.page.encoding)
+	for err != nil && len(c.page.fallbacks) > 0 {
+		// The encoding could not encode the values, fall back to the next
+		// preferred encoding for this page and the rest of the column.
+		c.page.encoding, c.page.fallbacks = c.page.fallbacks[0], c.page.fallbacks[1:]
+		c.addChunkEncoding(c.page.encoding.Encoding())
+		err = buf.encode(page, c.page.encoding)
+	}
+	if err != nil {
 		return 0, fmt.Errorf("encoding parquet data page: %w", err)
 	}
 	if c.dataPageType == format.DataPage {
-		buf.prependLevelsToDataPageV1(c.maxDefinitionLevel, c.maxDefinitionLevel)
+		buf.prependLevelsToDataPageV1(c.maxRepetitionLevel, c.maxDefinitionLevel)
 	}
 
 	uncompressedPageSize := buf.size()
-	if c.isCompressed {
-		if err := buf.compress(c.compression); err != nil {
+	isCompressed := c.isCompressed
+	if isCompressed {
+		pageSize := len(buf.page)
+		if err := c.compress(buf); err != nil {
 			return 0, fmt.Errorf("compressing parquet data page: %w", err)
 		}
+		// Data pages in version 2 record whether they are compressed, which
+		// allows storing the page uncompressed when compression did not make
+		// it small enough to be worth decompressing when reading.
+		if c.dataPageType == format.DataPageV2 && c.compressThreshold > 0 &&
+			float64(len(buf.page)) > c.compressThreshold*float64(pageSize) {
+			buf.restoreUncompressedPage(pageSize)
+			isCompressed = false
+		}
+	}
+
+	if page.Dictionary() == nil && c.sketch.hll != nil {
+		if err := c.writePageToSketch(page); err != nil {
+			return 0, err
+		}
 	}
 
 	if page.Dictionary() == nil {
@@ -1130,44 +1700,163 @@ func (c *writerColumn) writeBufferedPage(page BufferedPage) (int64, error) {
 			Encoding:                   c.page.encoding.Encoding(),
 			DefinitionLevelsByteLength: int32(len(buf.definitions)),
 			RepetitionLevelsByteLength: int32(len(buf.repetitions)),
-			IsCompressed:               &c.isCompressed,
+			IsCompressed:               &isCompressed,
 			Statistics:                 statistics,
 		}
 	}
 
-	buf.header.Reset()
-	if err := c.header.encoder.Encode(pageHeader); err != nil {
-		return 0, err
-	}
-
-	size := int64(buf.header.Len()) +
-		int64(len(buf.repetitions)) +
-		int64(len(buf.definitions)) +
-		int64(len(buf.page))
-
-	err := c.writePage(size, func(output io.Writer) (written int64, err error) {
-		for _, data := range [...][]byte{
-			buf.header.Bytes(),
-			buf.repetitions,
-			buf.definitions,
-			buf.page,
-		} {
-			wn, err := output.Write(data)
-			written += int64(wn)
-			if err != nil {
-				return written, err
-			}
+	var headerSize int32
+	if c.encryption != nil {
+		headerSize, err = c.writeEncryptedPage(pageHeader, buf.repetitions, buf.definitions, buf.page)
+	} else {
+		buf.header.Reset()
+		if err := c.header.encoder.Encode(pageHeader); err != nil {
+			return 0, err
 		}
-		return written, nil
-	})
+		headerSize = int32(buf.header.Len())
+
+		size := int64(buf.header.Len()) +
+			int64(len(buf.repetitions)) +
+			int64(len(buf.definitions)) +
+			int64(len(buf.page))
+
+		err = c.writePage(size, func(output io.Writer) (written int64, err error) {
+			for _, data := range [...][]byte{
+				buf.header.Bytes(),
+				buf.repetitions,
+				buf.definitions,
+				buf.page,
+			} {
+				wn, err := output.Write(data)
+				written += int64(wn)
+				if err != nil {
+					return written, err
+				}
+			}
+			return written, nil
+		})
+	}
 	if err != nil {
 		return 0, err
 	}
 
-	c.recordPageStats(int32(buf.header.Len()), pageHeader, page)
+	if c.aggregation.aggregator != nil {
+		if err := c.observePage(page); err != nil {
+			return 0, err
+		}
+	}
+	c.recordPageStats(headerSize, pageHeader, page)
 	return numValues, nil
 }
 
+// adaptiveEncoding holds the state of columns written with the AdaptiveEncoding
+// option, which select the encoding of each page based on the size of the
+// encoded values.
+type adaptiveEncoding struct {
+	columnType   Type
+	encoding     encoding.Encoding
+	fallbacks    []encoding.Encoding
+	encodings    []format.Encoding
+	candidates   []encoding.Encoding
+	isCompressed bool
+}
+
+// Table of encodings considered by columns with adaptive encoding, the first
+// one supporting the column type is selected when encodings produce outputs of
+// the same size.
+var adaptiveEncodings = [...]encoding.Encoding{
+	&Plain,
+	&DeltaBinaryPacked,
+	&DeltaLengthByteArray,
+	&DeltaByteArray,
+	&ByteStreamSplit,
+}
+
+func newAdaptiveEncoding(c *writerColumn, preferredEncodings []encoding.Encoding) *adaptiveEncoding {
+	kind := c.columnType.Kind()
+	if kind == Boolean {
+		// Boolean values are bit-packed with the PLAIN encoding, there are
+		// no alternatives worth considering.
+		return nil
+	}
+	a := &adaptiveEncoding{
+		columnType:   c.columnType,
+		encoding:     c.page.encoding,
+		fallbacks:    c.page.fallbacks,
+		isCompressed: c.isCompressed,
+	}
+	candidates := adaptiveEncodings[:]
+	if len(preferredEncodings) > 0 {
+		candidates = preferredEncodings
+	}
+	for _, enc := range candidates {
+		if CanEncode(enc, kind) && !isDictionaryEncoding(enc) {
+			a.candidates = append(a.candidates, enc)
+		}
+	}
+	return a
+}
+
+// selectPageEncoding sets the encoding of the column to the candidate producing
+// the smallest output for the values of page.
+//
+// On the first page of a column chunk, the method also estimates the size of
+// the values when dictionary encoded. If the dictionary is smaller, the column
+// is converted to use dictionary encoding for the rest of the column chunk, and
+// the method returns the page of dictionary indexes to write instead of page.
+func (c *writerColumn) selectPageEncoding(page BufferedPage) (BufferedPage, error) {
+	buf := c.buffers
+	bestEncoding, bestSize := c.page.encoding, math.MaxInt
+
+	for _, enc := range c.adaptive.candidates {
+		// Encodings which fail to encode the values are not selected, the page
+		// is written with the default encoding of the column if all of them
+		// failed.
+		if err := buf.encode(page, enc); err == nil && len(buf.page) < bestSize {
+			bestEncoding, bestSize = enc, len(buf.page)
+		}
+	}
+
+	if c.numRows == 0 {
+		columnType := c.adaptive.columnType
+		dictionary := columnType.NewDictionary(int(c.bufferIndex), 0, make([]byte, 0, defaultDictBufferSize))
+		c.columnType = dictionary.Type()
+		buffer := c.newColumnBuffer()
+
+		if _, err := CopyValues(buffer, page.Values()); err != nil {
+			c.columnType = columnType
+			return nil, fmt.Errorf("indexing values of parquet data page: %w", err)
+		}
+
+		// The estimated size accounts for the PLAIN encoded dictionary page
+		// and the bit-packed indexes; the run-length encoding of indexes can
+		// only produce smaller outputs.
+		indexedPage := buffer.Page()
+		numIndexes := indexedPage.NumValues() - indexedPage.NumNulls()
+		bitWidth := int64(bits.Len32(uint32(dictionary.Len())))
+		dictionarySize := len(dictionary.Page().Data()) + int((numIndexes*bitWidth+7)/8)
+
+		if dictionarySize < bestSize {
+			c.dictionary = dictionary
+			c.columnBuffer = buffer
+			c.maxValues = int32(buffer.Cap())
+			c.page.encoding = &RLEDictionary
+			// Same as when the column is configured with a dictionary, data
+			// pages in version 2 are not compressed.
+			c.isCompressed = c.adaptive.isCompressed && c.dataPageType != format.DataPageV2
+			c.addChunkEncoding(format.Plain)
+			c.addChunkEncoding(format.RLEDictionary)
+			return indexedPage, nil
+		}
+
+		c.columnType = columnType
+	}
+
+	c.page.encoding = bestEncoding
+	c.addChunkEncoding(bestEncoding.Encoding())
+	return nil, nil
+}
+
 func (c *writerColumn) writeCompressedPage(page CompressedPage) (int64, error) {
 	if page.Dictionary() == nil {
 		switch {
@@ -1207,26 +1896,49 @@ func (c *writerColumn) writeCompressedPage(page CompressedPage) (int64, error) {
 		return 0, fmt.Errorf("writing compressed page type of unknown type: %s", h.PageType())
 	}
 
-	header := &c.buffers.header
-	header.Reset()
-	if err := c.header.encoder.Encode(pageHeader); err != nil {
-		return 0, err
-	}
-	headerSize := int32(header.Len())
-	compressedSize := int64(headerSize + pageHeader.CompressedPageSize)
-
-	err := c.writePage(compressedSize, func(output io.Writer) (int64, error) {
-		headerSize, err := header.WriteTo(output)
-		if err != nil {
-			return headerSize, err
+	headerSize, err := int32(0), error(nil)
+	if c.encryption != nil {
+		var data []byte
+		if data, err = io.ReadAll(page.PageData()); err != nil {
+			return 0, err
 		}
-		dataSize, err := io.Copy(output, page.PageData())
-		return headerSize + dataSize, err
-	})
+		headerSize, err = c.writeEncryptedPage(pageHeader, data)
+	} else {
+		header := &c.buffers.header
+		header.Reset()
+		if err := c.header.encoder.Encode(pageHeader); err != nil {
+			return 0, err
+		}
+		headerSize = int32(header.Len())
+		compressedSize := int64(headerSize + pageHeader.CompressedPageSize)
+
+		err = c.writePage(compressedSize, func(output io.Writer) (int64, error) {
+			headerSize, err := header.WriteTo(output)
+			if err != nil {
+				return headerSize, err
+			}
+			dataSize, err := io.Copy(output, page.PageData())
+			return headerSize + dataSize, err
+		})
+	}
 	if err != nil {
 		return 0, err
 	}
+	if c.aggregation.aggregator != nil {
+		if err := c.observePage(page); err != nil {
+			return 0, err
+		}
+	}
 	c.recordPageStats(headerSize, pageHeader, page)
+
+	if c.adaptive != nil {
+		switch {
+		case pageHeader.DataPageHeader != nil:
+			c.addChunkEncoding(pageHeader.DataPageHeader.Encoding)
+		case pageHeader.DataPageHeaderV2 != nil:
+			c.addChunkEncoding(pageHeader.DataPageHeaderV2.Encoding)
+		}
+	}
 	return page.NumValues(), nil
 }
 
@@ -1240,7 +1952,7 @@ func (c *writerColumn) writeDictionaryPage(output io.Writer, dict Dictionary) (e
 
 	uncompressedPageSize := buf.size()
 	if isCompressed(c.compression) {
-		if err := buf.compress(c.compression); err != nil {
+		if err := c.compress(buf); err != nil {
 			return fmt.Errorf("copmressing parquet dictionary page: %w", err)
 		}
 	}
@@ -1257,18 +1969,25 @@ func (c *writerColumn) writeDictionaryPage(output io.Writer, dict Dictionary) (e
 		},
 	}
 
-	header := &c.buffers.header
-	header.Reset()
-	if err := c.header.encoder.Encode(pageHeader); err != nil {
-		return err
+	header, data := []byte(nil), buf.page
+	if c.encryption != nil {
+		if header, data, err = c.encryptPage(pageHeader, buf.page); err != nil {
+			return err
+		}
+	} else {
+		c.buffers.header.Reset()
+		if err := c.header.encoder.Encode(pageHeader); err != nil {
+			return err
+		}
+		header = c.buffers.header.Bytes()
 	}
-	if _, err := output.Write(header.Bytes()); err != nil {
+	if _, err := output.Write(header); err != nil {
 		return err
 	}
-	if _, err := output.Write(buf.page); err != nil {
+	if _, err := output.Write(data); err != nil {
 		return err
 	}
-	c.recordPageStats(int32(header.Len()), pageHeader, nil)
+	c.recordPageStats(int32(len(header)), pageHeader, nil)
 	return nil
 }
 
@@ -1299,15 +2018,64 @@ func (c *writerColumn) writePage(size int64, writeTo func(io.Writer) (int64, err
 
 func (c *writerColumn) makePageStatistics(page Page) format.Statistics {
 	numNulls := page.NumNulls()
-	minValue, maxValue, _ := page.Bounds()
+	minValue, maxValue, ok := page.Bounds()
 	minValueBytes := minValue.Bytes()
 	maxValueBytes := maxValue.Bytes()
-	return format.Statistics{
-		Min:       minValueBytes, // deprecated
-		Max:       maxValueBytes, // deprecated
+	statistics := format.Statistics{
 		NullCount: numNulls,
-		MinValue:  minValueBytes,
-		MaxValue:  maxValueBytes,
+	}
+	if ok {
+		// Byte array bounds are truncated the same way as in the column
+		// index, the flags let readers know that they are not exact.
+		minExact, maxExact := true, true
+		switch c.columnType.Kind() {
+		case ByteArray, FixedLenByteArray:
+			if c.columnIndexLimit > 0 {
+				truncatedMin := truncateLargeMinByteArrayValue(minValueBytes, c.columnIndexLimit)
+				truncatedMax := truncateLargeMaxByteArrayValue(maxValueBytes, c.columnIndexLimit)
+				minExact = len(truncatedMin) == len(minValueBytes)
+				maxExact = len(truncatedMax) == len(maxValueBytes)
+				minValueBytes, maxValueBytes = truncatedMin, truncatedMax
+			}
+		}
+		statistics.IsMinValueExact = &minExact
+		statistics.IsMaxValueExact = &maxExact
+	}
+	statistics.Min = minValueBytes // deprecated
+	statistics.Max = maxValueBytes // deprecated
+	statistics.MinValue = minValueBytes
+	statistics.MaxValue = maxValueBytes
+	return statistics
+}
+
+// recordPageSizeStats records the size statistics of the page in the column
+// chunk metadata, and in the offset and column indexes.
+func (c *writerColumn) recordPageSizeStats(page Page) {
+	stats := &c.columnChunk.MetaData.SizeStatistics
+
+	if c.columnType.Kind() == ByteArray {
+		size := unencodedByteArrayDataBytes(page)
+		stats.UnencodedByteArrayDataBytes += size
+		c.offsetIndex.UnencodedByteArrayDataBytes = append(c.offsetIndex.UnencodedByteArrayDataBytes, size)
+	}
+
+	if c.maxRepetitionLevel == 0 && c.maxDefinitionLevel == 0 {
+		return
+	}
+	// Buffered pages return themselves, only compressed pages need to be
+	// decoded to access their levels.
+	buffered := page.Buffer()
+
+	if c.maxRepetitionLevel > 0 {
+		offset := len(c.levelHistograms.repetition)
+		c.levelHistograms.repetition = appendLevelHistogram(c.levelHistograms.repetition, buffered.RepetitionLevels(), c.maxRepetitionLevel)
+		stats.RepetitionLevelHistogram = addLevelHistogram(stats.RepetitionLevelHistogram, c.levelHistograms.repetition[offset:])
+	}
+
+	if c.maxDefinitionLevel > 0 {
+		offset := len(c.levelHistograms.definition)
+		c.levelHistograms.definition = appendLevelHistogram(c.levelHistograms.definition, buffered.DefinitionLevels(), c.maxDefinitionLevel)
+		stats.DefinitionLevelHistogram = addLevelHistogram(stats.DefinitionLevelHistogram, c.levelHistograms.definition[offset:])
 	}
 }
 
@@ -1318,9 +2086,16 @@ func (c *writerColumn) recordPageStats(headerSize int32, header *format.PageHead
 	if page != nil {
 		numNulls := page.NumNulls()
 		numValues := page.NumValues()
-		minValue, maxValue, _ := page.Bounds()
+		minValue, maxValue, ok := page.Bounds()
+		if !ok && numValues > numNulls {
+			// The page has values but no bounds, which happens when all the
+			// values of a floating point page are NaN. The column index of
+			// the chunk cannot represent the page and must not be written.
+			c.omitColumnIndex = true
+		}
 		c.columnIndex.IndexPage(numValues, numNulls, minValue, maxValue)
 		c.columnChunk.MetaData.NumValues += numValues
+		c.recordPageSizeStats(page)
 
 		c.offsetIndex.PageLocations = append(c.offsetIndex.PageLocations, format.PageLocation{
 			Offset:             c.columnChunk.MetaData.TotalCompressedSize,
@@ -1351,6 +2126,19 @@ func (c *writerColumn) recordPageStats(headerSize int32, header *format.PageHead
 	})
 }
 
+// addChunkEncoding adds enc to the list of encodings of the current column
+// chunk. The list is copied when modified since it is shared with the metadata
+// of row groups which have already been written.
+func (c *writerColumn) addChunkEncoding(enc format.Encoding) {
+	n := len(c.encodings)
+	encodings := addEncoding(c.encodings[:n:n], enc)
+	if len(encodings) != n {
+		sortPageEncodings(encodings)
+		c.encodings = encodings
+		c.columnChunk.MetaData.Encoding = encodings
+	}
+}
+
 func addEncoding(encodings []format.Encoding, add format.Encoding) []format.Encoding {
 	for _, enc := range encodings {
 		if enc == add {
@@ -0,0 +1,137 @@
+package parquet
+
+import (
+	"io"
+	"sync"
+)
+
+// WriterPool is a pool of writers sharing the same configuration, which allows
+// programs producing many parquet files to reuse the writers, their column
+// buffers, dictionaries, and page buffers instead of constructing them for
+// each file.
+//
+// Writers are pooled by the fingerprint of their schema, a writer returned to
+// the pool is only reused for schemas with the same columns, types, encodings,
+// and compression codecs. The schemas do not need to be the same values, for
+// example the schemas of different Go types with the same parquet layout share
+// the same writers.
+//
+// WriterPool values are safe to use concurrently from multiple goroutines.
+type WriterPool struct {
+	config *WriterConfig
+	pools  sync.Map // map[string]*sync.Pool
+}
+
+// NewWriterPool constructs a pool of writers configured with the options
+// passed as arguments. The schema of the writers is given when getting them
+// from the pool, a schema set in the options is ignored.
+//
+// The function panics if the writer configuration is invalid, see NewWriter
+// for details.
+func NewWriterPool(options ...WriterOption) *WriterPool {
+	config, err := NewWriterConfig(options...)
+	if err != nil {
+		panic(err)
+	}
+	config.Schema = nil
+	return &WriterPool{config: config}
+}
+
+// Get returns a writer of the given schema writing to output, which is either
+// taken from the pool or constructed if the pool held no writers for the
+// schema.
+func (p *WriterPool) Get(output io.Writer, schema *Schema) *Writer {
+	if w, _ := p.poolOf(schema).Get().(*Writer); w != nil {
+		w.Reset(output)
+		// The writer may have been constructed from a different schema with
+		// the same fingerprint, rows are deconstructed with the schema of the
+		// caller in case it was created from a different Go type.
+		w.schema, w.config.Schema = schema, schema
+		return w
+	}
+	config := *p.config
+	config.Schema = schema
+	return NewWriter(output, &config)
+}
+
+// Put returns w to the pool. The writer is reset, the program must not use it
+// after calling Put, and must have closed it if the file it was writing had to
+// be completed.
+func (p *WriterPool) Put(w *Writer) {
+	if w != nil && w.schema != nil {
+		w.Reset(nil)
+		p.poolOf(w.schema).Put(w)
+	}
+}
+
+func (p *WriterPool) poolOf(schema *Schema) *sync.Pool {
+	return schemaPoolOf(&p.pools, schema)
+}
+
+// BufferPool is a pool of buffers sharing the same configuration, which allows
+// programs buffering rows of many row groups to reuse the buffers, their column
+// buffers, and dictionaries instead of constructing them for each row group.
+//
+// Like writers in a WriterPool, buffers are pooled by the fingerprint of their
+// schema.
+//
+// BufferPool values are safe to use concurrently from multiple goroutines.
+type BufferPool struct {
+	config *RowGroupConfig
+	pools  sync.Map // map[string]*sync.Pool
+}
+
+// NewBufferPool constructs a pool of buffers configured with the options passed
+// as arguments. The schema of the buffers is given when getting them from the
+// pool, a schema set in the options is ignored.
+//
+// The function panics if the buffer configuration is invalid, see NewBuffer
+// for details.
+func NewBufferPool(options ...RowGroupOption) *BufferPool {
+	config, err := NewRowGroupConfig(options...)
+	if err != nil {
+		panic(err)
+	}
+	config.Schema = nil
+	return &BufferPool{config: config}
+}
+
+// Get returns an empty buffer of the given schema, which is either taken from
+// the pool or constructed if the pool held no buffers for the schema.
+func (p *BufferPool) Get(schema *Schema) *Buffer {
+	if buf, _ := p.poolOf(schema).Get().(*Buffer); buf != nil {
+		buf.schema, buf.config.Schema = schema, schema
+		return buf
+	}
+	config := *p.config
+	config.Schema = schema
+	return NewBuffer(&config)
+}
+
+// Put returns buf to the pool. The buffer is reset, including the dictionaries
+// of its columns, the program must not use it or the row groups and pages it
+// returned after calling Put.
+func (p *BufferPool) Put(buf *Buffer) {
+	if buf != nil && buf.schema != nil {
+		buf.Reset()
+		for _, column := range buf.columns {
+			if dict := column.Page().Dictionary(); dict != nil {
+				dict.Reset()
+			}
+		}
+		p.poolOf(buf.schema).Put(buf)
+	}
+}
+
+func (p *BufferPool) poolOf(schema *Schema) *sync.Pool {
+	return schemaPoolOf(&p.pools, schema)
+}
+
+func schemaPoolOf(pools *sync.Map, schema *Schema) *sync.Pool {
+	fingerprint := schema.fingerprint()
+	pool, ok := pools.Load(fingerprint)
+	if !ok {
+		pool, _ = pools.LoadOrStore(fingerprint, new(sync.Pool))
+	}
+	return pool.(*sync.Pool)
+}
@@ -0,0 +1,118 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func TestWriterPool(t *testing.T) {
+	type RowA struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name,dict"`
+	}
+	type RowC struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+	// The schemas A and B have the same layout, writers are shared between
+	// them, while the schema C uses a different encoding.
+	schemaA := parquet.SchemaOf(new(RowA))
+	schemaB := parquet.NewSchema("RowA", parquet.Group{
+		"id":   parquet.Int(64),
+		"name": parquet.Encoded(parquet.String(), &parquet.RLEDictionary),
+	})
+	schemaC := parquet.SchemaOf(new(RowC))
+
+	pool := parquet.NewWriterPool(parquet.PageBufferSize(256))
+
+	for i := 0; i < 10; i++ {
+		for _, schema := range []*parquet.Schema{schemaA, schemaB, schemaC} {
+			output := new(bytes.Buffer)
+			w := pool.Get(output, schema)
+			if w.Schema() != schema {
+				t.Fatal("the writer returned by the pool does not have the requested schema")
+			}
+
+			want := []RowA{}
+			for j := 0; j < 100; j++ {
+				row := RowA{ID: int64(100*i + j), Name: fmt.Sprintf("name-%d-%d", i, j%10)}
+				want = append(want, row)
+				var err error
+				switch schema {
+				case schemaA:
+					err = w.Write(&row)
+				case schemaB:
+					_, err = w.WriteRows([]parquet.Row{schemaA.Deconstruct(nil, &row)})
+				case schemaC:
+					err = w.Write(&RowC{ID: row.ID, Name: row.Name})
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			pool.Put(w)
+
+			r := parquet.NewReader(bytes.NewReader(output.Bytes()), schemaA)
+			for j := range want {
+				got := RowA{}
+				if err := r.Read(&got); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, want[j]) {
+					t.Fatalf("row %d mismatch in file %d: want=%+v got=%+v", j, i, want[j], got)
+				}
+			}
+		}
+	}
+}
+
+func TestBufferPool(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name,dict"`
+	}
+	schema := parquet.SchemaOf(new(Row))
+	pool := parquet.NewBufferPool(parquet.SortingColumns(parquet.Descending("id")))
+
+	for i := 0; i < 10; i++ {
+		buffer := pool.Get(schema)
+		if buffer.NumRows() != 0 {
+			t.Fatalf("the buffer returned by the pool is not empty: %d rows", buffer.NumRows())
+		}
+		for j := 0; j < 10; j++ {
+			if err := buffer.Write(&Row{ID: int64(j), Name: fmt.Sprintf("name-%d-%d", i, j%5)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if n := buffer.ColumnBuffers()[1].Page().Dictionary().Len(); n != 5 {
+			t.Fatalf("wrong number of dictionary values: want=5 got=%d", n)
+		}
+
+		sort.Sort(buffer)
+		rows := buffer.Rows()
+		got := make([]parquet.Row, 10)
+		n, _ := rows.ReadRows(got)
+		rows.Close()
+		if n != 10 {
+			t.Fatalf("wrong number of rows: %d", n)
+		}
+		for j, row := range got[:n] {
+			r := Row{}
+			if err := schema.Reconstruct(&r, row); err != nil {
+				t.Fatal(err)
+			}
+			if want := (Row{ID: int64(9 - j), Name: fmt.Sprintf("name-%d-%d", i, (9-j)%5)}); r != want {
+				t.Fatalf("row %d mismatch: want=%+v got=%+v", j, want, r)
+			}
+		}
+		pool.Put(buffer)
+	}
+}
@@ -2,9 +2,14 @@ package parquet_test
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
 	"os"
 	"os/exec"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -12,8 +17,12 @@ import (
 	"github.com/hexops/gotextdiff"
 	"github.com/hexops/gotextdiff/myers"
 	"github.com/hexops/gotextdiff/span"
+	"github.com/segmentio/encoding/thrift"
 	"github.com/segmentio/parquet-go"
 	"github.com/segmentio/parquet-go/compress"
+	"github.com/segmentio/parquet-go/deprecated"
+	"github.com/segmentio/parquet-go/encoding"
+	"github.com/segmentio/parquet-go/format"
 )
 
 const (
@@ -616,6 +625,328 @@ func TestBloomFilterForDict(t *testing.T) {
 	}
 }
 
+func TestWriterBloomFilterConcurrency(t *testing.T) {
+	type Row struct {
+		ID    int64            `parquet:"id"`
+		Name  string           `parquet:"name"`
+		Color string           `parquet:"color,dict"`
+		Score *float64         `parquet:"score,optional"`
+		UUID  [16]byte         `parquet:"uuid"`
+		Tags  []int32          `parquet:"tags,list"`
+		Key   deprecated.Int96 `parquet:"key"`
+	}
+
+	rows := make([]Row, 50e3)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    int64(i),
+			Name:  fmt.Sprintf("name-%d", i),
+			Color: fmt.Sprintf("color-%d", i%100),
+			Key:   deprecated.Int96{uint32(i), 1, 2},
+		}
+		rows[i].UUID[0] = byte(i)
+		rows[i].UUID[1] = byte(i >> 8)
+		if i%2 == 0 {
+			score := float64(i)
+			rows[i].Score = &score
+		}
+		for j := 0; j < i%3; j++ {
+			rows[i].Tags = append(rows[i].Tags, int32(i+j))
+		}
+	}
+
+	write := func(options ...parquet.WriterOption) []byte {
+		b := new(bytes.Buffer)
+		w := parquet.NewWriter(b, append(options,
+			parquet.BloomFilters(
+				parquet.SplitBlockFilter("id"),
+				parquet.SplitBlockFilter("name"),
+				parquet.SplitBlockFilter("color"),
+				parquet.SplitBlockFilter("score"),
+				parquet.SplitBlockFilter("uuid"),
+				parquet.SplitBlockFilter("tags", "list", "element"),
+				parquet.SplitBlockFilter("key"),
+			),
+		)...)
+		for i := range rows {
+			if err := w.Write(&rows[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return b.Bytes()
+	}
+
+	want := write()
+	for _, concurrency := range []int{2, 4} {
+		if got := write(parquet.BloomFilterConcurrency(concurrency)); !bytes.Equal(got, want) {
+			t.Errorf("concurrency=%d: files written with concurrent bloom filters differ", concurrency)
+		}
+	}
+
+	if _, err := parquet.NewWriterConfig(parquet.BloomFilterConcurrency(-1)); err == nil {
+		t.Error("negative bloom filter concurrency was accepted")
+	}
+}
+
+func TestWriterBloomFilterPlacement(t *testing.T) {
+	type Row struct {
+		ID    int64  `parquet:"id"`
+		Name  string `parquet:"name"`
+		Color string `parquet:"color,dict"`
+	}
+
+	rows := make([]Row, 3000)
+	for i := range rows {
+		rows[i] = Row{
+			ID:    int64(i),
+			Name:  fmt.Sprintf("name-%d", i),
+			Color: fmt.Sprintf("color-%d", i%10),
+		}
+	}
+
+	placements := []parquet.BloomFilterPlacement{
+		parquet.BloomFiltersBeforeRowGroup,
+		parquet.BloomFiltersAfterRowGroup,
+		parquet.BloomFiltersBeforeFooter,
+	}
+
+	for _, placement := range placements {
+		t.Run(placement.String(), func(t *testing.T) {
+			b := new(bytes.Buffer)
+			w := parquet.NewWriter(b,
+				parquet.BloomFilterLocation(placement),
+				parquet.BloomFilters(
+					parquet.SplitBlockFilter("id"),
+					parquet.SplitBlockFilter("color"),
+				),
+			)
+			for i := range rows {
+				if err := w.Write(&rows[i]); err != nil {
+					t.Fatal(err)
+				}
+				if i%1000 == 999 {
+					if err := w.Flush(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			metadata := readFileMetaData(t, b.Bytes())
+			if len(metadata.RowGroups) != 3 {
+				t.Fatalf("wrong number of row groups: %d", len(metadata.RowGroups))
+			}
+
+			pageIndexEnd := int64(0)
+			for _, rowGroup := range metadata.RowGroups {
+				for _, c := range rowGroup.Columns {
+					if end := c.OffsetIndexOffset + int64(c.OffsetIndexLength); end > pageIndexEnd {
+						pageIndexEnd = end
+					}
+				}
+			}
+
+			for i, rowGroup := range metadata.RowGroups {
+				chunksBegin := rowGroup.Columns[0].MetaData.DataPageOffset
+				chunksEnd := int64(0)
+				for _, c := range rowGroup.Columns {
+					begin := c.MetaData.DataPageOffset
+					if c.MetaData.DictionaryPageOffset != 0 {
+						begin = c.MetaData.DictionaryPageOffset
+					}
+					if begin < chunksBegin {
+						chunksBegin = begin
+					}
+					if end := begin + c.MetaData.TotalCompressedSize; end > chunksEnd {
+						chunksEnd = end
+					}
+				}
+
+				for j, c := range rowGroup.Columns {
+					offset := c.MetaData.BloomFilterOffset
+					if j == 1 { // name
+						if offset != 0 {
+							t.Errorf("row group %d: unexpected bloom filter for column %d", i, j)
+						}
+						continue
+					}
+					var ok bool
+					switch placement {
+					case parquet.BloomFiltersBeforeRowGroup:
+						ok = offset >= rowGroup.FileOffset && offset < chunksBegin
+					case parquet.BloomFiltersAfterRowGroup:
+						ok = offset >= chunksEnd
+						if i+1 < len(metadata.RowGroups) {
+							ok = ok && offset < metadata.RowGroups[i+1].FileOffset
+						}
+					case parquet.BloomFiltersBeforeFooter:
+						ok = offset >= pageIndexEnd
+					}
+					if !ok {
+						t.Errorf("row group %d: bloom filter of column %d at offset %d is not placed %s (chunks=[%d:%d] page index end=%d)",
+							i, j, offset, placement, chunksBegin, chunksEnd, pageIndexEnd)
+					}
+				}
+			}
+
+			f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, row := range rows {
+				rowGroup := f.RowGroups()[i/1000]
+				for _, test := range []struct {
+					value interface{}
+					path  string
+				}{
+					{row.ID, "id"},
+					{row.Color, "color"},
+				} {
+					chunk, _ := parquet.LookupColumnChunk(rowGroup, test.path)
+					if ok, err := parquet.MayContain(chunk, parquet.ValueOf(test.value)); err != nil || !ok {
+						t.Fatalf("value %v of row %d not found in the bloom filter of column %q: %v", test.value, i, test.path, err)
+					}
+				}
+			}
+		})
+	}
+
+	if _, err := parquet.NewWriterConfig(parquet.BloomFilterLocation(3)); err == nil {
+		t.Error("invalid bloom filter placement was accepted")
+	}
+}
+
+func TestWriterBloomFiltersNestedColumns(t *testing.T) {
+	type Item struct {
+		SKU   string `parquet:"sku"`
+		Color string `parquet:"color,dict"`
+		Count int64  `parquet:"count"`
+	}
+	type Meta struct {
+		Key    string  `parquet:"key"`
+		Values []int32 `parquet:"values"`
+	}
+	type Row struct {
+		ID    int64    `parquet:"id"`
+		Items []Item   `parquet:"items,list"`
+		Meta  *Meta    `parquet:"meta,optional"`
+		Tags  []string `parquet:"tags"`
+	}
+
+	rows := make([]Row, 5000)
+	for i := range rows {
+		for j := 0; j < i%3; j++ {
+			rows[i].Items = append(rows[i].Items, Item{
+				SKU:   fmt.Sprintf("sku-%d-%d", i, j),
+				Color: fmt.Sprintf("color-%d", i%50),
+				Count: int64(10*i + j),
+			})
+			rows[i].Tags = append(rows[i].Tags, fmt.Sprintf("tag-%d-%d", i, j))
+		}
+		if i%2 == 0 {
+			rows[i].Meta = &Meta{Key: fmt.Sprintf("key-%d", i), Values: []int32{int32(i), int32(-i)}}
+		}
+	}
+
+	filters := []parquet.BloomFilterColumn{
+		parquet.SplitBlockFilter("items", "list", "element", "sku"),
+		parquet.SplitBlockFilter("items", "list", "element", "color"),
+		parquet.SplitBlockFilter("items", "list", "element", "count"),
+		parquet.SplitBlockFilter("meta", "key"),
+		parquet.SplitBlockFilter("meta", "values"),
+		parquet.SplitBlockFilter("tags"),
+	}
+
+	for _, version := range []int{1, 2} {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			b := new(bytes.Buffer)
+			w := parquet.NewWriter(b,
+				parquet.BloomFilters(filters...),
+				parquet.DataPageVersion(version),
+				parquet.PageBufferSize(2048),
+			)
+			for i := range rows {
+				if err := w.Write(&rows[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rowGroup := f.RowGroups()[0]
+
+			mayContain := func(value interface{}, path ...string) bool {
+				t.Helper()
+				chunk, ok := parquet.LookupColumnChunk(rowGroup, path...)
+				if !ok {
+					t.Fatalf("column %q not found", path)
+				}
+				if chunk.BloomFilter() == nil {
+					t.Fatalf("column %q has no bloom filter", path)
+				}
+				ok, err := parquet.MayContain(chunk, parquet.ValueOf(value))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return ok
+			}
+
+			for i, row := range rows {
+				for _, item := range row.Items {
+					if !mayContain(item.SKU, "items", "list", "element", "sku") ||
+						!mayContain(item.Color, "items", "list", "element", "color") ||
+						!mayContain(item.Count, "items", "list", "element", "count") {
+						t.Fatalf("item of row %d not found in the bloom filters: %+v", i, item)
+					}
+				}
+				for _, tag := range row.Tags {
+					if !mayContain(tag, "tags") {
+						t.Fatalf("tag of row %d not found in the bloom filter: %q", i, tag)
+					}
+				}
+				if row.Meta != nil {
+					if !mayContain(row.Meta.Key, "meta", "key") {
+						t.Fatalf("key of row %d not found in the bloom filter: %q", i, row.Meta.Key)
+					}
+					for _, v := range row.Meta.Values {
+						if !mayContain(v, "meta", "values") {
+							t.Fatalf("value of row %d not found in the bloom filter: %d", i, v)
+						}
+					}
+				}
+			}
+
+			if mayContain("sku-missing", "items", "list", "element", "sku") {
+				t.Error("missing value was found in the bloom filter")
+			}
+			if _, ok := parquet.LookupColumnChunk(rowGroup, "items", "sku"); ok {
+				t.Error("column chunk found with an incomplete path")
+			}
+		})
+	}
+
+	t.Run("invalid path", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("no panic creating a writer with a bloom filter on a group")
+			}
+		}()
+		parquet.NewWriter(new(bytes.Buffer), parquet.SchemaOf(new(Row)),
+			parquet.BloomFilters(parquet.SplitBlockFilter("items", "sku")),
+		)
+	})
+}
+
 func TestWriterRepeatedUUIDDict(t *testing.T) {
 	inputID := uuid.MustParse("123456ab-0000-0000-0000-000000000000")
 	records := []struct {
@@ -652,3 +983,544 @@ func TestWriterRepeatedUUIDDict(t *testing.T) {
 		t.Errorf("expected to get UUID %q back out, got %q", inputID, rowbuf[0][0].Bytes())
 	}
 }
+
+func TestWriterByteStreamSplit(t *testing.T) {
+	type Row struct {
+		Int32  int32   `parquet:"int32,split"`
+		Int64  int64   `parquet:"int64,split"`
+		Uint64 uint64  `parquet:"uint64,split"`
+		Fixed  [3]byte `parquet:"fixed,split"`
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{
+			Int32:  int32(i) * -7,
+			Int64:  int64(i) << 40,
+			Uint64: uint64(i) * 1e12,
+			Fixed:  [3]byte{byte(i), byte(i >> 1), byte(i >> 2)},
+		}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)))
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaf := range f.Root().Columns() {
+		if enc := leaf.Encoding().Encoding(); enc != format.ByteStreamSplit {
+			t.Errorf("column %q has the wrong encoding: %s", leaf.Name(), enc)
+		}
+	}
+
+	r := parquet.NewReader(f)
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+}
+
+func TestWriterDeltaLengthByteArray(t *testing.T) {
+	type Row struct {
+		Name string `parquet:"name,delta_length"`
+		Data []byte `parquet:"data,delta_length"`
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{
+			Name: fmt.Sprintf("name-%d", i*i),
+			Data: bytes.Repeat([]byte{byte(i)}, i%7),
+		}
+	}
+
+	b := new(bytes.Buffer)
+	// The struct tags take precedence over the preferred encodings.
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.PreferredEncodings(parquet.ByteArray, &parquet.Plain),
+	)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := readFileMetaData(t, b.Bytes())
+	for _, column := range metadata.RowGroups[0].Columns {
+		want := []format.Encoding{format.DeltaLengthByteArray}
+		if got := column.MetaData.Encoding; !reflect.DeepEqual(want, got) {
+			t.Errorf("column %q has the wrong encodings: want=%v got=%v", column.MetaData.PathInSchema, want, got)
+		}
+	}
+
+	r := parquet.NewReader(bytes.NewReader(b.Bytes()))
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != want.Name || !bytes.Equal(got.Data, want.Data) {
+			t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a delta_length tag on an integer field")
+		}
+	}()
+	parquet.SchemaOf(new(struct {
+		ID int64 `parquet:"id,delta_length"`
+	}))
+}
+
+func TestWriterAdaptiveEncoding(t *testing.T) {
+	type Row struct {
+		ID   int64  `parquet:"id"`
+		Name string `parquet:"name"`
+	}
+
+	rows := make([]Row, 2000)
+	for i := range rows {
+		rows[i].ID = int64(i)
+		if i < len(rows)/2 {
+			rows[i].Name = fmt.Sprintf("name-%d", i%3)
+		} else {
+			rows[i].Name = fmt.Sprintf("%x", i*i*i*i)
+		}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)), parquet.AdaptiveEncoding(true))
+	for i, row := range rows {
+		if i == len(rows)/2 {
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := b.Bytes()
+	metadata := readFileMetaData(t, data)
+
+	expected := [][][]format.Encoding{
+		{{format.DeltaBinaryPacked}, {format.Plain, format.RLEDictionary}},
+		{{format.DeltaBinaryPacked}, {format.DeltaByteArray}},
+	}
+	for i, rowGroup := range metadata.RowGroups {
+		for j, column := range rowGroup.Columns {
+			if want, got := expected[i][j], column.MetaData.Encoding; !reflect.DeepEqual(want, got) {
+				t.Errorf("row group %d: column %q has the wrong encodings: want=%v got=%v", i, column.MetaData.PathInSchema, want, got)
+			}
+		}
+	}
+
+	r := parquet.NewReader(bytes.NewReader(data))
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+}
+
+// failingEncoding is a test encoding which supports INT64 values but fails to
+// encode them.
+type failingEncoding struct {
+	encoding.NotSupported
+}
+
+func (e *failingEncoding) String() string { return "FAILING" }
+
+func (e *failingEncoding) Encoding() format.Encoding { return format.Plain }
+
+func (e *failingEncoding) EncodeInt64(dst, src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return dst[:0], nil
+	}
+	return dst[:0], encoding.Error(e, encoding.ErrInvalidArgument)
+}
+
+func TestWriterPreferredEncodings(t *testing.T) {
+	type Row struct {
+		A int32   `parquet:"a"`
+		B int64   `parquet:"b"`
+		C string  `parquet:"c"`
+		D float64 `parquet:"d"`
+	}
+
+	rows := make([]Row, 100)
+	for i := range rows {
+		rows[i] = Row{A: int32(i), B: int64(i), C: fmt.Sprint(i), D: float64(i)}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.PreferredEncodings(parquet.Int32, &parquet.DeltaLengthByteArray, &parquet.DeltaBinaryPacked),
+		parquet.PreferredEncodings(parquet.Int64, new(failingEncoding), &parquet.DeltaBinaryPacked),
+		parquet.PreferredEncodings(parquet.ByteArray, &parquet.RLEDictionary),
+	)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := b.Bytes()
+	metadata := readFileMetaData(t, data)
+
+	expected := [][]format.Encoding{
+		{format.DeltaBinaryPacked},
+		{format.Plain, format.DeltaBinaryPacked},
+		{format.Plain, format.RLEDictionary},
+		{format.Plain},
+	}
+	for i, column := range metadata.RowGroups[0].Columns {
+		if want, got := expected[i], column.MetaData.Encoding; !reflect.DeepEqual(want, got) {
+			t.Errorf("column %q has the wrong encodings: want=%v got=%v", column.MetaData.PathInSchema, want, got)
+		}
+		for _, stats := range column.MetaData.EncodingStats {
+			if stats.PageType != format.DictionaryPage && stats.Encoding != expected[i][len(expected[i])-1] {
+				t.Errorf("column %q has data pages with the wrong encoding: %v", column.MetaData.PathInSchema, stats.Encoding)
+			}
+		}
+	}
+
+	r := parquet.NewReader(bytes.NewReader(data))
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+}
+
+func TestWriterCompressionThreshold(t *testing.T) {
+	type Row struct {
+		Random   int64 `parquet:"random"`
+		Constant int64 `parquet:"constant"`
+	}
+
+	prng := rand.New(rand.NewSource(0))
+	rows := make([]Row, 1000)
+	for i := range rows {
+		rows[i] = Row{Random: prng.Int63(), Constant: 42}
+	}
+
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.Compression(&parquet.Snappy),
+		parquet.CompressionThreshold(0.9),
+		parquet.PageBufferSize(1024),
+	)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := b.Bytes()
+	metadata := readFileMetaData(t, data)
+
+	// Random values cannot be compressed, while the pages of the constant
+	// column compress well.
+	expected := []bool{false, true}
+	for i, column := range metadata.RowGroups[0].Columns {
+		headers := readDataPageHeaders(t, data, column.MetaData)
+		if len(headers) < 2 {
+			t.Fatalf("column %q has too few pages: %d", column.MetaData.PathInSchema, len(headers))
+		}
+		for j, header := range headers {
+			if got := header.DataPageHeaderV2.IsCompressed == nil || *header.DataPageHeaderV2.IsCompressed; got != expected[i] {
+				t.Errorf("page %d of column %q has the wrong compression flag: want=%t got=%t", j, column.MetaData.PathInSchema, expected[i], got)
+			}
+			if got := header.CompressedPageSize < header.UncompressedPageSize; got != expected[i] {
+				t.Errorf("page %d of column %q has the wrong size: compressed=%d uncompressed=%d", j, column.MetaData.PathInSchema, header.CompressedPageSize, header.UncompressedPageSize)
+			}
+		}
+	}
+
+	r := parquet.NewReader(bytes.NewReader(data))
+	for i, want := range rows {
+		got := Row{}
+		if err := r.Read(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("row %d mismatch: want=%+v got=%+v", i, want, got)
+		}
+	}
+
+	if _, err := parquet.NewWriterConfig(parquet.CompressionThreshold(-1)); err == nil {
+		t.Error("negative compression threshold was accepted")
+	}
+}
+
+func TestWriterOnRowGroupFlush(t *testing.T) {
+	type Row struct {
+		Random   int64 `parquet:"random"`
+		Constant int64 `parquet:"constant"`
+	}
+
+	var stats []*parquet.RowGroupStats
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.SchemaOf(new(Row)),
+		parquet.Compression(&parquet.Snappy),
+		parquet.OnRowGroupFlush(func(s *parquet.RowGroupStats) { stats = append(stats, s) }),
+	)
+
+	prng := rand.New(rand.NewSource(0))
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 1000; j++ {
+			if err := w.Write(Row{Random: prng.Int63(), Constant: 42}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := readFileMetaData(t, b.Bytes())
+	if len(stats) != len(metadata.RowGroups) {
+		t.Fatalf("wrong number of row group flushes: want=%d got=%d", len(metadata.RowGroups), len(stats))
+	}
+
+	for i, rowGroup := range metadata.RowGroups {
+		s := stats[i]
+		if s.RowGroup != i || s.NumRows != rowGroup.NumRows {
+			t.Errorf("wrong statistics for row group %d: index=%d rows=%d", i, s.RowGroup, s.NumRows)
+		}
+		if len(s.Columns) != len(rowGroup.Columns) {
+			t.Fatalf("wrong number of columns in row group %d: want=%d got=%d", i, len(rowGroup.Columns), len(s.Columns))
+		}
+		for j, column := range rowGroup.Columns {
+			c := &s.Columns[j]
+			if !reflect.DeepEqual(c.Path, column.MetaData.PathInSchema) {
+				t.Errorf("wrong path of column %d: want=%q got=%q", j, column.MetaData.PathInSchema, c.Path)
+			}
+			if c.Codec != format.Snappy {
+				t.Errorf("wrong codec of column %q: %s", c.Path, c.Codec)
+			}
+			if c.NumValues != 1000 {
+				t.Errorf("wrong number of values in column %q: %d", c.Path, c.NumValues)
+			}
+			if c.CompressedSize != column.MetaData.TotalCompressedSize || c.UncompressedSize != column.MetaData.TotalUncompressedSize {
+				t.Errorf("wrong sizes of column %q: compressed=%d uncompressed=%d", c.Path, c.CompressedSize, c.UncompressedSize)
+			}
+		}
+		// Random values cannot be compressed, while the constant column
+		// compresses well.
+		if random, constant := s.Columns[0].CompressionRatio(), s.Columns[1].CompressionRatio(); random <= constant {
+			t.Errorf("unexpected compression ratios: random=%f constant=%f", random, constant)
+		}
+	}
+}
+
+func TestWriterFloatStatisticsNaN(t *testing.T) {
+	type Row struct {
+		Mixed float64 `parquet:"mixed"`
+		Dict  float32 `parquet:"dict,dict"`
+		NaN   float64 `parquet:"nan"`
+	}
+
+	nan := math.NaN()
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b, parquet.PageBufferSize(64), parquet.DataPageStatistics(true))
+	for i := 0; i < 100; i++ {
+		row := Row{Mixed: float64(i), Dict: float32(i % 7), NaN: nan}
+		if i%3 == 0 {
+			row.Mixed, row.Dict = nan, float32(nan)
+		}
+		if err := w.Write(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := f.RowGroups()[0].ColumnChunks()
+
+	for _, column := range columns[:2] {
+		columnIndex := column.ColumnIndex()
+		if columnIndex == nil {
+			t.Fatalf("column %d has no column index", column.Column())
+		}
+		for i := 0; i < columnIndex.NumPages(); i++ {
+			min, max := columnIndex.MinValue(i).Double(), columnIndex.MaxValue(i).Double()
+			if column.Type().Kind() == parquet.Float {
+				min, max = float64(columnIndex.MinValue(i).Float()), float64(columnIndex.MaxValue(i).Float())
+			}
+			if math.IsNaN(min) || math.IsNaN(max) {
+				t.Errorf("column %d: page %d has NaN bounds: min=%g max=%g", column.Column(), i, min, max)
+			}
+			if min == 0 && !math.Signbit(min) {
+				t.Errorf("column %d: page %d has a +0 min", column.Column(), i)
+			}
+		}
+	}
+
+	// A page of NaN values cannot be represented in the column index, which
+	// is omitted, but the offset index and values are still available.
+	if columns[2].ColumnIndex() != nil {
+		t.Error("column index written for a column of NaN values")
+	}
+	if columns[2].OffsetIndex() == nil {
+		t.Error("offset index missing for a column of NaN values")
+	}
+
+	r := parquet.NewReader(f)
+	for i := 0; i < 100; i++ {
+		row := Row{}
+		if err := r.Read(&row); err != nil {
+			t.Fatal(err)
+		}
+		if !math.IsNaN(row.NaN) {
+			t.Errorf("row %d: wrong value: %g", i, row.NaN)
+		}
+	}
+}
+
+func TestWriterPageStatisticsTruncation(t *testing.T) {
+	type Row struct {
+		ID    int64  `parquet:"id"`
+		Short string `parquet:"short"`
+		Long  string `parquet:"long"`
+	}
+
+	const sizeLimit = 8
+	b := new(bytes.Buffer)
+	w := parquet.NewWriter(b,
+		parquet.PageBufferSize(256),
+		parquet.DataPageStatistics(true),
+		parquet.ColumnIndexSizeLimit(sizeLimit),
+	)
+	for i := 0; i < 100; i++ {
+		row := Row{
+			ID:    int64(i),
+			Short: fmt.Sprintf("s%03d", i),
+			Long:  fmt.Sprintf("%s-%03d", strings.Repeat("x", 2*sizeLimit), i),
+		}
+		if err := w.Write(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := b.Bytes()
+	metadata := readFileMetaData(t, data)
+	for _, column := range metadata.RowGroups[0].Columns {
+		name := column.MetaData.PathInSchema[0]
+		exact := name != "long"
+
+		for i, header := range readDataPageHeaders(t, data, column.MetaData) {
+			stats := header.DataPageHeaderV2.Statistics
+			if stats.IsMinValueExact == nil || stats.IsMaxValueExact == nil {
+				t.Fatalf("%s: page %d: exactness of the statistics not recorded", name, i)
+			}
+			if *stats.IsMinValueExact != exact || *stats.IsMaxValueExact != exact {
+				t.Errorf("%s: page %d: wrong exactness of the statistics: min=%t max=%t", name, i, *stats.IsMinValueExact, *stats.IsMaxValueExact)
+			}
+			if len(stats.MinValue) > sizeLimit || len(stats.MaxValue) > sizeLimit {
+				t.Errorf("%s: page %d: statistics were not truncated: min=%q max=%q", name, i, stats.MinValue, stats.MaxValue)
+			}
+		}
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path  string
+		exact bool
+	}{
+		{"id", true},
+		// The column index does not record whether byte array bounds were
+		// truncated.
+		{"short", false},
+		{"long", false},
+	} {
+		chunk, _ := parquet.LookupColumnChunk(f.RowGroups()[0], test.path)
+		stats := parquet.StatisticsOf(chunk)
+		if stats.MinValueExact != test.exact || stats.MaxValueExact != test.exact {
+			t.Errorf("%s: wrong exactness of the statistics: min=%t max=%t", test.path, stats.MinValueExact, stats.MaxValueExact)
+		}
+	}
+}
+
+func readFileMetaData(t *testing.T, data []byte) *format.FileMetaData {
+	t.Helper()
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	metadata := new(format.FileMetaData)
+	if err := thrift.Unmarshal(new(thrift.CompactProtocol), data[len(data)-(footerSize+8):len(data)-8], metadata); err != nil {
+		t.Fatal(err)
+	}
+	return metadata
+}
+
+func readDataPageHeaders(t *testing.T, data []byte, column format.ColumnMetaData) []format.PageHeader {
+	t.Helper()
+	chunk := bytes.NewReader(data[column.DataPageOffset : column.DataPageOffset+column.TotalCompressedSize])
+	decoder := thrift.NewDecoder(new(thrift.CompactProtocol).NewReader(chunk))
+	headers := []format.PageHeader{}
+	for chunk.Len() > 0 {
+		header := format.PageHeader{}
+		if err := decoder.Decode(&header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chunk.Seek(int64(header.CompressedPageSize), io.SeekCurrent); err != nil {
+			t.Fatal(err)
+		}
+		if header.Type == format.DataPageV2 {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}